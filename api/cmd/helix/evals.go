@@ -1,23 +1,218 @@
 package helix
 
 import (
-	"github.com/helixml/helix/api/pkg/evals"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
 	"github.com/spf13/cobra"
+
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/evals"
+	"github.com/helixml/helix/api/pkg/system"
 )
 
-var evalTargets []string
+type EvalsRunOptions struct {
+	ConfigFile string
+	AppID      string
+	Model      string
+	Target     string // LLM target from the config to evaluate instead of a helix app
+	Judge      string // LLM target from the config used as the judge
+	Output     string
+	JUnit      string
+	Baseline   string
+}
 
 func newEvalsCommand() *cobra.Command {
 	var evalsCmd = &cobra.Command{
 		Use:   "evals",
-		Short: "A CLI tool for evaluating finetuned LLMs",
-		Run: func(cmd *cobra.Command, args []string) {
-			evals.Run()
-		},
+		Short: "A CLI tool for evaluating helix apps and models",
 	}
-	evalsCmd.Flags().StringSliceVar(&evalTargets, "target", []string{},
-		"Target(s) to use, defaults to all",
-	)
+
+	evalsCmd.AddCommand(newEvalsRunCommand())
 
 	return evalsCmd
 }
+
+func newEvalsRunCommand() *cobra.Command {
+	options := &EvalsRunOptions{}
+
+	runCmd := &cobra.Command{
+		Use:   "run",
+		Short: "Run the evals against an app or a model",
+		Long: `Asks the app or the model every question from the evals config, grades the answers
+with the LLM judge and writes the report. Exits with a non-zero code if there are
+regressions, when a baseline report is given only the cases that passed in the
+baseline are counted as regressions.`,
+		Example: "helix evals run --config evals.yaml --app app_123 --output report.json --junit report.xml",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runEvals(cmd, options)
+		},
+	}
+
+	runCmd.Flags().StringVar(&options.ConfigFile, "config", "evals.yaml", "Evals config file")
+	runCmd.Flags().StringVar(&options.AppID, "app", "", "Helix app to evaluate")
+	runCmd.Flags().StringVar(&options.Model, "model", "", "Helix model to evaluate, optional for apps")
+	runCmd.Flags().StringVar(&options.Target, "target", "", "LLM target from the config to evaluate instead of helix")
+	runCmd.Flags().StringVar(&options.Judge, "judge", "", "LLM target from the config to grade the answers, defaults to the first one")
+	runCmd.Flags().StringVar(&options.Output, "output", "", "Write the JSON report to this file")
+	runCmd.Flags().StringVar(&options.JUnit, "junit", "", "Write the JUnit report to this file")
+	runCmd.Flags().StringVar(&options.Baseline, "baseline", "", "Previous JSON report to compare against")
+
+	return runCmd
+}
+
+func runEvals(cmd *cobra.Command, options *EvalsRunOptions) error {
+	system.SetupLogging()
+
+	cfg, err := evals.LoadConfig(options.ConfigFile)
+	if err != nil {
+		return err
+	}
+
+	judgeTarget, err := cfg.GetLLMTarget(options.Judge)
+	if err != nil {
+		return fmt.Errorf("failed to get judge: %w", err)
+	}
+
+	runnerOpts := evals.Options{
+		Config:     cfg,
+		Judge:      newOpenAIClient(judgeTarget.APIURL, judgeTarget.Token(), nil),
+		JudgeModel: judgeTarget.Model,
+	}
+
+	switch {
+	case options.Target != "":
+		target, err := cfg.GetLLMTarget(options.Target)
+		if err != nil {
+			return err
+		}
+
+		runnerOpts.Target = newOpenAIClient(target.APIURL, target.Token(), nil)
+		runnerOpts.TargetName = target.Name
+		runnerOpts.TargetModel = target.Model
+	case options.AppID != "" || options.Model != "":
+		cliConfig, err := config.LoadCliConfig()
+		if err != nil {
+			return err
+		}
+
+		if cliConfig.APIKey == "" {
+			return fmt.Errorf("HELIX_API_KEY is required")
+		}
+
+		var query map[string]string
+		if options.AppID != "" {
+			query = map[string]string{"app_id": options.AppID}
+			runnerOpts.TargetName = options.AppID
+		} else {
+			runnerOpts.TargetName = options.Model
+		}
+
+		runnerOpts.Target = newOpenAIClient(strings.TrimSuffix(cliConfig.URL, "/")+"/v1", cliConfig.APIKey, query)
+		runnerOpts.TargetModel = options.Model
+	default:
+		return fmt.Errorf("either --app, --model or --target is required")
+	}
+
+	runner, err := evals.NewRunner(runnerOpts)
+	if err != nil {
+		return err
+	}
+
+	report, err := runner.Run(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	if options.Output != "" {
+		err = writeReport(options.Output, report.WriteJSON)
+		if err != nil {
+			return err
+		}
+	}
+
+	if options.JUnit != "" {
+		err = writeReport(options.JUnit, report.WriteJUnit)
+		if err != nil {
+			return err
+		}
+	}
+
+	var baseline *evals.Report
+	if options.Baseline != "" {
+		baseline, err = evals.LoadReport(options.Baseline)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "%d evals: %d passed, %d failed, %d errored\n", report.Total, report.Passed, report.Failed, report.Errored)
+
+	regressions := report.Regressions(baseline)
+	if len(regressions) > 0 {
+		for _, r := range regressions {
+			if r.Error != "" {
+				fmt.Fprintf(cmd.OutOrStdout(), "  %s: %s\n", r.Name, r.Error)
+			} else {
+				fmt.Fprintf(cmd.OutOrStdout(), "  %s: graded %s (score %.2f)\n", r.Name, r.Grade, r.Score)
+			}
+		}
+		return fmt.Errorf("%d regressions", len(regressions))
+	}
+
+	return nil
+}
+
+func writeReport(path string, write func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report file: %w", err)
+	}
+	defer f.Close()
+
+	err = write(f)
+	if err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	return nil
+}
+
+// newOpenAIClient creates a client for an OpenAI compatible API, the query
+// parameters are added to every request (for example the helix app_id)
+func newOpenAIClient(baseURL, token string, query map[string]string) *openai.Client {
+	cfg := openai.DefaultConfig(token)
+	cfg.BaseURL = baseURL
+
+	if len(query) > 0 {
+		cfg.HTTPClient = &http.Client{
+			Transport: &queryTransport{
+				query: query,
+				next:  http.DefaultTransport,
+			},
+		}
+	}
+
+	return openai.NewClientWithConfig(cfg)
+}
+
+type queryTransport struct {
+	query map[string]string
+	next  http.RoundTripper
+}
+
+func (t *queryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+
+	q := req.URL.Query()
+	for k, v := range t.query {
+		q.Set(k, v)
+	}
+	req.URL.RawQuery = q.Encode()
+
+	return t.next.RoundTrip(req)
+}
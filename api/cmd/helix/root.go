@@ -6,9 +6,13 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/helixml/helix/api/pkg/cli/apikey"
 	"github.com/helixml/helix/api/pkg/cli/app"
 	"github.com/helixml/helix/api/pkg/cli/fs"
 	"github.com/helixml/helix/api/pkg/cli/knowledge"
+	"github.com/helixml/helix/api/pkg/cli/provider"
+	"github.com/helixml/helix/api/pkg/cli/session"
+	"github.com/helixml/helix/api/pkg/cli/usage"
 )
 
 var Fatal = FatalErrorHandler
@@ -38,6 +42,10 @@ func NewRootCmd() *cobra.Command {
 	RootCmd.AddCommand(knowledge.New())
 	RootCmd.AddCommand(fs.New())
 	RootCmd.AddCommand(fs.NewUploadCmd()) // Shortcut for upload
+	RootCmd.AddCommand(apikey.New())
+	RootCmd.AddCommand(usage.New())
+	RootCmd.AddCommand(provider.New())
+	RootCmd.AddCommand(session.New())
 
 	return RootCmd
 }
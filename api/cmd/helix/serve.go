@@ -30,6 +30,7 @@ import (
 	"github.com/helixml/helix/api/pkg/system"
 	"github.com/helixml/helix/api/pkg/trigger"
 	"github.com/helixml/helix/api/pkg/types"
+	"github.com/helixml/helix/api/pkg/usage"
 
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
@@ -153,6 +154,18 @@ func getFilestore(ctx context.Context, cfg *config.ServerConfig) (filestore.File
 			return nil, err
 		}
 		store = gcs
+	} else if cfg.FileStore.Type == types.FileStoreTypeS3 {
+		s3, err := filestore.NewS3Storage(ctx, filestore.S3StorageConfig{
+			Bucket:       cfg.FileStore.S3Bucket,
+			Region:       cfg.FileStore.S3Region,
+			Endpoint:     cfg.FileStore.S3Endpoint,
+			UsePathStyle: cfg.FileStore.S3UsePathStyle,
+			PartSize:     int64(cfg.FileStore.S3PartSizeMB) * 1024 * 1024,
+		})
+		if err != nil {
+			return nil, err
+		}
+		store = s3
 	} else {
 		return nil, fmt.Errorf("unknown filestore type: %s", cfg.FileStore.Type)
 	}
@@ -196,12 +209,24 @@ func serve(cmd *cobra.Command, cfg *config.ServerConfig) error {
 		return fmt.Errorf("runner token is required")
 	}
 
-	keycloakAuthenticator, err := auth.NewKeycloakAuthenticator(&cfg.Keycloak)
-	if err != nil {
-		return fmt.Errorf("failed to create keycloak authenticator: %v", err)
+	var authenticator auth.Authenticator
+
+	switch cfg.Auth.Provider {
+	case types.AuthProviderKeycloak:
+		authenticator, err = auth.NewKeycloakAuthenticator(&cfg.Keycloak)
+		if err != nil {
+			return fmt.Errorf("failed to create keycloak authenticator: %v", err)
+		}
+	case types.AuthProviderOIDC:
+		authenticator, err = auth.NewOIDCAuthenticator(ctx, &cfg.Auth.OIDC, store)
+		if err != nil {
+			return fmt.Errorf("failed to create OIDC authenticator: %v", err)
+		}
+	default:
+		return fmt.Errorf("unknown auth provider: %s", cfg.Auth.Provider)
 	}
 
-	notifier, err := notification.New(&cfg.Notifications, keycloakAuthenticator)
+	notifier, err := notification.New(&cfg.Notifications, authenticator)
 	if err != nil {
 		return fmt.Errorf("failed to create notifier: %v", err)
 	}
@@ -243,12 +268,37 @@ func serve(cmd *cobra.Command, cfg *config.ServerConfig) error {
 	// 	return err
 	// }
 
+	pricing, err := usage.LoadPricing(cfg.Usage.PricingFile)
+	if err != nil {
+		return err
+	}
+
 	logStores := []logger.LogStore{
 		store,
+		usage.NewRecorder(store, pricing),
 		// TODO: bigquery
 	}
 
-	providerManager := manager.NewProviderManager(cfg, helixInference, logStores...)
+	providerManager := manager.NewProviderManager(cfg, store, helixInference, logStores...)
+
+	routingPolicies, err := manager.LoadRoutingPolicies(cfg.Providers.RoutingFile)
+	if err != nil {
+		return err
+	}
+
+	err = providerManager.SetRoutingPolicies(routingPolicies)
+	if err != nil {
+		return err
+	}
+
+	responseCache, err := createResponseCache(cfg, providerManager)
+	if err != nil {
+		return err
+	}
+
+	if responseCache != nil {
+		providerManager.SetCache(responseCache)
+	}
 
 	// controllerOpenAIClient = logger.Wrap(cfg, controllerOpenAIClient, logStores...)
 
@@ -277,6 +327,19 @@ func serve(cmd *cobra.Command, cfg *config.ServerConfig) error {
 			DeleteURL: cfg.RAG.Llamaindex.RAGDeleteURL,
 		})
 		log.Info().Msgf("Using Llamaindex for RAG")
+	case "pgvector":
+		err = store.MigratePGVectorUp()
+		if err != nil {
+			return fmt.Errorf("failed to migrate pgvector tables: %v", err)
+		}
+		embeddingsClient, err := providerManager.GetClient(ctx, &manager.GetClientRequest{
+			Provider: types.Provider(cfg.RAG.PGVector.Provider),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get embeddings client for pgvector: %v", err)
+		}
+		ragClient = rag.NewPGVector(store.DB(), embeddingsClient, cfg.RAG.PGVector.EmbeddingsModel)
+		log.Info().Msgf("Using pgvector for RAG")
 	default:
 		return fmt.Errorf("unknown RAG provider: %s", cfg.RAG.DefaultRagProvider)
 	}
@@ -296,6 +359,7 @@ func serve(cmd *cobra.Command, cfg *config.ServerConfig) error {
 		ProviderManager:      providerManager,
 		DataprepOpenAIClient: dataprepOpenAIClient,
 		Scheduler:            scheduler,
+		ResponseCache:        responseCache,
 	}
 
 	appController, err = controller.NewController(ctx, controllerOptions)
@@ -303,6 +367,26 @@ func serve(cmd *cobra.Command, cfg *config.ServerConfig) error {
 		return err
 	}
 
+	// Restore the slots from before the restart, the session requests that
+	// were running in the previous process are failed in their sessions
+	err = scheduler.Restore(ctx, store, func(req *types.RunnerLLMInferenceRequest, reason string) {
+		appController.WriteRestoredLLMResponse(ctx, req, &types.RunnerLLMInferenceResponse{
+			RequestID: req.RequestID,
+			Error:     reason,
+			Done:      true,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to restore scheduler state: %w", err)
+	}
+
+	// Re-enqueue the session requests that were still waiting for a runner,
+	// their responses are written to the sessions
+	err = helixInference.RestoreQueue(ctx, store, appController.WriteRestoredLLMResponse)
+	if err != nil {
+		return fmt.Errorf("failed to restore inference queue: %w", err)
+	}
+
 	err = appController.Initialize()
 	if err != nil {
 		return err
@@ -310,6 +394,9 @@ func serve(cmd *cobra.Command, cfg *config.ServerConfig) error {
 
 	go appController.Start(ctx)
 
+	// Fine-tuning preempted by higher priority inference goes back to the queue
+	scheduler.OnPreempted(appController.AddSessionToQueue)
+
 	knowledgeReconciler, err := knowledge.New(cfg, store, fs, extractor, ragClient)
 	if err != nil {
 		return err
@@ -328,7 +415,7 @@ func serve(cmd *cobra.Command, cfg *config.ServerConfig) error {
 		},
 	)
 
-	server, err := server.NewServer(cfg, store, ps, gse, providerManager, helixInference, keycloakAuthenticator, stripe, appController, janitor, knowledgeReconciler, scheduler)
+	server, err := server.NewServer(cfg, store, ps, gse, providerManager, helixInference, authenticator, stripe, appController, janitor, knowledgeReconciler, scheduler)
 	if err != nil {
 		return err
 	}
@@ -1,6 +1,7 @@
 package helix
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"reflect"
@@ -11,8 +12,11 @@ import (
 
 	"github.com/helixml/helix/api/pkg/config"
 	"github.com/helixml/helix/api/pkg/openai"
+	"github.com/helixml/helix/api/pkg/openai/cache"
+	"github.com/helixml/helix/api/pkg/openai/manager"
 	"github.com/helixml/helix/api/pkg/types"
 	"github.com/rs/zerolog/log"
+	goopenai "github.com/sashabaranov/go-openai"
 	"github.com/spf13/cobra"
 )
 
@@ -167,3 +171,41 @@ func createDataPrepOpenAIClient(cfg *config.ServerConfig, helixInference openai.
 		return nil, errors.New("unknown inference provider")
 	}
 }
+
+// createResponseCache returns the cache of the chat completions, nil if it's
+// not enabled. The semantic cache embeds the messages with the configured provider.
+func createResponseCache(cfg *config.ServerConfig, providerManager manager.ProviderManager) (*cache.Cache, error) {
+	if !cfg.Cache.Enabled {
+		return nil, nil
+	}
+
+	log.Info().
+		Dur("ttl", cfg.Cache.TTL).
+		Bool("semantic", cfg.Cache.SemanticEnabled).
+		Msg("caching chat completion responses")
+
+	embed := func(ctx context.Context, text string) ([]float32, error) {
+		client, err := providerManager.GetClient(ctx, &manager.GetClientRequest{
+			Provider: types.Provider(cfg.Cache.EmbeddingsProvider),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := client.CreateEmbeddings(ctx, goopenai.EmbeddingRequest{
+			Input: text,
+			Model: goopenai.EmbeddingModel(cfg.Cache.EmbeddingsModel),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if len(resp.Data) == 0 {
+			return nil, errors.New("no embeddings returned")
+		}
+
+		return resp.Data[0].Embedding, nil
+	}
+
+	return cache.New(&cfg.Cache, embed)
+}
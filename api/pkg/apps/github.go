@@ -1,6 +1,7 @@
 package apps
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -22,20 +23,28 @@ type GithubAppOptions struct {
 	App          *types.App
 	ToolsPlanner tools.Planner
 	UpdateApp    func(app *types.App) (*types.App, error)
+	// ValidateConfig checks the config loaded from the repo before it's saved,
+	// existing is the config the app had before
+	ValidateConfig func(config, existing *types.AppHelixConfig) error
 }
 
 type GithubApp struct {
-	Name         string
-	Owner        string
-	Repo         string
-	GithubConfig config.GitHub
-	helixConfig  *types.AppHelixConfig
-	Client       *github.GithubClient
-	ToolsPlanner tools.Planner
-	App          *types.App
-	UpdateApp    func(app *types.App) (*types.App, error)
+	Name           string
+	Owner          string
+	Repo           string
+	GithubConfig   config.GitHub
+	helixConfig    *types.AppHelixConfig
+	Client         *github.GithubClient
+	ToolsPlanner   tools.Planner
+	App            *types.App
+	UpdateApp      func(app *types.App) (*types.App, error)
+	ValidateConfig func(config, existing *types.AppHelixConfig) error
 }
 
+// ErrInvalidConfig is returned when the helix.yaml of the repo is rejected by
+// ValidateConfig
+var ErrInvalidConfig = errors.New("invalid helix.yaml")
+
 var HELIX_YAML_FILENAMES = []string{"helix.yaml", "helix.yml"}
 var HELIX_DEPLOY_KEY_NAME = "helix-deploy-key"
 
@@ -56,15 +65,19 @@ func NewGithubApp(options GithubAppOptions) (*GithubApp, error) {
 	if options.UpdateApp == nil {
 		return nil, fmt.Errorf("UpdateApp function is required")
 	}
+	if options.ValidateConfig == nil {
+		return nil, fmt.Errorf("ValidateConfig function is required")
+	}
 	return &GithubApp{
-		Name:         options.App.Config.Github.Repo,
-		Owner:        parts[0],
-		Repo:         parts[1],
-		GithubConfig: options.GithubConfig,
-		Client:       options.Client,
-		ToolsPlanner: options.ToolsPlanner,
-		App:          options.App,
-		UpdateApp:    options.UpdateApp,
+		Name:           options.App.Config.Github.Repo,
+		Owner:          parts[0],
+		Repo:           parts[1],
+		GithubConfig:   options.GithubConfig,
+		Client:         options.Client,
+		ToolsPlanner:   options.ToolsPlanner,
+		App:            options.App,
+		UpdateApp:      options.UpdateApp,
+		ValidateConfig: options.ValidateConfig,
 	}, nil
 }
 
@@ -119,6 +132,12 @@ func (githubApp *GithubApp) Create() (*types.App, error) {
 		return nil, err
 	}
 
+	// Nothing was configured before, the app is new
+	err = githubApp.validateConfig(config, &types.AppHelixConfig{})
+	if err != nil {
+		return nil, err
+	}
+
 	app.Config.Helix = *config
 
 	commitHash, err := github.GetRepoHash(githubApp.Filepath(""))
@@ -196,6 +215,9 @@ func (githubApp *GithubApp) Update() (*types.App, error) {
 	}
 
 	config, err = githubApp.processConfig(config)
+	if err == nil {
+		err = githubApp.validateConfig(config, &app.Config.Helix)
+	}
 	if err != nil {
 		// if there is an error here it means there is a problem with the config
 		// we have loaded from github - let's mark the latest update as an error
@@ -228,6 +250,14 @@ func (githubApp *GithubApp) Update() (*types.App, error) {
 	return app, nil
 }
 
+func (githubApp *GithubApp) validateConfig(config, existing *types.AppHelixConfig) error {
+	err := githubApp.ValidateConfig(config, existing)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidConfig, err)
+	}
+	return nil
+}
+
 func (githubApp *GithubApp) Clone() error {
 	return github.CloneOrUpdateRepo(
 		// the name of the repo
@@ -412,6 +442,21 @@ func (githubApp *GithubApp) processConfig(config *types.AppHelixConfig) (*types.
 			})
 		}
 
+		for _, code := range assistant.Code {
+			codeConfig := code.ToolCodeConfig
+			newTools = append(newTools, &types.Tool{
+				ID:          system.GenerateToolID(),
+				Created:     time.Now(),
+				Updated:     time.Now(),
+				Name:        code.Name,
+				Description: code.Description,
+				ToolType:    types.ToolTypeCode,
+				Config: types.ToolConfig{
+					Code: &codeConfig,
+				},
+			})
+		}
+
 		for i := range newTools {
 			err := tools.ValidateTool(newTools[i], githubApp.ToolsPlanner, false)
 			if err != nil {
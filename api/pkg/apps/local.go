@@ -46,6 +46,7 @@ func NewLocalApp(filename string) (*LocalApp, error) {
 		apiTools   []*types.Tool
 		gptScripts []*types.Tool
 		zapier     []*types.Tool
+		code       []*types.Tool
 	)
 
 	for idx, assistant := range app.Assistants {
@@ -87,6 +88,18 @@ func NewLocalApp(filename string) (*LocalApp, error) {
 			})
 		}
 
+		for _, assistantCode := range assistant.Code {
+			codeConfig := assistantCode.ToolCodeConfig
+			code = append(code, &types.Tool{
+				Name:        assistantCode.Name,
+				Description: assistantCode.Description,
+				ToolType:    types.ToolTypeCode,
+				Config: types.ToolConfig{
+					Code: &codeConfig,
+				},
+			})
+		}
+
 		for _, script := range assistant.GPTScripts {
 			switch {
 			case script.Content != "":
@@ -135,6 +148,7 @@ func NewLocalApp(filename string) (*LocalApp, error) {
 		app.Assistants[idx].Tools = apiTools
 		app.Assistants[idx].Tools = append(app.Assistants[idx].Tools, zapier...)
 		app.Assistants[idx].Tools = append(app.Assistants[idx].Tools, gptScripts...)
+		app.Assistants[idx].Tools = append(app.Assistants[idx].Tools, code...)
 	}
 
 	return &LocalApp{
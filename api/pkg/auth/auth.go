@@ -11,4 +11,6 @@ import (
 type Authenticator interface {
 	GetUserByID(ctx context.Context, userID string) (*types.User, error)
 	ValidateUserToken(ctx context.Context, token string) (*jwt.Token, error)
+	// GetUserFromToken validates the user's login token and returns the user it was issued for
+	GetUserFromToken(ctx context.Context, token string) (*types.User, error)
 }
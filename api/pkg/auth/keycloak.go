@@ -275,6 +275,30 @@ func (k *KeycloakAuthenticator) ValidateUserToken(ctx context.Context, token str
 	return j, nil
 }
 
+func (k *KeycloakAuthenticator) GetUserFromToken(ctx context.Context, token string) (*types.User, error) {
+	keycloakJWT, err := k.ValidateUserToken(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("error validating keycloak token: %s", err.Error())
+	}
+	mc := keycloakJWT.Claims.(jwt.MapClaims)
+	keycloakUserID, _ := mc["sub"].(string)
+
+	if keycloakUserID == "" {
+		return nil, fmt.Errorf("no keycloak user ID found")
+	}
+
+	user, err := k.GetUserByID(ctx, keycloakUserID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading user from keycloak: %s", err.Error())
+	}
+
+	user.TokenType = types.TokenTypeKeycloak
+	user.ID = keycloakUserID
+	user.Type = types.OwnerTypeUser
+
+	return user, nil
+}
+
 func addr[T any](t T) *T { return &t }
 
 // Compile-time interface check:
@@ -25,3 +25,7 @@ func (m *MockAuthenticator) GetUserByID(ctx context.Context, userID string) (*ty
 func (m *MockAuthenticator) ValidateUserToken(ctx context.Context, token string) (*jwt.Token, error) {
 	return nil, nil
 }
+
+func (m *MockAuthenticator) GetUserFromToken(ctx context.Context, token string) (*types.User, error) {
+	return m.user, nil
+}
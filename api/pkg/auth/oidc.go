@@ -0,0 +1,404 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog/log"
+
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+const (
+	// minJWKSRefreshInterval limits the refreshes triggered by tokens signed
+	// with unknown keys
+	minJWKSRefreshInterval = time.Minute
+	oidcRequestTimeout     = 10 * time.Second
+)
+
+// OIDCAuthenticator validates the tokens issued by an OpenID Connect provider,
+// the user profile is taken from the token claims and kept in the user meta
+// so the users can be looked up for their API keys and notifications
+type OIDCAuthenticator struct {
+	cfg        *config.OIDC
+	store      store.Store
+	httpClient *http.Client
+
+	jwksURI string
+
+	keysMu      sync.Mutex
+	keys        map[string]interface{} // kid -> public key
+	keysFetched time.Time
+}
+
+type oidcDiscovery struct {
+	Issuer  string `json:"issuer"`
+	JWKSURI string `json:"jwks_uri"`
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func NewOIDCAuthenticator(ctx context.Context, cfg *config.OIDC, store store.Store) (*OIDCAuthenticator, error) {
+	if cfg.Issuer == "" {
+		return nil, fmt.Errorf("OIDC issuer is required")
+	}
+
+	if cfg.ClientID == "" {
+		return nil, fmt.Errorf("OIDC client ID is required")
+	}
+
+	o := &OIDCAuthenticator{
+		cfg:   cfg,
+		store: store,
+		httpClient: &http.Client{
+			Timeout: oidcRequestTimeout,
+		},
+		keys: make(map[string]interface{}),
+	}
+
+	log.Info().Str("issuer", cfg.Issuer).Msg("discovering OIDC provider configuration...")
+
+	err := o.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+func (o *OIDCAuthenticator) discover(ctx context.Context) error {
+	var discovery oidcDiscovery
+
+	err := o.getJSON(ctx, strings.TrimSuffix(o.cfg.Issuer, "/")+"/.well-known/openid-configuration", &discovery)
+	if err != nil {
+		return fmt.Errorf("failed to discover OIDC provider configuration: %w", err)
+	}
+
+	if strings.TrimSuffix(discovery.Issuer, "/") != strings.TrimSuffix(o.cfg.Issuer, "/") {
+		return fmt.Errorf("OIDC issuer mismatch, configured '%s' but the provider reports '%s'", o.cfg.Issuer, discovery.Issuer)
+	}
+
+	if discovery.JWKSURI == "" {
+		return fmt.Errorf("OIDC provider configuration has no jwks_uri")
+	}
+
+	o.jwksURI = discovery.JWKSURI
+
+	return nil
+}
+
+func (o *OIDCAuthenticator) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", url, resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// getKey returns the signing key, the keys are refreshed when the cache
+// expires or when the provider rotated to a key we haven't seen yet
+func (o *OIDCAuthenticator) getKey(ctx context.Context, kid string) (interface{}, error) {
+	o.keysMu.Lock()
+	defer o.keysMu.Unlock()
+
+	expired := time.Since(o.keysFetched) > o.cfg.JWKSCacheTTL
+	key, ok := o.keys[kid]
+
+	if ok && !expired {
+		return key, nil
+	}
+
+	if expired || time.Since(o.keysFetched) > minJWKSRefreshInterval {
+		err := o.refreshKeys(ctx)
+		if err != nil {
+			// Keep using the cached keys while the provider is unavailable
+			if ok {
+				log.Warn().Err(err).Msg("failed to refresh OIDC signing keys")
+				return key, nil
+			}
+			return nil, err
+		}
+
+		key, ok = o.keys[kid]
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key '%s'", kid)
+	}
+
+	return key, nil
+}
+
+func (o *OIDCAuthenticator) refreshKeys(ctx context.Context) error {
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+
+	err := o.getJSON(ctx, o.jwksURI, &jwks)
+	if err != nil {
+		return fmt.Errorf("failed to get OIDC signing keys: %w", err)
+	}
+
+	keys := make(map[string]interface{}, len(jwks.Keys))
+
+	for _, k := range jwks.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.publicKey()
+		if err != nil {
+			log.Warn().Err(err).Str("kid", k.Kid).Msg("skipping OIDC signing key")
+			continue
+		}
+
+		keys[k.Kid] = key
+	}
+
+	o.keys = keys
+	o.keysFetched = time.Now()
+
+	return nil
+}
+
+func (k *jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBase64URLInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := decodeBase64URLInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent: %w", err)
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve '%s'", k.Crv)
+		}
+		x, err := decodeBase64URLInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x coordinate: %w", err)
+		}
+		y, err := decodeBase64URLInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y coordinate: %w", err)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type '%s'", k.Kty)
+	}
+}
+
+func decodeBase64URLInt(s string) (*big.Int, error) {
+	bts, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(bts), nil
+}
+
+func (o *OIDCAuthenticator) ValidateUserToken(ctx context.Context, token string) (*jwt.Token, error) {
+	parsed, err := jwt.Parse(token, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return o.getKey(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(o.cfg.Issuer),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid or expired token: %w", err)
+	}
+
+	// The expiry is only validated when it's set
+	claims := parsed.Claims.(jwt.MapClaims)
+	exp, _ := claims.GetExpirationTime()
+	if exp == nil {
+		return nil, fmt.Errorf("token has no expiry")
+	}
+
+	// Access tokens don't always have the client in the audience, the
+	// authorized party is the client the token was issued to
+	audience, _ := claims.GetAudience()
+	azp, _ := claims["azp"].(string)
+
+	if azp != o.cfg.ClientID && !slices.Contains(audience, o.cfg.ClientID) {
+		return nil, fmt.Errorf("token was not issued for client '%s'", o.cfg.ClientID)
+	}
+
+	return parsed, nil
+}
+
+func (o *OIDCAuthenticator) GetUserFromToken(ctx context.Context, token string) (*types.User, error) {
+	parsed, err := o.ValidateUserToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	claims := parsed.Claims.(jwt.MapClaims)
+
+	userID := stringClaim(claims, o.cfg.UserIDClaim)
+	if userID == "" {
+		return nil, fmt.Errorf("no user ID found in the '%s' claim", o.cfg.UserIDClaim)
+	}
+
+	user := &types.User{
+		ID:        userID,
+		Type:      types.OwnerTypeUser,
+		TokenType: types.TokenTypeOIDC,
+		Username:  stringClaim(claims, o.cfg.UsernameClaim),
+		Email:     stringClaim(claims, o.cfg.EmailClaim),
+		FullName:  stringClaim(claims, o.cfg.NameClaim),
+	}
+
+	if o.cfg.AdminGroup != "" {
+		user.Admin = slices.Contains(stringsClaim(claims, o.cfg.GroupsClaim), o.cfg.AdminGroup)
+	}
+
+	err = o.ensureUserMeta(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// ensureUserMeta creates the user on the first login and keeps the profile
+// up to date with the claims
+func (o *OIDCAuthenticator) ensureUserMeta(ctx context.Context, user *types.User) error {
+	existing, err := o.store.GetUserMeta(ctx, user.ID)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return fmt.Errorf("error loading user: %w", err)
+	}
+
+	if existing != nil &&
+		existing.Username == user.Username &&
+		existing.Email == user.Email &&
+		existing.FullName == user.FullName {
+		return nil
+	}
+
+	userMeta := types.UserMeta{
+		ID: user.ID,
+	}
+	if existing != nil {
+		userMeta = *existing
+	}
+
+	userMeta.Username = user.Username
+	userMeta.Email = user.Email
+	userMeta.FullName = user.FullName
+
+	_, err = o.store.EnsureUserMeta(ctx, userMeta)
+	if err != nil {
+		return fmt.Errorf("error saving user: %w", err)
+	}
+
+	return nil
+}
+
+func (o *OIDCAuthenticator) GetUserByID(ctx context.Context, userID string) (*types.User, error) {
+	userMeta, err := o.store.GetUserMeta(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading user '%s': %w", userID, err)
+	}
+
+	return &types.User{
+		ID:       userMeta.ID,
+		Username: userMeta.Username,
+		Email:    userMeta.Email,
+		FullName: userMeta.FullName,
+	}, nil
+}
+
+// getClaim returns the claim, nested claims are separated by dots (e.g. realm_access.roles)
+func getClaim(claims jwt.MapClaims, name string) interface{} {
+	var value interface{} = map[string]interface{}(claims)
+
+	for _, part := range strings.Split(name, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = m[part]
+	}
+
+	return value
+}
+
+func stringClaim(claims jwt.MapClaims, name string) string {
+	switch v := getClaim(claims, name).(type) {
+	case string:
+		return v
+	case float64:
+		// Numeric user IDs
+		return fmt.Sprintf("%.0f", v)
+	default:
+		return ""
+	}
+}
+
+func stringsClaim(claims jwt.MapClaims, name string) []string {
+	switch v := getClaim(claims, name).(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		var values []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// Compile-time interface check:
+var _ Authenticator = (*OIDCAuthenticator)(nil)
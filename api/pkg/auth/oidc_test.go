@@ -0,0 +1,276 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+// fakeProvider serves the discovery document and the signing keys
+type fakeProvider struct {
+	srv       *httptest.Server
+	keys      map[string]*rsa.PrivateKey
+	jwksCalls atomic.Int32
+	// issuer reported by the discovery document, the server URL by default
+	issuer string
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	p := &fakeProvider{
+		keys: make(map[string]*rsa.PrivateKey),
+	}
+	p.addKey(t, "key-1")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		issuer := p.issuer
+		if issuer == "" {
+			issuer = p.srv.URL
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   issuer,
+			"jwks_uri": p.srv.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, _ *http.Request) {
+		p.jwksCalls.Add(1)
+
+		var keys []map[string]string
+		for kid, key := range p.keys {
+			keys = append(keys, map[string]string{
+				"kty": "RSA",
+				"kid": kid,
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+	})
+
+	p.srv = httptest.NewServer(mux)
+	t.Cleanup(p.srv.Close)
+
+	return p
+}
+
+func (p *fakeProvider) addKey(t *testing.T, kid string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	p.keys[kid] = key
+}
+
+func (p *fakeProvider) token(t *testing.T, kid string, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+
+	signed, err := token.SignedString(p.keys[kid])
+	require.NoError(t, err)
+	return signed
+}
+
+func (p *fakeProvider) claims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":                p.srv.URL,
+		"sub":                "user-1",
+		"aud":                "helix",
+		"exp":                time.Now().Add(time.Hour).Unix(),
+		"email":              "jane@example.com",
+		"name":               "Jane Doe",
+		"preferred_username": "jane",
+		"groups":             []string{"engineering", "helix-admins"},
+	}
+}
+
+func newTestOIDCConfig(issuer string) *config.OIDC {
+	return &config.OIDC{
+		Issuer:        issuer,
+		ClientID:      "helix",
+		UserIDClaim:   "sub",
+		EmailClaim:    "email",
+		NameClaim:     "name",
+		UsernameClaim: "preferred_username",
+		GroupsClaim:   "groups",
+		AdminGroup:    "helix-admins",
+		JWKSCacheTTL:  time.Hour,
+	}
+}
+
+func TestOIDCAuthenticator_GetUserFromToken(t *testing.T) {
+	provider := newFakeProvider(t)
+	storeMock := store.NewMockStore(gomock.NewController(t))
+
+	o, err := NewOIDCAuthenticator(context.Background(), newTestOIDCConfig(provider.srv.URL), storeMock)
+	require.NoError(t, err)
+
+	// First login creates the user
+	storeMock.EXPECT().GetUserMeta(gomock.Any(), "user-1").Return(nil, store.ErrNotFound)
+	storeMock.EXPECT().EnsureUserMeta(gomock.Any(), types.UserMeta{
+		ID:       "user-1",
+		Username: "jane",
+		Email:    "jane@example.com",
+		FullName: "Jane Doe",
+	}).Return(&types.UserMeta{}, nil)
+
+	user, err := o.GetUserFromToken(context.Background(), provider.token(t, "key-1", provider.claims()))
+	require.NoError(t, err)
+
+	assert.Equal(t, "user-1", user.ID)
+	assert.Equal(t, types.OwnerTypeUser, user.Type)
+	assert.Equal(t, types.TokenTypeOIDC, user.TokenType)
+	assert.Equal(t, "jane@example.com", user.Email)
+	assert.Equal(t, "Jane Doe", user.FullName)
+	assert.True(t, user.Admin)
+
+	// The profile is unchanged, nothing to save
+	storeMock.EXPECT().GetUserMeta(gomock.Any(), "user-1").Return(&types.UserMeta{
+		ID:       "user-1",
+		Username: "jane",
+		Email:    "jane@example.com",
+		FullName: "Jane Doe",
+	}, nil)
+
+	claims := provider.claims()
+	claims["groups"] = []string{"engineering"}
+
+	user, err = o.GetUserFromToken(context.Background(), provider.token(t, "key-1", claims))
+	require.NoError(t, err)
+	assert.False(t, user.Admin)
+
+	// Keys are cached
+	assert.Equal(t, int32(1), provider.jwksCalls.Load())
+}
+
+func TestOIDCAuthenticator_NestedClaims(t *testing.T) {
+	provider := newFakeProvider(t)
+	storeMock := store.NewMockStore(gomock.NewController(t))
+
+	cfg := newTestOIDCConfig(provider.srv.URL)
+	cfg.UserIDClaim = "oid"
+	cfg.GroupsClaim = "realm_access.roles"
+
+	o, err := NewOIDCAuthenticator(context.Background(), cfg, storeMock)
+	require.NoError(t, err)
+
+	storeMock.EXPECT().GetUserMeta(gomock.Any(), "oid-1").Return(&types.UserMeta{
+		ID:       "oid-1",
+		Username: "jane",
+		Email:    "jane@example.com",
+		FullName: "Jane Doe",
+		Config:   types.UserConfig{StripeCustomerID: "cus_1"},
+	}, nil)
+
+	claims := provider.claims()
+	claims["oid"] = "oid-1"
+	claims["realm_access"] = map[string]interface{}{"roles": []string{"helix-admins"}}
+
+	user, err := o.GetUserFromToken(context.Background(), provider.token(t, "key-1", claims))
+	require.NoError(t, err)
+
+	assert.Equal(t, "oid-1", user.ID)
+	assert.True(t, user.Admin)
+}
+
+func TestOIDCAuthenticator_ValidateUserToken(t *testing.T) {
+	provider := newFakeProvider(t)
+
+	o, err := NewOIDCAuthenticator(context.Background(), newTestOIDCConfig(provider.srv.URL), store.NewMockStore(gomock.NewController(t)))
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name    string
+		claims  func(jwt.MapClaims)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			claims: func(jwt.MapClaims) {},
+		},
+		{
+			name: "authorized party",
+			claims: func(c jwt.MapClaims) {
+				c["aud"] = "account"
+				c["azp"] = "helix"
+			},
+		},
+		{
+			name:    "expired",
+			claims:  func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() },
+			wantErr: "token is expired",
+		},
+		{
+			name:    "no expiry",
+			claims:  func(c jwt.MapClaims) { delete(c, "exp") },
+			wantErr: "token has no expiry",
+		},
+		{
+			name:    "other issuer",
+			claims:  func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" },
+			wantErr: "invalid issuer",
+		},
+		{
+			name:    "other client",
+			claims:  func(c jwt.MapClaims) { c["aud"] = "other" },
+			wantErr: "token was not issued for client 'helix'",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			claims := provider.claims()
+			tc.claims(claims)
+
+			_, err := o.ValidateUserToken(context.Background(), provider.token(t, "key-1", claims))
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestOIDCAuthenticator_KeyRotation(t *testing.T) {
+	provider := newFakeProvider(t)
+
+	o, err := NewOIDCAuthenticator(context.Background(), newTestOIDCConfig(provider.srv.URL), store.NewMockStore(gomock.NewController(t)))
+	require.NoError(t, err)
+
+	_, err = o.ValidateUserToken(context.Background(), provider.token(t, "key-1", provider.claims()))
+	require.NoError(t, err)
+
+	// Unknown keys are only looked up once a minute
+	provider.addKey(t, "key-2")
+
+	_, err = o.ValidateUserToken(context.Background(), provider.token(t, "key-2", provider.claims()))
+	assert.ErrorContains(t, err, "unknown signing key 'key-2'")
+
+	o.keysFetched = time.Now().Add(-2 * time.Minute)
+
+	_, err = o.ValidateUserToken(context.Background(), provider.token(t, "key-2", provider.claims()))
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), provider.jwksCalls.Load())
+}
+
+func TestNewOIDCAuthenticator_IssuerMismatch(t *testing.T) {
+	provider := newFakeProvider(t)
+	provider.issuer = "https://login.example.com"
+
+	_, err := NewOIDCAuthenticator(context.Background(), newTestOIDCConfig(provider.srv.URL), nil)
+	assert.ErrorContains(t, err, "OIDC issuer mismatch")
+}
@@ -0,0 +1,36 @@
+package apikey
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/helixml/helix/api/pkg/client"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+var rootCmd = &cobra.Command{
+	Use:     "apikey",
+	Short:   "Helix API key management",
+	Aliases: []string{"apikeys"},
+	Long:    `Create, list and revoke the API keys of your account.`,
+}
+
+func New() *cobra.Command {
+	return rootCmd
+}
+
+func lookupAPIKey(apiClient *client.HelixClient, ref string) (*types.APIKey, error) {
+	apiKeys, err := apiClient.ListAPIKeys()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+
+	for _, apiKey := range apiKeys {
+		if apiKey.Key == ref || apiKey.Name == ref {
+			return apiKey, nil
+		}
+	}
+
+	return nil, fmt.Errorf("API key not found: %s", ref)
+}
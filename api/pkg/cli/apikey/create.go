@@ -0,0 +1,70 @@
+package apikey
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/helixml/helix/api/pkg/client"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func init() {
+	createCmd.Flags().String("name", "", "Name of the API key")
+	createCmd.Flags().StringSlice("scope", []string{}, "Scopes of the API key (chat, sessions:read, knowledge:read, knowledge:admin, apps:read, apps:admin), all scopes if not set")
+	createCmd.Flags().Duration("expires", 0, "How long the API key is valid for (e.g. 720h), never expires if not set")
+	createCmd.Flags().StringSlice("allowed-ip", []string{}, "IP addresses or CIDR ranges the API key can be used from")
+	createCmd.Flags().Int("rpm", 0, "Requests per minute limit")
+	createCmd.Flags().Int("tpm", 0, "Tokens per minute limit")
+
+	rootCmd.AddCommand(createCmd)
+}
+
+var createCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a new API key",
+	Long:  ``,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		name, _ := cmd.Flags().GetString("name")
+		scopes, _ := cmd.Flags().GetStringSlice("scope")
+		expires, _ := cmd.Flags().GetDuration("expires")
+		allowedIPs, _ := cmd.Flags().GetStringSlice("allowed-ip")
+		rpm, _ := cmd.Flags().GetInt("rpm")
+		tpm, _ := cmd.Flags().GetInt("tpm")
+
+		if name == "" {
+			return fmt.Errorf("name is required")
+		}
+
+		apiClient, err := client.NewClientFromEnv()
+		if err != nil {
+			return err
+		}
+
+		req := &client.APIKeyRequest{
+			Name:              name,
+			AllowedIPs:        allowedIPs,
+			RequestsPerMinute: rpm,
+			TokensPerMinute:   tpm,
+		}
+
+		for _, scope := range scopes {
+			req.Scopes = append(req.Scopes, types.APIKeyScope(scope))
+		}
+
+		if expires > 0 {
+			expiresAt := time.Now().Add(expires)
+			req.ExpiresAt = &expiresAt
+		}
+
+		key, err := apiClient.CreateAPIKey(req)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(key)
+
+		return nil
+	},
+}
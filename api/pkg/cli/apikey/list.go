@@ -0,0 +1,88 @@
+package apikey
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/helixml/helix/api/pkg/client"
+)
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+}
+
+var listCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List API keys",
+	Long:    ``,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		apiClient, err := client.NewClientFromEnv()
+		if err != nil {
+			return err
+		}
+
+		apiKeys, err := apiClient.ListAPIKeys()
+		if err != nil {
+			return fmt.Errorf("failed to list API keys: %w", err)
+		}
+
+		table := tablewriter.NewWriter(cmd.OutOrStdout())
+
+		header := []string{"Name", "Key", "Created", "Scopes", "Expires", "Allowed IPs", "RPM", "TPM", "Last Used"}
+
+		table.SetHeader(header)
+
+		table.SetAutoWrapText(false)
+		table.SetAutoFormatHeaders(true)
+		table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		table.SetCenterSeparator("")
+		table.SetColumnSeparator("")
+		table.SetRowSeparator("")
+		table.SetHeaderLine(false)
+		table.SetBorder(false)
+		table.SetTablePadding(" ")
+		table.SetNoWhiteSpace(false)
+
+		for _, k := range apiKeys {
+			scopes := make([]string, 0, len(k.Scopes))
+			for _, scope := range k.Scopes {
+				scopes = append(scopes, string(scope))
+			}
+
+			var expiresStr string
+			if k.ExpiresAt != nil {
+				expiresStr = k.ExpiresAt.Format(time.RFC3339)
+			}
+
+			var lastUsedStr string
+			if k.LastUsed != nil {
+				lastUsedStr = k.LastUsed.Format(time.RFC3339)
+			}
+
+			row := []string{
+				k.Name,
+				k.Key,
+				k.Created.Format(time.RFC3339),
+				strings.Join(scopes, ","),
+				expiresStr,
+				strings.Join(k.AllowedIPs, ","),
+				strconv.Itoa(k.RequestsPerMinute),
+				strconv.Itoa(k.TokensPerMinute),
+				lastUsedStr,
+			}
+
+			table.Append(row)
+		}
+
+		table.Render()
+
+		return nil
+	},
+}
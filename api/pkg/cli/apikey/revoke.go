@@ -0,0 +1,43 @@
+package apikey
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/helixml/helix/api/pkg/client"
+)
+
+func init() {
+	rootCmd.AddCommand(revokeCmd)
+}
+
+var revokeCmd = &cobra.Command{
+	Use:     "revoke",
+	Aliases: []string{"rm"},
+	Short:   "Revoke an API key",
+	Long:    ``,
+	RunE: func(_ *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("API key or name is required")
+		}
+
+		apiClient, err := client.NewClientFromEnv()
+		if err != nil {
+			return err
+		}
+
+		apiKey, err := lookupAPIKey(apiClient, args[0])
+		if err != nil {
+			return err
+		}
+
+		if err := apiClient.DeleteAPIKey(apiKey.Key); err != nil {
+			return err
+		}
+
+		fmt.Printf("API key %s revoked\n", apiKey.Name)
+
+		return nil
+	},
+}
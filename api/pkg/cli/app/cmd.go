@@ -36,3 +36,18 @@ func lookupApp(apiClient *client.HelixClient, ref string) (*types.App, error) {
 
 	return nil, fmt.Errorf("app not found: %s", ref)
 }
+
+func lookupOrganization(apiClient *client.HelixClient, ref string) (string, error) {
+	orgs, err := apiClient.ListOrganizations()
+	if err != nil {
+		return "", fmt.Errorf("failed to list organizations: %w", err)
+	}
+
+	for _, org := range orgs {
+		if org.Name == ref || org.ID == ref {
+			return org.ID, nil
+		}
+	}
+
+	return "", fmt.Errorf("organization not found: %s", ref)
+}
@@ -13,6 +13,8 @@ import (
 func init() {
 	rootCmd.AddCommand(listCmd)
 
+	listCmd.Flags().String("org", "", "List the apps of the organization (name or ID)")
+
 	// Here you will define your flags and configuration settings.
 
 	// Cobra supports Persistent Flags which will work for this command
@@ -36,7 +38,20 @@ var listCmd = &cobra.Command{
 			return err
 		}
 
-		apps, err := apiClient.ListApps(&client.AppFilter{})
+		org, err := cmd.Flags().GetString("org")
+		if err != nil {
+			return err
+		}
+
+		filter := &client.AppFilter{}
+		if org != "" {
+			filter.OrgID, err = lookupOrganization(apiClient, org)
+			if err != nil {
+				return err
+			}
+		}
+
+		apps, err := apiClient.ListApps(filter)
 		if err != nil {
 			return fmt.Errorf("failed to list apps: %w", err)
 		}
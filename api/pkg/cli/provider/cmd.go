@@ -0,0 +1,36 @@
+package provider
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/helixml/helix/api/pkg/client"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+var rootCmd = &cobra.Command{
+	Use:     "provider",
+	Short:   "Helix provider endpoint management",
+	Aliases: []string{"providers"},
+	Long:    `Register OpenAI compatible endpoints (e.g. vLLM, Ollama or Azure OpenAI) that apps can use as their provider. Admins only.`,
+}
+
+func New() *cobra.Command {
+	return rootCmd
+}
+
+func lookupProviderEndpoint(apiClient *client.HelixClient, ref string) (*types.ProviderEndpoint, error) {
+	endpoints, err := apiClient.ListProviderEndpoints()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list provider endpoints: %w", err)
+	}
+
+	for _, endpoint := range endpoints {
+		if endpoint.ID == ref || endpoint.Name == ref {
+			return endpoint, nil
+		}
+	}
+
+	return nil, fmt.Errorf("provider endpoint not found: %s", ref)
+}
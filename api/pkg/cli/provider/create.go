@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/helixml/helix/api/pkg/client"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func init() {
+	createCmd.Flags().String("name", "", "Name of the provider, used as the assistant provider in apps")
+	createCmd.Flags().String("description", "", "Description of the provider")
+	createCmd.Flags().String("base-url", "", "Base URL of the OpenAI compatible API (e.g. http://vllm:8000/v1)")
+	createCmd.Flags().String("api-key", "", "API key of the endpoint")
+	createCmd.Flags().String("type", string(types.ProviderEndpointTypeOpenAI), "Type of the endpoint (openai, azure)")
+	createCmd.Flags().String("api-version", "", "API version, required for Azure endpoints")
+	createCmd.Flags().StringSlice("model", []string{}, "Models that can be used with the endpoint, all models if not set")
+	createCmd.Flags().StringSlice("header", []string{}, "Headers sent with every request, in key=value format")
+
+	rootCmd.AddCommand(createCmd)
+}
+
+var createCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Register a new provider endpoint",
+	Long:  ``,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		name, _ := cmd.Flags().GetString("name")
+		description, _ := cmd.Flags().GetString("description")
+		baseURL, _ := cmd.Flags().GetString("base-url")
+		apiKey, _ := cmd.Flags().GetString("api-key")
+		endpointType, _ := cmd.Flags().GetString("type")
+		apiVersion, _ := cmd.Flags().GetString("api-version")
+		models, _ := cmd.Flags().GetStringSlice("model")
+		headers, _ := cmd.Flags().GetStringSlice("header")
+
+		if name == "" {
+			return fmt.Errorf("name is required")
+		}
+
+		if baseURL == "" {
+			return fmt.Errorf("base URL is required")
+		}
+
+		endpoint := &types.ProviderEndpoint{
+			Name:        name,
+			Description: description,
+			Type:        types.ProviderEndpointType(endpointType),
+			BaseURL:     baseURL,
+			APIKey:      apiKey,
+			APIVersion:  apiVersion,
+			Models:      models,
+			Headers:     types.StringMap{},
+		}
+
+		for _, header := range headers {
+			k, v, ok := strings.Cut(header, "=")
+			if !ok || k == "" {
+				return fmt.Errorf("invalid header '%s', expected key=value", header)
+			}
+			endpoint.Headers[k] = v
+		}
+
+		apiClient, err := client.NewClientFromEnv()
+		if err != nil {
+			return err
+		}
+
+		created, err := apiClient.CreateProviderEndpoint(endpoint)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Provider endpoint %s created (%s)\n", created.Name, created.ID)
+
+		return nil
+	},
+}
@@ -0,0 +1,43 @@
+package provider
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/helixml/helix/api/pkg/client"
+)
+
+func init() {
+	rootCmd.AddCommand(deleteCmd)
+}
+
+var deleteCmd = &cobra.Command{
+	Use:     "delete",
+	Aliases: []string{"rm"},
+	Short:   "Delete a provider endpoint",
+	Long:    ``,
+	RunE: func(_ *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("provider endpoint ID or name is required")
+		}
+
+		apiClient, err := client.NewClientFromEnv()
+		if err != nil {
+			return err
+		}
+
+		endpoint, err := lookupProviderEndpoint(apiClient, args[0])
+		if err != nil {
+			return err
+		}
+
+		if err := apiClient.DeleteProviderEndpoint(endpoint.ID); err != nil {
+			return err
+		}
+
+		fmt.Printf("Provider endpoint %s deleted\n", endpoint.Name)
+
+		return nil
+	},
+}
@@ -0,0 +1,35 @@
+package session
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/helixml/helix/api/pkg/client"
+)
+
+var rootCmd = &cobra.Command{
+	Use:     "session",
+	Short:   "Helix session management",
+	Aliases: []string{"sessions"},
+	Long:    `Search, export and import your sessions.`,
+}
+
+func New() *cobra.Command {
+	return rootCmd
+}
+
+func lookupOrganization(apiClient *client.HelixClient, ref string) (string, error) {
+	orgs, err := apiClient.ListOrganizations()
+	if err != nil {
+		return "", fmt.Errorf("failed to list organizations: %w", err)
+	}
+
+	for _, org := range orgs {
+		if org.Name == ref || org.ID == ref {
+			return org.ID, nil
+		}
+	}
+
+	return "", fmt.Errorf("organization not found: %s", ref)
+}
@@ -0,0 +1,79 @@
+package session
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/helixml/helix/api/pkg/client"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func init() {
+	exportCmd.Flags().StringP("output", "o", "", "Output file, defaults to <session id>.tar (or .json, .md), - for stdout")
+	exportCmd.Flags().String("format", string(types.SessionExportFormatTar), "Export format: tar (session, transcript and files), json or markdown")
+
+	rootCmd.AddCommand(exportCmd)
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export <session id>",
+	Short: "Export a session",
+	Long: `Export a session as a self-contained bundle that can be imported into
+any Helix instance with "helix session import". The markdown format is a
+transcript of the conversation, e.g. to attach to bug reports.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		apiClient, err := client.NewClientFromEnv()
+		if err != nil {
+			return err
+		}
+
+		formatStr, _ := cmd.Flags().GetString("format")
+		format := types.SessionExportFormat(formatStr)
+
+		var extension string
+		switch format {
+		case types.SessionExportFormatTar:
+			extension = "tar"
+		case types.SessionExportFormatJSON:
+			extension = "json"
+		case types.SessionExportFormatMarkdown:
+			extension = "md"
+		default:
+			return fmt.Errorf("unknown format '%s', expected tar, json or markdown", format)
+		}
+
+		output, _ := cmd.Flags().GetString("output")
+		if output == "" {
+			output = args[0] + "." + extension
+		}
+
+		r, err := apiClient.ExportSession(cmd.Context(), args[0], format)
+		if err != nil {
+			return fmt.Errorf("failed to export session: %w", err)
+		}
+		defer r.Close()
+
+		if output == "-" {
+			_, err = io.Copy(cmd.OutOrStdout(), r)
+			return err
+		}
+
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		if _, err := io.Copy(f, r); err != nil {
+			return fmt.Errorf("failed to write %s: %w", output, err)
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Session %s exported to %s\n", args[0], output)
+
+		return nil
+	},
+}
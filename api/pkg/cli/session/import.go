@@ -0,0 +1,53 @@
+package session
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/helixml/helix/api/pkg/client"
+)
+
+func init() {
+	importCmd.Flags().String("org", "", "Import the session into the organization (name or ID)")
+
+	rootCmd.AddCommand(importCmd)
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import a session",
+	Long:  `Import a session exported with "helix session export", as a tar bundle or JSON. The session gets a new ID.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		apiClient, err := client.NewClientFromEnv()
+		if err != nil {
+			return err
+		}
+
+		var orgID string
+		org, _ := cmd.Flags().GetString("org")
+		if org != "" {
+			orgID, err = lookupOrganization(apiClient, org)
+			if err != nil {
+				return err
+			}
+		}
+
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		session, err := apiClient.ImportSession(cmd.Context(), f, orgID)
+		if err != nil {
+			return fmt.Errorf("failed to import session: %w", err)
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Session imported as %s\n", session.ID)
+
+		return nil
+	},
+}
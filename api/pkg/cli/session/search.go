@@ -0,0 +1,104 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/helixml/helix/api/pkg/client"
+)
+
+func init() {
+	searchCmd.Flags().String("org", "", "Search the sessions of the organization (name or ID)")
+	searchCmd.Flags().String("app", "", "Only sessions of the app")
+	searchCmd.Flags().String("model", "", "Only sessions using the model")
+	searchCmd.Flags().String("mode", "", "Only sessions in the mode (inference, finetune, action)")
+	searchCmd.Flags().String("from", "", "Messages sent on or after (YYYY-MM-DD or RFC 3339)")
+	searchCmd.Flags().String("to", "", "Messages sent before (YYYY-MM-DD or RFC 3339)")
+	searchCmd.Flags().Int("offset", 0, "Skip the first results")
+	searchCmd.Flags().Int("limit", 20, "Maximum number of results, at most 100")
+
+	rootCmd.AddCommand(searchCmd)
+}
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search the messages of your sessions",
+	Long: `Full text search over the messages of your sessions, best matches first.
+Quoted phrases, "or" and -excluded words are supported, e.g.
+
+  helix session search '"billing api" -stripe'`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		apiClient, err := client.NewClientFromEnv()
+		if err != nil {
+			return err
+		}
+
+		filter := &client.SessionSearchFilter{
+			Query: strings.Join(args, " "),
+		}
+		filter.AppID, _ = cmd.Flags().GetString("app")
+		filter.Model, _ = cmd.Flags().GetString("model")
+		filter.Mode, _ = cmd.Flags().GetString("mode")
+		filter.From, _ = cmd.Flags().GetString("from")
+		filter.To, _ = cmd.Flags().GetString("to")
+		filter.Offset, _ = cmd.Flags().GetInt("offset")
+		filter.Limit, _ = cmd.Flags().GetInt("limit")
+
+		org, _ := cmd.Flags().GetString("org")
+		if org != "" {
+			filter.OrgID, err = lookupOrganization(apiClient, org)
+			if err != nil {
+				return err
+			}
+		}
+
+		results, err := apiClient.SearchSessions(filter)
+		if err != nil {
+			return fmt.Errorf("failed to search sessions: %w", err)
+		}
+
+		table := tablewriter.NewWriter(cmd.OutOrStdout())
+
+		header := []string{"Session", "Name", "Interaction", "Created", "Snippet"}
+
+		table.SetHeader(header)
+
+		table.SetAutoWrapText(false)
+		table.SetAutoFormatHeaders(true)
+		table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		table.SetCenterSeparator("")
+		table.SetColumnSeparator("")
+		table.SetRowSeparator("")
+		table.SetHeaderLine(false)
+		table.SetBorder(false)
+		table.SetTablePadding(" ")
+		table.SetNoWhiteSpace(false)
+
+		for _, r := range results.Results {
+			row := []string{
+				r.SessionID,
+				r.SessionName,
+				r.InteractionID,
+				r.Created.Format(time.RFC3339),
+				// Snippets span several lines, keep one row per result
+				strings.Join(strings.Fields(r.Snippet), " "),
+			}
+
+			table.Append(row)
+		}
+
+		table.Render()
+
+		if results.Counter != nil && int(results.Counter.Count) > filter.Offset+len(results.Results) {
+			fmt.Fprintf(cmd.OutOrStdout(), "\nShowing %d of %d results, use --offset to see more\n", len(results.Results), results.Counter.Count)
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,131 @@
+package usage
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/helixml/helix/api/pkg/client"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func init() {
+	rootCmd.Flags().String("from", "", "First day (YYYY-MM-DD), defaults to the start of the month")
+	rootCmd.Flags().String("to", "", "Last day (YYYY-MM-DD), defaults to today")
+	rootCmd.Flags().String("org", "", "Usage of the organization (name or ID)")
+	rootCmd.Flags().String("app", "", "Usage of the app, the app owners see the usage of all its users")
+	rootCmd.Flags().String("api-key", "", "Usage of the API key, the key or its ID")
+	rootCmd.Flags().String("provider", "", "Usage of the provider")
+	rootCmd.Flags().String("model", "", "Usage of the model")
+	rootCmd.Flags().Bool("all", false, "Usage of all users, admins only")
+}
+
+var rootCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Show the token usage and cost",
+	Long:  `Show the daily token usage and cost by app, API key, provider and model.`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		apiClient, err := client.NewClientFromEnv()
+		if err != nil {
+			return err
+		}
+
+		filter := &client.UsageFilter{}
+		filter.From, _ = cmd.Flags().GetString("from")
+		filter.To, _ = cmd.Flags().GetString("to")
+		filter.AppID, _ = cmd.Flags().GetString("app")
+		filter.APIKeyID, _ = cmd.Flags().GetString("api-key")
+		if strings.HasPrefix(filter.APIKeyID, types.API_KEY_PREIX) {
+			// The usage only has the IDs of the keys
+			filter.APIKeyID = types.APIKeyID(filter.APIKeyID)
+		}
+		filter.Provider, _ = cmd.Flags().GetString("provider")
+		filter.Model, _ = cmd.Flags().GetString("model")
+		filter.All, _ = cmd.Flags().GetBool("all")
+
+		org, _ := cmd.Flags().GetString("org")
+		if org != "" {
+			filter.OrgID, err = lookupOrganization(apiClient, org)
+			if err != nil {
+				return err
+			}
+		}
+
+		report, err := apiClient.GetUsage(filter)
+		if err != nil {
+			return fmt.Errorf("failed to get usage: %w", err)
+		}
+
+		table := tablewriter.NewWriter(cmd.OutOrStdout())
+
+		header := []string{"Date", "Owner", "App", "API Key ID", "Provider", "Model", "Requests", "Prompt", "Completion", "Total", "Cost"}
+
+		table.SetHeader(header)
+
+		table.SetAutoWrapText(false)
+		table.SetAutoFormatHeaders(true)
+		table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		table.SetCenterSeparator("")
+		table.SetColumnSeparator("")
+		table.SetRowSeparator("")
+		table.SetHeaderLine(false)
+		table.SetBorder(false)
+		table.SetTablePadding(" ")
+		table.SetNoWhiteSpace(false)
+
+		for _, u := range report.Usage {
+			row := []string{
+				u.Date.Format(time.DateOnly),
+				u.Owner,
+				u.AppID,
+				u.APIKeyID,
+				u.Provider,
+				u.Model,
+				strconv.FormatInt(u.Requests, 10),
+				strconv.FormatInt(u.PromptTokens, 10),
+				strconv.FormatInt(u.CompletionTokens, 10),
+				strconv.FormatInt(u.TotalTokens, 10),
+				fmt.Sprintf("$%.4f", u.Cost),
+			}
+
+			table.Append(row)
+		}
+
+		table.SetFooter([]string{
+			"Total", "", "", "", "", "",
+			strconv.FormatInt(report.Totals.Requests, 10),
+			strconv.FormatInt(report.Totals.PromptTokens, 10),
+			strconv.FormatInt(report.Totals.CompletionTokens, 10),
+			strconv.FormatInt(report.Totals.TotalTokens, 10),
+			fmt.Sprintf("$%.4f", report.Totals.Cost),
+		})
+
+		table.Render()
+
+		return nil
+	},
+}
+
+func New() *cobra.Command {
+	return rootCmd
+}
+
+func lookupOrganization(apiClient *client.HelixClient, ref string) (string, error) {
+	orgs, err := apiClient.ListOrganizations()
+	if err != nil {
+		return "", fmt.Errorf("failed to list organizations: %w", err)
+	}
+
+	for _, org := range orgs {
+		if org.Name == ref || org.ID == ref {
+			return org.ID, nil
+		}
+	}
+
+	return "", fmt.Errorf("organization not found: %s", ref)
+}
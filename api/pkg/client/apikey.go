@@ -0,0 +1,60 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+// APIKeyRequest is the name and the restrictions of a new API key
+type APIKeyRequest struct {
+	Name              string              `json:"name"`
+	Type              types.APIKeyType    `json:"type"`
+	Scopes            []types.APIKeyScope `json:"scopes,omitempty"`
+	ExpiresAt         *time.Time          `json:"expires_at,omitempty"`
+	AllowedIPs        []string            `json:"allowed_ips,omitempty"`
+	RequestsPerMinute int                 `json:"requests_per_minute,omitempty"`
+	TokensPerMinute   int                 `json:"tokens_per_minute,omitempty"`
+}
+
+// CreateAPIKey creates the key and returns it
+func (c *HelixClient) CreateAPIKey(r *APIKeyRequest) (string, error) {
+	if r.Type == "" {
+		r.Type = types.APIKeyType_API
+	}
+
+	bts, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+
+	var key string
+	err = c.makeRequest(http.MethodPost, "/api_keys", bytes.NewBuffer(bts), &key)
+	if err != nil {
+		return "", fmt.Errorf("failed to create API key, %w", err)
+	}
+
+	return key, nil
+}
+
+func (c *HelixClient) ListAPIKeys() ([]*types.APIKey, error) {
+	var apiKeys []*types.APIKey
+	err := c.makeRequest(http.MethodGet, "/api_keys?types="+string(types.APIKeyType_API), nil, &apiKeys)
+	if err != nil {
+		return nil, err
+	}
+	return apiKeys, nil
+}
+
+func (c *HelixClient) DeleteAPIKey(key string) error {
+	err := c.makeRequest(http.MethodDelete, "/api_keys?key="+url.QueryEscape(key), nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete API key, %w", err)
+	}
+	return nil
+}
@@ -11,11 +11,17 @@ import (
 )
 
 type AppFilter struct {
+	OrgID string // Lists the apps of the organization instead of the user's
 }
 
 func (c *HelixClient) ListApps(f *AppFilter) ([]*types.App, error) {
+	path := "/apps"
+	if f.OrgID != "" {
+		path += "?org_id=" + url.QueryEscape(f.OrgID)
+	}
+
 	var apps []*types.App
-	err := c.makeRequest(http.MethodGet, "/apps", nil, &apps)
+	err := c.makeRequest(http.MethodGet, path, nil, &apps)
 	if err != nil {
 		return nil, err
 	}
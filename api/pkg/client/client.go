@@ -29,6 +29,23 @@ type Client interface {
 
 	ListKnowledgeVersions(f *KnowledgeVersionsFilter) ([]*types.KnowledgeVersion, error)
 
+	ListOrganizations() ([]*types.Organization, error)
+
+	CreateAPIKey(r *APIKeyRequest) (string, error)
+	ListAPIKeys() ([]*types.APIKey, error)
+	DeleteAPIKey(key string) error
+
+	GetUsage(f *UsageFilter) (*types.UsageReport, error)
+
+	SearchSessions(f *SessionSearchFilter) (*types.SessionSearchResults, error)
+	ExportSession(ctx context.Context, id string, format types.SessionExportFormat) (io.ReadCloser, error)
+	ImportSession(ctx context.Context, r io.Reader, orgID string) (*types.Session, error)
+
+	ListProviderEndpoints() ([]*types.ProviderEndpoint, error)
+	CreateProviderEndpoint(endpoint *types.ProviderEndpoint) (*types.ProviderEndpoint, error)
+	UpdateProviderEndpoint(endpoint *types.ProviderEndpoint) (*types.ProviderEndpoint, error)
+	DeleteProviderEndpoint(id string) error
+
 	FilestoreList(ctx context.Context, path string) ([]filestore.FileStoreItem, error)
 	FilestoreUpload(ctx context.Context, path string, file io.Reader) error
 	FilestoreDelete(ctx context.Context, path string) error
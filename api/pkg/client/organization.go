@@ -0,0 +1,16 @@
+package client
+
+import (
+	"net/http"
+
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func (c *HelixClient) ListOrganizations() ([]*types.Organization, error) {
+	var orgs []*types.Organization
+	err := c.makeRequest(http.MethodGet, "/organizations", nil, &orgs)
+	if err != nil {
+		return nil, err
+	}
+	return orgs, nil
+}
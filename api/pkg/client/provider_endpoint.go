@@ -0,0 +1,57 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func (c *HelixClient) ListProviderEndpoints() ([]*types.ProviderEndpoint, error) {
+	var endpoints []*types.ProviderEndpoint
+	err := c.makeRequest(http.MethodGet, "/provider_endpoints", nil, &endpoints)
+	if err != nil {
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+func (c *HelixClient) CreateProviderEndpoint(endpoint *types.ProviderEndpoint) (*types.ProviderEndpoint, error) {
+	bts, err := json.Marshal(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var created types.ProviderEndpoint
+	err = c.makeRequest(http.MethodPost, "/provider_endpoints", bytes.NewBuffer(bts), &created)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create provider endpoint, %w", err)
+	}
+
+	return &created, nil
+}
+
+func (c *HelixClient) UpdateProviderEndpoint(endpoint *types.ProviderEndpoint) (*types.ProviderEndpoint, error) {
+	bts, err := json.Marshal(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated types.ProviderEndpoint
+	err = c.makeRequest(http.MethodPut, "/provider_endpoints/"+endpoint.ID, bytes.NewBuffer(bts), &updated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update provider endpoint, %w", err)
+	}
+
+	return &updated, nil
+}
+
+func (c *HelixClient) DeleteProviderEndpoint(id string) error {
+	err := c.makeRequest(http.MethodDelete, "/provider_endpoints/"+id, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete provider endpoint, %w", err)
+	}
+	return nil
+}
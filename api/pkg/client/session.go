@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+type SessionSearchFilter struct {
+	Query  string
+	OrgID  string
+	AppID  string
+	Model  string
+	Mode   string
+	From   string // RFC 3339 or YYYY-MM-DD
+	To     string // RFC 3339 or YYYY-MM-DD
+	Offset int
+	Limit  int
+}
+
+func (c *HelixClient) SearchSessions(f *SessionSearchFilter) (*types.SessionSearchResults, error) {
+	query := url.Values{}
+	query.Set("q", f.Query)
+	for k, v := range map[string]string{
+		"org_id": f.OrgID,
+		"app_id": f.AppID,
+		"model":  f.Model,
+		"mode":   f.Mode,
+		"from":   f.From,
+		"to":     f.To,
+	} {
+		if v != "" {
+			query.Set(k, v)
+		}
+	}
+	if f.Offset > 0 {
+		query.Set("offset", strconv.Itoa(f.Offset))
+	}
+	if f.Limit > 0 {
+		query.Set("limit", strconv.Itoa(f.Limit))
+	}
+
+	var results types.SessionSearchResults
+	err := c.makeRequest(http.MethodGet, "/sessions/search?"+query.Encode(), nil, &results)
+	if err != nil {
+		return nil, err
+	}
+	return &results, nil
+}
+
+// ExportSession downloads the session export, the caller closes the reader.
+// Bundles can be large, so the request is bound by the context only.
+func (c *HelixClient) ExportSession(ctx context.Context, id string, format types.SessionExportFormat) (io.ReadCloser, error) {
+	path := "/sessions/" + url.PathEscape(id) + "/export"
+	if format != "" {
+		path += "?format=" + url.QueryEscape(string(format))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		bts, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("status code %d (%s)", resp.StatusCode, string(bts))
+	}
+
+	return resp.Body, nil
+}
+
+// ImportSession uploads a session export, a tar bundle or JSON
+func (c *HelixClient) ImportSession(ctx context.Context, r io.Reader, orgID string) (*types.Session, error) {
+	path := "/sessions/import"
+	if orgID != "" {
+		path += "?org_id=" + url.QueryEscape(orgID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+path, r)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-tar")
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bts, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("status code %d (%s)", resp.StatusCode, string(bts))
+	}
+
+	var session types.Session
+	if err := json.NewDecoder(resp.Body).Decode(&session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
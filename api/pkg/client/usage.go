@@ -0,0 +1,51 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+type UsageFilter struct {
+	From     string // YYYY-MM-DD, defaults to the start of the month
+	To       string // YYYY-MM-DD, defaults to today
+	OrgID    string
+	AppID    string
+	APIKeyID string // See types.APIKeyID
+	Provider string
+	Model    string
+	All      bool // Usage of all users, admins only
+}
+
+func (c *HelixClient) GetUsage(f *UsageFilter) (*types.UsageReport, error) {
+	query := url.Values{}
+	for k, v := range map[string]string{
+		"from":       f.From,
+		"to":         f.To,
+		"org_id":     f.OrgID,
+		"app_id":     f.AppID,
+		"api_key_id": f.APIKeyID,
+		"provider":   f.Provider,
+		"model":      f.Model,
+	} {
+		if v != "" {
+			query.Set(k, v)
+		}
+	}
+	if f.All {
+		query.Set("all", "true")
+	}
+
+	path := "/usage"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var report types.UsageReport
+	err := c.makeRequest(http.MethodGet, path, nil, &report)
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
@@ -11,6 +11,7 @@ type ServerConfig struct {
 	Inference          Inference
 	Providers          Providers
 	Tools              Tools
+	Auth               Auth
 	Keycloak           Keycloak
 	Notifications      Notifications
 	Janitor            Janitor
@@ -24,6 +25,8 @@ type ServerConfig struct {
 	PubSub             PubSub
 	WebServer          WebServer
 	SubscriptionQuotas SubscriptionQuotas
+	Usage              Usage
+	Cache              Cache
 	GitHub             GitHub
 	FineTuning         FineTuning
 	Apps               Apps
@@ -49,6 +52,10 @@ type Providers struct {
 	OpenAI     OpenAI
 	TogetherAI TogetherAI
 	Helix      Helix
+	// EncryptionKey encrypts the API keys of the provider endpoints registered at runtime
+	EncryptionKey string `envconfig:"PROVIDERS_ENCRYPTION_KEY" description:"Key to encrypt the API keys of the provider endpoints."`
+	// RoutingFile is a YAML list of the routing policies, see manager.RoutingPolicy
+	RoutingFile string `envconfig:"PROVIDERS_ROUTING_FILE" description:"Path to the YAML file with the provider fallback and load-balancing policies."`
 }
 
 type OpenAI struct {
@@ -67,6 +74,12 @@ type Helix struct {
 	ModelTTL           time.Duration `envconfig:"HELIX_MODEL_TTL" default:"10s"`                          // How long to keep models warm before allowing other work to be scheduled
 	RunnerTTL          time.Duration `envconfig:"HELIX_RUNNER_TTL" default:"30s"`                         // How long before runners are considered dead
 	SchedulingStrategy string        `envconfig:"HELIX_SCHEDULING_STRATEGY" default:"max_spread" description:"The strategy to use for scheduling workloads."`
+
+	// Priority classes map the class names to their weights, owners get a share of the runners
+	// proportional to the weight of their work. API keys and apps can set a class.
+	PriorityClasses      map[string]int `envconfig:"HELIX_PRIORITY_CLASSES" default:"high:8,normal:4,low:1" description:"The priority classes and their weights."`
+	DefaultPriorityClass string         `envconfig:"HELIX_DEFAULT_PRIORITY_CLASS" default:"normal" description:"The priority class of the work that doesn't set one."`
+	Preemption           bool           `envconfig:"HELIX_PREEMPTION" default:"false" description:"Preempt lower priority fine-tuning when inference can't be placed."`
 }
 
 type Tools struct {
@@ -83,6 +96,30 @@ type Tools struct {
 	IsActionableTemplate string `envconfig:"TOOLS_IS_ACTIONABLE_TEMPLATE"` // Either plain text, base64 or path to a file
 }
 
+// Auth selects how the user tokens are validated, API keys and the runner
+// token are handled by Helix itself
+type Auth struct {
+	Provider types.AuthProvider `envconfig:"AUTH_PROVIDER" default:"keycloak" description:"One of keycloak or oidc"`
+	OIDC     OIDC
+}
+
+// OIDC authenticates the users with any OpenID Connect provider. The users are
+// created in Helix on their first login, the claims can be nested (e.g. realm_access.roles)
+type OIDC struct {
+	Issuer        string        `envconfig:"OIDC_ISSUER" description:"The issuer URL, the provider configuration is discovered from it."`
+	ClientID      string        `envconfig:"OIDC_CLIENT_ID" description:"The tokens must be issued for this client (aud or azp claim)."`
+	UserIDClaim   string        `envconfig:"OIDC_USER_ID_CLAIM" default:"sub"`
+	EmailClaim    string        `envconfig:"OIDC_EMAIL_CLAIM" default:"email"`
+	NameClaim     string        `envconfig:"OIDC_NAME_CLAIM" default:"name"`
+	UsernameClaim string        `envconfig:"OIDC_USERNAME_CLAIM" default:"preferred_username"`
+	GroupsClaim   string        `envconfig:"OIDC_GROUPS_CLAIM" default:"groups"`
+	AdminGroup    string        `envconfig:"OIDC_ADMIN_GROUP" description:"Members of this group are admins when using their login tokens."`
+	JWKSCacheTTL  time.Duration `envconfig:"OIDC_JWKS_CACHE_TTL" default:"1h"`
+	// The web UI logs in with the authorization code flow and PKCE, the client
+	// must be a public client that allows the Helix URL as a redirect URI
+	Scopes string `envconfig:"OIDC_SCOPES" default:"openid profile email offline_access" description:"The scopes the web UI asks for, space separated."`
+}
+
 // Keycloak is used for authentication. You can find keycloak documentation
 // at https://www.keycloak.org/guides
 type Keycloak struct {
@@ -161,20 +198,42 @@ type TextExtractor struct {
 	}
 }
 
+// KnowledgeBuckets are the S3 and GCS buckets that users can index, none are
+// allowed by default
+type KnowledgeBuckets struct {
+	S3          []string `envconfig:"RAG_S3_ALLOWED_BUCKETS" description:"The S3 buckets that knowledge can be indexed from."`
+	S3Endpoints []string `envconfig:"RAG_S3_ALLOWED_ENDPOINTS" description:"The S3 compatible endpoints that knowledge can be indexed from, only AWS is allowed if empty."`
+	GCS         []string `envconfig:"RAG_GCS_ALLOWED_BUCKETS" description:"The GCS buckets that knowledge can be indexed from."`
+}
+
 type RAG struct {
 	IndexingConcurrency int `envconfig:"RAG_INDEXING_CONCURRENCY" default:"1" description:"The number of concurrent indexing tasks."`
 
 	// DefaultRagProvider is the default RAG provider to use if not specified
-	DefaultRagProvider string `envconfig:"RAG_DEFAULT_PROVIDER" default:"typesense" description:"The default RAG provider to use if not specified."`
+	DefaultRagProvider string `envconfig:"RAG_DEFAULT_PROVIDER" default:"typesense" description:"The default RAG provider to use if not specified (typesense, llamaindex or pgvector)."`
 
 	MaxVersions int `envconfig:"RAG_MAX_VERSIONS" default:"3" description:"The maximum number of versions to keep for a knowledge."`
 
+	MaxSourceObjectSize int64 `envconfig:"RAG_MAX_SOURCE_OBJECT_SIZE" default:"52428800" description:"The maximum size of a bucket object or repository file to index, larger ones are skipped."`
+
+	// Buckets limits the buckets knowledge can be indexed from, they are read
+	// with the credentials of the control plane
+	Buckets KnowledgeBuckets
+
 	// Typesense is used to store RAG records in a Typesense index
 	Typesense struct {
 		URL    string `envconfig:"RAG_TYPESENSE_URL" default:"http://typesense:8108" description:"The URL to the Typesense server."`
 		APIKey string `envconfig:"RAG_TYPESENSE_API_KEY" default:"typesense" description:"The API key to the Typesense server."`
 	}
 
+	// PGVector stores RAG records in the main Postgres database, it needs
+	// the vector extension to be installed
+	PGVector struct {
+		// Provider computes the embeddings, any provider with an OpenAI compatible embeddings API
+		Provider        string `envconfig:"RAG_PGVECTOR_PROVIDER" default:"helix" description:"The provider to compute the embeddings with."`
+		EmbeddingsModel string `envconfig:"RAG_PGVECTOR_EMBEDDINGS_MODEL" default:"nomic-embed-text:v1.5" description:"The model to compute the embeddings with."`
+	}
+
 	Llamaindex struct {
 		// the URL we can post a chunk of text to for RAG indexing
 		RAGIndexingURL string `envconfig:"RAG_INDEX_URL" default:"http://llamaindex:5000/api/v1/rag/chunk" description:"The URL to index text with RAG."`
@@ -210,11 +269,18 @@ type Controller struct {
 }
 
 type FileStore struct {
-	Type         types.FileStoreType `envconfig:"FILESTORE_TYPE" default:"fs" description:"What type of filestore should we use (fs | gcs)."`
+	Type         types.FileStoreType `envconfig:"FILESTORE_TYPE" default:"fs" description:"What type of filestore should we use (fs | gcs | s3)."`
 	LocalFSPath  string              `envconfig:"FILESTORE_LOCALFS_PATH" default:"/tmp/helix/filestore" description:"The local path that is the root for the local fs filestore."`
 	GCSKeyBase64 string              `envconfig:"FILESTORE_GCS_KEY_BASE64" description:"The base64 encoded service account json file for GCS."`
 	GCSKeyFile   string              `envconfig:"FILESTORE_GCS_KEY_FILE" description:"The local path to the service account json file for GCS."`
 	GCSBucket    string              `envconfig:"FILESTORE_GCS_BUCKET" description:"The bucket we are storing things in GCS."`
+
+	// S3 credentials are read from the standard AWS_* variables or the IAM role
+	S3Bucket       string `envconfig:"FILESTORE_S3_BUCKET" description:"The bucket we are storing things in S3."`
+	S3Region       string `envconfig:"FILESTORE_S3_REGION" description:"The region of the S3 bucket."`
+	S3Endpoint     string `envconfig:"FILESTORE_S3_ENDPOINT" description:"The endpoint of S3 compatible services such as MinIO, empty for AWS."`
+	S3UsePathStyle bool   `envconfig:"FILESTORE_S3_USE_PATH_STYLE" default:"false" description:"Use path style bucket URLs, needed by most S3 compatible services."`
+	S3PartSizeMB   int    `envconfig:"FILESTORE_S3_PART_SIZE_MB" default:"16" description:"The part size of multipart uploads to S3, at least 5MB."`
 }
 
 type PubSub struct {
@@ -246,6 +312,9 @@ type WebServer struct {
 	// a list of keycloak ids that are considered admins
 	// if the string '*' is included it means ALL users
 	AdminIDs []string `envconfig:"ADMIN_USER_IDS" description:"Keycloak admin IDs."`
+	// the proxies in front of the api server, used to find the client address
+	// of the requests for the API key IP allowlists
+	TrustedProxies []string `envconfig:"TRUSTED_PROXIES" description:"IP addresses or CIDR ranges of the proxies in front of the api server, X-Forwarded-For is trusted for their requests."`
 	// if this is specified then we provide the option to clone entire
 	// sessions into this user without having to logout and login
 	EvalUserID string `envconfig:"EVAL_USER_ID" description:""`
@@ -274,6 +343,30 @@ type SubscriptionQuotas struct {
 	}
 }
 
+// Usage configures the cost of the LLM calls and the monthly budgets, zero
+// budgets are unlimited
+type Usage struct {
+	// PricingFile is a YAML list of the model prices in USD per million tokens
+	PricingFile string `envconfig:"USAGE_PRICING_FILE" description:"YAML file with the prices of the models."`
+	// Budgets of each user and organization, apps can set their own budgets
+	MonthlyTokenBudget int64   `envconfig:"USAGE_MONTHLY_TOKEN_BUDGET" default:"0" description:"Monthly token budget of each user and organization."`
+	MonthlyCostBudget  float64 `envconfig:"USAGE_MONTHLY_COST_BUDGET" default:"0" description:"Monthly cost budget in USD of each user and organization."`
+}
+
+// Cache is the opt-in cache of the chat completion responses, the responses
+// are cached per owner and app for identical requests
+type Cache struct {
+	Enabled    bool          `envconfig:"CACHE_ENABLED" default:"false" description:"Cache the chat completion responses."`
+	TTL        time.Duration `envconfig:"CACHE_TTL" default:"1h" description:"How long the responses are cached, apps can set their own TTL."`
+	MaxEntries int           `envconfig:"CACHE_MAX_ENTRIES" default:"10000" description:"Max number of cached responses."`
+	// The semantic cache also returns the response of a request when the
+	// last message is similar enough to the one of a cached request
+	SemanticEnabled    bool    `envconfig:"CACHE_SEMANTIC_ENABLED" default:"false" description:"Match similar requests by the embeddings of the last message."`
+	SemanticThreshold  float64 `envconfig:"CACHE_SEMANTIC_THRESHOLD" default:"0.95" description:"Min cosine similarity of a semantic cache hit."`
+	EmbeddingsProvider string  `envconfig:"CACHE_EMBEDDINGS_PROVIDER" default:"openai" description:"Provider of the semantic cache embeddings."`
+	EmbeddingsModel    string  `envconfig:"CACHE_EMBEDDINGS_MODEL" default:"text-embedding-3-small" description:"Model of the semantic cache embeddings."`
+}
+
 type GitHub struct {
 	Enabled      bool   `envconfig:"GITHUB_INTEGRATION_ENABLED" default:"false" description:"Enable github integration."`
 	ClientID     string `envconfig:"GITHUB_INTEGRATION_CLIENT_ID" description:"The github app client id."`
@@ -313,6 +406,7 @@ type GPTScript struct {
 
 type Triggers struct {
 	Discord Discord
+	Slack   Slack
 	Cron    Cron
 }
 
@@ -321,6 +415,14 @@ type Discord struct {
 	BotToken string `envconfig:"DISCORD_BOT_TOKEN"`
 }
 
+// Slack bot connects with Socket Mode, the Slack app needs the app_mentions:read,
+// chat:write, channels:history, groups:history and im:history scopes
+type Slack struct {
+	Enabled  bool   `envconfig:"SLACK_ENABLED" default:"false"`
+	AppToken string `envconfig:"SLACK_APP_TOKEN" description:"App-level token (xapp-) with the connections:write scope."`
+	BotToken string `envconfig:"SLACK_BOT_TOKEN" description:"Bot user OAuth token (xoxb-)."`
+}
+
 type Cron struct {
 	Enabled bool `envconfig:"CRON_ENABLED" default:"true"`
 }
@@ -18,6 +18,20 @@ type GPTScriptRunnerConfig struct {
 	// Exit after executing this many tasks. Useful when
 	// GPTScript is run as a one-off task.
 	MaxTasks int `envconfig:"MAX_TASKS" default:"1"`
+
+	CodeSandbox CodeSandbox
+}
+
+// CodeSandbox runs the code tools in containers without network access (unless
+// the tool allows it), with the CPU, memory and time limits of the tool
+type CodeSandbox struct {
+	Runtime         string `envconfig:"CODE_SANDBOX_RUNTIME" default:"docker" description:"Container CLI that runs the code, docker or podman."`
+	OCIRuntime      string `envconfig:"CODE_SANDBOX_OCI_RUNTIME" description:"OCI runtime of the containers, e.g. runsc for gVisor. Defaults to the runtime default."`
+	PythonImage     string `envconfig:"CODE_SANDBOX_PYTHON_IMAGE" default:"python:3.12-slim"`
+	JavaScriptImage string `envconfig:"CODE_SANDBOX_JAVASCRIPT_IMAGE" default:"node:20-slim"`
+	MaxOutputBytes  int    `envconfig:"CODE_SANDBOX_MAX_OUTPUT_BYTES" default:"1048576" description:"Stdout and stderr are truncated to this size."`
+	MaxFilesBytes   int64  `envconfig:"CODE_SANDBOX_MAX_FILES_BYTES" default:"10485760" description:"Total size of the files returned from the scratch directory."`
+	WorkspaceSizeMB int    `envconfig:"CODE_SANDBOX_WORKSPACE_SIZE_MB" default:"256" description:"Size of the in-memory scratch directory of the code."`
 }
 
 func LoadGPTScriptRunnerConfig() (GPTScriptRunnerConfig, error) {
@@ -0,0 +1,24 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	oai "github.com/helixml/helix/api/pkg/openai"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+// withCacheOptions sets the TTL of the cached responses of the app, a
+// negative TTL opts the app out of the response cache
+func withCacheOptions(ctx context.Context, app *types.App) context.Context {
+	if app == nil || app.Config.Helix.CacheTTL == 0 {
+		return ctx
+	}
+
+	ttl := time.Duration(app.Config.Helix.CacheTTL)
+	if ttl < 0 {
+		return oai.SetCacheOptions(ctx, &oai.CacheOptions{Disabled: true})
+	}
+
+	return oai.SetCacheOptions(ctx, &oai.CacheOptions{TTL: ttl})
+}
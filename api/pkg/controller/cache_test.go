@@ -0,0 +1,31 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	oai "github.com/helixml/helix/api/pkg/openai"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func TestWithCacheOptions(t *testing.T) {
+	ctx := withCacheOptions(context.Background(), nil)
+	_, ok := oai.GetCacheOptions(ctx)
+	assert.False(t, ok)
+
+	app := &types.App{}
+	app.Config.Helix.CacheTTL = types.Duration(10 * time.Minute)
+
+	opts, ok := oai.GetCacheOptions(withCacheOptions(context.Background(), app))
+	assert.True(t, ok)
+	assert.Equal(t, &oai.CacheOptions{TTL: 10 * time.Minute}, opts)
+
+	app.Config.Helix.CacheTTL = types.Duration(-1)
+
+	opts, ok = oai.GetCacheOptions(withCacheOptions(context.Background(), app))
+	assert.True(t, ok)
+	assert.Equal(t, &oai.CacheOptions{Disabled: true}, opts)
+}
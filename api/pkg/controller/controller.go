@@ -15,6 +15,7 @@ import (
 	"github.com/helixml/helix/api/pkg/model"
 	"github.com/helixml/helix/api/pkg/notification"
 	"github.com/helixml/helix/api/pkg/openai"
+	"github.com/helixml/helix/api/pkg/openai/cache"
 	"github.com/helixml/helix/api/pkg/openai/manager"
 	"github.com/helixml/helix/api/pkg/pubsub"
 	"github.com/helixml/helix/api/pkg/rag"
@@ -40,6 +41,9 @@ type ControllerOptions struct {
 	ProviderManager      manager.ProviderManager
 	DataprepOpenAIClient openai.Client
 	Scheduler            scheduler.Scheduler
+	// ResponseCache is only set when the response cache is enabled, its
+	// stats are shown on the dashboard
+	ResponseCache *cache.Cache
 }
 
 type Controller struct {
@@ -120,7 +124,7 @@ func NewController(
 		return nil, fmt.Errorf("failed to get tools client: %v", err)
 	}
 
-	planner, err := tools.NewChainStrategy(options.Config, options.Store, options.GPTScriptExecutor, toolsOpenAIClient)
+	planner, err := tools.NewChainStrategy(options.Config, options.Store, options.GPTScriptExecutor, options.Filestore, toolsOpenAIClient)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create tools planner: %v", err)
 	}
@@ -465,12 +465,20 @@ func (c *Controller) getRAGResults(session *types.Session) ([]*types.SessionRAGR
 		return nil, err
 	}
 
+	// Search with the user's question when the agent extended the message
+	prompt := userInteraction.Message
+	if userInteraction.DisplayMessage != "" {
+		prompt = userInteraction.DisplayMessage
+	}
+
 	return c.Options.RAG.Query(context.Background(), &types.SessionRAGQuery{
-		Prompt:            userInteraction.Message,
+		Prompt:            prompt,
 		DataEntityID:      session.Metadata.RAGSourceID,
 		DistanceThreshold: session.Metadata.RagSettings.Threshold,
 		DistanceFunction:  session.Metadata.RagSettings.DistanceFunction,
 		MaxResults:        session.Metadata.RagSettings.ResultsCount,
+		Hybrid:            session.Metadata.RagSettings.Hybrid,
+		HybridAlpha:       session.Metadata.RagSettings.HybridAlpha,
 	})
 }
 
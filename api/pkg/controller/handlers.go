@@ -129,24 +129,27 @@ func (c *Controller) GetDashboardData(ctx context.Context) (*types.DashboardData
 		runners = append(runners, metrics)
 		return true
 	})
-	return &types.DashboardData{
+	data := &types.DashboardData{
 		SessionQueue:              c.sessionSummaryQueue,
 		Runners:                   runners,
 		GlobalSchedulingDecisions: c.schedulingDecisions,
-	}, nil
+	}
+	if c.Options.ResponseCache != nil {
+		data.Cache = c.Options.ResponseCache.Stats()
+	}
+	return data, nil
 }
 
 func (c *Controller) updateSubscriptionUser(userID string, stripeCustomerID string, stripeSubscriptionID string, active bool) error {
 	existingUser, err := c.Options.Store.GetUserMeta(context.Background(), userID)
-	if err != nil || existingUser != nil {
+	if err != nil || existingUser == nil {
 		existingUser = &types.UserMeta{
 			ID: userID,
-			Config: types.UserConfig{
-				StripeCustomerID:     stripeCustomerID,
-				StripeSubscriptionID: stripeSubscriptionID,
-			},
 		}
 	}
+	// Keep the rest of the user meta, e.g. the OIDC profile
+	existingUser.Config.StripeCustomerID = stripeCustomerID
+	existingUser.Config.StripeSubscriptionID = stripeSubscriptionID
 	existingUser.Config.StripeSubscriptionActive = active
 	_, err = c.Options.Store.EnsureUserMeta(context.Background(), *existingUser)
 	return err
@@ -34,13 +34,37 @@ type ChatCompletionOptions struct {
 // Runs the OpenAI with tools/app configuration and returns the response.
 // Returns the updated request because the controller mutates it when doing e.g. tools calls and RAG
 func (c *Controller) ChatCompletion(ctx context.Context, user *types.User, req openai.ChatCompletionRequest, opts *ChatCompletionOptions) (*openai.ChatCompletionResponse, *openai.ChatCompletionRequest, error) {
-	assistant, err := c.loadAssistant(ctx, user, opts)
+	app, err := c.loadApp(ctx, user, opts)
 	if err != nil {
 		log.Info().Msg("no assistant found")
 		return nil, nil, err
 	}
 
-	if len(assistant.Tools) > 0 {
+	ctx = withPriorityClass(ctx, user, app)
+
+	err = c.checkUsageBudget(ctx, user, app)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx = withUsageAttribution(ctx, user, app)
+	ctx = withCacheOptions(ctx, app)
+
+	assistant, err := getAppAssistant(app, opts)
+	if err != nil {
+		log.Info().Msg("no assistant found")
+		return nil, nil, err
+	}
+
+	switch {
+	case len(assistant.Tools) > 0 && assistant.AgentMode:
+		// Let the agent call the tools, the results are added to the
+		// prompt and the assistant's model writes the answer
+		ctx, err = c.runAgent(ctx, &req, assistant, opts)
+		if err != nil {
+			return nil, nil, fmt.Errorf("agent failed: %w", err)
+		}
+	case len(assistant.Tools) > 0:
 		// Check whether the app is configured for the call,
 		// if yes, execute the tools and return the response
 		toolResp, ok, err := c.evaluateToolUsage(ctx, user, req, opts)
@@ -58,7 +82,14 @@ func (c *Controller) ChatCompletion(ctx context.Context, user *types.User, req o
 	if assistant.Model != "" {
 		req.Model = assistant.Model
 
-		modelName, err := model.ProcessModelName(string(c.Options.Config.Inference.Provider), req.Model, types.SessionModeInference, types.SessionTypeText, false, false)
+		// Model names are resolved by the provider the assistant uses, which
+		// can also be a provider endpoint registered at runtime
+		provider := c.Options.Config.Inference.Provider
+		if assistant.Provider != "" {
+			provider = assistant.Provider
+		}
+
+		modelName, err := model.ProcessModelName(string(provider), req.Model, types.SessionModeInference, types.SessionTypeText, false, false)
 		if err != nil {
 			return nil, nil, fmt.Errorf("invalid model name '%s': %w", req.Model, err)
 		}
@@ -98,13 +129,35 @@ func (c *Controller) ChatCompletion(ctx context.Context, user *types.User, req o
 func (c *Controller) ChatCompletionStream(ctx context.Context, user *types.User, req openai.ChatCompletionRequest, opts *ChatCompletionOptions) (*openai.ChatCompletionStream, *openai.ChatCompletionRequest, error) {
 	req.Stream = true
 
-	assistant, err := c.loadAssistant(ctx, user, opts)
+	app, err := c.loadApp(ctx, user, opts)
 	if err != nil {
 		log.Info().Msg("no assistant found")
 		return nil, nil, err
 	}
 
-	if len(assistant.Tools) > 0 {
+	ctx = withPriorityClass(ctx, user, app)
+
+	err = c.checkUsageBudget(ctx, user, app)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx = withUsageAttribution(ctx, user, app)
+	ctx = withCacheOptions(ctx, app)
+
+	assistant, err := getAppAssistant(app, opts)
+	if err != nil {
+		log.Info().Msg("no assistant found")
+		return nil, nil, err
+	}
+
+	switch {
+	case len(assistant.Tools) > 0 && assistant.AgentMode:
+		ctx, err = c.runAgent(ctx, &req, assistant, opts)
+		if err != nil {
+			return nil, nil, fmt.Errorf("agent failed: %w", err)
+		}
+	case len(assistant.Tools) > 0:
 		// Check whether the app is configured for the call,
 		// if yes, execute the tools and return the response
 		toolRespStream, ok, err := c.evaluateToolUsageStream(ctx, user, req, opts)
@@ -122,7 +175,14 @@ func (c *Controller) ChatCompletionStream(ctx context.Context, user *types.User,
 	if assistant.Model != "" {
 		req.Model = assistant.Model
 
-		modelName, err := model.ProcessModelName(string(c.Options.Config.Inference.Provider), req.Model, types.SessionModeInference, types.SessionTypeText, false, false)
+		// Model names are resolved by the provider the assistant uses, which
+		// can also be a provider endpoint registered at runtime
+		provider := c.Options.Config.Inference.Provider
+		if assistant.Provider != "" {
+			provider = assistant.Provider
+		}
+
+		modelName, err := model.ProcessModelName(string(provider), req.Model, types.SessionModeInference, types.SessionTypeText, false, false)
 		if err != nil {
 			return nil, nil, fmt.Errorf("invalid model name '%s': %w", req.Model, err)
 		}
@@ -175,7 +235,7 @@ func (c *Controller) getClient(ctx context.Context, provider types.Provider) (oa
 }
 
 func (c *Controller) authorizeUserToApp(user *types.User, app *types.App) error {
-	if (!app.Global && !app.Shared) && app.Owner != user.ID {
+	if (!app.Global && !app.Shared) && !user.HasOwnerRole(app.Owner, app.OwnerType, types.OrganizationRoleMember) {
 		return system.NewHTTPError403(fmt.Sprintf("you do not have access to the app with the id: %s", app.ID))
 	}
 
@@ -334,25 +394,115 @@ func (c *Controller) selectAndConfigureTool(ctx context.Context, user *types.Use
 		return nil, nil, false, nil
 	}
 
-	selectedTool, ok := getToolFromAction(assistant.Tools, isActionable.Api)
+	selectedTool, ok := tools.GetToolFromAction(assistant.Tools, isActionable.Api)
 	if !ok {
 		return nil, nil, false, fmt.Errorf("tool not found for action: %s", isActionable.Api)
 	}
 
-	if len(opts.QueryParams) > 0 && selectedTool.Config.API != nil {
-		selectedTool.Config.API.Query = make(map[string]string)
+	setToolQueryParams(selectedTool, opts)
+
+	return selectedTool, isActionable, true, nil
+}
+
+func setToolQueryParams(tool *types.Tool, opts *ChatCompletionOptions) {
+	if len(opts.QueryParams) > 0 && tool.Config.API != nil {
+		tool.Config.API.Query = make(map[string]string)
 
 		for k, v := range opts.QueryParams {
-			selectedTool.Config.API.Query[k] = v
+			tool.Config.API.Query[k] = v
 		}
 	}
+}
 
-	return selectedTool, isActionable, true, nil
+// runAgent calls the tools in the agent mode and adds the results to the last
+// message. The returned context marks the next LLM call as the agent's answer.
+func (c *Controller) runAgent(ctx context.Context, req *openai.ChatCompletionRequest, assistant *types.AssistantConfig, opts *ChatCompletionOptions) (context.Context, error) {
+	vals, ok := oai.GetContextValues(ctx)
+	if !ok {
+		vals = &oai.ContextValues{}
+	}
+
+	for _, tool := range assistant.Tools {
+		setToolQueryParams(tool, opts)
+	}
+
+	c.emitStepInfo(ctx, &types.StepInfo{
+		Name:    "agent",
+		Type:    types.StepInfoTypeToolUse,
+		Message: "Planning the next steps",
+	})
+
+	history := types.HistoryFromChatCompletionRequest(*req)
+
+	resp, err := c.ToolsPlanner.RunAgent(ctx, vals.SessionID, vals.InteractionID, assistant.Tools, history,
+		tools.WithMaxAgentSteps(assistant.MaxAgentSteps),
+		tools.WithAgentStepCallback(func(step *tools.AgentStep) {
+			c.emitStepInfo(ctx, agentStepInfo(step))
+		}),
+	)
+	if err != nil {
+		return ctx, err
+	}
+
+	if len(resp.Steps) == 0 {
+		c.emitStepInfo(ctx, &types.StepInfo{
+			Name:    "agent",
+			Type:    types.StepInfoTypeToolUse,
+			Message: "No tools needed",
+		})
+
+		return ctx, nil
+	}
+
+	prompt, err := tools.AgentPrompt(getLastMessage(*req), resp)
+	if err != nil {
+		return ctx, err
+	}
+
+	setLastMessage(req, prompt)
+
+	return oai.SetStep(ctx, &oai.Step{
+		Step: types.LLMCallStepAgentAnswer,
+	}), nil
+}
+
+func agentStepInfo(step *tools.AgentStep) *types.StepInfo {
+	name := step.Tool
+	if name == "" {
+		name = step.Action
+	}
+
+	var message string
+
+	switch {
+	case !step.Finished:
+		message = fmt.Sprintf("Step %d: running %s", step.Number, step.Action)
+	case step.Error != "":
+		message = fmt.Sprintf("Step %d: %s failed: %s", step.Number, step.Action, step.Error)
+	default:
+		message = fmt.Sprintf("Step %d: %s completed", step.Number, step.Action)
+	}
+
+	return &types.StepInfo{
+		Name:    name,
+		Type:    types.StepInfoTypeToolUse,
+		Message: message,
+	}
 }
 
 func (c *Controller) loadAssistant(ctx context.Context, user *types.User, opts *ChatCompletionOptions) (*types.AssistantConfig, error) {
+	app, err := c.loadApp(ctx, user, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return getAppAssistant(app, opts)
+}
+
+// loadApp loads the app of the request, returns nil when the request isn't for an app
+func (c *Controller) loadApp(ctx context.Context, user *types.User, opts *ChatCompletionOptions) (*types.App, error) {
 	if opts.AppID == "" {
-		return &types.AssistantConfig{}, nil
+		return nil, nil
 	}
 
 	app, err := c.Options.Store.GetApp(ctx, opts.AppID)
@@ -360,10 +510,18 @@ func (c *Controller) loadAssistant(ctx context.Context, user *types.User, opts *
 		return nil, fmt.Errorf("error getting app: %w", err)
 	}
 
-	if (!app.Global && !app.Shared) && app.Owner != user.ID {
+	if (!app.Global && !app.Shared) && !user.HasOwnerRole(app.Owner, app.OwnerType, types.OrganizationRoleMember) {
 		return nil, fmt.Errorf("you do not have access to the app with the id: %s", app.ID)
 	}
 
+	return app, nil
+}
+
+func getAppAssistant(app *types.App, opts *ChatCompletionOptions) (*types.AssistantConfig, error) {
+	if app == nil {
+		return &types.AssistantConfig{}, nil
+	}
+
 	assistant := data.GetAssistant(app, opts.AssistantID)
 
 	if assistant == nil {
@@ -406,7 +564,7 @@ func (c *Controller) evaluateRAG(ctx context.Context, user *types.User, req open
 		return nil, fmt.Errorf("error getting data entity: %w", err)
 	}
 
-	if entity.Owner != user.ID {
+	if !user.HasOwnerRole(entity.Owner, entity.OwnerType, types.OrganizationRoleMember) {
 		return nil, fmt.Errorf("you do not have access to the data entity with the id: %s", entity.ID)
 	}
 
@@ -416,6 +574,8 @@ func (c *Controller) evaluateRAG(ctx context.Context, user *types.User, req open
 		DistanceThreshold: entity.Config.RAGSettings.Threshold,
 		DistanceFunction:  entity.Config.RAGSettings.DistanceFunction,
 		MaxResults:        entity.Config.RAGSettings.ResultsCount,
+		Hybrid:            entity.Config.RAGSettings.Hybrid,
+		HybridAlpha:       entity.Config.RAGSettings.HybridAlpha,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("error querying RAG: %w", err)
@@ -459,26 +619,15 @@ func (c *Controller) evaluateKnowledge(ctx context.Context, user *types.User, re
 
 			usedKnowledge = knowledge
 		default:
-			ragClient, err := c.GetRagClient(ctx, knowledge)
-			if err != nil {
-				return nil, nil, fmt.Errorf("error getting RAG client: %w", err)
-			}
-
 			c.emitStepInfo(ctx, &types.StepInfo{
 				Name:    knowledge.Name,
 				Type:    types.StepInfoTypeRAG,
 				Message: "Searching for knowledge",
 			})
 
-			ragResults, err := ragClient.Query(ctx, &types.SessionRAGQuery{
-				Prompt:            prompt,
-				DataEntityID:      knowledge.GetDataEntityID(),
-				DistanceThreshold: knowledge.RAGSettings.Threshold,
-				DistanceFunction:  knowledge.RAGSettings.DistanceFunction,
-				MaxResults:        knowledge.RAGSettings.ResultsCount,
-			})
+			ragResults, err := c.QueryKnowledge(ctx, knowledge, prompt)
 			if err != nil {
-				return nil, nil, fmt.Errorf("error querying RAG: %w", err)
+				return nil, nil, err
 			}
 
 			c.emitStepInfo(ctx, &types.StepInfo{
@@ -555,7 +704,7 @@ func extendMessageWithKnowledge(req *openai.ChatCompletionRequest, ragResults []
 		return fmt.Errorf("failed to extend message with knowledge: %w", err)
 	}
 
-	req.Messages[len(req.Messages)-1].Content = extended
+	setLastMessage(req, extended)
 
 	return nil
 }
@@ -599,3 +748,69 @@ func (c *Controller) GetRagClient(ctx context.Context, knowledge *types.Knowledg
 
 	return c.Options.RAG, nil
 }
+
+// QueryKnowledge searches the knowledge with its RAG settings. When reranking is enabled
+// the top N results are reordered by the reranking model and then truncated to the
+// results count.
+func (c *Controller) QueryKnowledge(ctx context.Context, knowledge *types.Knowledge, prompt string) ([]*types.SessionRAGResult, error) {
+	ragClient, err := c.GetRagClient(ctx, knowledge)
+	if err != nil {
+		return nil, fmt.Errorf("error getting RAG client: %w", err)
+	}
+
+	settings := knowledge.RAGSettings
+
+	query := &types.SessionRAGQuery{
+		Prompt:            prompt,
+		DataEntityID:      knowledge.GetDataEntityID(),
+		DistanceThreshold: settings.Threshold,
+		DistanceFunction:  settings.DistanceFunction,
+		MaxResults:        settings.ResultsCount,
+		Hybrid:            settings.Hybrid,
+		HybridAlpha:       settings.HybridAlpha,
+	}
+
+	if !settings.Rerank.Enabled {
+		results, err := ragClient.Query(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("error querying RAG: %w", err)
+		}
+		return results, nil
+	}
+
+	query.MaxResults = settings.Rerank.TopN
+	if query.MaxResults == 0 {
+		query.MaxResults = rag.DefaultRerankTopN
+	}
+
+	results, err := ragClient.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error querying RAG: %w", err)
+	}
+
+	client, err := c.getClient(ctx, types.Provider(settings.Rerank.Provider))
+	if err != nil {
+		return nil, err
+	}
+
+	model := settings.Rerank.Model
+	if model == "" {
+		model = c.Options.Config.Tools.Model
+	}
+
+	results, err = rag.NewLLMReranker(client, model).Rerank(ctx, prompt, results)
+	if err != nil {
+		return nil, fmt.Errorf("error reranking RAG results: %w", err)
+	}
+
+	resultsCount := settings.ResultsCount
+	if resultsCount == 0 {
+		resultsCount = rag.DefaultMaxResults
+	}
+
+	if len(results) > resultsCount {
+		results = results[:resultsCount]
+	}
+
+	return results, nil
+}
@@ -194,6 +194,63 @@ func (suite *ControllerSuite) Test_BasicInferenceWithKnowledge() {
 	}, resp)
 }
 
+func (suite *ControllerSuite) Test_QueryKnowledge_Rerank() {
+	hybridAlpha := 0.7
+
+	knowledge := &types.Knowledge{
+		ID: "knowledge_id",
+		RAGSettings: types.RAGSettings{
+			ResultsCount: 2,
+			Hybrid:       true,
+			HybridAlpha:  &hybridAlpha,
+			Rerank: types.RAGRerankSettings{
+				Enabled: true,
+				Model:   "rerank-model",
+				TopN:    5,
+			},
+		},
+	}
+
+	suite.rag.EXPECT().Query(suite.ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, q *types.SessionRAGQuery) ([]*types.SessionRAGResult, error) {
+			suite.Equal("what is E-4012?", q.Prompt)
+			suite.Equal(5, q.MaxResults)
+			suite.True(q.Hybrid)
+			suite.Require().NotNil(q.HybridAlpha)
+			suite.Equal(0.7, *q.HybridAlpha)
+
+			return []*types.SessionRAGResult{
+				{ID: "a", Content: "printer setup"},
+				{ID: "b", Content: "E-4012 means the paper is jammed"},
+				{ID: "c", Content: "list of error codes"},
+			}, nil
+		})
+
+	suite.openAiClient.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+			suite.Equal("rerank-model", req.Model)
+			suite.Contains(req.Messages[1].Content, "Passage 1:\nE-4012 means the paper is jammed")
+
+			return openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{
+					{
+						Message: openai.ChatCompletionMessage{
+							Content: "[2, 9, 5]",
+						},
+					},
+				},
+			}, nil
+		})
+
+	results, err := suite.controller.QueryKnowledge(suite.ctx, knowledge, "what is E-4012?")
+	suite.Require().NoError(err)
+
+	suite.Require().Len(results, 2)
+	suite.Equal("b", results[0].ID)
+	suite.Equal(0.9, results[0].RerankScore)
+	suite.Equal("c", results[1].ID)
+}
+
 func Test_setSystemPrompt(t *testing.T) {
 	type args struct {
 		req          *openai.ChatCompletionRequest
@@ -277,3 +334,40 @@ func Test_setSystemPrompt(t *testing.T) {
 		})
 	}
 }
+
+func Test_setLastMessage_MultiContent(t *testing.T) {
+	image := openai.ChatMessagePart{
+		Type:     openai.ChatMessagePartTypeImageURL,
+		ImageURL: &openai.ChatMessageImageURL{URL: "https://example.com/invoice.png"},
+	}
+
+	req := &openai.ChatCompletionRequest{
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role: openai.ChatMessageRoleUser,
+				MultiContent: []openai.ChatMessagePart{
+					{Type: openai.ChatMessagePartTypeText, Text: "What is the total"},
+					image,
+					{Type: openai.ChatMessagePartTypeText, Text: "of this invoice?"},
+				},
+			},
+		},
+	}
+
+	if got := getLastMessage(*req); got != "What is the total\nof this invoice?" {
+		t.Errorf("getLastMessage() = %q", got)
+	}
+
+	setLastMessage(req, "extended prompt")
+
+	want := []openai.ChatMessagePart{
+		{Type: openai.ChatMessagePartTypeText, Text: "extended prompt"},
+		image,
+	}
+	if !reflect.DeepEqual(req.Messages[0].MultiContent, want) {
+		t.Errorf("setLastMessage() = %+v, want %+v", req.Messages[0].MultiContent, want)
+	}
+	if req.Messages[0].Content != "" {
+		t.Errorf("setLastMessage() set both content fields")
+	}
+}
@@ -0,0 +1,51 @@
+package bucket
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+//go:generate mockgen -source $GOFILE -destination bucket_mocks.go -package $GOPACKAGE
+
+// Bucket lists and reads objects from a cloud storage bucket
+// that is used as a knowledge source
+type Bucket interface {
+	List(ctx context.Context) ([]*Object, error)
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Close() error
+}
+
+type Object struct {
+	Key      string
+	Revision string // ETag for S3, generation for GCS
+	Size     int64
+	URL      string // s3://bucket/key or gs://bucket/key
+}
+
+// NewBucket creates the client of the bucket of the knowledge. The buckets are
+// read with the credentials of the control plane, so only the buckets and the
+// endpoints allowed by the admin can be used.
+func NewBucket(ctx context.Context, allowed config.KnowledgeBuckets, k *types.Knowledge) (Bucket, error) {
+	switch {
+	case k.Source.S3 != nil:
+		if !slices.Contains(allowed.S3, k.Source.S3.Bucket) {
+			return nil, fmt.Errorf("s3 bucket %s is not allowed", k.Source.S3.Bucket)
+		}
+		if k.Source.S3.Endpoint != "" && !slices.Contains(allowed.S3Endpoints, k.Source.S3.Endpoint) {
+			return nil, fmt.Errorf("s3 endpoint %s is not allowed", k.Source.S3.Endpoint)
+		}
+		return NewS3(ctx, k.Source.S3)
+	case k.Source.GCS != nil:
+		if !slices.Contains(allowed.GCS, k.Source.GCS.Bucket) {
+			return nil, fmt.Errorf("gcs bucket %s is not allowed", k.Source.GCS.Bucket)
+		}
+		return NewGCS(ctx, k.Source.GCS)
+	default:
+		return nil, fmt.Errorf("knowledge %s has no bucket source", k.ID)
+	}
+}
@@ -0,0 +1,68 @@
+package bucket
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func TestNewBucket_Allowed(t *testing.T) {
+	allowed := config.KnowledgeBuckets{
+		S3:          []string{"docs"},
+		S3Endpoints: []string{"http://minio:9000"},
+		GCS:         []string{"docs"},
+	}
+
+	tests := []struct {
+		name   string
+		source types.KnowledgeSource
+		err    string
+	}{
+		{
+			name:   "allowed s3 bucket",
+			source: types.KnowledgeSource{S3: &types.KnowledgeSourceS3{Bucket: "docs"}},
+		},
+		{
+			name:   "allowed s3 endpoint",
+			source: types.KnowledgeSource{S3: &types.KnowledgeSourceS3{Bucket: "docs", Endpoint: "http://minio:9000"}},
+		},
+		{
+			name:   "other s3 bucket",
+			source: types.KnowledgeSource{S3: &types.KnowledgeSourceS3{Bucket: "secrets"}},
+			err:    "s3 bucket secrets is not allowed",
+		},
+		{
+			name:   "other s3 endpoint",
+			source: types.KnowledgeSource{S3: &types.KnowledgeSourceS3{Bucket: "docs", Endpoint: "http://169.254.169.254"}},
+			err:    "s3 endpoint http://169.254.169.254 is not allowed",
+		},
+		{
+			name:   "other gcs bucket",
+			source: types.KnowledgeSource{GCS: &types.KnowledgeSourceGCS{Bucket: "secrets"}},
+			err:    "gcs bucket secrets is not allowed",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := NewBucket(context.Background(), allowed, &types.Knowledge{Source: tc.source})
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, b.Close())
+		})
+	}
+}
+
+func TestNewBucket_NoneAllowedByDefault(t *testing.T) {
+	_, err := NewBucket(context.Background(), config.KnowledgeBuckets{}, &types.Knowledge{
+		Source: types.KnowledgeSource{S3: &types.KnowledgeSourceS3{Bucket: "docs"}},
+	})
+	require.EqualError(t, err, "s3 bucket docs is not allowed")
+}
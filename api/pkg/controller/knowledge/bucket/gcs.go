@@ -0,0 +1,84 @@
+package bucket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+type GCS struct {
+	client *storage.Client
+	bucket *storage.BucketHandle
+	name   string
+	prefix string
+}
+
+// NewGCS creates a GCS bucket client using the application default credentials.
+// Set STORAGE_EMULATOR_HOST to point it at the GCS emulator.
+func NewGCS(ctx context.Context, cfg *types.KnowledgeSourceGCS) (*GCS, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("gcs bucket not specified")
+	}
+
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage client: %w", err)
+	}
+
+	return &GCS{
+		client: client,
+		bucket: client.Bucket(cfg.Bucket),
+		name:   cfg.Bucket,
+		prefix: strings.TrimPrefix(cfg.Path, "/"),
+	}, nil
+}
+
+func (b *GCS) List(ctx context.Context) ([]*Object, error) {
+	it := b.bucket.Objects(ctx, &storage.Query{Prefix: b.prefix})
+
+	var objects []*Object
+
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects in gs://%s/%s: %w", b.name, b.prefix, err)
+		}
+
+		// Skip folder placeholders
+		if strings.HasSuffix(attrs.Name, "/") {
+			continue
+		}
+
+		objects = append(objects, &Object{
+			Key:      attrs.Name,
+			Revision: strconv.FormatInt(attrs.Generation, 10),
+			Size:     attrs.Size,
+			URL:      fmt.Sprintf("gs://%s/%s", b.name, attrs.Name),
+		})
+	}
+
+	return objects, nil
+}
+
+func (b *GCS) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	reader, err := b.bucket.Object(key).NewReader(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object gs://%s/%s: %w", b.name, key, err)
+	}
+	return reader, nil
+}
+
+func (b *GCS) Close() error {
+	return b.client.Close()
+}
@@ -0,0 +1,69 @@
+package bucket
+
+import (
+	"context"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+// Runs against the GCS emulator, for example:
+//
+//	docker run -p 4443:4443 fsouza/fake-gcs-server -scheme http
+//	STORAGE_EMULATOR_HOST=localhost:4443 go test ./...
+func TestGCS_ListAndOpen(t *testing.T) {
+	if os.Getenv("STORAGE_EMULATOR_HOST") == "" {
+		t.Skip("STORAGE_EMULATOR_HOST not set, skipping GCS tests")
+	}
+
+	ctx := context.Background()
+	bucketName := "helix-test-" + system.GenerateID()
+
+	b, err := NewGCS(ctx, &types.KnowledgeSourceGCS{
+		Bucket: bucketName,
+		Path:   "docs/",
+	})
+	require.NoError(t, err)
+
+	err = b.bucket.Create(ctx, "helix-test", nil)
+	require.NoError(t, err)
+
+	write := func(key, content string) {
+		w := b.bucket.Object(key).NewWriter(ctx)
+		_, err := w.Write([]byte(content))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+	}
+
+	write("docs/a.txt", "hello a")
+	write("docs/sub/b.txt", "hello b")
+	write("other/c.txt", "hello c")
+
+	objects, err := b.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, objects, 2)
+
+	require.Equal(t, "docs/a.txt", objects[0].Key)
+	require.Equal(t, "gs://"+bucketName+"/docs/a.txt", objects[0].URL)
+	require.NotEmpty(t, objects[0].Revision)
+
+	rc, err := b.Open(ctx, "docs/sub/b.txt")
+	require.NoError(t, err)
+	defer rc.Close()
+
+	bts, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.Equal(t, "hello b", string(bts))
+
+	// Overwriting the object must change the generation
+	write("docs/a.txt", "hello again")
+
+	updated, err := b.List(ctx)
+	require.NoError(t, err)
+	require.NotEqual(t, objects[0].Revision, updated[0].Revision)
+}
@@ -0,0 +1,108 @@
+package bucket
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+const defaultS3Region = "us-east-1"
+
+type S3 struct {
+	client *s3.Client
+	bucket string
+	prefix string
+}
+
+// NewS3 creates an S3 bucket client. Credentials are resolved through the
+// default AWS chain (environment, shared config, IAM role).
+func NewS3(ctx context.Context, cfg *types.KnowledgeSourceS3) (*S3, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("s3 bucket not specified")
+	}
+
+	var opts []func(*awsconfig.LoadOptions) error
+	if cfg.Region != "" {
+		opts = append(opts, awsconfig.WithRegion(cfg.Region))
+	}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	if awsCfg.Region == "" {
+		awsCfg.Region = defaultS3Region
+	}
+
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if cfg.Endpoint != "" {
+			o.BaseEndpoint = aws.String(cfg.Endpoint)
+		}
+		o.UsePathStyle = cfg.UsePathStyle
+	})
+
+	return &S3{
+		client: client,
+		bucket: cfg.Bucket,
+		prefix: strings.TrimPrefix(cfg.Path, "/"),
+	}, nil
+}
+
+func (b *S3) List(ctx context.Context) ([]*Object, error) {
+	paginator := s3.NewListObjectsV2Paginator(b.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(b.bucket),
+		Prefix: aws.String(b.prefix),
+	})
+
+	var objects []*Object
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects in s3://%s/%s: %w", b.bucket, b.prefix, err)
+		}
+
+		for _, obj := range page.Contents {
+			key := aws.ToString(obj.Key)
+
+			// Skip folder placeholders
+			if strings.HasSuffix(key, "/") {
+				continue
+			}
+
+			objects = append(objects, &Object{
+				Key:      key,
+				Revision: strings.Trim(aws.ToString(obj.ETag), `"`),
+				Size:     aws.ToInt64(obj.Size),
+				URL:      fmt.Sprintf("s3://%s/%s", b.bucket, key),
+			})
+		}
+	}
+
+	return objects, nil
+}
+
+func (b *S3) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	out, err := b.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object s3://%s/%s: %w", b.bucket, key, err)
+	}
+
+	return out.Body, nil
+}
+
+// Close is a no-op, the S3 client has no connections to release
+func (b *S3) Close() error {
+	return nil
+}
@@ -0,0 +1,85 @@
+package bucket
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+// Runs against any S3 compatible service, for example:
+//
+//	docker run -p 9000:9000 minio/minio server /data
+//	S3_ENDPOINT=http://localhost:9000 AWS_ACCESS_KEY_ID=minioadmin AWS_SECRET_ACCESS_KEY=minioadmin go test ./...
+func TestS3_ListAndOpen(t *testing.T) {
+	endpoint := os.Getenv("S3_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("S3_ENDPOINT not set, skipping S3 tests")
+	}
+
+	ctx := context.Background()
+	bucketName := "helix-test-" + system.GenerateID()
+
+	b, err := NewS3(ctx, &types.KnowledgeSourceS3{
+		Bucket:       bucketName,
+		Path:         "docs/",
+		Endpoint:     endpoint,
+		UsePathStyle: true,
+	})
+	require.NoError(t, err)
+
+	_, err = b.client.CreateBucket(ctx, &s3.CreateBucketInput{
+		Bucket: aws.String(bucketName),
+	})
+	require.NoError(t, err)
+
+	for key, content := range map[string]string{
+		"docs/a.txt":     "hello a",
+		"docs/sub/b.txt": "hello b",
+		"other/c.txt":    "hello c",
+	} {
+		_, err = b.client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket: aws.String(bucketName),
+			Key:    aws.String(key),
+			Body:   strings.NewReader(content),
+		})
+		require.NoError(t, err)
+	}
+
+	objects, err := b.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, objects, 2)
+
+	require.Equal(t, "docs/a.txt", objects[0].Key)
+	require.Equal(t, "s3://"+bucketName+"/docs/a.txt", objects[0].URL)
+	require.NotEmpty(t, objects[0].Revision)
+	require.Equal(t, int64(7), objects[0].Size)
+
+	rc, err := b.Open(ctx, "docs/sub/b.txt")
+	require.NoError(t, err)
+	defer rc.Close()
+
+	bts, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.Equal(t, "hello b", string(bts))
+
+	// Overwriting the object must change the revision
+	_, err = b.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String("docs/a.txt"),
+		Body:   strings.NewReader("hello again"),
+	})
+	require.NoError(t, err)
+
+	updated, err := b.List(ctx)
+	require.NoError(t, err)
+	require.NotEqual(t, objects[0].Revision, updated[0].Revision)
+}
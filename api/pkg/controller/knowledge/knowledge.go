@@ -8,9 +8,11 @@ import (
 	"time"
 
 	gocron "github.com/go-co-op/gocron/v2"
+	git "github.com/go-git/go-git/v5"
 	"github.com/rs/zerolog/log"
 
 	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/controller/knowledge/bucket"
 	"github.com/helixml/helix/api/pkg/controller/knowledge/crawler"
 	"github.com/helixml/helix/api/pkg/extract"
 	"github.com/helixml/helix/api/pkg/filestore"
@@ -32,6 +34,8 @@ type Reconciler struct {
 	ragClient    rag.RAG                                   // Default server RAG client
 	newRagClient func(settings *types.RAGSettings) rag.RAG // Custom RAG server client constructor
 	newCrawler   func(k *types.Knowledge) (crawler.Crawler, error)
+	newBucket    func(ctx context.Context, k *types.Knowledge) (bucket.Bucket, error)
+	openRepo     func(ctx context.Context, k *types.Knowledge) (*git.Repository, error)
 	cron         gocron.Scheduler
 	wg           sync.WaitGroup
 }
@@ -42,7 +46,7 @@ func New(config *config.ServerConfig, store store.Store, filestore filestore.Fil
 		return nil, fmt.Errorf("failed to create scheduler: %w", err)
 	}
 
-	return &Reconciler{
+	r := &Reconciler{
 		config:     config,
 		store:      store,
 		filestore:  filestore,
@@ -56,7 +60,13 @@ func New(config *config.ServerConfig, store store.Store, filestore filestore.Fil
 		newCrawler: func(k *types.Knowledge) (crawler.Crawler, error) {
 			return crawler.NewCrawler(k)
 		},
-	}, nil
+		newBucket: func(ctx context.Context, k *types.Knowledge) (bucket.Bucket, error) {
+			return bucket.NewBucket(ctx, config.RAG.Buckets, k)
+		},
+	}
+	r.openRepo = r.cloneGithubRepository
+
+	return r, nil
 }
 
 func (r *Reconciler) Start(ctx context.Context) error {
@@ -2,13 +2,24 @@ package knowledge
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"path"
 	"path/filepath"
+	"strings"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
 
 	"github.com/helixml/helix/api/pkg/extract"
 	"github.com/helixml/helix/api/pkg/filestore"
+	"github.com/helixml/helix/api/pkg/github"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/system"
 	"github.com/helixml/helix/api/pkg/types"
 	"github.com/rs/zerolog/log"
 )
@@ -19,6 +30,10 @@ func (r *Reconciler) getIndexingData(ctx context.Context, k *types.Knowledge) ([
 		return r.extractDataFromWeb(ctx, k)
 	case k.Source.Filestore != nil:
 		return r.extractDataFromHelixFilestore(ctx, k)
+	case k.Source.S3 != nil, k.Source.GCS != nil:
+		return r.extractDataFromBucket(ctx, k)
+	case k.Source.Github != nil:
+		return r.extractDataFromGithub(ctx, k)
 	default:
 		return nil, fmt.Errorf("unknown source: %+v", k.Source)
 	}
@@ -263,3 +278,439 @@ func (r *Reconciler) getFilestoreFiles(ctx context.Context, fs filestore.FileSto
 
 	return result, nil
 }
+
+func (r *Reconciler) extractDataFromBucket(ctx context.Context, k *types.Knowledge) ([]*indexerData, error) {
+	b, err := r.newBucket(ctx, k)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bucket client: %w", err)
+	}
+	defer b.Close()
+
+	objects, err := b.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bucket objects: %w", err)
+	}
+
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("no objects found in the bucket")
+	}
+
+	var sourceObjects []*sourceObject
+
+	for _, obj := range objects {
+		key := obj.Key
+
+		sourceObjects = append(sourceObjects, &sourceObject{
+			Key:      obj.Key,
+			Revision: obj.Revision,
+			Size:     obj.Size,
+			Source:   obj.URL,
+			open: func(ctx context.Context) (io.ReadCloser, error) {
+				return b.Open(ctx, key)
+			},
+		})
+	}
+
+	return r.extractSourceObjects(ctx, k, sourceObjects)
+}
+
+func (r *Reconciler) extractDataFromGithub(ctx context.Context, k *types.Knowledge) ([]*indexerData, error) {
+	src := k.Source.Github
+
+	repository, err := r.openRepo(ctx, k)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone repository %s/%s: %w", src.Owner, src.Repository, err)
+	}
+
+	head, err := repository.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository HEAD: %w", err)
+	}
+
+	commit, err := repository.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %w", head.Hash(), err)
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree of commit %s: %w", commit.Hash, err)
+	}
+
+	if k.SourceRevision != "" {
+		changed, err := countGithubChanges(repository, k.SourceRevision, tree, src)
+		if err != nil {
+			// The last indexed commit is gone, for example after a force push
+			log.Info().
+				Err(err).
+				Str("knowledge_id", k.ID).
+				Str("last_commit", k.SourceRevision).
+				Msg("can't diff against the last indexed commit, indexing all files")
+		} else {
+			log.Info().
+				Str("knowledge_id", k.ID).
+				Str("last_commit", k.SourceRevision).
+				Str("commit", commit.Hash.String()).
+				Int("changed", changed).
+				Msg("github repository changes since the last indexed commit")
+
+			if changed == 0 {
+				k.SourceRevision = commit.Hash.String()
+				return nil, errSourceUnchanged
+			}
+		}
+	}
+
+	var sourceObjects []*sourceObject
+
+	err = tree.Files().ForEach(func(f *object.File) error {
+		if f.Mode == filemode.Symlink || !matchesGithubFilters(src, f.Name) {
+			return nil
+		}
+
+		file := f
+
+		sourceObjects = append(sourceObjects, &sourceObject{
+			Key: file.Name,
+			// Blob hash only changes when the file contents change so unchanged
+			// files are not extracted again when the repository is updated
+			Revision: file.Hash.String(),
+			Size:     file.Size,
+			Source:   fmt.Sprintf("https://github.com/%s/%s/blob/%s/%s", src.Owner, src.Repository, commit.Hash, file.Name),
+			open: func(_ context.Context) (io.ReadCloser, error) {
+				return file.Reader()
+			},
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files of commit %s: %w", commit.Hash, err)
+	}
+
+	if len(sourceObjects) == 0 {
+		return nil, fmt.Errorf("no files matching the filters found in the repository")
+	}
+
+	log.Info().
+		Str("knowledge_id", k.ID).
+		Str("commit", commit.Hash.String()).
+		Int("files", len(sourceObjects)).
+		Msg("github repository loaded")
+
+	k.SourceRevision = commit.Hash.String()
+
+	return r.extractSourceObjects(ctx, k, sourceObjects)
+}
+
+// countGithubChanges returns the number of files matching the filters that
+// were added, modified or removed since the given commit
+func countGithubChanges(repository *git.Repository, since string, tree *object.Tree, src *types.KnowledgeSourceGithub) (int, error) {
+	previous, err := repository.CommitObject(plumbing.NewHash(since))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get commit %s: %w", since, err)
+	}
+
+	previousTree, err := previous.Tree()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get tree of commit %s: %w", since, err)
+	}
+
+	changes, err := object.DiffTree(previousTree, tree)
+	if err != nil {
+		return 0, fmt.Errorf("failed to diff against commit %s: %w", since, err)
+	}
+
+	var changed int
+	for _, change := range changes {
+		// Name is empty on the side that doesn't exist
+		from, to := change.From.Name, change.To.Name
+		if (from != "" && matchesGithubFilters(src, from)) || (to != "" && matchesGithubFilters(src, to)) {
+			changed++
+		}
+	}
+
+	return changed, nil
+}
+
+// cloneGithubRepository clones the repository or updates the existing clone,
+// private repositories are cloned with the knowledge owner's GitHub token
+func (r *Reconciler) cloneGithubRepository(ctx context.Context, k *types.Knowledge) (*git.Repository, error) {
+	src := k.Source.Github
+
+	apiKeys, err := r.store.ListAPIKeys(ctx, &store.ListApiKeysQuery{
+		Owner:     k.Owner,
+		OwnerType: k.OwnerType,
+		Type:      types.APIKeyType_Github,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get github token: %w", err)
+	}
+
+	var token string
+	for _, apiKey := range apiKeys {
+		if apiKey.Type == types.APIKeyType_Github {
+			token = apiKey.Key
+			break
+		}
+	}
+
+	repository, err := github.CloneOrUpdateBareRepo(
+		ctx,
+		fmt.Sprintf("%s/%s", src.Owner, src.Repository),
+		src.Branch,
+		token,
+		filepath.Join(r.config.GitHub.RepoFolder, "knowledge", k.ID),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if token != "" && r.config.GitHub.WebhookURL != "" && k.GithubWebhookSecret == "" {
+		err = r.addGithubWebhook(ctx, k, token)
+		if err != nil {
+			// The knowledge is still refreshed on its schedule
+			log.Warn().
+				Err(err).
+				Str("knowledge_id", k.ID).
+				Msg("failed to add webhook to the github repository")
+		}
+	}
+
+	return repository, nil
+}
+
+// addGithubWebhook adds a push webhook to the repository so that the knowledge
+// is refreshed on every push, the owner's token must be allowed to manage the
+// repository webhooks
+func (r *Reconciler) addGithubWebhook(ctx context.Context, k *types.Knowledge, token string) error {
+	src := k.Source.Github
+
+	client, err := github.NewGithubClient(github.GithubClientOptions{
+		Ctx:   ctx,
+		Token: token,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create github client: %w", err)
+	}
+
+	secret, err := system.GenerateAPIKey()
+	if err != nil {
+		return fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+
+	err = client.AddWebhookToRepo(
+		src.Owner,
+		src.Repository,
+		"helixwebhook",
+		fmt.Sprintf("%s?knowledge_id=%s", r.config.GitHub.WebhookURL, k.ID),
+		[]string{"push"},
+		secret,
+	)
+	if err != nil {
+		return err
+	}
+
+	k.GithubWebhookSecret = secret
+
+	_, err = r.store.UpdateKnowledge(ctx, k)
+	if err != nil {
+		return fmt.Errorf("failed to save webhook secret: %w", err)
+	}
+
+	return nil
+}
+
+func matchesGithubFilters(src *types.KnowledgeSourceGithub, name string) bool {
+	if len(src.FilterPaths) > 0 {
+		var found bool
+		for _, p := range src.FilterPaths {
+			p = strings.Trim(path.Clean("/"+p), "/")
+			if p == "" || name == p || strings.HasPrefix(name, p+"/") {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(src.FilterExtensions) > 0 {
+		ext := strings.ToLower(path.Ext(name))
+		for _, e := range src.FilterExtensions {
+			if ext != "" && ext == "."+strings.TrimPrefix(strings.ToLower(e), ".") {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// errSourceUnchanged is returned instead of the data when nothing changed
+// since the last indexed revision, the current version is kept
+var errSourceUnchanged = errors.New("source unchanged since the last indexed revision")
+
+// errSourceObjectTooLarge is returned when the object is larger than the
+// maximum source object size, it's skipped
+var errSourceObjectTooLarge = errors.New("source object too large")
+
+// sourceObject is a single object from an external source such as a bucket,
+// it's extracted only when its revision differs from the last extracted one
+type sourceObject struct {
+	Key      string
+	Revision string
+	Size     int64
+	Source   string // Source reported with the indexed data, e.g. s3://bucket/key
+
+	open func(ctx context.Context) (io.ReadCloser, error)
+}
+
+// extractSourceObjects extracts the objects, reusing the previously extracted
+// contents for the objects that haven't changed since the last run
+func (r *Reconciler) extractSourceObjects(ctx context.Context, k *types.Knowledge, objects []*sourceObject) ([]*indexerData, error) {
+	existing, err := r.store.ListKnowledgeSourceObjects(ctx, k.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list previously extracted objects: %w", err)
+	}
+
+	previous := make(map[string]*types.KnowledgeSourceObject, len(existing))
+	for _, obj := range existing {
+		previous[obj.Key] = obj
+	}
+
+	// Optional mode to disable text extractor and chunking,
+	// useful when the indexing server will know how to handle
+	// raw data directly
+	extractorEnabled := !k.RAGSettings.DisableChunking
+
+	var (
+		result    []*indexerData
+		unchanged int
+	)
+
+	for _, obj := range objects {
+		// Skipped objects are left in previous so that their earlier
+		// contents are removed too
+		if r.config.RAG.MaxSourceObjectSize > 0 && obj.Size > r.config.RAG.MaxSourceObjectSize {
+			logSkippedSourceObject(k, obj)
+			continue
+		}
+
+		prev, ok := previous[obj.Key]
+
+		if ok && prev.Revision == obj.Revision && prev.Extracted == extractorEnabled {
+			delete(previous, obj.Key)
+			unchanged++
+			result = append(result, &indexerData{
+				Data:   prev.Data,
+				Source: obj.Source,
+			})
+			continue
+		}
+
+		data, err := r.extractSourceObject(ctx, obj, extractorEnabled)
+		if errors.Is(err, errSourceObjectTooLarge) {
+			logSkippedSourceObject(k, obj)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		delete(previous, obj.Key)
+
+		if ok {
+			prev.Revision = obj.Revision
+			prev.Size = obj.Size
+			prev.Extracted = extractorEnabled
+			prev.Data = data
+
+			_, err = r.store.UpdateKnowledgeSourceObject(ctx, prev)
+		} else {
+			_, err = r.store.CreateKnowledgeSourceObject(ctx, &types.KnowledgeSourceObject{
+				KnowledgeID: k.ID,
+				Key:         obj.Key,
+				Revision:    obj.Revision,
+				Size:        obj.Size,
+				Extracted:   extractorEnabled,
+				Data:        data,
+			})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to save extracted object %s: %w", obj.Key, err)
+		}
+
+		result = append(result, &indexerData{
+			Data:   data,
+			Source: obj.Source,
+		})
+	}
+
+	// Whatever is left was removed from the source
+	for _, obj := range previous {
+		err := r.store.DeleteKnowledgeSourceObject(ctx, obj.ID)
+		if err != nil {
+			log.Warn().
+				Err(err).
+				Str("knowledge_id", k.ID).
+				Str("key", obj.Key).
+				Msg("failed to delete removed source object")
+		}
+	}
+
+	log.Info().
+		Str("knowledge_id", k.ID).
+		Int("count", len(objects)).
+		Int("unchanged", unchanged).
+		Int("removed", len(previous)).
+		Msg("source objects extracted")
+
+	return result, nil
+}
+
+func logSkippedSourceObject(k *types.Knowledge, obj *sourceObject) {
+	log.Warn().
+		Str("knowledge_id", k.ID).
+		Str("key", obj.Key).
+		Int64("size", obj.Size).
+		Msg("skipping source object larger than the maximum size")
+}
+
+func (r *Reconciler) extractSourceObject(ctx context.Context, obj *sourceObject, extractorEnabled bool) ([]byte, error) {
+	rc, err := obj.open(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s, error: %w", obj.Source, err)
+	}
+	defer rc.Close()
+
+	// The listed size isn't trusted, read one byte past the limit to
+	// tell if the object is larger
+	var reader io.Reader = rc
+	maxSize := r.config.RAG.MaxSourceObjectSize
+	if maxSize > 0 {
+		reader = io.LimitReader(rc, maxSize+1)
+	}
+
+	bts, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s, error: %w", obj.Source, err)
+	}
+
+	if maxSize > 0 && int64(len(bts)) > maxSize {
+		return nil, errSourceObjectTooLarge
+	}
+
+	if !extractorEnabled {
+		return bts, nil
+	}
+
+	extracted, err := r.extractor.Extract(ctx, &extract.ExtractRequest{
+		Content: bts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract data from %s, error: %w", obj.Source, err)
+	}
+
+	return []byte(extracted), nil
+}
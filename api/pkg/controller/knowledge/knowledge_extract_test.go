@@ -3,11 +3,21 @@ package knowledge
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
 
 	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/controller/knowledge/bucket"
 	"github.com/helixml/helix/api/pkg/controller/knowledge/crawler"
 	"github.com/helixml/helix/api/pkg/extract"
 	"github.com/helixml/helix/api/pkg/filestore"
@@ -16,6 +26,7 @@ import (
 	"github.com/helixml/helix/api/pkg/types"
 	"go.uber.org/mock/gomock"
 
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -26,6 +37,7 @@ type ExtractorSuite struct {
 
 	extractor *extract.MockExtractor
 	crawler   *crawler.MockCrawler
+	bucket    *bucket.MockBucket
 	store     *store.MockStore
 	rag       *rag.MockRAG
 	filestore *filestore.MockFileStore
@@ -44,6 +56,7 @@ func (suite *ExtractorSuite) SetupTest() {
 	suite.ctx = context.Background()
 	suite.extractor = extract.NewMockExtractor(ctrl)
 	suite.crawler = crawler.NewMockCrawler(ctrl)
+	suite.bucket = bucket.NewMockBucket(ctrl)
 	suite.store = store.NewMockStore(ctrl)
 	suite.rag = rag.NewMockRAG(ctrl)
 	suite.filestore = filestore.NewMockFileStore(ctrl)
@@ -59,6 +72,10 @@ func (suite *ExtractorSuite) SetupTest() {
 	suite.reconciler.newCrawler = func(k *types.Knowledge) (crawler.Crawler, error) {
 		return suite.crawler, nil
 	}
+
+	suite.reconciler.newBucket = func(ctx context.Context, k *types.Knowledge) (bucket.Bucket, error) {
+		return suite.bucket, nil
+	}
 }
 
 func (suite *ExtractorSuite) Test_getIndexingData_CrawlerEnabled() {
@@ -142,3 +159,309 @@ func (suite *ExtractorSuite) Test_getIndexingData_CrawlerDisabled_ExtractEnabled
 	suite.Equal("https://example.com", data[0].Source)
 	suite.Contains(string(data[0].Data), "Hello, world!")
 }
+
+func (suite *ExtractorSuite) Test_getIndexingData_S3() {
+	knowledge := &types.Knowledge{
+		ID: "knowledge_id",
+		Source: types.KnowledgeSource{
+			S3: &types.KnowledgeSourceS3{
+				Bucket: "docs",
+				Path:   "handbook/",
+			},
+		},
+	}
+
+	suite.bucket.EXPECT().List(gomock.Any()).Return([]*bucket.Object{
+		{Key: "handbook/unchanged.pdf", Revision: "etag-1", URL: "s3://docs/handbook/unchanged.pdf"},
+		{Key: "handbook/changed.pdf", Revision: "etag-3", URL: "s3://docs/handbook/changed.pdf"},
+		{Key: "handbook/new.pdf", Revision: "etag-4", URL: "s3://docs/handbook/new.pdf"},
+	}, nil)
+	suite.bucket.EXPECT().Close().Return(nil)
+
+	suite.store.EXPECT().ListKnowledgeSourceObjects(gomock.Any(), "knowledge_id").Return([]*types.KnowledgeSourceObject{
+		{ID: "knso_1", KnowledgeID: "knowledge_id", Key: "handbook/unchanged.pdf", Revision: "etag-1", Extracted: true, Data: []byte("unchanged text")},
+		{ID: "knso_2", KnowledgeID: "knowledge_id", Key: "handbook/changed.pdf", Revision: "etag-2", Extracted: true, Data: []byte("old text")},
+		{ID: "knso_3", KnowledgeID: "knowledge_id", Key: "handbook/removed.pdf", Revision: "etag-5", Extracted: true, Data: []byte("removed text")},
+	}, nil)
+
+	// Only the changed and the new objects are downloaded and extracted
+	suite.bucket.EXPECT().Open(gomock.Any(), "handbook/changed.pdf").Return(io.NopCloser(strings.NewReader("changed pdf")), nil)
+	suite.bucket.EXPECT().Open(gomock.Any(), "handbook/new.pdf").Return(io.NopCloser(strings.NewReader("new pdf")), nil)
+
+	suite.extractor.EXPECT().Extract(gomock.Any(), &extract.ExtractRequest{
+		Content: []byte("changed pdf"),
+	}).Return("changed text", nil)
+	suite.extractor.EXPECT().Extract(gomock.Any(), &extract.ExtractRequest{
+		Content: []byte("new pdf"),
+	}).Return("new text", nil)
+
+	suite.store.EXPECT().UpdateKnowledgeSourceObject(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, obj *types.KnowledgeSourceObject) (*types.KnowledgeSourceObject, error) {
+			suite.Equal("knso_2", obj.ID)
+			suite.Equal("etag-3", obj.Revision)
+			suite.Equal("changed text", string(obj.Data))
+			return obj, nil
+		})
+	suite.store.EXPECT().CreateKnowledgeSourceObject(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, obj *types.KnowledgeSourceObject) (*types.KnowledgeSourceObject, error) {
+			suite.Equal("handbook/new.pdf", obj.Key)
+			suite.Equal("etag-4", obj.Revision)
+			suite.Equal("new text", string(obj.Data))
+			return obj, nil
+		})
+	suite.store.EXPECT().DeleteKnowledgeSourceObject(gomock.Any(), "knso_3").Return(nil)
+
+	data, err := suite.reconciler.getIndexingData(suite.ctx, knowledge)
+	suite.NoError(err)
+	suite.Require().Equal(3, len(data))
+
+	suite.Equal("s3://docs/handbook/unchanged.pdf", data[0].Source)
+	suite.Equal("unchanged text", string(data[0].Data))
+	suite.Equal("s3://docs/handbook/changed.pdf", data[1].Source)
+	suite.Equal("changed text", string(data[1].Data))
+	suite.Equal("s3://docs/handbook/new.pdf", data[2].Source)
+	suite.Equal("new text", string(data[2].Data))
+}
+
+func (suite *ExtractorSuite) Test_getIndexingData_S3_SkipsLargeObjects() {
+	suite.cfg.RAG.MaxSourceObjectSize = 10
+
+	knowledge := &types.Knowledge{
+		ID: "knowledge_id",
+		RAGSettings: types.RAGSettings{
+			DisableChunking: true,
+		},
+		Source: types.KnowledgeSource{
+			S3: &types.KnowledgeSourceS3{
+				Bucket: "docs",
+			},
+		},
+	}
+
+	suite.bucket.EXPECT().List(gomock.Any()).Return([]*bucket.Object{
+		{Key: "small.txt", Revision: "etag-1", Size: 5, URL: "s3://docs/small.txt"},
+		{Key: "listed-large.txt", Revision: "etag-2", Size: 11, URL: "s3://docs/listed-large.txt"},
+		{Key: "read-large.txt", Revision: "etag-3", Size: 5, URL: "s3://docs/read-large.txt"},
+	}, nil)
+	suite.bucket.EXPECT().Close().Return(nil)
+
+	// The earlier contents of an object that grew too large are removed
+	suite.store.EXPECT().ListKnowledgeSourceObjects(gomock.Any(), "knowledge_id").Return([]*types.KnowledgeSourceObject{
+		{ID: "knso_1", KnowledgeID: "knowledge_id", Key: "read-large.txt", Revision: "etag-0", Data: []byte("small")},
+	}, nil)
+
+	// Objects listed above the limit aren't downloaded at all
+	suite.bucket.EXPECT().Open(gomock.Any(), "small.txt").Return(io.NopCloser(strings.NewReader("small")), nil)
+	suite.bucket.EXPECT().Open(gomock.Any(), "read-large.txt").Return(io.NopCloser(strings.NewReader("larger than listed")), nil)
+
+	suite.store.EXPECT().CreateKnowledgeSourceObject(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, obj *types.KnowledgeSourceObject) (*types.KnowledgeSourceObject, error) {
+			suite.Equal("small.txt", obj.Key)
+			return obj, nil
+		})
+	suite.store.EXPECT().DeleteKnowledgeSourceObject(gomock.Any(), "knso_1").Return(nil)
+
+	data, err := suite.reconciler.getIndexingData(suite.ctx, knowledge)
+	suite.NoError(err)
+	suite.Require().Equal(1, len(data))
+	suite.Equal("s3://docs/small.txt", data[0].Source)
+}
+
+func (suite *ExtractorSuite) Test_getIndexingData_GCS_ExtractDisabled() {
+	knowledge := &types.Knowledge{
+		ID: "knowledge_id",
+		RAGSettings: types.RAGSettings{
+			DisableChunking: true,
+		},
+		Source: types.KnowledgeSource{
+			GCS: &types.KnowledgeSourceGCS{
+				Bucket: "docs",
+			},
+		},
+	}
+
+	suite.bucket.EXPECT().List(gomock.Any()).Return([]*bucket.Object{
+		{Key: "report.csv", Revision: "1700000000", URL: "gs://docs/report.csv"},
+	}, nil)
+	suite.bucket.EXPECT().Close().Return(nil)
+
+	// Previously extracted as text, needs to be downloaded again in raw mode
+	suite.store.EXPECT().ListKnowledgeSourceObjects(gomock.Any(), "knowledge_id").Return([]*types.KnowledgeSourceObject{
+		{ID: "knso_1", KnowledgeID: "knowledge_id", Key: "report.csv", Revision: "1700000000", Extracted: true, Data: []byte("text")},
+	}, nil)
+
+	suite.bucket.EXPECT().Open(gomock.Any(), "report.csv").Return(io.NopCloser(strings.NewReader("a,b,c")), nil)
+
+	suite.store.EXPECT().UpdateKnowledgeSourceObject(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, obj *types.KnowledgeSourceObject) (*types.KnowledgeSourceObject, error) {
+			suite.False(obj.Extracted)
+			return obj, nil
+		})
+
+	data, err := suite.reconciler.getIndexingData(suite.ctx, knowledge)
+	suite.NoError(err)
+	suite.Require().Equal(1, len(data))
+	suite.Equal("gs://docs/report.csv", data[0].Source)
+	suite.Equal("a,b,c", string(data[0].Data))
+}
+
+func (suite *ExtractorSuite) Test_getIndexingData_Github() {
+	knowledge := &types.Knowledge{
+		ID: "knowledge_id",
+		Source: types.KnowledgeSource{
+			Github: &types.KnowledgeSourceGithub{
+				Owner:            "helixml",
+				Repository:       "helix",
+				FilterPaths:      []string{"docs/"},
+				FilterExtensions: []string{"md"},
+			},
+		},
+	}
+
+	repo, commit := newTestRepo(suite.T(), map[string]string{
+		"README.md":      "readme",
+		"main.go":        "package main",
+		"docs/guide.md":  "guide",
+		"docs/api.MD":    "api",
+		"docs/logo.png":  "png",
+		"docsite/faq.md": "faq",
+	})
+
+	suite.reconciler.openRepo = func(ctx context.Context, k *types.Knowledge) (*git.Repository, error) {
+		return repo, nil
+	}
+
+	// Guide was indexed from a previous commit and hasn't changed since
+	suite.store.EXPECT().ListKnowledgeSourceObjects(gomock.Any(), "knowledge_id").Return([]*types.KnowledgeSourceObject{
+		{
+			ID:          "knso_1",
+			KnowledgeID: "knowledge_id",
+			Key:         "docs/guide.md",
+			Revision:    plumbing.ComputeHash(plumbing.BlobObject, []byte("guide")).String(),
+			Extracted:   true,
+			Data:        []byte("guide text"),
+		},
+	}, nil)
+
+	suite.extractor.EXPECT().Extract(gomock.Any(), &extract.ExtractRequest{
+		Content: []byte("api"),
+	}).Return("api text", nil)
+
+	suite.store.EXPECT().CreateKnowledgeSourceObject(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, obj *types.KnowledgeSourceObject) (*types.KnowledgeSourceObject, error) {
+			suite.Equal("docs/api.MD", obj.Key)
+			suite.Equal(plumbing.ComputeHash(plumbing.BlobObject, []byte("api")).String(), obj.Revision)
+			return obj, nil
+		})
+
+	data, err := suite.reconciler.getIndexingData(suite.ctx, knowledge)
+	suite.NoError(err)
+	suite.Require().Equal(2, len(data))
+
+	suite.Equal("https://github.com/helixml/helix/blob/"+commit.String()+"/docs/api.MD", data[0].Source)
+	suite.Equal("api text", string(data[0].Data))
+	suite.Equal("https://github.com/helixml/helix/blob/"+commit.String()+"/docs/guide.md", data[1].Source)
+	suite.Equal("guide text", string(data[1].Data))
+}
+
+func (suite *ExtractorSuite) Test_getIndexingData_Github_Unchanged() {
+	knowledge := &types.Knowledge{
+		ID: "knowledge_id",
+		Source: types.KnowledgeSource{
+			Github: &types.KnowledgeSourceGithub{
+				Owner:       "helixml",
+				Repository:  "helix",
+				FilterPaths: []string{"docs/"},
+			},
+		},
+	}
+
+	repo, indexed := newTestRepo(suite.T(), map[string]string{
+		"main.go":       "package main",
+		"docs/guide.md": "guide",
+	})
+	knowledge.SourceRevision = indexed.String()
+
+	suite.reconciler.openRepo = func(ctx context.Context, k *types.Knowledge) (*git.Repository, error) {
+		return repo, nil
+	}
+
+	// Nothing was pushed since the last indexed commit
+	_, err := suite.reconciler.getIndexingData(suite.ctx, knowledge)
+	suite.ErrorIs(err, errSourceUnchanged)
+
+	// Pushes that don't touch the indexed files don't index it again either
+	commit := commitTestFiles(suite.T(), repo, map[string]string{
+		"main.go": "package main\n\nfunc main() {}",
+	})
+
+	_, err = suite.reconciler.getIndexingData(suite.ctx, knowledge)
+	suite.ErrorIs(err, errSourceUnchanged)
+	suite.Equal(commit.String(), knowledge.SourceRevision)
+
+	// Only the changed guide is extracted again
+	commit = commitTestFiles(suite.T(), repo, map[string]string{
+		"docs/guide.md": "new guide",
+	})
+
+	suite.store.EXPECT().ListKnowledgeSourceObjects(gomock.Any(), "knowledge_id").Return([]*types.KnowledgeSourceObject{
+		{
+			ID:          "knso_1",
+			KnowledgeID: "knowledge_id",
+			Key:         "docs/guide.md",
+			Revision:    plumbing.ComputeHash(plumbing.BlobObject, []byte("guide")).String(),
+			Extracted:   true,
+			Data:        []byte("guide text"),
+		},
+	}, nil)
+
+	suite.extractor.EXPECT().Extract(gomock.Any(), &extract.ExtractRequest{
+		Content: []byte("new guide"),
+	}).Return("new guide text", nil)
+
+	suite.store.EXPECT().UpdateKnowledgeSourceObject(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, obj *types.KnowledgeSourceObject) (*types.KnowledgeSourceObject, error) {
+			return obj, nil
+		})
+
+	data, err := suite.reconciler.getIndexingData(suite.ctx, knowledge)
+	suite.NoError(err)
+	suite.Require().Equal(1, len(data))
+	suite.Equal("new guide text", string(data[0].Data))
+	suite.Equal(commit.String(), knowledge.SourceRevision)
+}
+
+func newTestRepo(t *testing.T, files map[string]string) (*git.Repository, plumbing.Hash) {
+	repo, err := git.Init(memory.NewStorage(), memfs.New())
+	require.NoError(t, err)
+
+	return repo, commitTestFiles(t, repo, files)
+}
+
+func commitTestFiles(t *testing.T, repo *git.Repository, files map[string]string) plumbing.Hash {
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	for name, content := range files {
+		f, err := worktree.Filesystem.Create(name)
+		require.NoError(t, err)
+
+		_, err = f.Write([]byte(content))
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		_, err = worktree.Add(name)
+		require.NoError(t, err)
+	}
+
+	commit, err := worktree.Commit("update files", &git.CommitOptions{
+		Author: &object.Signature{
+			Name:  "helix",
+			Email: "helix@example.com",
+			When:  time.Now(),
+		},
+	})
+	require.NoError(t, err)
+
+	return commit
+}
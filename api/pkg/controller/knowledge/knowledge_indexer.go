@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"sort"
 	"sync/atomic"
@@ -56,6 +57,8 @@ func (r *Reconciler) index(ctx context.Context) error {
 
 				k.State = types.KnowledgeStateError
 				k.Message = err.Error()
+				// Index everything again on the next run
+				k.SourceRevision = ""
 				_, _ = r.store.UpdateKnowledge(ctx, k)
 
 				// Create a failed version too just for logs
@@ -92,6 +95,19 @@ func (r *Reconciler) indexKnowledge(ctx context.Context, k *types.Knowledge, ver
 	r.updateProgress(k, types.KnowledgeStateIndexing, "retrieving data for indexing", 0)
 
 	data, err := r.getIndexingData(ctx, k)
+	if errors.Is(err, errSourceUnchanged) {
+		log.Info().
+			Str("knowledge_id", k.ID).
+			Str("version", k.Version).
+			Msg("knowledge source unchanged, keeping the current version")
+
+		k.State = types.KnowledgeStateReady
+		_, err = r.store.UpdateKnowledge(ctx, k)
+		if err != nil {
+			return fmt.Errorf("failed to update knowledge, error: %w", err)
+		}
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("failed to get indexing data, error: %w", err)
 	}
@@ -40,5 +40,11 @@ func Validate(k *types.AssistantKnowledge) error {
 		}
 	}
 
+	if k.Source.Github != nil {
+		if k.Source.Github.Owner == "" || k.Source.Github.Repository == "" {
+			return fmt.Errorf("github owner and repository are required")
+		}
+	}
+
 	return nil
 }
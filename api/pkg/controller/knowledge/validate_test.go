@@ -68,6 +68,31 @@ func TestValidate(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name: "Github source without repository",
+			knowledge: &types.AssistantKnowledge{
+				Name: "Test",
+				Source: types.KnowledgeSource{
+					Github: &types.KnowledgeSourceGithub{
+						Owner: "helixml",
+					},
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "Valid github source",
+			knowledge: &types.AssistantKnowledge{
+				Name: "Test",
+				Source: types.KnowledgeSource{
+					Github: &types.KnowledgeSourceGithub{
+						Owner:      "helixml",
+						Repository: "helix",
+					},
+				},
+			},
+			expectError: false,
+		},
 		// Add more test cases for web source validation if needed
 	}
 
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"context"
+
+	oai "github.com/helixml/helix/api/pkg/openai"
+	"github.com/helixml/helix/api/pkg/types"
+
+	"github.com/rs/zerolog/log"
+)
+
+// resolvePriorityClass picks the scheduling priority class of the work, the
+// class of the API key takes precedence over the class of the app. An empty
+// class is scheduled with the default class.
+func (c *Controller) resolvePriorityClass(ctx context.Context, priorityClass, appID string) string {
+	if priorityClass != "" || appID == "" {
+		return priorityClass
+	}
+
+	app, err := c.Options.Store.GetApp(ctx, appID)
+	if err != nil {
+		log.Warn().Err(err).Str("app_id", appID).Msg("failed to get the app priority class")
+		return ""
+	}
+
+	return app.Config.Helix.PriorityClass
+}
+
+// withPriorityClass sets the priority class of the LLM calls made for the user,
+// the class of the API key takes precedence over the class of the app
+func withPriorityClass(ctx context.Context, user *types.User, app *types.App) context.Context {
+	vals, ok := oai.GetContextValues(ctx)
+	if !ok {
+		return ctx
+	}
+
+	priorityClass := user.PriorityClass
+	if priorityClass == "" && app != nil {
+		priorityClass = app.Config.Helix.PriorityClass
+	}
+	if priorityClass == "" {
+		return ctx
+	}
+
+	updated := *vals
+	updated.PriorityClass = priorityClass
+	return oai.SetContextValues(ctx, &updated)
+}
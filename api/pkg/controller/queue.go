@@ -154,16 +154,21 @@ func (c *Controller) ShiftSessionQueue(ctx context.Context, filter types.Session
 	c.sessionQueueMtx.Lock()
 	defer c.sessionQueueMtx.Unlock()
 
-	// Schedule all new sessions in the queue, until we run out of runners
-	taken := 0
+	// Schedule all new sessions in the queue, until we run out of runners. The scheduler
+	// decides the order so that every owner gets a fair share.
+	works := make([]*scheduler.Workload, 0, len(c.sessionQueue))
 	for _, session := range c.sessionQueue {
-		log.Info().Str("session_id", session.ID).Msg("scheduling session")
 		work, err := scheduler.NewSessonWorkload(session)
 		if err != nil {
 			return nil, fmt.Errorf("creating session workload: %w", err)
 		}
+		works = append(works, work)
+	}
 
-		err = c.scheduler.Schedule(work)
+	taken := make(map[string]bool)
+	for _, work := range c.scheduler.Prioritize(works) {
+		log.Info().Str("session_id", work.ID()).Msg("scheduling session")
+		err := c.scheduler.Schedule(work)
 		if err != nil {
 			retry, err := scheduler.ErrorHandlingStrategy(err, work)
 
@@ -175,20 +180,32 @@ func (c *Controller) ShiftSessionQueue(ctx context.Context, filter types.Session
 			// If we can't retry, write an error to the request and continue so it takes it off
 			// the queue
 			errSession := work.Session()
-			errSession.Interactions = append(errSession.Interactions, &types.Interaction{
-				Creator: types.CreatorTypeSystem,
-				Error:   err.Error(),
-				Message: "Error scheduling session",
-			})
-			_, err = c.Options.Store.UpdateSession(ctx, *errSession)
+			errInteraction := &types.Interaction{
+				SessionID: errSession.ID,
+				Creator:   types.CreatorTypeSystem,
+				Error:     err.Error(),
+				Message:   "Error scheduling session",
+			}
+			errSession.Interactions = append(errSession.Interactions, errInteraction)
+			_, err = c.Options.Store.CreateInteraction(ctx, errInteraction)
 			if err != nil {
 				log.Error().Err(err).Msg("error updating session")
 			}
 		}
-		taken++
+		taken[work.ID()] = true
+	}
+
+	sessionQueue := make([]*types.Session, 0, len(c.sessionQueue))
+	sessionSummaryQueue := make([]*types.SessionSummary, 0, len(c.sessionSummaryQueue))
+	for i, session := range c.sessionQueue {
+		if taken[session.ID] {
+			continue
+		}
+		sessionQueue = append(sessionQueue, session)
+		sessionSummaryQueue = append(sessionSummaryQueue, c.sessionSummaryQueue[i])
 	}
-	c.sessionQueue = c.sessionQueue[taken:]
-	c.sessionSummaryQueue = c.sessionSummaryQueue[taken:]
+	c.sessionQueue = sessionQueue
+	c.sessionSummaryQueue = sessionSummaryQueue
 
 	// Default to requesting warm work
 	newWorkOnly := false
@@ -208,13 +225,14 @@ func (c *Controller) ShiftSessionQueue(ctx context.Context, filter types.Session
 		return nil, nil
 	}
 
-	c.addSchedulingDecision(filter, runnerID, req.Session())
+	c.addSchedulingDecision(filter, runnerID, req)
 	log.Info().Str("runnerID", runnerID).Interface("filter", filter).Interface("req", req).Int("len(sessionQueue)", len(c.sessionQueue)).Msgf("🟠 helix_openai_server GetNextLLMInferenceRequest END")
 	return req.Session(), nil
 }
 
 // TODO: remove
-func (c *Controller) addSchedulingDecision(filter types.SessionFilter, runnerID string, session *types.Session) {
+func (c *Controller) addSchedulingDecision(filter types.SessionFilter, runnerID string, work *scheduler.Workload) {
+	session := work.Session()
 	assistantInteraction, err := data.GetAssistantInteraction(session)
 	if err != nil {
 		log.Error().Msgf("error adding scheduling decision: %s", err)
@@ -228,6 +246,10 @@ func (c *Controller) addSchedulingDecision(filter types.SessionFilter, runnerID
 		Filter:        filter,
 		ModelName:     session.ModelName,
 		Mode:          session.Mode,
+		Owner:         session.Owner,
+		PriorityClass: work.Decision().PriorityClass,
+		Reason:        work.Decision().Reason,
+		Preempted:     work.Decision().Preempted,
 	}
 
 	c.schedulingDecisions = append([]*types.GlobalSchedulingDecision{decision}, c.schedulingDecisions...)
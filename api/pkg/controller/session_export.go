@@ -0,0 +1,541 @@
+package controller
+
+import (
+	"archive/tar"
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/helixml/helix/api/pkg/data"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+// Layout of the session export bundle
+const (
+	sessionExportSessionFile    = "session.json"
+	sessionExportTranscriptFile = "transcript.md"
+	sessionExportFilesFolder    = "files"
+	// files referenced by the session that live outside of its folder and
+	// have no session relative path
+	sessionExportExternalFolder = "external"
+)
+
+// ExportSession writes the session in the given format, the tar bundle is
+// self-contained and can be imported with ImportSession
+func (c *Controller) ExportSession(ctx context.Context, session *types.Session, format types.SessionExportFormat, w io.Writer) error {
+	export, externalFiles, err := c.newSessionExport(session)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case types.SessionExportFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(export)
+	case types.SessionExportFormatMarkdown:
+		_, err := io.WriteString(w, RenderSessionTranscript(export))
+		return err
+	case types.SessionExportFormatTar, "":
+		return c.writeSessionExportTar(ctx, session, export, externalFiles, w)
+	default:
+		return fmt.Errorf("unknown export format: %s", format)
+	}
+}
+
+// newSessionExport copies the session with the file paths made relative to
+// the session folder, it also returns the referenced files that live outside
+// of the folder, e.g. the files of cloned sessions, keyed by their relative path
+func (c *Controller) newSessionExport(session *types.Session) (*types.SessionExport, map[string]string, error) {
+	prefix, err := c.GetFilestoreSessionPath(types.OwnerContext{Owner: session.Owner, OwnerType: session.OwnerType}, session.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Deep copy, the paths are rewritten below
+	bts, err := json.Marshal(session)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode session: %w", err)
+	}
+	var exported types.Session
+	if err := json.Unmarshal(bts, &exported); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode session: %w", err)
+	}
+
+	externalFiles := map[string]string{}
+	relative := func(filePath string) string {
+		if filePath == "" {
+			return ""
+		}
+		rel, external := sessionRelativePath(prefix, filePath)
+		if external {
+			externalFiles[rel] = filePath
+		}
+		return rel
+	}
+
+	// Folders outside of the session folder are not exported
+	relativeFolder := func(folderPath string) string {
+		if folderPath == "" {
+			return ""
+		}
+		rel, external := sessionRelativePath(prefix, folderPath)
+		if external {
+			return ""
+		}
+		return rel
+	}
+
+	for _, interaction := range exported.Interactions {
+		for i, file := range interaction.Files {
+			interaction.Files[i] = relative(file)
+		}
+		interaction.LoraDir = relativeFolder(interaction.LoraDir)
+	}
+
+	exported.LoraDir = relativeFolder(exported.LoraDir)
+
+	if len(exported.Metadata.DocumentIDs) > 0 {
+		documentIDs := map[string]string{}
+		for filename, documentID := range exported.Metadata.DocumentIDs {
+			documentIDs[relative(filename)] = documentID
+		}
+		exported.Metadata.DocumentIDs = documentIDs
+	}
+
+	return &types.SessionExport{
+		Version:      types.SessionExportVersion,
+		HelixVersion: data.GetHelixVersion(),
+		Exported:     time.Now(),
+		Session:      &exported,
+	}, externalFiles, nil
+}
+
+// sessionRelativePath returns the path of the file relative to the session
+// folder and whether the file lives outside of it
+func sessionRelativePath(prefix, filePath string) (string, bool) {
+	if rel, ok := strings.CutPrefix(filePath, prefix+"/"); ok {
+		return rel, false
+	}
+
+	// The files of cloned sessions can point at the folder of the original
+	// session, they keep their path within that folder
+	if _, rest, ok := strings.Cut(filePath, "/"+GetSessionFolder("")+"/"); ok {
+		if _, rel, ok := strings.Cut(rest, "/"); ok && rel != "" {
+			return rel, true
+		}
+	}
+
+	return path.Join(sessionExportExternalFolder, path.Base(filePath)), true
+}
+
+func (c *Controller) writeSessionExportTar(ctx context.Context, session *types.Session, export *types.SessionExport, externalFiles map[string]string, w io.Writer) error {
+	tw := tar.NewWriter(w)
+
+	bts, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+	if err := writeTarFile(tw, sessionExportSessionFile, export.Exported, bts); err != nil {
+		return err
+	}
+
+	if err := writeTarFile(tw, sessionExportTranscriptFile, export.Exported, []byte(RenderSessionTranscript(export))); err != nil {
+		return err
+	}
+
+	prefix, err := c.GetFilestoreSessionPath(types.OwnerContext{Owner: session.Owner, OwnerType: session.OwnerType}, session.ID)
+	if err != nil {
+		return err
+	}
+
+	written := map[string]bool{}
+
+	// Sessions without files have no folder
+	if _, err := c.Options.Filestore.Get(ctx, prefix); err == nil {
+		if err := c.copySessionFolderToTar(ctx, prefix, tw, written); err != nil {
+			return err
+		}
+	}
+
+	rels := make([]string, 0, len(externalFiles))
+	for rel := range externalFiles {
+		rels = append(rels, rel)
+	}
+	sort.Strings(rels)
+
+	// The file paths come from the session, only the files of the owner are
+	// exported so a crafted session can't read the files of other users
+	ownerPrefix, err := c.GetFilestoreUserPath(types.OwnerContext{Owner: session.Owner, OwnerType: session.OwnerType}, "")
+	if err != nil {
+		return err
+	}
+
+	for _, rel := range rels {
+		if written[rel] {
+			continue
+		}
+		if !isFilestorePathUnder(ownerPrefix, externalFiles[rel]) {
+			log.Warn().Str("session_id", session.ID).Str("file", externalFiles[rel]).Msg("skipping session file outside of the owner's filestore")
+			continue
+		}
+		if err := c.copyFileToTar(ctx, externalFiles[rel], path.Join(sessionExportFilesFolder, rel), tw); err != nil {
+			// The original session might have been deleted since
+			log.Warn().Err(err).Str("session_id", session.ID).Str("file", externalFiles[rel]).Msg("failed to export session file")
+		}
+	}
+
+	return tw.Close()
+}
+
+// isFilestorePathUnder returns true if the path is within the folder, the
+// path is cleaned first so ".." can't be used to climb out of the folder
+func isFilestorePathUnder(folder, filePath string) bool {
+	return strings.HasPrefix(path.Clean(filePath), path.Clean(folder)+"/")
+}
+
+func (c *Controller) copySessionFolderToTar(ctx context.Context, prefix string, tw *tar.Writer, written map[string]bool) error {
+	reader, err := c.Options.Filestore.DownloadFolder(ctx, prefix)
+	if err != nil {
+		return fmt.Errorf("failed to download session files: %w", err)
+	}
+	if closer, ok := reader.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	tr := tar.NewReader(reader)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read session files: %w", err)
+		}
+
+		name := sessionExportEntryName(header.Name)
+		if name == "" {
+			continue
+		}
+
+		written[strings.TrimSuffix(name, "/")] = true
+
+		header.Name = path.Join(sessionExportFilesFolder, name)
+		if header.Typeflag == tar.TypeDir {
+			header.Name += "/"
+		}
+
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Controller) copyFileToTar(ctx context.Context, filePath, name string, tw *tar.Writer) error {
+	item, err := c.Options.Filestore.Get(ctx, filePath)
+	if err != nil {
+		return err
+	}
+
+	r, err := c.Options.Filestore.OpenFile(ctx, filePath)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	err = tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    item.Size,
+		ModTime: time.Unix(item.Created, 0),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(tw, r)
+	return err
+}
+
+func writeTarFile(tw *tar.Writer, name string, modTime time.Time, content []byte) error {
+	err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(content)),
+		ModTime: modTime,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = tw.Write(content)
+	return err
+}
+
+// sessionExportEntryName cleans the tar entry name so it can't escape the
+// folder it is extracted to, the root of the archive is returned as ""
+func sessionExportEntryName(name string) string {
+	cleaned := strings.TrimPrefix(path.Clean("/"+name), "/")
+	if cleaned != "" && strings.HasSuffix(name, "/") {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+// ImportSession recreates an exported session, as a tar bundle or JSON, under
+// the owner. The session gets a new ID and its files are copied into the
+// owner's filestore. References that only make sense in the instance the
+// session was exported from, like data entities, are dropped.
+func (c *Controller) ImportSession(ctx context.Context, owner *types.User, r io.Reader) (*types.Session, error) {
+	session := &types.Session{
+		ID:        system.GenerateSessionID(),
+		Owner:     owner.ID,
+		OwnerType: owner.Type,
+	}
+
+	prefix, err := c.GetFilestoreSessionPath(types.OwnerContext{Owner: session.Owner, OwnerType: session.OwnerType}, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	export, err := c.readSessionExport(ctx, prefix, r)
+	if err != nil {
+		// Don't leave the files of a broken import behind
+		if _, getErr := c.Options.Filestore.Get(ctx, prefix); getErr == nil {
+			if deleteErr := c.Options.Filestore.Delete(ctx, prefix); deleteErr != nil {
+				log.Error().Err(deleteErr).Str("path", prefix).Msg("failed to delete the files of a failed session import")
+			}
+		}
+		return nil, err
+	}
+
+	imported := export.Session
+	absolute := func(rel string) string {
+		if rel == "" {
+			return ""
+		}
+		return path.Join(prefix, sessionExportEntryName(rel))
+	}
+
+	for _, interaction := range imported.Interactions {
+		interaction.SessionID = session.ID
+		for i, file := range interaction.Files {
+			interaction.Files[i] = absolute(file)
+		}
+		interaction.LoraDir = absolute(interaction.LoraDir)
+	}
+
+	if len(imported.Metadata.DocumentIDs) > 0 {
+		documentIDs := map[string]string{}
+		for filename, documentID := range imported.Metadata.DocumentIDs {
+			documentIDs[absolute(filename)] = documentID
+		}
+		imported.Metadata.DocumentIDs = documentIDs
+	}
+
+	metadata := imported.Metadata
+	metadata.Origin = types.SessionOrigin{
+		Type:              types.SessionOriginTypeImported,
+		ImportedSessionID: imported.ID,
+	}
+	metadata.Shared = false
+	metadata.DocumentGroupID = ""
+	metadata.UploadedDataID = ""
+	metadata.RAGSourceID = ""
+	metadata.LoraID = ""
+	metadata.EvalRunId = ""
+	// Only admins can raise the priority, the bundle could be edited
+	metadata.Priority = false
+	metadata.PriorityClass = ""
+
+	session.Name = imported.Name
+	session.Created = time.Now()
+	session.Updated = time.Now()
+	session.Metadata = metadata
+	session.Mode = imported.Mode
+	session.Type = imported.Type
+	session.ModelName = imported.ModelName
+	session.LoraDir = absolute(imported.LoraDir)
+	session.Interactions = imported.Interactions
+
+	// Only keep the app if it exists here and the owner can use it
+	if imported.ParentApp != "" {
+		app, err := c.Options.Store.GetApp(ctx, imported.ParentApp)
+		switch {
+		case err == nil && (app.Global || app.Shared || owner.HasOwnerRole(app.Owner, app.OwnerType, types.OrganizationRoleMember)):
+			session.ParentApp = app.ID
+		case err != nil && !errors.Is(err, store.ErrNotFound):
+			return nil, fmt.Errorf("failed to get app %s: %w", imported.ParentApp, err)
+		default:
+			session.Metadata.AssistantID = ""
+			session.Metadata.AppQueryParams = nil
+		}
+	}
+
+	return c.Options.Store.CreateSession(ctx, *session)
+}
+
+// readSessionExport reads the session and writes the bundle files under the prefix
+func (c *Controller) readSessionExport(ctx context.Context, prefix string, r io.Reader) (*types.SessionExport, error) {
+	br := bufio.NewReader(r)
+
+	// JSON exports start with the object, tar bundles with the name of the first entry
+	b, err := br.Peek(1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session export: %w", err)
+	}
+	if b[0] == '{' {
+		return decodeSessionExport(br)
+	}
+
+	var export *types.SessionExport
+
+	tr := tar.NewReader(br)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read session export, expected a tar or JSON export: %w", err)
+		}
+
+		name := sessionExportEntryName(header.Name)
+
+		switch {
+		case name == sessionExportSessionFile:
+			export, err = decodeSessionExport(tr)
+			if err != nil {
+				return nil, err
+			}
+		case header.Typeflag == tar.TypeReg && strings.HasPrefix(name, sessionExportFilesFolder+"/"):
+			rel := strings.TrimPrefix(name, sessionExportFilesFolder+"/")
+			_, err = c.Options.Filestore.WriteFile(ctx, path.Join(prefix, rel), tr)
+			if err != nil {
+				return nil, fmt.Errorf("failed to import session file %s: %w", rel, err)
+			}
+		}
+	}
+
+	if export == nil {
+		return nil, fmt.Errorf("not a session export, %s not found", sessionExportSessionFile)
+	}
+
+	return export, nil
+}
+
+func decodeSessionExport(r io.Reader) (*types.SessionExport, error) {
+	var export types.SessionExport
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, fmt.Errorf("failed to decode session export: %w", err)
+	}
+
+	if export.Session == nil {
+		return nil, fmt.Errorf("session export has no session")
+	}
+
+	if export.Version > types.SessionExportVersion {
+		return nil, fmt.Errorf("session export version %d is not supported, upgrade Helix to import it", export.Version)
+	}
+
+	return &export, nil
+}
+
+// RenderSessionTranscript renders the exported session as markdown, e.g. to
+// attach to bug reports
+func RenderSessionTranscript(export *types.SessionExport) string {
+	session := export.Session
+
+	var sb strings.Builder
+
+	name := session.Name
+	if name == "" {
+		name = session.ID
+	}
+	fmt.Fprintf(&sb, "# %s\n\n", name)
+
+	fmt.Fprintf(&sb, "- Session: `%s`\n", session.ID)
+	if session.ModelName != "" {
+		fmt.Fprintf(&sb, "- Model: `%s`\n", session.ModelName)
+	}
+	fmt.Fprintf(&sb, "- Mode: %s\n", session.Mode)
+	if session.ParentApp != "" {
+		fmt.Fprintf(&sb, "- App: `%s`\n", session.ParentApp)
+	}
+	fmt.Fprintf(&sb, "- Created: %s\n", session.Created.Format(time.RFC3339))
+	fmt.Fprintf(&sb, "- Exported: %s (Helix %s)\n", export.Exported.Format(time.RFC3339), export.HelixVersion)
+
+	if session.Metadata.SystemPrompt != "" {
+		fmt.Fprintf(&sb, "\n## System prompt\n\n%s\n", session.Metadata.SystemPrompt)
+	}
+
+	for _, interaction := range session.Interactions {
+		fmt.Fprintf(&sb, "\n## %s\n\n", transcriptCreator(interaction.Creator))
+		fmt.Fprintf(&sb, "_%s_\n\n", interaction.Created.Format(time.RFC3339))
+
+		message := interaction.DisplayMessage
+		if message == "" {
+			message = interaction.Message
+		}
+		if message != "" {
+			fmt.Fprintf(&sb, "%s\n", message)
+		}
+
+		if interaction.Error != "" {
+			fmt.Fprintf(&sb, "\n> **Error:** %s\n", interaction.Error)
+		}
+
+		if len(interaction.Files) > 0 {
+			sb.WriteString("\n**Files**\n\n")
+			for _, file := range interaction.Files {
+				fmt.Fprintf(&sb, "- `%s`\n", file)
+			}
+		}
+
+		if len(interaction.ToolCalls) > 0 {
+			sb.WriteString("\n**Tool calls**\n\n")
+			for _, call := range interaction.ToolCalls {
+				fmt.Fprintf(&sb, "- `%s(%s)`\n", call.Function.Name, call.Function.Arguments)
+			}
+		}
+
+		if len(interaction.RagResults) > 0 {
+			sb.WriteString("\n**Sources**\n\n")
+			for i, result := range interaction.RagResults {
+				source := result.Source
+				if source == "" {
+					source = result.Filename
+				}
+				fmt.Fprintf(&sb, "%d. %s\n", i+1, source)
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+func transcriptCreator(creator types.CreatorType) string {
+	switch creator {
+	case types.CreatorTypeUser:
+		return "User"
+	case types.CreatorTypeAssistant:
+		return "Assistant"
+	case types.CreatorTypeTool:
+		return "Tool"
+	default:
+		return "System"
+	}
+}
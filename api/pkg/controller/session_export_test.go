@@ -0,0 +1,285 @@
+package controller
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/filestore"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func newSessionExportController(t *testing.T) (*Controller, *store.MockStore, filestore.FileStore) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+
+	cfg := &config.ServerConfig{}
+	cfg.Controller.FilePrefixGlobal = "dev"
+
+	fs := filestore.NewFileSystemStorage(t.TempDir(), "http://localhost/files", "secret")
+
+	c := &Controller{}
+	c.Options.Config = cfg
+	c.Options.Store = storeMock
+	c.Options.Filestore = fs
+
+	return c, storeMock, fs
+}
+
+func newExportedSession(t *testing.T, fs filestore.FileStore) *types.Session {
+	ctx := context.Background()
+
+	_, err := fs.WriteFile(ctx, "dev/users/user_1/sessions/ses_1/inputs/int_1/notes.txt", strings.NewReader("hello notes"))
+	require.NoError(t, err)
+	// Cloned sessions point at the files of the original session
+	_, err = fs.WriteFile(ctx, "dev/users/user_1/sessions/ses_0/inputs/int_0/old.txt", strings.NewReader("hello old"))
+	require.NoError(t, err)
+
+	return &types.Session{
+		ID:        "ses_1",
+		Name:      "billing api",
+		Owner:     "user_1",
+		OwnerType: types.OwnerTypeUser,
+		Mode:      types.SessionModeInference,
+		Type:      types.SessionTypeText,
+		ModelName: "llama3:instruct",
+		ParentApp: "app_1",
+		Created:   time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		Metadata: types.SessionMetadata{
+			RAGSourceID:  "data_1",
+			SystemPrompt: "You are a billing expert",
+			DocumentIDs: map[string]string{
+				"dev/users/user_1/sessions/ses_1/inputs/int_1/notes.txt": "doc_1",
+			},
+		},
+		Interactions: types.Interactions{
+			{
+				ID:      "int_1",
+				Creator: types.CreatorTypeUser,
+				Message: "How do I paginate invoices?",
+				Files: []string{
+					"dev/users/user_1/sessions/ses_1/inputs/int_1/notes.txt",
+					"dev/users/user_1/sessions/ses_0/inputs/int_0/old.txt",
+				},
+			},
+			{
+				ID:      "int_2",
+				Creator: types.CreatorTypeAssistant,
+				Message: "Pass a cursor.",
+				ToolCalls: []openai.ToolCall{
+					{Function: openai.FunctionCall{Name: "list_invoices", Arguments: `{"cursor":"abc"}`}},
+				},
+				RagResults: []*types.SessionRAGResult{
+					{DocumentID: "doc_1", Source: "notes.txt"},
+				},
+			},
+		},
+	}
+}
+
+func readTar(t *testing.T, r io.Reader) map[string]string {
+	files := map[string]string{}
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		require.NoError(t, err)
+
+		bts, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[header.Name] = string(bts)
+	}
+}
+
+func TestExportSession_Tar(t *testing.T) {
+	c, _, fs := newSessionExportController(t)
+	session := newExportedSession(t, fs)
+
+	var buf bytes.Buffer
+	require.NoError(t, c.ExportSession(context.Background(), session, types.SessionExportFormatTar, &buf))
+
+	files := readTar(t, &buf)
+
+	assert.Equal(t, "hello notes", files["files/inputs/int_1/notes.txt"])
+	assert.Equal(t, "hello old", files["files/inputs/int_0/old.txt"])
+
+	var export types.SessionExport
+	require.NoError(t, json.Unmarshal([]byte(files["session.json"]), &export))
+	assert.Equal(t, types.SessionExportVersion, export.Version)
+	assert.Equal(t, []string{"inputs/int_1/notes.txt", "inputs/int_0/old.txt"}, export.Session.Interactions[0].Files)
+	assert.Equal(t, map[string]string{"inputs/int_1/notes.txt": "doc_1"}, export.Session.Metadata.DocumentIDs)
+
+	// The exported session is not modified
+	assert.Equal(t, "dev/users/user_1/sessions/ses_1/inputs/int_1/notes.txt", session.Interactions[0].Files[0])
+
+	transcript := files["transcript.md"]
+	assert.Contains(t, transcript, "# billing api")
+	assert.Contains(t, transcript, "## User")
+	assert.Contains(t, transcript, "How do I paginate invoices?")
+	assert.Contains(t, transcript, "`list_invoices({\"cursor\":\"abc\"})`")
+	assert.Contains(t, transcript, "1. notes.txt")
+}
+
+func TestExportSession_OtherOwnersFiles(t *testing.T) {
+	c, _, fs := newSessionExportController(t)
+	session := newExportedSession(t, fs)
+
+	ctx := context.Background()
+	_, err := fs.WriteFile(ctx, "dev/users/user_2/sessions/ses_2/inputs/int_2/private.txt", strings.NewReader("private"))
+	require.NoError(t, err)
+
+	session.Interactions[0].Files = append(session.Interactions[0].Files,
+		"dev/users/user_2/sessions/ses_2/inputs/int_2/private.txt",
+		"dev/users/user_1/../user_2/sessions/ses_2/inputs/int_2/private.txt",
+	)
+
+	var buf bytes.Buffer
+	require.NoError(t, c.ExportSession(ctx, session, types.SessionExportFormatTar, &buf))
+
+	files := readTar(t, &buf)
+	assert.Equal(t, "hello old", files["files/inputs/int_0/old.txt"])
+	assert.NotContains(t, files, "files/inputs/int_2/private.txt")
+	for name, content := range files {
+		assert.NotEqual(t, "private", content, name)
+	}
+}
+
+func TestImportSession(t *testing.T) {
+	c, storeMock, fs := newSessionExportController(t)
+	session := newExportedSession(t, fs)
+
+	var buf bytes.Buffer
+	require.NoError(t, c.ExportSession(context.Background(), session, types.SessionExportFormatTar, &buf))
+
+	// The app belongs to someone else
+	storeMock.EXPECT().GetApp(gomock.Any(), "app_1").Return(&types.App{ID: "app_1", Owner: "user_1", OwnerType: types.OwnerTypeUser}, nil)
+	storeMock.EXPECT().CreateSession(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, s types.Session) (*types.Session, error) {
+			return &s, nil
+		})
+
+	owner := &types.User{ID: "user_2", Type: types.OwnerTypeUser}
+
+	imported, err := c.ImportSession(context.Background(), owner, &buf)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, "ses_1", imported.ID)
+	assert.Equal(t, "user_2", imported.Owner)
+	assert.Equal(t, "billing api", imported.Name)
+	assert.Equal(t, types.SessionOriginTypeImported, imported.Metadata.Origin.Type)
+	assert.Equal(t, "ses_1", imported.Metadata.Origin.ImportedSessionID)
+	assert.Empty(t, imported.ParentApp)
+	assert.Empty(t, imported.Metadata.RAGSourceID)
+	require.Len(t, imported.Interactions, 2)
+	assert.Equal(t, imported.ID, imported.Interactions[0].SessionID)
+
+	prefix := "dev/users/user_2/sessions/" + imported.ID
+	assert.Equal(t, []string{prefix + "/inputs/int_1/notes.txt", prefix + "/inputs/int_0/old.txt"}, imported.Interactions[0].Files)
+	assert.Equal(t, map[string]string{prefix + "/inputs/int_1/notes.txt": "doc_1"}, imported.Metadata.DocumentIDs)
+
+	for path, content := range map[string]string{
+		prefix + "/inputs/int_1/notes.txt": "hello notes",
+		prefix + "/inputs/int_0/old.txt":   "hello old",
+	} {
+		r, err := fs.OpenFile(context.Background(), path)
+		require.NoError(t, err)
+		bts, err := io.ReadAll(r)
+		r.Close()
+		require.NoError(t, err)
+		assert.Equal(t, content, string(bts))
+	}
+}
+
+func TestImportSession_JSON(t *testing.T) {
+	c, storeMock, fs := newSessionExportController(t)
+	session := newExportedSession(t, fs)
+	session.ParentApp = ""
+
+	var buf bytes.Buffer
+	require.NoError(t, c.ExportSession(context.Background(), session, types.SessionExportFormatJSON, &buf))
+
+	storeMock.EXPECT().CreateSession(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, s types.Session) (*types.Session, error) {
+			return &s, nil
+		})
+
+	imported, err := c.ImportSession(context.Background(), &types.User{ID: "user_2", Type: types.OwnerTypeUser}, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, "How do I paginate invoices?", imported.Interactions[0].Message)
+}
+
+func TestImportSession_PriorityClass(t *testing.T) {
+	c, storeMock, fs := newSessionExportController(t)
+	session := newExportedSession(t, fs)
+	session.ParentApp = ""
+	session.Metadata.Priority = true
+	session.Metadata.PriorityClass = "high"
+
+	var buf bytes.Buffer
+	require.NoError(t, c.ExportSession(context.Background(), session, types.SessionExportFormatJSON, &buf))
+
+	storeMock.EXPECT().CreateSession(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, s types.Session) (*types.Session, error) {
+			return &s, nil
+		})
+
+	// The importing user can't jump the queue with an edited bundle
+	imported, err := c.ImportSession(context.Background(), &types.User{ID: "user_2", Type: types.OwnerTypeUser}, &buf)
+	require.NoError(t, err)
+	assert.False(t, imported.Metadata.Priority)
+	assert.Empty(t, imported.Metadata.PriorityClass)
+}
+
+func TestImportSession_Invalid(t *testing.T) {
+	c, _, fs := newSessionExportController(t)
+
+	// Files are cleaned up when the bundle has no session
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	require.NoError(t, writeTarFile(tw, "files/inputs/a.txt", time.Now(), []byte("hello")))
+	require.NoError(t, tw.Close())
+
+	_, err := c.ImportSession(context.Background(), &types.User{ID: "user_2", Type: types.OwnerTypeUser}, &buf)
+	assert.ErrorContains(t, err, "not a session export")
+
+	items, err := fs.List(context.Background(), "dev/users/user_2/sessions")
+	require.NoError(t, err)
+	assert.Empty(t, items)
+
+	_, err = c.ImportSession(context.Background(), &types.User{ID: "user_2"}, strings.NewReader(`{"version": 99, "session": {}}`))
+	assert.ErrorContains(t, err, "version 99 is not supported")
+}
+
+func TestSessionRelativePath(t *testing.T) {
+	prefix := "dev/users/user_1/sessions/ses_1"
+
+	rel, external := sessionRelativePath(prefix, prefix+"/results/a.png")
+	assert.Equal(t, "results/a.png", rel)
+	assert.False(t, external)
+
+	rel, external = sessionRelativePath(prefix, "dev/users/user_0/sessions/ses_0/inputs/int_0/b.txt")
+	assert.Equal(t, "inputs/int_0/b.txt", rel)
+	assert.True(t, external)
+
+	rel, external = sessionRelativePath(prefix, "dev/users/user_1/apps/app_1/c.txt")
+	assert.Equal(t, "external/c.txt", rel)
+	assert.True(t, external)
+
+	assert.Equal(t, "etc/passwd", sessionExportEntryName("../../etc/passwd"))
+	assert.Equal(t, "inputs/", sessionExportEntryName("./inputs/"))
+	assert.Equal(t, "", sessionExportEntryName("./"))
+}
@@ -17,6 +17,7 @@ import (
 
 	"github.com/helixml/helix/api/pkg/data"
 	"github.com/helixml/helix/api/pkg/notification"
+	oai "github.com/helixml/helix/api/pkg/openai"
 	"github.com/helixml/helix/api/pkg/prompts"
 	"github.com/helixml/helix/api/pkg/pubsub"
 	"github.com/helixml/helix/api/pkg/store"
@@ -72,6 +73,7 @@ func (c *Controller) StartSession(ctx context.Context, user *types.User, req typ
 				Type: types.SessionOriginTypeUserCreated,
 			},
 			Priority:                req.Priority,
+			PriorityClass:           c.resolvePriorityClass(ctx, req.PriorityClass, req.ParentApp),
 			ManuallyReviewQuestions: req.ManuallyReviewQuestions,
 			HelixVersion:            data.GetHelixVersion(),
 			RagEnabled:              req.RAGEnabled,
@@ -218,6 +220,13 @@ func (c *Controller) UpdateSession(ctx context.Context, user *types.User, req ty
 		return nil, err
 	}
 
+	req.UserInteraction.SessionID = session.ID
+	assistantInteraction.SessionID = session.ID
+	err = c.Options.Store.CreateInteractions(ctx, req.UserInteraction, assistantInteraction)
+	if err != nil {
+		return nil, err
+	}
+
 	go c.SessionRunner(sessionData)
 
 	err = c.Options.Janitor.WriteSessionEvent(types.SessionEventTypeUpdated, user, sessionData)
@@ -279,7 +288,8 @@ func (c *Controller) RestartSession(session *types.Session) (*types.Session, err
 		return nil, err
 	}
 
-	c.WriteSession(session)
+	assistantInteraction, _ := data.GetAssistantInteraction(session)
+	c.WriteSession(session, assistantInteraction)
 
 	// this will re-run the data prep preparation
 	// but that is idempotent so we should be able to
@@ -313,7 +323,7 @@ func (c *Controller) AddDocumentsToSession(ctx context.Context, session *types.S
 	session.Updated = time.Now()
 	session.Interactions = append(session.Interactions, userInteraction, assistantInteraction)
 
-	c.WriteSession(session)
+	c.WriteSession(session, userInteraction, assistantInteraction)
 	go c.SessionRunner(session)
 
 	return session, nil
@@ -353,7 +363,9 @@ func (c *Controller) AddDocumentsToInteraction(ctx context.Context, session *typ
 
 	session.Mode = types.SessionModeFinetune
 
-	c.WriteSession(session)
+	userInteraction, _ := data.GetUserInteraction(session.Interactions)
+	assistantInteraction, _ := data.GetAssistantInteraction(session)
+	c.WriteSession(session, userInteraction, assistantInteraction)
 	go c.SessionRunner(session)
 
 	return session, nil
@@ -464,7 +476,8 @@ func (c *Controller) PrepareSession(session *types.Session) (*types.Session, err
 					return nil, err
 				}
 
-				c.WriteSession(session)
+				assistantInteraction, _ := data.GetAssistantInteraction(session)
+				c.WriteSession(session, assistantInteraction)
 				c.BroadcastProgress(session, 0, "")
 			}
 		}
@@ -520,7 +533,10 @@ func (c *Controller) PrepareSession(session *types.Session) (*types.Session, err
 			}
 
 			session, err = data.UpdateUserInteraction(session, func(userInteraction *types.Interaction) (*types.Interaction, error) {
-				userInteraction.DisplayMessage = userInteraction.Message
+				// The agent might have extended the message already
+				if userInteraction.DisplayMessage == "" {
+					userInteraction.DisplayMessage = userInteraction.Message
+				}
 				injectedUserPrompt, err := prompts.RAGInferencePrompt(userInteraction.Message, ragContent)
 				if err != nil {
 					return nil, err
@@ -559,8 +575,14 @@ func (c *Controller) checkForActions(session *types.Session) (*types.Session, er
 		}
 
 		// if the tool exists but the user cannot access it - then something funky is being attempted and we should deny it
-		if (!app.Global && !app.Shared) && app.Owner != session.Owner {
-			return nil, system.NewHTTPError403(fmt.Sprintf("you do not have access to the app with the id: %s", app.ID))
+		if !app.Global && !app.Shared {
+			owner, err := c.loadSessionOwner(ctx, session)
+			if err != nil {
+				return nil, err
+			}
+			if !owner.HasOwnerRole(app.Owner, app.OwnerType, types.OrganizationRoleMember) {
+				return nil, system.NewHTTPError403(fmt.Sprintf("you do not have access to the app with the id: %s", app.ID))
+			}
 		}
 
 		if len(app.Config.Helix.Assistants) > 0 {
@@ -618,6 +640,10 @@ func (c *Controller) checkForActions(session *types.Session) (*types.Session, er
 		return nil, fmt.Errorf("failed to get last assistant interaction: %w", err)
 	}
 
+	if assistant != nil && assistant.AgentMode {
+		return c.runSessionAgent(ctx, session, lastInteraction.ID, assistant, activeTools, messageHistory)
+	}
+
 	var options []tools.Option
 
 	// If assistant has configured an actionable template, use it
@@ -648,7 +674,7 @@ func (c *Controller) checkForActions(session *types.Session) (*types.Session, er
 	lastInteraction.Metadata["tool_action"] = isActionable.Api
 	lastInteraction.Metadata["tool_action_justification"] = isActionable.Justification
 
-	actionTool, ok := getToolFromAction(activeTools, isActionable.Api)
+	actionTool, ok := tools.GetToolFromAction(activeTools, isActionable.Api)
 	if !ok {
 		return nil, fmt.Errorf("tool not found for action: %s", isActionable.Api)
 	}
@@ -659,6 +685,93 @@ func (c *Controller) checkForActions(session *types.Session) (*types.Session, er
 	return session, nil
 }
 
+// loadSessionOwner returns the owner of the session with the roles they have
+// in their organizations, sessions are run without the request's user
+func (c *Controller) loadSessionOwner(ctx context.Context, session *types.Session) (*types.User, error) {
+	owner := &types.User{
+		ID:   session.Owner,
+		Type: session.OwnerType,
+	}
+	if owner.Type == types.OwnerTypeOrg {
+		return owner, nil
+	}
+
+	memberships, err := c.Options.Store.ListOrganizationMemberships(ctx, &store.ListOrganizationMembershipsQuery{
+		UserID: session.Owner,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error loading organizations: %w", err)
+	}
+
+	owner.Organizations = make(map[string]types.OrganizationRole, len(memberships))
+	for _, membership := range memberships {
+		owner.Organizations[membership.OrganizationID] = membership.Role
+	}
+
+	return owner, nil
+}
+
+// runSessionAgent lets the agent call the tools, the results are added to the
+// user's message the same way as the RAG results so that the session's model
+// writes the answer
+func (c *Controller) runSessionAgent(ctx context.Context, session *types.Session, interactionID string, assistant *types.AssistantConfig, activeTools []*types.Tool, history []*types.ToolHistoryMessage) (*types.Session, error) {
+	for _, tool := range activeTools {
+		overrideToolQueryParams(tool, session.Metadata.AppQueryParams)
+	}
+
+	// The steps are published to the session's queue like the ones of the
+	// chat completions
+	ctx = oai.SetContextValues(ctx, &oai.ContextValues{
+		OwnerID:       session.Owner,
+		SessionID:     session.ID,
+		InteractionID: interactionID,
+		OwnerType:     session.OwnerType,
+		AppID:         session.ParentApp,
+	})
+
+	c.emitStepInfo(ctx, &types.StepInfo{
+		Name:    "agent",
+		Type:    types.StepInfoTypeToolUse,
+		Message: "Planning the next steps",
+	})
+
+	resp, err := c.ToolsPlanner.RunAgent(ctx, session.ID, interactionID, activeTools, history,
+		tools.WithMaxAgentSteps(assistant.MaxAgentSteps),
+		tools.WithAgentStepCallback(func(step *tools.AgentStep) {
+			c.emitStepInfo(ctx, agentStepInfo(step))
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("agent failed: %w", err)
+	}
+
+	log.Info().
+		Str("session_id", session.ID).
+		Int("steps", len(resp.Steps)).
+		Bool("out_of_steps", resp.OutOfSteps).
+		Msg("agent finished")
+
+	if len(resp.Steps) == 0 {
+		c.emitStepInfo(ctx, &types.StepInfo{
+			Name:    "agent",
+			Type:    types.StepInfoTypeToolUse,
+			Message: "No tools needed",
+		})
+
+		return session, nil
+	}
+
+	return data.UpdateUserInteraction(session, func(userInteraction *types.Interaction) (*types.Interaction, error) {
+		prompt, err := tools.AgentPrompt(userInteraction.Message, resp)
+		if err != nil {
+			return nil, err
+		}
+		userInteraction.DisplayMessage = userInteraction.Message
+		userInteraction.Message = prompt
+		return userInteraction, nil
+	})
+}
+
 func (c *Controller) BeginFineTune(session *types.Session) error {
 	session, err := data.UpdateAssistantInteraction(session, func(assistantInteraction *types.Interaction) (*types.Interaction, error) {
 		assistantInteraction.Finished = false
@@ -675,7 +788,8 @@ func (c *Controller) BeginFineTune(session *types.Session) error {
 		return err
 	}
 
-	c.WriteSession(session)
+	assistantInteraction, _ := data.GetAssistantInteraction(session)
+	c.WriteSession(session, assistantInteraction)
 	c.AddSessionToQueue(session)
 	c.BroadcastProgress(session, 1, "fine tuning on data...")
 
@@ -684,8 +798,9 @@ func (c *Controller) BeginFineTune(session *types.Session) error {
 
 // generic "update this session handler"
 // this will emit a UserWebsocketEvent with a type of
-// WebsocketEventSessionUpdate
-func (c *Controller) WriteSession(session *types.Session) error {
+// WebsocketEventSessionUpdate. Only the session and the given interactions
+// are written, pass the interactions that were added or changed.
+func (c *Controller) WriteSession(session *types.Session, interactions ...*types.Interaction) error {
 	log.Trace().
 		Msgf("🔵 update session: %s %+v", session.ID, session)
 
@@ -695,6 +810,17 @@ func (c *Controller) WriteSession(session *types.Session) error {
 		return err
 	}
 
+	for _, interaction := range interactions {
+		if interaction == nil {
+			continue
+		}
+		err = c.writeInteraction(context.Background(), session, interaction)
+		if err != nil {
+			log.Error().Err(err).Str("session_id", session.ID).Str("interaction_id", interaction.ID).Msg("error writing interaction")
+			return err
+		}
+	}
+
 	event := &types.WebsocketEvent{
 		Type:      types.WebsocketEventSessionUpdate,
 		SessionID: session.ID,
@@ -717,10 +843,68 @@ func (c *Controller) WriteInteraction(session *types.Session, newInteraction *ty
 		}
 	}
 	session.Interactions = newInteractions
-	c.WriteSession(session)
+
+	// only the interaction changed, no need to write the whole session
+	err := c.writeInteraction(context.Background(), session, newInteraction)
+	if err != nil {
+		log.Error().Err(err).Str("session_id", session.ID).Str("interaction_id", newInteraction.ID).Msg("error writing interaction")
+		return session
+	}
+
+	_ = c.publishEvent(context.Background(), &types.WebsocketEvent{
+		Type:      types.WebsocketEventSessionUpdate,
+		SessionID: session.ID,
+		Owner:     session.Owner,
+		Session:   session,
+	})
+
 	return session
 }
 
+// WriteRestoredLLMResponse writes the response of an inference request that
+// was restored after a restart to the interaction it was made for, the client
+// that was waiting for it is gone
+func (c *Controller) WriteRestoredLLMResponse(ctx context.Context, req *types.RunnerLLMInferenceRequest, resp *types.RunnerLLMInferenceResponse) {
+	session, err := c.Options.Store.GetSession(ctx, req.SessionID)
+	if err != nil {
+		log.Error().Err(err).Str("session_id", req.SessionID).Msg("error getting session of restored request")
+		return
+	}
+
+	interaction, err := data.GetInteraction(session, req.InteractionID)
+	if err != nil {
+		log.Error().Err(err).Str("session_id", req.SessionID).Msg("error getting interaction of restored request")
+		return
+	}
+
+	switch {
+	case resp.Error != "":
+		interaction.Error = resp.Error
+		interaction.State = types.InteractionStateError
+	case resp.Response != nil && len(resp.Response.Choices) > 0:
+		interaction.Message = resp.Response.Choices[0].Message.Content
+		interaction.State = types.InteractionStateComplete
+	default:
+		interaction.Error = "runner returned an empty response"
+		interaction.State = types.InteractionStateError
+	}
+	interaction.Completed = time.Now()
+	interaction.Finished = true
+
+	c.WriteInteraction(session, interaction)
+}
+
+// writeInteraction updates the interaction of the session, or appends it if
+// it's new
+func (c *Controller) writeInteraction(ctx context.Context, session *types.Session, interaction *types.Interaction) error {
+	interaction.SessionID = session.ID
+	_, err := c.Options.Store.UpdateInteraction(ctx, interaction)
+	if errors.Is(err, store.ErrNotFound) {
+		_, err = c.Options.Store.CreateInteraction(ctx, interaction)
+	}
+	return err
+}
+
 func (c *Controller) BroadcastProgress(
 	session *types.Session,
 	progress int,
@@ -776,7 +960,9 @@ func (c *Controller) ErrorSession(session *types.Session, sessionErr error) {
 	if err != nil {
 		return
 	}
-	c.WriteSession(session)
+	userInteraction, _ := data.GetUserInteraction(session.Interactions)
+	assistantInteraction, _ := data.GetAssistantInteraction(session)
+	c.WriteSession(session, userInteraction, assistantInteraction)
 	c.Options.Janitor.WriteSessionError(session, sessionErr)
 }
 
@@ -941,7 +1127,8 @@ func (c *Controller) HandleRunnerResponse(ctx context.Context, taskResponse *typ
 	if err != nil {
 		return nil, err
 	}
-	c.WriteSession(session)
+	assistantInteraction, _ := data.GetAssistantInteraction(session)
+	c.WriteSession(session, assistantInteraction)
 
 	if taskResponse.Error != "" {
 		c.Options.Janitor.WriteSessionError(session, fmt.Errorf(taskResponse.Error))
@@ -1034,6 +1221,9 @@ func (c *Controller) CloneUntilInteraction(
 		if err != nil {
 			return "", err
 		}
+		if closer, ok := reader.(io.Closer); ok {
+			defer closer.Close()
+		}
 		err = c.Options.Filestore.UploadFolder(ctx, newFolder, reader)
 		if err != nil {
 			return "", err
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+	"go.uber.org/mock/gomock"
+)
+
+func (suite *ControllerSuite) Test_CheckForActions_OrganizationApp() {
+	suite.controller.Options.Config.Tools.Enabled = true
+
+	app := &types.App{
+		ID:        "app_id",
+		Owner:     "org_id",
+		OwnerType: types.OwnerTypeOrg,
+		Config: types.AppConfig{
+			Helix: types.AppHelixConfig{
+				Assistants: []types.AssistantConfig{
+					{ID: "0", Name: "assistant"},
+				},
+			},
+		},
+	}
+
+	session := &types.Session{
+		ID:        "session_id",
+		Owner:     suite.user.ID,
+		OwnerType: types.OwnerTypeUser,
+		ParentApp: app.ID,
+	}
+
+	suite.store.EXPECT().GetApp(gomock.Any(), app.ID).Return(app, nil)
+	suite.store.EXPECT().ListOrganizationMemberships(gomock.Any(), &store.ListOrganizationMembershipsQuery{
+		UserID: suite.user.ID,
+	}).Return([]*types.OrganizationMembership{
+		{OrganizationID: "org_id", UserID: suite.user.ID, Role: types.OrganizationRoleMember},
+	}, nil)
+
+	checked, err := suite.controller.checkForActions(session)
+	suite.NoError(err)
+	suite.Equal(session, checked)
+}
+
+func (suite *ControllerSuite) Test_CheckForActions_OrganizationApp_NotMember() {
+	suite.controller.Options.Config.Tools.Enabled = true
+
+	app := &types.App{
+		ID:        "app_id",
+		Owner:     "org_id",
+		OwnerType: types.OwnerTypeOrg,
+	}
+
+	session := &types.Session{
+		ID:        "session_id",
+		Owner:     suite.user.ID,
+		OwnerType: types.OwnerTypeUser,
+		ParentApp: app.ID,
+	}
+
+	suite.store.EXPECT().GetApp(gomock.Any(), app.ID).Return(app, nil)
+	suite.store.EXPECT().ListOrganizationMemberships(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+	_, err := suite.controller.checkForActions(session)
+
+	var httpErr *system.HTTPError
+	suite.Require().ErrorAs(err, &httpErr)
+	suite.Equal(http.StatusForbidden, httpErr.StatusCode)
+}
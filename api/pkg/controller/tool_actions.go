@@ -55,16 +55,7 @@ func (c *Controller) runActionInteraction(ctx context.Context, session *types.Se
 		}
 	}
 
-	// Override query parameters if the user has specified them
-	for paramName, paramValue := range session.Metadata.AppQueryParams {
-		for queryName, queryValue := range tool.Config.API.Query {
-			// If the request query params match something in the tool query params, override it
-			if queryName == paramName {
-				tool.Config.API.Query[queryName] = paramValue
-				log.Debug().Msgf("Overriding default tool query param: %s=%s with %s=%s", queryName, queryValue, paramName, tool.Config.API.Query[queryName])
-			}
-		}
-	}
+	overrideToolQueryParams(tool, session.Metadata.AppQueryParams)
 
 	var updated *types.Session
 
@@ -94,7 +85,25 @@ func (c *Controller) runActionInteraction(ctx context.Context, session *types.Se
 		return nil, fmt.Errorf("failed to update assistant interaction: %w", err)
 	}
 
-	c.WriteSession(updated)
+	updatedInteraction, _ := data.GetAssistantInteraction(updated)
+	c.WriteSession(updated, updatedInteraction)
 
 	return updated, nil
 }
+
+// overrideToolQueryParams overrides the query parameters of the tool if the user has
+// specified them, only API tools have them
+func overrideToolQueryParams(tool *types.Tool, params map[string]string) {
+	for paramName, paramValue := range params {
+		if tool.Config.API == nil {
+			break
+		}
+		for queryName, queryValue := range tool.Config.API.Query {
+			// If the request query params match something in the tool query params, override it
+			if queryName == paramName {
+				tool.Config.API.Query[queryName] = paramValue
+				log.Debug().Msgf("Overriding default tool query param: %s=%s with %s=%s", queryName, queryValue, paramName, tool.Config.API.Query[queryName])
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	oai "github.com/helixml/helix/api/pkg/openai"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+// ErrUsageBudgetExceeded is returned when the monthly budget of the user,
+// organization or app is used up
+var ErrUsageBudgetExceeded = errors.New("usage budget exceeded")
+
+// withUsageAttribution sets the owner, the app and the API key the LLM calls
+// are recorded for in the usage ledger
+func withUsageAttribution(ctx context.Context, user *types.User, app *types.App) context.Context {
+	vals, ok := oai.GetContextValues(ctx)
+	if !ok {
+		return ctx
+	}
+
+	updated := *vals
+	updated.OwnerType = user.Type
+	if app != nil {
+		updated.AppID = app.ID
+	}
+	if user.APIKey != nil {
+		updated.APIKeyID = types.APIKeyID(user.APIKey.Key)
+	}
+	return oai.SetContextValues(ctx, &updated)
+}
+
+// checkUsageBudget returns ErrUsageBudgetExceeded when the monthly budget of
+// the user or the app is used up, the budgets reset at the start of each UTC month
+func (c *Controller) checkUsageBudget(ctx context.Context, user *types.User, app *types.App) error {
+	cfg := c.Options.Config.Usage
+
+	now := time.Now().UTC()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	resets := monthStart.AddDate(0, 1, 0).Format(time.DateOnly)
+
+	if (cfg.MonthlyTokenBudget > 0 || cfg.MonthlyCostBudget > 0) && user.ID != "" {
+		totals, err := c.usageTotals(ctx, &store.ListUsageQuery{
+			Owner:     user.ID,
+			OwnerType: user.Type,
+			From:      monthStart,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = checkBudget(totals, cfg.MonthlyTokenBudget, cfg.MonthlyCostBudget)
+		if err != nil {
+			return fmt.Errorf("%w: the %s of %s is used up, it resets on %s", ErrUsageBudgetExceeded, err, user.ID, resets)
+		}
+	}
+
+	if app != nil && (app.Config.Helix.MonthlyTokenBudget > 0 || app.Config.Helix.MonthlyCostBudget > 0) {
+		totals, err := c.usageTotals(ctx, &store.ListUsageQuery{
+			AppID: app.ID,
+			From:  monthStart,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = checkBudget(totals, app.Config.Helix.MonthlyTokenBudget, app.Config.Helix.MonthlyCostBudget)
+		if err != nil {
+			return fmt.Errorf("%w: the %s of app %s is used up, it resets on %s", ErrUsageBudgetExceeded, err, app.ID, resets)
+		}
+	}
+
+	return nil
+}
+
+func (c *Controller) usageTotals(ctx context.Context, q *store.ListUsageQuery) (*types.UsageTotals, error) {
+	usage, err := c.Options.Store.ListUsage(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get usage: %w", err)
+	}
+
+	var totals types.UsageTotals
+	for _, u := range usage {
+		totals.Add(&u.UsageTotals)
+	}
+
+	return &totals, nil
+}
+
+func checkBudget(totals *types.UsageTotals, tokenBudget int64, costBudget float64) error {
+	if tokenBudget > 0 && totals.TotalTokens >= tokenBudget {
+		return fmt.Errorf("monthly budget of %d tokens", tokenBudget)
+	}
+
+	if costBudget > 0 && totals.Cost >= costBudget {
+		return fmt.Errorf("monthly budget of $%.2f", costBudget)
+	}
+
+	return nil
+}
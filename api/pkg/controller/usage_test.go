@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	openai "github.com/sashabaranov/go-openai"
+	"go.uber.org/mock/gomock"
+
+	oai "github.com/helixml/helix/api/pkg/openai"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func (suite *ControllerSuite) Test_ChatCompletion_UserBudgetExceeded() {
+	suite.controller.Options.Config.Usage.MonthlyTokenBudget = 1000
+
+	suite.store.EXPECT().ListUsage(suite.ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, q *store.ListUsageQuery) ([]*types.DailyUsage, error) {
+			suite.Equal("user_id", q.Owner)
+			suite.Equal(1, q.From.Day())
+
+			return []*types.DailyUsage{
+				{UsageTotals: types.UsageTotals{TotalTokens: 600}},
+				{UsageTotals: types.UsageTotals{TotalTokens: 400}},
+			}, nil
+		})
+
+	_, _, err := suite.controller.ChatCompletion(suite.ctx, suite.user, openai.ChatCompletionRequest{
+		Model: openai.GPT4TurboPreview,
+	}, &ChatCompletionOptions{})
+	suite.True(errors.Is(err, ErrUsageBudgetExceeded))
+	suite.ErrorContains(err, "the monthly budget of 1000 tokens of user_id is used up")
+}
+
+func (suite *ControllerSuite) Test_ChatCompletion_AppCostBudgetExceeded() {
+	app := &types.App{
+		ID:     "app_id",
+		Global: true,
+		Config: types.AppConfig{
+			Helix: types.AppHelixConfig{
+				Assistants:        []types.AssistantConfig{{ID: "0"}},
+				MonthlyCostBudget: 5,
+			},
+		},
+	}
+
+	suite.store.EXPECT().GetApp(suite.ctx, "app_id").Return(app, nil)
+	suite.store.EXPECT().ListUsage(suite.ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, q *store.ListUsageQuery) ([]*types.DailyUsage, error) {
+			// The budget of the app is shared by all its users
+			suite.Equal("app_id", q.AppID)
+			suite.Empty(q.Owner)
+
+			return []*types.DailyUsage{
+				{UsageTotals: types.UsageTotals{Cost: 5.5}},
+			}, nil
+		})
+
+	_, _, err := suite.controller.ChatCompletionStream(suite.ctx, suite.user, openai.ChatCompletionRequest{
+		Model: openai.GPT4TurboPreview,
+	}, &ChatCompletionOptions{AppID: "app_id"})
+	suite.True(errors.Is(err, ErrUsageBudgetExceeded))
+	suite.ErrorContains(err, "the monthly budget of $5.00 of app app_id is used up")
+}
+
+func (suite *ControllerSuite) Test_ChatCompletion_UsageAttribution() {
+	suite.controller.Options.Config.Usage.MonthlyTokenBudget = 1000
+
+	user := *suite.user
+	user.Type = types.OwnerTypeUser
+	user.APIKey = &types.APIKey{Key: "hl-key"}
+
+	app := &types.App{
+		ID:     "app_id",
+		Global: true,
+		Config: types.AppConfig{
+			Helix: types.AppHelixConfig{
+				Assistants: []types.AssistantConfig{{ID: "0"}},
+			},
+		},
+	}
+
+	ctx := oai.SetContextValues(suite.ctx, &oai.ContextValues{OwnerID: "user_id"})
+
+	suite.store.EXPECT().GetApp(ctx, "app_id").Return(app, nil)
+	suite.store.EXPECT().ListUsage(ctx, gomock.Any()).Return([]*types.DailyUsage{
+		{UsageTotals: types.UsageTotals{TotalTokens: 999}},
+	}, nil)
+
+	suite.openAiClient.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+			vals, ok := oai.GetContextValues(ctx)
+			suite.Require().True(ok)
+			suite.Equal("user_id", vals.OwnerID)
+			suite.Equal(types.OwnerTypeUser, vals.OwnerType)
+			suite.Equal("app_id", vals.AppID)
+			suite.Equal(types.APIKeyID("hl-key"), vals.APIKeyID)
+
+			return openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{
+					{Message: openai.ChatCompletionMessage{Content: "Hello"}},
+				},
+			}, nil
+		})
+
+	_, _, err := suite.controller.ChatCompletion(ctx, &user, openai.ChatCompletionRequest{
+		Model: openai.GPT4TurboPreview,
+	}, &ChatCompletionOptions{AppID: "app_id", AssistantID: "0"})
+	suite.NoError(err)
+}
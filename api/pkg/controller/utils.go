@@ -294,12 +294,36 @@ func hasQuestionsFile(interaction *types.Interaction, sourceFilename string) boo
 
 func getLastMessage(req openai.ChatCompletionRequest) string {
 	if len(req.Messages) > 0 {
-		return req.Messages[len(req.Messages)-1].Content
+		return types.ChatMessageText(req.Messages[len(req.Messages)-1])
 	}
 
 	return ""
 }
 
+// setLastMessage replaces the text of the last message, the other parts of
+// multi content messages (e.g. images) are kept
+func setLastMessage(req *openai.ChatCompletionRequest, text string) {
+	if len(req.Messages) == 0 {
+		return
+	}
+
+	message := &req.Messages[len(req.Messages)-1]
+	if len(message.MultiContent) == 0 {
+		message.Content = text
+		return
+	}
+
+	parts := []openai.ChatMessagePart{
+		{Type: openai.ChatMessagePartTypeText, Text: text},
+	}
+	for _, part := range message.MultiContent {
+		if part.Type != openai.ChatMessagePartTypeText {
+			parts = append(parts, part)
+		}
+	}
+	message.MultiContent = parts
+}
+
 func sessionToChatCompletion(session *types.Session) (*openai.ChatCompletionRequest, error) {
 	var messages []openai.ChatCompletionMessage
 
@@ -257,6 +257,7 @@ func GetSessionSummary(session *types.Session) (*types.SessionSummary, error) {
 		Completed:     assistantInteraction.Completed,
 		Summary:       summary,
 		Priority:      session.Metadata.Priority,
+		PriorityClass: session.Metadata.PriorityClass,
 		AppID:         session.ParentApp,
 	}, nil
 }
@@ -0,0 +1,174 @@
+package evals
+
+import (
+	"fmt"
+	"os"
+	"text/template"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	defaultGrader    = "gpt4-similarity"
+	defaultPassScore = 0.5
+)
+
+type Config struct {
+	ManualEvals    []*ManualEval    `yaml:"manual_evals"`
+	AutomaticEvals []*AutomaticEval `yaml:"automatic_evals"`
+	Checkers       []*Checker       `yaml:"checkers"`
+	LLMTargets     []*LLMTarget     `yaml:"llm_targets"`
+}
+
+// ManualEval is a hand written question together with the answer we expect
+type ManualEval struct {
+	Name string `yaml:"name"`
+	// Download documents that are given to the checker as the context
+	Download []string `yaml:"download"`
+	// Context is added to the downloaded documents, useful for short snippets
+	Context        string `yaml:"context"`
+	Question       string `yaml:"question"`
+	ExpectedAnswer string `yaml:"expected_answer"`
+	Checker        string `yaml:"checker"`
+}
+
+// AutomaticEval has the checker generate the questions and the expected
+// answers from the context, the answers are then graded by the grader
+type AutomaticEval struct {
+	Name     string   `yaml:"name"`
+	Download []string `yaml:"download"`
+	Context  string   `yaml:"context"`
+	Checker  string   `yaml:"checker"`
+	Grader   string   `yaml:"grader"` // Checker used to grade the answers, gpt4-similarity by default
+}
+
+// Checker is a prompt template for the LLM judge. Templates can use
+// {{.Context}}, {{.Question}}, {{.RealAnswer}} and {{.ExpectedAnswer}}
+type Checker struct {
+	Name   string `yaml:"name"`
+	Prompt string `yaml:"prompt"`
+	// Values that the judge answers with and the score of each of them
+	Values map[string]float64 `yaml:"values"`
+	// PassScore is the lowest score that passes, 0.5 if not set
+	PassScore *float64 `yaml:"pass_score"`
+}
+
+func (c *Checker) passScore() float64 {
+	if c.PassScore == nil {
+		return defaultPassScore
+	}
+	return *c.PassScore
+}
+
+type LLMTarget struct {
+	Name         string `yaml:"name"`
+	APIURL       string `yaml:"api_url"`
+	Model        string `yaml:"model"`
+	TokenFromEnv string `yaml:"token_from_env"`
+}
+
+func (t *LLMTarget) Token() string {
+	if t.TokenFromEnv == "" {
+		return ""
+	}
+	return os.Getenv(t.TokenFromEnv)
+}
+
+func LoadConfig(path string) (*Config, error) {
+	bts, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read evals config: %w", err)
+	}
+
+	var cfg Config
+	err = yaml.Unmarshal(bts, &cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse evals config: %w", err)
+	}
+
+	err = cfg.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+func (c *Config) Validate() error {
+	for _, checker := range c.Checkers {
+		if checker.Name == "" {
+			return fmt.Errorf("checker name is required")
+		}
+
+		_, err := template.New(checker.Name).Parse(checker.Prompt)
+		if err != nil {
+			return fmt.Errorf("invalid prompt for checker '%s': %w", checker.Name, err)
+		}
+	}
+
+	for _, e := range c.ManualEvals {
+		if e.Question == "" || e.ExpectedAnswer == "" {
+			return fmt.Errorf("manual eval '%s' requires a question and an expected answer", e.Name)
+		}
+
+		checker, err := c.GetChecker(e.Checker)
+		if err != nil {
+			return fmt.Errorf("manual eval '%s': %w", e.Name, err)
+		}
+
+		if len(checker.Values) == 0 {
+			return fmt.Errorf("manual eval '%s': checker '%s' has no values to grade with", e.Name, checker.Name)
+		}
+	}
+
+	for _, e := range c.AutomaticEvals {
+		if len(e.Download) == 0 && e.Context == "" {
+			return fmt.Errorf("automatic eval '%s' requires documents to download or a context", e.Name)
+		}
+
+		_, err := c.GetChecker(e.Checker)
+		if err != nil {
+			return fmt.Errorf("automatic eval '%s': %w", e.Name, err)
+		}
+
+		grader, err := c.GetChecker(e.grader())
+		if err != nil {
+			return fmt.Errorf("automatic eval '%s': %w", e.Name, err)
+		}
+
+		if len(grader.Values) == 0 {
+			return fmt.Errorf("automatic eval '%s': checker '%s' has no values to grade with", e.Name, grader.Name)
+		}
+	}
+
+	return nil
+}
+
+func (e *AutomaticEval) grader() string {
+	if e.Grader == "" {
+		return defaultGrader
+	}
+	return e.Grader
+}
+
+func (c *Config) GetChecker(name string) (*Checker, error) {
+	for _, checker := range c.Checkers {
+		if checker.Name == name {
+			return checker, nil
+		}
+	}
+	return nil, fmt.Errorf("checker '%s' not found", name)
+}
+
+// GetLLMTarget returns the target by name, the first one if the name is empty
+func (c *Config) GetLLMTarget(name string) (*LLMTarget, error) {
+	for _, target := range c.LLMTargets {
+		if name == "" || target.Name == name {
+			return target, nil
+		}
+	}
+	if name == "" {
+		return nil, fmt.Errorf("no llm targets configured")
+	}
+	return nil, fmt.Errorf("llm target '%s' not found", name)
+}
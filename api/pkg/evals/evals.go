@@ -1,7 +1,267 @@
 package evals
 
-import "log"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
 
-func Run() {
-	log.Printf("hello from evals")
+	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/rs/zerolog/log"
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/helixml/helix/api/pkg/controller/knowledge/readability"
+	"github.com/helixml/helix/api/pkg/system"
+)
+
+//go:generate mockgen -source $GOFILE -destination evals_mocks.go -package $GOPACKAGE
+
+// ChatClient is the part of the OpenAI API that is used to answer the
+// questions and to grade the answers
+type ChatClient interface {
+	CreateChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error)
+}
+
+type Options struct {
+	Config *Config
+
+	// Target is the app or the model being evaluated, the model can be empty
+	// for apps as the app decides which model to use
+	Target      ChatClient
+	TargetName  string
+	TargetModel string
+
+	// Judge grades the answers with the checker prompts
+	Judge      ChatClient
+	JudgeModel string
+
+	HTTPClient *http.Client // Used to download the documents
+}
+
+type Runner struct {
+	opts      Options
+	parser    readability.Parser
+	converter *md.Converter
+}
+
+func NewRunner(opts Options) (*Runner, error) {
+	if opts.Config == nil {
+		return nil, errors.New("config is required")
+	}
+	if opts.Target == nil {
+		return nil, errors.New("target is required")
+	}
+	if opts.Judge == nil {
+		return nil, errors.New("judge is required")
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+
+	return &Runner{
+		opts:      opts,
+		parser:    readability.NewParser(),
+		converter: md.NewConverter("", true, nil),
+	}, nil
+}
+
+type evalCase struct {
+	name           string
+	context        string
+	question       string
+	expectedAnswer string
+	checker        *Checker
+}
+
+// Run runs all the evals from the config, failures of the individual cases
+// are recorded in the report
+func (r *Runner) Run(ctx context.Context) (*Report, error) {
+	report := &Report{
+		RunID:   system.GenerateUUID(),
+		Target:  r.opts.TargetName,
+		Started: time.Now(),
+	}
+
+	for _, e := range r.opts.Config.ManualEvals {
+		checker, err := r.opts.Config.GetChecker(e.Checker)
+		if err != nil {
+			return nil, err
+		}
+
+		evalContext, err := r.getContext(ctx, e.Download, e.Context)
+		if err != nil {
+			report.add(&Result{Name: e.Name, Checker: e.Checker, Error: err.Error()})
+			continue
+		}
+
+		report.add(r.runCase(ctx, &evalCase{
+			name:           e.Name,
+			context:        evalContext,
+			question:       e.Question,
+			expectedAnswer: e.ExpectedAnswer,
+			checker:        checker,
+		}))
+	}
+
+	for _, e := range r.opts.Config.AutomaticEvals {
+		checker, err := r.opts.Config.GetChecker(e.Checker)
+		if err != nil {
+			return nil, err
+		}
+
+		grader, err := r.opts.Config.GetChecker(e.grader())
+		if err != nil {
+			return nil, err
+		}
+
+		evalContext, err := r.getContext(ctx, e.Download, e.Context)
+		if err != nil {
+			report.add(&Result{Name: e.Name, Checker: e.Checker, Error: err.Error()})
+			continue
+		}
+
+		pairs, err := r.generateQuestions(ctx, checker, evalContext)
+		if err != nil {
+			report.add(&Result{Name: e.Name, Checker: e.Checker, Error: err.Error()})
+			continue
+		}
+
+		for i, pair := range pairs {
+			report.add(r.runCase(ctx, &evalCase{
+				name:           fmt.Sprintf("%s/%d", e.Name, i+1),
+				context:        evalContext,
+				question:       pair.Question,
+				expectedAnswer: pair.Answer,
+				checker:        grader,
+			}))
+		}
+	}
+
+	report.Duration = time.Since(report.Started).Seconds()
+
+	return report, nil
+}
+
+func (r *Runner) runCase(ctx context.Context, c *evalCase) *Result {
+	start := time.Now()
+
+	result := &Result{
+		Name:           c.name,
+		Question:       c.question,
+		ExpectedAnswer: c.expectedAnswer,
+		Checker:        c.checker.Name,
+	}
+
+	defer func() {
+		result.Duration = time.Since(start).Seconds()
+
+		log.Info().
+			Str("eval", result.Name).
+			Str("grade", result.Grade).
+			Float64("score", result.Score).
+			Bool("passed", result.Passed).
+			Str("error", result.Error).
+			Msg("eval finished")
+	}()
+
+	answer, err := r.ask(ctx, c.question)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to get the answer: %s", err)
+		return result
+	}
+	result.Answer = answer
+
+	grade, score, reason, err := r.grade(ctx, c.checker, &checkerData{
+		Context:        c.context,
+		Question:       c.question,
+		RealAnswer:     answer,
+		ExpectedAnswer: c.expectedAnswer,
+	})
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to grade the answer: %s", err)
+		return result
+	}
+
+	result.Grade = grade
+	result.Score = score
+	result.Reason = reason
+	result.Passed = score >= c.checker.passScore()
+
+	return result
+}
+
+func (r *Runner) ask(ctx context.Context, question string) (string, error) {
+	resp, err := r.opts.Target.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model: r.opts.TargetModel,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: question,
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if len(resp.Choices) == 0 {
+		return "", errors.New("no choices in the response")
+	}
+
+	return resp.Choices[0].Message.Content, nil
+}
+
+// getContext downloads the documents and converts them into markdown
+func (r *Runner) getContext(ctx context.Context, urls []string, inline string) (string, error) {
+	var parts []string
+
+	for _, u := range urls {
+		content, err := r.download(ctx, u)
+		if err != nil {
+			return "", fmt.Errorf("failed to download %s: %w", u, err)
+		}
+		parts = append(parts, content)
+	}
+
+	if inline != "" {
+		parts = append(parts, inline)
+	}
+
+	return strings.Join(parts, "\n\n"), nil
+}
+
+func (r *Runner) download(ctx context.Context, u string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := r.opts.HTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("status code %d", resp.StatusCode)
+	}
+
+	bts, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if !strings.Contains(resp.Header.Get("Content-Type"), "html") {
+		return string(bts), nil
+	}
+
+	article, err := r.parser.Parse(ctx, string(bts), u)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse article: %w", err)
+	}
+
+	return r.converter.ConvertString(article.Content)
 }
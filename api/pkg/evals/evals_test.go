@@ -0,0 +1,273 @@
+package evals
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+	"gopkg.in/yaml.v2"
+)
+
+type EvalsSuite struct {
+	suite.Suite
+
+	ctx context.Context
+
+	target *MockChatClient
+	judge  *MockChatClient
+
+	cfg *Config
+}
+
+func TestEvalsSuite(t *testing.T) {
+	suite.Run(t, new(EvalsSuite))
+}
+
+func (suite *EvalsSuite) SetupTest() {
+	ctrl := gomock.NewController(suite.T())
+
+	suite.ctx = context.Background()
+	suite.target = NewMockChatClient(ctrl)
+	suite.judge = NewMockChatClient(ctrl)
+
+	suite.cfg = &Config{
+		Checkers: []*Checker{
+			{
+				Name:   "similarity",
+				Prompt: "Context: {{.Context}}\nReal: {{.RealAnswer}}\nExpected: {{.ExpectedAnswer}}",
+				Values: map[string]float64{"Bad": 0, "OK": 0.5, "Good": 1},
+			},
+			{
+				Name:   "autoqa",
+				Prompt: "Questions about: {{.Context}}",
+			},
+		},
+	}
+}
+
+func (suite *EvalsSuite) newRunner() *Runner {
+	runner, err := NewRunner(Options{
+		Config:     suite.cfg,
+		Target:     suite.target,
+		TargetName: "app_123",
+		Judge:      suite.judge,
+		JudgeModel: "gpt-4",
+	})
+	suite.Require().NoError(err)
+	return runner
+}
+
+func chatResponse(content string) openai.ChatCompletionResponse {
+	return openai.ChatCompletionResponse{
+		Choices: []openai.ChatCompletionChoice{
+			{Message: openai.ChatCompletionMessage{Content: content}},
+		},
+	}
+}
+
+func (suite *EvalsSuite) TestRun_ManualEvals() {
+	suite.cfg.ManualEvals = []*ManualEval{
+		{
+			Name:           "strike",
+			Context:        "junior doctors will strike",
+			Question:       "what are the doctors going to do?",
+			ExpectedAnswer: "go on strike",
+			Checker:        "similarity",
+		},
+		{
+			Name:           "pay",
+			Question:       "how much are they paid?",
+			ExpectedAnswer: "not enough",
+			Checker:        "similarity",
+		},
+		{
+			Name:           "broken",
+			Question:       "when?",
+			ExpectedAnswer: "in january",
+			Checker:        "similarity",
+		},
+	}
+
+	suite.target.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+			switch req.Messages[0].Content {
+			case "what are the doctors going to do?":
+				return chatResponse("they will strike"), nil
+			case "how much are they paid?":
+				return chatResponse("I don't know"), nil
+			}
+			return openai.ChatCompletionResponse{}, errors.New("app is down")
+		}).Times(3)
+
+	suite.judge.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+			suite.Equal("gpt-4", req.Model)
+
+			prompt := req.Messages[0].Content
+			if strings.Contains(prompt, "they will strike") {
+				suite.Contains(prompt, "Context: junior doctors will strike")
+				suite.Contains(prompt, "Expected: go on strike")
+				return chatResponse("Good, the answers match"), nil
+			}
+			return chatResponse("The answer is bad. It's not even OK."), nil
+		}).Times(2)
+
+	report, err := suite.newRunner().Run(suite.ctx)
+	suite.Require().NoError(err)
+
+	suite.Equal("app_123", report.Target)
+	suite.Equal(3, report.Total)
+	suite.Equal(1, report.Passed)
+	suite.Equal(1, report.Failed)
+	suite.Equal(1, report.Errored)
+
+	suite.Require().Len(report.Results, 3)
+
+	suite.True(report.Results[0].Passed)
+	suite.Equal("Good", report.Results[0].Grade)
+	suite.Equal(1.0, report.Results[0].Score)
+
+	suite.False(report.Results[1].Passed)
+	suite.Equal("Bad", report.Results[1].Grade)
+
+	suite.Contains(report.Results[2].Error, "app is down")
+}
+
+func (suite *EvalsSuite) TestRun_AutomaticEvals() {
+	suite.cfg.AutomaticEvals = []*AutomaticEval{
+		{
+			Name:    "article",
+			Context: "the sky is blue",
+			Checker: "autoqa",
+			Grader:  "similarity",
+		},
+	}
+
+	suite.judge.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).Return(chatResponse("```json\n"+`[
+		{"question": "what color is the sky?", "answer": "blue"},
+		{"question": "", "answer": "skipped"},
+	]`+"\n```"), nil)
+
+	suite.target.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).Return(chatResponse("blue"), nil)
+
+	suite.judge.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).Return(chatResponse("OK"), nil)
+
+	report, err := suite.newRunner().Run(suite.ctx)
+	suite.Require().NoError(err)
+
+	suite.Require().Len(report.Results, 1)
+	suite.Equal("article/1", report.Results[0].Name)
+	suite.Equal("what color is the sky?", report.Results[0].Question)
+	suite.Equal("blue", report.Results[0].ExpectedAnswer)
+	suite.True(report.Results[0].Passed)
+}
+
+func TestParseGrade(t *testing.T) {
+	values := map[string]float64{"Bad": 0, "OK": 0.5, "Good": 1, "Not good": 0.1}
+
+	tests := []struct {
+		reply       string
+		grade       string
+		expectError bool
+	}{
+		{reply: "Good", grade: "Good"},
+		{reply: "good.", grade: "Good"},
+		{reply: "The answer is OK but could be good", grade: "OK"},
+		{reply: "Not good at all", grade: "Not good"},
+		{reply: "Okay-ish", expectError: true},
+		{reply: "", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.reply, func(t *testing.T) {
+			grade, score, err := parseGrade(tt.reply, values)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected an error, got grade %s", grade)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if grade != tt.grade || score != values[tt.grade] {
+				t.Errorf("expected %s, got %s (%f)", tt.grade, grade, score)
+			}
+		})
+	}
+}
+
+func TestChecker_PassScore(t *testing.T) {
+	var cfg Config
+	err := yaml.Unmarshal([]byte(`
+checkers:
+  - name: default
+  - name: everything-passes
+    pass_score: 0
+  - name: strict
+    pass_score: 1
+`), &cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i, want := range []float64{defaultPassScore, 0, 1} {
+		if got := cfg.Checkers[i].passScore(); got != want {
+			t.Errorf("checker %s: expected pass score %f, got %f", cfg.Checkers[i].Name, want, got)
+		}
+	}
+}
+
+func TestReport_Regressions(t *testing.T) {
+	report := &Report{}
+	report.add(&Result{Name: "a", Passed: true})
+	report.add(&Result{Name: "b"})
+	report.add(&Result{Name: "c", Error: "failed"})
+
+	if got := len(report.Regressions(nil)); got != 2 {
+		t.Errorf("expected 2 regressions without a baseline, got %d", got)
+	}
+
+	baseline := &Report{
+		Results: []*Result{
+			{Name: "a", Passed: true},
+			{Name: "b", Passed: true},
+			{Name: "c"},
+		},
+	}
+
+	regressions := report.Regressions(baseline)
+	if len(regressions) != 1 || regressions[0].Name != "b" {
+		t.Errorf("expected only 'b' to regress, got %+v", regressions)
+	}
+}
+
+func TestReport_WriteJUnit(t *testing.T) {
+	report := &Report{}
+	report.add(&Result{Name: "a", Checker: "similarity", Passed: true, Grade: "Good", Score: 1})
+	report.add(&Result{Name: "b", Checker: "similarity", Grade: "Bad", Reason: "wrong answer"})
+	report.add(&Result{Name: "c", Checker: "similarity", Error: "app is down"})
+
+	var buf bytes.Buffer
+	err := report.WriteJUnit(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	for _, expected := range []string{
+		`<testsuites tests="3" failures="1" errors="1"`,
+		`<testcase name="a" classname="similarity"`,
+		`<failure message="graded Bad (score 0.00)">wrong answer</failure>`,
+		`<error message="app is down"></error>`,
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in:\n%s", expected, out)
+		}
+	}
+}
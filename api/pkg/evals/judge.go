@@ -0,0 +1,172 @@
+package evals
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+type checkerData struct {
+	Context        string
+	Question       string
+	RealAnswer     string
+	ExpectedAnswer string
+}
+
+type qaPair struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+}
+
+// grade asks the judge to grade the answer, returns the grade, its score
+// and the full judge reply
+func (r *Runner) grade(ctx context.Context, checker *Checker, data *checkerData) (string, float64, string, error) {
+	reply, err := r.judge(ctx, checker, data)
+	if err != nil {
+		return "", 0, "", err
+	}
+
+	grade, score, err := parseGrade(reply, checker.Values)
+	if err != nil {
+		return "", 0, reply, err
+	}
+
+	return grade, score, reply, nil
+}
+
+// generateQuestions asks the judge to write questions and the expected
+// answers about the context
+func (r *Runner) generateQuestions(ctx context.Context, checker *Checker, evalContext string) ([]*qaPair, error) {
+	reply, err := r.judge(ctx, checker, &checkerData{
+		Context: evalContext,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pairs, err := parseQAPairs(reply)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pairs) == 0 {
+		return nil, errors.New("no questions were generated")
+	}
+
+	return pairs, nil
+}
+
+func (r *Runner) judge(ctx context.Context, checker *Checker, data *checkerData) (string, error) {
+	tmpl, err := template.New(checker.Name).Parse(checker.Prompt)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse checker prompt: %w", err)
+	}
+
+	var prompt bytes.Buffer
+	err = tmpl.Execute(&prompt, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to render checker prompt: %w", err)
+	}
+
+	resp, err := r.opts.Judge.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model: r.opts.JudgeModel,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: prompt.String(),
+			},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("judge request failed: %w", err)
+	}
+
+	if len(resp.Choices) == 0 {
+		return "", errors.New("no choices in the judge response")
+	}
+
+	return resp.Choices[0].Message.Content, nil
+}
+
+// parseGrade finds the value that the judge answered with. Judges tend to
+// explain themselves so the value that is mentioned first wins
+func parseGrade(reply string, values map[string]float64) (string, float64, error) {
+	// Longer values first so that for example 'Not good' wins over 'Not'
+	// when both start at the same position
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) == len(names[j]) {
+			return names[i] < names[j]
+		}
+		return len(names[i]) > len(names[j])
+	})
+
+	var (
+		grade string
+		first = -1
+	)
+
+	for _, name := range names {
+		re, err := regexp.Compile(`(?i)\b` + regexp.QuoteMeta(name) + `\b`)
+		if err != nil {
+			return "", 0, err
+		}
+
+		loc := re.FindStringIndex(reply)
+		if loc == nil {
+			continue
+		}
+
+		if first == -1 || loc[0] < first {
+			first = loc[0]
+			grade = name
+		}
+	}
+
+	if grade == "" {
+		return "", 0, fmt.Errorf("judge didn't answer with any of the values: %s", strings.Join(names, ", "))
+	}
+
+	return grade, values[grade], nil
+}
+
+var trailingCommaRe = regexp.MustCompile(`,\s*([\]}])`)
+
+// parseQAPairs extracts the JSON list of questions from the judge reply,
+// the list can be wrapped in a markdown code block
+func parseQAPairs(reply string) ([]*qaPair, error) {
+	start := strings.Index(reply, "[")
+	end := strings.LastIndex(reply, "]")
+	if start == -1 || end < start {
+		return nil, errors.New("no list of questions found in the judge reply")
+	}
+
+	data := trailingCommaRe.ReplaceAllString(reply[start:end+1], "$1")
+
+	var pairs []*qaPair
+	err := json.Unmarshal([]byte(data), &pairs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the list of questions: %w", err)
+	}
+
+	var result []*qaPair
+	for _, pair := range pairs {
+		if pair.Question == "" || pair.Answer == "" {
+			continue
+		}
+		result = append(result, pair)
+	}
+
+	return result, nil
+}
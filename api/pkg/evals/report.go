@@ -0,0 +1,197 @@
+package evals
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Report of an evals run, field names follow the session eval metadata
+type Report struct {
+	RunID    string    `json:"eval_run_id"`
+	Target   string    `json:"target"`
+	Started  time.Time `json:"started"`
+	Duration float64   `json:"duration_seconds"`
+
+	Total   int `json:"total"`
+	Passed  int `json:"passed"`
+	Failed  int `json:"failed"`
+	Errored int `json:"errored"`
+
+	Results []*Result `json:"results"`
+}
+
+type Result struct {
+	Name           string  `json:"name"`
+	Question       string  `json:"question"`
+	ExpectedAnswer string  `json:"expected_answer"`
+	Answer         string  `json:"answer"`
+	Checker        string  `json:"checker"`
+	Grade          string  `json:"grade"`
+	Score          float64 `json:"eval_automatic_score"`
+	Reason         string  `json:"eval_automatic_reason"` // Judge reply
+	Passed         bool    `json:"passed"`
+	Error          string  `json:"error,omitempty"`
+	Duration       float64 `json:"duration_seconds"`
+}
+
+func (r *Report) add(result *Result) {
+	r.Results = append(r.Results, result)
+	r.Total++
+
+	switch {
+	case result.Error != "":
+		r.Errored++
+	case result.Passed:
+		r.Passed++
+	default:
+		r.Failed++
+	}
+}
+
+// Regressions returns the results that didn't pass. If the baseline report
+// is set, only the results that passed in the baseline are returned
+func (r *Report) Regressions(baseline *Report) []*Result {
+	passedBefore := make(map[string]bool)
+	if baseline != nil {
+		for _, result := range baseline.Results {
+			if result.Passed {
+				passedBefore[result.Name] = true
+			}
+		}
+	}
+
+	var regressions []*Result
+
+	for _, result := range r.Results {
+		if result.Passed {
+			continue
+		}
+
+		if baseline != nil && !passedBefore[result.Name] {
+			continue
+		}
+
+		regressions = append(regressions, result)
+	}
+
+	return regressions
+}
+
+func LoadReport(path string) (*Report, error) {
+	bts, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report: %w", err)
+	}
+
+	var report Report
+	err = json.Unmarshal(bts, &report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse report: %w", err)
+	}
+
+	return &report, nil
+}
+
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Content string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report in the JUnit XML format understood by most CI systems
+func (r *Report) WriteJUnit(w io.Writer) error {
+	suite := junitTestSuite{
+		Name:      "helix-evals",
+		Tests:     r.Total,
+		Failures:  r.Failed,
+		Errors:    r.Errored,
+		Time:      formatSeconds(r.Duration),
+		Timestamp: r.Started.Format(time.RFC3339),
+	}
+
+	for _, result := range r.Results {
+		tc := junitTestCase{
+			Name:      result.Name,
+			ClassName: result.Checker,
+			Time:      formatSeconds(result.Duration),
+			SystemOut: fmt.Sprintf("Question: %s\nExpected answer: %s\nAnswer: %s", result.Question, result.ExpectedAnswer, result.Answer),
+		}
+
+		switch {
+		case result.Error != "":
+			tc.Error = &junitMessage{
+				Message: result.Error,
+			}
+		case !result.Passed:
+			tc.Failure = &junitMessage{
+				Message: fmt.Sprintf("graded %s (score %.2f)", result.Grade, result.Score),
+				Content: result.Reason,
+			}
+		}
+
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	err = enc.Encode(junitTestSuites{
+		Tests:    r.Total,
+		Failures: r.Failed,
+		Errors:   r.Errored,
+		Time:     formatSeconds(r.Duration),
+		Suites:   []junitTestSuite{suite},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+func formatSeconds(s float64) string {
+	return fmt.Sprintf("%.3f", s)
+}
@@ -0,0 +1,618 @@
+package filestore
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultS3Region = "us-east-1"
+	// S3 rejects multipart parts smaller than 5MiB, except for the last one
+	minS3PartSize     = 5 * 1024 * 1024
+	defaultS3PartSize = 16 * 1024 * 1024
+	// objects over 5GiB can't be copied with a single CopyObject call
+	maxS3CopySize     = 5 * 1024 * 1024 * 1024
+	s3CopyPartSize    = 512 * 1024 * 1024
+	maxS3DeleteBatch  = 1000
+	s3SignedURLExpiry = 20 * time.Minute
+)
+
+type S3StorageConfig struct {
+	Bucket string
+	Region string
+	// Endpoint of S3 compatible services such as MinIO, empty for AWS
+	Endpoint     string
+	UsePathStyle bool
+	// PartSize is the size of the parts of multipart uploads, files smaller
+	// than a part are uploaded with a single request
+	PartSize int64
+}
+
+// S3Storage stores the files in an S3 compatible bucket. S3 has no folders,
+// they are key prefixes and CreateFolder writes an empty "folder/" object so
+// that empty folders can be listed.
+type S3Storage struct {
+	client   *s3.Client
+	presign  *s3.PresignClient
+	bucket   string
+	partSize int64
+}
+
+// NewS3Storage creates an S3 filestore. Credentials are resolved through the
+// default AWS chain (environment, shared config, IAM role).
+func NewS3Storage(ctx context.Context, cfg S3StorageConfig) (*S3Storage, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("s3 bucket not specified")
+	}
+
+	if cfg.PartSize == 0 {
+		cfg.PartSize = defaultS3PartSize
+	}
+	if cfg.PartSize < minS3PartSize {
+		return nil, fmt.Errorf("s3 part size must be at least %d bytes", minS3PartSize)
+	}
+
+	var opts []func(*awsconfig.LoadOptions) error
+	if cfg.Region != "" {
+		opts = append(opts, awsconfig.WithRegion(cfg.Region))
+	}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	if awsCfg.Region == "" {
+		awsCfg.Region = defaultS3Region
+	}
+
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if cfg.Endpoint != "" {
+			o.BaseEndpoint = aws.String(cfg.Endpoint)
+		}
+		o.UsePathStyle = cfg.UsePathStyle
+	})
+
+	return &S3Storage{
+		client:   client,
+		presign:  s3.NewPresignClient(client),
+		bucket:   cfg.Bucket,
+		partSize: cfg.PartSize,
+	}, nil
+}
+
+func (s *S3Storage) List(ctx context.Context, prefix string) ([]FileStoreItem, error) {
+	folder := folderKey(prefix)
+
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket:    aws.String(s.bucket),
+		Prefix:    aws.String(folder),
+		Delimiter: aws.String("/"),
+	})
+
+	items := []FileStoreItem{}
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list s3://%s/%s: %w", s.bucket, folder, err)
+		}
+
+		for _, p := range page.CommonPrefixes {
+			items = append(items, s.folderItem(strings.TrimSuffix(aws.ToString(p.Prefix), "/"), time.Time{}))
+		}
+
+		for _, obj := range page.Contents {
+			key := aws.ToString(obj.Key)
+			// Skip the placeholder of the listed folder
+			if strings.HasSuffix(key, "/") {
+				continue
+			}
+			items = append(items, s.fileItem(key, aws.ToInt64(obj.Size), aws.ToTime(obj.LastModified)))
+		}
+	}
+
+	return items, nil
+}
+
+func (s *S3Storage) Get(ctx context.Context, path string) (FileStoreItem, error) {
+	key := objectKey(path)
+
+	head, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err == nil {
+		return s.fileItem(key, aws.ToInt64(head.ContentLength), aws.ToTime(head.LastModified)), nil
+	}
+	if !isS3NotFound(err) {
+		return FileStoreItem{}, fmt.Errorf("error fetching s3 object attributes: %w", err)
+	}
+
+	// Not a file, check if it's a folder
+	out, err := s.client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+		Bucket:  aws.String(s.bucket),
+		Prefix:  aws.String(folderKey(path)),
+		MaxKeys: aws.Int32(1),
+	})
+	if err != nil {
+		return FileStoreItem{}, fmt.Errorf("error fetching s3 object attributes: %w", err)
+	}
+	if len(out.Contents) == 0 {
+		return FileStoreItem{}, fmt.Errorf("error fetching s3 object attributes: %s not found", key)
+	}
+
+	return s.folderItem(key, aws.ToTime(out.Contents[0].LastModified)), nil
+}
+
+func (s *S3Storage) SignedURL(ctx context.Context, path string) (string, error) {
+	req, err := s.presign.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(objectKey(path)),
+	}, s3.WithPresignExpires(s3SignedURLExpiry))
+	if err != nil {
+		return "", fmt.Errorf("failed to presign s3 object: %w", err)
+	}
+	return req.URL, nil
+}
+
+func (s *S3Storage) CreateFolder(ctx context.Context, path string) (FileStoreItem, error) {
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(folderKey(path)),
+		Body:   bytes.NewReader(nil),
+	})
+	if err != nil {
+		return FileStoreItem{}, fmt.Errorf("failed to create s3 folder: %w", err)
+	}
+
+	return s.folderItem(objectKey(path), time.Now()), nil
+}
+
+func (s *S3Storage) OpenFile(ctx context.Context, path string) (io.ReadCloser, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(objectKey(path)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get s3 object: %w", err)
+	}
+	return out.Body, nil
+}
+
+func (s *S3Storage) WriteFile(ctx context.Context, path string, r io.Reader) (FileStoreItem, error) {
+	if err := s.upload(ctx, objectKey(path), r); err != nil {
+		return FileStoreItem{}, err
+	}
+	return s.Get(ctx, path)
+}
+
+// DownloadFolder streams the folder as a tar, the entries are relative to the
+// folder. The returned reader is an io.ReadCloser, closing it stops the
+// download.
+func (s *S3Storage) DownloadFolder(ctx context.Context, path string) (io.Reader, error) {
+	folder := folderKey(path)
+
+	pr, pw := io.Pipe()
+
+	go func() {
+		tarWriter := tar.NewWriter(pw)
+
+		err := s.walk(ctx, folder, func(obj s3types.Object) error {
+			key := aws.ToString(obj.Key)
+			name := strings.TrimPrefix(key, folder)
+
+			if name == "" {
+				return nil
+			}
+
+			if strings.HasSuffix(key, "/") {
+				return tarWriter.WriteHeader(&tar.Header{
+					Typeflag: tar.TypeDir,
+					Name:     name,
+					Mode:     0755,
+					ModTime:  aws.ToTime(obj.LastModified),
+				})
+			}
+
+			reader, err := s.OpenFile(ctx, key)
+			if err != nil {
+				return err
+			}
+			defer reader.Close()
+
+			if err := tarWriter.WriteHeader(&tar.Header{
+				Typeflag: tar.TypeReg,
+				Name:     name,
+				Mode:     0644,
+				Size:     aws.ToInt64(obj.Size),
+				ModTime:  aws.ToTime(obj.LastModified),
+			}); err != nil {
+				return err
+			}
+
+			_, err = io.Copy(tarWriter, reader)
+			return err
+		})
+		if err == nil {
+			err = tarWriter.Close()
+		}
+
+		pw.CloseWithError(err)
+	}()
+
+	return pr, nil
+}
+
+// UploadFolder uploads a tar stream to the folder, each file is streamed to
+// the bucket without being buffered to disk
+func (s *S3Storage) UploadFolder(ctx context.Context, path string, r io.Reader) error {
+	folder := folderKey(path)
+
+	tarReader := tar.NewReader(r)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error reading tar header: %w", err)
+		}
+
+		name := tarEntryName(header.Name)
+		if name == "" {
+			continue
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if _, err := s.CreateFolder(ctx, folder+name); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := s.upload(ctx, folder+name, tarReader); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Rename moves a file or all the files of a folder with server side copies
+func (s *S3Storage) Rename(ctx context.Context, path string, newPath string) (FileStoreItem, error) {
+	key := objectKey(path)
+	newKey := objectKey(newPath)
+
+	head, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	switch {
+	case err == nil:
+		if err := s.copyObject(ctx, key, newKey, aws.ToInt64(head.ContentLength)); err != nil {
+			return FileStoreItem{}, fmt.Errorf("failed to rename s3 object: %w", err)
+		}
+		if err := s.deleteKeys(ctx, []string{key}); err != nil {
+			return FileStoreItem{}, fmt.Errorf("failed to delete original s3 object after renaming: %w", err)
+		}
+	case isS3NotFound(err):
+		folder := folderKey(path)
+		newFolder := folderKey(newPath)
+
+		var moved []string
+		err := s.walk(ctx, folder, func(obj s3types.Object) error {
+			objKey := aws.ToString(obj.Key)
+			moved = append(moved, objKey)
+			return s.copyObject(ctx, objKey, newFolder+strings.TrimPrefix(objKey, folder), aws.ToInt64(obj.Size))
+		})
+		if err != nil {
+			return FileStoreItem{}, fmt.Errorf("error copying s3 objects during rename: %w", err)
+		}
+		if len(moved) == 0 {
+			return FileStoreItem{}, fmt.Errorf("failed to rename s3 object: %s not found", key)
+		}
+		if err := s.deleteKeys(ctx, moved); err != nil {
+			return FileStoreItem{}, fmt.Errorf("error deleting original s3 objects post rename: %w", err)
+		}
+	default:
+		return FileStoreItem{}, fmt.Errorf("error fetching s3 object attributes: %w", err)
+	}
+
+	return s.Get(ctx, newPath)
+}
+
+// Delete removes the file or the folder with everything in it
+func (s *S3Storage) Delete(ctx context.Context, path string) error {
+	var keys []string
+	if key := objectKey(path); key != "" {
+		keys = append(keys, key)
+	}
+
+	err := s.walk(ctx, folderKey(path), func(obj s3types.Object) error {
+		keys = append(keys, aws.ToString(obj.Key))
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error listing s3 objects during delete: %w", err)
+	}
+
+	if err := s.deleteKeys(ctx, keys); err != nil {
+		return fmt.Errorf("failed to delete s3 objects: %w", err)
+	}
+	return nil
+}
+
+func (s *S3Storage) CopyFile(ctx context.Context, fromPath string, toPath string) error {
+	head, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(objectKey(fromPath)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get source file: %w", err)
+	}
+
+	if err := s.copyObject(ctx, objectKey(fromPath), objectKey(toPath), aws.ToInt64(head.ContentLength)); err != nil {
+		return fmt.Errorf("failed to copy file: %w", err)
+	}
+	return nil
+}
+
+// upload writes the reader to the key, readers larger than a part are sent
+// as a multipart upload so only one part is held in memory
+func (s *S3Storage) upload(ctx context.Context, key string, r io.Reader) error {
+	buf := make([]byte, s.partSize)
+
+	n, err := io.ReadFull(r, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket: aws.String(s.bucket),
+			Key:    aws.String(key),
+			Body:   bytes.NewReader(buf[:n]),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to upload s3 object %s: %w", key, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read content of %s: %w", key, err)
+	}
+
+	created, err := s.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start multipart upload of %s: %w", key, err)
+	}
+
+	var parts []s3types.CompletedPart
+
+	for partNumber := int32(1); n > 0; partNumber++ {
+		part, err := s.client.UploadPart(ctx, &s3.UploadPartInput{
+			Bucket:     aws.String(s.bucket),
+			Key:        aws.String(key),
+			UploadId:   created.UploadId,
+			PartNumber: aws.Int32(partNumber),
+			Body:       bytes.NewReader(buf[:n]),
+		})
+		if err != nil {
+			s.abortUpload(key, created.UploadId)
+			return fmt.Errorf("failed to upload part %d of %s: %w", partNumber, key, err)
+		}
+
+		parts = append(parts, s3types.CompletedPart{
+			ETag:       part.ETag,
+			PartNumber: aws.Int32(partNumber),
+		})
+
+		n, err = io.ReadFull(r, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			s.abortUpload(key, created.UploadId)
+			return fmt.Errorf("failed to read content of %s: %w", key, err)
+		}
+	}
+
+	return s.completeUpload(ctx, key, created.UploadId, parts)
+}
+
+// copyObject copies the object in the bucket, objects over the CopyObject
+// size limit are copied part by part
+func (s *S3Storage) copyObject(ctx context.Context, from, to string, size int64) error {
+	source := copySource(s.bucket, from)
+
+	if size <= maxS3CopySize {
+		_, err := s.client.CopyObject(ctx, &s3.CopyObjectInput{
+			Bucket:     aws.String(s.bucket),
+			Key:        aws.String(to),
+			CopySource: aws.String(source),
+		})
+		return err
+	}
+
+	created, err := s.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(to),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start multipart copy of %s: %w", from, err)
+	}
+
+	var parts []s3types.CompletedPart
+
+	partNumber := int32(1)
+	for offset := int64(0); offset < size; offset += s3CopyPartSize {
+		end := min(offset+s3CopyPartSize, size) - 1
+
+		part, err := s.client.UploadPartCopy(ctx, &s3.UploadPartCopyInput{
+			Bucket:          aws.String(s.bucket),
+			Key:             aws.String(to),
+			UploadId:        created.UploadId,
+			PartNumber:      aws.Int32(partNumber),
+			CopySource:      aws.String(source),
+			CopySourceRange: aws.String(fmt.Sprintf("bytes=%d-%d", offset, end)),
+		})
+		if err != nil {
+			s.abortUpload(to, created.UploadId)
+			return fmt.Errorf("failed to copy part %d of %s: %w", partNumber, from, err)
+		}
+
+		parts = append(parts, s3types.CompletedPart{
+			ETag:       part.CopyPartResult.ETag,
+			PartNumber: aws.Int32(partNumber),
+		})
+		partNumber++
+	}
+
+	return s.completeUpload(ctx, to, created.UploadId, parts)
+}
+
+func (s *S3Storage) completeUpload(ctx context.Context, key string, uploadID *string, parts []s3types.CompletedPart) error {
+	_, err := s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(s.bucket),
+		Key:             aws.String(key),
+		UploadId:        uploadID,
+		MultipartUpload: &s3types.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		s.abortUpload(key, uploadID)
+		return fmt.Errorf("failed to complete multipart upload of %s: %w", key, err)
+	}
+	return nil
+}
+
+// abortUpload drops the uploaded parts, S3 keeps (and bills) them otherwise.
+// It runs on a fresh context as the upload context is often the cancelled one.
+func (s *S3Storage) abortUpload(key string, uploadID *string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := s.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(s.bucket),
+		Key:      aws.String(key),
+		UploadId: uploadID,
+	})
+	if err != nil {
+		log.Warn().Err(err).Str("key", key).Msg("failed to abort s3 multipart upload")
+	}
+}
+
+// walk calls fn for every object under the prefix, including the ones in
+// sub folders
+func (s *S3Storage) walk(ctx context.Context, prefix string, fn func(obj s3types.Object) error) error {
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		for _, obj := range page.Contents {
+			if err := fn(obj); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *S3Storage) deleteKeys(ctx context.Context, keys []string) error {
+	for start := 0; start < len(keys); start += maxS3DeleteBatch {
+		batch := keys[start:min(start+maxS3DeleteBatch, len(keys))]
+
+		objects := make([]s3types.ObjectIdentifier, 0, len(batch))
+		for _, key := range batch {
+			objects = append(objects, s3types.ObjectIdentifier{Key: aws.String(key)})
+		}
+
+		out, err := s.client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(s.bucket),
+			Delete: &s3types.Delete{
+				Objects: objects,
+				Quiet:   aws.Bool(true),
+			},
+		})
+		if err != nil {
+			return err
+		}
+		if len(out.Errors) > 0 {
+			return fmt.Errorf("failed to delete %s: %s", aws.ToString(out.Errors[0].Key), aws.ToString(out.Errors[0].Message))
+		}
+	}
+
+	return nil
+}
+
+func (s *S3Storage) fileItem(key string, size int64, modified time.Time) FileStoreItem {
+	return FileStoreItem{
+		Name:    path.Base(key),
+		Path:    key,
+		URL:     fmt.Sprintf("s3://%s/%s", s.bucket, key),
+		Created: modified.Unix(),
+		Size:    size,
+	}
+}
+
+func (s *S3Storage) folderItem(key string, modified time.Time) FileStoreItem {
+	item := s.fileItem(key, 0, modified)
+	item.Directory = true
+	if modified.IsZero() {
+		item.Created = 0
+	}
+	return item
+}
+
+func objectKey(p string) string {
+	return strings.Trim(p, "/")
+}
+
+func folderKey(p string) string {
+	key := objectKey(p)
+	if key == "" {
+		return ""
+	}
+	return key + "/"
+}
+
+// tarEntryName cleans the name of a tar entry so it can't escape the folder,
+// it's empty for the root of the tar
+func tarEntryName(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+// copySource is the URL encoded source of server side copies
+func copySource(bucket, key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return bucket + "/" + strings.Join(segments, "/")
+}
+
+func isS3NotFound(err error) bool {
+	var notFound *s3types.NotFound
+	var noSuchKey *s3types.NoSuchKey
+	return errors.As(err, &notFound) || errors.As(err, &noSuchKey)
+}
+
+// Compile-time interface check:
+var _ FileStore = (*S3Storage)(nil)
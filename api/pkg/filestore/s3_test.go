@@ -0,0 +1,204 @@
+package filestore
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/helixml/helix/api/pkg/system"
+)
+
+// Runs against any S3 compatible service, for example:
+//
+//	docker run -p 9000:9000 minio/minio server /data
+//	S3_ENDPOINT=http://localhost:9000 AWS_ACCESS_KEY_ID=minioadmin AWS_SECRET_ACCESS_KEY=minioadmin go test ./...
+func newTestS3Storage(t *testing.T) *S3Storage {
+	endpoint := os.Getenv("S3_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("S3_ENDPOINT not set, skipping S3 tests")
+	}
+
+	ctx := context.Background()
+	bucketName := "helix-test-" + system.GenerateID()
+
+	s, err := NewS3Storage(ctx, S3StorageConfig{
+		Bucket:       bucketName,
+		Endpoint:     endpoint,
+		UsePathStyle: true,
+		PartSize:     minS3PartSize,
+	})
+	require.NoError(t, err)
+
+	_, err = s.client.CreateBucket(ctx, &s3.CreateBucketInput{
+		Bucket: aws.String(bucketName),
+	})
+	require.NoError(t, err)
+
+	return s
+}
+
+func readAll(t *testing.T, s *S3Storage, path string) string {
+	rc, err := s.OpenFile(context.Background(), path)
+	require.NoError(t, err)
+	defer rc.Close()
+
+	bts, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	return string(bts)
+}
+
+func TestS3Storage_Files(t *testing.T) {
+	s := newTestS3Storage(t)
+	ctx := context.Background()
+
+	_, err := s.CreateFolder(ctx, "dev/users/u1/empty")
+	require.NoError(t, err)
+
+	item, err := s.WriteFile(ctx, "dev/users/u1/a.txt", strings.NewReader("hello a"))
+	require.NoError(t, err)
+	assert.Equal(t, "dev/users/u1/a.txt", item.Path)
+	assert.Equal(t, "a.txt", item.Name)
+	assert.Equal(t, int64(7), item.Size)
+
+	_, err = s.WriteFile(ctx, "dev/users/u1/sub/b.txt", strings.NewReader("hello b"))
+	require.NoError(t, err)
+
+	items, err := s.List(ctx, "dev/users/u1")
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+
+	assert.Equal(t, "dev/users/u1/empty", items[0].Path)
+	assert.True(t, items[0].Directory)
+	assert.Equal(t, "dev/users/u1/sub", items[1].Path)
+	assert.True(t, items[1].Directory)
+	assert.Equal(t, "dev/users/u1/a.txt", items[2].Path)
+	assert.False(t, items[2].Directory)
+
+	folder, err := s.Get(ctx, "dev/users/u1/sub")
+	require.NoError(t, err)
+	assert.True(t, folder.Directory)
+
+	_, err = s.Get(ctx, "dev/users/u1/missing")
+	assert.Error(t, err)
+
+	// Signed URLs work without credentials
+	url, err := s.SignedURL(ctx, "dev/users/u1/a.txt")
+	require.NoError(t, err)
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	bts, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "hello a", string(bts))
+
+	require.NoError(t, s.CopyFile(ctx, "dev/users/u1/a.txt", "dev/users/u1/copy/a.txt"))
+	assert.Equal(t, "hello a", readAll(t, s, "dev/users/u1/copy/a.txt"))
+
+	renamed, err := s.Rename(ctx, "dev/users/u1/a.txt", "dev/users/u1/renamed.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "dev/users/u1/renamed.txt", renamed.Path)
+	_, err = s.Get(ctx, "dev/users/u1/a.txt")
+	assert.Error(t, err)
+
+	renamed, err = s.Rename(ctx, "dev/users/u1/sub", "dev/users/u1/moved")
+	require.NoError(t, err)
+	assert.True(t, renamed.Directory)
+	assert.Equal(t, "hello b", readAll(t, s, "dev/users/u1/moved/b.txt"))
+	_, err = s.Get(ctx, "dev/users/u1/sub")
+	assert.Error(t, err)
+
+	require.NoError(t, s.Delete(ctx, "dev/users/u1"))
+	items, err = s.List(ctx, "dev/users")
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+func TestS3Storage_Multipart(t *testing.T) {
+	s := newTestS3Storage(t)
+	ctx := context.Background()
+
+	// Two and a half parts
+	content := bytes.Repeat([]byte("0123456789"), minS3PartSize/4)
+
+	item, err := s.WriteFile(ctx, "dev/big.bin", bytes.NewReader(content))
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), item.Size)
+	assert.Equal(t, string(content), readAll(t, s, "dev/big.bin"))
+}
+
+func TestS3Storage_Folders(t *testing.T) {
+	s := newTestS3Storage(t)
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, entry := range []struct {
+		name    string
+		content string
+	}{
+		{name: "./"},
+		{name: "inputs/"},
+		{name: "inputs/a.txt", content: "hello a"},
+		{name: "../../escape.txt", content: "hello c"},
+	} {
+		hdr := &tar.Header{Name: entry.name, Mode: 0644, Size: int64(len(entry.content)), Typeflag: tar.TypeReg}
+		if strings.HasSuffix(entry.name, "/") {
+			hdr.Typeflag = tar.TypeDir
+			hdr.Mode = 0755
+		}
+		require.NoError(t, tw.WriteHeader(hdr))
+		_, err := tw.Write([]byte(entry.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+
+	require.NoError(t, s.UploadFolder(ctx, "dev/session", &buf))
+
+	assert.Equal(t, "hello a", readAll(t, s, "dev/session/inputs/a.txt"))
+	// Entries can't escape the folder
+	assert.Equal(t, "hello c", readAll(t, s, "dev/session/escape.txt"))
+
+	r, err := s.DownloadFolder(ctx, "dev/session")
+	require.NoError(t, err)
+
+	files := map[string]string{}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		bts, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[hdr.Name] = string(bts)
+	}
+
+	assert.Equal(t, map[string]string{
+		"escape.txt":   "hello c",
+		"inputs/":      "",
+		"inputs/a.txt": "hello a",
+	}, files)
+}
+
+func TestTarEntryName(t *testing.T) {
+	assert.Equal(t, "a/b.txt", tarEntryName("a/b.txt"))
+	assert.Equal(t, "b.txt", tarEntryName("./a/../b.txt"))
+	assert.Equal(t, "etc/passwd", tarEntryName("../../etc/passwd"))
+	assert.Equal(t, "", tarEntryName("./"))
+}
+
+func TestCopySource(t *testing.T) {
+	assert.Equal(t, "bucket/dev/users/u1/my%20file%3F.txt", copySource("bucket", "dev/users/u1/my file?.txt"))
+}
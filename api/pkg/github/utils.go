@@ -1,14 +1,18 @@
 package github
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net"
 	"os"
 	"path/filepath"
 
 	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
 	"github.com/helixml/helix/api/pkg/types"
 	crypto_ssh "golang.org/x/crypto/ssh"
@@ -61,6 +65,105 @@ func CloneOrUpdateRepo(
 	}
 }
 
+// CloneOrUpdateBareRepo keeps a bare clone of a single branch of the repo
+// up to date over HTTPS, the working tree is never checked out so the files
+// have to be read from the commit tree. If the branch is empty the default
+// branch of the repo is used. The token is optional, public repos can be
+// cloned without it.
+func CloneOrUpdateBareRepo(
+	ctx context.Context,
+	repo string,
+	branch string,
+	token string,
+	repoPath string,
+) (*git.Repository, error) {
+	url := fmt.Sprintf("https://github.com/%s.git", repo)
+
+	var auth transport.AuthMethod
+	if token != "" {
+		auth = &http.BasicAuth{
+			// GitHub accepts any username as long as the token is the password
+			Username: "helix",
+			Password: token,
+		}
+	}
+
+	repository, err := git.PlainOpen(repoPath)
+	switch {
+	case err == nil:
+		if isClonedFrom(repository, url, branch) {
+			head, err := repository.Head()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get HEAD: %v", err)
+			}
+
+			// Force the update so that we follow force pushes too
+			refSpec := fmt.Sprintf("+%s:%s", head.Name(), head.Name())
+
+			err = repository.FetchContext(ctx, &git.FetchOptions{
+				RemoteName: "origin",
+				RefSpecs:   []config.RefSpec{config.RefSpec(refSpec)},
+				Auth:       auth,
+				Tags:       git.NoTags,
+			})
+			if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+				return nil, fmt.Errorf("failed to fetch repo: %v", err)
+			}
+			return repository, nil
+		}
+
+		// The repo or the branch has changed since it was cloned, start over
+		err = os.RemoveAll(repoPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to remove previous clone: %v", err)
+		}
+	case errors.Is(err, git.ErrRepositoryNotExists):
+	default:
+		return nil, fmt.Errorf("failed to open existing repo: %v", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(repoPath), os.ModePerm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create directory: %v", err)
+	}
+
+	opts := &git.CloneOptions{
+		URL:          url,
+		Auth:         auth,
+		SingleBranch: true,
+		Tags:         git.NoTags,
+	}
+	if branch != "" {
+		opts.ReferenceName = plumbing.NewBranchReferenceName(branch)
+	}
+
+	repository, err = git.PlainCloneContext(ctx, repoPath, true, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone repo: %v", err)
+	}
+
+	return repository, nil
+}
+
+func isClonedFrom(repository *git.Repository, url, branch string) bool {
+	remote, err := repository.Remote("origin")
+	if err != nil {
+		return false
+	}
+
+	urls := remote.Config().URLs
+	if len(urls) == 0 || urls[0] != url {
+		return false
+	}
+
+	head, err := repository.Head()
+	if err != nil {
+		return false
+	}
+
+	return branch == "" || head.Name() == plumbing.NewBranchReferenceName(branch)
+}
+
 func CheckoutRepo(repoPath string, commitHash string) error {
 	repository, err := git.PlainOpen(repoPath)
 	if err != nil {
@@ -0,0 +1,438 @@
+package gptscript
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+// Limits of the code runs, the tools are validated against the same limits
+// but the requests can come from apps and assistants that were never validated
+const (
+	defaultCodeTimeoutSeconds = 30
+	maxCodeTimeoutSeconds     = 300
+	defaultCodeMemoryMB       = 256
+	maxCodeMemoryMB           = 4096
+	defaultCodeCPUs           = 1.0
+	maxCodeCPUs               = 4.0
+
+	// sandboxUser is the nobody user, the code never runs as root
+	sandboxUser = "65534:65534"
+	sandboxPids = 128
+	// sandboxCodeSizeMB is the size of the directory the code is copied to
+	sandboxCodeSizeMB = 16
+	// sandboxGracePeriod is the time the container outlives the timeout of the
+	// code, it's removed as soon as the run is done
+	sandboxGracePeriod = 60 * time.Second
+)
+
+// CodeSandbox runs the code snippets of the code tools in throwaway containers.
+// The working directory is a size-limited tmpfs the files are copied in and out
+// of, the code is copied to its own tmpfs, the rest of the container file
+// system is read-only.
+type CodeSandbox struct {
+	cfg config.CodeSandbox
+	// execCommand starts the container runtime, replaced in the tests
+	execCommand func(ctx context.Context, name string, args ...string) *exec.Cmd
+}
+
+func NewCodeSandbox(cfg config.CodeSandbox) *CodeSandbox {
+	if cfg.Runtime == "" {
+		cfg.Runtime = "docker"
+	}
+	if cfg.PythonImage == "" {
+		cfg.PythonImage = "python:3.12-slim"
+	}
+	if cfg.JavaScriptImage == "" {
+		cfg.JavaScriptImage = "node:20-slim"
+	}
+	if cfg.MaxOutputBytes <= 0 {
+		cfg.MaxOutputBytes = 1024 * 1024
+	}
+	if cfg.MaxFilesBytes <= 0 {
+		cfg.MaxFilesBytes = 10 * 1024 * 1024
+	}
+	if cfg.WorkspaceSizeMB <= 0 {
+		cfg.WorkspaceSizeMB = 256
+	}
+
+	return &CodeSandbox{
+		cfg:         cfg,
+		execCommand: exec.CommandContext,
+	}
+}
+
+// Run runs the code and returns its output and the files it created or
+// changed in the scratch directory. Failures of the code itself are reported
+// in the response, the error is only returned if the sandbox couldn't be set up.
+func (s *CodeSandbox) Run(ctx context.Context, req *types.CodeExecutionRequest) (*types.CodeExecutionResponse, error) {
+	image, command, err := s.languageImage(req.Language)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, inputs, err := scratchArchive(req.Files)
+	if err != nil {
+		return nil, err
+	}
+
+	codeFile := "main" + command.extension
+	codeArchive, _, err := scratchArchive([]*types.CodeFile{{Path: codeFile, Content: []byte(req.Code)}})
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := time.Duration(codeLimit(req.TimeoutSeconds, defaultCodeTimeoutSeconds, maxCodeTimeoutSeconds)) * time.Second
+
+	// The container idles until the code is started in it and stops on its
+	// own if it isn't removed
+	name := "helix-code-" + system.GenerateUUID()
+	args := s.runArgs(name, req, image)
+	args = append(args, "sleep", strconv.Itoa(int((timeout + sandboxGracePeriod).Seconds())))
+
+	out, err := s.execCommand(ctx, s.cfg.Runtime, args...).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start code container: %w: %s", err, out)
+	}
+	defer s.remove(name)
+
+	err = s.copyIn(ctx, name, "/code", codeArchive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy the code to the code container: %w", err)
+	}
+
+	if len(req.Files) > 0 {
+		err = s.copyIn(ctx, name, "/workspace", archive)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy the files to the code container: %w", err)
+		}
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	stdout := &limitedBuffer{limit: s.cfg.MaxOutputBytes}
+	stderr := &limitedBuffer{limit: s.cfg.MaxOutputBytes}
+
+	cmd := s.execCommand(runCtx, s.cfg.Runtime, "exec", name, command.interpreter, "/code/"+codeFile)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	start := time.Now()
+	err = cmd.Run()
+
+	resp := &types.CodeExecutionResponse{
+		DurationMs: int(time.Since(start).Milliseconds()),
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case errors.Is(runCtx.Err(), context.DeadlineExceeded):
+		// The code keeps running until the container is removed
+		resp.TimedOut = true
+		resp.ExitCode = -1
+	case errors.As(err, &exitErr):
+		resp.ExitCode = exitErr.ExitCode()
+	case err != nil:
+		resp.ExitCode = -1
+		resp.Error = fmt.Sprintf("failed to run %s: %s", s.cfg.Runtime, err)
+	}
+
+	resp.Stdout = stdout.String()
+	resp.Stderr = stderr.String()
+	resp.Truncated = stdout.truncated || stderr.truncated
+
+	files, truncated, err := s.collectFiles(ctx, name, inputs)
+	if err != nil {
+		return nil, err
+	}
+	resp.Files = files
+	resp.Truncated = resp.Truncated || truncated
+
+	return resp, nil
+}
+
+type codeCommand struct {
+	interpreter string
+	extension   string
+}
+
+func (s *CodeSandbox) languageImage(language types.CodeLanguage) (string, codeCommand, error) {
+	switch language {
+	case types.CodeLanguagePython:
+		return s.cfg.PythonImage, codeCommand{interpreter: "python", extension: ".py"}, nil
+	case types.CodeLanguageJavaScript:
+		return s.cfg.JavaScriptImage, codeCommand{interpreter: "node", extension: ".js"}, nil
+	default:
+		return "", codeCommand{}, fmt.Errorf("unsupported language: %s", language)
+	}
+}
+
+func (s *CodeSandbox) runArgs(name string, req *types.CodeExecutionRequest, image string) []string {
+	memoryMB := codeLimit(req.MemoryMB, defaultCodeMemoryMB, maxCodeMemoryMB)
+	cpus := codeLimit(req.CPUs, defaultCodeCPUs, maxCodeCPUs)
+
+	args := []string{
+		"run", "--rm", "--detach",
+		"--name", name,
+		"--user", sandboxUser,
+		"--memory", fmt.Sprintf("%dm", memoryMB),
+		// No swap on top of the memory limit
+		"--memory-swap", fmt.Sprintf("%dm", memoryMB),
+		"--cpus", strconv.FormatFloat(cpus, 'f', -1, 64),
+		"--pids-limit", strconv.Itoa(sandboxPids),
+		"--read-only",
+		"--tmpfs", "/tmp:rw,size=64m",
+		"--cap-drop", "ALL",
+		"--security-opt", "no-new-privileges",
+		"-e", "HOME=/tmp",
+		// The scratch directory is in memory and limited, the code can't fill
+		// the disk of the host
+		"--tmpfs", fmt.Sprintf("/workspace:rw,size=%dm,mode=1777", s.cfg.WorkspaceSizeMB),
+		// The code is copied in like the files, nothing of the host is mounted
+		"--tmpfs", fmt.Sprintf("/code:rw,size=%dm,mode=1777", sandboxCodeSizeMB),
+		"-w", "/workspace",
+	}
+
+	if !req.Network {
+		args = append(args, "--network", "none")
+	}
+
+	if s.cfg.OCIRuntime != "" {
+		args = append(args, "--runtime", s.cfg.OCIRuntime)
+	}
+
+	return append(args, image)
+}
+
+// codeLimit returns the default for unset limits and caps the others at the maximum
+func codeLimit[T int | float64](value, defaultValue, maxValue T) T {
+	if value <= 0 {
+		return defaultValue
+	}
+	return min(value, maxValue)
+}
+
+// copyIn extracts the tar archive to the directory of the container
+func (s *CodeSandbox) copyIn(ctx context.Context, name, dir string, archive []byte) error {
+	cmd := s.execCommand(ctx, s.cfg.Runtime, "exec", "-i", name, "tar", "-x", "-C", dir)
+	cmd.Stdin = bytes.NewReader(archive)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, out)
+	}
+	return nil
+}
+
+// remove stops and removes the container, the code may still be running
+func (s *CodeSandbox) remove(name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := s.execCommand(ctx, s.cfg.Runtime, "rm", "--force", name).Run()
+	if err != nil {
+		log.Warn().Err(err).Str("container", name).Msg("failed to remove code container")
+	}
+}
+
+// scratchArchive returns the tar archive of the input files that is copied to
+// the scratch directory and their hashes, so the unchanged files are not returned
+func scratchArchive(files []*types.CodeFile) ([]byte, map[string][sha256.Size]byte, error) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+
+	inputs := make(map[string][sha256.Size]byte, len(files))
+	dirs := make(map[string]bool)
+
+	for _, f := range files {
+		path, err := scratchPath(f.Path)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// The parent directories come first so the code can write to them
+		var parents []string
+		for dir := filepath.Dir(path); dir != "."; dir = filepath.Dir(dir) {
+			parents = append([]string{dir}, parents...)
+		}
+		for _, dir := range parents {
+			if dirs[dir] {
+				continue
+			}
+			dirs[dir] = true
+
+			err := tw.WriteHeader(&tar.Header{
+				Typeflag: tar.TypeDir,
+				Name:     filepath.ToSlash(dir) + "/",
+				Mode:     0o755,
+			})
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to archive directory of %s: %w", f.Path, err)
+			}
+		}
+
+		err = tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     filepath.ToSlash(path),
+			Mode:     0o644,
+			Size:     int64(len(f.Content)),
+		})
+		if err == nil {
+			_, err = tw.Write(f.Content)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to archive %s: %w", f.Path, err)
+		}
+
+		inputs[path] = sha256.Sum256(f.Content)
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, nil, fmt.Errorf("failed to archive the files: %w", err)
+	}
+
+	return buf.Bytes(), inputs, nil
+}
+
+// scratchPath returns the clean relative path, paths outside of the scratch
+// directory are rejected
+func scratchPath(path string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(path))
+	if clean == "." || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid file path: %s", path)
+	}
+	return clean, nil
+}
+
+// collectFiles copies the scratch directory out of the container and returns
+// its new and changed files. A scratch directory that can't be fully copied,
+// e.g. because the code made files unreadable, is reported as truncated.
+func (s *CodeSandbox) collectFiles(ctx context.Context, name string, inputs map[string][sha256.Size]byte) ([]*types.CodeFile, bool, error) {
+	stderr := &limitedBuffer{limit: 1024}
+
+	cmd := s.execCommand(ctx, s.cfg.Runtime, "exec", name, "tar", "-c", "-C", "/workspace", ".")
+	cmd.Stderr = stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to copy the files from the code container: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, false, fmt.Errorf("failed to copy the files from the code container: %w", err)
+	}
+
+	files, truncated, readErr := s.readFiles(stdout, inputs)
+	// Drain the rest so tar doesn't block on the pipe
+	_, _ = io.Copy(io.Discard, stdout)
+
+	err = cmd.Wait()
+	if readErr != nil || err != nil {
+		log.Warn().
+			Err(errors.Join(readErr, err)).
+			Str("container", name).
+			Str("stderr", stderr.String()).
+			Msg("failed to copy all the files from the code container")
+		truncated = true
+	}
+
+	slices.SortFunc(files, func(a, b *types.CodeFile) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return files, truncated, nil
+}
+
+// readFiles reads the regular files of the scratch directory archive. Symlinks
+// are skipped, they could point to the files of the host.
+func (s *CodeSandbox) readFiles(r io.Reader, inputs map[string][sha256.Size]byte) ([]*types.CodeFile, bool, error) {
+	var (
+		files     []*types.CodeFile
+		total     int64
+		truncated bool
+	)
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return files, truncated, nil
+		}
+		if err != nil {
+			return files, truncated, err
+		}
+
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		rel := filepath.Clean(filepath.FromSlash(hdr.Name))
+
+		file := &types.CodeFile{
+			Path: filepath.ToSlash(rel),
+			Size: hdr.Size,
+		}
+
+		if total+hdr.Size > s.cfg.MaxFilesBytes {
+			// The file is listed without its content
+			truncated = true
+			if _, ok := inputs[rel]; !ok {
+				files = append(files, file)
+			}
+			continue
+		}
+
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return files, truncated, err
+		}
+
+		if hash, ok := inputs[rel]; ok && hash == sha256.Sum256(content) {
+			continue
+		}
+
+		file.Content = content
+		total += hdr.Size
+		files = append(files, file)
+	}
+}
+
+// limitedBuffer keeps the first bytes of the output, the rest is dropped
+// so the code can't exhaust the memory of the runner
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	remaining := b.limit - b.buf.Len()
+	if len(p) > remaining {
+		b.truncated = true
+		if remaining > 0 {
+			b.buf.Write(p[:remaining])
+		}
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
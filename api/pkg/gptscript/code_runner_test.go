@@ -0,0 +1,252 @@
+package gptscript
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+// fakeRuntime replaces the container runtime, the commands executed in the
+// container run on the host with temporary directories as the scratch and the
+// code directories, the script gets the code directory in $CODE_DIR
+func fakeRuntime(t *testing.T, script string, calls *[][]string) func(ctx context.Context, name string, args ...string) *exec.Cmd {
+	workspace := t.TempDir()
+	code := t.TempDir()
+
+	return func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		*calls = append(*calls, append([]string{name}, args...))
+
+		if args[0] != "exec" {
+			return exec.CommandContext(ctx, "true")
+		}
+
+		// Skip the flags and the container name
+		command := args[1:]
+		if command[0] == "-i" {
+			command = command[1:]
+		}
+		command = command[1:]
+
+		if command[0] == "tar" {
+			for i, arg := range command {
+				switch arg {
+				case "/workspace":
+					command[i] = workspace
+				case "/code":
+					command[i] = code
+				}
+			}
+			return exec.CommandContext(ctx, command[0], command[1:]...)
+		}
+
+		cmd := exec.CommandContext(ctx, "sh", "-c", script)
+		cmd.Dir = workspace
+		cmd.Env = append(os.Environ(), "CODE_DIR="+code)
+		return cmd
+	}
+}
+
+func newTestSandbox(t *testing.T, script string, calls *[][]string) *CodeSandbox {
+	s := NewCodeSandbox(config.CodeSandbox{OCIRuntime: "runsc"})
+	s.execCommand = fakeRuntime(t, script, calls)
+	return s
+}
+
+func TestCodeSandbox_Run(t *testing.T) {
+	var calls [][]string
+	s := newTestSandbox(t, `cat data.csv; echo warning >&2; echo result > out.txt; echo changed > changed.txt; exit 3`, &calls)
+
+	resp, err := s.Run(context.Background(), &types.CodeExecutionRequest{
+		Language: types.CodeLanguagePython,
+		Code:     "print(1)",
+		MemoryMB: 512,
+		CPUs:     0.5,
+		Files: []*types.CodeFile{
+			{Path: "data.csv", Content: []byte("a,b")},
+			{Path: "changed.txt", Content: []byte("original")},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "a,b", resp.Stdout)
+	assert.Equal(t, "warning\n", resp.Stderr)
+	assert.Equal(t, 3, resp.ExitCode)
+	assert.False(t, resp.TimedOut)
+
+	// Unchanged inputs are not returned
+	require.Len(t, resp.Files, 2)
+	assert.Equal(t, "changed.txt", resp.Files[0].Path)
+	assert.Equal(t, "out.txt", resp.Files[1].Path)
+	assert.Equal(t, "result\n", string(resp.Files[1].Content))
+
+	// Start, copy the code and the files in, run, copy the files out and remove
+	require.Len(t, calls, 6)
+	args := strings.Join(calls[0], " ")
+	assert.True(t, strings.HasPrefix(args, "docker run --rm --detach"))
+	assert.Contains(t, args, "--network none")
+	assert.Contains(t, args, "--memory 512m --memory-swap 512m")
+	assert.Contains(t, args, "--cpus 0.5")
+	assert.Contains(t, args, "--runtime runsc")
+	assert.Contains(t, args, "--read-only")
+	assert.Contains(t, args, "--tmpfs /workspace:rw,size=256m,mode=1777")
+	assert.Contains(t, args, "--tmpfs /code:rw,size=16m,mode=1777")
+	assert.NotContains(t, args, " -v ")
+	assert.True(t, strings.HasSuffix(args, "python:3.12-slim sleep 90"))
+
+	name := calls[0][5]
+	assert.Equal(t, []string{"docker", "exec", "-i", name, "tar", "-x", "-C", "/code"}, calls[1])
+	assert.Equal(t, []string{"docker", "exec", "-i", name, "tar", "-x", "-C", "/workspace"}, calls[2])
+	assert.Equal(t, []string{"docker", "exec", name, "python", "/code/main.py"}, calls[3])
+	assert.Equal(t, []string{"docker", "exec", name, "tar", "-c", "-C", "/workspace", "."}, calls[4])
+	assert.Equal(t, []string{"docker", "rm", "--force", name}, calls[5])
+}
+
+func TestCodeSandbox_Network(t *testing.T) {
+	var calls [][]string
+	s := newTestSandbox(t, `cat "$CODE_DIR/main.js"`, &calls)
+
+	resp, err := s.Run(context.Background(), &types.CodeExecutionRequest{
+		Language: types.CodeLanguageJavaScript,
+		Code:     "console.log(1)",
+		Network:  true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "console.log(1)", resp.Stdout)
+
+	args := strings.Join(calls[0], " ")
+	assert.NotContains(t, args, "--network")
+	assert.True(t, strings.HasSuffix(args, "node:20-slim sleep 90"))
+
+	// Only the code is copied, there are no input files
+	assert.Equal(t, []string{"docker", "exec", "-i", calls[0][5], "tar", "-x", "-C", "/code"}, calls[1])
+	assert.Equal(t, []string{"docker", "exec", calls[0][5], "node", "/code/main.js"}, calls[2])
+}
+
+func TestCodeSandbox_ResourceLimits(t *testing.T) {
+	var calls [][]string
+	s := newTestSandbox(t, `true`, &calls)
+
+	// Apps and assistants aren't validated like the tools, the limits are
+	// capped by the sandbox
+	_, err := s.Run(context.Background(), &types.CodeExecutionRequest{
+		Language: types.CodeLanguagePython,
+		Code:     "print(1)",
+		MemoryMB: 1024 * 1024,
+		CPUs:     64,
+	})
+	require.NoError(t, err)
+
+	args := strings.Join(calls[0], " ")
+	assert.Contains(t, args, "--memory 4096m --memory-swap 4096m")
+	assert.Contains(t, args, "--cpus 4")
+
+	assert.Equal(t, maxCodeTimeoutSeconds, codeLimit(3600, defaultCodeTimeoutSeconds, maxCodeTimeoutSeconds))
+	assert.Equal(t, defaultCodeTimeoutSeconds, codeLimit(-1, defaultCodeTimeoutSeconds, maxCodeTimeoutSeconds))
+}
+
+func TestCodeSandbox_Timeout(t *testing.T) {
+	var calls [][]string
+	s := newTestSandbox(t, `sleep 5`, &calls)
+
+	resp, err := s.Run(context.Background(), &types.CodeExecutionRequest{
+		Language:       types.CodeLanguagePython,
+		Code:           "while True: pass",
+		TimeoutSeconds: 1,
+	})
+	require.NoError(t, err)
+
+	assert.True(t, resp.TimedOut)
+	assert.Equal(t, -1, resp.ExitCode)
+
+	// The container is removed with the code still running in it
+	last := calls[len(calls)-1]
+	assert.Equal(t, []string{"docker", "rm", "--force", calls[0][5]}, last)
+}
+
+func TestCodeSandbox_Limits(t *testing.T) {
+	var calls [][]string
+	s := newTestSandbox(t, `head -c 100 /dev/zero | tr '\0' a; head -c 100 /dev/zero > big.bin; ln -s /etc/passwd passwd`, &calls)
+	s.cfg.MaxOutputBytes = 10
+	s.cfg.MaxFilesBytes = 50
+
+	resp, err := s.Run(context.Background(), &types.CodeExecutionRequest{
+		Language: types.CodeLanguagePython,
+		Code:     "print('a' * 100)",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "aaaaaaaaaa", resp.Stdout)
+	assert.True(t, resp.Truncated)
+
+	// The big file is listed without its content, symlinks are skipped
+	require.Len(t, resp.Files, 1)
+	assert.Equal(t, "big.bin", resp.Files[0].Path)
+	assert.Equal(t, int64(100), resp.Files[0].Size)
+	assert.Nil(t, resp.Files[0].Content)
+}
+
+func TestCodeSandbox_InvalidRequest(t *testing.T) {
+	var calls [][]string
+	s := newTestSandbox(t, `true`, &calls)
+
+	_, err := s.Run(context.Background(), &types.CodeExecutionRequest{Language: "ruby", Code: "puts 1"})
+	assert.ErrorContains(t, err, "unsupported language")
+
+	_, err = s.Run(context.Background(), &types.CodeExecutionRequest{
+		Language: types.CodeLanguagePython,
+		Code:     "print(1)",
+		Files:    []*types.CodeFile{{Path: "../../etc/cron.d/job", Content: []byte("x")}},
+	})
+	assert.ErrorContains(t, err, "invalid file path")
+
+	assert.Empty(t, calls)
+}
+
+func TestScratchPath(t *testing.T) {
+	for path, valid := range map[string]bool{
+		"data.csv":            true,
+		"inputs/int_1/a.txt":  true,
+		"./a/../b.txt":        true,
+		"/etc/passwd":         false,
+		"../secret":           false,
+		"a/../../secret":      false,
+		".":                   false,
+		"..data/not-a-parent": true,
+	} {
+		_, err := scratchPath(path)
+		assert.Equal(t, valid, err == nil, path)
+	}
+}
+
+func TestScratchArchive(t *testing.T) {
+	archive, inputs, err := scratchArchive([]*types.CodeFile{
+		{Path: "inputs/int_1/a.txt", Content: []byte("a")},
+		{Path: "inputs/int_1/b.txt", Content: []byte("b")},
+	})
+	require.NoError(t, err)
+	assert.Len(t, inputs, 2)
+
+	var names []string
+	tr := tar.NewReader(bytes.NewReader(archive))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, hdr.Name)
+	}
+
+	assert.Equal(t, []string{"inputs/", "inputs/int_1/", "inputs/int_1/a.txt", "inputs/int_1/b.txt"}, names)
+}
@@ -19,6 +19,7 @@ import (
 type Executor interface {
 	ExecuteApp(ctx context.Context, app *types.GptScriptGithubApp) (*types.GptScriptResponse, error)
 	ExecuteScript(ctx context.Context, script *types.GptScript) (*types.GptScriptResponse, error)
+	ExecuteCode(ctx context.Context, req *types.CodeExecutionRequest) (*types.CodeExecutionResponse, error)
 }
 
 // DefaultExecutor runs GPTScript scripts on the GPTScript cluster through the
@@ -138,13 +139,52 @@ func (e *DefaultExecutor) ExecuteScript(ctx context.Context, script *types.GptSc
 	return &response, nil
 }
 
+// codeRequestTimeoutSlack is added to the timeout of the code for starting
+// the container and returning the files
+const codeRequestTimeoutSlack = 30 * time.Second
+
+// ExecuteCode runs the code in the sandbox of a GPTScript runner, the code is
+// not retried as it might have side effects
+func (e *DefaultExecutor) ExecuteCode(ctx context.Context, req *types.CodeExecutionRequest) (*types.CodeExecutionResponse, error) {
+	bts, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	header := map[string]string{
+		"kind": "code",
+	}
+
+	timeout := time.Duration(req.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = defaultCodeTimeoutSeconds * time.Second
+	}
+	timeout += codeRequestTimeoutSlack
+
+	resp, err := e.pubsub.StreamRequest(ctx, pubsub.ScriptRunnerStream, pubsub.AppQueue, bts, header, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request code execution: %w", err)
+	}
+
+	var response types.CodeExecutionResponse
+	if err := json.Unmarshal(resp, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal code execution response: %w", err)
+	}
+
+	return &response, nil
+}
+
 // DirectExecutor runs GPTScript scripts directly
-type DirectExecutor struct{}
+type DirectExecutor struct {
+	sandbox *CodeSandbox
+}
 
 var _ Executor = &TestFasterExecutor{}
 
 func NewDirectExecutor() *DirectExecutor {
-	return &DirectExecutor{}
+	return &DirectExecutor{
+		sandbox: NewCodeSandbox(config.CodeSandbox{}),
+	}
 }
 
 func (e *DirectExecutor) ExecuteApp(ctx context.Context, app *types.GptScriptGithubApp) (*types.GptScriptResponse, error) {
@@ -154,3 +194,7 @@ func (e *DirectExecutor) ExecuteApp(ctx context.Context, app *types.GptScriptGit
 func (e *DirectExecutor) ExecuteScript(ctx context.Context, script *types.GptScript) (*types.GptScriptResponse, error) {
 	return RunGPTScript(ctx, script)
 }
+
+func (e *DirectExecutor) ExecuteCode(ctx context.Context, req *types.CodeExecutionRequest) (*types.CodeExecutionResponse, error) {
+	return e.sandbox.Run(ctx, req)
+}
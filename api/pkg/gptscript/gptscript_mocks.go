@@ -1,10 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
 // Source: gptscript.go
-//
-// Generated by this command:
-//
-//	mockgen -source gptscript.go -destination gptscript_mocks.go -package gptscript
-//
 
 // Package gptscript is a generated GoMock package.
 package gptscript
@@ -50,11 +45,26 @@ func (m *MockExecutor) ExecuteApp(ctx context.Context, app *types.GptScriptGithu
 }
 
 // ExecuteApp indicates an expected call of ExecuteApp.
-func (mr *MockExecutorMockRecorder) ExecuteApp(ctx, app any) *gomock.Call {
+func (mr *MockExecutorMockRecorder) ExecuteApp(ctx, app interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteApp", reflect.TypeOf((*MockExecutor)(nil).ExecuteApp), ctx, app)
 }
 
+// ExecuteCode mocks base method.
+func (m *MockExecutor) ExecuteCode(ctx context.Context, req *types.CodeExecutionRequest) (*types.CodeExecutionResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteCode", ctx, req)
+	ret0, _ := ret[0].(*types.CodeExecutionResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExecuteCode indicates an expected call of ExecuteCode.
+func (mr *MockExecutorMockRecorder) ExecuteCode(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteCode", reflect.TypeOf((*MockExecutor)(nil).ExecuteCode), ctx, req)
+}
+
 // ExecuteScript mocks base method.
 func (m *MockExecutor) ExecuteScript(ctx context.Context, script *types.GptScript) (*types.GptScriptResponse, error) {
 	m.ctrl.T.Helper()
@@ -65,7 +75,7 @@ func (m *MockExecutor) ExecuteScript(ctx context.Context, script *types.GptScrip
 }
 
 // ExecuteScript indicates an expected call of ExecuteScript.
-func (mr *MockExecutorMockRecorder) ExecuteScript(ctx, script any) *gomock.Call {
+func (mr *MockExecutorMockRecorder) ExecuteScript(ctx, script interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteScript", reflect.TypeOf((*MockExecutor)(nil).ExecuteScript), ctx, script)
 }
@@ -28,12 +28,14 @@ const (
 // Runner connects using a WebSocket to the Control Plane
 // and listens for GPTScript tasks to run
 type Runner struct {
-	cfg *config.GPTScriptRunnerConfig
+	cfg     *config.GPTScriptRunnerConfig
+	sandbox *CodeSandbox
 }
 
 func NewRunner(cfg *config.GPTScriptRunnerConfig) *Runner {
 	return &Runner{
-		cfg: cfg,
+		cfg:     cfg,
+		sandbox: NewCodeSandbox(cfg.CodeSandbox),
 	}
 }
 
@@ -178,6 +180,8 @@ func (d *Runner) processMessage(ctx context.Context, conn *websocket.Conn, messa
 		return d.processAppRequest(ctx, conn, &envelope)
 	case types.RunnerEventRequestTool:
 		return d.processToolRequest(ctx, conn, &envelope)
+	case types.RunnerEventRequestCode:
+		return d.processCodeRequest(ctx, conn, &envelope)
 	default:
 		return fmt.Errorf("unknown message type: %s", envelope.Type)
 	}
@@ -235,6 +239,38 @@ func (d *Runner) processToolRequest(ctx context.Context, conn *websocket.Conn, r
 	return d.respond(conn, req.RequestID, req.Reply, resp)
 }
 
+func (d *Runner) processCodeRequest(ctx context.Context, conn *websocket.Conn, req *types.RunnerEventRequestEnvelope) error {
+	logger := log.With().Str("request_id", req.RequestID).Logger()
+
+	var codeReq types.CodeExecutionRequest
+	if err := json.Unmarshal(req.Payload, &codeReq); err != nil {
+		return fmt.Errorf("failed to unmarshal code execution request: %w", err)
+	}
+
+	logger.Debug().
+		Str("language", string(codeReq.Language)).
+		Int("files", len(codeReq.Files)).
+		Msg("processing code execution request")
+
+	resp, err := d.sandbox.Run(ctx, &codeReq)
+	if err != nil {
+		// Respond with the error, otherwise the requestor waits until it times out
+		logger.Err(err).Msg("failed to run code")
+		resp = &types.CodeExecutionResponse{
+			ExitCode: -1,
+			Error:    err.Error(),
+		}
+	}
+
+	logger.Info().
+		Int("exit_code", resp.ExitCode).
+		Bool("timed_out", resp.TimedOut).
+		Int("duration_ms", resp.DurationMs).
+		Msg("message processed")
+
+	return d.respond(conn, req.RequestID, req.Reply, resp)
+}
+
 func (r *Runner) respond(conn *websocket.Conn, reqID, reply string, resp interface{}) error {
 	bts, err := json.Marshal(resp)
 	if err != nil {
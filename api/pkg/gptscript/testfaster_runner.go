@@ -38,6 +38,10 @@ func (e *TestFasterExecutor) ExecuteScript(ctx context.Context, script *types.Gp
 	return e.runGPTScriptTestfaster(ctx, script)
 }
 
+func (e *TestFasterExecutor) ExecuteCode(_ context.Context, _ *types.CodeExecutionRequest) (*types.CodeExecutionResponse, error) {
+	return nil, fmt.Errorf("code tools are not supported by the TestFaster executor, use the GPTScript runners")
+}
+
 // TODO: delete
 type TestFasterCluster struct {
 	PoolID  string
@@ -170,6 +170,11 @@ const (
 	Model_Ollama_Llama3_8b_q8_0 string = "llama3:8b-instruct-q8_0"
 
 	Model_Ollama_Phi3 string = "phi3:instruct"
+
+	// Embedding models
+	Model_Ollama_NomicEmbedText    string = "nomic-embed-text:v1.5"
+	Model_Ollama_MxbaiEmbedLarge   string = "mxbai-embed-large:335m"
+	Model_Ollama_DefaultEmbeddings string = Model_Ollama_NomicEmbedText
 )
 
 // See also types/models.go for model name constants
@@ -385,6 +390,24 @@ func GetDefaultOllamaModels() ([]*OllamaGenericText, error) {
 			Description:   "Fast and good for everyday tasks",
 			Hide:          true,
 		},
+
+		// Embedding models, only used through /v1/embeddings so they are hidden from the chat
+		{
+			Id:            "nomic-embed-text:v1.5", // https://ollama.com/library/nomic-embed-text:v1.5
+			Name:          "Nomic Embed Text v1.5",
+			Memory:        MB * 1024,
+			ContextLength: 8192,
+			Description:   "Text embeddings with 768 dimensions, 8K context",
+			Hide:          true,
+		},
+		{
+			Id:            "mxbai-embed-large:335m", // https://ollama.com/library/mxbai-embed-large:335m
+			Name:          "mxbai Embed Large",
+			Memory:        MB * 1536,
+			ContextLength: 512,
+			Description:   "Text embeddings with 1024 dimensions, 512 context",
+			Hide:          true,
+		},
 	}
 
 	return models, nil
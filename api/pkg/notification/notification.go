@@ -22,6 +22,7 @@ type Event int
 const (
 	EventFinetuningStarted  Event = 1
 	EventFinetuningComplete Event = 2
+	EventTriggerComplete    Event = 3
 )
 
 func (e Event) String() string {
@@ -30,6 +31,8 @@ func (e Event) String() string {
 		return "finetuning_started"
 	case EventFinetuningComplete:
 		return "finetuning_complete"
+	case EventTriggerComplete:
+		return "trigger_complete"
 	default:
 		return "unknown_event"
 	}
@@ -38,8 +41,10 @@ func (e Event) String() string {
 type Notification struct {
 	Event   Event
 	Session *types.Session
+	// Message is the response of the app, set for trigger events
+	Message string
 
-	// Populated by the provider
+	// Populated by the provider unless the email is set
 	Email     string
 	FirstName string
 }
@@ -67,16 +72,19 @@ func New(cfg *config.Notifications, authenticator auth.Authenticator) (Notifier,
 }
 
 func (n *NotificationsProvider) Notify(ctx context.Context, notification *Notification) error {
-	user, err := n.authenticator.GetUserByID(ctx, notification.Session.Owner)
-	if err != nil {
-		return fmt.Errorf("failed to get user '%s' details: %w", notification.Session.Owner, err)
+	// Trigger outputs send to the configured addresses instead of the session owner
+	if notification.Email == "" {
+		user, err := n.authenticator.GetUserByID(ctx, notification.Session.Owner)
+		if err != nil {
+			return fmt.Errorf("failed to get user '%s' details: %w", notification.Session.Owner, err)
+		}
+
+		notification.Email = user.Email
+		notification.FirstName = strings.Split(user.FullName, " ")[0]
 	}
 
 	log.Debug().
-		Str("email", user.Email).Str("notification", notification.Event.String()).Msg("sending notification")
-
-	notification.Email = user.Email
-	notification.FirstName = strings.Split(user.FullName, " ")[0]
+		Str("email", notification.Email).Str("notification", notification.Event.String()).Msg("sending notification")
 
 	if n.email.Enabled() {
 		err := n.email.Notify(ctx, notification)
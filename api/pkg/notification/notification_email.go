@@ -114,6 +114,19 @@ func (e *Email) getEmailMessage(n *Notification) (title, message string, err err
 		}
 
 		return fmt.Sprintf("Finetuning Complete - Ready for Action [%s]", n.Session.Name), buf.String(), nil
+	case EventTriggerComplete:
+		var buf bytes.Buffer
+
+		err = triggerCompletedTmpl.Execute(&buf, &templateData{
+			SessionURL:  fmt.Sprintf("%s/session/%s", e.cfg.AppURL, n.Session.ID),
+			SessionName: n.Session.Name,
+			Message:     n.Message,
+		})
+		if err != nil {
+			return "", "", fmt.Errorf("failed to execute template: %w", err)
+		}
+
+		return fmt.Sprintf("Scheduled Run Complete [%s]", n.Session.Name), buf.String(), nil
 	default:
 		return "", "", fmt.Errorf("unknown event '%s'", n.Event.String())
 	}
@@ -123,11 +136,13 @@ type templateData struct {
 	SessionURL  string
 	FirstName   string
 	SessionName string
+	Message     string
 }
 
 var (
 	finetuningStartedTmpl   = template.Must(template.New("").Parse(finetuningStartedTemplate))
 	finetuningCompletedTmpl = template.Must(template.New("").Parse(finetuningCompletedTemplate))
+	triggerCompletedTmpl    = template.Must(template.New("").Parse(triggerCompletedTemplate))
 )
 
 var finetuningStartedTemplate = `
@@ -155,3 +170,16 @@ We hope you enjoy the improvements. If you have any feedback or require assistan
 Best regards,<br/><br/>
 The Helix Team
 `
+
+var triggerCompletedTemplate = `
+Hello,
+<br/><br/>
+The scheduled run of '{{ .SessionName }}' has completed:
+<br/><br/>
+<div style="white-space: pre-wrap">{{ .Message }}</div>
+<br/><br/>
+You can view the full conversation at: <a href="{{ .SessionURL }}" target="_blank">{{ .SessionURL }}</a>.
+<br/><br/>
+Best regards,<br/><br/>
+The Helix Team
+`
@@ -0,0 +1,331 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/rs/zerolog/log"
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/helixml/helix/api/pkg/config"
+	oai "github.com/helixml/helix/api/pkg/openai"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+// maxSemanticEntries of the requests that only differ by their last message
+const maxSemanticEntries = 50
+
+// EmbedFunc returns the embedding of the text for the semantic cache
+type EmbedFunc func(ctx context.Context, text string) ([]float32, error)
+
+// Cache of the chat completion responses. The responses are cached by the
+// normalized request, the provider, the owner and the app, so the responses
+// are never shared between users.
+type Cache struct {
+	cfg *config.Cache
+
+	entries *lru.Cache[string, *entry]
+
+	// semantic entries are grouped by the key of the request without its
+	// last message, only the last message is compared by similarity
+	semanticMu sync.Mutex
+	semantic   *lru.Cache[string, []*semanticEntry]
+	embed      EmbedFunc
+
+	hits         atomic.Int64
+	semanticHits atomic.Int64
+	misses       atomic.Int64
+
+	now func() time.Time
+}
+
+type entry struct {
+	response openai.ChatCompletionResponse
+	expires  time.Time
+}
+
+type semanticEntry struct {
+	embedding []float32
+	key       string
+	expires   time.Time
+}
+
+// New returns the cache, embed is only used when the semantic cache is enabled
+func New(cfg *config.Cache, embed EmbedFunc) (*Cache, error) {
+	if cfg.MaxEntries <= 0 {
+		return nil, fmt.Errorf("max entries of the cache must be positive")
+	}
+
+	if cfg.SemanticEnabled && embed == nil {
+		return nil, fmt.Errorf("semantic cache requires an embeddings client")
+	}
+
+	entries, err := lru.New[string, *entry](cfg.MaxEntries)
+	if err != nil {
+		return nil, err
+	}
+
+	semantic, err := lru.New[string, []*semanticEntry](cfg.MaxEntries)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cache{
+		cfg:      cfg,
+		entries:  entries,
+		semantic: semantic,
+		embed:    embed,
+		now:      time.Now,
+	}, nil
+}
+
+// Wrap returns the client that caches the chat completions of the provider
+func (c *Cache) Wrap(provider types.Provider, client oai.Client) oai.Client {
+	return &CachingMiddleware{
+		cache:    c,
+		provider: provider,
+		client:   client,
+	}
+}
+
+func (c *Cache) Stats() *types.CacheStats {
+	stats := &types.CacheStats{
+		Entries:      c.entries.Len(),
+		Hits:         c.hits.Load(),
+		SemanticHits: c.semanticHits.Load(),
+		Misses:       c.misses.Load(),
+	}
+
+	if total := stats.Hits + stats.SemanticHits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits+stats.SemanticHits) / float64(total)
+	}
+
+	return stats
+}
+
+// ttl returns the TTL of the calls, zero if the cache is disabled for them
+func (c *Cache) ttl(ctx context.Context) time.Duration {
+	opts, ok := oai.GetCacheOptions(ctx)
+	if !ok {
+		return c.cfg.TTL
+	}
+	if opts.Disabled {
+		return 0
+	}
+	if opts.TTL > 0 {
+		return opts.TTL
+	}
+	return c.cfg.TTL
+}
+
+// lookupKey identifies the request in the cache
+type lookupKey struct {
+	exact string
+	// prefix and lastMessage are set when the request can be matched
+	// semantically, i.e. its last message is from the user
+	prefix      string
+	lastMessage string
+	embedding   []float32
+}
+
+func newLookupKey(ctx context.Context, provider types.Provider, req openai.ChatCompletionRequest) (*lookupKey, error) {
+	var owner, appID string
+	if vals, ok := oai.GetContextValues(ctx); ok {
+		owner = vals.OwnerID
+		appID = vals.AppID
+	}
+
+	req = normalizeRequest(req)
+
+	exact, err := hashRequest(provider, owner, appID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &lookupKey{exact: exact}
+
+	if len(req.Messages) > 0 {
+		last := req.Messages[len(req.Messages)-1]
+		if last.Role == openai.ChatMessageRoleUser && last.Content != "" {
+			messages := make([]openai.ChatCompletionMessage, len(req.Messages))
+			copy(messages, req.Messages)
+			messages[len(messages)-1].Content = ""
+			req.Messages = messages
+
+			key.prefix, err = hashRequest(provider, owner, appID, req)
+			if err != nil {
+				return nil, err
+			}
+			key.lastMessage = last.Content
+		}
+	}
+
+	return key, nil
+}
+
+// normalizeRequest drops the fields that don't change the response, streamed
+// and non-streamed requests share the cached responses
+func normalizeRequest(req openai.ChatCompletionRequest) openai.ChatCompletionRequest {
+	req.Stream = false
+	req.StreamOptions = nil
+	req.User = ""
+
+	messages := make([]openai.ChatCompletionMessage, len(req.Messages))
+	for i, m := range req.Messages {
+		m.Content = strings.TrimSpace(m.Content)
+		messages[i] = m
+	}
+	req.Messages = messages
+
+	return req
+}
+
+func hashRequest(provider types.Provider, owner, appID string, req openai.ChatCompletionRequest) (string, error) {
+	bts, err := json.Marshal(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00", provider, owner, appID)
+	h.Write(bts)
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (c *Cache) get(ctx context.Context, key *lookupKey) (openai.ChatCompletionResponse, bool) {
+	if resp, ok := c.getEntry(key.exact); ok {
+		c.hits.Add(1)
+		return resp, true
+	}
+
+	if c.cfg.SemanticEnabled && key.prefix != "" {
+		embedding, err := c.embed(ctx, key.lastMessage)
+		if err != nil {
+			log.Warn().Err(err).Msg("failed to embed the message for the semantic cache")
+		} else {
+			key.embedding = embedding
+
+			if resp, ok := c.getSemantic(key); ok {
+				c.semanticHits.Add(1)
+				return resp, true
+			}
+		}
+	}
+
+	c.misses.Add(1)
+
+	return openai.ChatCompletionResponse{}, false
+}
+
+func (c *Cache) getEntry(key string) (openai.ChatCompletionResponse, bool) {
+	e, ok := c.entries.Get(key)
+	if !ok {
+		return openai.ChatCompletionResponse{}, false
+	}
+
+	if c.now().After(e.expires) {
+		c.entries.Remove(key)
+		return openai.ChatCompletionResponse{}, false
+	}
+
+	return copyResponse(e.response), true
+}
+
+func (c *Cache) getSemantic(key *lookupKey) (openai.ChatCompletionResponse, bool) {
+	c.semanticMu.Lock()
+	candidates, _ := c.semantic.Get(key.prefix)
+	c.semanticMu.Unlock()
+
+	var (
+		best      *semanticEntry
+		bestScore float64
+	)
+
+	now := c.now()
+	for _, candidate := range candidates {
+		if now.After(candidate.expires) {
+			continue
+		}
+		score := cosineSimilarity(key.embedding, candidate.embedding)
+		if score >= c.cfg.SemanticThreshold && score > bestScore {
+			best, bestScore = candidate, score
+		}
+	}
+
+	if best == nil {
+		return openai.ChatCompletionResponse{}, false
+	}
+
+	return c.getEntry(best.key)
+}
+
+func (c *Cache) set(key *lookupKey, resp openai.ChatCompletionResponse, ttl time.Duration) {
+	expires := c.now().Add(ttl)
+
+	c.entries.Add(key.exact, &entry{
+		response: copyResponse(resp),
+		expires:  expires,
+	})
+
+	if key.embedding == nil {
+		return
+	}
+
+	c.semanticMu.Lock()
+	defer c.semanticMu.Unlock()
+
+	existing, _ := c.semantic.Get(key.prefix)
+
+	candidates := make([]*semanticEntry, 0, len(existing)+1)
+	for _, candidate := range existing {
+		if candidate.key != key.exact && c.now().Before(candidate.expires) {
+			candidates = append(candidates, candidate)
+		}
+	}
+	candidates = append(candidates, &semanticEntry{
+		embedding: key.embedding,
+		key:       key.exact,
+		expires:   expires,
+	})
+	if len(candidates) > maxSemanticEntries {
+		candidates = candidates[len(candidates)-maxSemanticEntries:]
+	}
+
+	c.semantic.Add(key.prefix, candidates)
+}
+
+func copyResponse(resp openai.ChatCompletionResponse) openai.ChatCompletionResponse {
+	choices := make([]openai.ChatCompletionChoice, len(resp.Choices))
+	copy(choices, resp.Choices)
+	resp.Choices = choices
+	return resp
+}
+
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,329 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/helixml/helix/api/pkg/config"
+	oai "github.com/helixml/helix/api/pkg/openai"
+	"github.com/helixml/helix/api/pkg/openai/transport"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func newTestCache(t *testing.T, cfg *config.Cache, embed EmbedFunc) *Cache {
+	if cfg.MaxEntries == 0 {
+		cfg.MaxEntries = 100
+	}
+	if cfg.TTL == 0 {
+		cfg.TTL = time.Hour
+	}
+
+	c, err := New(cfg, embed)
+	require.NoError(t, err)
+
+	return c
+}
+
+func chatRequest(content string) openai.ChatCompletionRequest {
+	return openai.ChatCompletionRequest{
+		Model: "llama3:instruct",
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: "You are a helpful assistant"},
+			{Role: openai.ChatMessageRoleUser, Content: content},
+		},
+	}
+}
+
+func chatResponse(content string) openai.ChatCompletionResponse {
+	return openai.ChatCompletionResponse{
+		ID:    "chatcmpl-1",
+		Model: "llama3:instruct",
+		Choices: []openai.ChatCompletionChoice{
+			{Message: openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: content}, FinishReason: openai.FinishReasonStop},
+		},
+		Usage: openai.Usage{PromptTokens: 10, CompletionTokens: 3, TotalTokens: 13},
+	}
+}
+
+func ownerContext(owner, appID string) context.Context {
+	return oai.SetContextValues(context.Background(), &oai.ContextValues{OwnerID: owner, AppID: appID})
+}
+
+func TestCachingMiddleware_ExactMatch(t *testing.T) {
+	client := oai.NewMockClient(gomock.NewController(t))
+	c := newTestCache(t, &config.Cache{}, nil)
+	cached := c.Wrap(types.ProviderHelix, client)
+
+	client.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).Return(chatResponse("Paris"), nil).Times(2)
+
+	ctx := ownerContext("user-1", "app-1")
+
+	resp, err := cached.CreateChatCompletion(ctx, chatRequest("What is the capital of France?"))
+	require.NoError(t, err)
+	assert.Equal(t, "Paris", resp.Choices[0].Message.Content)
+
+	// Whitespace and the stream flag don't change the key
+	req := chatRequest("  What is the capital of France?\n")
+	req.Stream = true
+	resp, err = cached.CreateChatCompletion(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, "Paris", resp.Choices[0].Message.Content)
+
+	// Other owners don't share the responses
+	_, err = cached.CreateChatCompletion(ownerContext("user-2", "app-1"), chatRequest("What is the capital of France?"))
+	require.NoError(t, err)
+
+	assert.Equal(t, &types.CacheStats{Entries: 2, Hits: 1, Misses: 2, HitRate: 1.0 / 3}, c.Stats())
+}
+
+func TestCachingMiddleware_TTL(t *testing.T) {
+	client := oai.NewMockClient(gomock.NewController(t))
+	c := newTestCache(t, &config.Cache{TTL: time.Minute}, nil)
+	cached := c.Wrap(types.ProviderHelix, client)
+
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	client.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).Return(chatResponse("Paris"), nil).Times(3)
+
+	ctx := ownerContext("user-1", "app-1")
+
+	_, err := cached.CreateChatCompletion(ctx, chatRequest("What is the capital of France?"))
+	require.NoError(t, err)
+
+	now = now.Add(2 * time.Minute)
+
+	_, err = cached.CreateChatCompletion(ctx, chatRequest("What is the capital of France?"))
+	require.NoError(t, err)
+
+	// The app opted out
+	ctx = oai.SetCacheOptions(ctx, &oai.CacheOptions{Disabled: true})
+	_, err = cached.CreateChatCompletion(ctx, chatRequest("What is the capital of France?"))
+	require.NoError(t, err)
+
+	// Errors are not cached
+	client.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).Return(openai.ChatCompletionResponse{}, errors.New("runner timeout"))
+	_, err = cached.CreateChatCompletion(ownerContext("user-1", "app-1"), chatRequest("What is the capital of Spain?"))
+	require.Error(t, err)
+
+	assert.Equal(t, 1, c.Stats().Entries)
+}
+
+func TestCachingMiddleware_Semantic(t *testing.T) {
+	embeddings := map[string][]float32{
+		"What is the capital of France?":     {1, 0, 0},
+		"What's the capital city of France?": {0.99, 0.1, 0},
+		"What is the capital of Spain?":      {0, 1, 0},
+	}
+
+	embed := func(_ context.Context, text string) ([]float32, error) {
+		return embeddings[text], nil
+	}
+
+	client := oai.NewMockClient(gomock.NewController(t))
+	c := newTestCache(t, &config.Cache{SemanticEnabled: true, SemanticThreshold: 0.95}, embed)
+	cached := c.Wrap(types.ProviderHelix, client)
+
+	ctx := ownerContext("user-1", "app-1")
+
+	client.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).Return(chatResponse("Paris"), nil)
+
+	_, err := cached.CreateChatCompletion(ctx, chatRequest("What is the capital of France?"))
+	require.NoError(t, err)
+
+	resp, err := cached.CreateChatCompletion(ctx, chatRequest("What's the capital city of France?"))
+	require.NoError(t, err)
+	assert.Equal(t, "Paris", resp.Choices[0].Message.Content)
+
+	client.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).Return(chatResponse("Madrid"), nil)
+
+	resp, err = cached.CreateChatCompletion(ctx, chatRequest("What is the capital of Spain?"))
+	require.NoError(t, err)
+	assert.Equal(t, "Madrid", resp.Choices[0].Message.Content)
+
+	stats := c.Stats()
+	assert.Equal(t, int64(1), stats.SemanticHits)
+	assert.Equal(t, int64(2), stats.Misses)
+}
+
+func TestCachingMiddleware_Stream(t *testing.T) {
+	client := oai.NewMockClient(gomock.NewController(t))
+	c := newTestCache(t, &config.Cache{}, nil)
+	cached := c.Wrap(types.ProviderHelix, client)
+
+	req := chatRequest("What is the capital of France?")
+	req.Stream = true
+
+	client.EXPECT().CreateChatCompletionStream(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, req openai.ChatCompletionRequest) (*openai.ChatCompletionStream, error) {
+			stream, writer, err := transport.NewOpenAIStreamingAdapter(req)
+			require.NoError(t, err)
+
+			go func() {
+				defer writer.Close()
+				for _, word := range []string{"The capital ", "is ", "Paris"} {
+					_ = transport.WriteChatCompletionStream(writer, &openai.ChatCompletionStreamResponse{
+						ID:      "chatcmpl-1",
+						Model:   "llama3:instruct",
+						Choices: []openai.ChatCompletionStreamChoice{{Delta: openai.ChatCompletionStreamChoiceDelta{Content: word}}},
+					})
+				}
+				_ = transport.WriteChatCompletionStream(writer, &openai.ChatCompletionStreamResponse{
+					ID:    "chatcmpl-1",
+					Usage: &openai.Usage{PromptTokens: 10, CompletionTokens: 3, TotalTokens: 13},
+				})
+			}()
+
+			return stream, nil
+		})
+
+	ctx := ownerContext("user-1", "app-1")
+
+	stream, err := cached.CreateChatCompletionStream(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, "The capital is Paris", readStream(t, stream))
+
+	// The response is cached once the stream completes
+	require.Eventually(t, func() bool { return c.Stats().Entries == 1 }, time.Second, 10*time.Millisecond)
+
+	// The replayed stream has no usage, the cache hits don't count against
+	// the token limits
+	stream, err = cached.CreateChatCompletionStream(ctx, req)
+	require.NoError(t, err)
+
+	var replayed openai.ChatCompletionResponse
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		assert.Nil(t, chunk.Usage)
+		transport.AppendChunk(&replayed, &chunk)
+	}
+	assert.Equal(t, "The capital is Paris", replayed.Choices[0].Message.Content)
+
+	// Non-streaming requests share the cached response
+	resp, err := cached.CreateChatCompletion(ctx, chatRequest("What is the capital of France?"))
+	require.NoError(t, err)
+	assert.Equal(t, "The capital is Paris", resp.Choices[0].Message.Content)
+}
+
+func TestCachingMiddleware_StreamToolCalls(t *testing.T) {
+	client := oai.NewMockClient(gomock.NewController(t))
+	c := newTestCache(t, &config.Cache{}, nil)
+	cached := c.Wrap(types.ProviderHelix, client)
+
+	req := chatRequest("What is the weather in Paris and London?")
+	req.Stream = true
+	req.N = 2
+
+	index := func(i int) *int { return &i }
+
+	chunks := []openai.ChatCompletionStreamResponse{
+		{Choices: []openai.ChatCompletionStreamChoice{
+			{Index: 0, Delta: openai.ChatCompletionStreamChoiceDelta{ToolCalls: []openai.ToolCall{
+				{Index: index(0), ID: "call_1", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "weather", Arguments: `{"city":`}},
+			}}},
+		}},
+		{Choices: []openai.ChatCompletionStreamChoice{
+			{Index: 1, Delta: openai.ChatCompletionStreamChoiceDelta{Content: "Let me check"}},
+			{Index: 0, Delta: openai.ChatCompletionStreamChoiceDelta{ToolCalls: []openai.ToolCall{
+				{Index: index(0), Function: openai.FunctionCall{Arguments: `"Paris"}`}},
+				{Index: index(1), ID: "call_2", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "weather", Arguments: `{"city":"London"}`}},
+			}}},
+		}},
+		{Choices: []openai.ChatCompletionStreamChoice{
+			{Index: 0, FinishReason: openai.FinishReasonToolCalls},
+			{Index: 1, FinishReason: openai.FinishReasonStop},
+		}},
+	}
+
+	client.EXPECT().CreateChatCompletionStream(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, req openai.ChatCompletionRequest) (*openai.ChatCompletionStream, error) {
+			stream, writer, err := transport.NewOpenAIStreamingAdapter(req)
+			require.NoError(t, err)
+
+			go func() {
+				defer writer.Close()
+				for i := range chunks {
+					_ = transport.WriteChatCompletionStream(writer, &chunks[i])
+				}
+			}()
+
+			return stream, nil
+		})
+
+	ctx := ownerContext("user-1", "app-1")
+
+	stream, err := cached.CreateChatCompletionStream(ctx, req)
+	require.NoError(t, err)
+	readStream(t, stream)
+
+	require.Eventually(t, func() bool { return c.Stats().Entries == 1 }, time.Second, 10*time.Millisecond)
+
+	nonStreamed := chatRequest("What is the weather in Paris and London?")
+	nonStreamed.N = 2
+
+	resp, err := cached.CreateChatCompletion(ctx, nonStreamed)
+	require.NoError(t, err)
+	require.Len(t, resp.Choices, 2)
+
+	toolCalls := resp.Choices[0].Message.ToolCalls
+	require.Len(t, toolCalls, 2)
+	assert.Equal(t, "call_1", toolCalls[0].ID)
+	assert.Equal(t, `{"city":"Paris"}`, toolCalls[0].Function.Arguments)
+	assert.Equal(t, "call_2", toolCalls[1].ID)
+	assert.Equal(t, `{"city":"London"}`, toolCalls[1].Function.Arguments)
+	assert.Equal(t, openai.FinishReasonToolCalls, resp.Choices[0].FinishReason)
+	assert.Equal(t, "Let me check", resp.Choices[1].Message.Content)
+
+	// The replayed stream has the same choices and tool calls
+	stream, err = cached.CreateChatCompletionStream(ctx, req)
+	require.NoError(t, err)
+
+	var replayed openai.ChatCompletionResponse
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		transport.AppendChunk(&replayed, &chunk)
+	}
+
+	require.Len(t, replayed.Choices, 2)
+	assert.Equal(t, resp.Choices[0].Message.ToolCalls[1].Function, replayed.Choices[0].Message.ToolCalls[1].Function)
+	assert.Equal(t, `{"city":"Paris"}`, replayed.Choices[0].Message.ToolCalls[0].Function.Arguments)
+	assert.Equal(t, openai.FinishReasonToolCalls, replayed.Choices[0].FinishReason)
+	assert.Equal(t, "Let me check", replayed.Choices[1].Message.Content)
+}
+
+func readStream(t *testing.T, stream *openai.ChatCompletionStream) string {
+	var sb strings.Builder
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		if len(chunk.Choices) > 0 {
+			sb.WriteString(chunk.Choices[0].Delta.Content)
+		}
+	}
+	return sb.String()
+}
+
+func TestNew_SemanticRequiresEmbeddings(t *testing.T) {
+	_, err := New(&config.Cache{MaxEntries: 10, SemanticEnabled: true}, nil)
+	assert.ErrorContains(t, err, "semantic cache requires an embeddings client")
+}
@@ -0,0 +1,192 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/helixml/helix/api/pkg/model"
+	oai "github.com/helixml/helix/api/pkg/openai"
+	"github.com/helixml/helix/api/pkg/openai/transport"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+var _ oai.Client = &CachingMiddleware{}
+
+// CachingMiddleware returns the cached responses of the chat completions,
+// cache hits never reach the provider so they are not logged as LLM calls
+type CachingMiddleware struct {
+	cache    *Cache
+	provider types.Provider
+	client   oai.Client
+}
+
+func (m *CachingMiddleware) ListModels(ctx context.Context) ([]model.OpenAIModel, error) {
+	return m.client.ListModels(ctx)
+}
+
+func (m *CachingMiddleware) CreateEmbeddings(ctx context.Context, request openai.EmbeddingRequest) (openai.EmbeddingResponse, error) {
+	return m.client.CreateEmbeddings(ctx, request)
+}
+
+func (m *CachingMiddleware) CreateChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	ttl := m.cache.ttl(ctx)
+	if ttl <= 0 {
+		return m.client.CreateChatCompletion(ctx, request)
+	}
+
+	key, err := newLookupKey(ctx, m.provider, request)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to get the cache key of the request")
+		return m.client.CreateChatCompletion(ctx, request)
+	}
+
+	if resp, ok := m.cache.get(ctx, key); ok {
+		return resp, nil
+	}
+
+	resp, err := m.client.CreateChatCompletion(ctx, request)
+	if err != nil {
+		return resp, err
+	}
+
+	m.cache.set(key, resp, ttl)
+
+	return resp, nil
+}
+
+// CreateChatCompletionStream replays the cached responses as a stream, the
+// streamed responses are cached once the stream completes
+func (m *CachingMiddleware) CreateChatCompletionStream(ctx context.Context, request openai.ChatCompletionRequest) (*openai.ChatCompletionStream, error) {
+	ttl := m.cache.ttl(ctx)
+	if ttl <= 0 {
+		return m.client.CreateChatCompletionStream(ctx, request)
+	}
+
+	key, err := newLookupKey(ctx, m.provider, request)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to get the cache key of the request")
+		return m.client.CreateChatCompletionStream(ctx, request)
+	}
+
+	if resp, ok := m.cache.get(ctx, key); ok {
+		return replayStream(request, &resp)
+	}
+
+	upstream, err := m.client.CreateChatCompletionStream(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	downstream, downstreamWriter, err := transport.NewOpenAIStreamingAdapter(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create streaming adapter: %w", err)
+	}
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error().Msgf("Recovered from panic: %v\n%s", r, debug.Stack())
+			}
+		}()
+
+		defer downstreamWriter.Close()
+
+		var resp openai.ChatCompletionResponse
+
+		for {
+			msg, err := upstream.Recv()
+			if err != nil {
+				if err == io.EOF {
+					break
+				}
+				// Incomplete responses are not cached
+				log.Error().Err(err).Msg("failed to receive message from upstream stream")
+				return
+			}
+
+			transport.AppendChunk(&resp, &msg)
+
+			err = transport.WriteChatCompletionStream(downstreamWriter, &msg)
+			if err != nil {
+				// The client is gone
+				return
+			}
+		}
+
+		m.cache.set(key, resp, ttl)
+	}()
+
+	return downstream, nil
+}
+
+// replayStream writes the content of every choice of the cached response word
+// by word, then its function and tool calls and the finish reason. The usage
+// is not sent, the cache hits don't use any tokens.
+func replayStream(request openai.ChatCompletionRequest, resp *openai.ChatCompletionResponse) (*openai.ChatCompletionStream, error) {
+	stream, writer, err := transport.NewOpenAIStreamingAdapter(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create streaming adapter: %w", err)
+	}
+
+	choices := resp.Choices
+	if len(choices) == 0 {
+		choices = []openai.ChatCompletionChoice{{}}
+	}
+
+	go func() {
+		defer writer.Close()
+
+		chunk := func(index int, delta openai.ChatCompletionStreamChoiceDelta, finishReason openai.FinishReason) *openai.ChatCompletionStreamResponse {
+			return &openai.ChatCompletionStreamResponse{
+				ID:      resp.ID,
+				Object:  "chat.completion.chunk",
+				Created: resp.Created,
+				Model:   resp.Model,
+				Choices: []openai.ChatCompletionStreamChoice{
+					{Index: index, Delta: delta, FinishReason: finishReason},
+				},
+			}
+		}
+
+		for _, choice := range choices {
+			message := choice.Message
+
+			for j, word := range strings.SplitAfter(message.Content, " ") {
+				delta := openai.ChatCompletionStreamChoiceDelta{Content: word}
+				if j == 0 {
+					delta.Role = openai.ChatMessageRoleAssistant
+				}
+				if err := transport.WriteChatCompletionStream(writer, chunk(choice.Index, delta, "")); err != nil {
+					return
+				}
+			}
+
+			for j, toolCall := range message.ToolCalls {
+				index := j
+				toolCall.Index = &index
+				delta := openai.ChatCompletionStreamChoiceDelta{ToolCalls: []openai.ToolCall{toolCall}}
+				if err := transport.WriteChatCompletionStream(writer, chunk(choice.Index, delta, "")); err != nil {
+					return
+				}
+			}
+
+			finishReason := choice.FinishReason
+			if finishReason == "" {
+				finishReason = openai.FinishReasonStop
+			}
+
+			last := chunk(choice.Index, openai.ChatCompletionStreamChoiceDelta{FunctionCall: message.FunctionCall}, finishReason)
+			if err := transport.WriteChatCompletionStream(writer, last); err != nil {
+				return
+			}
+		}
+	}()
+
+	return stream, nil
+}
@@ -2,13 +2,19 @@ package openai
 
 import (
 	"context"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
 
 	"github.com/helixml/helix/api/pkg/types"
 )
 
 const (
-	contextValuesKey = "contextValues"
-	stepKey          = "step"
+	contextValuesKey   = "contextValues"
+	stepKey            = "step"
+	routingDecisionKey = "routingDecision"
+	cacheOptionsKey    = "cacheOptions"
+	usageRecorderKey   = "usageRecorder"
 )
 
 type Step struct {
@@ -20,14 +26,33 @@ type ContextValues struct {
 	SessionID       string
 	InteractionID   string
 	OriginalRequest []byte
+	// PriorityClass is the scheduling priority class of the requests to the
+	// Helix runners
+	PriorityClass string
+	// OwnerType, AppID and APIKeyID attribute the calls in the usage ledger
+	OwnerType types.OwnerType
+	AppID     string
+	APIKeyID  string
 }
 
 func SetContextValues(ctx context.Context, vals *ContextValues) context.Context {
 	// Check if the context already has values, if it does,
-	// preserve the OriginalRequest
+	// preserve the OriginalRequest, the PriorityClass and the usage attribution
 	existingValues, ok := GetContextValues(ctx)
 	if ok {
 		vals.OriginalRequest = existingValues.OriginalRequest
+		if vals.PriorityClass == "" {
+			vals.PriorityClass = existingValues.PriorityClass
+		}
+		if vals.OwnerType == "" {
+			vals.OwnerType = existingValues.OwnerType
+		}
+		if vals.AppID == "" {
+			vals.AppID = existingValues.AppID
+		}
+		if vals.APIKeyID == "" {
+			vals.APIKeyID = existingValues.APIKeyID
+		}
 	}
 
 	return context.WithValue(ctx, contextValuesKey, vals)
@@ -62,3 +87,78 @@ func GetStep(ctx context.Context) (*Step, bool) {
 
 	return step, true
 }
+
+// RoutingDecision is how a routing policy picked the provider of the call
+type RoutingDecision struct {
+	// Route is the name of the routing policy
+	Route string
+	// Attempt is the position of the provider in the attempts, starting at 1
+	Attempt int
+	// Reason why the previous providers were skipped or failed, empty for
+	// the first choice
+	Reason string
+}
+
+func SetRoutingDecision(ctx context.Context, decision *RoutingDecision) context.Context {
+	return context.WithValue(ctx, routingDecisionKey, decision)
+}
+
+func GetRoutingDecision(ctx context.Context) (*RoutingDecision, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	decision, ok := ctx.Value(routingDecisionKey).(*RoutingDecision)
+	if !ok {
+		return nil, false
+	}
+
+	return decision, true
+}
+
+// CacheOptions of the response cache for the calls, e.g. the TTL of an app
+type CacheOptions struct {
+	// TTL of the cached responses, zero uses the server default
+	TTL time.Duration
+	// Disabled skips the cache
+	Disabled bool
+}
+
+func SetCacheOptions(ctx context.Context, opts *CacheOptions) context.Context {
+	return context.WithValue(ctx, cacheOptionsKey, opts)
+}
+
+func GetCacheOptions(ctx context.Context) (*CacheOptions, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	opts, ok := ctx.Value(cacheOptionsKey).(*CacheOptions)
+	if !ok {
+		return nil, false
+	}
+
+	return opts, true
+}
+
+// UsageRecorder is called with the usage of every LLM call made with the
+// context, streamed calls report it even when the client didn't ask for the
+// usage chunk
+type UsageRecorder func(usage openai.Usage)
+
+func SetUsageRecorder(ctx context.Context, recorder UsageRecorder) context.Context {
+	return context.WithValue(ctx, usageRecorderKey, recorder)
+}
+
+func GetUsageRecorder(ctx context.Context) (UsageRecorder, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	recorder, ok := ctx.Value(usageRecorderKey).(UsageRecorder)
+	if !ok {
+		return nil, false
+	}
+
+	return recorder, true
+}
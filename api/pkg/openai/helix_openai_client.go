@@ -19,6 +19,7 @@ import (
 type HelixClient interface {
 	CreateChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error)
 	CreateChatCompletionStream(ctx context.Context, request openai.ChatCompletionRequest) (*openai.ChatCompletionStream, error)
+	CreateEmbeddings(ctx context.Context, request openai.EmbeddingRequest) (openai.EmbeddingResponse, error)
 }
 
 var _ HelixClient = &InternalHelixServer{}
@@ -103,6 +104,7 @@ func (c *InternalHelixServer) CreateChatCompletion(ctx context.Context, request
 		OwnerID:       vals.OwnerID,
 		SessionID:     vals.SessionID,
 		InteractionID: vals.InteractionID,
+		PriorityClass: vals.PriorityClass,
 		Request:       &request,
 	})
 
@@ -110,10 +112,8 @@ func (c *InternalHelixServer) CreateChatCompletion(ctx context.Context, request
 	select {
 	case <-doneCh:
 	case <-ctx.Done():
-		err := c.scheduler.Release(requestID)
-		if err != nil {
-			log.Error().Err(err).Msg("error releasing allocation")
-		}
+		// Nobody reads the response any more, don't let a runner work on it
+		c.cancelRequest(requestID)
 		return openai.ChatCompletionResponse{}, fmt.Errorf("timeout waiting for runner response")
 	}
 
@@ -200,6 +200,7 @@ func (c *InternalHelixServer) CreateChatCompletionStream(ctx context.Context, re
 		OwnerID:       vals.OwnerID,
 		SessionID:     vals.SessionID,
 		InteractionID: vals.InteractionID,
+		PriorityClass: vals.PriorityClass,
 		Request:       &request,
 	})
 
@@ -207,7 +208,11 @@ func (c *InternalHelixServer) CreateChatCompletionStream(ctx context.Context, re
 		ctx, cancel := context.WithTimeout(ctx, chatCompletionTimeout)
 		defer cancel()
 
-		<-ctx.Done()
+		select {
+		case <-doneCh:
+		case <-ctx.Done():
+			c.cancelRequest(requestID)
+		}
 		_ = sub.Unsubscribe()
 	}()
 
@@ -215,6 +220,80 @@ func (c *InternalHelixServer) CreateChatCompletionStream(ctx context.Context, re
 	return client.CreateChatCompletionStream(ctx, request)
 }
 
+// CreateEmbeddings schedules the embedding request onto the runners the same
+// way as the chat completions and waits for the response
+func (c *InternalHelixServer) CreateEmbeddings(ctx context.Context, request openai.EmbeddingRequest) (openai.EmbeddingResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, chatCompletionTimeout)
+	defer cancel()
+
+	requestID := system.GenerateRequestID()
+
+	doneCh := make(chan struct{})
+
+	vals, ok := GetContextValues(ctx)
+	if !ok || vals.OwnerID == "" {
+		return openai.EmbeddingResponse{}, fmt.Errorf("ownerID not set in context, use 'openai.SetContextValues()' before calling this method")
+	}
+
+	var (
+		resp      openai.EmbeddingResponse
+		respError error
+	)
+
+	sub, err := c.pubsub.Subscribe(ctx, pubsub.GetRunnerResponsesQueue(vals.OwnerID, requestID), func(payload []byte) error {
+		var runnerResp types.RunnerLLMInferenceResponse
+		err := json.Unmarshal(payload, &runnerResp)
+		if err != nil {
+			return fmt.Errorf("error unmarshalling runner response: %w", err)
+		}
+
+		defer close(doneCh)
+
+		if runnerResp.EmbeddingResponse != nil {
+			resp = *runnerResp.EmbeddingResponse
+		}
+
+		if runnerResp.Error != "" {
+			respError = fmt.Errorf("runner error: %s", runnerResp.Error)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return openai.EmbeddingResponse{}, fmt.Errorf("failed to subscribe to runner responses: %w", err)
+	}
+
+	defer sub.Unsubscribe()
+
+	c.enqueueRequest(&types.RunnerLLMInferenceRequest{
+		RequestID:        requestID,
+		CreatedAt:        time.Now(),
+		OwnerID:          vals.OwnerID,
+		SessionID:        vals.SessionID,
+		InteractionID:    vals.InteractionID,
+		PriorityClass:    vals.PriorityClass,
+		EmbeddingRequest: &request,
+	})
+
+	select {
+	case <-doneCh:
+	case <-ctx.Done():
+		// Nobody reads the response any more, don't let a runner work on it
+		c.cancelRequest(requestID)
+		return openai.EmbeddingResponse{}, fmt.Errorf("timeout waiting for runner response")
+	}
+
+	if respError != nil {
+		err := c.scheduler.Release(requestID)
+		if err != nil {
+			log.Error().Err(err).Msg("error releasing allocation")
+		}
+		return openai.EmbeddingResponse{}, respError
+	}
+
+	return resp, nil
+}
+
 // NewOpenAIStreamingAdapter returns a new OpenAI streaming adapter which allows
 // to write into the io.Writer and read from the stream directly
 func NewOpenAIStreamingAdapter(req openai.ChatCompletionRequest) (*openai.ChatCompletionStream, *io.PipeWriter, error) {
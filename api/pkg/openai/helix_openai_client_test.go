@@ -273,6 +273,46 @@ func (suite *HelixClientTestSuite) Test_CreateChatCompletion_StreamingResponse()
 	suite.Equal("One,Two,Three.", resp)
 }
 
+func (suite *HelixClientTestSuite) Test_CreateEmbeddings_Response() {
+	var (
+		ownerID       = "owner1"
+		sessionID     = "session1"
+		interactionID = "interaction1"
+	)
+
+	// Fake running will pick up our request and send a response
+	go startFakeRunner(suite.T(), suite.srv, []*types.RunnerLLMInferenceResponse{
+		{
+			OwnerID:       ownerID,
+			SessionID:     sessionID,
+			InteractionID: interactionID,
+			EmbeddingResponse: &openai.EmbeddingResponse{
+				Data: []openai.Embedding{
+					{
+						Embedding: []float32{0.1, 0.2, 0.3},
+					},
+				},
+			},
+			Done: true,
+		},
+	})
+
+	ctx := SetContextValues(suite.ctx, &ContextValues{
+		OwnerID:       ownerID,
+		SessionID:     sessionID,
+		InteractionID: interactionID,
+	})
+
+	resp, err := suite.srv.CreateEmbeddings(ctx, openai.EmbeddingRequest{
+		Model: openai.EmbeddingModel(model.Model_Ollama_NomicEmbedText),
+		Input: "hello world",
+	})
+	suite.NoError(err)
+
+	suite.Require().Len(resp.Data, 1)
+	suite.Equal([]float32{0.1, 0.2, 0.3}, resp.Data[0].Embedding)
+}
+
 // startFakeRunner starts polling the queue for requests and sends responses. Exits once context
 // is done
 func startFakeRunner(t *testing.T, srv *InternalHelixServer, responses []*types.RunnerLLMInferenceResponse) {
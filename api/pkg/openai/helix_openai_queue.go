@@ -0,0 +1,195 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/helixml/helix/api/pkg/pubsub"
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+	"github.com/rs/zerolog/log"
+)
+
+// QueueStore persists the queue of LLM inference requests so that the pending
+// requests are not lost when the control plane restarts, implemented by the store
+type QueueStore interface {
+	ListLLMInferenceQueueItems(ctx context.Context) ([]*types.LLMInferenceQueueItem, error)
+	CreateLLMInferenceQueueItem(ctx context.Context, item *types.LLMInferenceQueueItem) error
+	DeleteLLMInferenceQueueItems(ctx context.Context, ids []string) error
+}
+
+// RestoredResponseFunc handles the response of a request restored after a
+// restart. The client that was waiting for it is gone, the response is written
+// to the session of the request instead.
+type RestoredResponseFunc func(ctx context.Context, req *types.RunnerLLMInferenceRequest, resp *types.RunnerLLMInferenceResponse)
+
+const queueStoreTimeout = 5 * time.Second
+
+// RestoreQueue re-enqueues the session requests that were waiting for a runner
+// before the restart and passes their responses to onResponse. The other
+// requests are dropped, nobody is waiting for their responses any more. From
+// now on the queue is persisted to the store.
+func (c *InternalHelixServer) RestoreQueue(ctx context.Context, store QueueStore, onResponse RestoredResponseFunc) error {
+	items, err := store.ListLLMInferenceQueueItems(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing queued requests: %w", err)
+	}
+
+	var (
+		restored []*types.RunnerLLMInferenceRequest
+		dropped  []string
+	)
+	for _, item := range items {
+		req := item.Request
+		if req.SessionID == "" || req.InteractionID == "" || req.Request == nil {
+			dropped = append(dropped, req.RequestID)
+			continue
+		}
+
+		// The response is written to the session at once
+		req.Request.Stream = false
+
+		err := c.consumeRestoredResponse(ctx, &req, onResponse)
+		if err != nil {
+			log.Error().Err(err).Str("id", req.RequestID).Msg("dropping restored request")
+			dropped = append(dropped, req.RequestID)
+			continue
+		}
+		restored = append(restored, &req)
+	}
+
+	c.queueMu.Lock()
+	defer c.queueMu.Unlock()
+
+	c.queueStore = store
+	c.queueWriter = system.NewSerialWorker()
+
+	for _, req := range restored {
+		if slices.ContainsFunc(c.queue, func(queued *types.RunnerLLMInferenceRequest) bool {
+			return queued.RequestID == req.RequestID
+		}) {
+			continue
+		}
+		c.queue = append(c.queue, req)
+	}
+	c.deleteQueuedRequests(dropped)
+
+	log.Info().
+		Int("num_requests", len(restored)).
+		Int("num_dropped", len(dropped)).
+		Msg("restored LLM inference queue")
+
+	return nil
+}
+
+// consumeRestoredResponse passes the response of the restored request to
+// onResponse. The request is cancelled if it doesn't get a response in time,
+// like the requests of the clients.
+func (c *InternalHelixServer) consumeRestoredResponse(ctx context.Context, req *types.RunnerLLMInferenceRequest, onResponse RestoredResponseFunc) error {
+	var once sync.Once
+	doneCh := make(chan struct{})
+	respond := func(resp *types.RunnerLLMInferenceResponse) {
+		once.Do(func() {
+			onResponse(ctx, req, resp)
+			close(doneCh)
+		})
+	}
+
+	sub, err := c.pubsub.Subscribe(ctx, pubsub.GetRunnerResponsesQueue(req.OwnerID, req.RequestID), func(payload []byte) error {
+		var resp types.RunnerLLMInferenceResponse
+		err := json.Unmarshal(payload, &resp)
+		if err != nil {
+			return fmt.Errorf("error unmarshalling runner response: %w", err)
+		}
+
+		respond(&resp)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to runner responses: %w", err)
+	}
+
+	go func() {
+		defer sub.Unsubscribe()
+
+		select {
+		case <-doneCh:
+		case <-ctx.Done():
+		case <-time.After(chatCompletionTimeout):
+			c.cancelRequest(req.RequestID)
+			respond(&types.RunnerLLMInferenceResponse{
+				RequestID:     req.RequestID,
+				OwnerID:       req.OwnerID,
+				SessionID:     req.SessionID,
+				InteractionID: req.InteractionID,
+				Error:         "timeout waiting for runner response",
+				Done:          true,
+			})
+		}
+	}()
+
+	return nil
+}
+
+// cancelRequest removes the request nobody waits for any more from the queue
+// and releases its slot, so that no runner picks it up
+func (c *InternalHelixServer) cancelRequest(requestID string) {
+	c.queueMu.Lock()
+	c.queue = slices.DeleteFunc(c.queue, func(req *types.RunnerLLMInferenceRequest) bool {
+		return req.RequestID == requestID
+	})
+	c.deleteQueuedRequests([]string{requestID})
+	c.queueMu.Unlock()
+
+	err := c.scheduler.Release(requestID)
+	if err != nil {
+		log.Error().Err(err).Msg("error releasing allocation")
+	}
+}
+
+// saveQueuedRequest persists the request in the background, errors are only
+// logged as the in-memory queue is the source of truth while the control
+// plane runs. Must be called with queueMu held to keep the writes in order.
+func (c *InternalHelixServer) saveQueuedRequest(req *types.RunnerLLMInferenceRequest) {
+	if c.queueStore == nil {
+		return
+	}
+
+	item := &types.LLMInferenceQueueItem{
+		ID:      req.RequestID,
+		Created: req.CreatedAt,
+		Request: *req,
+	}
+
+	c.queueWriter.Submit(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), queueStoreTimeout)
+		defer cancel()
+
+		err := c.queueStore.CreateLLMInferenceQueueItem(ctx, item)
+		if err != nil {
+			log.Error().Err(err).Str("id", item.ID).Msg("failed to save queued request")
+		}
+	})
+}
+
+// deleteQueuedRequests deletes the persisted requests in the background, must
+// be called with queueMu held
+func (c *InternalHelixServer) deleteQueuedRequests(ids []string) {
+	if c.queueStore == nil || len(ids) == 0 {
+		return
+	}
+
+	c.queueWriter.Submit(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), queueStoreTimeout)
+		defer cancel()
+
+		err := c.queueStore.DeleteLLMInferenceQueueItems(ctx, ids)
+		if err != nil {
+			log.Error().Err(err).Strs("ids", ids).Msg("failed to delete queued requests")
+		}
+	})
+}
@@ -0,0 +1,155 @@
+package openai
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/model"
+	"github.com/helixml/helix/api/pkg/pubsub"
+	"github.com/helixml/helix/api/pkg/scheduler"
+	"github.com/helixml/helix/api/pkg/types"
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryQueueStore struct {
+	mu    sync.Mutex
+	items map[string]*types.LLMInferenceQueueItem
+}
+
+func newMemoryQueueStore() *memoryQueueStore {
+	return &memoryQueueStore{items: make(map[string]*types.LLMInferenceQueueItem)}
+}
+
+func (m *memoryQueueStore) ListLLMInferenceQueueItems(_ context.Context) ([]*types.LLMInferenceQueueItem, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var items []*types.LLMInferenceQueueItem
+	for _, item := range m.items {
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (m *memoryQueueStore) CreateLLMInferenceQueueItem(_ context.Context, item *types.LLMInferenceQueueItem) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.items[item.ID] = item
+	return nil
+}
+
+func (m *memoryQueueStore) DeleteLLMInferenceQueueItems(_ context.Context, ids []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, id := range ids {
+		delete(m.items, id)
+	}
+	return nil
+}
+
+func newQueueTestServer(t *testing.T, runnerMemory uint64) *InternalHelixServer {
+	ps, err := pubsub.NewInMemoryNats(t.TempDir())
+	require.NoError(t, err)
+
+	cfg, _ := config.LoadServerConfig()
+	sched := scheduler.NewScheduler(&cfg)
+	if runnerMemory > 0 {
+		sched.UpdateRunner(&types.RunnerState{ID: runnerID, TotalMemory: runnerMemory})
+	}
+	return NewInternalHelixServer(&cfg, ps, sched)
+}
+
+func TestRestoreQueue(t *testing.T) {
+	store := newMemoryQueueStore()
+
+	srv := newQueueTestServer(t, 0)
+	require.NoError(t, srv.RestoreQueue(context.Background(), store, nil))
+
+	srv.enqueueRequest(&types.RunnerLLMInferenceRequest{
+		RequestID:     "req_1",
+		CreatedAt:     time.Now(),
+		OwnerID:       "owner1",
+		SessionID:     "ses_1",
+		InteractionID: "int_1",
+		Request: &openai.ChatCompletionRequest{
+			Model:  model.Model_Ollama_Llama3_8b,
+			Stream: true,
+		},
+	})
+	// Nobody waits for the response of a request without a session after
+	// the restart
+	srv.enqueueRequest(&types.RunnerLLMInferenceRequest{
+		RequestID: "req_2",
+		CreatedAt: time.Now(),
+		OwnerID:   "owner1",
+		Request: &openai.ChatCompletionRequest{
+			Model: model.Model_Ollama_Llama3_8b,
+		},
+	})
+	srv.queueWriter.Flush()
+	require.Len(t, store.items, 2)
+
+	// The control plane restarts before a runner picks up the requests
+	responses := make(chan *types.RunnerLLMInferenceResponse, 1)
+	restarted := newQueueTestServer(t, 9999999999)
+	require.NoError(t, restarted.RestoreQueue(context.Background(), store, func(_ context.Context, req *types.RunnerLLMInferenceRequest, resp *types.RunnerLLMInferenceResponse) {
+		assert.Equal(t, "int_1", req.InteractionID)
+		responses <- resp
+	}))
+	require.Equal(t, 1, restarted.QueueLength())
+
+	req, err := restarted.GetNextLLMInferenceRequest(context.Background(), types.InferenceRequestFilter{}, runnerID)
+	require.NoError(t, err)
+	require.NotNil(t, req)
+	assert.Equal(t, "req_1", req.RequestID)
+	assert.Equal(t, model.Model_Ollama_Llama3_8b, req.Request.Model)
+	// The response is written to the session at once
+	assert.False(t, req.Request.Stream)
+
+	assert.Equal(t, 0, restarted.QueueLength())
+	restarted.queueWriter.Flush()
+	assert.Empty(t, store.items)
+
+	err = restarted.ProcessRunnerResponse(context.Background(), &types.RunnerLLMInferenceResponse{
+		RequestID: "req_1",
+		OwnerID:   "owner1",
+		Response: &openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: "hi"}}},
+		},
+		Done: true,
+	})
+	require.NoError(t, err)
+
+	select {
+	case resp := <-responses:
+		assert.Equal(t, "hi", resp.Response.Choices[0].Message.Content)
+	case <-time.After(5 * time.Second):
+		t.Fatal("restored response was not handled")
+	}
+}
+
+func TestCreateChatCompletion_TimeoutRemovesRequest(t *testing.T) {
+	store := newMemoryQueueStore()
+
+	// No runners, the request waits in the queue until the client gives up
+	srv := newQueueTestServer(t, 0)
+	require.NoError(t, srv.RestoreQueue(context.Background(), store, nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	ctx = SetContextValues(ctx, &ContextValues{OwnerID: "owner1"})
+
+	_, err := srv.CreateChatCompletion(ctx, openai.ChatCompletionRequest{Model: model.Model_Ollama_Llama3_8b})
+	require.ErrorContains(t, err, "timeout waiting for runner response")
+
+	assert.Equal(t, 0, srv.QueueLength())
+	srv.queueWriter.Flush()
+	assert.Empty(t, store.items)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/helixml/helix/api/pkg/model"
 	"github.com/helixml/helix/api/pkg/pubsub"
 	"github.com/helixml/helix/api/pkg/scheduler"
+	"github.com/helixml/helix/api/pkg/system"
 	"github.com/helixml/helix/api/pkg/types"
 	"github.com/rs/zerolog/log"
 )
@@ -36,8 +38,10 @@ type InternalHelixServer struct {
 	pubsub pubsub.PubSub // Used to get responses from the runners
 	// controller Controller    // Used to create sessions
 
-	queueMu sync.Mutex
-	queue   []*types.RunnerLLMInferenceRequest
+	queueMu     sync.Mutex
+	queue       []*types.RunnerLLMInferenceRequest
+	queueStore  QueueStore           // Persists the queue if set, see RestoreQueue.
+	queueWriter *system.SerialWorker // Writes to the queue store outside of queueMu.
 
 	schedulingDecisionsMu sync.Mutex
 	schedulingDecisions   []*types.GlobalSchedulingDecision
@@ -63,15 +67,22 @@ func (c *InternalHelixServer) GetNextLLMInferenceRequest(ctx context.Context, fi
 	defer c.queueMu.Unlock()
 
 	// Doing all the scheduling work here to avoid making too many changes at once. Schedule any
-	// requests that are currently in the queue.
-	taken := 0
+	// requests that are currently in the queue, in the order that gives every owner a fair share.
+	var works []*scheduler.Workload
+	taken := make(map[string]bool)
 	for _, req := range c.queue {
 		work, err := scheduler.NewLLMWorkload(req)
 		if err != nil {
 			log.Warn().Err(err).Str("id", req.RequestID).Msg("creating workload")
+			c.FailRequest(req, fmt.Sprintf("error creating workload: %s", err))
+			taken[req.RequestID] = true
 			continue
 		}
-		err = c.scheduler.Schedule(work)
+		works = append(works, work)
+	}
+
+	for _, work := range c.scheduler.Prioritize(works) {
+		err := c.scheduler.Schedule(work)
 		if err != nil {
 			retry, err := scheduler.ErrorHandlingStrategy(err, work)
 
@@ -82,13 +93,20 @@ func (c *InternalHelixServer) GetNextLLMInferenceRequest(ctx context.Context, fi
 
 			// If we can't retry, write an error to the request and continue so it takes it off
 			// the queue
-			// TODO(Phil): Not sure how to write an error back as a response
 			log.Error().Err(err).Str("id", work.ID()).Msg("error scheduling")
+			c.FailRequest(work.LLMInferenceRequest(), fmt.Sprintf("error scheduling: %s", err))
 		}
-		taken++
+		taken[work.ID()] = true
 	}
 	// Clear processed queue
-	c.queue = c.queue[taken:]
+	c.queue = slices.DeleteFunc(c.queue, func(req *types.RunnerLLMInferenceRequest) bool {
+		return taken[req.RequestID]
+	})
+	takenIDs := make([]string, 0, len(taken))
+	for id := range taken {
+		takenIDs = append(takenIDs, id)
+	}
+	c.deleteQueuedRequests(takenIDs)
 
 	// Default to requesting warm work
 	newWorkOnly := false
@@ -105,7 +123,7 @@ func (c *InternalHelixServer) GetNextLLMInferenceRequest(ctx context.Context, fi
 	}
 
 	if req != nil {
-		c.addSchedulingDecision(filter, runnerID, runnerID, req.LLMInferenceRequest().SessionID, req.LLMInferenceRequest().InteractionID)
+		c.addSchedulingDecision(filter, runnerID, req)
 		log.Info().Str("runnerID", runnerID).Interface("filter", filter).Interface("req", req).Int("len(queue)", len(c.queue)).Msgf("🟠 helix_openai_server GetNextLLMInferenceRequest END")
 		return req.LLMInferenceRequest(), nil
 	}
@@ -113,11 +131,20 @@ func (c *InternalHelixServer) GetNextLLMInferenceRequest(ctx context.Context, fi
 
 }
 
+// QueueLength returns the number of requests waiting for a runner
+func (c *InternalHelixServer) QueueLength() int {
+	c.queueMu.Lock()
+	defer c.queueMu.Unlock()
+
+	return len(c.queue)
+}
+
 func (c *InternalHelixServer) enqueueRequest(req *types.RunnerLLMInferenceRequest) {
 	c.queueMu.Lock()
 	defer c.queueMu.Unlock()
 
 	c.queue = append(c.queue, req)
+	c.saveQueuedRequest(req)
 }
 
 // ProcessRunnerResponse is called on both partial streaming and full responses coming from the runner
@@ -143,6 +170,27 @@ func (c *InternalHelixServer) ProcessRunnerResponse(ctx context.Context, resp *t
 	return nil
 }
 
+// FailRequest sends an error response to the client waiting for the request
+func (c *InternalHelixServer) FailRequest(req *types.RunnerLLMInferenceRequest, reason string) {
+	bts, err := json.Marshal(&types.RunnerLLMInferenceResponse{
+		RequestID:     req.RequestID,
+		OwnerID:       req.OwnerID,
+		SessionID:     req.SessionID,
+		InteractionID: req.InteractionID,
+		Error:         reason,
+		Done:          true,
+	})
+	if err != nil {
+		log.Error().Err(err).Str("id", req.RequestID).Msg("error marshalling runner response")
+		return
+	}
+
+	err = c.pubsub.Publish(context.Background(), pubsub.GetRunnerResponsesQueue(req.OwnerID, req.RequestID), bts)
+	if err != nil {
+		log.Error().Err(err).Str("id", req.RequestID).Msg("error publishing runner response")
+	}
+}
+
 func (c *InternalHelixServer) GetSchedulingDecision() []*types.GlobalSchedulingDecision {
 	c.schedulingDecisionsMu.Lock()
 	defer c.schedulingDecisionsMu.Unlock()
@@ -154,19 +202,23 @@ func (c *InternalHelixServer) GetSchedulingDecision() []*types.GlobalSchedulingD
 	return queue
 }
 
-func (c *InternalHelixServer) addSchedulingDecision(filter types.InferenceRequestFilter, model, runnerID, sessionID, interactionID string) {
-
+func (c *InternalHelixServer) addSchedulingDecision(filter types.InferenceRequestFilter, runnerID string, work *scheduler.Workload) {
+	req := work.LLMInferenceRequest()
 	decision := &types.GlobalSchedulingDecision{
 		Created:       time.Now(),
 		RunnerID:      runnerID,
-		SessionID:     sessionID,
-		InteractionID: interactionID,
+		SessionID:     req.SessionID,
+		InteractionID: req.InteractionID,
 		Filter: types.SessionFilter{
 			Mode:  types.SessionModeInference,
 			Older: types.Duration(filter.Older),
 		},
-		ModelName: model,
-		Mode:      types.SessionModeInference,
+		ModelName:     work.ModelName().String(),
+		Mode:          types.SessionModeInference,
+		Owner:         req.OwnerID,
+		PriorityClass: work.Decision().PriorityClass,
+		Reason:        work.Decision().Reason,
+		Preempted:     work.Decision().Preempted,
 	}
 
 	c.schedulingDecisions = append([]*types.GlobalSchedulingDecision{decision}, c.schedulingDecisions...)
@@ -58,6 +58,8 @@ func (m *LoggingMiddleware) CreateChatCompletion(ctx context.Context, request op
 		return resp, err
 	}
 
+	recordUsage(ctx, resp.Usage)
+
 	m.wg.Add(1)
 	go func() {
 		defer func() {
@@ -75,7 +77,15 @@ func (m *LoggingMiddleware) CreateChatCompletion(ctx context.Context, request op
 }
 
 func (m *LoggingMiddleware) CreateChatCompletionStream(ctx context.Context, request openai.ChatCompletionRequest) (*openai.ChatCompletionStream, error) {
-	upstream, err := m.client.CreateChatCompletionStream(ctx, request)
+	// The usage of streamed calls is only sent when it's asked for, without it
+	// the calls would be recorded with no tokens and bypass the budgets. The
+	// usage chunk is only passed on to the callers that asked for it.
+	includeUsage := request.StreamOptions != nil && request.StreamOptions.IncludeUsage
+
+	upstreamRequest := request
+	upstreamRequest.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+
+	upstream, err := m.client.CreateChatCompletionStream(ctx, upstreamRequest)
 	if err != nil {
 		return nil, err
 	}
@@ -113,11 +123,19 @@ func (m *LoggingMiddleware) CreateChatCompletionStream(ctx context.Context, requ
 			}
 
 			// Add the message to the response
-			appendChunk(&resp, &msg)
+			transport.AppendChunk(&resp, &msg)
+
+			if !includeUsage && len(msg.Choices) == 0 && msg.Usage != nil {
+				continue
+			}
 
 			transport.WriteChatCompletionStream(downstreamWriter, &msg)
 		}
 
+		// Recorded before the downstream writer is closed so the usage is
+		// counted by the time the caller reads the end of the stream
+		recordUsage(ctx, resp.Usage)
+
 		// Once the stream is done, close the downstream writer
 		m.logLLMCall(ctx, &request, &resp, time.Since(start).Milliseconds())
 	}()
@@ -125,45 +143,20 @@ func (m *LoggingMiddleware) CreateChatCompletionStream(ctx context.Context, requ
 	return downstream, nil
 }
 
-func appendChunk(resp *openai.ChatCompletionResponse, chunk *openai.ChatCompletionStreamResponse) {
-	if chunk == nil {
-		return
-	}
-
-	if len(resp.Choices) == 0 {
-		resp.Choices = []openai.ChatCompletionChoice{
-			{
-				Message: openai.ChatCompletionMessage{},
-			},
-		}
-	}
-
-	if chunk.Model != "" {
-		resp.Model = chunk.Model
-	}
-
-	if chunk.ID != "" {
-		resp.ID = chunk.ID
-	}
-
-	if chunk.Created != 0 {
-		resp.Created = chunk.Created
-	}
-
-	// Append the chunk to the response
-	if len(chunk.Choices) > 0 {
-		resp.Choices[0].Message.Content += chunk.Choices[0].Delta.Content
+// CreateEmbeddings is not logged as an LLM call, the request and the vectors
+// would only bloat the table
+func (m *LoggingMiddleware) CreateEmbeddings(ctx context.Context, request openai.EmbeddingRequest) (openai.EmbeddingResponse, error) {
+	return m.client.CreateEmbeddings(ctx, request)
+}
 
-		if chunk.Choices[0].Delta.FunctionCall != nil {
-			resp.Choices[0].Message.FunctionCall = chunk.Choices[0].Delta.FunctionCall
-		}
+// recordUsage passes the usage of the call to the recorder of the context, if any
+func recordUsage(ctx context.Context, usage openai.Usage) {
+	recorder, ok := oai.GetUsageRecorder(ctx)
+	if !ok {
+		return
 	}
 
-	// Append the usage
-	// TODO: need to update the fork
-	// resp.Usage.PromptTokens += chunk.Usage.PromptTokens
-	// resp.Usage.CompletionTokens += chunk.Usage.CompletionTokens
-	// resp.Usage.TotalTokens += chunk.Usage.TotalTokens
+	recorder(usage)
 }
 
 func (m *LoggingMiddleware) logLLMCall(ctx context.Context, req *openai.ChatCompletionRequest, resp *openai.ChatCompletionResponse, durationMs int64) {
@@ -203,6 +196,12 @@ func (m *LoggingMiddleware) logLLMCall(ctx context.Context, req *openai.ChatComp
 		Int("total_tokens", resp.Usage.TotalTokens).
 		Msg("logging LLM call")
 
+	routing, ok := oai.GetRoutingDecision(ctx)
+	if !ok {
+		// Only set when the provider was picked by a routing policy
+		routing = &oai.RoutingDecision{}
+	}
+
 	llmCall := &types.LLMCall{
 		SessionID:        vals.SessionID,
 		InteractionID:    vals.InteractionID,
@@ -217,6 +216,12 @@ func (m *LoggingMiddleware) logLLMCall(ctx context.Context, req *openai.ChatComp
 		CompletionTokens: int64(resp.Usage.CompletionTokens),
 		TotalTokens:      int64(resp.Usage.TotalTokens),
 		UserID:           vals.OwnerID,
+		OwnerType:        vals.OwnerType,
+		AppID:            vals.AppID,
+		APIKeyID:         vals.APIKeyID,
+		Route:            routing.Route,
+		RouteAttempt:     routing.Attempt,
+		RouteReason:      routing.Reason,
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), logCallTimeout)
 	defer cancel()
@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/helixml/helix/api/pkg/config"
+	oai "github.com/helixml/helix/api/pkg/openai"
+	"github.com/helixml/helix/api/pkg/openai/transport"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func TestCreateChatCompletionStream_RecordsUsage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := oai.NewMockClient(ctrl)
+
+	client.EXPECT().CreateChatCompletionStream(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req openai.ChatCompletionRequest) (*openai.ChatCompletionStream, error) {
+			// The usage is asked for even though the caller didn't
+			require.NotNil(t, req.StreamOptions)
+			require.True(t, req.StreamOptions.IncludeUsage)
+
+			stream, writer, err := transport.NewOpenAIStreamingAdapter(req)
+			require.NoError(t, err)
+
+			go func() {
+				defer writer.Close()
+				_ = transport.WriteChatCompletionStream(writer, &openai.ChatCompletionStreamResponse{
+					Choices: []openai.ChatCompletionStreamChoice{
+						{Delta: openai.ChatCompletionStreamChoiceDelta{Content: "Hello"}},
+					},
+				})
+				_ = transport.WriteChatCompletionStream(writer, &openai.ChatCompletionStreamResponse{
+					Usage: &openai.Usage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5},
+				})
+			}()
+
+			return stream, nil
+		})
+
+	m := Wrap(&config.ServerConfig{}, types.ProviderOpenAI, client)
+
+	var recorded []openai.Usage
+	ctx := oai.SetUsageRecorder(context.Background(), func(usage openai.Usage) {
+		recorded = append(recorded, usage)
+	})
+
+	stream, err := m.CreateChatCompletionStream(ctx, openai.ChatCompletionRequest{
+		Model:  "model",
+		Stream: true,
+	})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	var chunks []openai.ChatCompletionStreamResponse
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		chunks = append(chunks, chunk)
+	}
+
+	// The usage chunk is not passed on but the usage is recorded by the time
+	// the stream ends
+	require.Len(t, chunks, 1)
+	assert.Nil(t, chunks[0].Usage)
+	assert.Equal(t, []openai.Usage{{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5}}, recorded)
+}
@@ -0,0 +1,69 @@
+package manager
+
+import (
+	"sync"
+	"time"
+)
+
+// circuitBreaker tracks the last calls of a target, the circuit opens when
+// too many of them failed and closes again after the cooldown with a fresh
+// window
+type circuitBreaker struct {
+	cfg *CircuitBreakerConfig
+
+	mu        sync.Mutex
+	failures  []bool
+	openUntil time.Time
+	now       func() time.Time
+}
+
+func newCircuitBreaker(cfg *CircuitBreakerConfig) *circuitBreaker {
+	return &circuitBreaker{
+		cfg: cfg,
+		now: time.Now,
+	}
+}
+
+// allow returns false while the circuit is open
+func (b *circuitBreaker) allow() bool {
+	if b.cfg == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return !b.now().Before(b.openUntil)
+}
+
+func (b *circuitBreaker) record(err error, latency time.Duration) {
+	if b.cfg == nil {
+		return
+	}
+
+	failed := err != nil || (b.cfg.MaxLatency > 0 && latency > b.cfg.MaxLatency)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = append(b.failures, failed)
+	if len(b.failures) > b.cfg.Window {
+		b.failures = b.failures[len(b.failures)-b.cfg.Window:]
+	}
+
+	if len(b.failures) < b.cfg.MinRequests {
+		return
+	}
+
+	var count int
+	for _, f := range b.failures {
+		if f {
+			count++
+		}
+	}
+
+	if float64(count)/float64(len(b.failures)) >= b.cfg.ErrorRate {
+		b.openUntil = b.now().Add(b.cfg.Cooldown)
+		b.failures = nil
+	}
+}
@@ -0,0 +1,124 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/helixml/helix/api/pkg/model"
+	helix_openai "github.com/helixml/helix/api/pkg/openai"
+	"github.com/helixml/helix/api/pkg/openai/logger"
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+type endpointClient struct {
+	updated time.Time
+	client  helix_openai.Client
+}
+
+// getEndpointClient returns the cached client of the endpoint, the client is
+// rebuilt when the endpoint was updated since it was created
+func (m *MultiClientManager) getEndpointClient(endpoint *types.ProviderEndpoint) (helix_openai.Client, error) {
+	m.endpointClientsMu.Lock()
+	defer m.endpointClientsMu.Unlock()
+
+	cached, ok := m.endpointClients[endpoint.Name]
+	if ok && cached.updated.Equal(endpoint.Updated) {
+		return cached.client, nil
+	}
+
+	client, err := m.newEndpointClient(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	m.endpointClients[endpoint.Name] = &endpointClient{
+		updated: endpoint.Updated,
+		client:  client,
+	}
+
+	return client, nil
+}
+
+func (m *MultiClientManager) newEndpointClient(endpoint *types.ProviderEndpoint) (helix_openai.Client, error) {
+	var apiKey string
+	if endpoint.APIKey != "" {
+		decrypted, err := system.Decrypt(m.cfg.Providers.EncryptionKey, endpoint.APIKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt API key of provider endpoint '%s': %w", endpoint.Name, err)
+		}
+		apiKey = decrypted
+	}
+
+	client := helix_openai.NewWithOptions(helix_openai.ClientOptions{
+		APIKey:     apiKey,
+		BaseURL:    endpoint.BaseURL,
+		Headers:    endpoint.Headers,
+		Azure:      endpoint.Type == types.ProviderEndpointTypeAzure,
+		APIVersion: endpoint.APIVersion,
+	})
+
+	loggedClient := logger.Wrap(m.cfg, types.Provider(endpoint.Name), client, m.logStores...)
+
+	return &allowlistClient{
+		endpoint: endpoint,
+		client:   loggedClient,
+	}, nil
+}
+
+// allowlistClient rejects the requests for models that are not in the
+// allowlist of the endpoint
+type allowlistClient struct {
+	endpoint *types.ProviderEndpoint
+	client   helix_openai.Client
+}
+
+func (c *allowlistClient) checkModel(model string) error {
+	if !c.endpoint.AllowsModel(model) {
+		return fmt.Errorf("model '%s' is not allowed for provider '%s'", model, c.endpoint.Name)
+	}
+	return nil
+}
+
+func (c *allowlistClient) CreateChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	if err := c.checkModel(request.Model); err != nil {
+		return openai.ChatCompletionResponse{}, err
+	}
+	return c.client.CreateChatCompletion(ctx, request)
+}
+
+func (c *allowlistClient) CreateChatCompletionStream(ctx context.Context, request openai.ChatCompletionRequest) (*openai.ChatCompletionStream, error) {
+	if err := c.checkModel(request.Model); err != nil {
+		return nil, err
+	}
+	return c.client.CreateChatCompletionStream(ctx, request)
+}
+
+func (c *allowlistClient) CreateEmbeddings(ctx context.Context, request openai.EmbeddingRequest) (openai.EmbeddingResponse, error) {
+	if err := c.checkModel(string(request.Model)); err != nil {
+		return openai.EmbeddingResponse{}, err
+	}
+	return c.client.CreateEmbeddings(ctx, request)
+}
+
+// ListModels returns the allowlist of the endpoint if it's set, otherwise
+// the models of the endpoint
+func (c *allowlistClient) ListModels(ctx context.Context) ([]model.OpenAIModel, error) {
+	if len(c.endpoint.Models) == 0 {
+		return c.client.ListModels(ctx)
+	}
+
+	models := make([]model.OpenAIModel, 0, len(c.endpoint.Models))
+	for _, m := range c.endpoint.Models {
+		models = append(models, model.OpenAIModel{
+			ID:      m,
+			Object:  "model",
+			OwnedBy: c.endpoint.Name,
+		})
+	}
+
+	return models, nil
+}
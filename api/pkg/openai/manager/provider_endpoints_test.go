@@ -0,0 +1,88 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func TestGetClient_ProviderEndpoint(t *testing.T) {
+	var gotAuth, gotHeader string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotHeader = r.Header.Get("X-Team")
+
+		_ = json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Model: "llama3",
+			Choices: []openai.ChatCompletionChoice{
+				{Message: openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "hi"}},
+			},
+		})
+	}))
+	defer srv.Close()
+
+	cfg := &config.ServerConfig{}
+	cfg.Providers.EncryptionKey = "secret"
+
+	encryptedKey, err := system.Encrypt(cfg.Providers.EncryptionKey, "sk-vllm")
+	require.NoError(t, err)
+
+	endpoint := &types.ProviderEndpoint{
+		ID:      "pe_1",
+		Updated: time.Now(),
+		Name:    "vllm",
+		Type:    types.ProviderEndpointTypeOpenAI,
+		BaseURL: srv.URL,
+		APIKey:  encryptedKey,
+		Models:  types.StringList{"llama3"},
+		Headers: types.StringMap{"X-Team": "research"},
+	}
+
+	storeMock := store.NewMockStore(gomock.NewController(t))
+	storeMock.EXPECT().GetProviderEndpoint(gomock.Any(), &store.GetProviderEndpointQuery{Name: "vllm"}).Return(endpoint, nil).Times(2)
+	storeMock.EXPECT().GetProviderEndpoint(gomock.Any(), &store.GetProviderEndpointQuery{Name: "unknown"}).Return(nil, store.ErrNotFound)
+
+	m := NewProviderManager(cfg, storeMock, nil)
+
+	client, err := m.GetClient(context.Background(), &GetClientRequest{Provider: "vllm"})
+	require.NoError(t, err)
+
+	resp, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model:    "llama3",
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "hello"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "hi", resp.Choices[0].Message.Content)
+	assert.Equal(t, "Bearer sk-vllm", gotAuth)
+	assert.Equal(t, "research", gotHeader)
+
+	_, err = client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: "gpt-4o"})
+	assert.ErrorContains(t, err, "model 'gpt-4o' is not allowed for provider 'vllm'")
+
+	models, err := client.ListModels(context.Background())
+	require.NoError(t, err)
+	require.Len(t, models, 1)
+	assert.Equal(t, "llama3", models[0].ID)
+
+	// Client is cached until the endpoint is updated
+	cached, err := m.GetClient(context.Background(), &GetClientRequest{Provider: "vllm"})
+	require.NoError(t, err)
+	assert.Same(t, client, cached)
+
+	_, err = m.GetClient(context.Background(), &GetClientRequest{Provider: "unknown"})
+	assert.ErrorContains(t, err, "no client found for provider: unknown")
+}
@@ -2,6 +2,7 @@ package manager
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 
@@ -9,7 +10,9 @@ import (
 
 	"github.com/helixml/helix/api/pkg/config"
 	"github.com/helixml/helix/api/pkg/openai"
+	"github.com/helixml/helix/api/pkg/openai/cache"
 	"github.com/helixml/helix/api/pkg/openai/logger"
+	"github.com/helixml/helix/api/pkg/store"
 	"github.com/helixml/helix/api/pkg/types"
 )
 
@@ -32,11 +35,33 @@ type providerClient struct {
 }
 
 type MultiClientManager struct {
+	cfg       *config.ServerConfig
+	store     store.Store
+	logStores []logger.LogStore
+
 	clients   map[types.Provider]*providerClient
 	clientsMu *sync.RWMutex
+
+	// endpointClients cache the clients of the provider endpoints
+	// registered at runtime, keyed by the endpoint name
+	endpointClients   map[string]*endpointClient
+	endpointClientsMu *sync.Mutex
+
+	// routers of the routing policies, keyed by the policy name
+	routers   map[types.Provider]*Router
+	routersMu *sync.RWMutex
+
+	helixQueueLength func() int
+
+	// cache of the chat completions, nil when disabled
+	cache *cache.Cache
 }
 
-func NewProviderManager(cfg *config.ServerConfig, helixInference openai.Client, logStores ...logger.LogStore) *MultiClientManager {
+type queueLengther interface {
+	QueueLength() int
+}
+
+func NewProviderManager(cfg *config.ServerConfig, store store.Store, helixInference openai.Client, logStores ...logger.LogStore) *MultiClientManager {
 	clients := make(map[types.Provider]*providerClient)
 
 	if cfg.Providers.OpenAI.APIKey != "" {
@@ -73,10 +98,55 @@ func NewProviderManager(cfg *config.ServerConfig, helixInference openai.Client,
 
 	clients[types.ProviderHelix] = &providerClient{client: loggedClient}
 
-	return &MultiClientManager{
-		clients:   clients,
-		clientsMu: &sync.RWMutex{},
+	m := &MultiClientManager{
+		cfg:               cfg,
+		store:             store,
+		logStores:         logStores,
+		clients:           clients,
+		clientsMu:         &sync.RWMutex{},
+		endpointClients:   make(map[string]*endpointClient),
+		endpointClientsMu: &sync.Mutex{},
+		routers:           make(map[types.Provider]*Router),
+		routersMu:         &sync.RWMutex{},
+	}
+
+	if q, ok := helixInference.(queueLengther); ok {
+		m.helixQueueLength = q.QueueLength
+	}
+
+	return m
+}
+
+// SetRoutingPolicies replaces the routing policies, the targets of a policy
+// can be any provider except another policy
+func (m *MultiClientManager) SetRoutingPolicies(policies []*RoutingPolicy) error {
+	routers := make(map[types.Provider]*Router, len(policies))
+
+	for _, policy := range policies {
+		err := validateRoutingPolicy(policy)
+		if err != nil {
+			return err
+		}
+		if _, ok := routers[types.Provider(policy.Name)]; ok {
+			return fmt.Errorf("duplicate routing policy '%s'", policy.Name)
+		}
+		routers[types.Provider(policy.Name)] = NewRouter(policy, m.getProviderClient, m.helixQueueLength)
+	}
+
+	for _, policy := range policies {
+		for _, target := range policy.Targets {
+			if _, ok := routers[target.Provider]; ok {
+				return fmt.Errorf("routing policy '%s' targets the routing policy '%s'", policy.Name, target.Provider)
+			}
+		}
 	}
+
+	m.routersMu.Lock()
+	defer m.routersMu.Unlock()
+
+	m.routers = routers
+
+	return nil
 }
 
 func (m *MultiClientManager) ListProviders(ctx context.Context) ([]types.Provider, error) {
@@ -88,17 +158,75 @@ func (m *MultiClientManager) ListProviders(ctx context.Context) ([]types.Provide
 		providers = append(providers, provider)
 	}
 
+	m.routersMu.RLock()
+	for provider := range m.routers {
+		providers = append(providers, provider)
+	}
+	m.routersMu.RUnlock()
+
+	endpoints, err := m.store.ListProviderEndpoints(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list provider endpoints: %w", err)
+	}
+
+	for _, endpoint := range endpoints {
+		providers = append(providers, types.Provider(endpoint.Name))
+	}
+
 	return providers, nil
 }
 
+// SetCache caches the chat completions of all the clients, the calls of the
+// routing policies are cached by the policy name
+func (m *MultiClientManager) SetCache(c *cache.Cache) {
+	m.cache = c
+}
+
 func (m *MultiClientManager) GetClient(ctx context.Context, req *GetClientRequest) (openai.Client, error) {
+	m.routersMu.RLock()
+	router, ok := m.routers[req.Provider]
+	m.routersMu.RUnlock()
+
+	var (
+		client openai.Client = router
+		err    error
+	)
+	if !ok {
+		client, err = m.getProviderClient(ctx, req.Provider)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if m.cache != nil {
+		return m.cache.Wrap(req.Provider, client), nil
+	}
+
+	return client, nil
+}
+
+// getProviderClient returns the client of a configured provider or of a
+// provider endpoint registered at runtime
+func (m *MultiClientManager) getProviderClient(ctx context.Context, provider types.Provider) (openai.Client, error) {
 	m.clientsMu.RLock()
-	defer m.clientsMu.RUnlock()
+	client, ok := m.clients[provider]
+	m.clientsMu.RUnlock()
 
-	client, ok := m.clients[req.Provider]
-	if !ok {
-		return nil, fmt.Errorf("no client found for provider: %s", req.Provider)
+	if ok {
+		return client.client, nil
+	}
+
+	if provider.IsBuiltin() || provider == "" {
+		return nil, fmt.Errorf("no client found for provider: %s", provider)
+	}
+
+	endpoint, err := m.store.GetProviderEndpoint(ctx, &store.GetProviderEndpointQuery{Name: string(provider)})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, fmt.Errorf("no client found for provider: %s", provider)
+		}
+		return nil, fmt.Errorf("failed to get provider endpoint '%s': %w", provider, err)
 	}
 
-	return client.client, nil
+	return m.getEndpointClient(endpoint)
 }
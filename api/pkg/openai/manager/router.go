@@ -0,0 +1,242 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/helixml/helix/api/pkg/model"
+	helix_openai "github.com/helixml/helix/api/pkg/openai"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+var _ helix_openai.Client = &Router{}
+
+// Router is the client of a routing policy, it sends the calls to the
+// targets of the policy and falls back to the next target when a call fails
+type Router struct {
+	policy    *RoutingPolicy
+	getClient func(ctx context.Context, provider types.Provider) (helix_openai.Client, error)
+	// queueLength of the Helix runners, nil if not known
+	queueLength func() int
+
+	breakers []*circuitBreaker
+
+	mu      sync.Mutex
+	current []int // smooth weighted round-robin state
+}
+
+func NewRouter(policy *RoutingPolicy, getClient func(ctx context.Context, provider types.Provider) (helix_openai.Client, error), queueLength func() int) *Router {
+	breakers := make([]*circuitBreaker, len(policy.Targets))
+	for i := range breakers {
+		breakers[i] = newCircuitBreaker(policy.CircuitBreaker)
+	}
+
+	return &Router{
+		policy:      policy,
+		getClient:   getClient,
+		queueLength: queueLength,
+		breakers:    breakers,
+		current:     make([]int, len(policy.Targets)),
+	}
+}
+
+func (r *Router) CreateChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	var resp openai.ChatCompletionResponse
+
+	err := r.route(ctx, request.Model, func(ctx context.Context, client helix_openai.Client, model string) error {
+		req := request
+		req.Model = model
+
+		var err error
+		resp, err = client.CreateChatCompletion(ctx, req)
+		return err
+	})
+
+	return resp, err
+}
+
+// CreateChatCompletionStream falls back only when the stream can't be
+// opened, the latency of the target is the time to open the stream
+func (r *Router) CreateChatCompletionStream(ctx context.Context, request openai.ChatCompletionRequest) (*openai.ChatCompletionStream, error) {
+	var stream *openai.ChatCompletionStream
+
+	err := r.route(ctx, request.Model, func(ctx context.Context, client helix_openai.Client, model string) error {
+		req := request
+		req.Model = model
+
+		var err error
+		stream, err = client.CreateChatCompletionStream(ctx, req)
+		return err
+	})
+
+	return stream, err
+}
+
+func (r *Router) CreateEmbeddings(ctx context.Context, request openai.EmbeddingRequest) (openai.EmbeddingResponse, error) {
+	var resp openai.EmbeddingResponse
+
+	err := r.route(ctx, string(request.Model), func(ctx context.Context, client helix_openai.Client, model string) error {
+		req := request
+		req.Model = openai.EmbeddingModel(model)
+
+		var err error
+		resp, err = client.CreateEmbeddings(ctx, req)
+		return err
+	})
+
+	return resp, err
+}
+
+// ListModels returns the models of the targets, or the models of the first
+// target if the targets don't set them
+func (r *Router) ListModels(ctx context.Context) ([]model.OpenAIModel, error) {
+	var models []model.OpenAIModel
+	seen := make(map[string]bool)
+
+	for _, target := range r.policy.Targets {
+		if target.Model == "" || seen[target.Model] {
+			continue
+		}
+		seen[target.Model] = true
+
+		models = append(models, model.OpenAIModel{
+			ID:      target.Model,
+			Object:  "model",
+			OwnedBy: string(target.Provider),
+		})
+	}
+
+	if len(models) > 0 {
+		return models, nil
+	}
+
+	client, err := r.getClient(ctx, r.policy.Targets[0].Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.ListModels(ctx)
+}
+
+// route calls the targets in the order of the policy until one succeeds
+func (r *Router) route(ctx context.Context, requestModel string, call func(ctx context.Context, client helix_openai.Client, model string) error) error {
+	var (
+		reasons  []string
+		attempts int
+		lastErr  error
+	)
+
+	for _, i := range r.order() {
+		target := r.policy.Targets[i]
+
+		if !r.breakers[i].allow() {
+			reasons = append(reasons, fmt.Sprintf("%s: circuit open", target.Provider))
+			continue
+		}
+
+		if target.MaxQueueLength > 0 && target.Provider == types.ProviderHelix && r.queueLength != nil {
+			if queueLength := r.queueLength(); queueLength >= target.MaxQueueLength {
+				reasons = append(reasons, fmt.Sprintf("%s: queue length %d", target.Provider, queueLength))
+				continue
+			}
+		}
+
+		client, err := r.getClient(ctx, target.Provider)
+		if err != nil {
+			reasons = append(reasons, fmt.Sprintf("%s: %s", target.Provider, err))
+			lastErr = err
+			continue
+		}
+
+		model := requestModel
+		if target.Model != "" {
+			model = target.Model
+		}
+
+		attempts++
+
+		callCtx := helix_openai.SetRoutingDecision(ctx, &helix_openai.RoutingDecision{
+			Route:   r.policy.Name,
+			Attempt: attempts,
+			Reason:  strings.Join(reasons, "; "),
+		})
+
+		start := time.Now()
+		err = call(callCtx, client, model)
+
+		// The caller is gone, the next target would fail too. The provider
+		// didn't fail so it doesn't count against its circuit.
+		if err != nil && ctx.Err() != nil {
+			return err
+		}
+
+		r.breakers[i].record(err, time.Since(start))
+		if err == nil {
+			return nil
+		}
+
+		log.Warn().
+			Err(err).
+			Str("route", r.policy.Name).
+			Str("provider", string(target.Provider)).
+			Str("model", model).
+			Msg("routed call failed, falling back to the next provider")
+
+		reasons = append(reasons, fmt.Sprintf("%s: %s", target.Provider, err))
+		lastErr = err
+	}
+
+	if lastErr != nil {
+		return fmt.Errorf("all providers of route '%s' failed: %w", r.policy.Name, lastErr)
+	}
+
+	return fmt.Errorf("no provider available for route '%s': %s", r.policy.Name, strings.Join(reasons, "; "))
+}
+
+// order returns the indexes of the targets in the order to try them, the
+// weighted strategy picks the first target by smooth weighted round-robin
+// among the targets with a closed circuit
+func (r *Router) order() []int {
+	order := make([]int, 0, len(r.policy.Targets))
+
+	if r.policy.Strategy != RoutingStrategyWeighted {
+		for i := range r.policy.Targets {
+			order = append(order, i)
+		}
+		return order
+	}
+
+	r.mu.Lock()
+	best, total := -1, 0
+	for i, target := range r.policy.Targets {
+		if !r.breakers[i].allow() {
+			continue
+		}
+		r.current[i] += target.Weight
+		total += target.Weight
+		if best == -1 || r.current[i] > r.current[best] {
+			best = i
+		}
+	}
+	if best != -1 {
+		r.current[best] -= total
+	}
+	r.mu.Unlock()
+
+	if best != -1 {
+		order = append(order, best)
+	}
+	for i := range r.policy.Targets {
+		if i != best {
+			order = append(order, i)
+		}
+	}
+
+	return order
+}
@@ -0,0 +1,311 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/helixml/helix/api/pkg/config"
+	helix_openai "github.com/helixml/helix/api/pkg/openai"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func newTestRouter(t *testing.T, policy *RoutingPolicy, clients map[types.Provider]helix_openai.Client, queueLength func() int) *Router {
+	require.NoError(t, validateRoutingPolicy(policy))
+
+	return NewRouter(policy, func(_ context.Context, provider types.Provider) (helix_openai.Client, error) {
+		client, ok := clients[provider]
+		if !ok {
+			return nil, fmt.Errorf("no client found for provider: %s", provider)
+		}
+		return client, nil
+	}, queueLength)
+}
+
+func TestRouter_Fallback(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	helix := helix_openai.NewMockClient(ctrl)
+	together := helix_openai.NewMockClient(ctrl)
+
+	router := newTestRouter(t, &RoutingPolicy{
+		Name: "llama3",
+		Targets: []RoutingTarget{
+			{Provider: types.ProviderHelix, Model: "llama3:instruct"},
+			{Provider: types.ProviderTogetherAI, Model: "meta-llama/Llama-3-8b-chat-hf"},
+		},
+	}, map[types.Provider]helix_openai.Client{
+		types.ProviderHelix:      helix,
+		types.ProviderTogetherAI: together,
+	}, nil)
+
+	helix.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+			assert.Equal(t, "llama3:instruct", req.Model)
+
+			decision, ok := helix_openai.GetRoutingDecision(ctx)
+			require.True(t, ok)
+			assert.Equal(t, &helix_openai.RoutingDecision{Route: "llama3", Attempt: 1}, decision)
+
+			return openai.ChatCompletionResponse{}, errors.New("runner timeout")
+		})
+
+	together.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+			assert.Equal(t, "meta-llama/Llama-3-8b-chat-hf", req.Model)
+
+			decision, ok := helix_openai.GetRoutingDecision(ctx)
+			require.True(t, ok)
+			assert.Equal(t, &helix_openai.RoutingDecision{Route: "llama3", Attempt: 2, Reason: "helix: runner timeout"}, decision)
+
+			return openai.ChatCompletionResponse{ID: "together"}, nil
+		})
+
+	resp, err := router.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: "llama3"})
+	require.NoError(t, err)
+	assert.Equal(t, "together", resp.ID)
+}
+
+func TestRouter_AllFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	helix := helix_openai.NewMockClient(ctrl)
+
+	router := newTestRouter(t, &RoutingPolicy{
+		Name: "llama3",
+		Targets: []RoutingTarget{
+			{Provider: types.ProviderHelix},
+			{Provider: types.ProviderTogetherAI},
+		},
+	}, map[types.Provider]helix_openai.Client{
+		types.ProviderHelix: helix,
+	}, nil)
+
+	helix.EXPECT().CreateChatCompletionStream(gomock.Any(), gomock.Any()).Return(nil, errors.New("runner timeout"))
+
+	_, err := router.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{Model: "llama3"})
+	assert.ErrorContains(t, err, "all providers of route 'llama3' failed: no client found for provider: togetherai")
+}
+
+func TestRouter_QueueLength(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	helix := helix_openai.NewMockClient(ctrl)
+	together := helix_openai.NewMockClient(ctrl)
+
+	queueLength := 25
+
+	router := newTestRouter(t, &RoutingPolicy{
+		Name: "llama3",
+		Targets: []RoutingTarget{
+			{Provider: types.ProviderHelix, MaxQueueLength: 20},
+			{Provider: types.ProviderTogetherAI},
+		},
+	}, map[types.Provider]helix_openai.Client{
+		types.ProviderHelix:      helix,
+		types.ProviderTogetherAI: together,
+	}, func() int { return queueLength })
+
+	together.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+			decision, _ := helix_openai.GetRoutingDecision(ctx)
+			assert.Equal(t, &helix_openai.RoutingDecision{Route: "llama3", Attempt: 1, Reason: "helix: queue length 25"}, decision)
+			return openai.ChatCompletionResponse{}, nil
+		})
+
+	_, err := router.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: "llama3"})
+	require.NoError(t, err)
+
+	queueLength = 5
+
+	helix.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).Return(openai.ChatCompletionResponse{}, nil)
+
+	_, err = router.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: "llama3"})
+	require.NoError(t, err)
+}
+
+func TestRouter_Weighted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	helix := helix_openai.NewMockClient(ctrl)
+	together := helix_openai.NewMockClient(ctrl)
+
+	router := newTestRouter(t, &RoutingPolicy{
+		Name:     "llama3",
+		Strategy: RoutingStrategyWeighted,
+		Targets: []RoutingTarget{
+			{Provider: types.ProviderHelix, Weight: 3},
+			{Provider: types.ProviderTogetherAI, Weight: 1},
+		},
+	}, map[types.Provider]helix_openai.Client{
+		types.ProviderHelix:      helix,
+		types.ProviderTogetherAI: together,
+	}, nil)
+
+	helix.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).Return(openai.ChatCompletionResponse{}, nil).Times(6)
+	together.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).Return(openai.ChatCompletionResponse{}, nil).Times(2)
+
+	for i := 0; i < 8; i++ {
+		_, err := router.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: "llama3"})
+		require.NoError(t, err)
+	}
+}
+
+func TestRouter_CircuitBreaker(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	helix := helix_openai.NewMockClient(ctrl)
+	together := helix_openai.NewMockClient(ctrl)
+
+	router := newTestRouter(t, &RoutingPolicy{
+		Name: "llama3",
+		Targets: []RoutingTarget{
+			{Provider: types.ProviderHelix},
+			{Provider: types.ProviderTogetherAI},
+		},
+		CircuitBreaker: &CircuitBreakerConfig{
+			MinRequests: 2,
+			Cooldown:    time.Minute,
+		},
+	}, map[types.Provider]helix_openai.Client{
+		types.ProviderHelix:      helix,
+		types.ProviderTogetherAI: together,
+	}, nil)
+
+	now := time.Now()
+	for _, b := range router.breakers {
+		b.now = func() time.Time { return now }
+	}
+
+	// Two failures open the circuit of helix
+	helix.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).Return(openai.ChatCompletionResponse{}, errors.New("runner timeout")).Times(2)
+	together.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).Return(openai.ChatCompletionResponse{}, nil).Times(3)
+
+	for i := 0; i < 3; i++ {
+		_, err := router.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: "llama3"})
+		require.NoError(t, err)
+	}
+
+	// Closes after the cooldown
+	now = now.Add(time.Minute)
+
+	helix.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).Return(openai.ChatCompletionResponse{}, nil)
+
+	_, err := router.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: "llama3"})
+	require.NoError(t, err)
+}
+
+func TestRouter_CallerCanceled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	helix := helix_openai.NewMockClient(ctrl)
+	together := helix_openai.NewMockClient(ctrl)
+
+	router := newTestRouter(t, &RoutingPolicy{
+		Name: "llama3",
+		Targets: []RoutingTarget{
+			{Provider: types.ProviderHelix},
+			{Provider: types.ProviderTogetherAI},
+		},
+		CircuitBreaker: &CircuitBreakerConfig{
+			MinRequests: 1,
+			Cooldown:    time.Minute,
+		},
+	}, map[types.Provider]helix_openai.Client{
+		types.ProviderHelix:      helix,
+		types.ProviderTogetherAI: together,
+	}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// The caller goes away mid-request, the fallback isn't tried
+	helix.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+			cancel()
+			return openai.ChatCompletionResponse{}, ctx.Err()
+		})
+
+	_, err := router.CreateChatCompletion(ctx, openai.ChatCompletionRequest{Model: "llama3"})
+	require.ErrorIs(t, err, context.Canceled)
+
+	// The canceled call doesn't open the circuit of helix
+	assert.True(t, router.breakers[0].allow())
+
+	helix.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).Return(openai.ChatCompletionResponse{}, nil)
+
+	_, err = router.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: "llama3"})
+	require.NoError(t, err)
+}
+
+func TestCircuitBreaker_Latency(t *testing.T) {
+	b := newCircuitBreaker(&CircuitBreakerConfig{Window: 4, MinRequests: 4, ErrorRate: 0.5, MaxLatency: time.Second, Cooldown: time.Minute})
+
+	b.record(nil, 100*time.Millisecond)
+	b.record(nil, 2*time.Second)
+	b.record(nil, 100*time.Millisecond)
+	assert.True(t, b.allow())
+
+	b.record(nil, 3*time.Second)
+	assert.False(t, b.allow())
+}
+
+func TestLoadRoutingPolicies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "routing.yaml")
+
+	err := os.WriteFile(path, []byte(`
+- name: llama3
+  strategy: weighted
+  targets:
+    - provider: helix
+      model: llama3:instruct
+      weight: 3
+      max_queue_length: 20
+    - provider: togetherai
+      model: meta-llama/Llama-3-8b-chat-hf
+  circuit_breaker:
+    max_latency: 30s
+`), 0o600)
+	require.NoError(t, err)
+
+	policies, err := LoadRoutingPolicies(path)
+	require.NoError(t, err)
+	require.Len(t, policies, 1)
+
+	policy := policies[0]
+	assert.Equal(t, RoutingStrategyWeighted, policy.Strategy)
+	assert.Equal(t, 3, policy.Targets[0].Weight)
+	assert.Equal(t, 20, policy.Targets[0].MaxQueueLength)
+	assert.Equal(t, 1, policy.Targets[1].Weight)
+	assert.Equal(t, &CircuitBreakerConfig{Window: 20, MinRequests: 5, ErrorRate: 0.5, MaxLatency: 30 * time.Second, Cooldown: 30 * time.Second}, policy.CircuitBreaker)
+
+	policies, err = LoadRoutingPolicies("")
+	require.NoError(t, err)
+	assert.Nil(t, policies)
+}
+
+func TestSetRoutingPolicies(t *testing.T) {
+	m := NewProviderManager(&config.ServerConfig{}, nil, nil)
+
+	err := m.SetRoutingPolicies([]*RoutingPolicy{
+		{Name: "openai", Targets: []RoutingTarget{{Provider: types.ProviderHelix}}},
+	})
+	assert.ErrorContains(t, err, "reserved for a built-in provider")
+
+	err = m.SetRoutingPolicies([]*RoutingPolicy{
+		{Name: "a", Targets: []RoutingTarget{{Provider: "b"}}},
+		{Name: "b", Targets: []RoutingTarget{{Provider: types.ProviderHelix}}},
+	})
+	assert.ErrorContains(t, err, "routing policy 'a' targets the routing policy 'b'")
+
+	err = m.SetRoutingPolicies([]*RoutingPolicy{
+		{Name: "llama3", Targets: []RoutingTarget{{Provider: types.ProviderHelix}}},
+	})
+	require.NoError(t, err)
+
+	client, err := m.GetClient(context.Background(), &GetClientRequest{Provider: "llama3"})
+	require.NoError(t, err)
+	assert.IsType(t, &Router{}, client)
+}
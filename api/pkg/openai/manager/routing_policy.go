@@ -0,0 +1,136 @@
+package manager
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+type RoutingStrategy string
+
+const (
+	// RoutingStrategyFallback tries the targets in order
+	RoutingStrategyFallback RoutingStrategy = "fallback"
+	// RoutingStrategyWeighted spreads the calls across the targets by their
+	// weights, the other targets are the fallbacks
+	RoutingStrategyWeighted RoutingStrategy = "weighted"
+)
+
+// RoutingTarget is a provider of a route
+type RoutingTarget struct {
+	Provider types.Provider `yaml:"provider"`
+	// Model replaces the model of the request, the same model can have
+	// different names on different providers
+	Model  string `yaml:"model"`
+	Weight int    `yaml:"weight"`
+	// MaxQueueLength skips the Helix provider when there are more requests
+	// waiting for a runner
+	MaxQueueLength int `yaml:"max_queue_length"`
+}
+
+// CircuitBreakerConfig stops sending calls to a target for the cooldown when
+// the failure rate of its last calls is too high. Calls slower than the max
+// latency count as failures.
+type CircuitBreakerConfig struct {
+	Window      int           `yaml:"window"`
+	MinRequests int           `yaml:"min_requests"`
+	ErrorRate   float64       `yaml:"error_rate"`
+	MaxLatency  time.Duration `yaml:"max_latency"`
+	Cooldown    time.Duration `yaml:"cooldown"`
+}
+
+// RoutingPolicy is a virtual provider that apps use by setting its name as
+// the assistant provider. The policies are loaded from a YAML list, e.g. a
+// route "llama3" with the fallback strategy that targets helix with a max
+// queue length of 20 and then togetherai with its own name of the model.
+type RoutingPolicy struct {
+	Name           string                `yaml:"name"`
+	Strategy       RoutingStrategy       `yaml:"strategy"`
+	Targets        []RoutingTarget       `yaml:"targets"`
+	CircuitBreaker *CircuitBreakerConfig `yaml:"circuit_breaker"`
+}
+
+func LoadRoutingPolicies(path string) ([]*RoutingPolicy, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	bts, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read routing file: %w", err)
+	}
+
+	var policies []*RoutingPolicy
+	err = yaml.Unmarshal(bts, &policies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse routing file: %w", err)
+	}
+
+	for _, policy := range policies {
+		err = validateRoutingPolicy(policy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return policies, nil
+}
+
+func validateRoutingPolicy(policy *RoutingPolicy) error {
+	if policy.Name == "" {
+		return fmt.Errorf("routing policy name is required")
+	}
+
+	if types.Provider(policy.Name).IsBuiltin() {
+		return fmt.Errorf("routing policy name '%s' is reserved for a built-in provider", policy.Name)
+	}
+
+	switch policy.Strategy {
+	case "":
+		policy.Strategy = RoutingStrategyFallback
+	case RoutingStrategyFallback, RoutingStrategyWeighted:
+	default:
+		return fmt.Errorf("unknown strategy '%s' of routing policy '%s'", policy.Strategy, policy.Name)
+	}
+
+	if len(policy.Targets) == 0 {
+		return fmt.Errorf("routing policy '%s' has no targets", policy.Name)
+	}
+
+	for i := range policy.Targets {
+		target := &policy.Targets[i]
+		if target.Provider == "" {
+			return fmt.Errorf("routing policy '%s' has a target without a provider", policy.Name)
+		}
+		if target.Weight < 0 {
+			return fmt.Errorf("weight of provider '%s' in routing policy '%s' must not be negative", target.Provider, policy.Name)
+		}
+		if target.Weight == 0 {
+			target.Weight = 1
+		}
+	}
+
+	if cb := policy.CircuitBreaker; cb != nil {
+		if cb.ErrorRate < 0 || cb.ErrorRate > 1 {
+			return fmt.Errorf("error rate of routing policy '%s' must be between 0 and 1", policy.Name)
+		}
+		if cb.Window == 0 {
+			cb.Window = 20
+		}
+		if cb.MinRequests == 0 {
+			cb.MinRequests = 5
+		}
+		if cb.ErrorRate == 0 {
+			cb.ErrorRate = 0.5
+		}
+		if cb.Cooldown == 0 {
+			cb.Cooldown = 30 * time.Second
+		}
+	}
+
+	return nil
+}
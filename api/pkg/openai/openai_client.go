@@ -26,21 +26,58 @@ const (
 type Client interface {
 	CreateChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error)
 	CreateChatCompletionStream(ctx context.Context, request openai.ChatCompletionRequest) (*openai.ChatCompletionStream, error)
+	CreateEmbeddings(ctx context.Context, request openai.EmbeddingRequest) (openai.EmbeddingResponse, error)
 
 	ListModels(ctx context.Context) ([]model.OpenAIModel, error)
 }
 
 func New(apiKey string, baseURL string) *RetryableClient {
-	config := openai.DefaultConfig(apiKey)
-	config.BaseURL = baseURL
+	return NewWithOptions(ClientOptions{
+		APIKey:  apiKey,
+		BaseURL: baseURL,
+	})
+}
+
+// ClientOptions configure the client of an OpenAI compatible endpoint
+type ClientOptions struct {
+	APIKey  string
+	BaseURL string
+	// Headers are sent with every request
+	Headers map[string]string
+	// Azure endpoints authenticate with the api-key header and address the
+	// models as deployments
+	Azure      bool
+	APIVersion string
+}
+
+func NewWithOptions(opts ClientOptions) *RetryableClient {
+	httpClient := http.DefaultClient
+	if len(opts.Headers) > 0 {
+		httpClient = &http.Client{
+			Transport: &headersTransport{
+				headers: opts.Headers,
+				next:    http.DefaultTransport,
+			},
+		}
+	}
+
+	config := openai.DefaultConfig(opts.APIKey)
+	if opts.Azure {
+		config = openai.DefaultAzureConfig(opts.APIKey, opts.BaseURL)
+		if opts.APIVersion != "" {
+			config.APIVersion = opts.APIVersion
+		}
+	}
+	config.BaseURL = opts.BaseURL
+	config.HTTPClient = httpClient
 
 	client := openai.NewClientWithConfig(config)
 
 	return &RetryableClient{
 		apiClient:  client,
-		httpClient: http.DefaultClient,
-		baseURL:    baseURL,
-		apiKey:     apiKey,
+		httpClient: httpClient,
+		baseURL:    opts.BaseURL,
+		apiKey:     opts.APIKey,
 	}
 }
 
@@ -52,6 +89,20 @@ type RetryableClient struct {
 	apiKey     string
 }
 
+// headersTransport sets the default headers of the endpoint on the requests
+type headersTransport struct {
+	headers map[string]string
+	next    http.RoundTripper
+}
+
+func (t *headersTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	return t.next.RoundTrip(req)
+}
+
 func (c *RetryableClient) CreateChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (resp openai.ChatCompletionResponse, err error) {
 	// Perform request with retries
 	err = retry.Do(func() error {
@@ -78,6 +129,27 @@ func (c *RetryableClient) CreateChatCompletionStream(ctx context.Context, reques
 	return c.apiClient.CreateChatCompletionStream(ctx, request)
 }
 
+func (c *RetryableClient) CreateEmbeddings(ctx context.Context, request openai.EmbeddingRequest) (resp openai.EmbeddingResponse, err error) {
+	err = retry.Do(func() error {
+		resp, err = c.apiClient.CreateEmbeddings(ctx, request)
+		if err != nil {
+			if strings.Contains(err.Error(), "401 Unauthorized") {
+				return retry.Unrecoverable(err)
+			}
+
+			return err
+		}
+
+		return nil
+	},
+		retry.Attempts(retries),
+		retry.Delay(delayBetweenRetries),
+		retry.Context(ctx),
+	)
+
+	return
+}
+
 // TODO: just use OpenAI client's ListModels function and separate this from TogetherAI
 func (c *RetryableClient) ListModels(ctx context.Context) ([]model.OpenAIModel, error) {
 	url := c.baseURL + "/models"
@@ -72,3 +72,98 @@ func WriteChunk(w io.Writer, chunk []byte) error {
 
 	return nil
 }
+
+// AppendChunk adds the chunk of a stream to the response, so that streamed
+// responses can be logged and cached like the others. The deltas of every
+// choice are accumulated, including the tool calls and their arguments.
+func AppendChunk(resp *openai.ChatCompletionResponse, chunk *openai.ChatCompletionStreamResponse) {
+	if chunk == nil {
+		return
+	}
+
+	if len(resp.Choices) == 0 {
+		resp.Choices = []openai.ChatCompletionChoice{newChoice(0)}
+	}
+
+	if chunk.Model != "" {
+		resp.Model = chunk.Model
+	}
+
+	if chunk.ID != "" {
+		resp.ID = chunk.ID
+	}
+
+	if chunk.Created != 0 {
+		resp.Created = chunk.Created
+	}
+
+	for _, delta := range chunk.Choices {
+		for len(resp.Choices) <= delta.Index {
+			resp.Choices = append(resp.Choices, newChoice(len(resp.Choices)))
+		}
+		choice := &resp.Choices[delta.Index]
+
+		if delta.Delta.Role != "" {
+			choice.Message.Role = delta.Delta.Role
+		}
+
+		choice.Message.Content += delta.Delta.Content
+
+		// The name comes in the first delta, the arguments are streamed
+		if delta.Delta.FunctionCall != nil {
+			if choice.Message.FunctionCall == nil {
+				choice.Message.FunctionCall = &openai.FunctionCall{}
+			}
+			choice.Message.FunctionCall.Name += delta.Delta.FunctionCall.Name
+			choice.Message.FunctionCall.Arguments += delta.Delta.FunctionCall.Arguments
+		}
+
+		for _, toolCall := range delta.Delta.ToolCalls {
+			appendToolCall(&choice.Message, toolCall)
+		}
+
+		if delta.FinishReason != "" {
+			choice.FinishReason = delta.FinishReason
+		}
+	}
+
+	// The usage is only sent in the last chunk, when the client asks for it
+	if chunk.Usage != nil {
+		resp.Usage = *chunk.Usage
+	}
+}
+
+func newChoice(index int) openai.ChatCompletionChoice {
+	return openai.ChatCompletionChoice{
+		Index: index,
+		Message: openai.ChatCompletionMessage{
+			Role: openai.ChatMessageRoleAssistant,
+		},
+	}
+}
+
+// appendToolCall adds the delta to the tool call with the same index, the ID,
+// the type and the function name come in the first delta of each call
+func appendToolCall(message *openai.ChatCompletionMessage, delta openai.ToolCall) {
+	index := len(message.ToolCalls)
+	if delta.Index != nil {
+		index = *delta.Index
+	} else if delta.ID == "" && index > 0 {
+		// Continuation of the last call
+		index--
+	}
+
+	for len(message.ToolCalls) <= index {
+		message.ToolCalls = append(message.ToolCalls, openai.ToolCall{})
+	}
+	toolCall := &message.ToolCalls[index]
+
+	if delta.ID != "" {
+		toolCall.ID = delta.ID
+	}
+	if delta.Type != "" {
+		toolCall.Type = delta.Type
+	}
+	toolCall.Function.Name += delta.Function.Name
+	toolCall.Function.Arguments += delta.Function.Arguments
+}
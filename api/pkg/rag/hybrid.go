@@ -0,0 +1,65 @@
+package rag
+
+import (
+	"sort"
+
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+const DefaultHybridAlpha = 0.5
+
+// hybridAlpha is the weight of the vector score, 0 only uses the keyword score
+func hybridAlpha(q *types.SessionRAGQuery) float64 {
+	if q.HybridAlpha == nil || *q.HybridAlpha < 0 || *q.HybridAlpha > 1 {
+		return DefaultHybridAlpha
+	}
+	return *q.HybridAlpha
+}
+
+// blendResults sets the HybridScore of the results found by the vector and the
+// keyword searches. VectorScore and KeywordScore are normalised to 0..1 across
+// all the results and blended, alpha being the weight of the vector score. The
+// results are sorted by the HybridScore and truncated to the limit.
+func blendResults(results []*types.SessionRAGResult, alpha float64, limit int) []*types.SessionRAGResult {
+	vectorScores := normalisedScores(results, func(r *types.SessionRAGResult) float64 { return r.VectorScore })
+	keywordScores := normalisedScores(results, func(r *types.SessionRAGResult) float64 { return r.KeywordScore })
+
+	for i, result := range results {
+		result.HybridScore = alpha*vectorScores[i] + (1-alpha)*keywordScores[i]
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].HybridScore > results[j].HybridScore
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results
+}
+
+// normalisedScores min-max normalises the scores, when all the scores are equal
+// they can't tell the results apart so they all get 0
+func normalisedScores(results []*types.SessionRAGResult, score func(*types.SessionRAGResult) float64) []float64 {
+	normalised := make([]float64, len(results))
+	if len(results) == 0 {
+		return normalised
+	}
+
+	minScore, maxScore := score(results[0]), score(results[0])
+	for _, result := range results {
+		minScore = min(minScore, score(result))
+		maxScore = max(maxScore, score(result))
+	}
+
+	if maxScore == minScore {
+		return normalised
+	}
+
+	for i, result := range results {
+		normalised[i] = (score(result) - minScore) / (maxScore - minScore)
+	}
+
+	return normalised
+}
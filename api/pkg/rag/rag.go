@@ -3,7 +3,10 @@ package rag
 import (
 	"context"
 
+	oai "github.com/helixml/helix/api/pkg/openai"
 	"github.com/helixml/helix/api/pkg/types"
+
+	openai "github.com/sashabaranov/go-openai"
 )
 
 //go:generate mockgen -source $GOFILE -destination rag_mocks.go -package $GOPACKAGE
@@ -13,3 +16,29 @@ type RAG interface {
 	Query(ctx context.Context, q *types.SessionRAGQuery) ([]*types.SessionRAGResult, error)
 	Delete(ctx context.Context, req *types.DeleteIndexRequest) error
 }
+
+// EmbeddingsClient is an OpenAI compatible API used by the backends that
+// compute the embeddings themselves
+type EmbeddingsClient interface {
+	CreateEmbeddings(ctx context.Context, request openai.EmbeddingRequest) (openai.EmbeddingResponse, error)
+}
+
+// ChatClient is an OpenAI compatible API used by the LLM reranker
+type ChatClient interface {
+	CreateChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error)
+}
+
+// withContextValues sets the owner for the requests made outside of a session,
+// for example indexing runs in the background. The helix provider needs one to
+// route the request.
+func withContextValues(ctx context.Context) context.Context {
+	if _, ok := oai.GetContextValues(ctx); ok {
+		return ctx
+	}
+
+	return oai.SetContextValues(ctx, &oai.ContextValues{
+		OwnerID:       "system",
+		SessionID:     "n/a",
+		InteractionID: "n/a",
+	})
+}
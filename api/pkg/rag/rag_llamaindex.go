@@ -117,6 +117,11 @@ func (l *Llamaindex) Query(ctx context.Context, q *types.SessionRAGQuery) ([]*ty
 		return nil, fmt.Errorf("data entity ID cannot be empty")
 	}
 
+	// The llamaindex server only does vector search
+	if q.Hybrid {
+		return nil, fmt.Errorf("hybrid search is not supported by the llamaindex RAG backend, use pgvector or typesense")
+	}
+
 	// Set defaults
 	if q.DistanceFunction == "" {
 		q.DistanceFunction = DefaultDistanceFunction
@@ -12,6 +12,19 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+func TestLlamaindex_HybridUnsupported(t *testing.T) {
+	indexer := NewLlamaindex(&types.RAGSettings{
+		QueryURL: "http://localhost:0/query",
+	})
+
+	_, err := indexer.Query(context.Background(), &types.SessionRAGQuery{
+		Prompt:       "E-4012",
+		DataEntityID: "data_entity_id",
+		Hybrid:       true,
+	})
+	require.ErrorContains(t, err, "hybrid search is not supported")
+}
+
 func TestRAG(t *testing.T) {
 	indexURL := os.Getenv("RAG_INDEX_URL")
 	queryURL := os.Getenv("RAG_QUERY_URL")
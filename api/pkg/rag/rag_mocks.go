@@ -8,8 +8,9 @@ import (
 	context "context"
 	reflect "reflect"
 
-	gomock "go.uber.org/mock/gomock"
 	types "github.com/helixml/helix/api/pkg/types"
+	openai "github.com/sashabaranov/go-openai"
+	gomock "go.uber.org/mock/gomock"
 )
 
 // MockRAG is a mock of RAG interface.
@@ -82,3 +83,79 @@ func (mr *MockRAGMockRecorder) Query(ctx, q interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockRAG)(nil).Query), ctx, q)
 }
+
+// MockEmbeddingsClient is a mock of EmbeddingsClient interface.
+type MockEmbeddingsClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockEmbeddingsClientMockRecorder
+}
+
+// MockEmbeddingsClientMockRecorder is the mock recorder for MockEmbeddingsClient.
+type MockEmbeddingsClientMockRecorder struct {
+	mock *MockEmbeddingsClient
+}
+
+// NewMockEmbeddingsClient creates a new mock instance.
+func NewMockEmbeddingsClient(ctrl *gomock.Controller) *MockEmbeddingsClient {
+	mock := &MockEmbeddingsClient{ctrl: ctrl}
+	mock.recorder = &MockEmbeddingsClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEmbeddingsClient) EXPECT() *MockEmbeddingsClientMockRecorder {
+	return m.recorder
+}
+
+// CreateEmbeddings mocks base method.
+func (m *MockEmbeddingsClient) CreateEmbeddings(ctx context.Context, request openai.EmbeddingRequest) (openai.EmbeddingResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEmbeddings", ctx, request)
+	ret0, _ := ret[0].(openai.EmbeddingResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateEmbeddings indicates an expected call of CreateEmbeddings.
+func (mr *MockEmbeddingsClientMockRecorder) CreateEmbeddings(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEmbeddings", reflect.TypeOf((*MockEmbeddingsClient)(nil).CreateEmbeddings), ctx, request)
+}
+
+// MockChatClient is a mock of ChatClient interface.
+type MockChatClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockChatClientMockRecorder
+}
+
+// MockChatClientMockRecorder is the mock recorder for MockChatClient.
+type MockChatClientMockRecorder struct {
+	mock *MockChatClient
+}
+
+// NewMockChatClient creates a new mock instance.
+func NewMockChatClient(ctrl *gomock.Controller) *MockChatClient {
+	mock := &MockChatClient{ctrl: ctrl}
+	mock.recorder = &MockChatClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockChatClient) EXPECT() *MockChatClientMockRecorder {
+	return m.recorder
+}
+
+// CreateChatCompletion mocks base method.
+func (m *MockChatClient) CreateChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateChatCompletion", ctx, request)
+	ret0, _ := ret[0].(openai.ChatCompletionResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateChatCompletion indicates an expected call of CreateChatCompletion.
+func (mr *MockChatClientMockRecorder) CreateChatCompletion(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateChatCompletion", reflect.TypeOf((*MockChatClient)(nil).CreateChatCompletion), ctx, request)
+}
@@ -0,0 +1,304 @@
+package rag
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+
+	"github.com/rs/zerolog/log"
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// pgvectorOperators maps the distance functions to the pgvector operators,
+// note that inner_product returns the negative inner product
+var pgvectorOperators = map[string]string{
+	"l2":            "<->",
+	"inner_product": "<#>",
+	"cosine":        "<=>",
+}
+
+// Static check
+var _ RAG = &PGVector{}
+
+// PGVector stores the chunks and their embeddings in Postgres with the pgvector
+// extension. Tables are created by store.MigratePGVectorUp.
+type PGVector struct {
+	db         *sql.DB
+	embeddings EmbeddingsClient
+	model      string
+}
+
+func NewPGVector(db *sql.DB, embeddings EmbeddingsClient, model string) *PGVector {
+	return &PGVector{
+		db:         db,
+		embeddings: embeddings,
+		model:      model,
+	}
+}
+
+func (p *PGVector) Index(ctx context.Context, indexReqs ...*types.SessionRAGIndexChunk) error {
+	if len(indexReqs) == 0 {
+		return fmt.Errorf("no index requests provided")
+	}
+
+	input := make([]string, len(indexReqs))
+	for i, indexReq := range indexReqs {
+		if indexReq.DataEntityID == "" {
+			return fmt.Errorf("data entity ID cannot be empty")
+		}
+		input[i] = indexReq.Content
+	}
+
+	embeddings, err := p.embed(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO knowledge_embeddings
+			(id, data_entity_id, document_group_id, document_id, source, filename, content_offset, content, embedding)
+		VALUES
+			($1, $2, $3, $4, $5, $6, $7, $8, $9::vector)
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for i, indexReq := range indexReqs {
+		_, err = stmt.ExecContext(ctx,
+			system.GenerateUUID(),
+			indexReq.DataEntityID,
+			indexReq.DocumentGroupID,
+			indexReq.DocumentID,
+			indexReq.Source,
+			indexReq.Filename,
+			indexReq.ContentOffset,
+			indexReq.Content,
+			vectorLiteral(embeddings[i]),
+		)
+		if err != nil {
+			return fmt.Errorf("error inserting document chunk: %w", err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	log.Trace().
+		Str("data_entity_id", indexReqs[0].DataEntityID).
+		Int("chunks", len(indexReqs)).
+		Msg("indexed document chunks")
+
+	return nil
+}
+
+func (p *PGVector) Query(ctx context.Context, q *types.SessionRAGQuery) ([]*types.SessionRAGResult, error) {
+	if q.Prompt == "" {
+		return nil, fmt.Errorf("prompt cannot be empty")
+	}
+
+	if q.DataEntityID == "" {
+		return nil, fmt.Errorf("data entity ID cannot be empty")
+	}
+
+	distanceFunction := q.DistanceFunction
+	if distanceFunction == "" {
+		distanceFunction = DefaultDistanceFunction
+	}
+
+	operator, ok := pgvectorOperators[distanceFunction]
+	if !ok {
+		return nil, fmt.Errorf("unknown distance function %s, must be one of l2, inner_product or cosine", distanceFunction)
+	}
+
+	embeddings, err := p.embed(ctx, []string{q.Prompt})
+	if err != nil {
+		return nil, err
+	}
+	embedding := vectorLiteral(embeddings[0])
+
+	results, err := p.query(ctx, distanceFunction, pgvectorVectorQuery(operator),
+		embedding, q.DataEntityID, q.Prompt, maxResults(q), threshold(q))
+	if err != nil {
+		return nil, err
+	}
+
+	if q.Hybrid {
+		// Exact matches of identifiers like error codes can be far from the
+		// prompt in the vector space, so the keyword search ignores the threshold
+		keywordResults, err := p.query(ctx, distanceFunction, pgvectorKeywordQuery(operator),
+			embedding, q.DataEntityID, q.Prompt, maxResults(q))
+		if err != nil {
+			return nil, err
+		}
+
+		found := make(map[string]bool)
+		for _, result := range results {
+			found[result.ID] = true
+		}
+		for _, result := range keywordResults {
+			if !found[result.ID] {
+				results = append(results, result)
+			}
+		}
+
+		results = blendResults(results, hybridAlpha(q), maxResults(q))
+	}
+
+	log.Trace().
+		Str("data_entity_id", q.DataEntityID).
+		Bool("hybrid", q.Hybrid).
+		Int("num_results", len(results)).
+		Msg("pgvector results")
+
+	return results, nil
+}
+
+func (p *PGVector) query(ctx context.Context, distanceFunction, query string, args ...any) ([]*types.SessionRAGResult, error) {
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying document chunks: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*types.SessionRAGResult
+	for rows.Next() {
+		var result types.SessionRAGResult
+		err = rows.Scan(
+			&result.ID,
+			&result.DocumentGroupID,
+			&result.DocumentID,
+			&result.Source,
+			&result.Filename,
+			&result.ContentOffset,
+			&result.Content,
+			&result.Distance,
+			&result.KeywordScore,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result.VectorScore = similarity(distanceFunction, result.Distance)
+		results = append(results, &result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (p *PGVector) Delete(ctx context.Context, r *types.DeleteIndexRequest) error {
+	if r.DataEntityID == "" {
+		return fmt.Errorf("data entity ID cannot be empty")
+	}
+
+	_, err := p.db.ExecContext(ctx, `DELETE FROM knowledge_embeddings WHERE data_entity_id = $1`, r.DataEntityID)
+	if err != nil {
+		return fmt.Errorf("error deleting document chunks: %w", err)
+	}
+
+	return nil
+}
+
+func (p *PGVector) embed(ctx context.Context, input []string) ([][]float32, error) {
+	resp, err := p.embeddings.CreateEmbeddings(withContextValues(ctx), openai.EmbeddingRequest{
+		Model: openai.EmbeddingModel(p.model),
+		Input: input,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting embeddings: %w", err)
+	}
+
+	if len(resp.Data) != len(input) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(input), len(resp.Data))
+	}
+
+	embeddings := make([][]float32, len(input))
+	for _, data := range resp.Data {
+		if data.Index < 0 || data.Index >= len(input) {
+			return nil, fmt.Errorf("unexpected embedding index %d", data.Index)
+		}
+		embeddings[data.Index] = data.Embedding
+	}
+
+	return embeddings, nil
+}
+
+// The queries take the embedding ($1), the data entity ID ($2), the prompt ($3),
+// the limit ($4) and the vector search also the threshold ($5). Both return the
+// distance and the keyword rank so that the scores can be blended. The operator
+// can't be passed as a parameter.
+const pgvectorSelect = `
+	SELECT id, document_group_id, document_id, source, filename, content_offset, content,
+		embedding %[1]s $1::vector AS distance,
+		ts_rank_cd(content_tsv, websearch_to_tsquery('simple', $3)) AS keyword_rank
+	FROM knowledge_embeddings
+`
+
+func pgvectorVectorQuery(operator string) string {
+	return fmt.Sprintf(pgvectorSelect+`
+	WHERE data_entity_id = $2 AND embedding %[1]s $1::vector < $5
+	ORDER BY distance
+	LIMIT $4
+	`, operator)
+}
+
+func pgvectorKeywordQuery(operator string) string {
+	return fmt.Sprintf(pgvectorSelect+`
+	WHERE data_entity_id = $2 AND content_tsv @@ websearch_to_tsquery('simple', $3)
+	ORDER BY keyword_rank DESC
+	LIMIT $4
+	`, operator)
+}
+
+// similarity converts the distance into a score where higher is better
+func similarity(distanceFunction string, distance float64) float64 {
+	switch distanceFunction {
+	case "l2":
+		return 1 / (1 + distance)
+	case "inner_product":
+		// pgvector returns the negative inner product
+		return -distance
+	default:
+		return 1 - distance
+	}
+}
+
+func threshold(q *types.SessionRAGQuery) float64 {
+	if q.DistanceThreshold == 0 {
+		return DefaultThreshold
+	}
+	return q.DistanceThreshold
+}
+
+func maxResults(q *types.SessionRAGQuery) int {
+	if q.MaxResults == 0 {
+		return DefaultMaxResults
+	}
+	return q.MaxResults
+}
+
+// vectorLiteral formats the embedding as a pgvector literal, e.g. [0.1,0.2]
+func vectorLiteral(embedding []float32) string {
+	parts := make([]string, len(embedding))
+	for i, v := range embedding {
+		parts[i] = strconv.FormatFloat(float64(v), 'f', -1, 32)
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
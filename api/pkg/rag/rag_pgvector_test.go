@@ -0,0 +1,263 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type PGVectorTestSuite struct {
+	suite.Suite
+	ctx context.Context
+
+	embeddings *MockEmbeddingsClient
+	pgv        *PGVector
+}
+
+func TestPGVectorTestSuite(t *testing.T) {
+	suite.Run(t, new(PGVectorTestSuite))
+}
+
+// testEmbeddings are 'embeddings' of the test chunks and prompts
+var testEmbeddings = map[string][]float32{
+	"cats are small":         {1, 0, 0},
+	"dogs are loyal":         {0, 1, 0},
+	"fish can swim":          {0, 0, 1},
+	"tell me about cats":     {0.9, 0.1, 0},
+	"tell me about anything": {0.5, 0.5, 0.5},
+	"error E-4012":           {0, -1, 0},
+	"E-4012":                 {0, 0, -1},
+}
+
+func (suite *PGVectorTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+
+	host := os.Getenv("POSTGRES_HOST")
+	if host == "" {
+		host = "localhost"
+	}
+
+	db, err := store.NewPostgresStore(config.Store{
+		Host:        host,
+		Port:        5432,
+		Username:    "postgres",
+		Password:    "postgres",
+		Database:    "postgres",
+		AutoMigrate: true,
+	})
+	suite.Require().NoError(err)
+
+	err = db.MigratePGVectorUp()
+	suite.Require().NoError(err)
+
+	suite.embeddings = NewMockEmbeddingsClient(gomock.NewController(suite.T()))
+	suite.embeddings.EXPECT().CreateEmbeddings(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req openai.EmbeddingRequest) (openai.EmbeddingResponse, error) {
+			suite.Equal(openai.EmbeddingModel("test-model"), req.Model)
+
+			var resp openai.EmbeddingResponse
+			for i, input := range req.Input.([]string) {
+				embedding, ok := testEmbeddings[input]
+				if !ok {
+					return resp, fmt.Errorf("no embedding for %s", input)
+				}
+				resp.Data = append(resp.Data, openai.Embedding{Index: i, Embedding: embedding})
+			}
+			return resp, nil
+		}).AnyTimes()
+
+	suite.pgv = NewPGVector(db.DB(), suite.embeddings, "test-model")
+}
+
+func (suite *PGVectorTestSuite) index(dataEntityID string) {
+	var chunks []*types.SessionRAGIndexChunk
+	for i, content := range []string{"cats are small", "dogs are loyal", "fish can swim"} {
+		chunks = append(chunks, &types.SessionRAGIndexChunk{
+			DataEntityID:    dataEntityID,
+			Source:          "pets.txt",
+			Filename:        "pets.txt",
+			DocumentID:      "doc-" + dataEntityID,
+			DocumentGroupID: "group-" + dataEntityID,
+			ContentOffset:   i * 100,
+			Content:         content,
+		})
+	}
+
+	err := suite.pgv.Index(suite.ctx, chunks...)
+	suite.Require().NoError(err)
+
+	suite.T().Cleanup(func() {
+		_ = suite.pgv.Delete(suite.ctx, &types.DeleteIndexRequest{DataEntityID: dataEntityID})
+	})
+}
+
+func (suite *PGVectorTestSuite) TestIndexAndQuery() {
+	dataEntityID := "test-" + system.GenerateID()
+	suite.index(dataEntityID)
+
+	// Other data entities must not show up in the results
+	suite.index("test-" + system.GenerateID())
+
+	results, err := suite.pgv.Query(suite.ctx, &types.SessionRAGQuery{
+		Prompt:            "tell me about cats",
+		DataEntityID:      dataEntityID,
+		DistanceFunction:  "cosine",
+		DistanceThreshold: 0.4,
+		MaxResults:        3,
+	})
+	suite.Require().NoError(err)
+
+	suite.Require().Len(results, 1)
+	suite.Equal("cats are small", results[0].Content)
+	suite.Equal("doc-"+dataEntityID, results[0].DocumentID)
+	suite.Equal("group-"+dataEntityID, results[0].DocumentGroupID)
+	suite.Equal("pets.txt", results[0].Source)
+	suite.Equal(0, results[0].ContentOffset)
+	suite.Less(results[0].Distance, 0.4)
+}
+
+func (suite *PGVectorTestSuite) TestQuery_DistanceFunctions() {
+	dataEntityID := "test-" + system.GenerateID()
+	suite.index(dataEntityID)
+
+	for _, distanceFunction := range []string{"l2", "inner_product", "cosine"} {
+		suite.Run(distanceFunction, func() {
+			results, err := suite.pgv.Query(suite.ctx, &types.SessionRAGQuery{
+				Prompt:            "tell me about anything",
+				DataEntityID:      dataEntityID,
+				DistanceFunction:  distanceFunction,
+				DistanceThreshold: 10,
+				MaxResults:        2,
+			})
+			suite.Require().NoError(err)
+
+			// All the chunks are equally close, only the limit applies
+			suite.Len(results, 2)
+		})
+	}
+}
+
+func (suite *PGVectorTestSuite) TestQuery_Hybrid() {
+	dataEntityID := "test-" + system.GenerateID()
+	suite.index(dataEntityID)
+
+	err := suite.pgv.Index(suite.ctx, &types.SessionRAGIndexChunk{
+		DataEntityID: dataEntityID,
+		Source:       "errors.txt",
+		Content:      "error E-4012",
+	})
+	suite.Require().NoError(err)
+
+	query := &types.SessionRAGQuery{
+		Prompt:            "E-4012",
+		DataEntityID:      dataEntityID,
+		DistanceFunction:  "cosine",
+		DistanceThreshold: 0.4,
+	}
+
+	// The error code is too far in the vector space
+	results, err := suite.pgv.Query(suite.ctx, query)
+	suite.Require().NoError(err)
+	suite.Empty(results)
+
+	query.Hybrid = true
+
+	results, err = suite.pgv.Query(suite.ctx, query)
+	suite.Require().NoError(err)
+	suite.Require().Len(results, 1)
+	suite.Equal("error E-4012", results[0].Content)
+	suite.Greater(results[0].KeywordScore, 0.0)
+}
+
+func (suite *PGVectorTestSuite) TestDelete() {
+	dataEntityID := "test-" + system.GenerateID()
+	suite.index(dataEntityID)
+
+	err := suite.pgv.Delete(suite.ctx, &types.DeleteIndexRequest{DataEntityID: dataEntityID})
+	suite.Require().NoError(err)
+
+	results, err := suite.pgv.Query(suite.ctx, &types.SessionRAGQuery{
+		Prompt:            "tell me about anything",
+		DataEntityID:      dataEntityID,
+		DistanceThreshold: 10,
+	})
+	suite.Require().NoError(err)
+	suite.Empty(results)
+}
+
+func TestPGVectorQuery(t *testing.T) {
+	for distanceFunction, operator := range pgvectorOperators {
+		t.Run(distanceFunction, func(t *testing.T) {
+			query := pgvectorVectorQuery(operator)
+			if !strings.Contains(query, "embedding "+operator+" $1::vector < $5") {
+				t.Errorf("expected operator %s in the query:\n%s", operator, query)
+			}
+		})
+	}
+}
+
+func TestBlendResults(t *testing.T) {
+	results := []*types.SessionRAGResult{
+		{ID: "vector", VectorScore: 1, KeywordScore: 0},
+		{ID: "both", VectorScore: 0.8, KeywordScore: 0.8},
+		{ID: "keyword", VectorScore: 0.2, KeywordScore: 1},
+	}
+
+	blended := blendResults(results, 0.4, 2)
+	if len(blended) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(blended))
+	}
+	if blended[0].ID != "both" || blended[1].ID != "keyword" {
+		t.Errorf("unexpected order: %s, %s", blended[0].ID, blended[1].ID)
+	}
+	if math.Abs(blended[0].HybridScore-(0.4*0.75+0.6*0.8)) > 0.0001 {
+		t.Errorf("unexpected hybrid score %f", blended[0].HybridScore)
+	}
+
+	vectorOnly := blendResults(results, 1, 0)
+	if vectorOnly[0].ID != "vector" {
+		t.Errorf("expected the vector result first with alpha 1, got %s", vectorOnly[0].ID)
+	}
+}
+
+func TestHybridAlpha(t *testing.T) {
+	alpha := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		alpha *float64
+		want  float64
+	}{
+		{nil, DefaultHybridAlpha},
+		{alpha(0), 0},
+		{alpha(0.7), 0.7},
+		{alpha(1), 1},
+		{alpha(-1), DefaultHybridAlpha},
+		{alpha(2), DefaultHybridAlpha},
+	}
+
+	for _, tt := range tests {
+		got := hybridAlpha(&types.SessionRAGQuery{Hybrid: true, HybridAlpha: tt.alpha})
+		if got != tt.want {
+			t.Errorf("expected alpha %f, got %f", tt.want, got)
+		}
+	}
+}
+
+func TestVectorLiteral(t *testing.T) {
+	got := vectorLiteral([]float32{0.1, -2, 3.5})
+	if got != "[0.1,-2,3.5]" {
+		t.Errorf("unexpected vector literal %s", got)
+	}
+}
@@ -136,7 +136,6 @@ func (t *Typesense) Query(ctx context.Context, q *types.SessionRAGQuery) ([]*typ
 		return nil, err
 	}
 
-	// TODO: implement hybrid search https://typesense.org/docs/26.0/api/vector-search.html#hybrid-search
 	searchParameters := &api.SearchCollectionParams{
 		Q:             pointer.String(q.Prompt),
 		QueryBy:       pointer.String("embedding,content"),
@@ -145,6 +144,16 @@ func (t *Typesense) Query(ctx context.Context, q *types.SessionRAGQuery) ([]*typ
 		ExcludeFields: pointer.String("embedding"), // Don't return the raw floating point numbers in the vector field in the search API response, to save on network bandwidth.
 	}
 
+	if q.Hybrid {
+		// Typesense blends the keyword and vector ranks itself, alpha is the weight of the
+		// vector rank https://typesense.org/docs/26.0/api/vector-search.html#hybrid-search
+		searchParameters.VectorQuery = pointer.String(fmt.Sprintf("embedding:([], alpha: %.2f)", hybridAlpha(q)))
+		searchParameters.SortBy = nil
+		if q.MaxResults > 0 {
+			searchParameters.PerPage = pointer.Int(q.MaxResults)
+		}
+	}
+
 	results, err := t.client.Collection(t.collection).Documents().Search(ctx, searchParameters)
 	if err != nil {
 		return nil, err
@@ -163,6 +172,15 @@ func (t *Typesense) Query(ctx context.Context, q *types.SessionRAGQuery) ([]*typ
 			Content:         getStrVariable(&hit, "content"),
 			ContentOffset:   getIntVariable(&hit, "content_offset"),
 		}
+
+		if hit.TextMatch != nil {
+			ragResult.KeywordScore = float64(*hit.TextMatch)
+		}
+		if hit.VectorDistance != nil {
+			ragResult.Distance = float64(*hit.VectorDistance)
+			ragResult.VectorScore = 1 - ragResult.Distance
+		}
+
 		ragResults = append(ragResults, ragResult)
 	}
 
@@ -0,0 +1,117 @@
+package rag
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	oai "github.com/helixml/helix/api/pkg/openai"
+	"github.com/helixml/helix/api/pkg/types"
+
+	"github.com/rs/zerolog/log"
+	openai "github.com/sashabaranov/go-openai"
+)
+
+const DefaultRerankTopN = 20
+
+// LLMReranker asks a model to grade how relevant each result is to the prompt
+// and reorders the results by the grades
+type LLMReranker struct {
+	client ChatClient
+	model  string
+}
+
+func NewLLMReranker(client ChatClient, model string) *LLMReranker {
+	return &LLMReranker{
+		client: client,
+		model:  model,
+	}
+}
+
+// Rerank sets the RerankScore (0..1) of the results and sorts them by it,
+// results with equal scores keep their original order
+func (r *LLMReranker) Rerank(ctx context.Context, prompt string, results []*types.SessionRAGResult) ([]*types.SessionRAGResult, error) {
+	if len(results) == 0 {
+		return results, nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Query: %s\n", prompt)
+	for i, result := range results {
+		fmt.Fprintf(&sb, "\nPassage %d:\n%s\n", i, result.Content)
+	}
+
+	ctx = oai.SetStep(withContextValues(ctx), &oai.Step{
+		Step: types.LLMCallStepRerank,
+	})
+
+	resp, err := r.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model: r.model,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleSystem,
+				Content: rerankPrompt,
+			},
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: sb.String(),
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting rerank scores: %w", err)
+	}
+
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("no response from the rerank model")
+	}
+
+	scores, err := parseRerankScores(resp.Choices[0].Message.Content, len(results))
+	if err != nil {
+		return nil, err
+	}
+
+	for i, result := range results {
+		result.RerankScore = scores[i] / 10
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].RerankScore > results[j].RerankScore
+	})
+
+	log.Trace().
+		Str("model", r.model).
+		Int("num_results", len(results)).
+		Msg("reranked results")
+
+	return results, nil
+}
+
+// parseRerankScores reads the JSON array of scores from the answer, the model
+// might wrap it in markdown or add some commentary
+func parseRerankScores(answer string, expected int) ([]float64, error) {
+	start := strings.Index(answer, "[")
+	end := strings.LastIndex(answer, "]")
+	if start == -1 || end < start {
+		return nil, fmt.Errorf("no scores in the rerank response: %s", answer)
+	}
+
+	var scores []float64
+	err := json.Unmarshal([]byte(answer[start:end+1]), &scores)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the rerank scores: %w (response: %s)", err, answer)
+	}
+
+	if len(scores) != expected {
+		return nil, fmt.Errorf("expected %d rerank scores, got %d", expected, len(scores))
+	}
+
+	return scores, nil
+}
+
+const rerankPrompt = `You grade how relevant passages are to a search query. For each passage give a score from 0 (unrelated) to 10 (answers the query exactly). Exact matches of identifiers in the query, such as error codes, product codes or names, are highly relevant.
+
+Respond only with a JSON array of the scores in the order of the passages, for example for three passages:
+[7, 0, 10]`
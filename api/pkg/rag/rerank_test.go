@@ -0,0 +1,40 @@
+package rag
+
+import (
+	"testing"
+)
+
+func TestParseRerankScores(t *testing.T) {
+	tests := []struct {
+		answer      string
+		expected    []float64
+		expectError bool
+	}{
+		{answer: "[7, 0, 10]", expected: []float64{7, 0, 10}},
+		{answer: "```json\n[1, 2.5, 3]\n```", expected: []float64{1, 2.5, 3}},
+		{answer: "Here are the scores: [0, 0, 1]", expected: []float64{0, 0, 1}},
+		{answer: "[1, 2]", expectError: true},
+		{answer: "all passages are relevant", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.answer, func(t *testing.T) {
+			scores, err := parseRerankScores(tt.answer, 3)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected an error, got %v", scores)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for i := range tt.expected {
+				if scores[i] != tt.expected[i] {
+					t.Errorf("expected %v, got %v", tt.expected, scores)
+					break
+				}
+			}
+		})
+	}
+}
@@ -306,7 +306,7 @@ func (r *Runner) reportStateLoop(_ context.Context) error {
 		return err
 	}
 	log.Trace().Msgf("🟠 Sending runner state %s %+v", r.Options.ID, state)
-	_, err = system.PostRequest[*types.RunnerState, *types.RunnerState](
+	resp, err := system.PostRequest[*types.RunnerState, *types.RunnerState](
 		r.httpClientOptions,
 		system.GetApiPath(fmt.Sprintf("/runner/%s/state", r.Options.ID)),
 		state,
@@ -314,9 +314,34 @@ func (r *Runner) reportStateLoop(_ context.Context) error {
 	if err != nil {
 		return err
 	}
+	if resp != nil {
+		r.stopPreemptedSessions(resp.PreemptedSessions)
+	}
 	return nil
 }
 
+// stopPreemptedSessions stops the model instances running the sessions that the
+// scheduler gave to work of a higher priority class
+func (r *Runner) stopPreemptedSessions(sessionIDs []string) {
+	for _, sessionID := range sessionIDs {
+		r.activeModelInstances.Range(func(key string, modelInstance ModelInstance) bool {
+			state, err := modelInstance.GetState()
+			if err != nil || state.CurrentSession == nil || state.CurrentSession.SessionID != sessionID {
+				return true
+			}
+
+			r.addSchedulingDecision(fmt.Sprintf("Stopping model instance %s because session %s was preempted", modelInstance.ID(), sessionID))
+			log.Info().Msgf("Stopping model instance %s because session %s was preempted", modelInstance.ID(), sessionID)
+			err = modelInstance.Stop()
+			if err != nil {
+				log.Error().Msgf("error stopping model instance %s: %s", modelInstance.ID(), err.Error())
+			}
+			r.activeModelInstances.Delete(modelInstance.ID())
+			return false
+		})
+	}
+}
+
 func GiB(bytes int64) float32 {
 	return float32(bytes) / 1024 / 1024 / 1024
 }
@@ -60,7 +60,7 @@ func (r *Runner) pollInferenceRequests(ctx context.Context) error {
 		return nil
 	}
 
-	modelName := model.ModelName(request.Request.Model)
+	modelName := model.ModelName(request.ModelName())
 
 	aiModel, err := model.GetModel(string(modelName))
 	if err != nil {
@@ -42,7 +42,7 @@ var (
 )
 
 func NewOllamaInferenceModelInstance(ctx context.Context, cfg *InferenceModelInstanceConfig, request *types.RunnerLLMInferenceRequest) (*OllamaInferenceModelInstance, error) {
-	modelName := model.ModelName(request.Request.Model)
+	modelName := model.ModelName(request.ModelName())
 
 	aiModel, err := model.GetModel(string(modelName))
 	if err != nil {
@@ -183,7 +183,7 @@ func (i *OllamaInferenceModelInstance) Start(_ context.Context) error {
 				} else {
 					log.Info().
 						Str("session_id", req.SessionID).
-						Bool("stream", req.Request.Stream).
+						Bool("embeddings", req.EmbeddingRequest != nil).
 						Msg("🟢 request processed")
 				}
 
@@ -436,7 +436,7 @@ func (i *OllamaInferenceModelInstance) GetState() (*types.ModelInstanceState, er
 		var summary string
 
 		// Get last message
-		if len(i.currentRequest.Request.Messages) > 0 {
+		if i.currentRequest.Request != nil && len(i.currentRequest.Request.Messages) > 0 {
 			summary = i.currentRequest.Request.Messages[len(i.currentRequest.Request.Messages)-1].Content
 		}
 
@@ -478,6 +478,10 @@ func (i *OllamaInferenceModelInstance) processInteraction(inferenceReq *types.Ru
 	i.inUse.Store(true)
 	defer i.inUse.Store(false)
 
+	if inferenceReq.EmbeddingRequest != nil {
+		return i.processEmbeddings(inferenceReq)
+	}
+
 	// Get the default Ollama models
 	defaultModels, err := model.GetDefaultOllamaModels()
 	if err != nil {
@@ -595,6 +599,82 @@ func (i *OllamaInferenceModelInstance) processInteraction(inferenceReq *types.Ru
 	}
 }
 
+// processEmbeddings gets the embedding of every input, Ollama only takes
+// one prompt per request
+func (i *OllamaInferenceModelInstance) processEmbeddings(inferenceReq *types.RunnerLLMInferenceRequest) error {
+	inputs, err := embeddingInputs(inferenceReq.EmbeddingRequest.Input)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+
+	resp := &openai.EmbeddingResponse{
+		Object: "list",
+		Model:  inferenceReq.EmbeddingRequest.Model,
+		Data:   make([]openai.Embedding, 0, len(inputs)),
+	}
+
+	for idx, input := range inputs {
+		embeddingResp, err := i.client.Embeddings(i.ctx, &api.EmbeddingRequest{
+			Model:  string(inferenceReq.EmbeddingRequest.Model),
+			Prompt: input,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get embeddings from inference API: %w", err)
+		}
+
+		embedding := make([]float32, len(embeddingResp.Embedding))
+		for j, v := range embeddingResp.Embedding {
+			embedding[j] = float32(v)
+		}
+
+		resp.Data = append(resp.Data, openai.Embedding{
+			Object:    "embedding",
+			Embedding: embedding,
+			Index:     idx,
+		})
+	}
+
+	err = i.responseHandler(&types.RunnerLLMInferenceResponse{
+		RequestID:         inferenceReq.RequestID,
+		OwnerID:           inferenceReq.OwnerID,
+		SessionID:         inferenceReq.SessionID,
+		InteractionID:     inferenceReq.InteractionID,
+		EmbeddingResponse: resp,
+		DurationMs:        time.Since(start).Milliseconds(),
+		Done:              true,
+	})
+	if err != nil {
+		log.Error().Msgf("error writing event: %s", err.Error())
+	}
+
+	return nil
+}
+
+// embeddingInputs converts the OpenAI input (a string or a list of strings)
+// into prompts, token inputs are not supported by Ollama
+func embeddingInputs(input any) ([]string, error) {
+	switch v := input.(type) {
+	case string:
+		return []string{v}, nil
+	case []string:
+		return v, nil
+	case []any:
+		inputs := make([]string, 0, len(v))
+		for _, item := range v {
+			str, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("unsupported embedding input %T, only strings are supported", item)
+			}
+			inputs = append(inputs, str)
+		}
+		return inputs, nil
+	default:
+		return nil, fmt.Errorf("unsupported embedding input %T, only strings are supported", input)
+	}
+}
+
 func (i *OllamaInferenceModelInstance) responseStreamProcessor(req *types.RunnerLLMInferenceRequest, resp *openai.ChatCompletionStreamResponse, done bool, durationMs int64) {
 	if req == nil {
 		log.Error().Msgf("no current request")
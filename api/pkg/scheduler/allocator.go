@@ -20,6 +20,9 @@ type WorkloadAllocator interface {
 	WarmSlots(req *Workload) []*Slot
 	RunnerSlots(id string) []*Slot
 	ReconcileSlots(props *types.RunnerState) error
+	RestoreSlot(slotID uuid.UUID, runnerID string, req *Workload) *Slot
+	Slot(slotID uuid.UUID) (*Slot, bool)
+	DeleteSlot(slotID uuid.UUID)
 }
 
 // TimeoutFunc defines a function type that determines if a runner has timed out based on the last activity.
@@ -93,6 +96,24 @@ func (a *allocator) AllocateNewSlot(runnerID string, req *Workload) (*Slot, erro
 	return slot, a.AllocateSlot(slot.ID, req)
 }
 
+// RestoreSlot adds an idle slot that was persisted before a restart, the model is
+// expected to be loaded on the runner already.
+func (a *allocator) RestoreSlot(slotID uuid.UUID, runnerID string, req *Workload) *Slot {
+	slot := NewSlot(runnerID, req, a.modelTimeoutFunc)
+	slot.ID = slotID
+	slot.isNew = false
+
+	log.Trace().
+		Str("runner_id", slot.RunnerID).
+		Str("slot_id", slot.ID.String()).
+		Str("model_name", slot.ModelName().String()).
+		Msg("restoring slot")
+
+	a.slots.Store(slot.ID, slot)
+
+	return slot
+}
+
 // ReleaseSlot frees the resources allocated to a specific slot.
 func (a *allocator) ReleaseSlot(slotID uuid.UUID) error {
 	// Find the slot.
@@ -246,6 +267,16 @@ func (a *allocator) WarmSlots(req *Workload) []*Slot {
 	return cosyWarm
 }
 
+// Slot returns the slot with the given ID.
+func (a *allocator) Slot(slotID uuid.UUID) (*Slot, bool) {
+	return a.slots.Load(slotID)
+}
+
+// DeleteSlot removes the slot, e.g. when its work is preempted.
+func (a *allocator) DeleteSlot(slotID uuid.UUID) {
+	a.slots.Delete(slotID)
+}
+
 // RunnerSlots returns all slots associated with a specific runner ID.
 func (a *allocator) RunnerSlots(id string) []*Slot {
 	allSlots := Values(a.slots)
@@ -0,0 +1,172 @@
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/helixml/helix/api/pkg/types"
+	"github.com/rs/zerolog/log"
+)
+
+// PreemptedFunc is called with the preempted session so that it can be queued again
+type PreemptedFunc func(session *types.Session)
+
+// preemptions keeps the sessions that the runners have to stop until they next
+// report their state
+type preemptions struct {
+	mu       sync.Mutex
+	sessions map[string][]string // Maps a runner ID to the preempted session IDs.
+}
+
+func (p *preemptions) add(runnerID, sessionID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.sessions == nil {
+		p.sessions = make(map[string][]string)
+	}
+	p.sessions[runnerID] = append(p.sessions[runnerID], sessionID)
+}
+
+func (p *preemptions) take(runnerID string) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	sessions := p.sessions[runnerID]
+	delete(p.sessions, runnerID)
+	return sessions
+}
+
+// OnPreempted sets the function that queues the preempted work again.
+func (s *scheduler) OnPreempted(fn PreemptedFunc) {
+	s.onPreempted = fn
+}
+
+// PreemptedSessions returns the sessions that the runner has to stop, each
+// session is returned once.
+func (s *scheduler) PreemptedSessions(runnerID string) []string {
+	return s.preemptions.take(runnerID)
+}
+
+// preempt makes room for inference work by preempting fine-tuning of a lower
+// priority class. It picks the runner where the least memory has to be freed and
+// returns placementErr if no runner can fit the work.
+func (s *scheduler) preempt(work *Workload, placementErr error) (string, error) {
+	if work.Mode() != types.SessionModeInference {
+		return "", placementErr
+	}
+
+	_, weight := s.priorityClasses.resolve(work)
+	required := work.Model().GetMemoryRequirements(work.Mode())
+
+	var (
+		bestRunnerID string
+		bestVictims  []*Slot
+		bestFreed    uint64
+	)
+	for _, runnerID := range s.cluster.RunnerIDs() {
+		if s.cluster.TotalMemory(runnerID) < required {
+			continue
+		}
+
+		available, err := availableMemory(s.cluster, s.allocator, runnerID)
+		if err != nil {
+			log.Warn().Err(err).Str("runner_id", runnerID).Msg("failed to get available memory for preemption")
+			continue
+		}
+
+		var (
+			victims []*Slot
+			freed   uint64
+		)
+		for _, slot := range s.preemptibleSlots(runnerID, weight) {
+			if available+freed >= required {
+				break
+			}
+			victims = append(victims, slot)
+			freed += slot.Memory()
+		}
+
+		if available+freed < required {
+			continue
+		}
+
+		if bestRunnerID == "" || freed < bestFreed {
+			bestRunnerID = runnerID
+			bestVictims = victims
+			bestFreed = freed
+		}
+	}
+
+	if bestRunnerID == "" {
+		return "", placementErr
+	}
+
+	for _, slot := range bestVictims {
+		victim := s.evict(slot)
+		if victim != nil {
+			work.decision.Preempted = append(work.decision.Preempted, victim.ID())
+		}
+	}
+	work.decision.Reason = fmt.Sprintf("preempted %d lower priority slots", len(bestVictims))
+
+	return bestRunnerID, nil
+}
+
+// preemptibleSlots returns the fine-tuning slots of the runner with work of a
+// lower weight, smallest first
+func (s *scheduler) preemptibleSlots(runnerID string, weight int) []*Slot {
+	slots := Filter(s.allocator.RunnerSlots(runnerID), func(slot *Slot) bool {
+		if slot.Mode() != types.SessionModeFinetune {
+			return false
+		}
+		if !slot.IsActive() && !slot.IsScheduled() {
+			return false
+		}
+		work, ok := s.workStore.Load(slot.ID)
+		if !ok {
+			return false
+		}
+		_, victimWeight := s.priorityClasses.resolve(work)
+		return victimWeight < weight
+	})
+
+	sort.SliceStable(slots, func(i, j int) bool {
+		return slots[i].Memory() < slots[j].Memory()
+	})
+
+	return slots
+}
+
+// evict removes the slot, asks the runner to stop the session if it already
+// started it and hands the work over to be queued again
+func (s *scheduler) evict(slot *Slot) *Workload {
+	active := slot.IsActive()
+
+	s.allocator.DeleteSlot(slot.ID)
+	s.deleteSlotState(slot.ID.String())
+
+	work, ok := s.workStore.LoadAndDelete(slot.ID)
+	if !ok {
+		return nil
+	}
+
+	log.Info().
+		Str("runner_id", slot.RunnerID).
+		Str("slot_id", slot.ID.String()).
+		Str("work_id", work.ID()).
+		Bool("active", active).
+		Msg("preempting work")
+
+	if active {
+		s.preemptions.add(slot.RunnerID, work.ID())
+	}
+
+	if s.onPreempted != nil && work.WorkloadType == WorkloadTypeSession {
+		// The callers of Schedule hold their queue locks, queue it again later
+		go s.onPreempted(work.Session())
+	}
+
+	return work
+}
@@ -0,0 +1,135 @@
+package scheduler
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// priorityClasses resolves the priority class of the work to its weight
+type priorityClasses struct {
+	weights      map[string]int
+	defaultClass string
+	highestClass string // Used for the work with the legacy priority flag.
+}
+
+func newPriorityClasses(weights map[string]int, defaultClass string) *priorityClasses {
+	p := &priorityClasses{
+		weights:      make(map[string]int),
+		defaultClass: defaultClass,
+	}
+
+	for class, weight := range weights {
+		if weight <= 0 {
+			log.Warn().Str("priority_class", class).Int("weight", weight).Msg("ignoring priority class without a positive weight")
+			continue
+		}
+		p.weights[class] = weight
+	}
+
+	if _, ok := p.weights[defaultClass]; !ok {
+		log.Warn().Str("priority_class", defaultClass).Msg("default priority class is not configured, giving it a weight of 1")
+		p.weights[defaultClass] = 1
+	}
+
+	for class, weight := range p.weights {
+		highest := p.weights[p.highestClass]
+		if weight > highest || (weight == highest && class < p.highestClass) {
+			p.highestClass = class
+		}
+	}
+
+	return p
+}
+
+// resolve returns the priority class of the work and its weight, unknown classes
+// fall back to the default class
+func (p *priorityClasses) resolve(work *Workload) (string, int) {
+	class, legacyPriority := work.PriorityClass()
+	if weight, ok := p.weights[class]; ok {
+		return class, weight
+	}
+
+	if class != "" {
+		log.Warn().Str("priority_class", class).Str("work_id", work.ID()).Msg("unknown priority class, using the default")
+	}
+
+	if legacyPriority {
+		return p.highestClass, p.weights[p.highestClass]
+	}
+
+	return p.defaultClass, p.weights[p.defaultClass]
+}
+
+// fairQueue orders the work with start-time fair queuing across the owners. Each
+// owner gets a share of the scheduled work proportional to the weight of its
+// priority class, so a single busy owner can't starve everyone else.
+type fairQueue struct {
+	mu          sync.Mutex
+	classes     *priorityClasses
+	virtualTime float64
+	finish      map[string]float64 // Virtual finish time of the last work scheduled for each owner.
+}
+
+func newFairQueue(classes *priorityClasses) *fairQueue {
+	return &fairQueue{
+		classes: classes,
+		finish:  make(map[string]float64),
+	}
+}
+
+// order returns the work sorted by the virtual start time, the work of each owner
+// keeps its order and ties keep the queue order
+func (f *fairQueue) order(works []*Workload) []*Workload {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	type tagged struct {
+		work  *Workload
+		start float64
+	}
+
+	next := make(map[string]float64)
+	queue := make([]tagged, len(works))
+	for i, work := range works {
+		owner := work.Owner()
+		start, ok := next[owner]
+		if !ok {
+			start = max(f.virtualTime, f.finish[owner])
+		}
+		_, weight := f.classes.resolve(work)
+		next[owner] = start + 1/float64(weight)
+		queue[i] = tagged{work: work, start: start}
+	}
+
+	sort.SliceStable(queue, func(i, j int) bool {
+		return queue[i].start < queue[j].start
+	})
+
+	ordered := make([]*Workload, len(queue))
+	for i, t := range queue {
+		ordered[i] = t.work
+	}
+	return ordered
+}
+
+// scheduled charges the owner of the work and advances the virtual time
+func (f *fairQueue) scheduled(work *Workload) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	owner := work.Owner()
+	_, weight := f.classes.resolve(work)
+
+	start := max(f.virtualTime, f.finish[owner])
+	f.finish[owner] = start + 1/float64(weight)
+	f.virtualTime = start
+
+	// Owners that have caught up with the virtual time start from it anyway
+	for owner, finish := range f.finish {
+		if finish <= f.virtualTime {
+			delete(f.finish, owner)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/model"
+	"github.com/helixml/helix/api/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriorityClasses_Resolve(t *testing.T) {
+	classes := newPriorityClasses(map[string]int{"high": 8, "normal": 4, "low": 1}, "normal")
+
+	for _, tc := range []struct {
+		name          string
+		priorityClass string
+		legacy        bool
+		expected      string
+	}{
+		{name: "explicit class", priorityClass: "low", expected: "low"},
+		{name: "default class", expected: "normal"},
+		{name: "unknown class", priorityClass: "urgent", expected: "normal"},
+		{name: "legacy priority", legacy: true, expected: "high"},
+		{name: "explicit class wins over legacy priority", priorityClass: "low", legacy: true, expected: "low"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			work := newTestSessionWorkload(t, "session", "owner", tc.priorityClass)
+			work.session.Metadata.Priority = tc.legacy
+
+			class, weight := classes.resolve(work)
+			assert.Equal(t, tc.expected, class)
+			assert.Equal(t, classes.weights[tc.expected], weight)
+		})
+	}
+}
+
+func TestFairQueue_OwnersShareTheQueue(t *testing.T) {
+	queue := newFairQueue(newPriorityClasses(map[string]int{"normal": 1}, "normal"))
+
+	// A busy owner queued a lot of work before anyone else
+	var works []*Workload
+	for _, id := range []string{"busy-1", "busy-2", "busy-3"} {
+		works = append(works, newTestSessionWorkload(t, id, "busy", ""))
+	}
+	works = append(works, newTestSessionWorkload(t, "quiet-1", "quiet", ""))
+
+	assert.Equal(t, []string{"busy-1", "quiet-1", "busy-2", "busy-3"}, workIDs(queue.order(works)))
+
+	// The busy owner used its share, so the quiet owner goes first next time
+	queue.scheduled(works[0])
+	assert.Equal(t, []string{"quiet-1", "busy-2", "busy-3"}, workIDs(queue.order(works[1:])))
+}
+
+func TestFairQueue_Weights(t *testing.T) {
+	queue := newFairQueue(newPriorityClasses(map[string]int{"high": 2, "low": 1}, "low"))
+
+	var works []*Workload
+	for _, id := range []string{"low-1", "low-2"} {
+		works = append(works, newTestSessionWorkload(t, id, "low-owner", "low"))
+	}
+	for _, id := range []string{"high-1", "high-2", "high-3"} {
+		works = append(works, newTestSessionWorkload(t, id, "high-owner", "high"))
+	}
+
+	// The high class gets twice the share of the low class
+	assert.Equal(t, []string{"low-1", "high-1", "high-2", "low-2", "high-3"}, workIDs(queue.order(works)))
+}
+
+func TestScheduler_PreemptFinetune(t *testing.T) {
+	config, _ := config.LoadServerConfig()
+	config.Providers.Helix.Preemption = true
+	scheduler := NewScheduler(&config)
+
+	var requeued []string
+	preempted := make(chan struct{})
+	scheduler.OnPreempted(func(session *types.Session) {
+		requeued = append(requeued, session.ID)
+		close(preempted)
+	})
+
+	m, _ := model.GetModel(model.Model_Axolotl_Mistral7b)
+	scheduler.UpdateRunner(&types.RunnerState{
+		ID:          "test-runner",
+		TotalMemory: m.GetMemoryRequirements(types.SessionModeFinetune),
+	})
+
+	// Low priority fine-tuning takes the whole runner
+	finetune := newTestSessionWorkload(t, "test-finetune", "owner-1", "low")
+	finetune.session.ModelName = model.Model_Axolotl_Mistral7b
+	finetune.session.Mode = types.SessionModeFinetune
+	require.NoError(t, scheduler.Schedule(finetune))
+	work, err := scheduler.WorkForRunner("test-runner", WorkloadTypeSession, false)
+	require.NoError(t, err)
+	require.NotNil(t, work)
+
+	// High priority inference preempts it
+	inference := newTestSessionWorkload(t, "test-inference", "owner-2", "high")
+	inference.session.ModelName = model.Model_Axolotl_Mistral7b
+	require.NoError(t, scheduler.Schedule(inference))
+	assert.Equal(t, []string{"test-finetune"}, inference.Decision().Preempted)
+	assert.Equal(t, "high", inference.Decision().PriorityClass)
+
+	// The runner is told to stop the fine-tuning once
+	assert.Equal(t, []string{"test-finetune"}, scheduler.PreemptedSessions("test-runner"))
+	assert.Empty(t, scheduler.PreemptedSessions("test-runner"))
+
+	select {
+	case <-preempted:
+	case <-time.After(time.Second):
+		t.Fatal("preempted session wasn't queued again")
+	}
+	assert.Equal(t, []string{"test-finetune"}, requeued)
+}
+
+func TestScheduler_NoPreemptionOfHigherPriority(t *testing.T) {
+	config, _ := config.LoadServerConfig()
+	config.Providers.Helix.Preemption = true
+	scheduler := NewScheduler(&config)
+
+	m, _ := model.GetModel(model.Model_Axolotl_Mistral7b)
+	scheduler.UpdateRunner(&types.RunnerState{
+		ID:          "test-runner",
+		TotalMemory: m.GetMemoryRequirements(types.SessionModeFinetune),
+	})
+
+	finetune := newTestSessionWorkload(t, "test-finetune", "owner-1", "high")
+	finetune.session.ModelName = model.Model_Axolotl_Mistral7b
+	finetune.session.Mode = types.SessionModeFinetune
+	require.NoError(t, scheduler.Schedule(finetune))
+
+	inference := newTestSessionWorkload(t, "test-inference", "owner-2", "low")
+	inference.session.ModelName = model.Model_Axolotl_Mistral7b
+	err := scheduler.Schedule(inference)
+	assert.ErrorIs(t, err, ErrRunnersAreFull)
+	assert.Empty(t, scheduler.PreemptedSessions("test-runner"))
+}
+
+func newTestSessionWorkload(t *testing.T, id, owner, priorityClass string) *Workload {
+	work, err := NewSessonWorkload(&types.Session{
+		ID:        id,
+		Owner:     owner,
+		ModelName: model.Model_Ollama_Llama3_8b,
+		Mode:      types.SessionModeInference,
+		Metadata: types.SessionMetadata{
+			PriorityClass: priorityClass,
+		},
+	})
+	require.NoError(t, err)
+	return work
+}
+
+func workIDs(works []*Workload) []string {
+	var ids []string
+	for _, work := range works {
+		ids = append(ids, work.ID())
+	}
+	return ids
+}
@@ -1,13 +1,16 @@
 package scheduler
 
 import (
+	"errors"
 	"fmt"
 	"math/rand"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/helixml/helix/api/pkg/config"
 	"github.com/helixml/helix/api/pkg/model"
+	"github.com/helixml/helix/api/pkg/system"
 	"github.com/helixml/helix/api/pkg/types"
 	"github.com/puzpuzpuz/xsync/v3"
 	"github.com/rs/zerolog/log"
@@ -21,6 +24,8 @@ type Scheduler interface {
 	Release(id string) error
 	WorkForRunner(id string, workType WorkloadType, newWorkOnly bool) (*Workload, error)
 	UpdateRunner(props *types.RunnerState)
+	Prioritize(works []*Workload) []*Workload
+	PreemptedSessions(runnerID string) []string
 }
 
 // scheduler is a struct implementing the Scheduler interface.
@@ -30,6 +35,14 @@ type scheduler struct {
 	workStore         *xsync.MapOf[uuid.UUID, *Workload] // Map to store the work associated with a slot.
 	cluster           Cluster                            // Cluster to manage runner state.
 	placementStrategy SchedulingStrategyFunc
+	stateStore        StateStore                    // Persists the slots if set, see Restore.
+	stateWriter       *system.SerialWorker          // Writes to the state store in the background.
+	restoredSlots     *xsync.MapOf[uuid.UUID, bool] // Running slots restored from the state store, not yet seen on their runner.
+	priorityClasses   *priorityClasses
+	fairQueue         *fairQueue
+	preemption        bool // Preempt lower priority fine-tuning when inference can't be placed.
+	preemptions       preemptions
+	onPreempted       PreemptedFunc
 }
 
 var _ Scheduler = &scheduler{}
@@ -55,11 +68,20 @@ func NewScheduler(cfg *config.ServerConfig) *scheduler {
 	default:
 		log.Warn().Str("strategy", cfg.Providers.Helix.SchedulingStrategy).Msg("unknown scheduling strategy, defaulting to max utilization")
 	}
+	priorityClasses := newPriorityClasses(
+		cfg.Providers.Helix.PriorityClasses,
+		cfg.Providers.Helix.DefaultPriorityClass,
+	)
+
 	scheduler := &scheduler{
 		allocator:         allocator,
 		cluster:           cluster,
 		workStore:         xsync.NewMapOf[uuid.UUID, *Workload](),
 		placementStrategy: schedStratFunc, // TODO: Make this configurable.
+		restoredSlots:     xsync.NewMapOf[uuid.UUID, bool](),
+		priorityClasses:   priorityClasses,
+		fairQueue:         newFairQueue(priorityClasses),
+		preemption:        cfg.Providers.Helix.Preemption,
 	}
 
 	// Start a goroutine to log the current state of the scheduler.
@@ -99,6 +121,9 @@ func (s *scheduler) Schedule(work *Workload) (err error) {
 
 	var slot *Slot // Holds the slot where the work will be scheduled.
 
+	priorityClass, _ := s.priorityClasses.resolve(work)
+	work.decision = Decision{PriorityClass: priorityClass}
+
 	// Try to find warm slots, which are ready to take new work.
 	slots := s.allocator.WarmSlots(work)
 	log.Trace().
@@ -117,9 +142,15 @@ func (s *scheduler) Schedule(work *Workload) (err error) {
 			// Return error if unable to allocate work to the warm model.
 			return fmt.Errorf("unable to allocate work to a warm model: %w", err)
 		}
+		work.decision.Reason = "warm slot"
 	} else {
 		// If no warm slots are available, pick a runner to allocate a slot to.
+		work.decision.Reason = "new slot"
 		bestRunnerID, err := s.placementStrategy(s.cluster, s.allocator, work)
+		if err != nil && s.preemption && errors.Is(err, ErrRunnersAreFull) {
+			// Make room by preempting lower priority fine-tuning.
+			bestRunnerID, err = s.preempt(work, err)
+		}
 		if err != nil {
 			return fmt.Errorf("unable to place work on any runner: %w", err)
 		}
@@ -139,10 +170,19 @@ func (s *scheduler) Schedule(work *Workload) (err error) {
 	}
 
 	s.workStore.Store(slot.ID, work)
+	s.saveSlotState(slot)
+	s.fairQueue.scheduled(work)
 
 	return nil
 }
 
+// Prioritize orders the queued work so that the owners get their fair share of
+// the runners, weighted by the priority classes of their work. The queues should
+// schedule the work in this order.
+func (s *scheduler) Prioritize(works []*Workload) []*Workload {
+	return s.fairQueue.order(works)
+}
+
 // Release frees the resources associated with a specific scheduled request.
 // It finds the request by its ID, releases the allocated slot, and removes the associated work from the store.
 func (s *scheduler) Release(id string) error {
@@ -163,6 +203,10 @@ func (s *scheduler) Release(id string) error {
 	// Remove the work associated with the slot from the store.
 	s.workStore.Delete(slotID)
 
+	if slot, ok := s.allocator.Slot(slotID); ok {
+		s.saveSlotState(slot)
+	}
+
 	return nil
 }
 
@@ -173,8 +217,10 @@ func (s *scheduler) WorkForRunner(id string, workType WorkloadType, newWorkOnly
 	// Before retrieving work, check for dead runners and attempt to reschedule their work.
 	deadSlots := s.allocator.DeadSlots(s.cluster.DeadRunnerIDs())
 	for _, dead := range deadSlots {
+		s.deleteSlotState(dead.ID.String())
+
 		// Get work associated with the dead slot.
-		work, ok := s.workStore.Load(dead.ID)
+		work, ok := s.workStore.LoadAndDelete(dead.ID)
 		if !ok {
 			continue // Work not owned by this scheduler, ignore it.
 		}
@@ -210,6 +256,7 @@ func (s *scheduler) WorkForRunner(id string, workType WorkloadType, newWorkOnly
 				continue // Work is not new, ignore it.
 			}
 			slot.Start() // Mark the work in the slot as started.
+			s.saveSlotState(slot)
 			return work, nil
 		}
 	}
@@ -221,8 +268,18 @@ func (s *scheduler) WorkForRunner(id string, workType WorkloadType, newWorkOnly
 func (s *scheduler) UpdateRunner(props *types.RunnerState) {
 	// Update the runner's state in the cluster.
 	s.cluster.UpdateRunner(props)
+	// Check the slots restored after a restart against the runner's models.
+	s.reconcileRestoredSlots(props)
 	// Reconcile the runner's slots with the allocator's records.
+	before := s.allocator.RunnerSlots(props.ID)
 	s.allocator.ReconcileSlots(props)
+	// Forget the slots that the allocator deleted.
+	after := s.allocator.RunnerSlots(props.ID)
+	for _, slot := range before {
+		if !slices.Contains(after, slot) {
+			s.deleteSlotState(slot.ID.String())
+		}
+	}
 }
 
 // find searches for the slot ID associated with a given workload ID.
@@ -0,0 +1,240 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+	"github.com/rs/zerolog/log"
+)
+
+// StateStore persists the slots so that the scheduler can restore them after a
+// restart of the control plane, implemented by the store
+type StateStore interface {
+	ListSchedulerSlots(ctx context.Context) ([]*types.SchedulerSlot, error)
+	SaveSchedulerSlot(ctx context.Context, slot *types.SchedulerSlot) error
+	DeleteSchedulerSlot(ctx context.Context, id string) error
+}
+
+// InterruptedFunc is called for the session LLM inference requests that were
+// scheduled or running when the control plane restarted, their clients were
+// waiting in the previous process so the requests are failed in their sessions
+// rather than re-queued
+type InterruptedFunc func(req *types.RunnerLLMInferenceRequest, reason string)
+
+const (
+	stateStoreTimeout = 5 * time.Second
+
+	interruptedReason = "request interrupted by a restart of the control plane"
+)
+
+// Restore loads the persisted slots, from now on every slot change is saved to
+// the store. Sessions that were running are restored and checked when their
+// runner reports its models, sessions that were only scheduled are dropped as
+// the controller re-queues them. LLM inference requests of sessions are failed
+// with onInterrupted, the other ones are dropped as nobody waits for them.
+func (s *scheduler) Restore(ctx context.Context, store StateStore, onInterrupted InterruptedFunc) error {
+	records, err := store.ListSchedulerSlots(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing scheduler slots: %w", err)
+	}
+
+	s.stateStore = store
+	s.stateWriter = system.NewSerialWorker()
+
+	runnerIDs := make(map[string]bool)
+	for _, record := range records {
+		id, work, err := restoreWork(record)
+		if err != nil {
+			log.Warn().Err(err).Str("slot_id", record.ID).Msg("dropping scheduler slot that can't be restored")
+			s.deleteSlotState(record.ID)
+			continue
+		}
+
+		if record.State != types.SchedulerSlotStateIdle && work.WorkloadType == WorkloadTypeLLMInferenceRequest {
+			req := work.LLMInferenceRequest()
+			// Only the requests of sessions have someone to read the failure
+			if req.SessionID != "" && req.InteractionID != "" && onInterrupted != nil {
+				log.Info().
+					Str("slot_id", record.ID).
+					Str("request_id", work.ID()).
+					Msg("failing interrupted LLM inference request")
+				onInterrupted(req, interruptedReason)
+			} else {
+				log.Info().
+					Str("slot_id", record.ID).
+					Str("request_id", work.ID()).
+					Msg("dropping interrupted LLM inference request")
+			}
+		}
+
+		running := record.State == types.SchedulerSlotStateRunning && work.WorkloadType == WorkloadTypeSession
+		if record.IsNew && !running {
+			// The model was never loaded
+			s.deleteSlotState(record.ID)
+			continue
+		}
+
+		slot := s.allocator.RestoreSlot(id, record.RunnerID, work)
+		if running {
+			// The runner might still be working on it, see reconcileRestoredSlots
+			slot.Start()
+			s.workStore.Store(slot.ID, work)
+			s.restoredSlots.Store(slot.ID, true)
+		}
+		s.saveSlotState(slot)
+		runnerIDs[slot.RunnerID] = true
+	}
+
+	// Track the runners so that their slots are rescheduled if they never
+	// report back. They have no memory until then so no new slots are placed
+	// on them.
+	for runnerID := range runnerIDs {
+		s.cluster.UpdateRunner(&types.RunnerState{ID: runnerID})
+	}
+
+	log.Info().
+		Int("num_slots", len(records)).
+		Int("num_runners", len(runnerIDs)).
+		Msg("restored scheduler state")
+
+	return nil
+}
+
+func restoreWork(record *types.SchedulerSlot) (uuid.UUID, *Workload, error) {
+	id, err := uuid.Parse(record.ID)
+	if err != nil {
+		return uuid.Nil, nil, fmt.Errorf("invalid slot id: %w", err)
+	}
+
+	var work *Workload
+	switch {
+	case record.Work.LLMInferenceRequest != nil:
+		work, err = NewLLMWorkload(record.Work.LLMInferenceRequest)
+	case record.Work.Session != nil:
+		work, err = NewSessonWorkload(record.Work.Session)
+	default:
+		err = fmt.Errorf("slot has no work")
+	}
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+
+	return id, work, nil
+}
+
+// reconcileRestoredSlots checks the running slots restored from the store when
+// their runner reports for the first time. If the runner doesn't have the model
+// any more the session was lost and gets rescheduled.
+func (s *scheduler) reconcileRestoredSlots(props *types.RunnerState) {
+	for _, slot := range s.allocator.RunnerSlots(props.ID) {
+		if _, ok := s.restoredSlots.LoadAndDelete(slot.ID); !ok {
+			continue
+		}
+
+		if !slot.IsActive() || hasModelInstance(props, slot) {
+			continue
+		}
+
+		work, ok := s.workStore.LoadAndDelete(slot.ID)
+		slot.Release()
+		s.saveSlotState(slot)
+		if !ok {
+			continue
+		}
+
+		log.Info().
+			Str("runner_id", props.ID).
+			Str("slot_id", slot.ID.String()).
+			Str("work_id", work.ID()).
+			Msg("rescheduling work lost by the runner")
+		err := s.Schedule(work)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("runner_id", props.ID).
+				Str("work_id", work.ID()).
+				Msg("failed to reschedule work lost by the runner")
+		}
+	}
+}
+
+func hasModelInstance(props *types.RunnerState, slot *Slot) bool {
+	for _, m := range props.ModelInstances {
+		if m.ModelName == slot.ModelName().String() && m.Mode == slot.Mode() && m.LoraDir == slot.LoraDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// saveSlotState persists the slot in the background, errors are only logged as
+// the in-memory state is the source of truth while the control plane runs
+func (s *scheduler) saveSlotState(slot *Slot) {
+	if s.stateStore == nil {
+		return
+	}
+
+	work, _ := s.workStore.Load(slot.ID)
+	record := slotRecord(slot, work)
+
+	s.stateWriter.Submit(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), stateStoreTimeout)
+		defer cancel()
+
+		err := s.stateStore.SaveSchedulerSlot(ctx, record)
+		if err != nil {
+			log.Error().Err(err).Str("slot_id", record.ID).Msg("failed to save scheduler slot")
+		}
+	})
+}
+
+func (s *scheduler) deleteSlotState(id string) {
+	if s.stateStore == nil {
+		return
+	}
+
+	s.stateWriter.Submit(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), stateStoreTimeout)
+		defer cancel()
+
+		err := s.stateStore.DeleteSchedulerSlot(ctx, id)
+		if err != nil {
+			log.Error().Err(err).Str("slot_id", id).Msg("failed to delete scheduler slot")
+		}
+	})
+}
+
+// slotRecord is the persisted state of the slot, the work is the current work
+// or the last one if the slot is idle
+func slotRecord(slot *Slot, work *Workload) *types.SchedulerSlot {
+	slot.mu.RLock()
+	defer slot.mu.RUnlock()
+
+	record := &types.SchedulerSlot{
+		ID:       slot.ID.String(),
+		RunnerID: slot.RunnerID,
+		State:    types.SchedulerSlotStateIdle,
+		IsNew:    slot.isNew,
+	}
+
+	switch {
+	case slot.isActive:
+		record.State = types.SchedulerSlotStateRunning
+	case slot.isScheduled:
+		record.State = types.SchedulerSlotStateScheduled
+	}
+
+	if work == nil {
+		work = slot.work
+	}
+	record.Work = types.SchedulerSlotWork{
+		LLMInferenceRequest: work.llmInfereceRequest,
+		Session:             work.session,
+	}
+
+	return record
+}
@@ -0,0 +1,144 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/model"
+	"github.com/helixml/helix/api/pkg/types"
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStateStore struct {
+	mu    sync.Mutex
+	slots map[string]*types.SchedulerSlot
+}
+
+func newMemoryStateStore() *memoryStateStore {
+	return &memoryStateStore{slots: make(map[string]*types.SchedulerSlot)}
+}
+
+func (m *memoryStateStore) ListSchedulerSlots(_ context.Context) ([]*types.SchedulerSlot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var slots []*types.SchedulerSlot
+	for _, slot := range m.slots {
+		slots = append(slots, slot)
+	}
+	return slots, nil
+}
+
+func (m *memoryStateStore) SaveSchedulerSlot(_ context.Context, slot *types.SchedulerSlot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.slots[slot.ID] = slot
+	return nil
+}
+
+func (m *memoryStateStore) DeleteSchedulerSlot(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.slots, id)
+	return nil
+}
+
+// restartedScheduler schedules a running session and two scheduled LLM requests
+// and returns a new scheduler restored from the same store
+func restartedScheduler(t *testing.T) (*scheduler, []string) {
+	config, _ := config.LoadServerConfig()
+	store := newMemoryStateStore()
+	m, _ := model.GetModel(model.Model_Ollama_Llama3_8b)
+
+	before := NewScheduler(&config)
+	err := before.Restore(context.Background(), store, nil)
+	require.NoError(t, err)
+
+	before.UpdateRunner(&types.RunnerState{
+		ID:          "test-runner",
+		TotalMemory: m.GetMemoryRequirements(types.SessionModeInference) * 3,
+	})
+
+	err = createTestSession(before, "test-session-1", model.Model_Ollama_Llama3_8b, "")
+	require.NoError(t, err)
+	work, err := before.WorkForRunner("test-runner", WorkloadTypeSession, false)
+	require.NoError(t, err)
+	require.NotNil(t, work)
+
+	// Nobody waits for the requests without a session after the restart
+	err = createTestWork(before, "test-request-1", model.Model_Ollama_Llama3_8b)
+	require.NoError(t, err)
+	work, err = NewLLMWorkload(&types.RunnerLLMInferenceRequest{
+		RequestID:     "test-request-2",
+		SessionID:     "test-session-2",
+		InteractionID: "test-interaction-2",
+		Request: &openai.ChatCompletionRequest{
+			Model: model.Model_Ollama_Llama3_8b,
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, before.Schedule(work))
+	before.stateWriter.Flush()
+	require.Len(t, store.slots, 3)
+
+	var interrupted []string
+	after := NewScheduler(&config)
+	err = after.Restore(context.Background(), store, func(req *types.RunnerLLMInferenceRequest, reason string) {
+		interrupted = append(interrupted, req.RequestID)
+	})
+	require.NoError(t, err)
+
+	return after, interrupted
+}
+
+func TestScheduler_RestoreState(t *testing.T) {
+	scheduler, interrupted := restartedScheduler(t)
+
+	// The client of the session LLM request was waiting in the previous process
+	assert.Equal(t, []string{"test-request-2"}, interrupted)
+
+	// The new slots of the LLM requests never loaded the model so only the
+	// session's slot is restored
+	slots := scheduler.allocator.RunnerSlots("test-runner")
+	require.Len(t, slots, 1)
+	assert.True(t, slots[0].IsActive())
+	assert.Equal(t, []string{"test-runner"}, scheduler.cluster.RunnerIDs())
+
+	// The runner finishes the session after the restart
+	m, _ := model.GetModel(model.Model_Ollama_Llama3_8b)
+	scheduler.UpdateRunner(&types.RunnerState{
+		ID:          "test-runner",
+		TotalMemory: m.GetMemoryRequirements(types.SessionModeInference) * 2,
+		ModelInstances: []*types.ModelInstanceState{
+			{
+				ModelName: model.Model_Ollama_Llama3_8b,
+				Mode:      types.SessionModeInference,
+			},
+		},
+	})
+	err := scheduler.Release("test-session-1")
+	assert.NoError(t, err)
+	assert.False(t, slots[0].IsActive())
+}
+
+func TestScheduler_RestoreState_RunnerLostWork(t *testing.T) {
+	scheduler, _ := restartedScheduler(t)
+
+	// The runner restarted too and doesn't have the model any more
+	m, _ := model.GetModel(model.Model_Ollama_Llama3_8b)
+	scheduler.UpdateRunner(&types.RunnerState{
+		ID:          "test-runner",
+		TotalMemory: m.GetMemoryRequirements(types.SessionModeInference) * 2,
+	})
+
+	work, err := scheduler.WorkForRunner("test-runner", WorkloadTypeSession, false)
+	assert.NoError(t, err)
+	require.NotNil(t, work)
+	assert.Equal(t, "test-session-1", work.ID())
+}
@@ -18,6 +18,14 @@ type Workload struct {
 	WorkloadType       WorkloadType
 	llmInfereceRequest *types.RunnerLLMInferenceRequest
 	session            *types.Session
+	decision           Decision
+}
+
+// Decision describes how the scheduler placed the work, it is shown on the dashboard
+type Decision struct {
+	PriorityClass string
+	Reason        string
+	Preempted     []string // IDs of the work preempted to make room
 }
 
 func NewLLMWorkload(work *types.RunnerLLMInferenceRequest) (*Workload, error) {
@@ -58,7 +66,7 @@ func (w *Workload) ID() string {
 func (w *Workload) ModelName() model.ModelName {
 	switch w.WorkloadType {
 	case WorkloadTypeLLMInferenceRequest:
-		return model.ModelName(w.llmInfereceRequest.Request.Model)
+		return model.ModelName(w.llmInfereceRequest.ModelName())
 	case WorkloadTypeSession:
 		return model.ModelName(w.session.ModelName)
 	}
@@ -106,3 +114,29 @@ func (w *Workload) LoraDir() string {
 	}
 	panic(fmt.Sprintf("unknown workload type: %s", w.WorkloadType))
 }
+
+func (w *Workload) Owner() string {
+	switch w.WorkloadType {
+	case WorkloadTypeLLMInferenceRequest:
+		return w.llmInfereceRequest.OwnerID
+	case WorkloadTypeSession:
+		return w.session.Owner
+	}
+	panic(fmt.Sprintf("unknown workload type: %s", w.WorkloadType))
+}
+
+// PriorityClass returns the requested priority class and the legacy priority flag
+func (w *Workload) PriorityClass() (string, bool) {
+	switch w.WorkloadType {
+	case WorkloadTypeLLMInferenceRequest:
+		return w.llmInfereceRequest.PriorityClass, w.llmInfereceRequest.Priority
+	case WorkloadTypeSession:
+		return w.session.Metadata.PriorityClass, w.session.Metadata.Priority
+	}
+	panic(fmt.Sprintf("unknown workload type: %s", w.WorkloadType))
+}
+
+// Decision returns how the work was last scheduled
+func (w *Workload) Decision() Decision {
+	return w.decision
+}
@@ -0,0 +1,370 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	openai "github.com/sashabaranov/go-openai"
+
+	oai "github.com/helixml/helix/api/pkg/openai"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+const (
+	rateLimitWindow = time.Minute
+	// lastUsedInterval limits how often the last used time of a key is written
+	lastUsedInterval = time.Minute
+)
+
+// apiKeyScopeRule lists the scopes that allow the requests to the path and
+// everything under it, GET requests need one of the read scopes, the other
+// methods one of the write scopes
+type apiKeyScopeRule struct {
+	path  string
+	read  []types.APIKeyScope
+	write []types.APIKeyScope
+}
+
+// apiKeyScopeRules are matched in order, the paths that don't match any rule
+// can't be used with scoped keys
+var apiKeyScopeRules = []apiKeyScopeRule{
+	{
+		path:  "/v1/chat/completions",
+		write: []types.APIKeyScope{types.APIKeyScopeChat},
+	},
+	{
+		path:  "/openai/deployments",
+		write: []types.APIKeyScope{types.APIKeyScopeChat},
+	},
+	{
+		path:  "/v1/embeddings",
+		write: []types.APIKeyScope{types.APIKeyScopeChat},
+	},
+	{
+		path: "/v1/models",
+		read: []types.APIKeyScope{types.APIKeyScopeChat},
+	},
+	{
+		path:  "/api/v1/sessions/chat",
+		write: []types.APIKeyScope{types.APIKeyScopeChat},
+	},
+	{
+		path: "/api/v1/sessions",
+		read: []types.APIKeyScope{types.APIKeyScopeSessionsRead},
+	},
+	{
+		path:  "/api/v1/knowledge",
+		read:  []types.APIKeyScope{types.APIKeyScopeKnowledgeRead, types.APIKeyScopeKnowledgeAdmin},
+		write: []types.APIKeyScope{types.APIKeyScopeKnowledgeAdmin},
+	},
+	{
+		path:  "/api/v1/apps",
+		read:  []types.APIKeyScope{types.APIKeyScopeAppsRead, types.APIKeyScopeAppsAdmin},
+		write: []types.APIKeyScope{types.APIKeyScopeAppsAdmin},
+	},
+}
+
+// apiKeyAllowsRequest checks the scopes of the key against the request
+func apiKeyAllowsRequest(apiKey *types.APIKey, method, path string) bool {
+	if len(apiKey.Scopes) == 0 {
+		return true
+	}
+
+	for _, rule := range apiKeyScopeRules {
+		if path != rule.path && !strings.HasPrefix(path, rule.path+"/") {
+			continue
+		}
+
+		scopes := rule.write
+		if method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions {
+			scopes = rule.read
+		}
+
+		for _, scope := range scopes {
+			if apiKey.HasScope(scope) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return false
+}
+
+// apiKeyAllowsIP checks the client address against the allowlist of the key
+func apiKeyAllowsIP(apiKey *types.APIKey, remoteAddr string) bool {
+	if len(apiKey.AllowedIPs) == 0 {
+		return true
+	}
+
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, allowed := range apiKey.AllowedIPs {
+		if strings.Contains(allowed, "/") {
+			_, network, err := net.ParseCIDR(allowed)
+			if err == nil && network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+
+		if allowedIP := net.ParseIP(allowed); allowedIP != nil && allowedIP.Equal(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseTrustedProxies parses the IP addresses and CIDR ranges of the proxies in
+// front of the API server, invalid entries are logged and ignored
+func parseTrustedProxies(proxies []string) []*net.IPNet {
+	var networks []*net.IPNet
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				log.Warn().Str("proxy", proxy).Msg("ignoring invalid trusted proxy")
+				continue
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			log.Warn().Str("proxy", proxy).Msg("ignoring invalid trusted proxy")
+			continue
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// clientAddr returns the address of the client that made the request. For the
+// requests of trusted proxies X-Forwarded-For is read from the right, the first
+// address that isn't a trusted proxy is the client as the addresses left of it
+// could have been set by the client itself.
+func clientAddr(r *http.Request, trustedProxies []*net.IPNet) string {
+	addr, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		addr = r.RemoteAddr
+	}
+
+	if !isTrustedProxy(addr, trustedProxies) {
+		return addr
+	}
+
+	var forwarded []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(header, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				forwarded = append(forwarded, hop)
+			}
+		}
+	}
+
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		addr = forwarded[i]
+		if !isTrustedProxy(addr, trustedProxies) {
+			break
+		}
+	}
+
+	return addr
+}
+
+func isTrustedProxy(addr string, trustedProxies []*net.IPNet) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateAPIKeyRestrictions checks the scopes, expiry, allowlist and rate limits of a new key
+func validateAPIKeyRestrictions(apiKey *types.APIKey) error {
+	for _, scope := range apiKey.Scopes {
+		if !slices.Contains(types.APIKeyScopes, scope) {
+			return fmt.Errorf("unknown scope '%s'", scope)
+		}
+	}
+
+	if apiKey.ExpiresAt != nil && !apiKey.ExpiresAt.After(time.Now()) {
+		return fmt.Errorf("expiry must be in the future")
+	}
+
+	for _, allowed := range apiKey.AllowedIPs {
+		if strings.Contains(allowed, "/") {
+			_, _, err := net.ParseCIDR(allowed)
+			if err != nil {
+				return fmt.Errorf("invalid CIDR range '%s'", allowed)
+			}
+			continue
+		}
+
+		if net.ParseIP(allowed) == nil {
+			return fmt.Errorf("invalid IP address '%s'", allowed)
+		}
+	}
+
+	if apiKey.RequestsPerMinute < 0 || apiKey.TokensPerMinute < 0 {
+		return fmt.Errorf("rate limits must not be negative")
+	}
+
+	return nil
+}
+
+// apiKeyLimiter enforces the rate limits of the keys in one minute windows.
+// The counters are kept in memory, so the limits apply to each API server replica.
+type apiKeyLimiter struct {
+	mu      sync.Mutex
+	windows map[string]*apiKeyWindow // API key -> current window
+	now     func() time.Time
+}
+
+type apiKeyWindow struct {
+	start    time.Time
+	requests int
+	tokens   int
+}
+
+func newAPIKeyLimiter() *apiKeyLimiter {
+	return &apiKeyLimiter{
+		windows: make(map[string]*apiKeyWindow),
+		now:     time.Now,
+	}
+}
+
+// window returns the current window of the key, the caller must hold the lock
+func (l *apiKeyLimiter) window(key string) *apiKeyWindow {
+	now := l.now()
+
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= rateLimitWindow {
+		w = &apiKeyWindow{start: now}
+		l.windows[key] = w
+	}
+
+	return w
+}
+
+// allow counts the request against the limits of the key, when the key is
+// over its limits it returns how long until the next window
+func (l *apiKeyLimiter) allow(apiKey *types.APIKey) (time.Duration, bool) {
+	if apiKey.RequestsPerMinute == 0 && apiKey.TokensPerMinute == 0 {
+		return 0, true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	w := l.window(apiKey.Key)
+
+	if (apiKey.RequestsPerMinute > 0 && w.requests >= apiKey.RequestsPerMinute) ||
+		(apiKey.TokensPerMinute > 0 && w.tokens >= apiKey.TokensPerMinute) {
+		return w.start.Add(rateLimitWindow).Sub(l.now()), false
+	}
+
+	w.requests++
+
+	return 0, true
+}
+
+// addTokens counts the tokens used by a request of the key, the requests are
+// rejected once the tokens of the window are used up
+func (l *apiKeyLimiter) addTokens(apiKey *types.APIKey, tokens int) {
+	if apiKey.TokensPerMinute == 0 || tokens <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.window(apiKey.Key).tokens += tokens
+}
+
+// authorizeAPIKey enforces the scopes, the IP allowlist and the rate limits of
+// the API key the request was made with, the error response is written if the
+// request is not allowed
+func (auth *authMiddleware) authorizeAPIKey(w http.ResponseWriter, r *http.Request, apiKey *types.APIKey) bool {
+	if !apiKeyAllowsRequest(apiKey, r.Method, r.URL.Path) {
+		http.Error(w, "path not allowed for the API key scopes", http.StatusForbidden)
+		return false
+	}
+
+	if !apiKeyAllowsIP(apiKey, clientAddr(r, auth.trustedProxies)) {
+		http.Error(w, "IP address not allowed for the API key", http.StatusForbidden)
+		return false
+	}
+
+	retryAfter, ok := auth.apiKeyLimiter.allow(apiKey)
+	if !ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Round(time.Second).Seconds())))
+		http.Error(w, "API key rate limit exceeded", http.StatusTooManyRequests)
+		return false
+	}
+
+	auth.touchAPIKey(r.Context(), apiKey)
+
+	return true
+}
+
+// touchAPIKey records when the key was last used, at most once a minute
+func (auth *authMiddleware) touchAPIKey(ctx context.Context, apiKey *types.APIKey) {
+	now := time.Now()
+	if apiKey.LastUsed != nil && now.Sub(*apiKey.LastUsed) < lastUsedInterval {
+		return
+	}
+
+	err := auth.store.UpdateAPIKeyLastUsed(ctx, apiKey.Key, now)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to update API key last used time")
+		return
+	}
+	apiKey.LastUsed = &now
+}
+
+// withAPIKeyUsage counts the tokens of the LLM calls made with the context
+// against the rate limit of the API key, the calls report their usage even
+// when the streaming clients didn't ask for it
+func (s *HelixAPIServer) withAPIKeyUsage(ctx context.Context, user *types.User) context.Context {
+	if user.APIKey == nil || s.authMiddleware == nil {
+		return ctx
+	}
+
+	return oai.SetUsageRecorder(ctx, func(usage openai.Usage) {
+		s.authMiddleware.apiKeyLimiter.addTokens(user.APIKey, usage.TotalTokens)
+	})
+}
@@ -0,0 +1,179 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func TestAPIKeyAllowsRequest(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		scopes types.APIKeyScopeList
+		method string
+		path   string
+		want   bool
+	}{
+		{name: "no scopes", method: http.MethodDelete, path: "/api/v1/apps/app_1", want: true},
+		{name: "chat completions", scopes: types.APIKeyScopeList{types.APIKeyScopeChat}, method: http.MethodPost, path: "/v1/chat/completions", want: true},
+		{name: "models", scopes: types.APIKeyScopeList{types.APIKeyScopeChat}, method: http.MethodGet, path: "/v1/models", want: true},
+		{name: "sessions chat", scopes: types.APIKeyScopeList{types.APIKeyScopeChat}, method: http.MethodPost, path: "/api/v1/sessions/chat", want: true},
+		{name: "sessions chat without chat scope", scopes: types.APIKeyScopeList{types.APIKeyScopeSessionsRead}, method: http.MethodPost, path: "/api/v1/sessions/chat", want: false},
+		{name: "read sessions", scopes: types.APIKeyScopeList{types.APIKeyScopeSessionsRead}, method: http.MethodGet, path: "/api/v1/sessions/ses_1", want: true},
+		{name: "delete session", scopes: types.APIKeyScopeList{types.APIKeyScopeSessionsRead}, method: http.MethodDelete, path: "/api/v1/sessions/ses_1", want: false},
+		{name: "read knowledge as admin", scopes: types.APIKeyScopeList{types.APIKeyScopeKnowledgeAdmin}, method: http.MethodGet, path: "/api/v1/knowledge", want: true},
+		{name: "refresh knowledge", scopes: types.APIKeyScopeList{types.APIKeyScopeKnowledgeRead}, method: http.MethodPost, path: "/api/v1/knowledge/k_1/refresh", want: false},
+		{name: "update app", scopes: types.APIKeyScopeList{types.APIKeyScopeAppsAdmin}, method: http.MethodPut, path: "/api/v1/apps/app_1", want: true},
+		{name: "path prefix only", scopes: types.APIKeyScopeList{types.APIKeyScopeAppsRead}, method: http.MethodGet, path: "/api/v1/appsx", want: false},
+		{name: "unknown path", scopes: types.APIKeyScopeList{types.APIKeyScopeAppsAdmin}, method: http.MethodPost, path: "/api/v1/api_keys", want: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			apiKey := &types.APIKey{Scopes: tc.scopes}
+			assert.Equal(t, tc.want, apiKeyAllowsRequest(apiKey, tc.method, tc.path))
+		})
+	}
+}
+
+func TestAPIKeyAllowsIP(t *testing.T) {
+	apiKey := &types.APIKey{AllowedIPs: types.StringList{"10.0.0.0/8", "192.168.1.10", "2001:db8::/32"}}
+
+	assert.True(t, apiKeyAllowsIP(apiKey, "10.1.2.3:5000"))
+	assert.True(t, apiKeyAllowsIP(apiKey, "192.168.1.10:5000"))
+	assert.True(t, apiKeyAllowsIP(apiKey, "[2001:db8::1]:5000"))
+	assert.False(t, apiKeyAllowsIP(apiKey, "192.168.1.11:5000"))
+	assert.False(t, apiKeyAllowsIP(apiKey, "invalid"))
+
+	assert.True(t, apiKeyAllowsIP(&types.APIKey{}, "192.168.1.11:5000"))
+}
+
+func TestClientAddr(t *testing.T) {
+	trustedProxies := parseTrustedProxies([]string{"10.0.0.1", "172.16.0.0/12", "invalid"})
+	require.Len(t, trustedProxies, 2)
+
+	newRequest := func(remoteAddr string, forwardedFor ...string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/v1/models", http.NoBody)
+		req.RemoteAddr = remoteAddr
+		for _, header := range forwardedFor {
+			req.Header.Add("X-Forwarded-For", header)
+		}
+		return req
+	}
+
+	// Direct requests can't set their address
+	assert.Equal(t, "192.168.1.10", clientAddr(newRequest("192.168.1.10:5000", "10.1.2.3"), trustedProxies))
+
+	assert.Equal(t, "10.1.2.3", clientAddr(newRequest("10.0.0.1:5000", "10.1.2.3"), trustedProxies))
+	assert.Equal(t, "2001:db8::1", clientAddr(newRequest("10.0.0.1:5000", "2001:db8::1"), trustedProxies))
+
+	// Only the hops added by the trusted proxies are skipped, the client can
+	// prepend anything
+	assert.Equal(t, "192.168.1.10", clientAddr(newRequest("10.0.0.1:5000", "10.1.2.3, 192.168.1.10, 172.16.0.5"), trustedProxies))
+	assert.Equal(t, "192.168.1.10", clientAddr(newRequest("10.0.0.1:5000", "10.1.2.3", "192.168.1.10"), trustedProxies))
+
+	// Without the header the proxy is the client
+	assert.Equal(t, "10.0.0.1", clientAddr(newRequest("10.0.0.1:5000"), trustedProxies))
+	assert.Equal(t, "10.0.0.1", clientAddr(newRequest("10.0.0.1:5000"), nil))
+}
+
+func TestValidateAPIKeyRestrictions(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+
+	assert.NoError(t, validateAPIKeyRestrictions(&types.APIKey{
+		Scopes:     types.APIKeyScopeList{types.APIKeyScopeChat},
+		AllowedIPs: types.StringList{"10.0.0.0/8", "::1"},
+	}))
+	assert.ErrorContains(t, validateAPIKeyRestrictions(&types.APIKey{Scopes: types.APIKeyScopeList{"admin"}}), "unknown scope 'admin'")
+	assert.ErrorContains(t, validateAPIKeyRestrictions(&types.APIKey{ExpiresAt: &past}), "expiry must be in the future")
+	assert.ErrorContains(t, validateAPIKeyRestrictions(&types.APIKey{AllowedIPs: types.StringList{"10.0.0.0/33"}}), "invalid CIDR range")
+	assert.ErrorContains(t, validateAPIKeyRestrictions(&types.APIKey{AllowedIPs: types.StringList{"localhost"}}), "invalid IP address")
+	assert.ErrorContains(t, validateAPIKeyRestrictions(&types.APIKey{RequestsPerMinute: -1}), "rate limits must not be negative")
+}
+
+func TestAPIKeyLimiter(t *testing.T) {
+	now := time.Now()
+
+	limiter := newAPIKeyLimiter()
+	limiter.now = func() time.Time { return now }
+
+	apiKey := &types.APIKey{Key: "hl-key", RequestsPerMinute: 2, TokensPerMinute: 100}
+
+	_, ok := limiter.allow(apiKey)
+	assert.True(t, ok)
+	_, ok = limiter.allow(apiKey)
+	assert.True(t, ok)
+
+	retryAfter, ok := limiter.allow(apiKey)
+	assert.False(t, ok)
+	assert.Equal(t, time.Minute, retryAfter)
+
+	// Next window
+	now = now.Add(time.Minute)
+
+	_, ok = limiter.allow(apiKey)
+	assert.True(t, ok)
+
+	limiter.addTokens(apiKey, 100)
+
+	now = now.Add(15 * time.Second)
+
+	retryAfter, ok = limiter.allow(apiKey)
+	assert.False(t, ok)
+	assert.Equal(t, 45*time.Second, retryAfter)
+}
+
+func TestAuthorizeAPIKey(t *testing.T) {
+	storeMock := store.NewMockStore(gomock.NewController(t))
+
+	auth := &authMiddleware{
+		store:         storeMock,
+		apiKeyLimiter: newAPIKeyLimiter(),
+	}
+
+	apiKey := &types.APIKey{
+		Key:               "hl-key",
+		Scopes:            types.APIKeyScopeList{types.APIKeyScopeChat},
+		AllowedIPs:        types.StringList{"10.0.0.0/8"},
+		RequestsPerMinute: 1,
+	}
+
+	// Last used is only written once a minute
+	storeMock.EXPECT().UpdateAPIKeyLastUsed(gomock.Any(), "hl-key", gomock.Any()).Return(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", http.NoBody)
+	req.RemoteAddr = "10.0.0.1:5000"
+
+	rec := httptest.NewRecorder()
+	assert.True(t, auth.authorizeAPIKey(rec, req, apiKey))
+	assert.NotNil(t, apiKey.LastUsed)
+
+	rec = httptest.NewRecorder()
+	assert.False(t, auth.authorizeAPIKey(rec, req, apiKey))
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+
+	req.RemoteAddr = "192.168.0.1:5000"
+	rec = httptest.NewRecorder()
+	assert.False(t, auth.authorizeAPIKey(rec, req, apiKey))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	// The allowlist is checked against the client behind the trusted proxy
+	auth.trustedProxies = parseTrustedProxies([]string{"192.168.0.1"})
+	req.Header.Set("X-Forwarded-For", "10.0.0.2")
+	rec = httptest.NewRecorder()
+	assert.False(t, auth.authorizeAPIKey(rec, req, apiKey))
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/apps", http.NoBody)
+	req.RemoteAddr = "10.0.0.1:5000"
+	rec = httptest.NewRecorder()
+	assert.False(t, auth.authorizeAPIKey(rec, req, apiKey))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
@@ -8,12 +8,18 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/helixml/helix/api/pkg/apps"
 	"github.com/helixml/helix/api/pkg/controller/knowledge"
 	"github.com/helixml/helix/api/pkg/store"
 	"github.com/helixml/helix/api/pkg/system"
+	crontrigger "github.com/helixml/helix/api/pkg/trigger/cron"
+	"github.com/helixml/helix/api/pkg/trigger/webhook"
 	"github.com/helixml/helix/api/pkg/types"
 	"github.com/robfig/cron/v3"
 	"github.com/rs/zerolog/log"
@@ -29,7 +35,11 @@ import (
 // @Security BearerAuth
 func (s *HelixAPIServer) listApps(_ http.ResponseWriter, r *http.Request) ([]*types.App, *system.HTTPError) {
 	ctx := r.Context()
-	user := getRequestUser(r)
+	requestUser := getRequestUser(r)
+	user, httpErr := getRequestOwner(r, types.OrganizationRoleMember)
+	if httpErr != nil {
+		return nil, httpErr
+	}
 
 	userApps, err := s.Store.ListApps(ctx, &store.ListAppsQuery{
 		Owner:     user.ID,
@@ -71,6 +81,7 @@ func (s *HelixAPIServer) listApps(_ http.ResponseWriter, r *http.Request) ([]*ty
 				app.Config.Github.WebhookSecret = ""
 			}
 		}
+		redactWebhookSecrets(requestUser, app)
 
 		filteredApps = append(filteredApps, app)
 	}
@@ -99,7 +110,10 @@ func (s *HelixAPIServer) createApp(_ http.ResponseWriter, r *http.Request) (*typ
 		return nil, system.NewHTTPError400("failed to decode request body 1, error: %s, body: %s", err, string(body))
 	}
 
-	user := getRequestUser(r)
+	user, httpErr := getRequestOwner(r, types.OrganizationRoleAdmin)
+	if httpErr != nil {
+		return nil, httpErr
+	}
 	ctx := r.Context()
 
 	// Getting existing tools for the user
@@ -127,7 +141,27 @@ func (s *HelixAPIServer) createApp(_ http.ResponseWriter, r *http.Request) (*typ
 	// if this is a github app - then initialise it
 	switch app.AppSource {
 	case types.AppSourceHelix:
-		err = s.validateTriggers(app.Config.Helix.Triggers)
+		err = s.validateTriggers(r.Context(), app.ID, app.Config.Helix.Triggers)
+		if err != nil {
+			return nil, system.NewHTTPError400(err.Error())
+		}
+
+		err = s.validatePriorityClass(getRequestUser(r), app.Config.Helix.PriorityClass, "")
+		if err != nil {
+			return nil, system.NewHTTPError400(err.Error())
+		}
+
+		err = validateSlackClaimOwner(getRequestUser(r), app.Config.Helix.Triggers, nil)
+		if err != nil {
+			return nil, system.NewHTTPError403(err.Error())
+		}
+
+		err = validateDiscordClaimOwner(getRequestUser(r), app.Config.Helix.Triggers, nil)
+		if err != nil {
+			return nil, system.NewHTTPError403(err.Error())
+		}
+
+		err = s.validateEmailRecipients(ctx, app.Owner, app.OwnerType, app.Config.Helix.Triggers)
 		if err != nil {
 			return nil, system.NewHTTPError400(err.Error())
 		}
@@ -180,6 +214,7 @@ func (s *HelixAPIServer) createApp(_ http.ResponseWriter, r *http.Request) (*typ
 			UpdateApp: func(app *types.App) (*types.App, error) {
 				return s.Store.UpdateApp(r.Context(), app)
 			},
+			ValidateConfig: s.validateGithubAppConfig(ctx, getRequestUser(r), created),
 		})
 		if err != nil {
 			return nil, system.NewHTTPError500(err.Error())
@@ -188,6 +223,9 @@ func (s *HelixAPIServer) createApp(_ http.ResponseWriter, r *http.Request) (*typ
 		newApp, err := githubApp.Create()
 		if err != nil {
 			s.Store.DeleteApp(r.Context(), created.ID)
+			if errors.Is(err, apps.ErrInvalidConfig) {
+				return nil, system.NewHTTPError400(err.Error())
+			}
 			return nil, system.NewHTTPError500(err.Error())
 		}
 
@@ -202,12 +240,12 @@ func (s *HelixAPIServer) createApp(_ http.ResponseWriter, r *http.Request) (*typ
 			types.AppSourceGithub)
 	}
 
-	err = s.ensureKnowledge(ctx, created)
+	err = s.ensureKnowledge(r, created)
 	if err != nil {
 		return nil, system.NewHTTPError500(err.Error())
 	}
 
-	_, err = s.Controller.CreateAPIKey(ctx, user, &types.APIKey{
+	apiKey, err := s.Controller.CreateAPIKey(ctx, user, &types.APIKey{
 		Name:  "api key 1",
 		Type:  types.APIKeyType_App,
 		AppID: &sql.NullString{String: created.ID, Valid: true},
@@ -216,6 +254,9 @@ func (s *HelixAPIServer) createApp(_ http.ResponseWriter, r *http.Request) (*typ
 		return nil, system.NewHTTPError500(err.Error())
 	}
 
+	s.audit(r, types.AuditActionCreate, types.AuditTargetApp, created.ID, created.ID, nil, created)
+	s.audit(r, types.AuditActionCreate, types.AuditTargetAPIKey, auditAPIKeyID(apiKey.Key), created.ID, nil, apiKey)
+
 	return created, nil
 }
 
@@ -223,7 +264,7 @@ func (s *HelixAPIServer) validateKnowledge(k *types.AssistantKnowledge) error {
 	return knowledge.Validate(k)
 }
 
-func (s *HelixAPIServer) validateTriggers(triggers []types.Trigger) error {
+func (s *HelixAPIServer) validateTriggers(ctx context.Context, appID string, triggers []types.Trigger) error {
 	// If it's cron, check that it runs not more than once every 90 seconds
 	for _, trigger := range triggers {
 		if trigger.Cron != nil && trigger.Cron.Schedule != "" {
@@ -237,13 +278,263 @@ func (s *HelixAPIServer) validateTriggers(triggers []types.Trigger) error {
 			if secondRun.Sub(nextRun) < 90*time.Second {
 				return fmt.Errorf("cron trigger must not run more than once per 90 seconds")
 			}
+
+			err = crontrigger.ValidateTrigger(trigger.Cron)
+			if err != nil {
+				return fmt.Errorf("invalid cron trigger: %w", err)
+			}
+		}
+
+		if trigger.Slack != nil && len(trigger.Slack.Channels) == 0 && !trigger.Slack.DirectMessages {
+			return fmt.Errorf("slack trigger must have channels or answer direct messages")
+		}
+
+		if trigger.Webhook != nil {
+			err := webhook.ValidateTrigger(trigger.Webhook)
+			if err != nil {
+				return fmt.Errorf("invalid webhook trigger: %w", err)
+			}
+		}
+	}
+
+	return s.validateSlackClaims(ctx, appID, triggers)
+}
+
+// validateSlackClaims checks that the Slack channels and the direct messages
+// of the app aren't answered by another app already, the shared bot routes
+// every channel to a single app
+func (s *HelixAPIServer) validateSlackClaims(ctx context.Context, appID string, triggers []types.Trigger) error {
+	var (
+		channels       []string
+		directMessages bool
+	)
+	for _, trigger := range triggers {
+		if trigger.Slack == nil {
+			continue
+		}
+		channels = append(channels, trigger.Slack.Channels...)
+		directMessages = directMessages || trigger.Slack.DirectMessages
+	}
+
+	if len(channels) == 0 && !directMessages {
+		return nil
+	}
+
+	apps, err := s.Store.ListApps(ctx, &store.ListAppsQuery{})
+	if err != nil {
+		return fmt.Errorf("failed to list apps: %w", err)
+	}
+
+	for _, app := range apps {
+		if app.ID == appID {
+			continue
+		}
+
+		for _, trigger := range app.Config.Helix.Triggers {
+			if trigger.Slack == nil {
+				continue
+			}
+
+			for _, channel := range trigger.Slack.Channels {
+				if slices.Contains(channels, channel) {
+					return fmt.Errorf("slack channel %s is already used by another app", channel)
+				}
+			}
+
+			if directMessages && trigger.Slack.DirectMessages {
+				return fmt.Errorf("slack direct messages are already answered by another app")
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateSlackClaimOwner checks that only admins add Slack channels or direct
+// messages to the app. The shared bot is in the whole workspace, so users could
+// otherwise route the channels they aren't in or every direct message to their
+// app. The claims the app already has are kept.
+func validateSlackClaimOwner(user *types.User, triggers, existing []types.Trigger) error {
+	if isAdmin(user) {
+		return nil
+	}
+
+	var (
+		existingChannels       []string
+		existingDirectMessages bool
+	)
+	for _, trigger := range existing {
+		if trigger.Slack == nil {
+			continue
+		}
+		existingChannels = append(existingChannels, trigger.Slack.Channels...)
+		existingDirectMessages = existingDirectMessages || trigger.Slack.DirectMessages
+	}
+
+	for _, trigger := range triggers {
+		if trigger.Slack == nil {
+			continue
+		}
+
+		for _, channel := range trigger.Slack.Channels {
+			if !slices.Contains(existingChannels, channel) {
+				return fmt.Errorf("only admin users can add slack channel %s", channel)
+			}
+		}
+
+		if trigger.Slack.DirectMessages && !existingDirectMessages {
+			return fmt.Errorf("only admin users can answer slack direct messages")
+		}
+	}
+
+	return nil
+}
+
+// validateDiscordClaimOwner checks that only admins add Discord channels to the
+// cron outputs of the app. The shared bot is in every server it was invited to,
+// so users could otherwise post to any of their channels. The channels the app
+// already posts to are kept.
+func validateDiscordClaimOwner(user *types.User, triggers, existing []types.Trigger) error {
+	if isAdmin(user) {
+		return nil
+	}
+
+	existingChannels := discordOutputChannels(existing)
+
+	for _, channel := range discordOutputChannels(triggers) {
+		if !slices.Contains(existingChannels, channel) {
+			return fmt.Errorf("only admin users can add discord channel %s", channel)
+		}
+	}
+
+	return nil
+}
+
+func discordOutputChannels(triggers []types.Trigger) []string {
+	var channels []string
+	for _, trigger := range triggers {
+		if trigger.Cron == nil {
+			continue
+		}
+		for _, output := range trigger.Cron.Outputs {
+			if output.Discord != nil {
+				channels = append(channels, output.Discord.ChannelID)
+			}
 		}
 	}
+	return channels
+}
+
+// validateEmailRecipients checks that the cron outputs of the app only email
+// the owner of the app or the members of the organization that owns it, the
+// emails are sent from Helix
+func (s *HelixAPIServer) validateEmailRecipients(ctx context.Context, owner string, ownerType types.OwnerType, triggers []types.Trigger) error {
+	var recipients []string
+	for _, trigger := range triggers {
+		if trigger.Cron == nil {
+			continue
+		}
+		for _, output := range trigger.Cron.Outputs {
+			if output.Email != nil {
+				recipients = append(recipients, output.Email.To...)
+			}
+		}
+	}
+
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	userIDs := []string{owner}
+	if ownerType == types.OwnerTypeOrg {
+		memberships, err := s.Store.ListOrganizationMemberships(ctx, &store.ListOrganizationMembershipsQuery{
+			OrganizationID: owner,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list organization members: %w", err)
+		}
+
+		userIDs = userIDs[:0]
+		for _, membership := range memberships {
+			userIDs = append(userIDs, membership.UserID)
+		}
+	}
+
+	var allowed []string
+	for _, userID := range userIDs {
+		user, err := s.authMiddleware.authenticator.GetUserByID(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to get user '%s' details: %w", userID, err)
+		}
+		if user.Email != "" {
+			allowed = append(allowed, strings.ToLower(user.Email))
+		}
+	}
+
+	for _, recipient := range recipients {
+		if !slices.Contains(allowed, strings.ToLower(recipient)) {
+			return fmt.Errorf("email recipient %s is not the owner of the app or a member of its organization", recipient)
+		}
+	}
+
 	return nil
 }
 
+// validatePriorityClass checks that the priority class is configured, only admins
+// can change it so that users can't jump the queue
+func (s *HelixAPIServer) validatePriorityClass(user *types.User, priorityClass, existing string) error {
+	if priorityClass == existing {
+		return nil
+	}
+
+	if !isAdmin(user) {
+		return fmt.Errorf("only admin users can set the priority class")
+	}
+
+	if priorityClass == "" {
+		return nil
+	}
+
+	if _, ok := s.Cfg.Providers.Helix.PriorityClasses[priorityClass]; !ok {
+		return fmt.Errorf("unknown priority class %s", priorityClass)
+	}
+
+	return nil
+}
+
+// validateGithubAppConfig checks the config loaded from the helix.yaml of the
+// GitHub app like the config of the apps created in Helix, so that the repo
+// can't set what only admins can
+func (s *HelixAPIServer) validateGithubAppConfig(ctx context.Context, user *types.User, app *types.App) func(config, existing *types.AppHelixConfig) error {
+	return func(config, existing *types.AppHelixConfig) error {
+		err := s.validateTriggers(ctx, app.ID, config.Triggers)
+		if err != nil {
+			return err
+		}
+
+		err = s.validatePriorityClass(user, config.PriorityClass, existing.PriorityClass)
+		if err != nil {
+			return err
+		}
+
+		err = validateSlackClaimOwner(user, config.Triggers, existing.Triggers)
+		if err != nil {
+			return err
+		}
+
+		err = validateDiscordClaimOwner(user, config.Triggers, existing.Triggers)
+		if err != nil {
+			return err
+		}
+
+		return s.validateEmailRecipients(ctx, app.Owner, app.OwnerType, config.Triggers)
+	}
+}
+
 // ensureKnowledge creates or updates knowledge config in the database
-func (s *HelixAPIServer) ensureKnowledge(ctx context.Context, app *types.App) error {
+func (s *HelixAPIServer) ensureKnowledge(r *http.Request, app *types.App) error {
+	ctx := r.Context()
+
 	var knowledge []*types.AssistantKnowledge
 
 	// Get knowledge for all assistants
@@ -280,6 +571,9 @@ func (s *HelixAPIServer) ensureKnowledge(ctx context.Context, app *types.App) er
 				if err != nil {
 					return fmt.Errorf("failed to create knowledge '%s': %w", k.Name, err)
 				}
+
+				s.audit(r, types.AuditActionCreate, types.AuditTargetKnowledge, created.ID, app.ID, nil, created)
+
 				// OK, continue
 				foundKnowledge[created.ID] = true
 				continue
@@ -288,6 +582,12 @@ func (s *HelixAPIServer) ensureKnowledge(ctx context.Context, app *types.App) er
 			}
 		}
 
+		// Index everything again on the next refresh if the source or the
+		// settings changed, even if the source itself didn't
+		if !reflect.DeepEqual(existing.Source, k.Source) || !reflect.DeepEqual(existing.RAGSettings, k.RAGSettings) {
+			existing.SourceRevision = ""
+		}
+
 		// Update existing knowledge
 		existing.Description = k.Description
 		existing.RAGSettings = k.RAGSettings
@@ -317,6 +617,8 @@ func (s *HelixAPIServer) ensureKnowledge(ctx context.Context, app *types.App) er
 			if err != nil {
 				return fmt.Errorf("failed to delete knowledge '%s': %w", k.Name, err)
 			}
+
+			s.audit(r, types.AuditActionDelete, types.AuditTargetKnowledge, k.ID, app.ID, k, nil)
 		}
 	}
 
@@ -358,12 +660,143 @@ func (s *HelixAPIServer) getApp(_ http.ResponseWriter, r *http.Request) (*types.
 		return nil, system.NewHTTPError500(err.Error())
 	}
 
-	if (!app.Global && !app.Shared) && app.Owner != user.ID {
+	if (!app.Global && !app.Shared) && !user.HasOwnerRole(app.Owner, app.OwnerType, types.OrganizationRoleMember) {
 		return nil, system.NewHTTPError404(store.ErrNotFound.Error())
 	}
+
+	redactWebhookSecrets(user, app)
+
 	return app, nil
 }
 
+// redactWebhookSecrets removes the webhook trigger secrets unless the user
+// can update the app, anyone else could sign webhook requests with them
+func redactWebhookSecrets(user *types.User, app *types.App) {
+	if app.Global && isAdmin(user) {
+		return
+	}
+
+	if !app.Global && user.HasOwnerRole(app.Owner, app.OwnerType, types.OrganizationRoleAdmin) {
+		return
+	}
+
+	for _, trigger := range app.Config.Helix.Triggers {
+		if trigger.Webhook != nil {
+			trigger.Webhook.Secret = ""
+		}
+	}
+}
+
+// listAppTriggerExecutions godoc
+// @Summary List app trigger executions
+// @Description List the runs of the app triggers, newest first.
+// @Tags    apps
+
+// @Success 200 {array} types.TriggerExecution
+// @Param id path string true "App ID"
+// @Param offset query int false "Offset"
+// @Param limit query int false "Limit"
+// @Router /api/v1/apps/{id}/trigger-executions [get]
+// @Security BearerAuth
+func (s *HelixAPIServer) listAppTriggerExecutions(_ http.ResponseWriter, r *http.Request) ([]*types.TriggerExecution, *system.HTTPError) {
+	user := getRequestUser(r)
+	id := getID(r)
+
+	app, err := s.Store.GetApp(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, system.NewHTTPError404(store.ErrNotFound.Error())
+		}
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	// Runs are only visible to the owners, even for global and shared apps
+	if !user.HasOwnerRole(app.Owner, app.OwnerType, types.OrganizationRoleMember) {
+		return nil, system.NewHTTPError404(store.ErrNotFound.Error())
+	}
+
+	// Default values if offset or limit are not provided or conversion fails
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 50
+	}
+
+	executions, err := s.Store.ListTriggerExecutions(r.Context(), &store.ListTriggerExecutionsQuery{
+		AppID:  app.ID,
+		Offset: offset,
+		Limit:  limit,
+	})
+	if err != nil {
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	return executions, nil
+}
+
+// maxWebhookPayloadSize limits the body of the app webhook requests
+const maxWebhookPayloadSize = 1 << 20
+
+// appWebhook godoc
+// @Summary Run the app from a webhook
+// @Description Run the app with the JSON payload, the request is authenticated with the
+// @Description HMAC-SHA256 signature in the X-Helix-Signature header (sha256=<hex>) of the unix time in the
+// @Description X-Helix-Timestamp header, a dot and the body. Requests signed more than 5 minutes ago are rejected.
+// @Description The result is returned when the trigger has no callback URL, otherwise the request
+// @Description is accepted and the result is POSTed to the callback URL.
+// @Tags    apps
+
+// @Success 200 {object} webhook.Result
+// @Success 202 {object} webhook.Result
+// @Param id path string true "App ID"
+// @Param request body object true "Payload"
+// @Router /api/v1/apps/{id}/webhook [post]
+func (s *HelixAPIServer) appWebhook(rw http.ResponseWriter, r *http.Request) (*webhook.Result, *system.HTTPError) {
+	id := getID(r)
+
+	body, err := io.ReadAll(http.MaxBytesReader(rw, r.Body, maxWebhookPayloadSize))
+	if err != nil {
+		return nil, system.NewHTTPError400(fmt.Sprintf("failed to read payload: %s", err))
+	}
+
+	app, err := s.Store.GetApp(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, system.NewHTTPError404(store.ErrNotFound.Error())
+		}
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	trigger, ok := webhook.GetTrigger(app)
+	if !ok {
+		return nil, system.NewHTTPError404(store.ErrNotFound.Error())
+	}
+
+	err = webhook.VerifySignature(trigger.Secret, body, r.Header)
+	if err != nil {
+		return nil, system.NewHTTPError401(err.Error())
+	}
+
+	if trigger.CallbackURL == "" {
+		result, err := s.webhookTrigger.Invoke(r.Context(), app, trigger, body)
+		if err != nil {
+			return nil, system.NewHTTPError500(err.Error())
+		}
+		return result, nil
+	}
+
+	// The run continues after the response is written
+	result, err := s.webhookTrigger.InvokeAsync(context.WithoutCancel(r.Context()), app, trigger, body)
+	if err != nil {
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusAccepted)
+
+	return result, nil
+}
+
 // updateApp godoc
 // @Summary Update an existing app
 // @Description Update existing app
@@ -401,12 +834,36 @@ func (s *HelixAPIServer) updateApp(_ http.ResponseWriter, r *http.Request) (*typ
 			return nil, system.NewHTTPError403("only admin users can update global apps")
 		}
 	} else {
-		if existing.Owner != user.ID {
+		if !user.HasOwnerRole(existing.Owner, existing.OwnerType, types.OrganizationRoleAdmin) {
 			return nil, system.NewHTTPError403("you do not have permission to update this app")
 		}
 	}
 
-	err = s.validateTriggers(update.Config.Helix.Triggers)
+	// Apps can't be moved to another owner
+	update.Owner = existing.Owner
+	update.OwnerType = existing.OwnerType
+
+	err = s.validateTriggers(r.Context(), existing.ID, update.Config.Helix.Triggers)
+	if err != nil {
+		return nil, system.NewHTTPError400(err.Error())
+	}
+
+	err = s.validatePriorityClass(user, update.Config.Helix.PriorityClass, existing.Config.Helix.PriorityClass)
+	if err != nil {
+		return nil, system.NewHTTPError400(err.Error())
+	}
+
+	err = validateSlackClaimOwner(user, update.Config.Helix.Triggers, existing.Config.Helix.Triggers)
+	if err != nil {
+		return nil, system.NewHTTPError403(err.Error())
+	}
+
+	err = validateDiscordClaimOwner(user, update.Config.Helix.Triggers, existing.Config.Helix.Triggers)
+	if err != nil {
+		return nil, system.NewHTTPError403(err.Error())
+	}
+
+	err = s.validateEmailRecipients(r.Context(), existing.Owner, existing.OwnerType, update.Config.Helix.Triggers)
 	if err != nil {
 		return nil, system.NewHTTPError400(err.Error())
 	}
@@ -438,11 +895,13 @@ func (s *HelixAPIServer) updateApp(_ http.ResponseWriter, r *http.Request) (*typ
 		return nil, system.NewHTTPError500(err.Error())
 	}
 
-	err = s.ensureKnowledge(r.Context(), updated)
+	err = s.ensureKnowledge(r, updated)
 	if err != nil {
 		return nil, system.NewHTTPError500(err.Error())
 	}
 
+	s.audit(r, types.AuditActionUpdate, types.AuditTargetApp, updated.ID, updated.ID, existing, updated)
+
 	return updated, nil
 }
 
@@ -497,11 +956,14 @@ func (s *HelixAPIServer) updateGithubApp(_ http.ResponseWriter, r *http.Request)
 			return nil, system.NewHTTPError403("only admin users can update global apps")
 		}
 	} else {
-		if existing.Owner != user.ID {
+		if !user.HasOwnerRole(existing.Owner, existing.OwnerType, types.OrganizationRoleAdmin) {
 			return nil, system.NewHTTPError403("you do not have permission to update this app")
 		}
 	}
 
+	// The app is updated in place, keep the config from before the update
+	before := auditSnapshotOf(existing)
+
 	if existing.AppSource == types.AppSourceGithub {
 		client, err := s.getGithubClientFromRequest(r)
 		if err != nil {
@@ -515,6 +977,7 @@ func (s *HelixAPIServer) updateGithubApp(_ http.ResponseWriter, r *http.Request)
 			UpdateApp: func(app *types.App) (*types.App, error) {
 				return s.Store.UpdateApp(r.Context(), app)
 			},
+			ValidateConfig: s.validateGithubAppConfig(r.Context(), user, existing),
 		})
 		if err != nil {
 			return nil, system.NewHTTPError500(err.Error())
@@ -522,6 +985,9 @@ func (s *HelixAPIServer) updateGithubApp(_ http.ResponseWriter, r *http.Request)
 
 		existing, err = githubApp.Update()
 		if err != nil {
+			if errors.Is(err, apps.ErrInvalidConfig) {
+				return nil, system.NewHTTPError400(err.Error())
+			}
 			return nil, system.NewHTTPError500(err.Error())
 		}
 	}
@@ -538,6 +1004,8 @@ func (s *HelixAPIServer) updateGithubApp(_ http.ResponseWriter, r *http.Request)
 		return nil, system.NewHTTPError500(err.Error())
 	}
 
+	s.audit(r, types.AuditActionUpdate, types.AuditTargetAppGithubConfig, updated.ID, updated.ID, before, updated)
+
 	return updated, nil
 }
 
@@ -569,7 +1037,7 @@ func (s *HelixAPIServer) deleteApp(_ http.ResponseWriter, r *http.Request) (*typ
 			return nil, system.NewHTTPError403("only admin users can delete global apps")
 		}
 	} else {
-		if existing.Owner != user.ID {
+		if !user.HasOwnerRole(existing.Owner, existing.OwnerType, types.OrganizationRoleAdmin) {
 			return nil, system.NewHTTPError403("you do not have permission to delete this app")
 		}
 	}
@@ -587,6 +1055,8 @@ func (s *HelixAPIServer) deleteApp(_ http.ResponseWriter, r *http.Request) (*typ
 			if err != nil {
 				return nil, system.NewHTTPError500(err.Error())
 			}
+
+			s.audit(r, types.AuditActionDelete, types.AuditTargetKnowledge, k.ID, id, k, nil)
 		}
 	}
 
@@ -595,6 +1065,8 @@ func (s *HelixAPIServer) deleteApp(_ http.ResponseWriter, r *http.Request) (*typ
 		return nil, system.NewHTTPError500(err.Error())
 	}
 
+	s.audit(r, types.AuditActionDelete, types.AuditTargetApp, id, id, existing, nil)
+
 	return existing, nil
 }
 
@@ -0,0 +1,298 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/helixml/helix/api/pkg/auth"
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func newWebhookApp(owner string, shared bool) *types.App {
+	return &types.App{
+		ID:        "app_1",
+		Owner:     owner,
+		OwnerType: types.OwnerTypeUser,
+		Shared:    shared,
+		Config: types.AppConfig{
+			Helix: types.AppHelixConfig{
+				Triggers: []types.Trigger{
+					{Webhook: &types.WebhookTrigger{Enabled: true, Secret: "secret"}},
+				},
+			},
+		},
+	}
+}
+
+func getAppAs(t *testing.T, userID string, app *types.App) *types.App {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+	storeMock.EXPECT().GetApp(gomock.Any(), "app_1").Return(app, nil)
+
+	server := &HelixAPIServer{Store: storeMock}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/apps/app_1", http.NoBody)
+	req = req.WithContext(setRequestUser(context.Background(), types.User{ID: userID, Type: types.OwnerTypeUser}))
+	req = mux.SetURLVars(req, map[string]string{"id": "app_1"})
+
+	resp, httpErr := server.getApp(httptest.NewRecorder(), req)
+	require.Nil(t, httpErr)
+	return resp
+}
+
+func TestGetApp_WebhookSecret(t *testing.T) {
+	app := getAppAs(t, "user_1", newWebhookApp("user_1", true))
+	assert.Equal(t, "secret", app.Config.Helix.Triggers[0].Webhook.Secret)
+
+	// Other users of shared apps can't sign webhook requests
+	app = getAppAs(t, "user_2", newWebhookApp("user_1", true))
+	assert.Empty(t, app.Config.Helix.Triggers[0].Webhook.Secret)
+	assert.True(t, app.Config.Helix.Triggers[0].Webhook.Enabled)
+}
+
+func TestValidateTriggers_SlackClaims(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+	storeMock.EXPECT().ListApps(gomock.Any(), &store.ListAppsQuery{}).Return([]*types.App{
+		{
+			ID: "app_1",
+			Config: types.AppConfig{
+				Helix: types.AppHelixConfig{
+					Triggers: []types.Trigger{
+						{Slack: &types.SlackTrigger{Channels: []string{"C1"}, DirectMessages: true}},
+					},
+				},
+			},
+		},
+		{ID: "app_2"},
+	}, nil).AnyTimes()
+
+	server := &HelixAPIServer{Store: storeMock}
+	ctx := context.Background()
+
+	slackTrigger := func(channels []string, directMessages bool) []types.Trigger {
+		return []types.Trigger{{Slack: &types.SlackTrigger{Channels: channels, DirectMessages: directMessages}}}
+	}
+
+	err := server.validateTriggers(ctx, "app_2", slackTrigger([]string{"C1"}, false))
+	assert.ErrorContains(t, err, "slack channel C1 is already used by another app")
+
+	err = server.validateTriggers(ctx, "app_2", slackTrigger([]string{"C2"}, true))
+	assert.ErrorContains(t, err, "direct messages are already answered by another app")
+
+	assert.NoError(t, server.validateTriggers(ctx, "app_2", slackTrigger([]string{"C2"}, false)))
+
+	// The app can keep its own claims
+	assert.NoError(t, server.validateTriggers(ctx, "app_1", slackTrigger([]string{"C1"}, true)))
+}
+
+func TestValidateSlackClaimOwner(t *testing.T) {
+	user := &types.User{ID: "user_1", Type: types.OwnerTypeUser}
+	admin := &types.User{ID: "admin_1", Type: types.OwnerTypeUser, Admin: true}
+
+	slackTrigger := func(channels []string, directMessages bool) []types.Trigger {
+		return []types.Trigger{{Slack: &types.SlackTrigger{Channels: channels, DirectMessages: directMessages}}}
+	}
+
+	err := validateSlackClaimOwner(user, slackTrigger([]string{"C1"}, false), nil)
+	assert.ErrorContains(t, err, "only admin users can add slack channel C1")
+
+	err = validateSlackClaimOwner(user, slackTrigger(nil, true), slackTrigger([]string{"C1"}, false))
+	assert.ErrorContains(t, err, "only admin users can answer slack direct messages")
+
+	assert.NoError(t, validateSlackClaimOwner(admin, slackTrigger([]string{"C1"}, true), nil))
+
+	// Users can keep and remove the claims an admin set up
+	assert.NoError(t, validateSlackClaimOwner(user, slackTrigger([]string{"C1"}, true), slackTrigger([]string{"C1", "C2"}, true)))
+	assert.NoError(t, validateSlackClaimOwner(user, nil, slackTrigger([]string{"C1"}, true)))
+}
+
+func TestValidateDiscordClaimOwner(t *testing.T) {
+	user := &types.User{ID: "user_1", Type: types.OwnerTypeUser}
+	admin := &types.User{ID: "admin_1", Type: types.OwnerTypeUser, Admin: true}
+
+	discordOutput := func(channels ...string) []types.Trigger {
+		var outputs []types.TriggerOutput
+		for _, channel := range channels {
+			outputs = append(outputs, types.TriggerOutput{Discord: &types.DiscordTriggerOutput{ChannelID: channel}})
+		}
+		return []types.Trigger{{Cron: &types.CronTrigger{Schedule: "0 * * * *", Outputs: outputs}}}
+	}
+
+	err := validateDiscordClaimOwner(user, discordOutput("123"), nil)
+	assert.ErrorContains(t, err, "only admin users can add discord channel 123")
+
+	// A Discord trigger of the app doesn't allow posting to its server
+	err = validateDiscordClaimOwner(user, append(discordOutput("123"), types.Trigger{
+		Discord: &types.DiscordTrigger{ServerName: "helix"},
+	}), nil)
+	assert.ErrorContains(t, err, "only admin users can add discord channel 123")
+
+	assert.NoError(t, validateDiscordClaimOwner(admin, discordOutput("123"), nil))
+
+	// Users can keep and remove the channels an admin set up
+	assert.NoError(t, validateDiscordClaimOwner(user, discordOutput("123"), discordOutput("123", "456")))
+	assert.NoError(t, validateDiscordClaimOwner(user, nil, discordOutput("123")))
+}
+
+func TestValidateEmailRecipients(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+
+	server := &HelixAPIServer{
+		Store: storeMock,
+		authMiddleware: &authMiddleware{
+			authenticator: auth.NewMockAuthenticator(&types.User{ID: "user_1", Email: "Owner@example.com"}),
+		},
+	}
+	ctx := context.Background()
+
+	emailOutput := func(to ...string) []types.Trigger {
+		return []types.Trigger{{Cron: &types.CronTrigger{
+			Schedule: "0 * * * *",
+			Outputs:  []types.TriggerOutput{{Email: &types.EmailTriggerOutput{To: to}}},
+		}}}
+	}
+
+	assert.NoError(t, server.validateEmailRecipients(ctx, "user_1", types.OwnerTypeUser, emailOutput("owner@example.com")))
+
+	err := server.validateEmailRecipients(ctx, "user_1", types.OwnerTypeUser, emailOutput("owner@example.com", "someone@example.com"))
+	assert.ErrorContains(t, err, "email recipient someone@example.com is not the owner of the app")
+
+	storeMock.EXPECT().ListOrganizationMemberships(ctx, &store.ListOrganizationMembershipsQuery{OrganizationID: "org_1"}).
+		Return([]*types.OrganizationMembership{{OrganizationID: "org_1", UserID: "user_1"}}, nil)
+
+	assert.NoError(t, server.validateEmailRecipients(ctx, "org_1", types.OwnerTypeOrg, emailOutput("owner@example.com")))
+}
+
+func TestValidateGithubAppConfig_PriorityClass(t *testing.T) {
+	cfg := &config.ServerConfig{}
+	cfg.Providers.Helix.PriorityClasses = map[string]int{"high": 8, "low": 1}
+	server := &HelixAPIServer{Cfg: cfg}
+
+	user := &types.User{ID: "user_1", Type: types.OwnerTypeUser}
+	admin := &types.User{ID: "admin_1", Type: types.OwnerTypeUser, Admin: true}
+	app := &types.App{ID: "app_1", Owner: "user_1", OwnerType: types.OwnerTypeUser}
+	ctx := context.Background()
+
+	// The helix.yaml can't raise the priority of the app
+	err := server.validateGithubAppConfig(ctx, user, app)(&types.AppHelixConfig{PriorityClass: "high"}, &types.AppHelixConfig{})
+	assert.ErrorContains(t, err, "only admin users can set the priority class")
+
+	// The class an admin set up is kept
+	assert.NoError(t, server.validateGithubAppConfig(ctx, user, app)(&types.AppHelixConfig{PriorityClass: "high"}, &types.AppHelixConfig{PriorityClass: "high"}))
+	assert.NoError(t, server.validateGithubAppConfig(ctx, admin, app)(&types.AppHelixConfig{PriorityClass: "high"}, &types.AppHelixConfig{}))
+}
+
+func TestValidateGithubAppConfig_CronOutputs(t *testing.T) {
+	server := &HelixAPIServer{
+		Cfg: &config.ServerConfig{},
+		authMiddleware: &authMiddleware{
+			authenticator: auth.NewMockAuthenticator(&types.User{ID: "user_1", Email: "owner@example.com"}),
+		},
+	}
+
+	user := &types.User{ID: "user_1", Type: types.OwnerTypeUser}
+	app := &types.App{ID: "app_1", Owner: "user_1", OwnerType: types.OwnerTypeUser}
+	ctx := context.Background()
+
+	cronOutput := func(output types.TriggerOutput) *types.AppHelixConfig {
+		return &types.AppHelixConfig{Triggers: []types.Trigger{{Cron: &types.CronTrigger{
+			Schedule: "0 * * * *",
+			Outputs:  []types.TriggerOutput{output},
+		}}}}
+	}
+
+	// The helix.yaml can't post to Discord channels without an admin
+	err := server.validateGithubAppConfig(ctx, user, app)(cronOutput(types.TriggerOutput{
+		Discord: &types.DiscordTriggerOutput{ChannelID: "123"},
+	}), &types.AppHelixConfig{})
+	assert.ErrorContains(t, err, "only admin users can add discord channel 123")
+
+	// or email anyone but the owner
+	err = server.validateGithubAppConfig(ctx, user, app)(cronOutput(types.TriggerOutput{
+		Email: &types.EmailTriggerOutput{To: []string{"someone@example.com"}},
+	}), &types.AppHelixConfig{})
+	assert.ErrorContains(t, err, "email recipient someone@example.com is not the owner of the app")
+
+	assert.NoError(t, server.validateGithubAppConfig(ctx, user, app)(cronOutput(types.TriggerOutput{
+		Email: &types.EmailTriggerOutput{To: []string{"owner@example.com"}},
+	}), &types.AppHelixConfig{}))
+}
+
+func TestValidateGithubAppConfig_SlackClaims(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+	storeMock.EXPECT().ListApps(gomock.Any(), &store.ListAppsQuery{}).Return([]*types.App{
+		{
+			ID: "app_2",
+			Config: types.AppConfig{
+				Helix: types.AppHelixConfig{
+					Triggers: []types.Trigger{
+						{Slack: &types.SlackTrigger{Channels: []string{"C2"}}},
+					},
+				},
+			},
+		},
+	}, nil).AnyTimes()
+
+	server := &HelixAPIServer{Store: storeMock, Cfg: &config.ServerConfig{}}
+
+	user := &types.User{ID: "user_1", Type: types.OwnerTypeUser}
+	admin := &types.User{ID: "admin_1", Type: types.OwnerTypeUser, Admin: true}
+	app := &types.App{ID: "app_1", Owner: "user_1", OwnerType: types.OwnerTypeUser}
+	ctx := context.Background()
+
+	slackTrigger := func(channels ...string) *types.AppHelixConfig {
+		return &types.AppHelixConfig{Triggers: []types.Trigger{{Slack: &types.SlackTrigger{Channels: channels}}}}
+	}
+
+	// The helix.yaml can't claim channels without an admin
+	err := server.validateGithubAppConfig(ctx, user, app)(slackTrigger("C1"), &types.AppHelixConfig{})
+	assert.ErrorContains(t, err, "only admin users can add slack channel C1")
+
+	// or take over the channels of another app
+	err = server.validateGithubAppConfig(ctx, admin, app)(slackTrigger("C2"), &types.AppHelixConfig{})
+	assert.ErrorContains(t, err, "slack channel C2 is already used by another app")
+
+	assert.NoError(t, server.validateGithubAppConfig(ctx, admin, app)(slackTrigger("C1"), &types.AppHelixConfig{}))
+	assert.NoError(t, server.validateGithubAppConfig(ctx, user, app)(slackTrigger("C1"), slackTrigger("C1")))
+}
+
+func TestValidateGithubAppConfig_WebhookTrigger(t *testing.T) {
+	server := &HelixAPIServer{Cfg: &config.ServerConfig{}}
+
+	user := &types.User{ID: "user_1", Type: types.OwnerTypeUser}
+	app := &types.App{ID: "app_1", Owner: "user_1", OwnerType: types.OwnerTypeUser}
+	ctx := context.Background()
+
+	webhookTrigger := func(trigger *types.WebhookTrigger) *types.AppHelixConfig {
+		return &types.AppHelixConfig{Triggers: []types.Trigger{{Webhook: trigger}}}
+	}
+
+	// The helix.yaml can't add unsigned webhooks
+	err := server.validateGithubAppConfig(ctx, user, app)(webhookTrigger(&types.WebhookTrigger{Enabled: true}), &types.AppHelixConfig{})
+	assert.ErrorContains(t, err, "webhook secret not specified")
+
+	err = server.validateGithubAppConfig(ctx, user, app)(webhookTrigger(&types.WebhookTrigger{
+		Enabled:     true,
+		Secret:      "secret",
+		CallbackURL: "file:///etc/passwd",
+	}), &types.AppHelixConfig{})
+	assert.ErrorContains(t, err, "invalid callback URL")
+
+	assert.NoError(t, server.validateGithubAppConfig(ctx, user, app)(webhookTrigger(&types.WebhookTrigger{
+		Enabled:     true,
+		Secret:      "secret",
+		CallbackURL: "https://example.com/callback",
+	}), &types.AppHelixConfig{}))
+}
@@ -0,0 +1,236 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+const auditRedacted = "[redacted]"
+
+// auditSecretFields are the JSON fields that are redacted from the audit log,
+// the keys of the maps are kept so that the diff still shows what changed.
+// Fields are matched case-insensitively as structs without json tags are
+// marshalled with the Go field names.
+var auditSecretFields = map[string]bool{
+	"api_key":          true,
+	"key":              true,
+	"privatekey":       true,
+	"webhook_secret":   true,
+	"secret":           true,
+	"secrets":          true,
+	"headers":          true,
+	"callback_headers": true,
+	"password":         true,
+	"token":            true,
+}
+
+// audit records the administrative action of the user in the audit log, the
+// before and after snapshots of the target are nil for creates and deletes.
+// The action already happened so failing to record it is only logged.
+func (s *HelixAPIServer) audit(r *http.Request, action types.AuditAction, targetType types.AuditTargetType, targetID, appID string, before, after interface{}) {
+	user := getRequestUser(r)
+
+	auditLog := &types.AuditLog{
+		UserID:     user.ID,
+		UserEmail:  user.Email,
+		TokenType:  user.TokenType,
+		RemoteAddr: clientAddr(r, s.authMiddleware.trustedProxies),
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		AppID:      appID,
+	}
+
+	err := buildAuditDiff(auditLog, before, after)
+	if err != nil {
+		log.Error().Err(err).Str("target_id", targetID).Msg("failed to build audit log diff")
+	}
+
+	_, err = s.Store.CreateAuditLog(r.Context(), auditLog)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("user_id", user.ID).
+			Str("action", string(action)).
+			Str("target_type", string(targetType)).
+			Str("target_id", targetID).
+			Msg("failed to write audit log")
+	}
+}
+
+// auditSnapshotOf returns the snapshot of the target for the targets that are
+// updated in place, so the before snapshot must be taken before the update
+func auditSnapshotOf(target interface{}) interface{} {
+	snapshot, err := auditSnapshot(target)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to snapshot audit target")
+	}
+	return snapshot
+}
+
+// auditAPIKeyID identifies the API key in the audit log without revealing it
+func auditAPIKeyID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+// buildAuditDiff sets the redacted snapshots and the changed fields
+func buildAuditDiff(auditLog *types.AuditLog, before, after interface{}) error {
+	beforeValue, err := auditSnapshot(before)
+	if err != nil {
+		return err
+	}
+
+	afterValue, err := auditSnapshot(after)
+	if err != nil {
+		return err
+	}
+
+	if beforeValue != nil {
+		auditLog.Before, err = json.Marshal(beforeValue)
+		if err != nil {
+			return err
+		}
+	}
+
+	if afterValue != nil {
+		auditLog.After, err = json.Marshal(afterValue)
+		if err != nil {
+			return err
+		}
+	}
+
+	changes := auditChanges(beforeValue, afterValue)
+	if len(changes) > 0 {
+		auditLog.Diff, err = json.Marshal(changes)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// auditSnapshot returns the redacted JSON value of the target
+func auditSnapshot(target interface{}) (interface{}, error) {
+	if target == nil || reflect.ValueOf(target).Kind() == reflect.Ptr && reflect.ValueOf(target).IsNil() {
+		return nil, nil
+	}
+
+	bts, err := json.Marshal(target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal audit target: %w", err)
+	}
+
+	var value interface{}
+	err = json.Unmarshal(bts, &value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal audit target: %w", err)
+	}
+
+	return redactAuditValue(value), nil
+}
+
+func redactAuditValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, field := range v {
+			if auditSecretFields[strings.ToLower(k)] {
+				v[k] = redactAuditSecret(field)
+				continue
+			}
+			v[k] = redactAuditValue(field)
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redactAuditValue(v[i])
+		}
+	}
+	return value
+}
+
+func redactAuditSecret(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		if v == "" {
+			return v
+		}
+		return auditRedacted
+	case map[string]interface{}:
+		for k := range v {
+			v[k] = auditRedacted
+		}
+		return v
+	default:
+		return auditRedacted
+	}
+}
+
+// auditChanges returns the changed leaf fields, ordered by their path
+func auditChanges(before, after interface{}) []types.AuditChange {
+	beforeFields := map[string]interface{}{}
+	flattenAuditValue("", before, beforeFields)
+
+	afterFields := map[string]interface{}{}
+	flattenAuditValue("", after, afterFields)
+
+	paths := map[string]bool{}
+	for path := range beforeFields {
+		paths[path] = true
+	}
+	for path := range afterFields {
+		paths[path] = true
+	}
+
+	var changes []types.AuditChange
+	for path := range paths {
+		b, a := beforeFields[path], afterFields[path]
+		if reflect.DeepEqual(b, a) {
+			continue
+		}
+		changes = append(changes, types.AuditChange{Path: path, Before: b, After: a})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+
+	return changes
+}
+
+func flattenAuditValue(prefix string, value interface{}, fields map[string]interface{}) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, field := range v {
+			flattenAuditValue(join(k), field, fields)
+		}
+	case []interface{}:
+		for i, item := range v {
+			flattenAuditValue(join(strconv.Itoa(i)), item, fields)
+		}
+	case nil:
+		// Missing and null fields are the same in the diff
+	default:
+		fields[prefix] = v
+	}
+}
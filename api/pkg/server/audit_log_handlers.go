@@ -0,0 +1,173 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+const (
+	defaultAuditLogsLimit = 100
+	maxAuditLogsLimit     = 1000
+	// auditLogsExportBatch is the number of entries read from the store at
+	// once while exporting
+	auditLogsExportBatch = 500
+)
+
+// listAuditLogs godoc
+// @Summary List audit logs
+// @Description List the audit log of the administrative actions, newest first
+// @Tags    audit
+// @Produce json
+// @Param   user_id     query    string  false  "Filter by the user that made the change"
+// @Param   action      query    string  false  "Filter by action (create, update, delete, refresh)"
+// @Param   target_type query    string  false  "Filter by target type (app, app_github_config, tool, knowledge, api_key, session, provider_endpoint)"
+// @Param   target_id   query    string  false  "Filter by target ID"
+// @Param   app_id      query    string  false  "Filter by app ID"
+// @Param   from        query    string  false  "Entries created at or after the time (RFC 3339)"
+// @Param   to          query    string  false  "Entries created before the time (RFC 3339)"
+// @Param   offset      query    int     false  "Offset"
+// @Param   limit       query    int     false  "Limit, defaults to 100 and at most 1000"
+// @Success 200 {array} types.AuditLog
+// @Router /api/v1/audit_logs [get]
+// @Security BearerAuth
+func (s *HelixAPIServer) listAuditLogs(_ http.ResponseWriter, r *http.Request) ([]*types.AuditLog, *system.HTTPError) {
+	q, err := parseAuditLogsQuery(r)
+	if err != nil {
+		return nil, system.NewHTTPError400(err.Error())
+	}
+
+	if q.Limit == 0 {
+		q.Limit = defaultAuditLogsLimit
+	}
+
+	if q.Limit > maxAuditLogsLimit {
+		q.Limit = maxAuditLogsLimit
+	}
+
+	auditLogs, err := s.Store.ListAuditLogs(r.Context(), q)
+	if err != nil {
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	return auditLogs, nil
+}
+
+// exportAuditLogs godoc
+// @Summary Export audit logs
+// @Description Export the audit log as JSON lines, oldest first, e.g. for a SIEM. Takes the same filters as the list endpoint, all matching entries are exported.
+// @Tags    audit
+// @Produce application/x-ndjson
+// @Param   user_id     query    string  false  "Filter by the user that made the change"
+// @Param   action      query    string  false  "Filter by action"
+// @Param   target_type query    string  false  "Filter by target type"
+// @Param   target_id   query    string  false  "Filter by target ID"
+// @Param   app_id      query    string  false  "Filter by app ID"
+// @Param   from        query    string  false  "Entries created at or after the time (RFC 3339)"
+// @Param   to          query    string  false  "Entries created before the time (RFC 3339)"
+// @Success 200 {string} string "JSON lines of types.AuditLog"
+// @Router /api/v1/audit_logs/export [get]
+// @Security BearerAuth
+func (s *HelixAPIServer) exportAuditLogs(rw http.ResponseWriter, r *http.Request) {
+	q, err := parseAuditLogsQuery(r)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Entries created during the export are left for the next one
+	if q.To.IsZero() {
+		q.To = time.Now()
+	}
+	q.OldestFirst = true
+	q.Offset = 0
+	q.Limit = auditLogsExportBatch
+
+	rw.Header().Set("Content-Type", "application/x-ndjson")
+	rw.Header().Set("Content-Disposition", "attachment; filename=audit_logs.jsonl")
+
+	enc := json.NewEncoder(rw)
+	written := 0
+
+	for {
+		auditLogs, err := s.Store.ListAuditLogs(r.Context(), q)
+		if err != nil {
+			log.Err(err).Msg("error listing audit logs")
+			if written == 0 {
+				http.Error(rw, "Internal server error: "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		for _, auditLog := range auditLogs {
+			err = enc.Encode(auditLog)
+			if err != nil {
+				log.Err(err).Msg("error writing audit logs")
+				return
+			}
+			written++
+		}
+
+		if len(auditLogs) < q.Limit {
+			return
+		}
+
+		q.Offset += len(auditLogs)
+
+		if f, ok := rw.(http.Flusher); ok {
+			f.Flush()
+		}
+	}
+}
+
+func parseAuditLogsQuery(r *http.Request) (*store.ListAuditLogsQuery, error) {
+	params := r.URL.Query()
+
+	q := &store.ListAuditLogsQuery{
+		UserID:     params.Get("user_id"),
+		Action:     types.AuditAction(params.Get("action")),
+		TargetType: types.AuditTargetType(params.Get("target_type")),
+		TargetID:   params.Get("target_id"),
+		AppID:      params.Get("app_id"),
+	}
+
+	var err error
+
+	if from := params.Get("from"); from != "" {
+		q.From, err = time.Parse(time.RFC3339, from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from time, expected RFC 3339: %w", err)
+		}
+	}
+
+	if to := params.Get("to"); to != "" {
+		q.To, err = time.Parse(time.RFC3339, to)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to time, expected RFC 3339: %w", err)
+		}
+	}
+
+	if offset := params.Get("offset"); offset != "" {
+		q.Offset, err = strconv.Atoi(offset)
+		if err != nil || q.Offset < 0 {
+			return nil, fmt.Errorf("invalid offset: %s", offset)
+		}
+	}
+
+	if limit := params.Get("limit"); limit != "" {
+		q.Limit, err = strconv.Atoi(limit)
+		if err != nil || q.Limit < 0 {
+			return nil, fmt.Errorf("invalid limit: %s", limit)
+		}
+	}
+
+	return q, nil
+}
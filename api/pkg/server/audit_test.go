@@ -0,0 +1,185 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func TestBuildAuditDiff(t *testing.T) {
+	before := &types.App{
+		ID: "app_1",
+		Config: types.AppConfig{
+			Secrets: map[string]string{"GITHUB_TOKEN": "ghp_old"},
+			Helix: types.AppHelixConfig{
+				Name: "support bot",
+				Assistants: []types.AssistantConfig{
+					{Name: "default", Model: "llama3:instruct"},
+				},
+			},
+		},
+	}
+
+	after := &types.App{
+		ID: "app_1",
+		Config: types.AppConfig{
+			Secrets: map[string]string{"GITHUB_TOKEN": "ghp_new", "SLACK_TOKEN": "xoxb"},
+			Helix: types.AppHelixConfig{
+				Name: "support bot",
+				Assistants: []types.AssistantConfig{
+					{Name: "default", Model: "mixtral:instruct"},
+				},
+			},
+		},
+	}
+
+	auditLog := &types.AuditLog{}
+	require.NoError(t, buildAuditDiff(auditLog, before, after))
+
+	// Secrets never reach the audit log
+	assert.NotContains(t, string(auditLog.Before), "ghp_old")
+	assert.NotContains(t, string(auditLog.After), "ghp_new")
+	assert.NotContains(t, string(auditLog.After), "xoxb")
+
+	var changes []types.AuditChange
+	require.NoError(t, json.Unmarshal(auditLog.Diff, &changes))
+
+	assert.Equal(t, []types.AuditChange{
+		{Path: "config.helix.assistants.0.model", Before: "llama3:instruct", After: "mixtral:instruct"},
+		// Changed secret values are indistinguishable, added ones are not
+		{Path: "config.secrets.SLACK_TOKEN", Before: nil, After: auditRedacted},
+	}, changes)
+}
+
+func TestBuildAuditDiff_Delete(t *testing.T) {
+	auditLog := &types.AuditLog{}
+	require.NoError(t, buildAuditDiff(auditLog, &types.APIKey{Key: "hl-secret", Name: "ci"}, nil))
+
+	assert.NotContains(t, string(auditLog.Before), "hl-secret")
+	assert.Empty(t, auditLog.After)
+
+	var changes []types.AuditChange
+	require.NoError(t, json.Unmarshal(auditLog.Diff, &changes))
+	assert.Contains(t, changes, types.AuditChange{Path: "name", Before: "ci", After: nil})
+}
+
+func TestBuildAuditDiff_CaseInsensitiveSecrets(t *testing.T) {
+	knowledge := &types.Knowledge{
+		ID: "kno_1",
+		Source: types.KnowledgeSource{
+			Web: &types.KnowledgeSourceWeb{
+				URLs: []string{"https://intranet.example.com"},
+				Auth: types.KnowledgeSourceWebAuth{Username: "bot", Password: "hunter2"},
+			},
+		},
+	}
+
+	auditLog := &types.AuditLog{}
+	require.NoError(t, buildAuditDiff(auditLog, nil, knowledge))
+	assert.NotContains(t, string(auditLog.After), "hunter2")
+	assert.Contains(t, string(auditLog.After), "bot")
+
+	// Structs without json tags are marshalled with the Go field names
+	auditLog = &types.AuditLog{}
+	require.NoError(t, buildAuditDiff(auditLog, nil, map[string]interface{}{
+		"Password": "hunter2",
+		"API_KEY":  "sk-secret",
+	}))
+	assert.NotContains(t, string(auditLog.After), "hunter2")
+	assert.NotContains(t, string(auditLog.After), "sk-secret")
+}
+
+func TestAuditAPIKeyID(t *testing.T) {
+	id := auditAPIKeyID("hl-secret")
+	assert.Equal(t, id, auditAPIKeyID("hl-secret"))
+	assert.NotEqual(t, id, auditAPIKeyID("hl-other"))
+	assert.NotContains(t, id, "secret")
+}
+
+func TestAudit_ClientAddr(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+
+	server := &HelixAPIServer{
+		Store: storeMock,
+		authMiddleware: &authMiddleware{
+			trustedProxies: parseTrustedProxies([]string{"10.0.0.0/8"}),
+		},
+	}
+
+	storeMock.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, auditLog *types.AuditLog) (*types.AuditLog, error) {
+			// The address of the client behind the trusted proxy
+			assert.Equal(t, "203.0.113.7", auditLog.RemoteAddr)
+			return auditLog, nil
+		})
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/apps/app_1", http.NoBody)
+	req.RemoteAddr = "10.0.0.2:51234"
+	req.Header.Set("X-Forwarded-For", "203.0.113.7")
+	req = req.WithContext(setRequestUser(req.Context(), types.User{ID: "user_1"}))
+
+	server.audit(req, types.AuditActionDelete, types.AuditTargetApp, "app_1", "app_1", nil, nil)
+}
+
+func TestExportAuditLogs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+
+	server := &HelixAPIServer{Store: storeMock}
+
+	batch := make([]*types.AuditLog, auditLogsExportBatch)
+	for i := range batch {
+		batch[i] = &types.AuditLog{ID: "audit_1", Action: types.AuditActionDelete, TargetType: types.AuditTargetApp}
+	}
+
+	gomock.InOrder(
+		storeMock.EXPECT().ListAuditLogs(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, q *store.ListAuditLogsQuery) ([]*types.AuditLog, error) {
+				assert.True(t, q.OldestFirst)
+				assert.Equal(t, types.AuditTargetApp, q.TargetType)
+				assert.Equal(t, 0, q.Offset)
+				assert.False(t, q.To.IsZero())
+				return batch, nil
+			}),
+		storeMock.EXPECT().ListAuditLogs(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, q *store.ListAuditLogsQuery) ([]*types.AuditLog, error) {
+				assert.Equal(t, auditLogsExportBatch, q.Offset)
+				return batch[:1], nil
+			}),
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/audit_logs/export?target_type=app", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	server.exportAuditLogs(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+
+	lines := 0
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		var auditLog types.AuditLog
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &auditLog))
+		assert.Equal(t, "audit_1", auditLog.ID)
+		lines++
+	}
+	assert.Equal(t, auditLogsExportBatch+1, lines)
+}
+
+func TestParseAuditLogsQuery_InvalidTime(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/audit_logs?from=yesterday", http.NoBody)
+	_, err := parseAuditLogsQuery(req)
+	assert.ErrorContains(t, err, "invalid from time")
+}
@@ -3,10 +3,11 @@ package server
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"strings"
+	"time"
 
-	jwt "github.com/golang-jwt/jwt/v5"
 	"github.com/helixml/helix/api/pkg/auth"
 	"github.com/helixml/helix/api/pkg/store"
 	"github.com/helixml/helix/api/pkg/types"
@@ -22,8 +23,9 @@ var (
 )
 
 type authMiddlewareConfig struct {
-	adminUserIDs []string
-	runnerToken  string
+	adminUserIDs   []string
+	runnerToken    string
+	trustedProxies []string
 }
 
 type authMiddleware struct {
@@ -34,6 +36,9 @@ type authMiddleware struct {
 	// this means ALL users
 	// if '*' is included in the list
 	developmentMode bool
+	apiKeyLimiter   *apiKeyLimiter
+	// the client address of their requests is read from X-Forwarded-For
+	trustedProxies []*net.IPNet
 }
 
 func newAuthMiddleware(
@@ -47,6 +52,8 @@ func newAuthMiddleware(
 		adminUserIDs:    cfg.adminUserIDs,
 		runnerToken:     cfg.runnerToken,
 		developmentMode: isDevelopmentMode(cfg.adminUserIDs),
+		apiKeyLimiter:   newAPIKeyLimiter(),
+		trustedProxies:  parseTrustedProxies(cfg.trustedProxies),
 	}
 }
 
@@ -82,10 +89,18 @@ func (auth *authMiddleware) getUserFromToken(ctx context.Context, token string)
 		if apiKey == nil {
 			return nil, fmt.Errorf("error getting API key: no key found")
 		}
+		if apiKey.ExpiresAt != nil && time.Now().After(*apiKey.ExpiresAt) {
+			return nil, fmt.Errorf("API key expired")
+		}
+
+		if apiKey.OwnerType == types.OwnerTypeOrg {
+			// organization keys act as the organization itself
+			return auth.getOrganizationUser(ctx, token, apiKey)
+		}
 
 		user, err := auth.authenticator.GetUserByID(ctx, apiKey.Owner)
 		if err != nil {
-			return user, fmt.Errorf("error loading user from keycloak: %s", err.Error())
+			return user, fmt.Errorf("error loading user: %s", err.Error())
 		}
 
 		user.Token = token
@@ -96,36 +111,74 @@ func (auth *authMiddleware) getUserFromToken(ctx context.Context, token string)
 		if apiKey.AppID != nil && apiKey.AppID.Valid {
 			user.AppID = apiKey.AppID.String
 		}
+		user.PriorityClass = apiKey.PriorityClass
+		user.APIKey = apiKey
+
+		err = auth.loadOrganizations(ctx, user)
+		if err != nil {
+			return nil, err
+		}
 
 		return user, nil
 	} else {
-		// otherwise we try to decode the token with keycloak
-		keycloakJWT, err := auth.authenticator.ValidateUserToken(ctx, token)
+		// otherwise we try to decode the token with the identity provider
+		user, err := auth.authenticator.GetUserFromToken(ctx, token)
 		if err != nil {
-			return nil, fmt.Errorf("error validating keycloak token: %s", err.Error())
+			return nil, err
 		}
-		mc := keycloakJWT.Claims.(jwt.MapClaims)
-		keycloakUserID := mc["sub"].(string)
 
-		if keycloakUserID == "" {
-			return nil, fmt.Errorf("no keycloak user ID found")
-		}
+		user.Token = token
+		user.Admin = user.Admin || auth.isUserAdmin(user.ID)
 
-		user, err := auth.authenticator.GetUserByID(ctx, keycloakUserID)
+		err = auth.loadOrganizations(ctx, user)
 		if err != nil {
-			return user, fmt.Errorf("error loading user from keycloak: %s", err.Error())
+			return nil, err
 		}
 
-		user.Token = token
-		user.TokenType = types.TokenTypeKeycloak
-		user.ID = keycloakUserID
-		user.Type = types.OwnerTypeUser
-		user.Admin = auth.isUserAdmin(user.ID)
-
 		return user, nil
 	}
 }
 
+func (auth *authMiddleware) getOrganizationUser(ctx context.Context, token string, apiKey *types.APIKey) (*types.User, error) {
+	org, err := auth.store.GetOrganization(ctx, apiKey.Owner)
+	if err != nil {
+		return nil, fmt.Errorf("error loading organization: %s", err.Error())
+	}
+
+	user := &types.User{
+		Token:         token,
+		TokenType:     types.TokenTypeAPIKey,
+		ID:            org.ID,
+		Type:          types.OwnerTypeOrg,
+		Username:      org.Name,
+		FullName:      org.Name,
+		PriorityClass: apiKey.PriorityClass,
+		APIKey:        apiKey,
+	}
+	if apiKey.AppID != nil && apiKey.AppID.Valid {
+		user.AppID = apiKey.AppID.String
+	}
+
+	return user, nil
+}
+
+// loadOrganizations sets the roles of the user in the organizations they are a member of
+func (auth *authMiddleware) loadOrganizations(ctx context.Context, user *types.User) error {
+	memberships, err := auth.store.ListOrganizationMemberships(ctx, &store.ListOrganizationMembershipsQuery{
+		UserID: user.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("error loading organizations: %s", err.Error())
+	}
+
+	user.Organizations = make(map[string]types.OrganizationRole, len(memberships))
+	for _, membership := range memberships {
+		user.Organizations[membership.OrganizationID] = membership.Role
+	}
+
+	return nil
+}
+
 // this will extract the token from the request and then load the correct
 // user based on what type of token it is
 // if there is no token, a default user object will be written to the
@@ -149,6 +202,10 @@ func (auth *authMiddleware) extractMiddleware(next http.Handler) http.Handler {
 			}
 		}
 
+		if user.APIKey != nil && !auth.authorizeAPIKey(w, r, user.APIKey) {
+			return
+		}
+
 		r = r.WithContext(setRequestUser(r.Context(), *user))
 		next.ServeHTTP(w, r)
 	}
@@ -174,6 +231,10 @@ func (auth *authMiddleware) auth(f http.HandlerFunc) http.HandlerFunc {
 			}
 		}
 
+		if user.APIKey != nil && !auth.authorizeAPIKey(w, r, user.APIKey) {
+			return
+		}
+
 		r = r.WithContext(setRequestUser(r.Context(), *user))
 
 		f(w, r)
@@ -2,9 +2,11 @@ package server
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"strings"
 
+	"github.com/helixml/helix/api/pkg/system"
 	"github.com/helixml/helix/api/pkg/types"
 )
 
@@ -92,6 +94,28 @@ func getRequestUser(req *http.Request) *types.User {
 	return &user
 }
 
+// getRequestOwner returns the user that the resources of the request are created
+// and listed as. With the org_id query parameter that is the organization, if the
+// user has at least the role in it.
+func getRequestOwner(req *http.Request, role types.OrganizationRole) (*types.User, *system.HTTPError) {
+	user := getRequestUser(req)
+
+	orgID := req.URL.Query().Get("org_id")
+	if orgID == "" {
+		return user, nil
+	}
+
+	if !user.HasOwnerRole(orgID, types.OwnerTypeOrg, role) {
+		return nil, system.NewHTTPError403(fmt.Sprintf("you need the %s role in the organization %s", role, orgID))
+	}
+
+	if user.Type == types.OwnerTypeOrg {
+		return user, nil
+	}
+
+	return user.AsOrganization(orgID), nil
+}
+
 func getOwnerContext(req *http.Request) types.OwnerContext {
 	user := getRequestUser(req)
 	return types.OwnerContext{
@@ -164,6 +188,9 @@ func canSeeSession(user *types.User, session *types.Session) bool {
 	if session.Metadata.Shared {
 		return true
 	}
+	if session.OwnerType == types.OwnerTypeOrg && user.HasOwnerRole(session.Owner, session.OwnerType, types.OrganizationRoleMember) {
+		return true
+	}
 	return false
 }
 
@@ -171,6 +198,9 @@ func canEditSession(user *types.User, session *types.Session) bool {
 	if session.OwnerType == user.Type && session.Owner == user.ID {
 		return true
 	}
+	if session.OwnerType == types.OwnerTypeOrg && user.HasOwnerRole(session.Owner, session.OwnerType, types.OrganizationRoleAdmin) {
+		return true
+	}
 	if user.Admin {
 		return true
 	}
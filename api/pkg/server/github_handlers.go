@@ -3,9 +3,11 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -47,6 +49,12 @@ func (apiServer *HelixAPIServer) getGithubApp(app *types.App) (*apps.GithubApp,
 		UpdateApp: func(app *types.App) (*types.App, error) {
 			return apiServer.Store.UpdateApp(context.Background(), app)
 		},
+		// Pushes aren't made by a Helix user, so they can only keep what
+		// the app already has of the admin-only config
+		ValidateConfig: apiServer.validateGithubAppConfig(context.Background(), &types.User{
+			ID:   app.Owner,
+			Type: app.OwnerType,
+		}, app),
 	})
 	if err != nil {
 		return nil, err
@@ -55,6 +63,12 @@ func (apiServer *HelixAPIServer) getGithubApp(app *types.App) (*apps.GithubApp,
 }
 
 func (apiServer *HelixAPIServer) githubWebhook(w http.ResponseWriter, r *http.Request) {
+	// Knowledge repositories have their own webhook, see the knowledge reconciler
+	if knowledgeID := r.URL.Query().Get("knowledge_id"); knowledgeID != "" {
+		apiServer.githubKnowledgeWebhook(w, r, knowledgeID)
+		return
+	}
+
 	appID := r.URL.Query().Get("app_id")
 	if appID == "" {
 		log.Error().Msgf("github webhook app_id is required: %s", r.URL.String())
@@ -101,6 +115,17 @@ func (apiServer *HelixAPIServer) githubWebhook(w http.ResponseWriter, r *http.Re
 			return
 		}
 
+		// knowledge can follow any branch so it's refreshed before we
+		// filter the pushes that update the app itself
+		knowledge, err := apiServer.Store.ListKnowledge(r.Context(), &store.ListKnowledgeQuery{
+			AppID: app.ID,
+		})
+		if err != nil {
+			log.Error().Msgf("error listing app knowledge: %s", err.Error())
+		} else if err := apiServer.refreshGithubKnowledge(r.Context(), knowledge, &evt); err != nil {
+			log.Error().Msgf("error refreshing github knowledge: %s", err.Error())
+		}
+
 		// only accept pushes to master or main
 		if *evt.Ref != "refs/heads/master" && *evt.Ref != "refs/heads/main" {
 			log.Info().Msgf("ignoring push to branch: %s %s", *evt.Ref, *evt.Repo.HTMLURL)
@@ -146,6 +171,95 @@ func (apiServer *HelixAPIServer) githubWebhook(w http.ResponseWriter, r *http.Re
 	}
 }
 
+// githubKnowledgeWebhook receives the pushes to the repository of a GitHub
+// knowledge source, the events are signed with the secret of the knowledge
+func (apiServer *HelixAPIServer) githubKnowledgeWebhook(w http.ResponseWriter, r *http.Request, knowledgeID string) {
+	k, err := apiServer.Store.GetKnowledge(r.Context(), knowledgeID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			http.Error(w, "knowledge not found", http.StatusNotFound)
+			return
+		}
+		log.Error().Msgf("error loading knowledge from ID: %s %s", knowledgeID, err.Error())
+		http.Error(w, fmt.Sprintf("error loading knowledge: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	if k.Source.Github == nil || k.GithubWebhookSecret == "" {
+		http.Error(w, "knowledge has no github webhook", http.StatusBadRequest)
+		return
+	}
+
+	hook, err := githubhook.Parse([]byte(k.GithubWebhookSecret), r)
+	if err != nil {
+		log.Error().Msgf("error parsing knowledge webhook: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing webhook: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	if hook.Event != "push" {
+		return
+	}
+
+	// The knowledge webhooks are created with the JSON content type
+	evt := github_api.PushEvent{}
+	if err := json.Unmarshal(hook.Payload, &evt); err != nil {
+		log.Error().Msgf("error parsing webhook: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing webhook: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	if err := apiServer.refreshGithubKnowledge(r.Context(), []*types.Knowledge{k}, &evt); err != nil {
+		log.Error().Msgf("error refreshing github knowledge: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error refreshing knowledge: %s", err.Error()), http.StatusInternalServerError)
+	}
+}
+
+// refreshGithubKnowledge queues the knowledge that is sourced from the pushed
+// repository and branch for indexing, it's skipped when none of the indexed
+// files changed since the last indexed commit
+func (apiServer *HelixAPIServer) refreshGithubKnowledge(ctx context.Context, knowledge []*types.Knowledge, evt *github_api.PushEvent) error {
+	for _, k := range knowledge {
+		src := k.Source.Github
+		if src == nil {
+			continue
+		}
+
+		if !strings.EqualFold(fmt.Sprintf("%s/%s", src.Owner, src.Repository), evt.GetRepo().GetFullName()) {
+			continue
+		}
+
+		branch := src.Branch
+		if branch == "" {
+			branch = evt.GetRepo().GetDefaultBranch()
+		}
+
+		if evt.GetRef() != "refs/heads/"+branch {
+			continue
+		}
+
+		// Already queued or being indexed, leave it alone
+		if k.State == types.KnowledgeStateIndexing || k.State == types.KnowledgeStatePending {
+			continue
+		}
+
+		log.Info().
+			Str("knowledge_id", k.ID).
+			Str("commit", evt.GetAfter()).
+			Msg("github push, refreshing knowledge")
+
+		k.State = types.KnowledgeStatePending
+		k.Message = ""
+
+		_, err := apiServer.Store.UpdateKnowledge(ctx, k)
+		if err != nil {
+			return fmt.Errorf("failed to update knowledge '%s': %w", k.Name, err)
+		}
+	}
+
+	return nil
+}
+
 // do we already have the github token as an api key in the database?
 func (apiServer *HelixAPIServer) getGithubDatabaseToken(ctx context.Context, user *types.User) (string, error) {
 	apiKeys, err := apiServer.Store.ListAPIKeys(ctx, &store.ListApiKeysQuery{
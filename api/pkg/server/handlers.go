@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -27,13 +28,19 @@ import (
 )
 
 func (apiServer *HelixAPIServer) sessionLoaderWithID(req *http.Request, id string, writeMode bool) (*types.Session, *system.HTTPError) {
+	return apiServer.sessionLoaderWithQuery(req, &store.ListInteractionsQuery{SessionID: id}, writeMode)
+}
+
+// sessionLoaderWithQuery loads the session with the requested page of interactions
+func (apiServer *HelixAPIServer) sessionLoaderWithQuery(req *http.Request, query *store.ListInteractionsQuery, writeMode bool) (*types.Session, *system.HTTPError) {
+	id := query.SessionID
 	if id == "" {
 		return nil, system.NewHTTPError400("cannot load session without id")
 	}
 	ctx := req.Context()
 	user := getRequestUser(req)
 
-	session, err := apiServer.Store.GetSession(ctx, id)
+	session, err := apiServer.Store.GetSessionWithInteractions(ctx, query)
 	if err != nil {
 		return nil, system.NewHTTPError500(err.Error())
 	}
@@ -59,8 +66,62 @@ func (apiServer *HelixAPIServer) sessionLoader(req *http.Request, writeMode bool
 	return apiServer.sessionLoaderWithID(req, mux.Vars(req)["id"], writeMode)
 }
 
+// getSession returns the session, the interactions_offset and interactions_limit
+// query parameters return a page of the interactions instead of all of them
 func (apiServer *HelixAPIServer) getSession(res http.ResponseWriter, req *http.Request) (*types.Session, *system.HTTPError) {
-	return apiServer.sessionLoader(req, false)
+	query, httpErr := interactionsQuery(req, "interactions_offset", "interactions_limit")
+	if httpErr != nil {
+		return nil, httpErr
+	}
+	return apiServer.sessionLoaderWithQuery(req, query, false)
+}
+
+// getSessionInteractions returns a page of the session interactions, oldest first,
+// so clients can lazy load the history of long sessions
+func (apiServer *HelixAPIServer) getSessionInteractions(res http.ResponseWriter, req *http.Request) (*types.InteractionsList, *system.HTTPError) {
+	query, httpErr := interactionsQuery(req, "offset", "limit")
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	session, httpErr := apiServer.sessionLoaderWithQuery(req, query, false)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	counter, err := apiServer.Store.GetInteractionsCounter(req.Context(), session.ID)
+	if err != nil {
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	return &types.InteractionsList{
+		Counter:      counter,
+		Interactions: session.Interactions,
+	}, nil
+}
+
+func interactionsQuery(req *http.Request, offsetParam, limitParam string) (*store.ListInteractionsQuery, *system.HTTPError) {
+	query := &store.ListInteractionsQuery{
+		SessionID: mux.Vars(req)["id"],
+	}
+
+	if offsetStr := req.URL.Query().Get(offsetParam); offsetStr != "" {
+		offset, err := strconv.Atoi(offsetStr)
+		if err != nil || offset < 0 {
+			return nil, system.NewHTTPError400(fmt.Sprintf("invalid %s '%s'", offsetParam, offsetStr))
+		}
+		query.Offset = offset
+	}
+
+	if limitStr := req.URL.Query().Get(limitParam); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 0 {
+			return nil, system.NewHTTPError400(fmt.Sprintf("invalid %s '%s'", limitParam, limitStr))
+		}
+		query.Limit = limit
+	}
+
+	return query, nil
 }
 
 func (apiServer *HelixAPIServer) getSessionSummary(res http.ResponseWriter, req *http.Request) (*types.SessionSummary, *system.HTTPError) {
@@ -73,7 +134,10 @@ func (apiServer *HelixAPIServer) getSessionSummary(res http.ResponseWriter, req
 
 func (apiServer *HelixAPIServer) getSessions(res http.ResponseWriter, req *http.Request) (*types.SessionsList, error) {
 	ctx := req.Context()
-	user := getRequestUser(req)
+	user, httpErr := getRequestOwner(req, types.OrganizationRoleMember)
+	if httpErr != nil {
+		return nil, httpErr
+	}
 
 	query := store.GetSessionsQuery{}
 	query.Owner = user.ID
@@ -283,6 +347,7 @@ func (apiServer *HelixAPIServer) createSession(res http.ResponseWriter, req *htt
 		OwnerType:               user.Type,
 		UserInteractions:        []*types.Interaction{userInteraction},
 		Priority:                status.Config.StripeSubscriptionActive,
+		PriorityClass:           user.PriorityClass,
 		ParentSession:           req.FormValue("parent_session"),
 		ManuallyReviewQuestions: req.FormValue("manuallyReviewQuestions") == "yes",
 		RAGEnabled:              ragEnable,
@@ -357,6 +422,19 @@ func (apiServer *HelixAPIServer) updateSessionConfig(res http.ResponseWriter, re
 	if err != nil {
 		return nil, system.NewHTTPError400(err.Error())
 	}
+	if data == nil {
+		return nil, system.NewHTTPError400("session config is required")
+	}
+
+	// The scheduler trusts the priority of the session, keep the stored one
+	// unless the user is allowed to change it
+	user := getRequestUser(req)
+	if apiServer.validatePriorityClass(user, data.PriorityClass, session.Metadata.PriorityClass) != nil {
+		data.PriorityClass = session.Metadata.PriorityClass
+	}
+	if !isAdmin(user) {
+		data.Priority = session.Metadata.Priority
+	}
 
 	result, err := apiServer.Controller.UpdateSessionMetadata(req.Context(), session, data)
 	if err != nil {
@@ -374,7 +452,7 @@ func (apiServer *HelixAPIServer) getConfig() (types.ServerConfigForFrontend, err
 		return types.ServerConfigForFrontend{}, system.NewHTTPError500("we currently only support local filestore")
 	}
 
-	return types.ServerConfigForFrontend{
+	config := types.ServerConfigForFrontend{
 		FilestorePrefix:         filestorePrefix,
 		StripeEnabled:           apiServer.Stripe.Enabled(),
 		SentryDSNFrontend:       apiServer.Cfg.Janitor.SentryDsnFrontend,
@@ -384,7 +462,16 @@ func (apiServer *HelixAPIServer) getConfig() (types.ServerConfigForFrontend, err
 		RudderStackDataPlaneURL: apiServer.Cfg.Janitor.RudderStackDataPlaneURL,
 		ToolsEnabled:            apiServer.Cfg.Tools.Enabled,
 		AppsEnabled:             apiServer.Cfg.Apps.Enabled,
-	}, nil
+		AuthProvider:            apiServer.Cfg.Auth.Provider,
+	}
+
+	if apiServer.Cfg.Auth.Provider == types.AuthProviderOIDC {
+		config.OIDCIssuer = apiServer.Cfg.Auth.OIDC.Issuer
+		config.OIDCClientID = apiServer.Cfg.Auth.OIDC.ClientID
+		config.OIDCScopes = apiServer.Cfg.Auth.OIDC.Scopes
+	}
+
+	return config, nil
 }
 
 func (apiServer *HelixAPIServer) config(res http.ResponseWriter, req *http.Request) (types.ServerConfigForFrontend, error) {
@@ -530,6 +617,10 @@ func (apiServer *HelixAPIServer) runnerSessionDownloadFolder(res http.ResponseWr
 		if err != nil {
 			return err
 		}
+		// streamed tars must be closed to stop the download if the client goes away
+		if closer, ok := tarStream.(io.Closer); ok {
+			defer closer.Close()
+		}
 
 		// Set the appropriate mime-type headers
 		res.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s,tar", filename))
@@ -847,20 +938,34 @@ func (apiServer *HelixAPIServer) deleteSession(res http.ResponseWriter, req *htt
 		return nil, httpError
 	}
 
-	return system.DefaultController(apiServer.Store.DeleteSession(req.Context(), session.ID))
+	deleted, err := apiServer.Store.DeleteSession(req.Context(), session.ID)
+	if err != nil {
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	// The interactions are the user's conversation, they don't belong in the audit log
+	before := *session
+	before.Interactions = nil
+	apiServer.audit(req, types.AuditActionDelete, types.AuditTargetSession, session.ID, session.ParentApp, &before, nil)
+
+	return deleted, nil
 }
 
 func (apiServer *HelixAPIServer) createAPIKey(res http.ResponseWriter, req *http.Request) (string, error) {
 	newAPIKey := &types.APIKey{}
 	name := req.URL.Query().Get("name")
 
-	user := getRequestUser(req)
+	user, httpErr := getRequestOwner(req, types.OrganizationRoleAdmin)
+	if httpErr != nil {
+		return "", httpErr
+	}
 	ctx := req.Context()
 
 	if name != "" {
 		// if we are using the query string route then don't try to deode the body
 		newAPIKey.Name = name
 		newAPIKey.Type = types.APIKeyType_API
+		newAPIKey.PriorityClass = req.URL.Query().Get("priority_class")
 	} else {
 		// For now we need to manually unmarshal the body because of the sql.NullString
 		body, err := io.ReadAll(req.Body)
@@ -882,20 +987,65 @@ func (apiServer *HelixAPIServer) createAPIKey(res http.ResponseWriter, req *http
 		if err != nil {
 			return "", err
 		}
-		var apiKeyStr string
-		err = json.Unmarshal(objmap["app_id"], &apiKeyStr)
-		if err != nil {
-			return "", err
-		}
 		newAPIKey.Name = nameStr
 		newAPIKey.Type = types.APIKeyType(typeStr)
-		newAPIKey.AppID = &sql.NullString{String: apiKeyStr, Valid: true}
+		if appID, ok := objmap["app_id"]; ok {
+			var apiKeyStr string
+			err = json.Unmarshal(appID, &apiKeyStr)
+			if err != nil {
+				return "", err
+			}
+			if apiKeyStr != "" {
+				newAPIKey.AppID = &sql.NullString{String: apiKeyStr, Valid: true}
+			}
+		}
+		if priorityClass, ok := objmap["priority_class"]; ok {
+			err = json.Unmarshal(priorityClass, &newAPIKey.PriorityClass)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		// Restrictions of the key
+		var restrictions struct {
+			Scopes            types.APIKeyScopeList `json:"scopes"`
+			ExpiresAt         *time.Time            `json:"expires_at"`
+			AllowedIPs        types.StringList      `json:"allowed_ips"`
+			RequestsPerMinute int                   `json:"requests_per_minute"`
+			TokensPerMinute   int                   `json:"tokens_per_minute"`
+		}
+		err = json.Unmarshal(body, &restrictions)
+		if err != nil {
+			return "", system.NewHTTPError400(fmt.Sprintf("invalid API key restrictions: %s", err))
+		}
+		newAPIKey.Scopes = restrictions.Scopes
+		newAPIKey.ExpiresAt = restrictions.ExpiresAt
+		newAPIKey.AllowedIPs = restrictions.AllowedIPs
+		newAPIKey.RequestsPerMinute = restrictions.RequestsPerMinute
+		newAPIKey.TokensPerMinute = restrictions.TokensPerMinute
+	}
+
+	err := validateAPIKeyRestrictions(newAPIKey)
+	if err != nil {
+		return "", system.NewHTTPError400(err.Error())
+	}
+
+	err = apiServer.validatePriorityClass(getRequestUser(req), newAPIKey.PriorityClass, "")
+	if err != nil {
+		return "", system.NewHTTPError400(err.Error())
 	}
 
 	createdKey, err := apiServer.Controller.CreateAPIKey(ctx, user, newAPIKey)
 	if err != nil {
 		return "", err
 	}
+
+	var appID string
+	if createdKey.AppID != nil {
+		appID = createdKey.AppID.String
+	}
+	apiServer.audit(req, types.AuditActionCreate, types.AuditTargetAPIKey, auditAPIKeyID(createdKey.Key), appID, nil, createdKey)
+
 	return createdKey.Key, nil
 }
 
@@ -914,7 +1064,10 @@ func containsType(keyType string, typesParam string) bool {
 }
 
 func (apiServer *HelixAPIServer) getAPIKeys(res http.ResponseWriter, req *http.Request) ([]*types.APIKey, error) {
-	user := getRequestUser(req)
+	user, httpErr := getRequestOwner(req, types.OrganizationRoleAdmin)
+	if httpErr != nil {
+		return nil, httpErr
+	}
 	ctx := req.Context()
 
 	apiKeys, err := apiServer.Controller.GetAPIKeys(ctx, user)
@@ -935,7 +1088,7 @@ func (apiServer *HelixAPIServer) getAPIKeys(res http.ResponseWriter, req *http.R
 		if !includeAllTypes && !containsType(string(key.Type), typesParam) {
 			continue
 		}
-		if appIDParam != "" && (!key.AppID.Valid || key.AppID.String != appIDParam) {
+		if appIDParam != "" && (key.AppID == nil || !key.AppID.Valid || key.AppID.String != appIDParam) {
 			continue
 		}
 		filteredAPIKeys = append(filteredAPIKeys, key)
@@ -945,7 +1098,10 @@ func (apiServer *HelixAPIServer) getAPIKeys(res http.ResponseWriter, req *http.R
 }
 
 func (apiServer *HelixAPIServer) deleteAPIKey(res http.ResponseWriter, req *http.Request) (string, error) {
-	user := getRequestUser(req)
+	user, httpErr := getRequestOwner(req, types.OrganizationRoleAdmin)
+	if httpErr != nil {
+		return "", httpErr
+	}
 	ctx := req.Context()
 
 	apiKey := req.URL.Query().Get("key")
@@ -953,6 +1109,9 @@ func (apiServer *HelixAPIServer) deleteAPIKey(res http.ResponseWriter, req *http
 	if err != nil {
 		return "", err
 	}
+
+	apiServer.audit(req, types.AuditActionDelete, types.AuditTargetAPIKey, auditAPIKeyID(apiKey), "", nil, nil)
+
 	return apiKey, nil
 }
 
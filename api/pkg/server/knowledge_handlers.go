@@ -13,7 +13,10 @@ import (
 
 func (s *HelixAPIServer) listKnowledge(_ http.ResponseWriter, r *http.Request) ([]*types.Knowledge, *system.HTTPError) {
 	ctx := r.Context()
-	user := getRequestUser(r)
+	user, httpErr := getRequestOwner(r, types.OrganizationRoleMember)
+	if httpErr != nil {
+		return nil, httpErr
+	}
 
 	appID := r.URL.Query().Get("app_id")
 
@@ -51,7 +54,7 @@ func (s *HelixAPIServer) getKnowledge(_ http.ResponseWriter, r *http.Request) (*
 		return nil, system.NewHTTPError500(err.Error())
 	}
 
-	if existing.Owner != user.ID {
+	if !user.HasOwnerRole(existing.Owner, existing.OwnerType, types.OrganizationRoleMember) {
 		return nil, system.NewHTTPError403("you do not have permission to delete this knowledge")
 	}
 
@@ -70,7 +73,7 @@ func (s *HelixAPIServer) listKnowledgeVersions(_ http.ResponseWriter, r *http.Re
 		return nil, system.NewHTTPError500(err.Error())
 	}
 
-	if existing.Owner != user.ID {
+	if !user.HasOwnerRole(existing.Owner, existing.OwnerType, types.OrganizationRoleMember) {
 		return nil, system.NewHTTPError403("you do not have permission to delete this knowledge")
 	}
 
@@ -96,7 +99,7 @@ func (s *HelixAPIServer) deleteKnowledge(_ http.ResponseWriter, r *http.Request)
 		return nil, system.NewHTTPError500(err.Error())
 	}
 
-	if existing.Owner != user.ID {
+	if !user.HasOwnerRole(existing.Owner, existing.OwnerType, types.OrganizationRoleAdmin) {
 		return nil, system.NewHTTPError403("you do not have permission to delete this knowledge")
 	}
 
@@ -105,6 +108,8 @@ func (s *HelixAPIServer) deleteKnowledge(_ http.ResponseWriter, r *http.Request)
 		return nil, system.NewHTTPError500(err.Error())
 	}
 
+	s.audit(r, types.AuditActionDelete, types.AuditTargetKnowledge, existing.ID, existing.AppID, existing, nil)
+
 	return existing, nil
 }
 
@@ -169,7 +174,7 @@ func (s *HelixAPIServer) refreshKnowledge(_ http.ResponseWriter, r *http.Request
 		return nil, system.NewHTTPError500(err.Error())
 	}
 
-	if existing.Owner != user.ID {
+	if !user.HasOwnerRole(existing.Owner, existing.OwnerType, types.OrganizationRoleAdmin) {
 		return nil, system.NewHTTPError403("you do not have permission to refresh this knowledge")
 	}
 
@@ -180,14 +185,19 @@ func (s *HelixAPIServer) refreshKnowledge(_ http.ResponseWriter, r *http.Request
 		return nil, system.NewHTTPError400("knowledge is queued for indexing, please wait")
 	}
 
-	// Push back to pending
+	before := auditSnapshotOf(existing)
+
+	// Push back to pending, a manual refresh indexes everything again
 	existing.State = types.KnowledgeStatePending
 	existing.Message = ""
+	existing.SourceRevision = ""
 
 	updated, err := s.Store.UpdateKnowledge(r.Context(), existing)
 	if err != nil {
 		return nil, system.NewHTTPError500(err.Error())
 	}
 
+	s.audit(r, types.AuditActionRefresh, types.AuditTargetKnowledge, updated.ID, updated.AppID, before, updated)
+
 	return updated, nil
 }
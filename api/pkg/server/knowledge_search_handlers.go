@@ -49,20 +49,9 @@ func (s *HelixAPIServer) knowledgeSearch(_ http.ResponseWriter, r *http.Request)
 	for _, knowledge := range knowledges {
 		knowledge := knowledge
 
-		client, err := s.Controller.GetRagClient(ctx, knowledge)
-		if err != nil {
-			return nil, system.NewHTTPError500(err.Error())
-		}
-
 		pool.Go(func() error {
 			start := time.Now()
-			resp, err := client.Query(ctx, &types.SessionRAGQuery{
-				Prompt:            prompt,
-				DataEntityID:      knowledge.GetDataEntityID(),
-				DistanceThreshold: knowledge.RAGSettings.Threshold,
-				DistanceFunction:  knowledge.RAGSettings.DistanceFunction,
-				MaxResults:        knowledge.RAGSettings.ResultsCount,
-			})
+			resp, err := s.Controller.QueryKnowledge(ctx, knowledge, prompt)
 			if err != nil {
 				return fmt.Errorf("error querying RAG for knowledge %s: %w", knowledge.ID, err)
 			}
@@ -56,8 +56,8 @@ func (s *HelixAPIServer) startLearnSessionHandler(rw http.ResponseWriter, req *h
 		return
 	}
 
-	if dataEntity.Owner != user.ID {
-		http.Error(rw, "you must own the data entity", http.StatusBadRequest)
+	if !user.HasOwnerRole(dataEntity.Owner, dataEntity.OwnerType, types.OrganizationRoleMember) {
+		http.Error(rw, "you do not have access to the data entity", http.StatusForbidden)
 		return
 	}
 
@@ -100,6 +100,7 @@ func (s *HelixAPIServer) startLearnSessionHandler(rw http.ResponseWriter, req *h
 		OwnerType:           user.Type,
 		UserInteractions:    []*types.Interaction{userInteraction},
 		Priority:            status.Config.StripeSubscriptionActive,
+		PriorityClass:       user.PriorityClass,
 		UploadedDataID:      dataEntity.ID,
 		RAGEnabled:          startReq.RagEnabled,
 		TextFinetuneEnabled: startReq.TextFinetuneEnabled,
@@ -78,6 +78,7 @@ func (s *HelixAPIServer) createChatCompletion(rw http.ResponseWriter, r *http.Re
 		InteractionID:   "n/a",
 		OriginalRequest: body,
 	})
+	ctx = s.withAPIKeyUsage(ctx, user)
 
 	options := &controller.ChatCompletionOptions{
 		AppID:       r.URL.Query().Get("app_id"),
@@ -142,7 +143,7 @@ func (s *HelixAPIServer) createChatCompletion(rw http.ResponseWriter, r *http.Re
 		resp, _, err := s.Controller.ChatCompletion(ctx, user, chatCompletionRequest, options)
 		if err != nil {
 			log.Error().Err(err).Msg("error creating chat completion")
-			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			http.Error(rw, err.Error(), chatCompletionErrorStatus(err))
 			return
 		}
 
@@ -171,7 +172,7 @@ func (s *HelixAPIServer) createChatCompletion(rw http.ResponseWriter, r *http.Re
 	// Streaming request, receive and write the stream in chunks
 	stream, _, err := s.Controller.ChatCompletionStream(ctx, user, chatCompletionRequest, options)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		http.Error(rw, err.Error(), chatCompletionErrorStatus(err))
 		return
 	}
 	defer stream.Close()
@@ -209,6 +210,15 @@ func (s *HelixAPIServer) createChatCompletion(rw http.ResponseWriter, r *http.Re
 
 }
 
+// chatCompletionErrorStatus returns 429 when the usage budget is used up so
+// the clients can tell it apart from the failures of the LLM
+func chatCompletionErrorStatus(err error) int {
+	if errors.Is(err, controller.ErrUsageBudgetExceeded) {
+		return http.StatusTooManyRequests
+	}
+	return http.StatusInternalServerError
+}
+
 func (s *HelixAPIServer) getAppLoraAssistant(ctx context.Context, appID string) (*types.AssistantConfig, error) {
 	app, err := s.Store.GetApp(ctx, appID)
 	if err != nil {
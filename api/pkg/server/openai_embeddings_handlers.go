@@ -0,0 +1,110 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/helixml/helix/api/pkg/model"
+	oai "github.com/helixml/helix/api/pkg/openai"
+	"github.com/helixml/helix/api/pkg/openai/manager"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+// POST https://app.tryhelix.ai/v1/embeddings
+
+// createEmbeddings godoc
+// @Summary Creates embeddings
+// @Description Creates an embedding vector representing the input text. Helix models are run on the runners, use the provider query parameter to use another provider.
+// @Tags    embeddings
+// @Success 200 {object} openai.EmbeddingResponse
+// @Param request    body openai.EmbeddingRequest true "Request body with the input and the model")
+// @Param provider   query string false "Provider, defaults to the inference provider"
+// @Router /v1/embeddings [post]
+// @Security BearerAuth
+// @externalDocs.url https://platform.openai.com/docs/api-reference/embeddings/create
+func (s *HelixAPIServer) createEmbeddings(rw http.ResponseWriter, r *http.Request) {
+	addCorsHeaders(rw)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	user := getRequestUser(r)
+
+	if !hasUser(user) {
+		http.Error(rw, "unauthorized", http.StatusUnauthorized)
+		log.Error().Msg("unauthorized")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 10*MEGABYTE))
+	if err != nil {
+		log.Error().Err(err).Msg("error reading body")
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var embeddingRequest openai.EmbeddingRequest
+	err = json.Unmarshal(body, &embeddingRequest)
+	if err != nil {
+		log.Error().Err(err).Msg("error unmarshalling body")
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if embeddingRequest.Input == nil {
+		http.Error(rw, "input is required", http.StatusBadRequest)
+		return
+	}
+
+	provider := types.Provider(r.URL.Query().Get("provider"))
+	if provider == "" {
+		provider = s.Cfg.Inference.Provider
+	}
+
+	if provider == types.ProviderHelix {
+		if embeddingRequest.Model == "" {
+			embeddingRequest.Model = openai.EmbeddingModel(model.Model_Ollama_DefaultEmbeddings)
+		}
+
+		// Unknown models would never be scheduled onto the runners
+		_, err = model.GetModel(string(embeddingRequest.Model))
+		if err != nil {
+			http.Error(rw, "invalid model name: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	ctx := oai.SetContextValues(r.Context(), &oai.ContextValues{
+		OwnerID:         user.ID,
+		SessionID:       "n/a",
+		InteractionID:   "n/a",
+		OriginalRequest: body,
+	})
+
+	client, err := s.providerManager.GetClient(ctx, &manager.GetClientRequest{
+		Provider: provider,
+	})
+	if err != nil {
+		log.Err(err).Msg("error getting client")
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp, err := client.CreateEmbeddings(ctx, embeddingRequest)
+	if err != nil {
+		log.Error().Err(err).Msg("error creating embeddings")
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	err = json.NewEncoder(rw).Encode(resp)
+	if err != nil {
+		log.Error().Err(err).Msg("error writing response")
+	}
+}
@@ -0,0 +1,335 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+// listOrganizations godoc
+// @Summary List organizations
+// @Description List the organizations the user is a member of.
+// @Tags    organizations
+
+// @Success 200 {array} types.Organization
+// @Router /api/v1/organizations [get]
+// @Security BearerAuth
+func (s *HelixAPIServer) listOrganizations(_ http.ResponseWriter, r *http.Request) ([]*types.Organization, *system.HTTPError) {
+	user := getRequestUser(r)
+
+	if user.Type == types.OwnerTypeOrg {
+		org, err := s.Store.GetOrganization(r.Context(), user.ID)
+		if err != nil {
+			return nil, system.NewHTTPError500(err.Error())
+		}
+		return []*types.Organization{org}, nil
+	}
+
+	orgs, err := s.Store.ListOrganizations(r.Context(), &store.ListOrganizationsQuery{
+		UserID: user.ID,
+	})
+	if err != nil {
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	return orgs, nil
+}
+
+// createOrganization godoc
+// @Summary Create new organization
+// @Description Create new organization, the user becomes its owner.
+// @Tags    organizations
+
+// @Success 200 {object} types.Organization
+// @Param request    body types.Organization true "Request body with the organization name.")
+// @Router /api/v1/organizations [post]
+// @Security BearerAuth
+func (s *HelixAPIServer) createOrganization(_ http.ResponseWriter, r *http.Request) (*types.Organization, *system.HTTPError) {
+	user := getRequestUser(r)
+
+	if user.Type == types.OwnerTypeOrg {
+		return nil, system.NewHTTPError403("organization API keys can't create organizations")
+	}
+
+	var org types.Organization
+	err := json.NewDecoder(r.Body).Decode(&org)
+	if err != nil {
+		return nil, system.NewHTTPError400("failed to decode request body, error: %s", err)
+	}
+
+	if org.Name == "" {
+		return nil, system.NewHTTPError400("organization name is required")
+	}
+
+	created, err := s.Store.CreateOrganization(r.Context(), &types.Organization{
+		Name:  org.Name,
+		Owner: user.ID,
+	})
+	if err != nil {
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	return created, nil
+}
+
+// getOrganization godoc
+// @Summary Get organization by ID
+// @Description Get organization by ID.
+// @Tags    organizations
+
+// @Success 200 {object} types.Organization
+// @Param id path string true "Organization ID"
+// @Router /api/v1/organizations/{id} [get]
+// @Security BearerAuth
+func (s *HelixAPIServer) getOrganization(_ http.ResponseWriter, r *http.Request) (*types.Organization, *system.HTTPError) {
+	return s.organizationLoader(r, types.OrganizationRoleMember)
+}
+
+// updateOrganization godoc
+// @Summary Update an existing organization
+// @Description Rename the organization, admins only.
+// @Tags    organizations
+
+// @Success 200 {object} types.Organization
+// @Param request    body types.Organization true "Request body with the organization name.")
+// @Param id path string true "Organization ID"
+// @Router /api/v1/organizations/{id} [put]
+// @Security BearerAuth
+func (s *HelixAPIServer) updateOrganization(_ http.ResponseWriter, r *http.Request) (*types.Organization, *system.HTTPError) {
+	existing, httpErr := s.organizationLoader(r, types.OrganizationRoleAdmin)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	var update types.Organization
+	err := json.NewDecoder(r.Body).Decode(&update)
+	if err != nil {
+		return nil, system.NewHTTPError400("failed to decode request body, error: %s", err)
+	}
+
+	if update.Name == "" {
+		return nil, system.NewHTTPError400("organization name is required")
+	}
+
+	existing.Name = update.Name
+
+	updated, err := s.Store.UpdateOrganization(r.Context(), existing)
+	if err != nil {
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	return updated, nil
+}
+
+// deleteOrganization godoc
+// @Summary Delete organization
+// @Description Delete the organization and its memberships, owners only. The apps, knowledge and sessions of the organization are kept.
+// @Tags    organizations
+
+// @Success 200
+// @Param id path string true "Organization ID"
+// @Router /api/v1/organizations/{id} [delete]
+// @Security BearerAuth
+func (s *HelixAPIServer) deleteOrganization(_ http.ResponseWriter, r *http.Request) (*types.Organization, *system.HTTPError) {
+	existing, httpErr := s.organizationLoader(r, types.OrganizationRoleOwner)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	err := s.Store.DeleteOrganization(r.Context(), existing.ID)
+	if err != nil {
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	return existing, nil
+}
+
+// listOrganizationMembers godoc
+// @Summary List organization members
+// @Description List the members of the organization and their roles.
+// @Tags    organizations
+
+// @Success 200 {array} types.OrganizationMembership
+// @Param id path string true "Organization ID"
+// @Router /api/v1/organizations/{id}/members [get]
+// @Security BearerAuth
+func (s *HelixAPIServer) listOrganizationMembers(_ http.ResponseWriter, r *http.Request) ([]*types.OrganizationMembership, *system.HTTPError) {
+	org, httpErr := s.organizationLoader(r, types.OrganizationRoleMember)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	memberships, err := s.Store.ListOrganizationMemberships(r.Context(), &store.ListOrganizationMembershipsQuery{
+		OrganizationID: org.ID,
+	})
+	if err != nil {
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	return memberships, nil
+}
+
+// updateOrganizationMember godoc
+// @Summary Add or update an organization member
+// @Description Add the user to the organization or change their role, admins only. Only owners can grant the owner role or change the role of other owners.
+// @Tags    organizations
+
+// @Success 200 {object} types.OrganizationMembership
+// @Param request    body types.OrganizationMembership true "Request body with the role.")
+// @Param id path string true "Organization ID"
+// @Param user_id path string true "User ID"
+// @Router /api/v1/organizations/{id}/members/{user_id} [put]
+// @Security BearerAuth
+func (s *HelixAPIServer) updateOrganizationMember(_ http.ResponseWriter, r *http.Request) (*types.OrganizationMembership, *system.HTTPError) {
+	user := getRequestUser(r)
+
+	org, httpErr := s.organizationLoader(r, types.OrganizationRoleAdmin)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	var update types.OrganizationMembership
+	err := json.NewDecoder(r.Body).Decode(&update)
+	if err != nil {
+		return nil, system.NewHTTPError400("failed to decode request body, error: %s", err)
+	}
+
+	if !update.Role.Valid() {
+		return nil, system.NewHTTPError400("invalid role '%s', must be one of owner, admin or member", update.Role)
+	}
+
+	userID := mux.Vars(r)["user_id"]
+
+	existing, err := s.Store.GetOrganizationMembership(r.Context(), org.ID, userID)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	isOwner := isAdmin(user) || user.HasOwnerRole(org.ID, types.OwnerTypeOrg, types.OrganizationRoleOwner)
+	if update.Role == types.OrganizationRoleOwner && !isOwner {
+		return nil, system.NewHTTPError403("only owners can grant the owner role")
+	}
+
+	if existing != nil && existing.Role == types.OrganizationRoleOwner && update.Role != types.OrganizationRoleOwner {
+		if !isOwner {
+			return nil, system.NewHTTPError403("only owners can change the role of other owners")
+		}
+
+		httpErr = s.ensureAnotherOwner(r, org.ID, userID)
+		if httpErr != nil {
+			return nil, httpErr
+		}
+	}
+
+	membership, err := s.Store.SaveOrganizationMembership(r.Context(), &types.OrganizationMembership{
+		OrganizationID: org.ID,
+		UserID:         userID,
+		Role:           update.Role,
+	})
+	if err != nil {
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	return membership, nil
+}
+
+// deleteOrganizationMember godoc
+// @Summary Remove an organization member
+// @Description Remove the user from the organization. Admins can remove members, owners can remove anyone and every member can leave.
+// @Tags    organizations
+
+// @Success 200
+// @Param id path string true "Organization ID"
+// @Param user_id path string true "User ID"
+// @Router /api/v1/organizations/{id}/members/{user_id} [delete]
+// @Security BearerAuth
+func (s *HelixAPIServer) deleteOrganizationMember(_ http.ResponseWriter, r *http.Request) (*types.OrganizationMembership, *system.HTTPError) {
+	user := getRequestUser(r)
+	userID := mux.Vars(r)["user_id"]
+
+	// Every member can leave the organization
+	role := types.OrganizationRoleAdmin
+	if userID == user.ID {
+		role = types.OrganizationRoleMember
+	}
+
+	org, httpErr := s.organizationLoader(r, role)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	existing, err := s.Store.GetOrganizationMembership(r.Context(), org.ID, userID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, system.NewHTTPError404(store.ErrNotFound.Error())
+		}
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	if existing.Role == types.OrganizationRoleOwner {
+		if userID != user.ID && !isAdmin(user) && !user.HasOwnerRole(org.ID, types.OwnerTypeOrg, types.OrganizationRoleOwner) {
+			return nil, system.NewHTTPError403("only owners can remove other owners")
+		}
+
+		httpErr = s.ensureAnotherOwner(r, org.ID, userID)
+		if httpErr != nil {
+			return nil, httpErr
+		}
+	}
+
+	err = s.Store.DeleteOrganizationMembership(r.Context(), org.ID, userID)
+	if err != nil {
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	return existing, nil
+}
+
+// organizationLoader loads the organization of the request if the user has at
+// least the role in it
+func (s *HelixAPIServer) organizationLoader(r *http.Request, role types.OrganizationRole) (*types.Organization, *system.HTTPError) {
+	user := getRequestUser(r)
+	id := getID(r)
+
+	// Organization API keys can't manage the organization
+	if user.Type == types.OwnerTypeOrg && role != types.OrganizationRoleMember {
+		return nil, system.NewHTTPError403("organization API keys can't manage the organization")
+	}
+
+	if !isAdmin(user) && !user.HasOwnerRole(id, types.OwnerTypeOrg, role) {
+		return nil, system.NewHTTPError404(store.ErrNotFound.Error())
+	}
+
+	org, err := s.Store.GetOrganization(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, system.NewHTTPError404(store.ErrNotFound.Error())
+		}
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	return org, nil
+}
+
+// ensureAnotherOwner makes sure the organization keeps an owner without the user
+func (s *HelixAPIServer) ensureAnotherOwner(r *http.Request, orgID, userID string) *system.HTTPError {
+	memberships, err := s.Store.ListOrganizationMemberships(r.Context(), &store.ListOrganizationMembershipsQuery{
+		OrganizationID: orgID,
+	})
+	if err != nil {
+		return system.NewHTTPError500(err.Error())
+	}
+
+	for _, membership := range memberships {
+		if membership.UserID != userID && membership.Role == types.OrganizationRoleOwner {
+			return nil
+		}
+	}
+
+	return system.NewHTTPError400("the organization must keep at least one owner")
+}
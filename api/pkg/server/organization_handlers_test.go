@@ -0,0 +1,230 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/helixml/helix/api/pkg/auth"
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/controller"
+	"github.com/helixml/helix/api/pkg/janitor"
+	"github.com/helixml/helix/api/pkg/pubsub"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/tools"
+	"github.com/helixml/helix/api/pkg/types"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+func TestOrganizationsSuite(t *testing.T) {
+	suite.Run(t, new(OrganizationsTestSuite))
+}
+
+type OrganizationsTestSuite struct {
+	suite.Suite
+
+	store  *store.MockStore
+	pubsub pubsub.PubSub
+
+	userID string
+	orgID  string
+
+	server *HelixAPIServer
+}
+
+func (suite *OrganizationsTestSuite) SetupTest() {
+	ctrl := gomock.NewController(suite.T())
+
+	suite.store = store.NewMockStore(ctrl)
+	ps, err := pubsub.New(suite.T().TempDir())
+	suite.NoError(err)
+
+	suite.pubsub = ps
+	suite.userID = "user_id"
+	suite.orgID = "org_id"
+
+	user := types.User{
+		ID:       suite.userID,
+		Email:    "foo@email.com",
+		FullName: "Foo Bar",
+	}
+
+	janitor := janitor.NewJanitor(config.Janitor{})
+
+	suite.server = &HelixAPIServer{
+		Cfg:     &config.ServerConfig{},
+		pubsub:  suite.pubsub,
+		Store:   suite.store,
+		Janitor: janitor,
+		authMiddleware: &authMiddleware{
+			store:         suite.store,
+			authenticator: auth.NewMockAuthenticator(&user),
+			apiKeyLimiter: newAPIKeyLimiter(),
+		},
+		Controller: &controller.Controller{
+			ToolsPlanner: &tools.ChainStrategy{},
+			Options: controller.ControllerOptions{
+				Store:   suite.store,
+				Janitor: janitor,
+			},
+		},
+	}
+
+	_, err = suite.server.registerRoutes(context.Background())
+	suite.NoError(err)
+}
+
+// authenticate expects the API key of the user who has the role in the organization
+func (suite *OrganizationsTestSuite) authenticate(role types.OrganizationRole) {
+	suite.store.EXPECT().GetAPIKey(gomock.Any(), "hl-API_KEY").Return(&types.APIKey{
+		Owner:     suite.userID,
+		OwnerType: types.OwnerTypeUser,
+	}, nil)
+	suite.store.EXPECT().UpdateAPIKeyLastUsed(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	var memberships []*types.OrganizationMembership
+	if role != "" {
+		memberships = append(memberships, &types.OrganizationMembership{
+			OrganizationID: suite.orgID,
+			UserID:         suite.userID,
+			Role:           role,
+		})
+	}
+
+	suite.store.EXPECT().ListOrganizationMemberships(gomock.Any(), &store.ListOrganizationMembershipsQuery{
+		UserID: suite.userID,
+	}).Return(memberships, nil)
+}
+
+func (suite *OrganizationsTestSuite) serve(method, path string, body interface{}) *httptest.ResponseRecorder {
+	bts, err := json.Marshal(body)
+	suite.NoError(err)
+
+	req, err := http.NewRequest(method, path, bytes.NewBuffer(bts))
+	suite.NoError(err)
+	req.Header.Set("Authorization", "Bearer hl-API_KEY")
+
+	rec := httptest.NewRecorder()
+	suite.server.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func (suite *OrganizationsTestSuite) TestListApps_Organization() {
+	suite.authenticate(types.OrganizationRoleMember)
+
+	orgApps := []*types.App{
+		{
+			ID:        "app_1",
+			Owner:     suite.orgID,
+			OwnerType: types.OwnerTypeOrg,
+		},
+	}
+
+	suite.store.EXPECT().ListApps(gomock.Any(), &store.ListAppsQuery{
+		Owner:     suite.orgID,
+		OwnerType: types.OwnerTypeOrg,
+	}).Return(orgApps, nil)
+
+	suite.store.EXPECT().ListApps(gomock.Any(), &store.ListAppsQuery{
+		Global: true,
+	}).Return([]*types.App{}, nil)
+
+	rec := suite.serve("GET", "/api/v1/apps?org_id="+suite.orgID, nil)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+
+	var resp []*types.App
+	suite.NoError(json.NewDecoder(rec.Body).Decode(&resp))
+	suite.Require().Len(resp, 1)
+	suite.Equal("app_1", resp[0].ID)
+}
+
+func (suite *OrganizationsTestSuite) TestListApps_NotMember() {
+	suite.authenticate("")
+
+	rec := suite.serve("GET", "/api/v1/apps?org_id="+suite.orgID, nil)
+	suite.Equal(http.StatusForbidden, rec.Code)
+}
+
+func (suite *OrganizationsTestSuite) TestDeleteApp_MemberForbidden() {
+	suite.authenticate(types.OrganizationRoleMember)
+
+	suite.store.EXPECT().GetApp(gomock.Any(), "app_1").Return(&types.App{
+		ID:        "app_1",
+		Owner:     suite.orgID,
+		OwnerType: types.OwnerTypeOrg,
+	}, nil)
+
+	rec := suite.serve("DELETE", "/api/v1/apps/app_1", nil)
+	suite.Equal(http.StatusForbidden, rec.Code)
+}
+
+func (suite *OrganizationsTestSuite) TestUpdateMember_AdminCannotGrantOwner() {
+	suite.authenticate(types.OrganizationRoleAdmin)
+
+	suite.store.EXPECT().GetOrganization(gomock.Any(), suite.orgID).Return(&types.Organization{
+		ID:   suite.orgID,
+		Name: "team",
+	}, nil)
+	suite.store.EXPECT().GetOrganizationMembership(gomock.Any(), suite.orgID, "other_user").Return(nil, store.ErrNotFound)
+
+	rec := suite.serve("PUT", "/api/v1/organizations/"+suite.orgID+"/members/other_user", &types.OrganizationMembership{
+		Role: types.OrganizationRoleOwner,
+	})
+	suite.Equal(http.StatusForbidden, rec.Code)
+}
+
+func (suite *OrganizationsTestSuite) TestUpdateMember_AddMember() {
+	suite.authenticate(types.OrganizationRoleAdmin)
+
+	suite.store.EXPECT().GetOrganization(gomock.Any(), suite.orgID).Return(&types.Organization{
+		ID:   suite.orgID,
+		Name: "team",
+	}, nil)
+	suite.store.EXPECT().GetOrganizationMembership(gomock.Any(), suite.orgID, "other_user").Return(nil, store.ErrNotFound)
+	suite.store.EXPECT().SaveOrganizationMembership(gomock.Any(), &types.OrganizationMembership{
+		OrganizationID: suite.orgID,
+		UserID:         "other_user",
+		Role:           types.OrganizationRoleMember,
+	}).DoAndReturn(func(_ context.Context, membership *types.OrganizationMembership) (*types.OrganizationMembership, error) {
+		return membership, nil
+	})
+
+	rec := suite.serve("PUT", "/api/v1/organizations/"+suite.orgID+"/members/other_user", &types.OrganizationMembership{
+		Role: types.OrganizationRoleMember,
+	})
+	suite.Require().Equal(http.StatusOK, rec.Code)
+
+	var resp types.OrganizationMembership
+	suite.NoError(json.NewDecoder(rec.Body).Decode(&resp))
+	suite.Equal(types.OrganizationRoleMember, resp.Role)
+}
+
+func (suite *OrganizationsTestSuite) TestDeleteMember_LastOwner() {
+	suite.authenticate(types.OrganizationRoleOwner)
+
+	suite.store.EXPECT().GetOrganization(gomock.Any(), suite.orgID).Return(&types.Organization{
+		ID:   suite.orgID,
+		Name: "team",
+	}, nil)
+	suite.store.EXPECT().GetOrganizationMembership(gomock.Any(), suite.orgID, suite.userID).Return(&types.OrganizationMembership{
+		OrganizationID: suite.orgID,
+		UserID:         suite.userID,
+		Role:           types.OrganizationRoleOwner,
+	}, nil)
+	suite.store.EXPECT().ListOrganizationMemberships(gomock.Any(), &store.ListOrganizationMembershipsQuery{
+		OrganizationID: suite.orgID,
+	}).Return([]*types.OrganizationMembership{
+		{
+			OrganizationID: suite.orgID,
+			UserID:         suite.userID,
+			Role:           types.OrganizationRoleOwner,
+		},
+	}, nil)
+
+	rec := suite.serve("DELETE", "/api/v1/organizations/"+suite.orgID+"/members/"+suite.userID, nil)
+	suite.Equal(http.StatusBadRequest, rec.Code)
+}
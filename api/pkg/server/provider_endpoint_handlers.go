@@ -0,0 +1,235 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+// listProviderEndpoints godoc
+// @Summary List provider endpoints
+// @Description List the OpenAI compatible endpoints registered at runtime. API keys are never returned.
+// @Tags    providers
+// @Produce json
+// @Success 200 {array} types.ProviderEndpoint
+// @Router /api/v1/provider_endpoints [get]
+// @Security BearerAuth
+func (s *HelixAPIServer) listProviderEndpoints(_ http.ResponseWriter, r *http.Request) ([]*types.ProviderEndpoint, *system.HTTPError) {
+	endpoints, err := s.Store.ListProviderEndpoints(r.Context())
+	if err != nil {
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	for _, endpoint := range endpoints {
+		endpoint.APIKey = ""
+	}
+
+	return endpoints, nil
+}
+
+// getProviderEndpoint godoc
+// @Summary Get provider endpoint
+// @Description Get a provider endpoint by ID
+// @Tags    providers
+// @Produce json
+// @Param id path string true "Provider endpoint ID"
+// @Success 200 {object} types.ProviderEndpoint
+// @Router /api/v1/provider_endpoints/{id} [get]
+// @Security BearerAuth
+func (s *HelixAPIServer) getProviderEndpoint(_ http.ResponseWriter, r *http.Request) (*types.ProviderEndpoint, *system.HTTPError) {
+	endpoint, err := s.Store.GetProviderEndpoint(r.Context(), &store.GetProviderEndpointQuery{ID: getID(r)})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, system.NewHTTPError404(store.ErrNotFound.Error())
+		}
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	endpoint.APIKey = ""
+
+	return endpoint, nil
+}
+
+// createProviderEndpoint godoc
+// @Summary Create provider endpoint
+// @Description Register an OpenAI compatible endpoint (e.g. vLLM, Ollama or Azure OpenAI). Apps use it by setting its name as the assistant provider.
+// @Tags    providers
+// @Accept  json
+// @Produce json
+// @Param request body types.ProviderEndpoint true "Provider endpoint"
+// @Success 200 {object} types.ProviderEndpoint
+// @Router /api/v1/provider_endpoints [post]
+// @Security BearerAuth
+func (s *HelixAPIServer) createProviderEndpoint(_ http.ResponseWriter, r *http.Request) (*types.ProviderEndpoint, *system.HTTPError) {
+	var endpoint types.ProviderEndpoint
+	err := json.NewDecoder(r.Body).Decode(&endpoint)
+	if err != nil {
+		return nil, system.NewHTTPError400("failed to decode request body, error: %s", err)
+	}
+
+	err = validateProviderEndpoint(&endpoint)
+	if err != nil {
+		return nil, system.NewHTTPError400(err.Error())
+	}
+
+	_, err = s.Store.GetProviderEndpoint(r.Context(), &store.GetProviderEndpointQuery{Name: endpoint.Name})
+	if err == nil {
+		return nil, system.NewHTTPError400("provider endpoint with name %s already exists", endpoint.Name)
+	}
+	if !errors.Is(err, store.ErrNotFound) {
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	if endpoint.APIKey != "" {
+		endpoint.APIKey, err = system.Encrypt(s.Cfg.Providers.EncryptionKey, endpoint.APIKey)
+		if err != nil {
+			return nil, system.NewHTTPError500("failed to encrypt API key: %s", err)
+		}
+	}
+
+	endpoint.ID = ""
+
+	created, err := s.Store.CreateProviderEndpoint(r.Context(), &endpoint)
+	if err != nil {
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	s.audit(r, types.AuditActionCreate, types.AuditTargetProviderEndpoint, created.ID, "", nil, created)
+
+	created.APIKey = ""
+
+	return created, nil
+}
+
+// updateProviderEndpoint godoc
+// @Summary Update provider endpoint
+// @Description Update a provider endpoint. The API key is kept if it's not set in the request.
+// @Tags    providers
+// @Accept  json
+// @Produce json
+// @Param id path string true "Provider endpoint ID"
+// @Param request body types.ProviderEndpoint true "Provider endpoint"
+// @Success 200 {object} types.ProviderEndpoint
+// @Router /api/v1/provider_endpoints/{id} [put]
+// @Security BearerAuth
+func (s *HelixAPIServer) updateProviderEndpoint(_ http.ResponseWriter, r *http.Request) (*types.ProviderEndpoint, *system.HTTPError) {
+	var endpoint types.ProviderEndpoint
+	err := json.NewDecoder(r.Body).Decode(&endpoint)
+	if err != nil {
+		return nil, system.NewHTTPError400("failed to decode request body, error: %s", err)
+	}
+
+	existing, err := s.Store.GetProviderEndpoint(r.Context(), &store.GetProviderEndpointQuery{ID: getID(r)})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, system.NewHTTPError404(store.ErrNotFound.Error())
+		}
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	err = validateProviderEndpoint(&endpoint)
+	if err != nil {
+		return nil, system.NewHTTPError400(err.Error())
+	}
+
+	if endpoint.Name != existing.Name {
+		_, err = s.Store.GetProviderEndpoint(r.Context(), &store.GetProviderEndpointQuery{Name: endpoint.Name})
+		if err == nil {
+			return nil, system.NewHTTPError400("provider endpoint with name %s already exists", endpoint.Name)
+		}
+		if !errors.Is(err, store.ErrNotFound) {
+			return nil, system.NewHTTPError500(err.Error())
+		}
+	}
+
+	if endpoint.APIKey == "" {
+		endpoint.APIKey = existing.APIKey
+	} else {
+		endpoint.APIKey, err = system.Encrypt(s.Cfg.Providers.EncryptionKey, endpoint.APIKey)
+		if err != nil {
+			return nil, system.NewHTTPError500("failed to encrypt API key: %s", err)
+		}
+	}
+
+	endpoint.ID = existing.ID
+	endpoint.Created = existing.Created
+
+	updated, err := s.Store.UpdateProviderEndpoint(r.Context(), &endpoint)
+	if err != nil {
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	s.audit(r, types.AuditActionUpdate, types.AuditTargetProviderEndpoint, updated.ID, "", existing, updated)
+
+	updated.APIKey = ""
+
+	return updated, nil
+}
+
+// deleteProviderEndpoint godoc
+// @Summary Delete provider endpoint
+// @Description Delete a provider endpoint, apps using it will fail until it's registered again
+// @Tags    providers
+// @Param id path string true "Provider endpoint ID"
+// @Success 200 {object} types.ProviderEndpoint
+// @Router /api/v1/provider_endpoints/{id} [delete]
+// @Security BearerAuth
+func (s *HelixAPIServer) deleteProviderEndpoint(_ http.ResponseWriter, r *http.Request) (*types.ProviderEndpoint, *system.HTTPError) {
+	existing, err := s.Store.GetProviderEndpoint(r.Context(), &store.GetProviderEndpointQuery{ID: getID(r)})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, system.NewHTTPError404(store.ErrNotFound.Error())
+		}
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	err = s.Store.DeleteProviderEndpoint(r.Context(), existing.ID)
+	if err != nil {
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	s.audit(r, types.AuditActionDelete, types.AuditTargetProviderEndpoint, existing.ID, "", existing, nil)
+
+	existing.APIKey = ""
+
+	return existing, nil
+}
+
+func validateProviderEndpoint(endpoint *types.ProviderEndpoint) error {
+	if endpoint.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	if types.Provider(endpoint.Name).IsBuiltin() {
+		return fmt.Errorf("name '%s' is reserved for a built-in provider", endpoint.Name)
+	}
+
+	u, err := url.Parse(endpoint.BaseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("base URL must be an http(s) URL")
+	}
+
+	switch endpoint.Type {
+	case "":
+		endpoint.Type = types.ProviderEndpointTypeOpenAI
+	case types.ProviderEndpointTypeOpenAI:
+	case types.ProviderEndpointTypeAzure:
+		// Azure deployments are addressed by the model name
+		if len(endpoint.Models) == 0 {
+			return fmt.Errorf("models are required for Azure endpoints")
+		}
+		if endpoint.APIVersion == "" {
+			return fmt.Errorf("api_version is required for Azure endpoints")
+		}
+	default:
+		return fmt.Errorf("unknown endpoint type '%s'", endpoint.Type)
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func TestValidateProviderEndpoint(t *testing.T) {
+	endpoint := &types.ProviderEndpoint{Name: "vllm", BaseURL: "http://vllm:8000/v1"}
+	assert.NoError(t, validateProviderEndpoint(endpoint))
+	assert.Equal(t, types.ProviderEndpointTypeOpenAI, endpoint.Type)
+
+	assert.NoError(t, validateProviderEndpoint(&types.ProviderEndpoint{
+		Name:       "azure-eu",
+		Type:       types.ProviderEndpointTypeAzure,
+		BaseURL:    "https://helix.openai.azure.com",
+		APIVersion: "2024-02-01",
+		Models:     types.StringList{"gpt-4o"},
+	}))
+
+	assert.ErrorContains(t, validateProviderEndpoint(&types.ProviderEndpoint{BaseURL: "http://vllm:8000/v1"}), "name is required")
+	assert.ErrorContains(t, validateProviderEndpoint(&types.ProviderEndpoint{Name: "openai", BaseURL: "http://vllm:8000/v1"}), "reserved for a built-in provider")
+	assert.ErrorContains(t, validateProviderEndpoint(&types.ProviderEndpoint{Name: "vllm", BaseURL: "vllm:8000"}), "base URL must be an http(s) URL")
+	assert.ErrorContains(t, validateProviderEndpoint(&types.ProviderEndpoint{Name: "vllm", BaseURL: "http://vllm:8000/v1", Type: "anthropic"}), "unknown endpoint type")
+	assert.ErrorContains(t, validateProviderEndpoint(&types.ProviderEndpoint{Name: "azure-eu", Type: types.ProviderEndpointTypeAzure, BaseURL: "https://helix.openai.azure.com", Models: types.StringList{"gpt-4o"}}), "api_version is required")
+}
@@ -196,5 +196,11 @@ func (apiServer *HelixAPIServer) handleRunnerMetrics(res http.ResponseWriter, re
 	if err != nil {
 		return nil, err
 	}
-	return runnerState, nil
+
+	// Tell the runner which sessions to stop to make room for higher priority work,
+	// on a copy as the controller keeps the state for the dashboard
+	resp := *runnerState
+	resp.PreemptedSessions = apiServer.scheduler.PreemptedSessions(runnerState.ID)
+
+	return &resp, nil
 }
@@ -26,6 +26,8 @@ import (
 	"github.com/helixml/helix/api/pkg/store"
 	"github.com/helixml/helix/api/pkg/stripe"
 	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/trigger/webhook"
+	"github.com/helixml/helix/api/pkg/types"
 
 	_ "net/http/pprof"
 )
@@ -68,6 +70,7 @@ type HelixAPIServer struct {
 	knowledgeManager  knowledge.KnowledgeManager
 	router            *mux.Router
 	scheduler         scheduler.Scheduler
+	webhookTrigger    *webhook.Webhook
 }
 
 func NewServer(
@@ -112,14 +115,16 @@ func NewServer(
 			authenticator,
 			store,
 			authMiddlewareConfig{
-				adminUserIDs: cfg.WebServer.AdminIDs,
-				runnerToken:  cfg.WebServer.RunnerToken,
+				adminUserIDs:   cfg.WebServer.AdminIDs,
+				runnerToken:    cfg.WebServer.RunnerToken,
+				trustedProxies: cfg.WebServer.TrustedProxies,
 			},
 		),
 		providerManager:  providerManager,
 		pubsub:           ps,
 		knowledgeManager: knowledgeManager,
 		scheduler:        scheduler,
+		webhookTrigger:   webhook.New(cfg, store, controller),
 	}, nil
 }
 
@@ -228,6 +233,8 @@ func (apiServer *HelixAPIServer) registerRoutes(_ context.Context) (*mux.Router,
 	authRouter.HandleFunc("/api_keys", system.DefaultWrapper(apiServer.deleteAPIKey)).Methods("DELETE")
 	authRouter.HandleFunc("/api_keys/check", system.DefaultWrapper(apiServer.checkAPIKey)).Methods("GET")
 
+	authRouter.HandleFunc("/usage", system.Wrapper(apiServer.getUsage)).Methods("GET")
+
 	if apiServer.Cfg.WebServer.LocalFilestorePath != "" {
 		// disable directory listings
 		fileServer := http.FileServer(neuteredFileSystem{http.Dir(apiServer.Cfg.WebServer.LocalFilestorePath)})
@@ -275,6 +282,7 @@ func (apiServer *HelixAPIServer) registerRoutes(_ context.Context) (*mux.Router,
 	// OpenAI API compatible routes
 	router.HandleFunc("/v1/chat/completions", apiServer.authMiddleware.auth(apiServer.createChatCompletion)).Methods("POST", "OPTIONS")
 	router.HandleFunc("/v1/models", apiServer.authMiddleware.auth(apiServer.listModels)).Methods("GET")
+	router.HandleFunc("/v1/embeddings", apiServer.authMiddleware.auth(apiServer.createEmbeddings)).Methods("POST", "OPTIONS")
 	// Azure OpenAI API compatible routes
 	router.HandleFunc("/openai/deployments/{model}/chat/completions", apiServer.authMiddleware.auth(apiServer.createChatCompletion)).Methods("POST", "OPTIONS")
 
@@ -287,10 +295,14 @@ func (apiServer *HelixAPIServer) registerRoutes(_ context.Context) (*mux.Router,
 	authRouter.HandleFunc("/sessions/learn", apiServer.startLearnSessionHandler).Methods("POST")
 
 	authRouter.HandleFunc("/sessions", system.DefaultWrapper(apiServer.getSessions)).Methods("GET")
+	authRouter.HandleFunc("/sessions/search", system.Wrapper(apiServer.searchSessions)).Methods("GET")
+	authRouter.HandleFunc("/sessions/import", system.Wrapper(apiServer.importSession)).Methods("POST")
+	authRouter.HandleFunc("/sessions/{id}/export", apiServer.exportSession).Methods("GET")
 	// authRouter.HandleFunc("/sessions", system.DefaultWrapper(apiServer.createSession)).Methods("POST")
 
 	subRouter.HandleFunc("/sessions/{id}", system.Wrapper(apiServer.getSession)).Methods("GET")
 	subRouter.HandleFunc("/sessions/{id}/summary", system.Wrapper(apiServer.getSessionSummary)).Methods("GET")
+	subRouter.HandleFunc("/sessions/{id}/interactions", system.Wrapper(apiServer.getSessionInteractions)).Methods("GET")
 	authRouter.HandleFunc("/sessions/{id}", system.Wrapper(apiServer.updateSession)).Methods("PUT")
 	authRouter.HandleFunc("/sessions/{id}", system.Wrapper(apiServer.deleteSession)).Methods("DELETE")
 	authRouter.HandleFunc("/sessions/{id}/restart", system.Wrapper(apiServer.restartSession)).Methods("PUT")
@@ -315,6 +327,18 @@ func (apiServer *HelixAPIServer) registerRoutes(_ context.Context) (*mux.Router,
 	authRouter.HandleFunc("/apps/{id}", system.Wrapper(apiServer.updateApp)).Methods("PUT")
 	authRouter.HandleFunc("/apps/github/{id}", system.Wrapper(apiServer.updateGithubApp)).Methods("PUT")
 	authRouter.HandleFunc("/apps/{id}", system.Wrapper(apiServer.deleteApp)).Methods("DELETE")
+	authRouter.HandleFunc("/apps/{id}/trigger-executions", system.Wrapper(apiServer.listAppTriggerExecutions)).Methods("GET")
+	// this is not authenticated because the requests are signed with the trigger's secret
+	subRouter.HandleFunc("/apps/{id}/webhook", system.Wrapper(apiServer.appWebhook)).Methods("POST")
+
+	authRouter.HandleFunc("/organizations", system.Wrapper(apiServer.listOrganizations)).Methods("GET")
+	authRouter.HandleFunc("/organizations", system.Wrapper(apiServer.createOrganization)).Methods("POST")
+	authRouter.HandleFunc("/organizations/{id}", system.Wrapper(apiServer.getOrganization)).Methods("GET")
+	authRouter.HandleFunc("/organizations/{id}", system.Wrapper(apiServer.updateOrganization)).Methods("PUT")
+	authRouter.HandleFunc("/organizations/{id}", system.Wrapper(apiServer.deleteOrganization)).Methods("DELETE")
+	authRouter.HandleFunc("/organizations/{id}/members", system.Wrapper(apiServer.listOrganizationMembers)).Methods("GET")
+	authRouter.HandleFunc("/organizations/{id}/members/{user_id}", system.Wrapper(apiServer.updateOrganizationMember)).Methods("PUT")
+	authRouter.HandleFunc("/organizations/{id}/members/{user_id}", system.Wrapper(apiServer.deleteOrganizationMember)).Methods("DELETE")
 
 	authRouter.HandleFunc("/search", system.Wrapper(apiServer.knowledgeSearch)).Methods("GET")
 
@@ -331,6 +355,13 @@ func (apiServer *HelixAPIServer) registerRoutes(_ context.Context) (*mux.Router,
 	authRouter.HandleFunc("/apps/script", system.Wrapper(apiServer.appRunScript)).Methods("POST", "OPTIONS")
 	adminRouter.HandleFunc("/dashboard", system.DefaultWrapper(apiServer.dashboard)).Methods("GET")
 	adminRouter.HandleFunc("/llm_calls", system.Wrapper(apiServer.listLLMCalls)).Methods("GET")
+	adminRouter.HandleFunc("/provider_endpoints", system.Wrapper(apiServer.listProviderEndpoints)).Methods("GET")
+	adminRouter.HandleFunc("/provider_endpoints", system.Wrapper(apiServer.createProviderEndpoint)).Methods("POST")
+	adminRouter.HandleFunc("/provider_endpoints/{id}", system.Wrapper(apiServer.getProviderEndpoint)).Methods("GET")
+	adminRouter.HandleFunc("/provider_endpoints/{id}", system.Wrapper(apiServer.updateProviderEndpoint)).Methods("PUT")
+	adminRouter.HandleFunc("/provider_endpoints/{id}", system.Wrapper(apiServer.deleteProviderEndpoint)).Methods("DELETE")
+	adminRouter.HandleFunc("/audit_logs", system.Wrapper(apiServer.listAuditLogs)).Methods("GET")
+	adminRouter.HandleFunc("/audit_logs/export", apiServer.exportAuditLogs).Methods("GET")
 
 	// all these routes are secured via runner tokens
 	runnerRouter.HandleFunc("/runner/{runnerid}/nextsession", system.DefaultWrapper(apiServer.getNextRunnerSession)).Methods("GET")
@@ -347,7 +378,9 @@ func (apiServer *HelixAPIServer) registerRoutes(_ context.Context) (*mux.Router,
 	router.PathPrefix("/debug/pprof/").Handler(http.DefaultServeMux)
 
 	// proxy /admin -> keycloak
-	apiServer.registerKeycloakHandler(router)
+	if apiServer.Cfg.Auth.Provider != types.AuthProviderOIDC {
+		apiServer.registerKeycloakHandler(router)
+	}
 
 	// proxy other routes to frontend
 	apiServer.registerDefaultHandler(router)
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/controller"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func TestUpdateSessionConfig_PriorityClass(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+
+	c := &controller.Controller{}
+	c.Options.Store = storeMock
+
+	server := &HelixAPIServer{Store: storeMock, Controller: c, Cfg: &config.ServerConfig{}}
+
+	storeMock.EXPECT().GetSessionWithInteractions(gomock.Any(), &store.ListInteractionsQuery{SessionID: "ses_1"}).Return(&types.Session{
+		ID:        "ses_1",
+		Owner:     "user_1",
+		OwnerType: types.OwnerTypeUser,
+		Metadata:  types.SessionMetadata{PriorityClass: "low"},
+	}, nil)
+	storeMock.EXPECT().UpdateSession(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, session types.Session) (*types.Session, error) {
+		return &session, nil
+	})
+
+	body := `{"priority_class": "high", "priority": true, "system_prompt": "be brief"}`
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/sessions/ses_1/config", strings.NewReader(body))
+	req = req.WithContext(setRequestUser(context.Background(), types.User{ID: "user_1", Type: types.OwnerTypeUser}))
+	req = mux.SetURLVars(req, map[string]string{"id": "ses_1"})
+
+	meta, httpErr := server.updateSessionConfig(httptest.NewRecorder(), req)
+	require.Nil(t, httpErr)
+
+	// Other users can't be preempted by changing the priority
+	assert.Equal(t, "low", meta.PriorityClass)
+	assert.False(t, meta.Priority)
+	assert.Equal(t, "be brief", meta.SystemPrompt)
+}
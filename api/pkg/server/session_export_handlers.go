@@ -0,0 +1,91 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+// exportSession godoc
+// @Summary Export a session
+// @Description Export the session as a self-contained tar bundle with the session JSON, a markdown transcript and the session files. The bundle can be imported into any Helix instance. The json and markdown formats only contain the session and the transcript.
+// @Tags    sessions
+// @Produce application/x-tar
+// @Param   id      path     string  true   "Session ID"
+// @Param   format  query    string  false  "Export format: tar (default), json or markdown"
+// @Success 200 {file} file
+// @Router /api/v1/sessions/{id}/export [get]
+// @Security BearerAuth
+func (apiServer *HelixAPIServer) exportSession(rw http.ResponseWriter, req *http.Request) {
+	format := types.SessionExportFormat(req.URL.Query().Get("format"))
+
+	var contentType, extension string
+	switch format {
+	case types.SessionExportFormatTar, "":
+		contentType, extension = "application/x-tar", "tar"
+	case types.SessionExportFormatJSON:
+		contentType, extension = "application/json", "json"
+	case types.SessionExportFormatMarkdown:
+		contentType, extension = "text/markdown; charset=utf-8", "md"
+	default:
+		http.Error(rw, fmt.Sprintf("unknown export format '%s', expected tar, json or markdown", format), http.StatusBadRequest)
+		return
+	}
+
+	session, httpErr := apiServer.sessionLoader(req, false)
+	if httpErr != nil {
+		http.Error(rw, httpErr.Message, httpErr.StatusCode)
+		return
+	}
+
+	rw.Header().Set("Content-Type", contentType)
+	rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.%s", session.ID, extension))
+
+	err := apiServer.Controller.ExportSession(req.Context(), session, format, rw)
+	if err != nil {
+		// The headers are already sent, the client gets a truncated export
+		log.Err(err).Str("session_id", session.ID).Msg("error exporting session")
+	}
+}
+
+// maxSessionImportSize limits the size of the imported bundles, the bundles
+// include the session files
+const maxSessionImportSize = 512 << 20
+
+// importSession godoc
+// @Summary Import a session
+// @Description Import a session exported with the export endpoint, as a tar bundle or JSON. The session is recreated with a new ID under the caller, or under the organization when org_id is set.
+// @Tags    sessions
+// @Accept  application/x-tar
+// @Produce json
+// @Param   org_id  query    string  false  "Import the session into the organization"
+// @Success 200 {object} types.Session
+// @Router /api/v1/sessions/import [post]
+// @Security BearerAuth
+func (apiServer *HelixAPIServer) importSession(rw http.ResponseWriter, req *http.Request) (*types.Session, *system.HTTPError) {
+	owner, httpErr := getRequestOwner(req, types.OrganizationRoleMember)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	body := http.MaxBytesReader(rw, req.Body, maxSessionImportSize)
+
+	session, err := apiServer.Controller.ImportSession(req.Context(), owner, body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, &system.HTTPError{
+				StatusCode: http.StatusRequestEntityTooLarge,
+				Message:    fmt.Sprintf("session bundle is larger than %d bytes", maxBytesErr.Limit),
+			}
+		}
+		return nil, system.NewHTTPError400(fmt.Sprintf("failed to import session: %s", err))
+	}
+
+	return session, nil
+}
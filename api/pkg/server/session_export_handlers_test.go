@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/controller"
+	"github.com/helixml/helix/api/pkg/filestore"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func newSessionExportServer(t *testing.T) (*HelixAPIServer, *store.MockStore) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+
+	c := &controller.Controller{}
+	c.Options.Config = &config.ServerConfig{}
+	c.Options.Store = storeMock
+	c.Options.Filestore = filestore.NewFileSystemStorage(t.TempDir(), "http://localhost/files", "secret")
+
+	return &HelixAPIServer{Store: storeMock, Controller: c}, storeMock
+}
+
+func newSessionExportRequest(target string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, target, http.NoBody)
+	req = req.WithContext(setRequestUser(context.Background(), types.User{ID: "user_1", Type: types.OwnerTypeUser}))
+	return mux.SetURLVars(req, map[string]string{"id": "ses_1"})
+}
+
+func TestExportSession_Markdown(t *testing.T) {
+	server, storeMock := newSessionExportServer(t)
+
+	storeMock.EXPECT().GetSessionWithInteractions(gomock.Any(), &store.ListInteractionsQuery{SessionID: "ses_1"}).Return(&types.Session{
+		ID:        "ses_1",
+		Name:      "billing api",
+		Owner:     "user_1",
+		OwnerType: types.OwnerTypeUser,
+		Interactions: types.Interactions{
+			{ID: "int_1", Creator: types.CreatorTypeUser, Message: "How do I paginate invoices?"},
+		},
+	}, nil)
+
+	rec := httptest.NewRecorder()
+	server.exportSession(rec, newSessionExportRequest("/api/v1/sessions/ses_1/export?format=markdown"))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/markdown; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "attachment; filename=ses_1.md", rec.Header().Get("Content-Disposition"))
+	assert.Contains(t, rec.Body.String(), "How do I paginate invoices?")
+}
+
+func TestExportSession_AccessDenied(t *testing.T) {
+	server, storeMock := newSessionExportServer(t)
+
+	storeMock.EXPECT().GetSessionWithInteractions(gomock.Any(), gomock.Any()).Return(&types.Session{
+		ID:        "ses_1",
+		Owner:     "user_2",
+		OwnerType: types.OwnerTypeUser,
+	}, nil)
+
+	rec := httptest.NewRecorder()
+	server.exportSession(rec, newSessionExportRequest("/api/v1/sessions/ses_1/export"))
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestExportSession_UnknownFormat(t *testing.T) {
+	server, _ := newSessionExportServer(t)
+
+	rec := httptest.NewRecorder()
+	server.exportSession(rec, newSessionExportRequest("/api/v1/sessions/ses_1/export?format=pdf"))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
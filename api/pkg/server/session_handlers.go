@@ -139,7 +139,7 @@ func (s *HelixAPIServer) startChatSessionHandler(rw http.ResponseWriter, req *ht
 			return
 		}
 
-		if session.Owner != user.ID {
+		if !user.HasOwnerRole(session.Owner, session.OwnerType, types.OrganizationRoleMember) {
 			http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
 			return
 		}
@@ -177,35 +177,34 @@ func (s *HelixAPIServer) startChatSessionHandler(rw http.ResponseWriter, req *ht
 		}
 	}
 
-	session.Interactions = append(session.Interactions,
-		&types.Interaction{
-			ID:        system.GenerateUUID(),
-			Created:   time.Now(),
-			Updated:   time.Now(),
-			Scheduled: time.Now(),
-			Completed: time.Now(),
-			Mode:      types.SessionModeInference,
-			Creator:   types.CreatorTypeUser,
-			State:     types.InteractionStateComplete,
-			Finished:  true,
-			Message:   message,
-		},
-		&types.Interaction{
-			ID:       system.GenerateUUID(),
-			Created:  time.Now(),
-			Updated:  time.Now(),
-			Creator:  types.CreatorTypeAssistant,
-			Mode:     types.SessionModeInference,
-			Message:  "",
-			State:    types.InteractionStateWaiting,
-			Finished: false,
-			Metadata: map[string]string{},
-		},
-	)
+	userInteraction := &types.Interaction{
+		ID:        system.GenerateUUID(),
+		Created:   time.Now(),
+		Updated:   time.Now(),
+		Scheduled: time.Now(),
+		Completed: time.Now(),
+		Mode:      types.SessionModeInference,
+		Creator:   types.CreatorTypeUser,
+		State:     types.InteractionStateComplete,
+		Finished:  true,
+		Message:   message,
+	}
+	assistantInteraction := &types.Interaction{
+		ID:       system.GenerateUUID(),
+		Created:  time.Now(),
+		Updated:  time.Now(),
+		Creator:  types.CreatorTypeAssistant,
+		Mode:     types.SessionModeInference,
+		Message:  "",
+		State:    types.InteractionStateWaiting,
+		Finished: false,
+		Metadata: map[string]string{},
+	}
+	session.Interactions = append(session.Interactions, userInteraction, assistantInteraction)
 
 	// Write the initial session that has the user prompt and also the placeholder interaction
 	// for the system response which will be updated later once the response is received
-	err = s.Controller.WriteSession(session)
+	err = s.Controller.WriteSession(session, userInteraction, assistantInteraction)
 	if err != nil {
 		http.Error(rw, "failed to write session: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -280,8 +279,9 @@ func (s *HelixAPIServer) restartChatSessionHandler(rw http.ResponseWriter, req *
 	}
 
 	// Restart the previous interaction
+	var lastInteraction *types.Interaction
 	if len(session.Interactions) > 0 {
-		lastInteraction := session.Interactions[len(session.Interactions)-1]
+		lastInteraction = session.Interactions[len(session.Interactions)-1]
 		lastInteraction.State = types.InteractionStateWaiting
 		lastInteraction.Completed = time.Time{}
 		lastInteraction.Finished = false
@@ -290,7 +290,7 @@ func (s *HelixAPIServer) restartChatSessionHandler(rw http.ResponseWriter, req *
 	}
 
 	// Update the session
-	err = s.Controller.WriteSession(session)
+	err = s.Controller.WriteSession(session, lastInteraction)
 	if err != nil {
 		http.Error(rw, "failed to write session: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -332,7 +332,7 @@ func (s *HelixAPIServer) restartChatSessionHandler(rw http.ResponseWriter, req *
 func (s *HelixAPIServer) _legacyChatCompletionStream(ctx context.Context, user *types.User, session *types.Session, chatCompletionRequest openai.ChatCompletionRequest, options *controller.ChatCompletionOptions, rw http.ResponseWriter) {
 	stream, updatedReq, err := s.Controller.ChatCompletionStream(ctx, user, chatCompletionRequest, options)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		http.Error(rw, err.Error(), chatCompletionErrorStatus(err))
 		return
 	}
 
@@ -355,6 +355,7 @@ func (s *HelixAPIServer) _legacyChatCompletionStream(ctx context.Context, user *
 func (s *HelixAPIServer) handleBlockingSession(ctx context.Context, user *types.User, session *types.Session, chatCompletionRequest openai.ChatCompletionRequest, options *controller.ChatCompletionOptions, rw http.ResponseWriter) error {
 	// Ensure request is not streaming
 	chatCompletionRequest.Stream = false
+	ctx = s.withAPIKeyUsage(ctx, user)
 
 	// Call the LLM
 	chatCompletionResponse, _, err := s.Controller.ChatCompletion(ctx, user, chatCompletionRequest, options)
@@ -362,12 +363,12 @@ func (s *HelixAPIServer) handleBlockingSession(ctx context.Context, user *types.
 		// Update the session with the response
 		session.Interactions[len(session.Interactions)-1].Error = err.Error()
 		session.Interactions[len(session.Interactions)-1].State = types.InteractionStateError
-		writeErr := s.Controller.WriteSession(session)
+		writeErr := s.Controller.WriteSession(session, session.Interactions[len(session.Interactions)-1])
 		if writeErr != nil {
 			return fmt.Errorf("error writing session: %w", writeErr)
 		}
 
-		http.Error(rw, fmt.Sprintf("error running LLM: %s", err.Error()), http.StatusInternalServerError)
+		http.Error(rw, fmt.Sprintf("error running LLM: %s", err.Error()), chatCompletionErrorStatus(err))
 		return nil
 	}
 
@@ -381,7 +382,7 @@ func (s *HelixAPIServer) handleBlockingSession(ctx context.Context, user *types.
 	session.Interactions[len(session.Interactions)-1].State = types.InteractionStateComplete
 	session.Interactions[len(session.Interactions)-1].Finished = true
 
-	err = s.Controller.WriteSession(session)
+	err = s.Controller.WriteSession(session, session.Interactions[len(session.Interactions)-1])
 	if err != nil {
 		return err
 	}
@@ -401,6 +402,7 @@ func (s *HelixAPIServer) handleBlockingSession(ctx context.Context, user *types.
 func (s *HelixAPIServer) handleStreamingSession(ctx context.Context, user *types.User, session *types.Session, chatCompletionRequest openai.ChatCompletionRequest, options *controller.ChatCompletionOptions, rw http.ResponseWriter) error {
 	// Ensure request is streaming
 	chatCompletionRequest.Stream = true
+	ctx = s.withAPIKeyUsage(ctx, user)
 
 	rw.Header().Set("Content-Type", "text/event-stream")
 	rw.Header().Set("Cache-Control", "no-cache")
@@ -428,7 +430,7 @@ func (s *HelixAPIServer) handleStreamingSession(ctx context.Context, user *types
 	// Call the LLM
 	stream, _, err := s.Controller.ChatCompletionStream(ctx, user, chatCompletionRequest, options)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		http.Error(rw, err.Error(), chatCompletionErrorStatus(err))
 		return nil
 	}
 	defer stream.Close()
@@ -475,7 +477,7 @@ func (s *HelixAPIServer) handleStreamingSession(ctx context.Context, user *types
 	session.Interactions[len(session.Interactions)-1].State = types.InteractionStateComplete
 	session.Interactions[len(session.Interactions)-1].Finished = true
 
-	return s.Controller.WriteSession(session)
+	return s.Controller.WriteSession(session, session.Interactions[len(session.Interactions)-1])
 }
 
 // legacyStreamUpdates writes the event to pubsub so user's browser can pick them
@@ -573,7 +575,7 @@ func (s *HelixAPIServer) legacyStreamUpdates(user *types.User, session *types.Se
 	session.Interactions[len(session.Interactions)-1].State = types.InteractionStateComplete
 	session.Interactions[len(session.Interactions)-1].Finished = true
 
-	s.Controller.WriteSession(session)
+	s.Controller.WriteSession(session, session.Interactions[len(session.Interactions)-1])
 }
 
 func (s *HelixAPIServer) logLegacyLLMCall(userID, sessionID, interactionID string, step types.LLMCallStep, req *openai.ChatCompletionRequest, resp *openai.ChatCompletionResponse, durationMs int64, model string, provider string) {
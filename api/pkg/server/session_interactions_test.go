@@ -0,0 +1,98 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func newSessionInteractionsRequest(target string) *http.Request {
+	user := &types.User{ID: "user_1", Type: types.OwnerTypeUser}
+	req := httptest.NewRequest(http.MethodGet, target, http.NoBody)
+	req = req.WithContext(setRequestUser(context.Background(), *user))
+	return mux.SetURLVars(req, map[string]string{"id": "ses_1"})
+}
+
+func TestGetSessionInteractions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+
+	server := &HelixAPIServer{Store: storeMock}
+
+	storeMock.EXPECT().GetSessionWithInteractions(gomock.Any(), &store.ListInteractionsQuery{
+		SessionID: "ses_1",
+		Offset:    10,
+		Limit:     5,
+	}).Return(&types.Session{
+		ID:        "ses_1",
+		Owner:     "user_1",
+		OwnerType: types.OwnerTypeUser,
+		Interactions: types.Interactions{
+			{ID: "int_11", SessionID: "ses_1"},
+		},
+	}, nil)
+	storeMock.EXPECT().GetInteractionsCounter(gomock.Any(), "ses_1").Return(&types.Counter{Count: 11}, nil)
+
+	list, httpErr := server.getSessionInteractions(httptest.NewRecorder(), newSessionInteractionsRequest("/api/v1/sessions/ses_1/interactions?offset=10&limit=5"))
+	require.Nil(t, httpErr)
+
+	assert.Equal(t, int64(11), list.Counter.Count)
+	require.Len(t, list.Interactions, 1)
+	assert.Equal(t, "int_11", list.Interactions[0].ID)
+}
+
+func TestGetSessionInteractions_AccessDenied(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+
+	server := &HelixAPIServer{Store: storeMock}
+
+	storeMock.EXPECT().GetSessionWithInteractions(gomock.Any(), gomock.Any()).Return(&types.Session{
+		ID:        "ses_1",
+		Owner:     "user_2",
+		OwnerType: types.OwnerTypeUser,
+	}, nil)
+
+	_, httpErr := server.getSessionInteractions(httptest.NewRecorder(), newSessionInteractionsRequest("/api/v1/sessions/ses_1/interactions"))
+	require.NotNil(t, httpErr)
+	assert.Equal(t, http.StatusForbidden, httpErr.StatusCode)
+}
+
+func TestGetSession_InteractionsPage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+
+	server := &HelixAPIServer{Store: storeMock}
+
+	storeMock.EXPECT().GetSessionWithInteractions(gomock.Any(), &store.ListInteractionsQuery{
+		SessionID: "ses_1",
+		Limit:     20,
+	}).Return(&types.Session{
+		ID:        "ses_1",
+		Owner:     "user_1",
+		OwnerType: types.OwnerTypeUser,
+	}, nil)
+
+	session, httpErr := server.getSession(httptest.NewRecorder(), newSessionInteractionsRequest("/api/v1/sessions/ses_1?interactions_limit=20"))
+	require.Nil(t, httpErr)
+	assert.Equal(t, "ses_1", session.ID)
+}
+
+func TestInteractionsQuery_Invalid(t *testing.T) {
+	_, httpErr := interactionsQuery(newSessionInteractionsRequest("/api/v1/sessions/ses_1/interactions?limit=-1"), "offset", "limit")
+	require.NotNil(t, httpErr)
+	assert.Equal(t, http.StatusBadRequest, httpErr.StatusCode)
+
+	_, httpErr = interactionsQuery(newSessionInteractionsRequest("/api/v1/sessions/ses_1/interactions?offset=abc"), "offset", "limit")
+	require.NotNil(t, httpErr)
+	assert.Equal(t, http.StatusBadRequest, httpErr.StatusCode)
+}
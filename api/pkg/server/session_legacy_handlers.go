@@ -110,6 +110,7 @@ func (s *HelixAPIServer) startChatSessionLegacyHandler(ctx context.Context, user
 			LoraDir:          startReq.LoraDir,
 			UserInteractions: interactions,
 			Priority:         status.Config.StripeSubscriptionActive,
+			PriorityClass:    user.PriorityClass,
 			ActiveTools:      startReq.Tools,
 			RAGSourceID:      startReq.RAGSourceID,
 		}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+const (
+	defaultSessionsSearchLimit = 20
+	maxSessionsSearchLimit     = 100
+)
+
+// searchSessions godoc
+// @Summary Search sessions
+// @Description Full text search over the interaction messages of your sessions, or of the organization sessions when org_id is set. Returns the matching interactions, best matches first.
+// @Tags    sessions
+// @Produce json
+// @Param   q       query    string  true   "Search query, supports quoted phrases, or and -excluded words"
+// @Param   org_id  query    string  false  "Search the sessions of the organization"
+// @Param   app_id  query    string  false  "Filter by app ID"
+// @Param   model   query    string  false  "Filter by model name"
+// @Param   mode    query    string  false  "Filter by session mode (inference, finetune, action)"
+// @Param   from    query    string  false  "Interactions created at or after the time (RFC 3339 or YYYY-MM-DD)"
+// @Param   to      query    string  false  "Interactions created before the time (RFC 3339 or YYYY-MM-DD)"
+// @Param   offset  query    int     false  "Offset"
+// @Param   limit   query    int     false  "Limit, defaults to 20 and at most 100"
+// @Success 200 {object} types.SessionSearchResults
+// @Router /api/v1/sessions/search [get]
+// @Security BearerAuth
+func (apiServer *HelixAPIServer) searchSessions(_ http.ResponseWriter, req *http.Request) (*types.SessionSearchResults, *system.HTTPError) {
+	owner, httpErr := getRequestOwner(req, types.OrganizationRoleMember)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	q, err := parseSearchSessionsQuery(req)
+	if err != nil {
+		return nil, system.NewHTTPError400(err.Error())
+	}
+
+	q.Owner = owner.ID
+	q.OwnerType = owner.Type
+
+	if q.Limit == 0 {
+		q.Limit = defaultSessionsSearchLimit
+	}
+
+	if q.Limit > maxSessionsSearchLimit {
+		q.Limit = maxSessionsSearchLimit
+	}
+
+	results, err := apiServer.Store.SearchSessions(req.Context(), q)
+	if err != nil {
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	counter, err := apiServer.Store.SearchSessionsCounter(req.Context(), q)
+	if err != nil {
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	return &types.SessionSearchResults{
+		Counter: counter,
+		Results: results,
+	}, nil
+}
+
+func parseSearchSessionsQuery(r *http.Request) (*store.SearchSessionsQuery, error) {
+	params := r.URL.Query()
+
+	q := &store.SearchSessionsQuery{
+		Query:     strings.TrimSpace(params.Get("q")),
+		AppID:     params.Get("app_id"),
+		ModelName: params.Get("model"),
+	}
+
+	if q.Query == "" {
+		return nil, fmt.Errorf("search query 'q' not specified")
+	}
+
+	var err error
+
+	q.Mode, err = types.ValidateSessionMode(params.Get("mode"), true)
+	if err != nil {
+		return nil, err
+	}
+
+	if from := params.Get("from"); from != "" {
+		q.From, err = parseSearchTime(from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from time: %w", err)
+		}
+	}
+
+	if to := params.Get("to"); to != "" {
+		q.To, err = parseSearchTime(to)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to time: %w", err)
+		}
+	}
+
+	if offset := params.Get("offset"); offset != "" {
+		q.Offset, err = strconv.Atoi(offset)
+		if err != nil || q.Offset < 0 {
+			return nil, fmt.Errorf("invalid offset: %s", offset)
+		}
+	}
+
+	if limit := params.Get("limit"); limit != "" {
+		q.Limit, err = strconv.Atoi(limit)
+		if err != nil || q.Limit < 0 {
+			return nil, fmt.Errorf("invalid limit: %s", limit)
+		}
+	}
+
+	return q, nil
+}
+
+// parseSearchTime accepts RFC 3339 times or dates, dates are the start of the day in UTC
+func parseSearchTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected RFC 3339 or YYYY-MM-DD: %s", value)
+	}
+	return t, nil
+}
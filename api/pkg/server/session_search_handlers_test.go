@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func TestSearchSessions_ScopedToOrganization(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+
+	server := &HelixAPIServer{Store: storeMock}
+
+	user := types.User{
+		ID:   "user_1",
+		Type: types.OwnerTypeUser,
+		Organizations: map[string]types.OrganizationRole{
+			"org_1": types.OrganizationRoleMember,
+		},
+	}
+
+	expected := &store.SearchSessionsQuery{
+		Owner:     "org_1",
+		OwnerType: types.OwnerTypeOrg,
+		Query:     `"billing api"`,
+		AppID:     "app_1",
+		Mode:      types.SessionModeInference,
+		From:      time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		Limit:     maxSessionsSearchLimit,
+	}
+
+	storeMock.EXPECT().SearchSessions(gomock.Any(), expected).Return([]*types.SessionSearchResult{
+		{SessionID: "ses_1", InteractionID: "int_1", Snippet: "the **billing** **API**"},
+	}, nil)
+	storeMock.EXPECT().SearchSessionsCounter(gomock.Any(), expected).Return(&types.Counter{Count: 1}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, `/api/v1/sessions/search?q=%22billing+api%22&org_id=org_1&app_id=app_1&mode=inference&from=2024-05-01&limit=500`, http.NoBody)
+	req = req.WithContext(setRequestUser(context.Background(), user))
+
+	results, httpErr := server.searchSessions(httptest.NewRecorder(), req)
+	require.Nil(t, httpErr)
+
+	assert.Equal(t, int64(1), results.Counter.Count)
+	require.Len(t, results.Results, 1)
+	assert.Equal(t, "int_1", results.Results[0].InteractionID)
+}
+
+func TestSearchSessions_NotMember(t *testing.T) {
+	server := &HelixAPIServer{}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/sessions/search?q=billing&org_id=org_2", http.NoBody)
+	req = req.WithContext(setRequestUser(context.Background(), types.User{ID: "user_1", Type: types.OwnerTypeUser}))
+
+	_, httpErr := server.searchSessions(httptest.NewRecorder(), req)
+	require.NotNil(t, httpErr)
+	assert.Equal(t, http.StatusForbidden, httpErr.StatusCode)
+}
+
+func TestParseSearchSessionsQuery(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		url  string
+		err  string
+	}{
+		{name: "missing query", url: "/api/v1/sessions/search?q=+", err: "not specified"},
+		{name: "invalid mode", url: "/api/v1/sessions/search?q=billing&mode=chat", err: "invalid session mode"},
+		{name: "invalid from", url: "/api/v1/sessions/search?q=billing&from=yesterday", err: "invalid from time"},
+		{name: "invalid limit", url: "/api/v1/sessions/search?q=billing&limit=-1", err: "invalid limit"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseSearchSessionsQuery(httptest.NewRequest(http.MethodGet, tc.url, http.NoBody))
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+
+	q, err := parseSearchSessionsQuery(httptest.NewRequest(http.MethodGet, "/api/v1/sessions/search?q=billing&to=2024-05-01T12:00:00Z", http.NoBody))
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), q.To)
+}
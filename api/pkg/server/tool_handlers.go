@@ -20,7 +20,10 @@ import (
 // @Router /api/v1/tools [get]
 // @Security BearerAuth
 func (s *HelixAPIServer) listTools(rw http.ResponseWriter, r *http.Request) ([]*types.Tool, *system.HTTPError) {
-	user := getRequestUser(r)
+	user, httpErr := getRequestOwner(r, types.OrganizationRoleMember)
+	if httpErr != nil {
+		return nil, httpErr
+	}
 
 	userTools, err := s.Store.ListTools(r.Context(), &store.ListToolsQuery{
 		Owner:     user.ID,
@@ -78,7 +81,10 @@ func (s *HelixAPIServer) createTool(rw http.ResponseWriter, r *http.Request) (*t
 		return nil, system.NewHTTPError400("failed to decode request body 5, error: %s", err)
 	}
 
-	user := getRequestUser(r)
+	user, httpErr := getRequestOwner(r, types.OrganizationRoleAdmin)
+	if httpErr != nil {
+		return nil, httpErr
+	}
 
 	// only let admins create global tools
 	if tool.Global && !isAdmin(user) {
@@ -115,6 +121,8 @@ func (s *HelixAPIServer) createTool(rw http.ResponseWriter, r *http.Request) (*t
 		return nil, system.NewHTTPError500(err.Error())
 	}
 
+	s.audit(r, types.AuditActionCreate, types.AuditTargetTool, created.ID, "", nil, created)
+
 	return created, nil
 }
 
@@ -162,7 +170,7 @@ func (s *HelixAPIServer) updateTool(rw http.ResponseWriter, r *http.Request) (*t
 			return nil, system.NewHTTPError403("only admin users can update global tools")
 		}
 	} else {
-		if existing.Owner != user.ID {
+		if !user.HasOwnerRole(existing.Owner, existing.OwnerType, types.OrganizationRoleAdmin) {
 			return nil, system.NewHTTPError404(store.ErrNotFound.Error())
 		}
 	}
@@ -176,6 +184,8 @@ func (s *HelixAPIServer) updateTool(rw http.ResponseWriter, r *http.Request) (*t
 		return nil, system.NewHTTPError500(err.Error())
 	}
 
+	s.audit(r, types.AuditActionUpdate, types.AuditTargetTool, updated.ID, "", existing, updated)
+
 	return updated, nil
 }
 
@@ -212,7 +222,7 @@ func (s *HelixAPIServer) deleteTool(rw http.ResponseWriter, r *http.Request) (*t
 			return nil, system.NewHTTPError403("only admin users can delete global tools")
 		}
 	} else {
-		if existing.Owner != user.ID {
+		if !user.HasOwnerRole(existing.Owner, existing.OwnerType, types.OrganizationRoleAdmin) {
 			return nil, system.NewHTTPError404(store.ErrNotFound.Error())
 		}
 	}
@@ -222,5 +232,7 @@ func (s *HelixAPIServer) deleteTool(rw http.ResponseWriter, r *http.Request) (*t
 		return nil, system.NewHTTPError500(err.Error())
 	}
 
+	s.audit(r, types.AuditActionDelete, types.AuditTargetTool, id, "", existing, nil)
+
 	return existing, nil
 }
@@ -41,6 +41,7 @@ func (suite *ToolsTestSuite) SetupTest() {
 	ctrl := gomock.NewController(suite.T())
 
 	suite.store = store.NewMockStore(ctrl)
+	suite.store.EXPECT().ListOrganizationMemberships(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
 	ps, err := pubsub.New(suite.T().TempDir())
 	suite.NoError(err)
 
@@ -65,6 +66,7 @@ func (suite *ToolsTestSuite) SetupTest() {
 		authMiddleware: &authMiddleware{
 			store:         suite.store,
 			authenticator: auth.NewMockAuthenticator(&user),
+			apiKeyLimiter: newAPIKeyLimiter(),
 		},
 		Controller: &controller.Controller{
 			ToolsPlanner: &tools.ChainStrategy{},
@@ -97,6 +99,7 @@ func (suite *ToolsTestSuite) TestListTools() {
 		Owner:     suite.userID,
 		OwnerType: types.OwnerTypeUser,
 	}, nil)
+	suite.store.EXPECT().UpdateAPIKeyLastUsed(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 
 	suite.store.EXPECT().ListTools(gomock.Any(), &store.ListToolsQuery{
 		Owner:     suite.userID,
@@ -130,6 +133,7 @@ func (suite *ToolsTestSuite) TestCreateTool() {
 		Owner:     suite.userID,
 		OwnerType: types.OwnerTypeUser,
 	}, nil)
+	suite.store.EXPECT().UpdateAPIKeyLastUsed(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 
 	suite.store.EXPECT().ListTools(gomock.Any(), &store.ListToolsQuery{
 		Owner:     suite.userID,
@@ -150,6 +154,18 @@ func (suite *ToolsTestSuite) TestCreateTool() {
 			return tool, nil
 		})
 
+	suite.store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, auditLog *types.AuditLog) (*types.AuditLog, error) {
+			suite.Equal(types.AuditActionCreate, auditLog.Action)
+			suite.Equal(types.AuditTargetTool, auditLog.TargetType)
+			suite.Equal("tool_1", auditLog.TargetID)
+			suite.Equal(types.TokenTypeAPIKey, auditLog.TokenType)
+			suite.Empty(auditLog.Before)
+			suite.NotEmpty(auditLog.After)
+
+			return auditLog, nil
+		})
+
 	bts, err := json.Marshal(&types.Tool{
 		Name:        "tool_1_name",
 		Description: "tool_1_description",
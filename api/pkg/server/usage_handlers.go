@@ -0,0 +1,109 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+// getUsage godoc
+// @Summary Get token usage
+// @Description Get the daily token usage and cost by app, API key, provider and model. The usage of an app includes all its users.
+// @Tags    usage
+// @Produce json
+// @Param   from      query    string  false  "First day (YYYY-MM-DD), defaults to the start of the month"
+// @Param   to        query    string  false  "Last day (YYYY-MM-DD), defaults to today"
+// @Param   org_id    query    string  false  "Organization ID"
+// @Param   app_id    query    string  false  "App ID"
+// @Param   api_key_id  query  string  false  "API key ID, see the key IDs in the usage"
+// @Param   provider  query    string  false  "Provider"
+// @Param   model     query    string  false  "Model"
+// @Param   all       query    bool    false  "Usage of all users, admins only"
+// @Success 200 {object} types.UsageReport
+// @Router /api/v1/usage [get]
+// @Security BearerAuth
+func (s *HelixAPIServer) getUsage(_ http.ResponseWriter, r *http.Request) (*types.UsageReport, *system.HTTPError) {
+	ctx := r.Context()
+	user := getRequestUser(r)
+
+	owner, httpErr := getRequestOwner(r, types.OrganizationRoleAdmin)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	var err error
+	if v := r.URL.Query().Get("from"); v != "" {
+		from, err = time.Parse(time.DateOnly, v)
+		if err != nil {
+			return nil, system.NewHTTPError400(fmt.Sprintf("invalid from date '%s', expected YYYY-MM-DD", v))
+		}
+	}
+	if v := r.URL.Query().Get("to"); v != "" {
+		to, err = time.Parse(time.DateOnly, v)
+		if err != nil {
+			return nil, system.NewHTTPError400(fmt.Sprintf("invalid to date '%s', expected YYYY-MM-DD", v))
+		}
+	}
+	if to.Before(from) {
+		return nil, system.NewHTTPError400("to date must not be before the from date")
+	}
+
+	q := &store.ListUsageQuery{
+		Owner:     owner.ID,
+		OwnerType: owner.Type,
+		AppID:     r.URL.Query().Get("app_id"),
+		APIKeyID:  r.URL.Query().Get("api_key_id"),
+		Provider:  r.URL.Query().Get("provider"),
+		Model:     r.URL.Query().Get("model"),
+		From:      from,
+		To:        to,
+	}
+
+	switch {
+	case r.URL.Query().Get("all") == "true":
+		if !user.Admin {
+			return nil, system.NewHTTPError403("only admins can see the usage of all users")
+		}
+		q.Owner = ""
+		q.OwnerType = ""
+	case q.AppID != "":
+		// The owners of the app can see the usage of all its users
+		app, err := s.Store.GetApp(ctx, q.AppID)
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				return nil, system.NewHTTPError404(store.ErrNotFound.Error())
+			}
+			return nil, system.NewHTTPError500(err.Error())
+		}
+
+		if user.Admin || user.HasOwnerRole(app.Owner, app.OwnerType, types.OrganizationRoleAdmin) {
+			q.Owner = ""
+			q.OwnerType = ""
+		}
+	}
+
+	usage, err := s.Store.ListUsage(ctx, q)
+	if err != nil {
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	report := &types.UsageReport{
+		From:  from,
+		To:    to,
+		Usage: usage,
+	}
+	for _, u := range usage {
+		report.Totals.Add(&u.UsageTotals)
+	}
+
+	return report, nil
+}
@@ -66,6 +66,8 @@ func (apiServer *HelixAPIServer) startGptScriptRunnerWebSocketServer(r *mux.Rout
 				messageType = types.RunnerEventRequestApp
 			case "tool":
 				messageType = types.RunnerEventRequestTool
+			case "code":
+				messageType = types.RunnerEventRequestCode
 			}
 
 			err := wsConn.WriteJSON(&types.RunnerEventRequestEnvelope{
@@ -4,11 +4,15 @@ import (
 	"fmt"
 
 	_ "github.com/lib/pq"
+	"github.com/rs/zerolog/log"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	_ "github.com/doug-martin/goqu/v9/dialect/postgres"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+
+	"github.com/helixml/helix/api/pkg/types"
 )
 
 // the poing of this setup is if when we change database schemas we need to loop over
@@ -23,6 +27,58 @@ func helloWorld(*gorm.DB) error {
 	return nil
 }
 
+// migrateSessionInteractions moves the interactions from the json column of
+// the session table to the interactions table, one session per transaction so
+// an interrupted migration carries on from where it stopped
+func migrateSessionInteractions(db *gorm.DB) error {
+	const batchSize = 100
+
+	migrated := 0
+
+	for {
+		var sessions []*struct {
+			ID           string
+			Interactions types.Interactions
+		}
+		err := db.Raw(`SELECT id, interactions FROM session WHERE interactions IS NOT NULL LIMIT ?`, batchSize).Scan(&sessions).Error
+		if err != nil {
+			return fmt.Errorf("failed to list sessions to migrate: %w", err)
+		}
+
+		if len(sessions) == 0 {
+			log.Info().Int("sessions", migrated).Msg("migrated session interactions")
+			return nil
+		}
+
+		for _, session := range sessions {
+			interactions := make([]*types.Interaction, 0, len(session.Interactions))
+			for _, interaction := range session.Interactions {
+				if interaction == nil {
+					continue
+				}
+				interaction.SessionID = session.ID
+				interactions = append(interactions, interaction)
+			}
+
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if len(interactions) > 0 {
+					err := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(interactions, interactionsBatchSize).Error
+					if err != nil {
+						return err
+					}
+				}
+				return tx.Exec(`UPDATE session SET interactions = NULL WHERE id = ?`, session.ID).Error
+			})
+			if err != nil {
+				return fmt.Errorf("failed to migrate interactions of session %s: %w", session.ID, err)
+			}
+		}
+
+		migrated += len(sessions)
+	}
+}
+
 var MIGRATION_SCRIPTS map[string]func(*gorm.DB) error = map[string]func(*gorm.DB) error{
-	"01_hello_world": helloWorld,
+	"01_hello_world":          helloWorld,
+	"02_session_interactions": migrateSessionInteractions,
 }
@@ -0,0 +1,4 @@
+ALTER TABLE usermeta
+DROP COLUMN username,
+DROP COLUMN email,
+DROP COLUMN full_name;
@@ -0,0 +1,4 @@
+ALTER TABLE usermeta
+ADD COLUMN username varchar(255) NOT NULL DEFAULT '',
+ADD COLUMN email varchar(255) NOT NULL DEFAULT '',
+ADD COLUMN full_name varchar(255) NOT NULL DEFAULT '';
@@ -0,0 +1,9 @@
+-- The interactions of the migrated sessions are only in the interactions
+-- table, refuse to go back instead of dropping the conversations
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM session WHERE interactions IS NULL) THEN
+    RAISE EXCEPTION 'sessions have been migrated to the interactions table, restore a backup to downgrade';
+  END IF;
+END $$;
+ALTER TABLE session ALTER COLUMN interactions SET NOT NULL;
@@ -0,0 +1,3 @@
+-- interactions are moved to their own table by the 02_session_interactions
+-- migration script, the column is kept until all the sessions are migrated
+ALTER TABLE session ALTER COLUMN interactions DROP NOT NULL;
@@ -0,0 +1 @@
+drop table if exists knowledge_embeddings;
\ No newline at end of file
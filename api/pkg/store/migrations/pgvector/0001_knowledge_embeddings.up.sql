@@ -0,0 +1,19 @@
+create extension if not exists vector;
+
+create table if not exists knowledge_embeddings (
+  id varchar(255) PRIMARY KEY,
+  created timestamp default current_timestamp,
+  -- all the chunks of a knowledge version share the data entity ID, it's
+  -- what we query and delete by
+  data_entity_id varchar(255) NOT NULL,
+  document_group_id varchar(255) NOT NULL DEFAULT '',
+  document_id varchar(255) NOT NULL DEFAULT '',
+  source text NOT NULL DEFAULT '',
+  filename text NOT NULL DEFAULT '',
+  content_offset integer NOT NULL DEFAULT 0,
+  content text NOT NULL,
+  -- no fixed dimensions so that the embeddings model can be changed
+  embedding vector NOT NULL
+);
+
+create index if not exists knowledge_embeddings_data_entity_id_idx on knowledge_embeddings (data_entity_id);
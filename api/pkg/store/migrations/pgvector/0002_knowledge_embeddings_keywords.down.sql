@@ -0,0 +1,2 @@
+drop index if exists knowledge_embeddings_content_tsv_idx;
+alter table knowledge_embeddings drop column if exists content_tsv;
\ No newline at end of file
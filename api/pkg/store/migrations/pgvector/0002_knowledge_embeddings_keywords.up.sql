@@ -0,0 +1,6 @@
+-- keyword search for the hybrid mode, the simple configuration doesn't stem or
+-- drop stop words so identifiers like error codes match exactly
+alter table knowledge_embeddings
+add column if not exists content_tsv tsvector generated always as (to_tsvector('simple', content)) stored;
+
+create index if not exists knowledge_embeddings_content_tsv_idx on knowledge_embeddings using gin (content_tsv);
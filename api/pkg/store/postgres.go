@@ -96,10 +96,20 @@ func (s *PostgresStore) autoMigrate() error {
 		&types.Tool{},
 		&types.Knowledge{},
 		&types.KnowledgeVersion{},
+		&types.KnowledgeSourceObject{},
 		&types.SessionToolBinding{},
 		&types.DataEntity{},
 		&types.ScriptRun{},
 		&types.LLMCall{},
+		&types.SchedulerSlot{},
+		&types.LLMInferenceQueueItem{},
+		&types.Organization{},
+		&types.OrganizationMembership{},
+		&types.TriggerExecution{},
+		&types.DailyUsage{},
+		&types.ProviderEndpoint{},
+		&types.AuditLog{},
+		&types.Interaction{},
 		&MigrationScript{},
 	)
 	if err != nil {
@@ -118,10 +128,27 @@ func (s *PostgresStore) autoMigrate() error {
 		log.Err(err).Msg("failed to add DB FK")
 	}
 
+	if err := createFK(s.gdb, types.TriggerExecution{}, types.App{}, "app_id", "id", "CASCADE", "CASCADE"); err != nil {
+		log.Err(err).Msg("failed to add DB FK")
+	}
+
+	if err := createFK(s.gdb, types.Interaction{}, types.Session{}, "session_id", "id", "CASCADE", "CASCADE"); err != nil {
+		log.Err(err).Msg("failed to add DB FK")
+	}
+
 	if err := createFK(s.gdb, types.KnowledgeVersion{}, types.Knowledge{}, "knowledge_id", "id", "CASCADE", "CASCADE"); err != nil {
 		log.Err(err).Msg("failed to add DB FK")
 	}
 
+	if err := createFK(s.gdb, types.KnowledgeSourceObject{}, types.Knowledge{}, "knowledge_id", "id", "CASCADE", "CASCADE"); err != nil {
+		log.Err(err).Msg("failed to add DB FK")
+	}
+
+	// Full text index for the session search, the expression must match sessionsSearchVector
+	if err := s.gdb.Exec(`CREATE INDEX IF NOT EXISTS idx_interactions_message_search ON interactions USING GIN (to_tsvector('english', message))`).Error; err != nil {
+		log.Err(err).Msg("failed to create interactions search index")
+	}
+
 	return s.runMigrationScripts(MIGRATION_SCRIPTS)
 }
 
@@ -228,6 +255,9 @@ func getKeyValueIndexes(fields []string, offset int) string {
 var USERMETA_FIELDS = []string{
 	"id",
 	"config",
+	"username",
+	"email",
+	"full_name",
 }
 
 var USERMETA_FIELDS_STRING = strings.Join(USERMETA_FIELDS, ", ")
@@ -238,6 +268,9 @@ func scanUserMetaRow(row Scanner) (*types.UserMeta, error) {
 	err := row.Scan(
 		&user.ID,
 		&config,
+		&user.Username,
+		&user.Email,
+		&user.FullName,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -261,6 +294,9 @@ func getUserMetaValues(user *types.UserMeta) ([]interface{}, error) {
 	return []interface{}{
 		user.ID,
 		config,
+		user.Username,
+		user.Email,
+		user.FullName,
 	}, nil
 }
 
@@ -423,6 +459,38 @@ func (d *PostgresStore) GetMigrations() (*migrate.Migrate, error) {
 	return migrations, nil
 }
 
+//go:embed migrations/pgvector/*.sql
+var pgvectorFS embed.FS
+
+// MigratePGVectorUp creates the tables for the pgvector RAG backend. They are kept
+// apart from the main migrations as they need the vector extension, which is only
+// required when pgvector is used.
+func (d *PostgresStore) MigratePGVectorUp() error {
+	files, err := iofs.New(pgvectorFS, "migrations/pgvector")
+	if err != nil {
+		return err
+	}
+	migrations, err := migrate.NewWithSourceInstance(
+		"iofs",
+		files,
+		fmt.Sprintf("%s&&x-migrations-table=helix_pgvector_schema_migrations", d.connectionString),
+	)
+	if err != nil {
+		return err
+	}
+	err = migrations.Up()
+	if err != migrate.ErrNoChange {
+		return err
+	}
+	return nil
+}
+
+// DB returns the underlying database connection, used by the components that
+// share the database but not the store, such as the pgvector RAG backend
+func (d *PostgresStore) DB() *sql.DB {
+	return d.pgDb
+}
+
 // Available DB types
 const (
 	DatabaseTypePostgres = "postgres"
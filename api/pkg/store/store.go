@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/helixml/helix/api/pkg/types"
 )
@@ -25,6 +26,30 @@ type GetSessionsQuery struct {
 	Limit         int             `json:"limit"`
 }
 
+// ListInteractionsQuery pages the interactions of a session, oldest first
+type ListInteractionsQuery struct {
+	SessionID string `json:"session_id"`
+	Offset    int    `json:"offset"`
+	// Limit of 0 returns all the interactions after the offset
+	Limit int `json:"limit"`
+}
+
+// SearchSessionsQuery is a full text search over the interaction messages of
+// the sessions of an owner
+type SearchSessionsQuery struct {
+	Owner     string            `json:"owner"`
+	OwnerType types.OwnerType   `json:"owner_type"`
+	Query     string            `json:"query"`
+	AppID     string            `json:"app_id"`
+	ModelName string            `json:"model_name"`
+	Mode      types.SessionMode `json:"mode"`
+	// From is inclusive, To is exclusive, both on the interaction creation time
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+	Offset int       `json:"offset"`
+	Limit  int       `json:"limit"`
+}
+
 type ListApiKeysQuery struct {
 	Owner     string           `json:"owner"`
 	OwnerType types.OwnerType  `json:"owner_type"`
@@ -61,6 +86,19 @@ type Store interface {
 	UpdateSessionMeta(ctx context.Context, data types.SessionMetaUpdate) (*types.Session, error)
 	DeleteSession(ctx context.Context, id string) (*types.Session, error)
 
+	// interactions, GetSession loads all of them, GetSessionWithInteractions
+	// loads a page for the UI
+	GetSessionWithInteractions(ctx context.Context, query *ListInteractionsQuery) (*types.Session, error)
+	CreateInteraction(ctx context.Context, interaction *types.Interaction) (*types.Interaction, error)
+	CreateInteractions(ctx context.Context, interactions ...*types.Interaction) error
+	GetInteraction(ctx context.Context, sessionID, id string) (*types.Interaction, error)
+	UpdateInteraction(ctx context.Context, interaction *types.Interaction) (*types.Interaction, error)
+	ListInteractions(ctx context.Context, query *ListInteractionsQuery) ([]*types.Interaction, error)
+	GetInteractionsCounter(ctx context.Context, sessionID string) (*types.Counter, error)
+
+	SearchSessions(ctx context.Context, query *SearchSessionsQuery) ([]*types.SessionSearchResult, error)
+	SearchSessionsCounter(ctx context.Context, query *SearchSessionsQuery) (*types.Counter, error)
+
 	// usermeta
 	GetUserMeta(ctx context.Context, id string) (*types.UserMeta, error)
 	CreateUserMeta(ctx context.Context, UserMeta types.UserMeta) (*types.UserMeta, error)
@@ -72,6 +110,7 @@ type Store interface {
 	GetAPIKey(ctx context.Context, apiKey string) (*types.APIKey, error)
 	ListAPIKeys(ctx context.Context, query *ListApiKeysQuery) ([]*types.APIKey, error)
 	DeleteAPIKey(ctx context.Context, apiKey string) error
+	UpdateAPIKeyLastUsed(ctx context.Context, apiKey string, lastUsed time.Time) error
 
 	// tools
 	CreateTool(ctx context.Context, tool *types.Tool) (*types.Tool, error)
@@ -112,6 +151,11 @@ type Store interface {
 	ListKnowledgeVersions(ctx context.Context, q *ListKnowledgeVersionQuery) ([]*types.KnowledgeVersion, error)
 	DeleteKnowledgeVersion(ctx context.Context, id string) error
 
+	CreateKnowledgeSourceObject(ctx context.Context, obj *types.KnowledgeSourceObject) (*types.KnowledgeSourceObject, error)
+	UpdateKnowledgeSourceObject(ctx context.Context, obj *types.KnowledgeSourceObject) (*types.KnowledgeSourceObject, error)
+	ListKnowledgeSourceObjects(ctx context.Context, knowledgeID string) ([]*types.KnowledgeSourceObject, error)
+	DeleteKnowledgeSourceObject(ctx context.Context, id string) error
+
 	// GPTScript runs history table
 	CreateScriptRun(ctx context.Context, task *types.ScriptRun) (*types.ScriptRun, error)
 	ListScriptRuns(ctx context.Context, q *types.GptScriptRunsQuery) ([]*types.ScriptRun, error)
@@ -119,6 +163,48 @@ type Store interface {
 
 	CreateLLMCall(ctx context.Context, call *types.LLMCall) (*types.LLMCall, error)
 	ListLLMCalls(ctx context.Context, page, pageSize int, sessionFilter string) ([]*types.LLMCall, int64, error)
+
+	// organizations
+	CreateOrganization(ctx context.Context, org *types.Organization) (*types.Organization, error)
+	GetOrganization(ctx context.Context, id string) (*types.Organization, error)
+	ListOrganizations(ctx context.Context, q *ListOrganizationsQuery) ([]*types.Organization, error)
+	UpdateOrganization(ctx context.Context, org *types.Organization) (*types.Organization, error)
+	DeleteOrganization(ctx context.Context, id string) error
+
+	SaveOrganizationMembership(ctx context.Context, membership *types.OrganizationMembership) (*types.OrganizationMembership, error)
+	GetOrganizationMembership(ctx context.Context, organizationID, userID string) (*types.OrganizationMembership, error)
+	ListOrganizationMemberships(ctx context.Context, q *ListOrganizationMembershipsQuery) ([]*types.OrganizationMembership, error)
+	DeleteOrganizationMembership(ctx context.Context, organizationID, userID string) error
+
+	// trigger executions
+	CreateTriggerExecution(ctx context.Context, execution *types.TriggerExecution) (*types.TriggerExecution, error)
+	UpdateTriggerExecution(ctx context.Context, execution *types.TriggerExecution) (*types.TriggerExecution, error)
+	ListTriggerExecutions(ctx context.Context, q *ListTriggerExecutionsQuery) ([]*types.TriggerExecution, error)
+
+	// provider endpoints registered at runtime
+	CreateProviderEndpoint(ctx context.Context, endpoint *types.ProviderEndpoint) (*types.ProviderEndpoint, error)
+	GetProviderEndpoint(ctx context.Context, q *GetProviderEndpointQuery) (*types.ProviderEndpoint, error)
+	ListProviderEndpoints(ctx context.Context) ([]*types.ProviderEndpoint, error)
+	UpdateProviderEndpoint(ctx context.Context, endpoint *types.ProviderEndpoint) (*types.ProviderEndpoint, error)
+	DeleteProviderEndpoint(ctx context.Context, id string) error
+
+	// usage ledger
+	RecordUsage(ctx context.Context, usage *types.DailyUsage) error
+	ListUsage(ctx context.Context, q *ListUsageQuery) ([]*types.DailyUsage, error)
+
+	// audit log, append-only
+	CreateAuditLog(ctx context.Context, auditLog *types.AuditLog) (*types.AuditLog, error)
+	ListAuditLogs(ctx context.Context, q *ListAuditLogsQuery) ([]*types.AuditLog, error)
+
+	// scheduler slots, restored on startup
+	ListSchedulerSlots(ctx context.Context) ([]*types.SchedulerSlot, error)
+	SaveSchedulerSlot(ctx context.Context, slot *types.SchedulerSlot) error
+	DeleteSchedulerSlot(ctx context.Context, id string) error
+
+	// LLM inference queue, restored on startup
+	ListLLMInferenceQueueItems(ctx context.Context) ([]*types.LLMInferenceQueueItem, error)
+	CreateLLMInferenceQueueItem(ctx context.Context, item *types.LLMInferenceQueueItem) error
+	DeleteLLMInferenceQueueItems(ctx context.Context, ids []string) error
 }
 
 var ErrNotFound = errors.New("not found")
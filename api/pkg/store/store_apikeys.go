@@ -73,3 +73,7 @@ func (s *PostgresStore) DeleteAPIKey(ctx context.Context, key string) error {
 
 	return nil
 }
+
+func (s *PostgresStore) UpdateAPIKeyLastUsed(ctx context.Context, key string, lastUsed time.Time) error {
+	return s.gdb.WithContext(ctx).Model(&types.APIKey{}).Where("key = ?", key).Update("last_used", lastUsed).Error
+}
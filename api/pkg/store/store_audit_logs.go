@@ -0,0 +1,92 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+type ListAuditLogsQuery struct {
+	UserID     string
+	Action     types.AuditAction
+	TargetType types.AuditTargetType
+	TargetID   string
+	AppID      string
+	// From is inclusive, To is exclusive
+	From time.Time
+	To   time.Time
+	// OldestFirst orders the entries by creation time ascending, e.g. for exports
+	OldestFirst bool
+	Offset      int
+	Limit       int
+}
+
+// CreateAuditLog appends the entry to the audit log, the entries are never
+// updated or deleted
+func (s *PostgresStore) CreateAuditLog(ctx context.Context, auditLog *types.AuditLog) (*types.AuditLog, error) {
+	if auditLog.Action == "" {
+		return nil, fmt.Errorf("action not specified")
+	}
+
+	if auditLog.TargetType == "" {
+		return nil, fmt.Errorf("target type not specified")
+	}
+
+	if auditLog.ID == "" {
+		auditLog.ID = system.GenerateAuditLogID()
+	}
+
+	if auditLog.Created.IsZero() {
+		auditLog.Created = time.Now()
+	}
+
+	err := s.gdb.WithContext(ctx).Create(auditLog).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return auditLog, nil
+}
+
+func (s *PostgresStore) ListAuditLogs(ctx context.Context, q *ListAuditLogsQuery) ([]*types.AuditLog, error) {
+	query := s.gdb.WithContext(ctx).Where(&types.AuditLog{
+		UserID:     q.UserID,
+		Action:     q.Action,
+		TargetType: q.TargetType,
+		TargetID:   q.TargetID,
+		AppID:      q.AppID,
+	})
+
+	if !q.From.IsZero() {
+		query = query.Where("created >= ?", q.From)
+	}
+
+	if !q.To.IsZero() {
+		query = query.Where("created < ?", q.To)
+	}
+
+	if q.OldestFirst {
+		query = query.Order("created ASC, id ASC")
+	} else {
+		query = query.Order("created DESC, id DESC")
+	}
+
+	if q.Offset > 0 {
+		query = query.Offset(q.Offset)
+	}
+
+	if q.Limit > 0 {
+		query = query.Limit(q.Limit)
+	}
+
+	var auditLogs []*types.AuditLog
+	err := query.Find(&auditLogs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return auditLogs, nil
+}
@@ -0,0 +1,49 @@
+package store
+
+import (
+	"time"
+
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func (suite *PostgresStoreTestSuite) TestAuditLogs() {
+	appID := system.GenerateAppID()
+	start := time.Now().Add(-time.Second)
+
+	created, err := suite.db.CreateAuditLog(suite.ctx, &types.AuditLog{
+		UserID:     "user-1",
+		TokenType:  types.TokenTypeKeycloak,
+		Action:     types.AuditActionCreate,
+		TargetType: types.AuditTargetApp,
+		TargetID:   appID,
+		AppID:      appID,
+		After:      []byte(`{"id":"` + appID + `"}`),
+	})
+	suite.Require().NoError(err)
+	suite.NotEmpty(created.ID)
+
+	_, err = suite.db.CreateAuditLog(suite.ctx, &types.AuditLog{
+		UserID:     "user-1",
+		TokenType:  types.TokenTypeAPIKey,
+		Action:     types.AuditActionDelete,
+		TargetType: types.AuditTargetApp,
+		TargetID:   appID,
+		AppID:      appID,
+		Created:    time.Now().Add(time.Millisecond),
+	})
+	suite.Require().NoError(err)
+
+	auditLogs, err := suite.db.ListAuditLogs(suite.ctx, &ListAuditLogsQuery{AppID: appID})
+	suite.Require().NoError(err)
+	suite.Require().Len(auditLogs, 2)
+	suite.Equal(types.AuditActionDelete, auditLogs[0].Action)
+
+	auditLogs, err = suite.db.ListAuditLogs(suite.ctx, &ListAuditLogsQuery{AppID: appID, OldestFirst: true, From: start, Limit: 1})
+	suite.Require().NoError(err)
+	suite.Require().Len(auditLogs, 1)
+	suite.Equal(types.AuditActionCreate, auditLogs[0].Action)
+
+	_, err = suite.db.CreateAuditLog(suite.ctx, &types.AuditLog{TargetType: types.AuditTargetApp})
+	suite.Error(err)
+}
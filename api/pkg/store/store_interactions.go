@@ -0,0 +1,162 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+// interactionsBatchSize keeps the inserts under the Postgres parameter limit
+const interactionsBatchSize = 100
+
+// CreateInteraction appends the interaction to its session
+func (s *PostgresStore) CreateInteraction(ctx context.Context, interaction *types.Interaction) (*types.Interaction, error) {
+	if err := s.createInteractions(s.gdb.WithContext(ctx), []*types.Interaction{interaction}); err != nil {
+		return nil, err
+	}
+	return interaction, nil
+}
+
+// CreateInteractions appends the interactions in the given order
+func (s *PostgresStore) CreateInteractions(ctx context.Context, interactions ...*types.Interaction) error {
+	return s.createInteractions(s.gdb.WithContext(ctx), interactions)
+}
+
+func (s *PostgresStore) createInteractions(db *gorm.DB, interactions []*types.Interaction) error {
+	if len(interactions) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+
+	for _, interaction := range interactions {
+		if interaction.SessionID == "" {
+			return fmt.Errorf("session id not specified")
+		}
+		if interaction.ID == "" {
+			interaction.ID = system.GenerateUUID()
+		}
+		if interaction.Created.IsZero() {
+			interaction.Created = now
+		}
+		if interaction.Updated.IsZero() {
+			interaction.Updated = now
+		}
+	}
+
+	return db.CreateInBatches(interactions, interactionsBatchSize).Error
+}
+
+func (s *PostgresStore) GetInteraction(ctx context.Context, sessionID, id string) (*types.Interaction, error) {
+	if sessionID == "" || id == "" {
+		return nil, fmt.Errorf("session id and id must be specified")
+	}
+
+	var interaction types.Interaction
+	err := s.gdb.WithContext(ctx).Where("session_id = ? AND id = ?", sessionID, id).First(&interaction).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &interaction, nil
+}
+
+// UpdateInteraction writes a single interaction, the position of the
+// interaction in the session doesn't change
+func (s *PostgresStore) UpdateInteraction(ctx context.Context, interaction *types.Interaction) (*types.Interaction, error) {
+	if interaction.SessionID == "" || interaction.ID == "" {
+		return nil, fmt.Errorf("session id and id must be specified")
+	}
+
+	res := s.gdb.WithContext(ctx).
+		Model(&types.Interaction{}).
+		Where("session_id = ? AND id = ?", interaction.SessionID, interaction.ID).
+		Select("*").
+		Omit("id", "session_id", "seq").
+		Updates(interaction)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrNotFound
+	}
+
+	return interaction, nil
+}
+
+func (s *PostgresStore) ListInteractions(ctx context.Context, query *ListInteractionsQuery) ([]*types.Interaction, error) {
+	if query == nil || query.SessionID == "" {
+		return nil, fmt.Errorf("session id not specified")
+	}
+
+	q := s.gdb.WithContext(ctx).Where("session_id = ?", query.SessionID).Order("seq ASC")
+
+	if query.Offset > 0 {
+		q = q.Offset(query.Offset)
+	}
+
+	if query.Limit > 0 {
+		q = q.Limit(query.Limit)
+	}
+
+	interactions := []*types.Interaction{}
+	err := q.Find(&interactions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return interactions, nil
+}
+
+func (s *PostgresStore) GetInteractionsCounter(ctx context.Context, sessionID string) (*types.Counter, error) {
+	var counter int64
+	err := s.gdb.WithContext(ctx).Model(&types.Interaction{}).Where("session_id = ?", sessionID).Count(&counter).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.Counter{
+		Count: counter,
+	}, nil
+}
+
+// loadInteractions loads all the interactions of the sessions with a single query
+func (s *PostgresStore) loadInteractions(ctx context.Context, sessions []*types.Session) error {
+	if len(sessions) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(sessions))
+	for _, session := range sessions {
+		ids = append(ids, session.ID)
+	}
+
+	var interactions []*types.Interaction
+	err := s.gdb.WithContext(ctx).Where("session_id IN ?", ids).Order("seq ASC").Find(&interactions).Error
+	if err != nil {
+		return err
+	}
+
+	bySession := make(map[string]types.Interactions, len(sessions))
+	for _, interaction := range interactions {
+		bySession[interaction.SessionID] = append(bySession[interaction.SessionID], interaction)
+	}
+
+	for _, session := range sessions {
+		session.Interactions = bySession[session.ID]
+		if session.Interactions == nil {
+			session.Interactions = types.Interactions{}
+		}
+	}
+
+	return nil
+}
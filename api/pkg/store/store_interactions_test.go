@@ -0,0 +1,127 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func (suite *PostgresStoreTestSuite) createInteractionsSession(count int) *types.Session {
+	session := types.Session{
+		ID:      system.GenerateSessionID(),
+		Owner:   "user_id",
+		Created: time.Now(),
+		Updated: time.Now(),
+	}
+
+	for i := 0; i < count; i++ {
+		session.Interactions = append(session.Interactions, &types.Interaction{
+			ID:      fmt.Sprintf("id-%d", i),
+			State:   types.InteractionStateComplete,
+			Creator: types.CreatorTypeUser,
+			Message: fmt.Sprintf("message %d", i),
+		})
+	}
+
+	createdSession, err := suite.db.CreateSession(context.Background(), session)
+	suite.Require().NoError(err)
+
+	suite.T().Cleanup(func() {
+		_, _ = suite.db.DeleteSession(context.Background(), session.ID)
+	})
+
+	return createdSession
+}
+
+func (suite *PostgresStoreTestSuite) TestPostgresStore_CreateInteraction() {
+	session := suite.createInteractionsSession(2)
+
+	created, err := suite.db.CreateInteraction(context.Background(), &types.Interaction{
+		SessionID: session.ID,
+		Creator:   types.CreatorTypeAssistant,
+		Message:   "appended",
+		Files:     []string{"a.txt"},
+	})
+	suite.NoError(err)
+	suite.NotEmpty(created.ID)
+
+	interactions, err := suite.db.ListInteractions(context.Background(), &ListInteractionsQuery{SessionID: session.ID})
+	suite.NoError(err)
+	suite.Require().Equal(3, len(interactions))
+
+	// Appended interactions go last
+	suite.Equal("id-0", interactions[0].ID)
+	suite.Equal("id-1", interactions[1].ID)
+	suite.Equal(created.ID, interactions[2].ID)
+	suite.Equal([]string{"a.txt"}, interactions[2].Files)
+}
+
+func (suite *PostgresStoreTestSuite) TestPostgresStore_UpdateInteraction() {
+	session := suite.createInteractionsSession(2)
+
+	interaction := session.Interactions[0]
+	interaction.Message = "updated"
+	interaction.State = types.InteractionStateError
+
+	_, err := suite.db.UpdateInteraction(context.Background(), interaction)
+	suite.NoError(err)
+
+	updated, err := suite.db.GetInteraction(context.Background(), session.ID, interaction.ID)
+	suite.NoError(err)
+	suite.Equal("updated", updated.Message)
+	suite.Equal(types.InteractionStateError, updated.State)
+
+	// Updating doesn't move the interaction
+	interactions, err := suite.db.ListInteractions(context.Background(), &ListInteractionsQuery{SessionID: session.ID})
+	suite.NoError(err)
+	suite.Equal("id-0", interactions[0].ID)
+
+	_, err = suite.db.UpdateInteraction(context.Background(), &types.Interaction{
+		ID:        "missing",
+		SessionID: session.ID,
+	})
+	suite.Equal(ErrNotFound, err)
+}
+
+func (suite *PostgresStoreTestSuite) TestPostgresStore_ListInteractions_Paging() {
+	session := suite.createInteractionsSession(5)
+
+	interactions, err := suite.db.ListInteractions(context.Background(), &ListInteractionsQuery{
+		SessionID: session.ID,
+		Offset:    1,
+		Limit:     2,
+	})
+	suite.NoError(err)
+	suite.Require().Equal(2, len(interactions))
+	suite.Equal("id-1", interactions[0].ID)
+	suite.Equal("id-2", interactions[1].ID)
+
+	counter, err := suite.db.GetInteractionsCounter(context.Background(), session.ID)
+	suite.NoError(err)
+	suite.Equal(int64(5), counter.Count)
+
+	paged, err := suite.db.GetSessionWithInteractions(context.Background(), &ListInteractionsQuery{
+		SessionID: session.ID,
+		Offset:    4,
+	})
+	suite.NoError(err)
+	suite.Require().Equal(1, len(paged.Interactions))
+	suite.Equal("id-4", paged.Interactions[0].ID)
+}
+
+func (suite *PostgresStoreTestSuite) TestPostgresStore_InteractionsSharedIDs() {
+	// Cloned sessions keep the interaction ids of the original
+	original := suite.createInteractionsSession(1)
+	clone := suite.createInteractionsSession(1)
+
+	clone.Interactions[0].Message = "changed in the clone"
+	_, err := suite.db.UpdateInteraction(context.Background(), clone.Interactions[0])
+	suite.NoError(err)
+
+	interaction, err := suite.db.GetInteraction(context.Background(), original.ID, "id-0")
+	suite.NoError(err)
+	suite.Equal("message 0", interaction.Message)
+}
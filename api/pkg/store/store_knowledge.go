@@ -180,6 +180,11 @@ func (s *PostgresStore) DeleteKnowledge(ctx context.Context, id string) error {
 			return err
 		}
 
+		// Delete extracted source objects
+		if err := tx.Where("knowledge_id = ?", id).Delete(&types.KnowledgeSourceObject{}).Error; err != nil {
+			return err
+		}
+
 		// Delete the knowledge
 		if err := tx.Delete(&types.Knowledge{ID: id}).Error; err != nil {
 			return err
@@ -261,3 +266,63 @@ func (s *PostgresStore) DeleteKnowledgeVersion(ctx context.Context, id string) e
 	}
 	return nil
 }
+
+func (s *PostgresStore) CreateKnowledgeSourceObject(ctx context.Context, obj *types.KnowledgeSourceObject) (*types.KnowledgeSourceObject, error) {
+	if obj.ID == "" {
+		obj.ID = system.GenerateKnowledgeSourceObjectID()
+	}
+
+	if obj.KnowledgeID == "" {
+		return nil, fmt.Errorf("knowledge_id not specified")
+	}
+
+	obj.Created = time.Now()
+	obj.Updated = time.Now()
+
+	err := s.gdb.WithContext(ctx).Create(obj).Error
+	if err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func (s *PostgresStore) UpdateKnowledgeSourceObject(ctx context.Context, obj *types.KnowledgeSourceObject) (*types.KnowledgeSourceObject, error) {
+	if obj.ID == "" {
+		return nil, fmt.Errorf("id not specified")
+	}
+
+	if obj.KnowledgeID == "" {
+		return nil, fmt.Errorf("knowledge_id not specified")
+	}
+
+	obj.Updated = time.Now()
+
+	err := s.gdb.WithContext(ctx).Save(obj).Error
+	if err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func (s *PostgresStore) ListKnowledgeSourceObjects(ctx context.Context, knowledgeID string) ([]*types.KnowledgeSourceObject, error) {
+	if knowledgeID == "" {
+		return nil, fmt.Errorf("knowledge_id not specified")
+	}
+
+	var objects []*types.KnowledgeSourceObject
+
+	err := s.gdb.WithContext(ctx).Where("knowledge_id = ?", knowledgeID).Find(&objects).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return objects, nil
+}
+
+func (s *PostgresStore) DeleteKnowledgeSourceObject(ctx context.Context, id string) error {
+	if id == "" {
+		return fmt.Errorf("id not specified")
+	}
+
+	return s.gdb.WithContext(ctx).Delete(&types.KnowledgeSourceObject{ID: id}).Error
+}
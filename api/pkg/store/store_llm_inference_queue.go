@@ -0,0 +1,38 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func (s *PostgresStore) ListLLMInferenceQueueItems(ctx context.Context) ([]*types.LLMInferenceQueueItem, error) {
+	var items []*types.LLMInferenceQueueItem
+	err := s.gdb.WithContext(ctx).Order("created ASC").Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (s *PostgresStore) CreateLLMInferenceQueueItem(ctx context.Context, item *types.LLMInferenceQueueItem) error {
+	if item.ID == "" {
+		return fmt.Errorf("id not specified")
+	}
+
+	if item.Created.IsZero() {
+		item.Created = time.Now()
+	}
+
+	return s.gdb.WithContext(ctx).Create(item).Error
+}
+
+func (s *PostgresStore) DeleteLLMInferenceQueueItems(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return s.gdb.WithContext(ctx).Where("id IN ?", ids).Delete(&types.LLMInferenceQueueItem{}).Error
+}
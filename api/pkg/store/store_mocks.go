@@ -7,6 +7,7 @@ package store
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	types "github.com/helixml/helix/api/pkg/types"
 	gomock "go.uber.org/mock/gomock"
@@ -65,6 +66,21 @@ func (mr *MockStoreMockRecorder) CreateApp(ctx, tool interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateApp", reflect.TypeOf((*MockStore)(nil).CreateApp), ctx, tool)
 }
 
+// CreateAuditLog mocks base method.
+func (m *MockStore) CreateAuditLog(ctx context.Context, auditLog *types.AuditLog) (*types.AuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditLog", ctx, auditLog)
+	ret0, _ := ret[0].(*types.AuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAuditLog indicates an expected call of CreateAuditLog.
+func (mr *MockStoreMockRecorder) CreateAuditLog(ctx, auditLog interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditLog", reflect.TypeOf((*MockStore)(nil).CreateAuditLog), ctx, auditLog)
+}
+
 // CreateDataEntity mocks base method.
 func (m *MockStore) CreateDataEntity(ctx context.Context, dataEntity *types.DataEntity) (*types.DataEntity, error) {
 	m.ctrl.T.Helper()
@@ -80,6 +96,40 @@ func (mr *MockStoreMockRecorder) CreateDataEntity(ctx, dataEntity interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDataEntity", reflect.TypeOf((*MockStore)(nil).CreateDataEntity), ctx, dataEntity)
 }
 
+// CreateInteraction mocks base method.
+func (m *MockStore) CreateInteraction(ctx context.Context, interaction *types.Interaction) (*types.Interaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInteraction", ctx, interaction)
+	ret0, _ := ret[0].(*types.Interaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateInteraction indicates an expected call of CreateInteraction.
+func (mr *MockStoreMockRecorder) CreateInteraction(ctx, interaction interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInteraction", reflect.TypeOf((*MockStore)(nil).CreateInteraction), ctx, interaction)
+}
+
+// CreateInteractions mocks base method.
+func (m *MockStore) CreateInteractions(ctx context.Context, interactions ...*types.Interaction) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range interactions {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreateInteractions", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateInteractions indicates an expected call of CreateInteractions.
+func (mr *MockStoreMockRecorder) CreateInteractions(ctx interface{}, interactions ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, interactions...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInteractions", reflect.TypeOf((*MockStore)(nil).CreateInteractions), varargs...)
+}
+
 // CreateKnowledge mocks base method.
 func (m *MockStore) CreateKnowledge(ctx context.Context, knowledge *types.Knowledge) (*types.Knowledge, error) {
 	m.ctrl.T.Helper()
@@ -95,6 +145,21 @@ func (mr *MockStoreMockRecorder) CreateKnowledge(ctx, knowledge interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateKnowledge", reflect.TypeOf((*MockStore)(nil).CreateKnowledge), ctx, knowledge)
 }
 
+// CreateKnowledgeSourceObject mocks base method.
+func (m *MockStore) CreateKnowledgeSourceObject(ctx context.Context, obj *types.KnowledgeSourceObject) (*types.KnowledgeSourceObject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateKnowledgeSourceObject", ctx, obj)
+	ret0, _ := ret[0].(*types.KnowledgeSourceObject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateKnowledgeSourceObject indicates an expected call of CreateKnowledgeSourceObject.
+func (mr *MockStoreMockRecorder) CreateKnowledgeSourceObject(ctx, obj interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateKnowledgeSourceObject", reflect.TypeOf((*MockStore)(nil).CreateKnowledgeSourceObject), ctx, obj)
+}
+
 // CreateKnowledgeVersion mocks base method.
 func (m *MockStore) CreateKnowledgeVersion(ctx context.Context, version *types.KnowledgeVersion) (*types.KnowledgeVersion, error) {
 	m.ctrl.T.Helper()
@@ -125,6 +190,50 @@ func (mr *MockStoreMockRecorder) CreateLLMCall(ctx, call interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLLMCall", reflect.TypeOf((*MockStore)(nil).CreateLLMCall), ctx, call)
 }
 
+// CreateLLMInferenceQueueItem mocks base method.
+func (m *MockStore) CreateLLMInferenceQueueItem(ctx context.Context, item *types.LLMInferenceQueueItem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLLMInferenceQueueItem", ctx, item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateLLMInferenceQueueItem indicates an expected call of CreateLLMInferenceQueueItem.
+func (mr *MockStoreMockRecorder) CreateLLMInferenceQueueItem(ctx, item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLLMInferenceQueueItem", reflect.TypeOf((*MockStore)(nil).CreateLLMInferenceQueueItem), ctx, item)
+}
+
+// CreateOrganization mocks base method.
+func (m *MockStore) CreateOrganization(ctx context.Context, org *types.Organization) (*types.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrganization", ctx, org)
+	ret0, _ := ret[0].(*types.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOrganization indicates an expected call of CreateOrganization.
+func (mr *MockStoreMockRecorder) CreateOrganization(ctx, org interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrganization", reflect.TypeOf((*MockStore)(nil).CreateOrganization), ctx, org)
+}
+
+// CreateProviderEndpoint mocks base method.
+func (m *MockStore) CreateProviderEndpoint(ctx context.Context, endpoint *types.ProviderEndpoint) (*types.ProviderEndpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateProviderEndpoint", ctx, endpoint)
+	ret0, _ := ret[0].(*types.ProviderEndpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateProviderEndpoint indicates an expected call of CreateProviderEndpoint.
+func (mr *MockStoreMockRecorder) CreateProviderEndpoint(ctx, endpoint interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProviderEndpoint", reflect.TypeOf((*MockStore)(nil).CreateProviderEndpoint), ctx, endpoint)
+}
+
 // CreateScriptRun mocks base method.
 func (m *MockStore) CreateScriptRun(ctx context.Context, task *types.ScriptRun) (*types.ScriptRun, error) {
 	m.ctrl.T.Helper()
@@ -184,6 +293,21 @@ func (mr *MockStoreMockRecorder) CreateTool(ctx, tool interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTool", reflect.TypeOf((*MockStore)(nil).CreateTool), ctx, tool)
 }
 
+// CreateTriggerExecution mocks base method.
+func (m *MockStore) CreateTriggerExecution(ctx context.Context, execution *types.TriggerExecution) (*types.TriggerExecution, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTriggerExecution", ctx, execution)
+	ret0, _ := ret[0].(*types.TriggerExecution)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTriggerExecution indicates an expected call of CreateTriggerExecution.
+func (mr *MockStoreMockRecorder) CreateTriggerExecution(ctx, execution interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTriggerExecution", reflect.TypeOf((*MockStore)(nil).CreateTriggerExecution), ctx, execution)
+}
+
 // CreateUserMeta mocks base method.
 func (m *MockStore) CreateUserMeta(ctx context.Context, UserMeta types.UserMeta) (*types.UserMeta, error) {
 	m.ctrl.T.Helper()
@@ -255,6 +379,20 @@ func (mr *MockStoreMockRecorder) DeleteKnowledge(ctx, id interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteKnowledge", reflect.TypeOf((*MockStore)(nil).DeleteKnowledge), ctx, id)
 }
 
+// DeleteKnowledgeSourceObject mocks base method.
+func (m *MockStore) DeleteKnowledgeSourceObject(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteKnowledgeSourceObject", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteKnowledgeSourceObject indicates an expected call of DeleteKnowledgeSourceObject.
+func (mr *MockStoreMockRecorder) DeleteKnowledgeSourceObject(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteKnowledgeSourceObject", reflect.TypeOf((*MockStore)(nil).DeleteKnowledgeSourceObject), ctx, id)
+}
+
 // DeleteKnowledgeVersion mocks base method.
 func (m *MockStore) DeleteKnowledgeVersion(ctx context.Context, id string) error {
 	m.ctrl.T.Helper()
@@ -269,6 +407,76 @@ func (mr *MockStoreMockRecorder) DeleteKnowledgeVersion(ctx, id interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteKnowledgeVersion", reflect.TypeOf((*MockStore)(nil).DeleteKnowledgeVersion), ctx, id)
 }
 
+// DeleteLLMInferenceQueueItems mocks base method.
+func (m *MockStore) DeleteLLMInferenceQueueItems(ctx context.Context, ids []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLLMInferenceQueueItems", ctx, ids)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteLLMInferenceQueueItems indicates an expected call of DeleteLLMInferenceQueueItems.
+func (mr *MockStoreMockRecorder) DeleteLLMInferenceQueueItems(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLLMInferenceQueueItems", reflect.TypeOf((*MockStore)(nil).DeleteLLMInferenceQueueItems), ctx, ids)
+}
+
+// DeleteOrganization mocks base method.
+func (m *MockStore) DeleteOrganization(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOrganization", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOrganization indicates an expected call of DeleteOrganization.
+func (mr *MockStoreMockRecorder) DeleteOrganization(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOrganization", reflect.TypeOf((*MockStore)(nil).DeleteOrganization), ctx, id)
+}
+
+// DeleteOrganizationMembership mocks base method.
+func (m *MockStore) DeleteOrganizationMembership(ctx context.Context, organizationID, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOrganizationMembership", ctx, organizationID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOrganizationMembership indicates an expected call of DeleteOrganizationMembership.
+func (mr *MockStoreMockRecorder) DeleteOrganizationMembership(ctx, organizationID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOrganizationMembership", reflect.TypeOf((*MockStore)(nil).DeleteOrganizationMembership), ctx, organizationID, userID)
+}
+
+// DeleteProviderEndpoint mocks base method.
+func (m *MockStore) DeleteProviderEndpoint(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProviderEndpoint", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteProviderEndpoint indicates an expected call of DeleteProviderEndpoint.
+func (mr *MockStoreMockRecorder) DeleteProviderEndpoint(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProviderEndpoint", reflect.TypeOf((*MockStore)(nil).DeleteProviderEndpoint), ctx, id)
+}
+
+// DeleteSchedulerSlot mocks base method.
+func (m *MockStore) DeleteSchedulerSlot(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSchedulerSlot", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSchedulerSlot indicates an expected call of DeleteSchedulerSlot.
+func (mr *MockStoreMockRecorder) DeleteSchedulerSlot(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSchedulerSlot", reflect.TypeOf((*MockStore)(nil).DeleteSchedulerSlot), ctx, id)
+}
+
 // DeleteScriptRun mocks base method.
 func (m *MockStore) DeleteScriptRun(ctx context.Context, id string) error {
 	m.ctrl.T.Helper()
@@ -386,6 +594,36 @@ func (mr *MockStoreMockRecorder) GetDataEntity(ctx, id interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDataEntity", reflect.TypeOf((*MockStore)(nil).GetDataEntity), ctx, id)
 }
 
+// GetInteraction mocks base method.
+func (m *MockStore) GetInteraction(ctx context.Context, sessionID, id string) (*types.Interaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInteraction", ctx, sessionID, id)
+	ret0, _ := ret[0].(*types.Interaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInteraction indicates an expected call of GetInteraction.
+func (mr *MockStoreMockRecorder) GetInteraction(ctx, sessionID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInteraction", reflect.TypeOf((*MockStore)(nil).GetInteraction), ctx, sessionID, id)
+}
+
+// GetInteractionsCounter mocks base method.
+func (m *MockStore) GetInteractionsCounter(ctx context.Context, sessionID string) (*types.Counter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInteractionsCounter", ctx, sessionID)
+	ret0, _ := ret[0].(*types.Counter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInteractionsCounter indicates an expected call of GetInteractionsCounter.
+func (mr *MockStoreMockRecorder) GetInteractionsCounter(ctx, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInteractionsCounter", reflect.TypeOf((*MockStore)(nil).GetInteractionsCounter), ctx, sessionID)
+}
+
 // GetKnowledge mocks base method.
 func (m *MockStore) GetKnowledge(ctx context.Context, id string) (*types.Knowledge, error) {
 	m.ctrl.T.Helper()
@@ -416,6 +654,51 @@ func (mr *MockStoreMockRecorder) GetKnowledgeVersion(ctx, id interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKnowledgeVersion", reflect.TypeOf((*MockStore)(nil).GetKnowledgeVersion), ctx, id)
 }
 
+// GetOrganization mocks base method.
+func (m *MockStore) GetOrganization(ctx context.Context, id string) (*types.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganization", ctx, id)
+	ret0, _ := ret[0].(*types.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganization indicates an expected call of GetOrganization.
+func (mr *MockStoreMockRecorder) GetOrganization(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganization", reflect.TypeOf((*MockStore)(nil).GetOrganization), ctx, id)
+}
+
+// GetOrganizationMembership mocks base method.
+func (m *MockStore) GetOrganizationMembership(ctx context.Context, organizationID, userID string) (*types.OrganizationMembership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganizationMembership", ctx, organizationID, userID)
+	ret0, _ := ret[0].(*types.OrganizationMembership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganizationMembership indicates an expected call of GetOrganizationMembership.
+func (mr *MockStoreMockRecorder) GetOrganizationMembership(ctx, organizationID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganizationMembership", reflect.TypeOf((*MockStore)(nil).GetOrganizationMembership), ctx, organizationID, userID)
+}
+
+// GetProviderEndpoint mocks base method.
+func (m *MockStore) GetProviderEndpoint(ctx context.Context, q *GetProviderEndpointQuery) (*types.ProviderEndpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProviderEndpoint", ctx, q)
+	ret0, _ := ret[0].(*types.ProviderEndpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProviderEndpoint indicates an expected call of GetProviderEndpoint.
+func (mr *MockStoreMockRecorder) GetProviderEndpoint(ctx, q interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProviderEndpoint", reflect.TypeOf((*MockStore)(nil).GetProviderEndpoint), ctx, q)
+}
+
 // GetSession mocks base method.
 func (m *MockStore) GetSession(ctx context.Context, id string) (*types.Session, error) {
 	m.ctrl.T.Helper()
@@ -431,6 +714,21 @@ func (mr *MockStoreMockRecorder) GetSession(ctx, id interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockStore)(nil).GetSession), ctx, id)
 }
 
+// GetSessionWithInteractions mocks base method.
+func (m *MockStore) GetSessionWithInteractions(ctx context.Context, query *ListInteractionsQuery) (*types.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessionWithInteractions", ctx, query)
+	ret0, _ := ret[0].(*types.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessionWithInteractions indicates an expected call of GetSessionWithInteractions.
+func (mr *MockStoreMockRecorder) GetSessionWithInteractions(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionWithInteractions", reflect.TypeOf((*MockStore)(nil).GetSessionWithInteractions), ctx, query)
+}
+
 // GetSessions mocks base method.
 func (m *MockStore) GetSessions(ctx context.Context, query GetSessionsQuery) ([]*types.Session, error) {
 	m.ctrl.T.Helper()
@@ -521,6 +819,21 @@ func (mr *MockStoreMockRecorder) ListApps(ctx, q interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListApps", reflect.TypeOf((*MockStore)(nil).ListApps), ctx, q)
 }
 
+// ListAuditLogs mocks base method.
+func (m *MockStore) ListAuditLogs(ctx context.Context, q *ListAuditLogsQuery) ([]*types.AuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditLogs", ctx, q)
+	ret0, _ := ret[0].([]*types.AuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuditLogs indicates an expected call of ListAuditLogs.
+func (mr *MockStoreMockRecorder) ListAuditLogs(ctx, q interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditLogs", reflect.TypeOf((*MockStore)(nil).ListAuditLogs), ctx, q)
+}
+
 // ListDataEntities mocks base method.
 func (m *MockStore) ListDataEntities(ctx context.Context, q *ListDataEntitiesQuery) ([]*types.DataEntity, error) {
 	m.ctrl.T.Helper()
@@ -536,6 +849,21 @@ func (mr *MockStoreMockRecorder) ListDataEntities(ctx, q interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDataEntities", reflect.TypeOf((*MockStore)(nil).ListDataEntities), ctx, q)
 }
 
+// ListInteractions mocks base method.
+func (m *MockStore) ListInteractions(ctx context.Context, query *ListInteractionsQuery) ([]*types.Interaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInteractions", ctx, query)
+	ret0, _ := ret[0].([]*types.Interaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInteractions indicates an expected call of ListInteractions.
+func (mr *MockStoreMockRecorder) ListInteractions(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInteractions", reflect.TypeOf((*MockStore)(nil).ListInteractions), ctx, query)
+}
+
 // ListKnowledge mocks base method.
 func (m *MockStore) ListKnowledge(ctx context.Context, q *ListKnowledgeQuery) ([]*types.Knowledge, error) {
 	m.ctrl.T.Helper()
@@ -551,6 +879,21 @@ func (mr *MockStoreMockRecorder) ListKnowledge(ctx, q interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListKnowledge", reflect.TypeOf((*MockStore)(nil).ListKnowledge), ctx, q)
 }
 
+// ListKnowledgeSourceObjects mocks base method.
+func (m *MockStore) ListKnowledgeSourceObjects(ctx context.Context, knowledgeID string) ([]*types.KnowledgeSourceObject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListKnowledgeSourceObjects", ctx, knowledgeID)
+	ret0, _ := ret[0].([]*types.KnowledgeSourceObject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListKnowledgeSourceObjects indicates an expected call of ListKnowledgeSourceObjects.
+func (mr *MockStoreMockRecorder) ListKnowledgeSourceObjects(ctx, knowledgeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListKnowledgeSourceObjects", reflect.TypeOf((*MockStore)(nil).ListKnowledgeSourceObjects), ctx, knowledgeID)
+}
+
 // ListKnowledgeVersions mocks base method.
 func (m *MockStore) ListKnowledgeVersions(ctx context.Context, q *ListKnowledgeVersionQuery) ([]*types.KnowledgeVersion, error) {
 	m.ctrl.T.Helper()
@@ -582,6 +925,81 @@ func (mr *MockStoreMockRecorder) ListLLMCalls(ctx, page, pageSize, sessionFilter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLLMCalls", reflect.TypeOf((*MockStore)(nil).ListLLMCalls), ctx, page, pageSize, sessionFilter)
 }
 
+// ListLLMInferenceQueueItems mocks base method.
+func (m *MockStore) ListLLMInferenceQueueItems(ctx context.Context) ([]*types.LLMInferenceQueueItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLLMInferenceQueueItems", ctx)
+	ret0, _ := ret[0].([]*types.LLMInferenceQueueItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLLMInferenceQueueItems indicates an expected call of ListLLMInferenceQueueItems.
+func (mr *MockStoreMockRecorder) ListLLMInferenceQueueItems(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLLMInferenceQueueItems", reflect.TypeOf((*MockStore)(nil).ListLLMInferenceQueueItems), ctx)
+}
+
+// ListOrganizationMemberships mocks base method.
+func (m *MockStore) ListOrganizationMemberships(ctx context.Context, q *ListOrganizationMembershipsQuery) ([]*types.OrganizationMembership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrganizationMemberships", ctx, q)
+	ret0, _ := ret[0].([]*types.OrganizationMembership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrganizationMemberships indicates an expected call of ListOrganizationMemberships.
+func (mr *MockStoreMockRecorder) ListOrganizationMemberships(ctx, q interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrganizationMemberships", reflect.TypeOf((*MockStore)(nil).ListOrganizationMemberships), ctx, q)
+}
+
+// ListOrganizations mocks base method.
+func (m *MockStore) ListOrganizations(ctx context.Context, q *ListOrganizationsQuery) ([]*types.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrganizations", ctx, q)
+	ret0, _ := ret[0].([]*types.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrganizations indicates an expected call of ListOrganizations.
+func (mr *MockStoreMockRecorder) ListOrganizations(ctx, q interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrganizations", reflect.TypeOf((*MockStore)(nil).ListOrganizations), ctx, q)
+}
+
+// ListProviderEndpoints mocks base method.
+func (m *MockStore) ListProviderEndpoints(ctx context.Context) ([]*types.ProviderEndpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProviderEndpoints", ctx)
+	ret0, _ := ret[0].([]*types.ProviderEndpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListProviderEndpoints indicates an expected call of ListProviderEndpoints.
+func (mr *MockStoreMockRecorder) ListProviderEndpoints(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProviderEndpoints", reflect.TypeOf((*MockStore)(nil).ListProviderEndpoints), ctx)
+}
+
+// ListSchedulerSlots mocks base method.
+func (m *MockStore) ListSchedulerSlots(ctx context.Context) ([]*types.SchedulerSlot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSchedulerSlots", ctx)
+	ret0, _ := ret[0].([]*types.SchedulerSlot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSchedulerSlots indicates an expected call of ListSchedulerSlots.
+func (mr *MockStoreMockRecorder) ListSchedulerSlots(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSchedulerSlots", reflect.TypeOf((*MockStore)(nil).ListSchedulerSlots), ctx)
+}
+
 // ListScriptRuns mocks base method.
 func (m *MockStore) ListScriptRuns(ctx context.Context, q *types.GptScriptRunsQuery) ([]*types.ScriptRun, error) {
 	m.ctrl.T.Helper()
@@ -627,6 +1045,36 @@ func (mr *MockStoreMockRecorder) ListTools(ctx, q interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTools", reflect.TypeOf((*MockStore)(nil).ListTools), ctx, q)
 }
 
+// ListTriggerExecutions mocks base method.
+func (m *MockStore) ListTriggerExecutions(ctx context.Context, q *ListTriggerExecutionsQuery) ([]*types.TriggerExecution, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTriggerExecutions", ctx, q)
+	ret0, _ := ret[0].([]*types.TriggerExecution)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTriggerExecutions indicates an expected call of ListTriggerExecutions.
+func (mr *MockStoreMockRecorder) ListTriggerExecutions(ctx, q interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTriggerExecutions", reflect.TypeOf((*MockStore)(nil).ListTriggerExecutions), ctx, q)
+}
+
+// ListUsage mocks base method.
+func (m *MockStore) ListUsage(ctx context.Context, q *ListUsageQuery) ([]*types.DailyUsage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsage", ctx, q)
+	ret0, _ := ret[0].([]*types.DailyUsage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsage indicates an expected call of ListUsage.
+func (mr *MockStoreMockRecorder) ListUsage(ctx, q interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsage", reflect.TypeOf((*MockStore)(nil).ListUsage), ctx, q)
+}
+
 // LookupKnowledge mocks base method.
 func (m *MockStore) LookupKnowledge(ctx context.Context, q *LookupKnowledgeQuery) (*types.Knowledge, error) {
 	m.ctrl.T.Helper()
@@ -642,6 +1090,93 @@ func (mr *MockStoreMockRecorder) LookupKnowledge(ctx, q interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupKnowledge", reflect.TypeOf((*MockStore)(nil).LookupKnowledge), ctx, q)
 }
 
+// RecordUsage mocks base method.
+func (m *MockStore) RecordUsage(ctx context.Context, usage *types.DailyUsage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordUsage", ctx, usage)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordUsage indicates an expected call of RecordUsage.
+func (mr *MockStoreMockRecorder) RecordUsage(ctx, usage interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordUsage", reflect.TypeOf((*MockStore)(nil).RecordUsage), ctx, usage)
+}
+
+// SaveOrganizationMembership mocks base method.
+func (m *MockStore) SaveOrganizationMembership(ctx context.Context, membership *types.OrganizationMembership) (*types.OrganizationMembership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveOrganizationMembership", ctx, membership)
+	ret0, _ := ret[0].(*types.OrganizationMembership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveOrganizationMembership indicates an expected call of SaveOrganizationMembership.
+func (mr *MockStoreMockRecorder) SaveOrganizationMembership(ctx, membership interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOrganizationMembership", reflect.TypeOf((*MockStore)(nil).SaveOrganizationMembership), ctx, membership)
+}
+
+// SaveSchedulerSlot mocks base method.
+func (m *MockStore) SaveSchedulerSlot(ctx context.Context, slot *types.SchedulerSlot) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSchedulerSlot", ctx, slot)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSchedulerSlot indicates an expected call of SaveSchedulerSlot.
+func (mr *MockStoreMockRecorder) SaveSchedulerSlot(ctx, slot interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSchedulerSlot", reflect.TypeOf((*MockStore)(nil).SaveSchedulerSlot), ctx, slot)
+}
+
+// SearchSessions mocks base method.
+func (m *MockStore) SearchSessions(ctx context.Context, query *SearchSessionsQuery) ([]*types.SessionSearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchSessions", ctx, query)
+	ret0, _ := ret[0].([]*types.SessionSearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchSessions indicates an expected call of SearchSessions.
+func (mr *MockStoreMockRecorder) SearchSessions(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchSessions", reflect.TypeOf((*MockStore)(nil).SearchSessions), ctx, query)
+}
+
+// SearchSessionsCounter mocks base method.
+func (m *MockStore) SearchSessionsCounter(ctx context.Context, query *SearchSessionsQuery) (*types.Counter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchSessionsCounter", ctx, query)
+	ret0, _ := ret[0].(*types.Counter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchSessionsCounter indicates an expected call of SearchSessionsCounter.
+func (mr *MockStoreMockRecorder) SearchSessionsCounter(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchSessionsCounter", reflect.TypeOf((*MockStore)(nil).SearchSessionsCounter), ctx, query)
+}
+
+// UpdateAPIKeyLastUsed mocks base method.
+func (m *MockStore) UpdateAPIKeyLastUsed(ctx context.Context, apiKey string, lastUsed time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAPIKeyLastUsed", ctx, apiKey, lastUsed)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAPIKeyLastUsed indicates an expected call of UpdateAPIKeyLastUsed.
+func (mr *MockStoreMockRecorder) UpdateAPIKeyLastUsed(ctx, apiKey, lastUsed interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAPIKeyLastUsed", reflect.TypeOf((*MockStore)(nil).UpdateAPIKeyLastUsed), ctx, apiKey, lastUsed)
+}
+
 // UpdateApp mocks base method.
 func (m *MockStore) UpdateApp(ctx context.Context, tool *types.App) (*types.App, error) {
 	m.ctrl.T.Helper()
@@ -672,6 +1207,21 @@ func (mr *MockStoreMockRecorder) UpdateDataEntity(ctx, dataEntity interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDataEntity", reflect.TypeOf((*MockStore)(nil).UpdateDataEntity), ctx, dataEntity)
 }
 
+// UpdateInteraction mocks base method.
+func (m *MockStore) UpdateInteraction(ctx context.Context, interaction *types.Interaction) (*types.Interaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateInteraction", ctx, interaction)
+	ret0, _ := ret[0].(*types.Interaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateInteraction indicates an expected call of UpdateInteraction.
+func (mr *MockStoreMockRecorder) UpdateInteraction(ctx, interaction interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateInteraction", reflect.TypeOf((*MockStore)(nil).UpdateInteraction), ctx, interaction)
+}
+
 // UpdateKnowledge mocks base method.
 func (m *MockStore) UpdateKnowledge(ctx context.Context, knowledge *types.Knowledge) (*types.Knowledge, error) {
 	m.ctrl.T.Helper()
@@ -687,6 +1237,21 @@ func (mr *MockStoreMockRecorder) UpdateKnowledge(ctx, knowledge interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateKnowledge", reflect.TypeOf((*MockStore)(nil).UpdateKnowledge), ctx, knowledge)
 }
 
+// UpdateKnowledgeSourceObject mocks base method.
+func (m *MockStore) UpdateKnowledgeSourceObject(ctx context.Context, obj *types.KnowledgeSourceObject) (*types.KnowledgeSourceObject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateKnowledgeSourceObject", ctx, obj)
+	ret0, _ := ret[0].(*types.KnowledgeSourceObject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateKnowledgeSourceObject indicates an expected call of UpdateKnowledgeSourceObject.
+func (mr *MockStoreMockRecorder) UpdateKnowledgeSourceObject(ctx, obj interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateKnowledgeSourceObject", reflect.TypeOf((*MockStore)(nil).UpdateKnowledgeSourceObject), ctx, obj)
+}
+
 // UpdateKnowledgeState mocks base method.
 func (m *MockStore) UpdateKnowledgeState(ctx context.Context, id string, state types.KnowledgeState, message string, percent int) error {
 	m.ctrl.T.Helper()
@@ -701,6 +1266,36 @@ func (mr *MockStoreMockRecorder) UpdateKnowledgeState(ctx, id, state, message, p
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateKnowledgeState", reflect.TypeOf((*MockStore)(nil).UpdateKnowledgeState), ctx, id, state, message, percent)
 }
 
+// UpdateOrganization mocks base method.
+func (m *MockStore) UpdateOrganization(ctx context.Context, org *types.Organization) (*types.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOrganization", ctx, org)
+	ret0, _ := ret[0].(*types.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateOrganization indicates an expected call of UpdateOrganization.
+func (mr *MockStoreMockRecorder) UpdateOrganization(ctx, org interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrganization", reflect.TypeOf((*MockStore)(nil).UpdateOrganization), ctx, org)
+}
+
+// UpdateProviderEndpoint mocks base method.
+func (m *MockStore) UpdateProviderEndpoint(ctx context.Context, endpoint *types.ProviderEndpoint) (*types.ProviderEndpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProviderEndpoint", ctx, endpoint)
+	ret0, _ := ret[0].(*types.ProviderEndpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProviderEndpoint indicates an expected call of UpdateProviderEndpoint.
+func (mr *MockStoreMockRecorder) UpdateProviderEndpoint(ctx, endpoint interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProviderEndpoint", reflect.TypeOf((*MockStore)(nil).UpdateProviderEndpoint), ctx, endpoint)
+}
+
 // UpdateSession mocks base method.
 func (m *MockStore) UpdateSession(ctx context.Context, session types.Session) (*types.Session, error) {
 	m.ctrl.T.Helper()
@@ -746,6 +1341,21 @@ func (mr *MockStoreMockRecorder) UpdateTool(ctx, tool interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTool", reflect.TypeOf((*MockStore)(nil).UpdateTool), ctx, tool)
 }
 
+// UpdateTriggerExecution mocks base method.
+func (m *MockStore) UpdateTriggerExecution(ctx context.Context, execution *types.TriggerExecution) (*types.TriggerExecution, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTriggerExecution", ctx, execution)
+	ret0, _ := ret[0].(*types.TriggerExecution)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTriggerExecution indicates an expected call of UpdateTriggerExecution.
+func (mr *MockStoreMockRecorder) UpdateTriggerExecution(ctx, execution interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTriggerExecution", reflect.TypeOf((*MockStore)(nil).UpdateTriggerExecution), ctx, execution)
+}
+
 // UpdateUserMeta mocks base method.
 func (m *MockStore) UpdateUserMeta(ctx context.Context, UserMeta types.UserMeta) (*types.UserMeta, error) {
 	m.ctrl.T.Helper()
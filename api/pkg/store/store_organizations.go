@@ -0,0 +1,207 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+	"gorm.io/gorm"
+)
+
+type ListOrganizationsQuery struct {
+	UserID string // Only the organizations the user is a member of
+}
+
+type ListOrganizationMembershipsQuery struct {
+	OrganizationID string
+	UserID         string
+}
+
+func (s *PostgresStore) CreateOrganization(ctx context.Context, org *types.Organization) (*types.Organization, error) {
+	if org.ID == "" {
+		org.ID = system.GenerateOrganizationID()
+	}
+
+	if org.Name == "" {
+		return nil, fmt.Errorf("name not specified")
+	}
+
+	if org.Owner == "" {
+		return nil, fmt.Errorf("owner not specified")
+	}
+
+	org.Created = time.Now()
+	org.Updated = org.Created
+
+	// The creator is the first owner of the organization
+	err := s.gdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(org).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&types.OrganizationMembership{
+			OrganizationID: org.ID,
+			UserID:         org.Owner,
+			Created:        org.Created,
+			Updated:        org.Created,
+			Role:           types.OrganizationRoleOwner,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetOrganization(ctx, org.ID)
+}
+
+func (s *PostgresStore) GetOrganization(ctx context.Context, id string) (*types.Organization, error) {
+	if id == "" {
+		return nil, fmt.Errorf("id not specified")
+	}
+
+	var org types.Organization
+	err := s.gdb.WithContext(ctx).Where("id = ?", id).First(&org).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &org, nil
+}
+
+func (s *PostgresStore) ListOrganizations(ctx context.Context, q *ListOrganizationsQuery) ([]*types.Organization, error) {
+	query := s.gdb.WithContext(ctx)
+
+	if q.UserID != "" {
+		query = query.Where("id IN (?)", s.gdb.Model(&types.OrganizationMembership{}).
+			Select("organization_id").
+			Where("user_id = ?", q.UserID))
+	}
+
+	var orgs []*types.Organization
+	err := query.Order("name ASC").Find(&orgs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return orgs, nil
+}
+
+func (s *PostgresStore) UpdateOrganization(ctx context.Context, org *types.Organization) (*types.Organization, error) {
+	if org.ID == "" {
+		return nil, fmt.Errorf("id not specified")
+	}
+
+	if org.Name == "" {
+		return nil, fmt.Errorf("name not specified")
+	}
+
+	err := s.gdb.WithContext(ctx).Model(&types.Organization{ID: org.ID}).Updates(map[string]interface{}{
+		"name":    org.Name,
+		"updated": time.Now(),
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetOrganization(ctx, org.ID)
+}
+
+// DeleteOrganization deletes the organization and all of its memberships
+func (s *PostgresStore) DeleteOrganization(ctx context.Context, id string) error {
+	if id == "" {
+		return fmt.Errorf("id not specified")
+	}
+
+	return s.gdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("organization_id = ?", id).Delete(&types.OrganizationMembership{}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Delete(&types.Organization{ID: id}).Error
+	})
+}
+
+// SaveOrganizationMembership adds the user to the organization or changes their role
+func (s *PostgresStore) SaveOrganizationMembership(ctx context.Context, membership *types.OrganizationMembership) (*types.OrganizationMembership, error) {
+	if membership.OrganizationID == "" {
+		return nil, fmt.Errorf("organization id not specified")
+	}
+
+	if membership.UserID == "" {
+		return nil, fmt.Errorf("user id not specified")
+	}
+
+	if !membership.Role.Valid() {
+		return nil, fmt.Errorf("invalid role: %s", membership.Role)
+	}
+
+	existing, err := s.GetOrganizationMembership(ctx, membership.OrganizationID, membership.UserID)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		membership.Created = time.Now()
+	case err != nil:
+		return nil, err
+	default:
+		membership.Created = existing.Created
+	}
+	membership.Updated = time.Now()
+
+	err = s.gdb.WithContext(ctx).Save(membership).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetOrganizationMembership(ctx, membership.OrganizationID, membership.UserID)
+}
+
+func (s *PostgresStore) GetOrganizationMembership(ctx context.Context, organizationID, userID string) (*types.OrganizationMembership, error) {
+	var membership types.OrganizationMembership
+	err := s.gdb.WithContext(ctx).
+		Where("organization_id = ? AND user_id = ?", organizationID, userID).
+		First(&membership).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &membership, nil
+}
+
+func (s *PostgresStore) ListOrganizationMemberships(ctx context.Context, q *ListOrganizationMembershipsQuery) ([]*types.OrganizationMembership, error) {
+	query := s.gdb.WithContext(ctx)
+
+	if q.OrganizationID != "" {
+		query = query.Where("organization_id = ?", q.OrganizationID)
+	}
+	if q.UserID != "" {
+		query = query.Where("user_id = ?", q.UserID)
+	}
+
+	var memberships []*types.OrganizationMembership
+	err := query.Order("created ASC").Find(&memberships).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return memberships, nil
+}
+
+func (s *PostgresStore) DeleteOrganizationMembership(ctx context.Context, organizationID, userID string) error {
+	if organizationID == "" || userID == "" {
+		return fmt.Errorf("organization id and user id must be specified")
+	}
+
+	return s.gdb.WithContext(ctx).
+		Where("organization_id = ? AND user_id = ?", organizationID, userID).
+		Delete(&types.OrganizationMembership{}).Error
+}
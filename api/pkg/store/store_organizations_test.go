@@ -0,0 +1,106 @@
+package store
+
+import (
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func (suite *PostgresStoreTestSuite) TestCreateOrganization() {
+	ownerID := "test-" + system.GenerateUUID()
+
+	org, err := suite.db.CreateOrganization(suite.ctx, &types.Organization{
+		Name:  "test-" + system.GenerateUUID(),
+		Owner: ownerID,
+	})
+	suite.Require().NoError(err)
+	suite.NotEmpty(org.ID)
+
+	suite.T().Cleanup(func() {
+		err := suite.db.DeleteOrganization(suite.ctx, org.ID)
+		suite.NoError(err)
+	})
+
+	// The creator is the owner
+	membership, err := suite.db.GetOrganizationMembership(suite.ctx, org.ID, ownerID)
+	suite.Require().NoError(err)
+	suite.Equal(types.OrganizationRoleOwner, membership.Role)
+
+	orgs, err := suite.db.ListOrganizations(suite.ctx, &ListOrganizationsQuery{
+		UserID: ownerID,
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(orgs, 1)
+	suite.Equal(org.ID, orgs[0].ID)
+}
+
+func (suite *PostgresStoreTestSuite) TestOrganizationMemberships() {
+	ownerID := "test-" + system.GenerateUUID()
+	memberID := "test-" + system.GenerateUUID()
+
+	org, err := suite.db.CreateOrganization(suite.ctx, &types.Organization{
+		Name:  "test-" + system.GenerateUUID(),
+		Owner: ownerID,
+	})
+	suite.Require().NoError(err)
+
+	suite.T().Cleanup(func() {
+		err := suite.db.DeleteOrganization(suite.ctx, org.ID)
+		suite.NoError(err)
+	})
+
+	_, err = suite.db.SaveOrganizationMembership(suite.ctx, &types.OrganizationMembership{
+		OrganizationID: org.ID,
+		UserID:         memberID,
+		Role:           types.OrganizationRoleMember,
+	})
+	suite.Require().NoError(err)
+
+	// Promote the member
+	membership, err := suite.db.SaveOrganizationMembership(suite.ctx, &types.OrganizationMembership{
+		OrganizationID: org.ID,
+		UserID:         memberID,
+		Role:           types.OrganizationRoleAdmin,
+	})
+	suite.Require().NoError(err)
+	suite.Equal(types.OrganizationRoleAdmin, membership.Role)
+
+	memberships, err := suite.db.ListOrganizationMemberships(suite.ctx, &ListOrganizationMembershipsQuery{
+		OrganizationID: org.ID,
+	})
+	suite.Require().NoError(err)
+	suite.Len(memberships, 2)
+
+	err = suite.db.DeleteOrganizationMembership(suite.ctx, org.ID, memberID)
+	suite.Require().NoError(err)
+
+	_, err = suite.db.GetOrganizationMembership(suite.ctx, org.ID, memberID)
+	suite.ErrorIs(err, ErrNotFound)
+}
+
+func (suite *PostgresStoreTestSuite) TestListOrganizationApps() {
+	org, err := suite.db.CreateOrganization(suite.ctx, &types.Organization{
+		Name:  "test-" + system.GenerateUUID(),
+		Owner: "test-" + system.GenerateUUID(),
+	})
+	suite.Require().NoError(err)
+
+	app, err := suite.db.CreateApp(suite.ctx, &types.App{
+		Owner:     org.ID,
+		OwnerType: types.OwnerTypeOrg,
+		Config:    types.AppConfig{},
+	})
+	suite.Require().NoError(err)
+
+	suite.T().Cleanup(func() {
+		suite.NoError(suite.db.DeleteApp(suite.ctx, app.ID))
+		suite.NoError(suite.db.DeleteOrganization(suite.ctx, org.ID))
+	})
+
+	apps, err := suite.db.ListApps(suite.ctx, &ListAppsQuery{
+		Owner:     org.ID,
+		OwnerType: types.OwnerTypeOrg,
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(apps, 1)
+	suite.Equal(app.ID, apps[0].ID)
+}
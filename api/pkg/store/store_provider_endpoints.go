@@ -0,0 +1,87 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+type GetProviderEndpointQuery struct {
+	ID   string
+	Name string
+}
+
+func (s *PostgresStore) CreateProviderEndpoint(ctx context.Context, endpoint *types.ProviderEndpoint) (*types.ProviderEndpoint, error) {
+	if endpoint.ID == "" {
+		endpoint.ID = system.GenerateProviderEndpointID()
+	}
+
+	if endpoint.Name == "" {
+		return nil, fmt.Errorf("name not specified")
+	}
+
+	endpoint.Created = time.Now()
+	endpoint.Updated = endpoint.Created
+
+	err := s.gdb.WithContext(ctx).Create(endpoint).Error
+	if err != nil {
+		return nil, err
+	}
+	return s.GetProviderEndpoint(ctx, &GetProviderEndpointQuery{ID: endpoint.ID})
+}
+
+func (s *PostgresStore) GetProviderEndpoint(ctx context.Context, q *GetProviderEndpointQuery) (*types.ProviderEndpoint, error) {
+	if q.ID == "" && q.Name == "" {
+		return nil, fmt.Errorf("id or name not specified")
+	}
+
+	var endpoint types.ProviderEndpoint
+	err := s.gdb.WithContext(ctx).Where(&types.ProviderEndpoint{
+		ID:   q.ID,
+		Name: q.Name,
+	}).First(&endpoint).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+func (s *PostgresStore) ListProviderEndpoints(ctx context.Context) ([]*types.ProviderEndpoint, error) {
+	var endpoints []*types.ProviderEndpoint
+	err := s.gdb.WithContext(ctx).Order("name ASC").Find(&endpoints).Error
+	if err != nil {
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+func (s *PostgresStore) UpdateProviderEndpoint(ctx context.Context, endpoint *types.ProviderEndpoint) (*types.ProviderEndpoint, error) {
+	if endpoint.ID == "" {
+		return nil, fmt.Errorf("id not specified")
+	}
+
+	endpoint.Updated = time.Now()
+
+	err := s.gdb.WithContext(ctx).Save(endpoint).Error
+	if err != nil {
+		return nil, err
+	}
+	return s.GetProviderEndpoint(ctx, &GetProviderEndpointQuery{ID: endpoint.ID})
+}
+
+func (s *PostgresStore) DeleteProviderEndpoint(ctx context.Context, id string) error {
+	if id == "" {
+		return fmt.Errorf("id not specified")
+	}
+
+	return s.gdb.WithContext(ctx).Delete(&types.ProviderEndpoint{ID: id}).Error
+}
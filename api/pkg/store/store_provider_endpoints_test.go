@@ -0,0 +1,39 @@
+package store
+
+import (
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func (suite *PostgresStoreTestSuite) TestProviderEndpoints() {
+	name := "test-" + system.GenerateUUID()
+
+	endpoint, err := suite.db.CreateProviderEndpoint(suite.ctx, &types.ProviderEndpoint{
+		Name:    name,
+		Type:    types.ProviderEndpointTypeOpenAI,
+		BaseURL: "http://vllm:8000/v1",
+		Models:  types.StringList{"llama3"},
+		Headers: types.StringMap{"X-Team": "ml"},
+	})
+	suite.Require().NoError(err)
+	suite.NotEmpty(endpoint.ID)
+
+	suite.T().Cleanup(func() {
+		err := suite.db.DeleteProviderEndpoint(suite.ctx, endpoint.ID)
+		suite.NoError(err)
+	})
+
+	byName, err := suite.db.GetProviderEndpoint(suite.ctx, &GetProviderEndpointQuery{Name: name})
+	suite.Require().NoError(err)
+	suite.Equal(endpoint.ID, byName.ID)
+	suite.Equal(types.StringList{"llama3"}, byName.Models)
+	suite.Equal(types.StringMap{"X-Team": "ml"}, byName.Headers)
+
+	byName.BaseURL = "http://vllm:9000/v1"
+	updated, err := suite.db.UpdateProviderEndpoint(suite.ctx, byName)
+	suite.Require().NoError(err)
+	suite.Equal("http://vllm:9000/v1", updated.BaseURL)
+
+	_, err = suite.db.GetProviderEndpoint(suite.ctx, &GetProviderEndpointQuery{Name: "test-" + system.GenerateUUID()})
+	suite.ErrorIs(err, ErrNotFound)
+}
@@ -0,0 +1,46 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/helixml/helix/api/pkg/types"
+	"gorm.io/gorm/clause"
+)
+
+func (s *PostgresStore) ListSchedulerSlots(ctx context.Context) ([]*types.SchedulerSlot, error) {
+	var slots []*types.SchedulerSlot
+	err := s.gdb.WithContext(ctx).Order("created ASC").Find(&slots).Error
+	if err != nil {
+		return nil, err
+	}
+	return slots, nil
+}
+
+// SaveSchedulerSlot creates or updates the slot
+func (s *PostgresStore) SaveSchedulerSlot(ctx context.Context, slot *types.SchedulerSlot) error {
+	if slot.ID == "" {
+		return fmt.Errorf("id not specified")
+	}
+
+	if slot.RunnerID == "" {
+		return fmt.Errorf("runner id not specified")
+	}
+
+	slot.Created = time.Now()
+	slot.Updated = slot.Created
+
+	return s.gdb.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"updated", "runner_id", "state", "is_new", "work"}),
+	}).Create(slot).Error
+}
+
+func (s *PostgresStore) DeleteSchedulerSlot(ctx context.Context, id string) error {
+	if id == "" {
+		return fmt.Errorf("id not specified")
+	}
+
+	return s.gdb.WithContext(ctx).Delete(&types.SchedulerSlot{ID: id}).Error
+}
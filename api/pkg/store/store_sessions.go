@@ -48,6 +48,10 @@ func (s *PostgresStore) GetSessions(ctx context.Context, query GetSessionsQuery)
 		return nil, err
 	}
 
+	if err := s.loadInteractions(ctx, sessions); err != nil {
+		return nil, err
+	}
+
 	return sessions, nil
 }
 
@@ -76,7 +80,16 @@ func (s *PostgresStore) CreateSession(ctx context.Context, session types.Session
 		session.Created = time.Now()
 	}
 
-	err := s.gdb.WithContext(ctx).Create(&session).Error
+	for _, interaction := range session.Interactions {
+		interaction.SessionID = session.ID
+	}
+
+	err := s.gdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&session).Error; err != nil {
+			return err
+		}
+		return s.createInteractions(tx, session.Interactions)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -84,13 +97,21 @@ func (s *PostgresStore) CreateSession(ctx context.Context, session types.Session
 	return &session, nil
 }
 
+// GetSession returns the session with all of its interactions
 func (s *PostgresStore) GetSession(ctx context.Context, sessionID string) (*types.Session, error) {
-	if sessionID == "" {
+	return s.GetSessionWithInteractions(ctx, &ListInteractionsQuery{
+		SessionID: sessionID,
+	})
+}
+
+// GetSessionWithInteractions returns the session with a page of its interactions
+func (s *PostgresStore) GetSessionWithInteractions(ctx context.Context, query *ListInteractionsQuery) (*types.Session, error) {
+	if query.SessionID == "" {
 		return nil, fmt.Errorf("sessionID cannot be empty")
 	}
 
 	var session types.Session
-	err := s.gdb.WithContext(ctx).Where("id = ?", sessionID).First(&session).Error
+	err := s.gdb.WithContext(ctx).Where("id = ?", query.SessionID).First(&session).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrNotFound
@@ -98,9 +119,16 @@ func (s *PostgresStore) GetSession(ctx context.Context, sessionID string) (*type
 		return nil, err
 	}
 
+	session.Interactions, err = s.ListInteractions(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
 	return &session, nil
 }
 
+// UpdateSession writes the session row only, the interactions are written with
+// CreateInteraction and UpdateInteraction so the conversation isn't rewritten
 func (s *PostgresStore) UpdateSession(ctx context.Context, session types.Session) (*types.Session, error) {
 	if session.ID == "" {
 		return nil, fmt.Errorf("id not specified")
@@ -110,7 +138,7 @@ func (s *PostgresStore) UpdateSession(ctx context.Context, session types.Session
 	if err != nil {
 		return nil, err
 	}
-	return s.GetSession(ctx, session.ID)
+	return &session, nil
 }
 
 func (s *PostgresStore) DeleteSession(ctx context.Context, sessionID string) (*types.Session, error) {
@@ -119,9 +147,14 @@ func (s *PostgresStore) DeleteSession(ctx context.Context, sessionID string) (*t
 		return nil, err
 	}
 
-	err = s.gdb.WithContext(ctx).Delete(&types.Session{
-		ID: sessionID,
-	}).Error
+	err = s.gdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("session_id = ?", sessionID).Delete(&types.Interaction{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&types.Session{
+			ID: sessionID,
+		}).Error
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,116 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+const (
+	// sessionsSearchConfig is the text search configuration used for both the
+	// index and the queries, they must match for the index to be used
+	sessionsSearchConfig = "english"
+	// sessionsSearchVector must match the expression of the index created in autoMigrate
+	sessionsSearchVector = "to_tsvector('english', interactions.message)"
+	// websearch_to_tsquery accepts the syntax users expect from search engines:
+	// quoted phrases, "or" and -excluded words
+	sessionsSearchTSQuery = "websearch_to_tsquery('english', ?)"
+	// Markdown bold, the interaction messages are rendered as markdown
+	sessionsSearchHeadline = "StartSel=**, StopSel=**, MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=\" ... \""
+)
+
+// SearchSessions finds the interactions of the owner's sessions that match
+// the query, best matches first
+func (s *PostgresStore) SearchSessions(ctx context.Context, query *SearchSessionsQuery) ([]*types.SessionSearchResult, error) {
+	q, err := s.searchSessionsQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	q = q.Select(`session.id AS session_id,
+		session.name AS session_name,
+		session.parent_app AS app_id,
+		session.mode AS mode,
+		session.model_name AS model_name,
+		interactions.id AS interaction_id,
+		interactions.creator AS creator,
+		interactions.created AS created,
+		ts_headline(?, interactions.message, `+sessionsSearchTSQuery+`, ?) AS snippet,
+		ts_rank(`+sessionsSearchVector+`, `+sessionsSearchTSQuery+`) AS rank`,
+		sessionsSearchConfig, query.Query, sessionsSearchHeadline, query.Query,
+	).Order("rank DESC, interactions.created DESC")
+
+	if query.Offset > 0 {
+		q = q.Offset(query.Offset)
+	}
+
+	if query.Limit > 0 {
+		q = q.Limit(query.Limit)
+	}
+
+	results := []*types.SessionSearchResult{}
+	err = q.Scan(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (s *PostgresStore) SearchSessionsCounter(ctx context.Context, query *SearchSessionsQuery) (*types.Counter, error) {
+	q, err := s.searchSessionsQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var counter int64
+	err = q.Count(&counter).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.Counter{
+		Count: counter,
+	}, nil
+}
+
+func (s *PostgresStore) searchSessionsQuery(ctx context.Context, query *SearchSessionsQuery) (*gorm.DB, error) {
+	if query.Owner == "" || query.OwnerType == "" {
+		return nil, fmt.Errorf("owner not specified")
+	}
+
+	if query.Query == "" {
+		return nil, fmt.Errorf("query not specified")
+	}
+
+	q := s.gdb.WithContext(ctx).
+		Table("interactions").
+		Joins("JOIN session ON session.id = interactions.session_id").
+		Where(sessionsSearchVector+" @@ "+sessionsSearchTSQuery, query.Query).
+		Where("session.owner = ? AND session.owner_type = ?", query.Owner, query.OwnerType)
+
+	if query.AppID != "" {
+		q = q.Where("session.parent_app = ?", query.AppID)
+	}
+
+	if query.ModelName != "" {
+		q = q.Where("session.model_name = ?", query.ModelName)
+	}
+
+	if query.Mode != "" {
+		q = q.Where("session.mode = ?", query.Mode)
+	}
+
+	if !query.From.IsZero() {
+		q = q.Where("interactions.created >= ?", query.From)
+	}
+
+	if !query.To.IsZero() {
+		q = q.Where("interactions.created < ?", query.To)
+	}
+
+	return q, nil
+}
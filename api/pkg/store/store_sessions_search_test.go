@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func (suite *PostgresStoreTestSuite) TestPostgresStore_SearchSessions() {
+	owner := "user_" + system.GenerateUUID()
+
+	for _, session := range []types.Session{
+		{
+			ID:        system.GenerateSessionID(),
+			Name:      "billing",
+			Owner:     owner,
+			OwnerType: types.OwnerTypeUser,
+			Mode:      types.SessionModeInference,
+			ParentApp: "app_1",
+			Interactions: []*types.Interaction{
+				{ID: "id-1", Creator: types.CreatorTypeUser, Message: "How do I paginate the invoices in the billing API?"},
+				{ID: "id-2", Creator: types.CreatorTypeAssistant, Message: "Pass a cursor to the list invoices endpoint."},
+			},
+		},
+		{
+			ID:        system.GenerateSessionID(),
+			Name:      "other",
+			Owner:     owner,
+			OwnerType: types.OwnerTypeUser,
+			Mode:      types.SessionModeInference,
+			Interactions: []*types.Interaction{
+				{ID: "id-1", Creator: types.CreatorTypeUser, Message: "Write a poem about invoices"},
+			},
+		},
+		{
+			ID:        system.GenerateSessionID(),
+			Owner:     "someone_else",
+			OwnerType: types.OwnerTypeUser,
+			Interactions: []*types.Interaction{
+				{ID: "id-1", Creator: types.CreatorTypeUser, Message: "Billing API invoices"},
+			},
+		},
+	} {
+		_, err := suite.db.CreateSession(context.Background(), session)
+		suite.Require().NoError(err)
+
+		id := session.ID
+		suite.T().Cleanup(func() {
+			_, _ = suite.db.DeleteSession(context.Background(), id)
+		})
+	}
+
+	query := &SearchSessionsQuery{
+		Owner:     owner,
+		OwnerType: types.OwnerTypeUser,
+		Query:     `"billing api"`,
+	}
+
+	results, err := suite.db.SearchSessions(context.Background(), query)
+	suite.NoError(err)
+	suite.Require().Equal(1, len(results))
+	suite.Equal("billing", results[0].SessionName)
+	suite.Equal("id-1", results[0].InteractionID)
+	suite.Equal("app_1", results[0].AppID)
+	suite.Contains(results[0].Snippet, "**billing**")
+
+	counter, err := suite.db.SearchSessionsCounter(context.Background(), query)
+	suite.NoError(err)
+	suite.Equal(int64(1), counter.Count)
+
+	// Stemming matches invoice and invoices in both sessions
+	query.Query = "invoice"
+	results, err = suite.db.SearchSessions(context.Background(), query)
+	suite.NoError(err)
+	suite.Equal(3, len(results))
+
+	query.AppID = "app_1"
+	results, err = suite.db.SearchSessions(context.Background(), query)
+	suite.NoError(err)
+	suite.Equal(2, len(results))
+
+	query.From = time.Now().Add(time.Hour)
+	results, err = suite.db.SearchSessions(context.Background(), query)
+	suite.NoError(err)
+	suite.Equal(0, len(results))
+}
@@ -109,13 +109,25 @@ func (suite *PostgresStoreTestSuite) TestPostgresStore_UpdateSession() {
 	}
 
 	// Call the UpdateSession method to update the session
-	updatedSession, err := suite.db.UpdateSession(context.Background(), session)
+	_, err = suite.db.UpdateSession(context.Background(), session)
 
 	// Assert that no error occurred
 	suite.NoError(err)
 
-	// Assert that the updated session matches the modified session
+	// Only the session row is written
+	updatedSession, err := suite.db.GetSession(context.Background(), session.ID)
+	suite.NoError(err)
 	suite.Equal("new_name", updatedSession.Name)
+	suite.Equal(0, len(updatedSession.Interactions))
+
+	for _, interaction := range session.Interactions {
+		interaction.SessionID = session.ID
+	}
+	err = suite.db.CreateInteractions(context.Background(), session.Interactions...)
+	suite.NoError(err)
+
+	updatedSession, err = suite.db.GetSession(context.Background(), session.ID)
+	suite.NoError(err)
 	suite.Equal(2, len(updatedSession.Interactions))
 
 	// Assert that the interactions are in the correct order
@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+type ListTriggerExecutionsQuery struct {
+	AppID  string
+	Offset int
+	Limit  int
+}
+
+func (s *PostgresStore) CreateTriggerExecution(ctx context.Context, execution *types.TriggerExecution) (*types.TriggerExecution, error) {
+	if execution.ID == "" {
+		execution.ID = system.GenerateTriggerExecutionID()
+	}
+
+	if execution.AppID == "" {
+		return nil, fmt.Errorf("app ID not specified")
+	}
+
+	execution.Created = time.Now()
+	execution.Updated = execution.Created
+
+	err := s.gdb.WithContext(ctx).Create(execution).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return execution, nil
+}
+
+func (s *PostgresStore) UpdateTriggerExecution(ctx context.Context, execution *types.TriggerExecution) (*types.TriggerExecution, error) {
+	if execution.ID == "" {
+		return nil, fmt.Errorf("id not specified")
+	}
+
+	execution.Updated = time.Now()
+
+	err := s.gdb.WithContext(ctx).Save(execution).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return execution, nil
+}
+
+// ListTriggerExecutions returns the executions, newest first
+func (s *PostgresStore) ListTriggerExecutions(ctx context.Context, q *ListTriggerExecutionsQuery) ([]*types.TriggerExecution, error) {
+	var executions []*types.TriggerExecution
+	query := s.gdb.WithContext(ctx)
+
+	if q.AppID != "" {
+		query = query.Where("app_id = ?", q.AppID)
+	}
+
+	if q.Offset > 0 {
+		query = query.Offset(q.Offset)
+	}
+
+	if q.Limit > 0 {
+		query = query.Limit(q.Limit)
+	}
+
+	err := query.Order("created DESC").Find(&executions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return executions, nil
+}
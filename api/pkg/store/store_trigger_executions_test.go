@@ -0,0 +1,59 @@
+package store
+
+import (
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func (suite *PostgresStoreTestSuite) TestTriggerExecutions() {
+	app, err := suite.db.CreateApp(suite.ctx, &types.App{
+		Owner:     "test-" + system.GenerateUUID(),
+		OwnerType: types.OwnerTypeUser,
+		Config:    types.AppConfig{},
+	})
+	suite.Require().NoError(err)
+
+	suite.T().Cleanup(func() {
+		err := suite.db.DeleteApp(suite.ctx, app.ID)
+		suite.NoError(err)
+	})
+
+	first, err := suite.db.CreateTriggerExecution(suite.ctx, &types.TriggerExecution{
+		AppID:   app.ID,
+		Trigger: types.TriggerTypeCron,
+		Status:  types.TriggerExecutionStatusRunning,
+	})
+	suite.Require().NoError(err)
+	suite.NotEmpty(first.ID)
+
+	first.Status = types.TriggerExecutionStatusSuccess
+	first.Output = "done"
+	_, err = suite.db.UpdateTriggerExecution(suite.ctx, first)
+	suite.Require().NoError(err)
+
+	second, err := suite.db.CreateTriggerExecution(suite.ctx, &types.TriggerExecution{
+		AppID:   app.ID,
+		Trigger: types.TriggerTypeCron,
+		Status:  types.TriggerExecutionStatusRunning,
+	})
+	suite.Require().NoError(err)
+
+	executions, err := suite.db.ListTriggerExecutions(suite.ctx, &ListTriggerExecutionsQuery{
+		AppID: app.ID,
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(executions, 2)
+
+	// Newest first
+	suite.Equal(second.ID, executions[0].ID)
+	suite.Equal(first.ID, executions[1].ID)
+	suite.Equal(types.TriggerExecutionStatusSuccess, executions[1].Status)
+	suite.Equal("done", executions[1].Output)
+
+	executions, err = suite.db.ListTriggerExecutions(suite.ctx, &ListTriggerExecutionsQuery{
+		AppID: app.ID,
+		Limit: 1,
+	})
+	suite.Require().NoError(err)
+	suite.Len(executions, 1)
+}
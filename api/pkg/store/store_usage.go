@@ -0,0 +1,86 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+type ListUsageQuery struct {
+	Owner     string
+	OwnerType types.OwnerType
+	AppID     string
+	APIKeyID  string
+	Provider  string
+	Model     string
+	// From and To are inclusive dates
+	From time.Time
+	To   time.Time
+}
+
+// RecordUsage adds the usage to the ledger row of the day
+func (s *PostgresStore) RecordUsage(ctx context.Context, usage *types.DailyUsage) error {
+	if usage.Owner == "" {
+		return fmt.Errorf("owner not specified")
+	}
+
+	if usage.Date.IsZero() {
+		return fmt.Errorf("date not specified")
+	}
+
+	usage.Date = usageDate(usage.Date)
+	usage.Updated = time.Now()
+
+	return s.gdb.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{
+			{Name: "date"}, {Name: "owner"}, {Name: "owner_type"}, {Name: "app_id"},
+			{Name: "api_key_id"}, {Name: "provider"}, {Name: "model"},
+		},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"updated":           usage.Updated,
+			"requests":          gorm.Expr("daily_usage.requests + excluded.requests"),
+			"prompt_tokens":     gorm.Expr("daily_usage.prompt_tokens + excluded.prompt_tokens"),
+			"completion_tokens": gorm.Expr("daily_usage.completion_tokens + excluded.completion_tokens"),
+			"total_tokens":      gorm.Expr("daily_usage.total_tokens + excluded.total_tokens"),
+			"cost":              gorm.Expr("daily_usage.cost + excluded.cost"),
+		}),
+	}).Create(usage).Error
+}
+
+func (s *PostgresStore) ListUsage(ctx context.Context, q *ListUsageQuery) ([]*types.DailyUsage, error) {
+	var usage []*types.DailyUsage
+
+	query := s.gdb.WithContext(ctx).Where(&types.DailyUsage{
+		Owner:     q.Owner,
+		OwnerType: q.OwnerType,
+		AppID:     q.AppID,
+		APIKeyID:  q.APIKeyID,
+		Provider:  q.Provider,
+		Model:     q.Model,
+	})
+
+	if !q.From.IsZero() {
+		query = query.Where("date >= ?", usageDate(q.From))
+	}
+
+	if !q.To.IsZero() {
+		query = query.Where("date <= ?", usageDate(q.To))
+	}
+
+	err := query.Order("date ASC, owner, app_id, api_key_id, provider, model").Find(&usage).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return usage, nil
+}
+
+// usageDate returns the UTC day of the time, the ledger is kept in UTC days
+func usageDate(t time.Time) time.Time {
+	return time.Date(t.UTC().Year(), t.UTC().Month(), t.UTC().Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,58 @@
+package store
+
+import (
+	"time"
+
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func (suite *PostgresStoreTestSuite) TestUsage() {
+	owner := "test-" + system.GenerateUUID()
+	day := time.Date(2024, 9, 3, 15, 4, 5, 0, time.UTC)
+
+	record := func(date time.Time, model string, tokens int64) {
+		err := suite.db.RecordUsage(suite.ctx, &types.DailyUsage{
+			Date:      date,
+			Owner:     owner,
+			OwnerType: types.OwnerTypeUser,
+			AppID:     "app_1",
+			Provider:  "openai",
+			Model:     model,
+			UsageTotals: types.UsageTotals{
+				Requests:    1,
+				TotalTokens: tokens,
+				Cost:        0.5,
+			},
+		})
+		suite.Require().NoError(err)
+	}
+
+	// Calls of the same day are added up
+	record(day, "gpt-4o", 100)
+	record(day.Add(time.Hour), "gpt-4o", 50)
+	record(day, "gpt-4o-mini", 10)
+	record(day.AddDate(0, 0, 1), "gpt-4o", 20)
+
+	usage, err := suite.db.ListUsage(suite.ctx, &ListUsageQuery{
+		Owner: owner,
+		From:  day,
+		To:    day,
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(usage, 2)
+
+	suite.Equal("gpt-4o", usage[0].Model)
+	suite.Equal(int64(2), usage[0].Requests)
+	suite.Equal(int64(150), usage[0].TotalTokens)
+	suite.InDelta(1.0, usage[0].Cost, 1e-9)
+
+	usage, err = suite.db.ListUsage(suite.ctx, &ListUsageQuery{
+		Owner: owner,
+		Model: "gpt-4o",
+		From:  day,
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(usage, 2)
+	suite.Equal(int64(20), usage[1].TotalTokens)
+}
@@ -0,0 +1,29 @@
+package system
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncrypt(t *testing.T) {
+	encrypted, err := Encrypt("passphrase", "sk-secret")
+	require.NoError(t, err)
+	assert.NotContains(t, encrypted, "sk-secret")
+
+	// Every encryption uses a new nonce
+	other, err := Encrypt("passphrase", "sk-secret")
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted, other)
+
+	secret, err := Decrypt("passphrase", encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "sk-secret", secret)
+
+	_, err = Decrypt("other", encrypted)
+	assert.ErrorContains(t, err, "failed to decrypt secret")
+
+	_, err = Encrypt("", "sk-secret")
+	assert.ErrorContains(t, err, "encryption key not configured")
+}
@@ -0,0 +1,59 @@
+package system
+
+import (
+	"sync"
+)
+
+// SerialWorker runs the submitted functions one at a time in a background
+// goroutine, in the order they were submitted. Submit never blocks, so slow
+// work like database writes can be submitted while holding a lock.
+type SerialWorker struct {
+	mu      sync.Mutex
+	pending []func()
+	wake    chan struct{}
+}
+
+func NewSerialWorker() *SerialWorker {
+	w := &SerialWorker{
+		wake: make(chan struct{}, 1),
+	}
+	go w.run()
+	return w
+}
+
+// Submit queues the function to run after the ones submitted before it
+func (w *SerialWorker) Submit(fn func()) {
+	w.mu.Lock()
+	w.pending = append(w.pending, fn)
+	w.mu.Unlock()
+
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Flush waits until the functions submitted so far have run
+func (w *SerialWorker) Flush() {
+	done := make(chan struct{})
+	w.Submit(func() { close(done) })
+	<-done
+}
+
+func (w *SerialWorker) run() {
+	for range w.wake {
+		for {
+			w.mu.Lock()
+			if len(w.pending) == 0 {
+				w.mu.Unlock()
+				break
+			}
+			fn := w.pending[0]
+			w.pending[0] = nil
+			w.pending = w.pending[1:]
+			w.mu.Unlock()
+
+			fn()
+		}
+	}
+}
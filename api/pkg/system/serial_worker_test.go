@@ -0,0 +1,24 @@
+package system
+
+import (
+	"testing"
+)
+
+func TestSerialWorkerOrder(t *testing.T) {
+	w := NewSerialWorker()
+
+	var got []int
+	for i := 0; i < 100; i++ {
+		w.Submit(func() { got = append(got, i) })
+	}
+	w.Flush()
+
+	if len(got) != 100 {
+		t.Fatalf("expected 100 calls, got %d", len(got))
+	}
+	for i, v := range got {
+		if v != i {
+			t.Fatalf("expected call %d to be %d, got %d", i, i, v)
+		}
+	}
+}
@@ -10,15 +10,20 @@ import (
 )
 
 const (
-	ToolPrefix                = "tool_"
-	SessionPrefix             = "ses_"
-	AppPrefix                 = "app_"
-	GptScriptRunnerTaskPrefix = "gst_"
-	RequestPrefix             = "req_"
-	DataEntityPrefix          = "dent_"
-	LLMCallPrefix             = "llmc_"
-	KnowledgePrefix           = "kno_"
-	KnowledgeVersionPrefix    = "knov_"
+	ToolPrefix                  = "tool_"
+	SessionPrefix               = "ses_"
+	AppPrefix                   = "app_"
+	GptScriptRunnerTaskPrefix   = "gst_"
+	RequestPrefix               = "req_"
+	DataEntityPrefix            = "dent_"
+	LLMCallPrefix               = "llmc_"
+	KnowledgePrefix             = "kno_"
+	KnowledgeVersionPrefix      = "knov_"
+	KnowledgeSourceObjectPrefix = "knso_"
+	OrganizationPrefix          = "org_"
+	TriggerExecutionPrefix      = "trex_"
+	ProviderEndpointPrefix      = "pe_"
+	AuditLogPrefix              = "audit_"
 )
 
 func GenerateUUID() string {
@@ -69,6 +74,26 @@ func GenerateKnowledgeVersionID() string {
 	return fmt.Sprintf("%s%s", KnowledgeVersionPrefix, newID())
 }
 
+func GenerateKnowledgeSourceObjectID() string {
+	return fmt.Sprintf("%s%s", KnowledgeSourceObjectPrefix, newID())
+}
+
+func GenerateOrganizationID() string {
+	return fmt.Sprintf("%s%s", OrganizationPrefix, newID())
+}
+
+func GenerateTriggerExecutionID() string {
+	return fmt.Sprintf("%s%s", TriggerExecutionPrefix, newID())
+}
+
+func GenerateProviderEndpointID() string {
+	return fmt.Sprintf("%s%s", ProviderEndpointPrefix, newID())
+}
+
+func GenerateAuditLogID() string {
+	return fmt.Sprintf("%s%s", AuditLogPrefix, newID())
+}
+
 // GenerateVersion generates a version string for the knowledge
 // This is used to identify the version of the knowledge
 // and to determine if the knowledge has been updated
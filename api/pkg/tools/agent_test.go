@@ -0,0 +1,222 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/openai"
+	"github.com/helixml/helix/api/pkg/types"
+
+	oai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAgentTestSuite(t *testing.T) {
+	suite.Run(t, new(AgentTestSuite))
+}
+
+type AgentTestSuite struct {
+	suite.Suite
+	ctx       context.Context
+	apiClient *openai.MockClient
+	strategy  *ChainStrategy
+}
+
+func (suite *AgentTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.apiClient = openai.NewMockClient(gomock.NewController(suite.T()))
+
+	strategy, err := NewChainStrategy(&config.ServerConfig{}, nil, nil, nil, suite.apiClient)
+	suite.Require().NoError(err)
+
+	suite.strategy = strategy
+}
+
+func agentChatResponse(content string) oai.ChatCompletionResponse {
+	return oai.ChatCompletionResponse{
+		Choices: []oai.ChatCompletionChoice{
+			{Message: oai.ChatCompletionMessage{Content: content}},
+		},
+	}
+}
+
+func agentPetStoreTool(url string) *types.Tool {
+	return &types.Tool{
+		Name:     "petStore",
+		ToolType: types.ToolTypeAPI,
+		Config: types.ToolConfig{
+			API: &types.ToolApiConfig{
+				URL:    url,
+				Schema: petStoreApiSpec,
+				Actions: []*types.ToolApiAction{
+					{
+						Name:        "listPets",
+						Description: "List all pets",
+						Method:      "GET",
+						Path:        "/pets",
+					},
+					{
+						Name:        "showPetById",
+						Description: "Info for a specific pet",
+						Method:      "GET",
+						Path:        "/pets/{petId}",
+					},
+				},
+			},
+		},
+	}
+}
+
+func (suite *AgentTestSuite) TestRunAgent_MultipleSteps() {
+	var paths []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+
+		if r.URL.Path == "/pets" {
+			fmt.Fprintln(w, `[{"id": 1, "name": "kitty"}, {"id": 99944, "name": "doggie"}]`)
+			return
+		}
+		fmt.Fprintln(w, `{"id": 99944, "name": "doggie", "tag": "dog"}`)
+	}))
+	defer ts.Close()
+
+	plans := []string{
+		`{"thought": "Get the details of pet 99944", "action": "showPetById"}`,
+		`{"thought": "List the other pets", "action": "listPets"}`,
+		`{"thought": "I can answer now", "action": ""}`,
+	}
+	params := []string{`{"petId": "99944"}`, `{}`}
+	interpreted := []string{"Pet 99944 is a dog called doggie", "There are 2 pets"}
+
+	var planCalls, paramCalls, interpretCalls int
+
+	suite.apiClient.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, req oai.ChatCompletionRequest) (oai.ChatCompletionResponse, error) {
+			step, ok := openai.GetStep(ctx)
+			suite.Require().True(ok)
+
+			lastMessage := req.Messages[len(req.Messages)-1].Content
+
+			switch step.Step {
+			case types.LLMCallStepAgentPlan:
+				if planCalls == 2 {
+					suite.Contains(req.Messages[len(req.Messages)-2].Content, "Result of listPets:\nThere are 2 pets")
+				}
+				planCalls++
+				return agentChatResponse(plans[planCalls-1]), nil
+			case types.LLMCallStepPrepareAPIRequest:
+				userMessage := req.Messages[len(req.Messages)-2].Content
+				if paramCalls == 1 {
+					suite.Contains(userMessage, "Result of showPetById:\nPet 99944 is a dog called doggie")
+					suite.Contains(userMessage, "Current step: List the other pets")
+				}
+				paramCalls++
+				return agentChatResponse(params[paramCalls-1]), nil
+			case types.LLMCallStepInterpretResponse:
+				interpretCalls++
+				return agentChatResponse(interpreted[interpretCalls-1]), nil
+			}
+
+			return oai.ChatCompletionResponse{}, fmt.Errorf("unexpected step %s: %s", step.Step, lastMessage)
+		}).Times(7)
+
+	var events []AgentStep
+
+	history := []*types.ToolHistoryMessage{
+		{
+			Role:    oai.ChatMessageRoleUser,
+			Content: "Tell me about pet 99944 and which other pets there are",
+		},
+	}
+
+	resp, err := suite.strategy.RunAgent(suite.ctx, "session-123", "i-123", []*types.Tool{agentPetStoreTool(ts.URL)}, history,
+		WithAgentStepCallback(func(step *AgentStep) {
+			events = append(events, *step)
+		}),
+	)
+	suite.Require().NoError(err)
+
+	suite.False(resp.OutOfSteps)
+	suite.Require().Len(resp.Steps, 2)
+	suite.Equal("petStore", resp.Steps[0].Tool)
+	suite.Equal("Pet 99944 is a dog called doggie", resp.Steps[0].Result)
+	suite.Equal("There are 2 pets", resp.Steps[1].Result)
+
+	suite.Equal([]string{"/pets/99944", "/pets"}, paths)
+
+	suite.Require().Len(events, 4)
+	suite.False(events[0].Finished)
+	suite.True(events[1].Finished)
+	suite.Equal(2, events[3].Number)
+}
+
+func (suite *AgentTestSuite) TestRunAgent_NoToolsNeeded() {
+	suite.apiClient.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).
+		Return(agentChatResponse(`{"thought": "This is a general question", "action": ""}`), nil)
+
+	history := []*types.ToolHistoryMessage{
+		{
+			Role:    oai.ChatMessageRoleUser,
+			Content: "What is a pet?",
+		},
+	}
+
+	resp, err := suite.strategy.RunAgent(suite.ctx, "session-123", "i-123", []*types.Tool{agentPetStoreTool("http://localhost")}, history)
+	suite.Require().NoError(err)
+
+	suite.Empty(resp.Steps)
+	suite.False(resp.OutOfSteps)
+}
+
+func (suite *AgentTestSuite) TestRunAgent_OutOfSteps() {
+	suite.apiClient.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).
+		Return(agentChatResponse(`{"thought": "Feed the pet", "action": "feedPet"}`), nil).
+		Times(2)
+
+	history := []*types.ToolHistoryMessage{
+		{
+			Role:    oai.ChatMessageRoleUser,
+			Content: "Feed pet 99944",
+		},
+	}
+
+	resp, err := suite.strategy.RunAgent(suite.ctx, "session-123", "i-123", []*types.Tool{agentPetStoreTool("http://localhost")}, history,
+		WithMaxAgentSteps(2),
+	)
+	suite.Require().NoError(err)
+
+	suite.True(resp.OutOfSteps)
+	suite.Require().Len(resp.Steps, 2)
+	suite.Equal("there is no tool for the action feedPet", resp.Steps[1].Error)
+}
+
+func TestAgentPrompt(t *testing.T) {
+	prompt, err := AgentPrompt("Feed pet 99944 and tell me its name", &RunAgentResponse{
+		Steps: []*AgentStep{
+			{Number: 1, Action: "showPetById", Result: "Pet 99944 is called doggie"},
+			{Number: 2, Action: "feedPet", Error: "the pet is not hungry"},
+		},
+		OutOfSteps: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		"Feed pet 99944 and tell me its name",
+		"1. showPetById: Pet 99944 is called doggie",
+		"2. feedPet: failed with the error: the pet is not hungry",
+		"run out of steps",
+	} {
+		if !strings.Contains(prompt, expected) {
+			t.Errorf("expected %q in the prompt:\n%s", expected, prompt)
+		}
+	}
+}
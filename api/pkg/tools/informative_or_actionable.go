@@ -47,6 +47,7 @@ func (c *ChainStrategy) IsActionable(ctx context.Context, sessionID, interaction
 func (c *ChainStrategy) getDefaultOptions() Options {
 	return Options{
 		isActionableTemplate: c.isActionableTemplate,
+		maxAgentSteps:        defaultMaxAgentSteps,
 	}
 }
 
@@ -170,6 +171,31 @@ func (c *ChainStrategy) getActionableSystemPrompt(tools []*types.Tool, options O
 		return openai.ChatCompletionMessage{}, fmt.Errorf("failed to parse 'isInformativeOrActionablePrompt' template: %w", err)
 	}
 
+	modelTools := getModelTools(tools)
+
+	// Render template
+	var sb strings.Builder
+	err = tmpl.Execute(&sb, struct {
+		Tools []*modelTool
+	}{
+		Tools: modelTools,
+	})
+
+	if err != nil {
+		return openai.ChatCompletionMessage{}, fmt.Errorf("failed to render 'isInformativeOrActionablePrompt' template: %w", err)
+	}
+
+	// log.Info().Msgf("tools prompt: %s", sb.String())
+
+	return openai.ChatCompletionMessage{
+		Role:    openai.ChatMessageRoleSystem,
+		Content: sb.String(),
+	}, nil
+}
+
+// getModelTools lists the tools for the prompts, API tools are listed
+// action by action
+func getModelTools(tools []*types.Tool) []*modelTool {
 	var modelTools []*modelTool
 
 	for _, tool := range tools {
@@ -196,28 +222,17 @@ func (c *ChainStrategy) getActionableSystemPrompt(tools []*types.Tool, options O
 				Description: tool.Description,
 				ToolType:    string(tool.ToolType),
 			})
+		case types.ToolTypeCode:
+			modelTools = append(modelTools, &modelTool{
+				Name:        tool.Name,
+				Description: tool.Description,
+				ToolType:    string(tool.ToolType),
+			})
 		}
 
 	}
 
-	// Render template
-	var sb strings.Builder
-	err = tmpl.Execute(&sb, struct {
-		Tools []*modelTool
-	}{
-		Tools: modelTools,
-	})
-
-	if err != nil {
-		return openai.ChatCompletionMessage{}, fmt.Errorf("failed to render 'isInformativeOrActionablePrompt' template: %w", err)
-	}
-
-	// log.Info().Msgf("tools prompt: %s", sb.String())
-
-	return openai.ChatCompletionMessage{
-		Role:    openai.ChatMessageRoleSystem,
-		Content: sb.String(),
-	}, nil
+	return modelTools
 }
 
 // modelTool is used to render the template. It can be an API endpoint, a function, etc.
@@ -227,7 +242,7 @@ type modelTool struct {
 	ToolType    string
 }
 
-const isInformativeOrActionablePrompt = `You are an AI that classifies whether user input requires the use of a tool or not. You should recommend using a tool if the user request matches one of the tool descriptions below. Such user requests can be fulfilled by calling a tool or external API to either execute something or fetch more data to help in answering the question. Also, if the user question is asking you to perform actions (e.g. list, create, update, delete) then you will need to use an tool. If the user asks about a specific item or person, always check with an appropriate tool rather than making something up/depending on your background knowledge. There are two types of tools: api tools and gptscript tools. API tools are used to call APIs. gptscript tools can do anything. If the user mentions gptscript, use one of the gptscript tools.
+const isInformativeOrActionablePrompt = `You are an AI that classifies whether user input requires the use of a tool or not. You should recommend using a tool if the user request matches one of the tool descriptions below. Such user requests can be fulfilled by calling a tool or external API to either execute something or fetch more data to help in answering the question. Also, if the user question is asking you to perform actions (e.g. list, create, update, delete) then you will need to use an tool. If the user asks about a specific item or person, always check with an appropriate tool rather than making something up/depending on your background knowledge. There are three types of tools: api tools, gptscript tools and code tools. API tools are used to call APIs. gptscript tools can do anything. If the user mentions gptscript, use one of the gptscript tools. code tools write and run code, use them for calculations, data analysis and processing the user's files.
 
 Examples:  
 
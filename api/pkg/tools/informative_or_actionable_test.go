@@ -58,7 +58,7 @@ func (suite *ActionTestSuite) SetupTest() {
 		apiClient = openai.NewMockClient(suite.ctrl)
 	}
 
-	strategy, err := NewChainStrategy(&cfg, suite.store, suite.executor, apiClient)
+	strategy, err := NewChainStrategy(&cfg, suite.store, suite.executor, nil, apiClient)
 	suite.NoError(err)
 
 	suite.strategy = strategy
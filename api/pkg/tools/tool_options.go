@@ -10,6 +10,8 @@ type Option func(*Options) error
 // Options can be used to create a customized connection.
 type Options struct {
 	isActionableTemplate string
+	maxAgentSteps        int
+	onAgentStep          func(step *AgentStep)
 }
 
 func WithIsActionableTemplate(isActionableTemplate string) Option {
@@ -18,3 +20,21 @@ func WithIsActionableTemplate(isActionableTemplate string) Option {
 		return nil
 	}
 }
+
+// WithMaxAgentSteps limits the number of tool calls the agent can make
+func WithMaxAgentSteps(maxAgentSteps int) Option {
+	return func(o *Options) error {
+		if maxAgentSteps > 0 {
+			o.maxAgentSteps = maxAgentSteps
+		}
+		return nil
+	}
+}
+
+// WithAgentStepCallback is called when the agent starts and finishes every step
+func WithAgentStepCallback(onAgentStep func(step *AgentStep)) Option {
+	return func(o *Options) error {
+		o.onAgentStep = onAgentStep
+		return nil
+	}
+}
@@ -11,6 +11,7 @@ import (
 	oai "github.com/sashabaranov/go-openai"
 
 	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/filestore"
 	"github.com/helixml/helix/api/pkg/gptscript"
 	"github.com/helixml/helix/api/pkg/openai"
 	"github.com/helixml/helix/api/pkg/store"
@@ -24,6 +25,8 @@ type Planner interface {
 	// TODO: RAG lookup
 	RunAction(ctx context.Context, sessionID, interactionID string, tool *types.Tool, history []*types.ToolHistoryMessage, action string) (*RunActionResponse, error)
 	RunActionStream(ctx context.Context, sessionID, interactionID string, tool *types.Tool, history []*types.ToolHistoryMessage, action string) (*oai.ChatCompletionStream, error)
+	// Multi-step tool calling, the results are used to answer the user
+	RunAgent(ctx context.Context, sessionID, interactionID string, tools []*types.Tool, history []*types.ToolHistoryMessage, options ...Option) (*RunAgentResponse, error)
 	// Validation and defaulting
 	ValidateAndDefault(ctx context.Context, tool *types.Tool) (*types.Tool, error)
 }
@@ -37,11 +40,12 @@ type ChainStrategy struct {
 	apiClient            openai.Client
 	httpClient           *http.Client
 	gptScriptExecutor    gptscript.Executor
+	filestore            filestore.FileStore
 	isActionableTemplate string
 	wg                   sync.WaitGroup
 }
 
-func NewChainStrategy(cfg *config.ServerConfig, store store.Store, gptScriptExecutor gptscript.Executor, client openai.Client) (*ChainStrategy, error) {
+func NewChainStrategy(cfg *config.ServerConfig, store store.Store, gptScriptExecutor gptscript.Executor, fileStore filestore.FileStore, client openai.Client) (*ChainStrategy, error) {
 	isActionableTemplate, err := getIsActionablePromptTemplate(cfg)
 	if err != nil {
 		log.Err(err).Msg("failed to get actionable template, falling back to default")
@@ -55,6 +59,7 @@ func NewChainStrategy(cfg *config.ServerConfig, store store.Store, gptScriptExec
 		apiClient:            client,
 		store:                store,
 		gptScriptExecutor:    gptScriptExecutor,
+		filestore:            fileStore,
 		httpClient:           retryClient.StandardClient(),
 		isActionableTemplate: isActionableTemplate,
 	}, nil
@@ -38,6 +38,8 @@ func (c *ChainStrategy) RunAction(ctx context.Context, sessionID, interactionID
 		)
 	case types.ToolTypeZapier:
 		return c.RunZapierAction(ctx, tool, history, action)
+	case types.ToolTypeCode:
+		return c.RunCodeAction(ctx, sessionID, interactionID, tool, history, action)
 	default:
 		return nil, fmt.Errorf("unknown tool type: %s", tool.ToolType)
 	}
@@ -51,6 +53,8 @@ func (c *ChainStrategy) RunActionStream(ctx context.Context, sessionID, interact
 		return c.runApiActionStream(ctx, sessionID, interactionID, tool, history, action)
 	case types.ToolTypeZapier:
 		return c.RunZapierActionStream(ctx, tool, history, action)
+	case types.ToolTypeCode:
+		return c.RunCodeActionStream(ctx, sessionID, interactionID, tool, history, action)
 	default:
 		return nil, fmt.Errorf("unknown tool type: %s", tool.ToolType)
 	}
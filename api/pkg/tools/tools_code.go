@@ -0,0 +1,452 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/helixml/helix/api/pkg/filestore"
+	oai "github.com/helixml/helix/api/pkg/openai"
+	"github.com/helixml/helix/api/pkg/openai/transport"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+// Limits of the code tools, the sandbox of the GPTScript runners enforces them
+const (
+	defaultCodeTimeoutSeconds = 30
+	maxCodeTimeoutSeconds     = 300
+	defaultCodeMemoryMB       = 256
+	maxCodeMemoryMB           = 4096
+	defaultCodeCPUs           = 1.0
+	maxCodeCPUs               = 4
+
+	// maxSessionFilesBytes is the total size of the session files that are
+	// copied into the sandbox
+	maxSessionFilesBytes = 20 * 1024 * 1024
+	// maxCodeOutputMessage is the size of stdout and stderr in the message
+	// returned to the model
+	maxCodeOutputMessage = 8 * 1024
+)
+
+func (c *ChainStrategy) RunCodeAction(ctx context.Context, sessionID, interactionID string, tool *types.Tool, history []*types.ToolHistoryMessage, action string) (*RunActionResponse, error) {
+	result, err := c.runCode(ctx, sessionID, interactionID, tool, history)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := json.Marshal(withoutFileContent(result))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal code execution result: %w", err)
+	}
+
+	return &RunActionResponse{
+		Message:    formatCodeResult(result),
+		RawMessage: string(raw),
+		Error:      result.Error,
+	}, nil
+}
+
+func (c *ChainStrategy) RunCodeActionStream(ctx context.Context, sessionID, interactionID string, tool *types.Tool, history []*types.ToolHistoryMessage, action string) (*openai.ChatCompletionStream, error) {
+	result, err := c.runCode(ctx, sessionID, interactionID, tool, history)
+	if err != nil {
+		return nil, err
+	}
+
+	downstream, downstreamWriter, err := transport.NewOpenAIStreamingAdapter(openai.ChatCompletionRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create streaming adapter: %w", err)
+	}
+
+	go func() {
+		defer downstreamWriter.Close()
+
+		_ = transport.WriteChatCompletionStream(downstreamWriter, &openai.ChatCompletionStreamResponse{
+			Choices: []openai.ChatCompletionStreamChoice{
+				{
+					Delta: openai.ChatCompletionStreamChoiceDelta{
+						Content: formatCodeResult(result),
+					},
+				},
+			},
+		})
+	}()
+
+	return downstream, nil
+}
+
+// runCode asks the model to write the code for the last user message, runs it
+// in the sandbox and saves the produced files to the session
+func (c *ChainStrategy) runCode(ctx context.Context, sessionID, interactionID string, tool *types.Tool, history []*types.ToolHistoryMessage) (*types.CodeExecutionResponse, error) {
+	if tool.Config.Code == nil {
+		return nil, fmt.Errorf("code config is missing from the tool %s", tool.Name)
+	}
+
+	vals, ok := oai.GetContextValues(ctx)
+	if !ok {
+		vals = &oai.ContextValues{}
+	}
+
+	var (
+		files       []*types.CodeFile
+		sessionPath string
+	)
+
+	if tool.Config.Code.SessionFiles && c.filestore != nil && sessionID != "" && vals.OwnerID != "" {
+		sessionPath = path.Join(filestore.GetUserPrefix(c.cfg.Controller.FilePrefixGlobal, vals.OwnerID), "sessions", sessionID)
+
+		var err error
+		files, err = c.readSessionFiles(ctx, sessionPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read session files: %w", err)
+		}
+	}
+
+	language, code, err := c.generateCode(ctx, tool, history, files)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &types.CodeExecutionRequest{
+		Language:       language,
+		Code:           code,
+		TimeoutSeconds: tool.Config.Code.TimeoutSeconds,
+		MemoryMB:       tool.Config.Code.MemoryMB,
+		CPUs:           tool.Config.Code.CPUs,
+		Network:        tool.Config.Code.Network,
+		Files:          files,
+	}
+
+	log.Info().
+		Str("tool", tool.Name).
+		Str("session_id", sessionID).
+		Str("interaction_id", interactionID).
+		Str("language", string(language)).
+		Int("files", len(files)).
+		Msg("running code")
+
+	start := time.Now()
+
+	result, err := c.gptScriptExecutor.ExecuteCode(ctx, req)
+
+	c.saveScriptRun(vals, req, result, err, time.Since(start))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to run code: %w", err)
+	}
+
+	if sessionPath != "" {
+		for _, f := range result.Files {
+			if f.Content == nil {
+				continue
+			}
+			_, err := c.filestore.WriteFile(ctx, path.Join(sessionPath, f.Path), bytes.NewReader(f.Content))
+			if err != nil {
+				return nil, fmt.Errorf("failed to save %s to the session: %w", f.Path, err)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// readSessionFiles reads the files of the session, the files over the size
+// limit are skipped
+func (c *ChainStrategy) readSessionFiles(ctx context.Context, sessionPath string) ([]*types.CodeFile, error) {
+	var (
+		files []*types.CodeFile
+		total int64
+	)
+
+	var walk func(dir string) error
+	walk = func(dir string) error {
+		items, err := c.filestore.List(ctx, dir)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			if item.Directory {
+				if err := walk(item.Path); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if total+item.Size > maxSessionFilesBytes {
+				log.Warn().Str("path", item.Path).Msg("session files are over the size limit of the code tools, skipping file")
+				continue
+			}
+
+			r, err := c.filestore.OpenFile(ctx, item.Path)
+			if err != nil {
+				return err
+			}
+			content, err := io.ReadAll(r)
+			r.Close()
+			if err != nil {
+				return err
+			}
+
+			total += int64(len(content))
+			files = append(files, &types.CodeFile{
+				Path:    strings.TrimPrefix(strings.TrimPrefix(item.Path, sessionPath), "/"),
+				Size:    int64(len(content)),
+				Content: content,
+			})
+		}
+
+		return nil
+	}
+
+	if err := walk(sessionPath); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+func (c *ChainStrategy) generateCode(ctx context.Context, tool *types.Tool, history []*types.ToolHistoryMessage, files []*types.CodeFile) (types.CodeLanguage, string, error) {
+	languages := codeLanguages(tool.Config.Code)
+
+	var sb strings.Builder
+	err := codeSystemPromptTemplate.Execute(&sb, struct {
+		Description string
+		Languages   []types.CodeLanguage
+		Network     bool
+		Files       []*types.CodeFile
+	}{
+		Description: tool.Description,
+		Languages:   languages,
+		Network:     tool.Config.Code.Network,
+		Files:       files,
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to render code prompt: %w", err)
+	}
+
+	messages := []openai.ChatCompletionMessage{
+		{
+			Role:    openai.ChatMessageRoleSystem,
+			Content: sb.String(),
+		},
+	}
+
+	for _, msg := range history {
+		if msg.Role != openai.ChatMessageRoleSystem {
+			messages = append(messages, openai.ChatCompletionMessage{
+				Role:    msg.Role,
+				Content: msg.Content,
+			})
+		}
+	}
+
+	ctx = oai.SetStep(ctx, &oai.Step{
+		Step: types.LLMCallStepPrepareCode,
+	})
+
+	resp, err := c.apiClient.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model:    c.cfg.Tools.Model,
+		Messages: messages,
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get response from inference API: %w", err)
+	}
+
+	if len(resp.Choices) == 0 {
+		return "", "", fmt.Errorf("no response from inference API")
+	}
+
+	language, code := parseCodeBlock(resp.Choices[0].Message.Content, languages[0])
+	if code == "" {
+		return "", "", fmt.Errorf("model didn't write any code")
+	}
+
+	for _, allowed := range languages {
+		if language == allowed {
+			return language, code, nil
+		}
+	}
+
+	return "", "", fmt.Errorf("language %s is not allowed for the tool %s", language, tool.Name)
+}
+
+func codeLanguages(cfg *types.ToolCodeConfig) []types.CodeLanguage {
+	if len(cfg.Languages) == 0 {
+		return []types.CodeLanguage{types.CodeLanguagePython, types.CodeLanguageJavaScript}
+	}
+	return cfg.Languages
+}
+
+// parseCodeBlock returns the first fenced code block of the answer, the whole
+// answer is the code if there are no fences
+func parseCodeBlock(answer string, defaultLanguage types.CodeLanguage) (types.CodeLanguage, string) {
+	start := strings.Index(answer, "```")
+	if start < 0 {
+		return defaultLanguage, strings.TrimSpace(answer)
+	}
+
+	rest := answer[start+3:]
+
+	newline := strings.Index(rest, "\n")
+	if newline < 0 {
+		return defaultLanguage, ""
+	}
+
+	info := strings.ToLower(strings.TrimSpace(rest[:newline]))
+	body := rest[newline+1:]
+
+	if end := strings.Index(body, "```"); end >= 0 {
+		body = body[:end]
+	}
+
+	language := defaultLanguage
+	switch info {
+	case "python", "py", "python3":
+		language = types.CodeLanguagePython
+	case "javascript", "js", "node", "nodejs":
+		language = types.CodeLanguageJavaScript
+	}
+
+	return language, strings.TrimSpace(body)
+}
+
+// saveScriptRun stores the run without the content of the files
+func (c *ChainStrategy) saveScriptRun(vals *oai.ContextValues, req *types.CodeExecutionRequest, result *types.CodeExecutionResponse, runErr error, duration time.Duration) {
+	if c.store == nil {
+		return
+	}
+
+	storedReq := *req
+	storedReq.Files = withoutContent(req.Files)
+
+	run := &types.ScriptRun{
+		Owner:      vals.OwnerID,
+		OwnerType:  vals.OwnerType,
+		AppID:      vals.AppID,
+		State:      types.ScriptRunStateComplete,
+		Type:       types.GptScriptRunnerTaskTypeCode,
+		DurationMs: int(duration.Milliseconds()),
+		Request: &types.GptScriptRunnerRequest{
+			Code: &storedReq,
+		},
+	}
+
+	switch {
+	case runErr != nil:
+		run.State = types.ScriptRunStateError
+		run.SystemError = runErr.Error()
+	default:
+		if result.Error != "" {
+			run.State = types.ScriptRunStateError
+		}
+		run.Response = &types.GptScriptResponse{
+			Output: result.Stdout,
+			Error:  result.Error,
+			Code:   withoutFileContent(result),
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := c.store.CreateScriptRun(ctx, run)
+	if err != nil {
+		log.Err(err).Msg("failed to create script run")
+	}
+}
+
+func withoutFileContent(result *types.CodeExecutionResponse) *types.CodeExecutionResponse {
+	stored := *result
+	stored.Files = withoutContent(result.Files)
+	return &stored
+}
+
+func withoutContent(files []*types.CodeFile) []*types.CodeFile {
+	stripped := make([]*types.CodeFile, 0, len(files))
+	for _, f := range files {
+		stripped = append(stripped, &types.CodeFile{Path: f.Path, Size: f.Size})
+	}
+	return stripped
+}
+
+// formatCodeResult is the result of the code for the model and the user
+func formatCodeResult(result *types.CodeExecutionResponse) string {
+	var sb strings.Builder
+
+	if result.Error != "" {
+		fmt.Fprintf(&sb, "Failed to run the code: %s\n", result.Error)
+		return sb.String()
+	}
+
+	if result.TimedOut {
+		sb.WriteString("The code timed out.\n")
+	} else {
+		fmt.Fprintf(&sb, "Exit code: %d\n", result.ExitCode)
+	}
+
+	if result.Stdout != "" {
+		fmt.Fprintf(&sb, "\nStdout:\n```\n%s\n```\n", truncateOutput(result.Stdout))
+	}
+
+	if result.Stderr != "" {
+		fmt.Fprintf(&sb, "\nStderr:\n```\n%s\n```\n", truncateOutput(result.Stderr))
+	}
+
+	if len(result.Files) > 0 {
+		sb.WriteString("\nFiles:\n")
+		for _, f := range result.Files {
+			fmt.Fprintf(&sb, "- %s (%d bytes)\n", f.Path, f.Size)
+		}
+	}
+
+	if result.Truncated {
+		sb.WriteString("\nThe output or the files were truncated.\n")
+	}
+
+	return sb.String()
+}
+
+func truncateOutput(output string) string {
+	output = strings.TrimRight(output, "\n")
+	if len(output) <= maxCodeOutputMessage {
+		return output
+	}
+	return output[:maxCodeOutputMessage] + "\n..."
+}
+
+var codeSystemPromptTemplate = template.Must(template.New("code").Parse(codeSystemPrompt))
+
+const codeSystemPrompt = `You are an expert programmer. Write a single program that answers the last user message, it will be run and its output will be shown to the user.
+{{- if .Description }}
+
+The program is used for: {{ .Description }}
+{{- end }}
+
+Rules:
+- Use one of these languages: {{ range $i, $l := .Languages }}{{ if $i }}, {{ end }}{{ $l }}{{ end }}.
+- Only use the standard library of the language.
+- Print the results to stdout.
+- The working directory is a scratch directory, the files written there are returned to the user.
+{{- if .Network }}
+- The program can access the network.
+{{- else }}
+- The program has no network access.
+{{- end }}
+{{- if .Files }}
+
+The working directory contains these files:
+{{- range .Files }}
+- {{ .Path }} ({{ .Size }} bytes)
+{{- end }}
+{{- end }}
+
+Reply with the program in a single fenced code block tagged with its language, e.g. ` + "```python" + `, and nothing else.`
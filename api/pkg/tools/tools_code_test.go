@@ -0,0 +1,222 @@
+package tools
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/filestore"
+	"github.com/helixml/helix/api/pkg/gptscript"
+	oai "github.com/helixml/helix/api/pkg/openai"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func TestParseCodeBlock(t *testing.T) {
+	tests := []struct {
+		name     string
+		answer   string
+		language types.CodeLanguage
+		code     string
+	}{
+		{
+			name:     "python block",
+			answer:   "Here you go:\n```python\nprint(\"a\\nb\")\n```\nDone",
+			language: types.CodeLanguagePython,
+			code:     "print(\"a\\nb\")",
+		},
+		{
+			name:     "js block",
+			answer:   "```js\nconsole.log(1)\n```",
+			language: types.CodeLanguageJavaScript,
+			code:     "console.log(1)",
+		},
+		{
+			name:     "untagged block",
+			answer:   "```\nprint(1)\n```",
+			language: types.CodeLanguagePython,
+			code:     "print(1)",
+		},
+		{
+			name:     "no block",
+			answer:   "print(1)\n",
+			language: types.CodeLanguagePython,
+			code:     "print(1)",
+		},
+		{
+			name:     "unterminated block",
+			answer:   "```python\nprint(1)",
+			language: types.CodeLanguagePython,
+			code:     "print(1)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			language, code := parseCodeBlock(tt.answer, types.CodeLanguagePython)
+			assert.Equal(t, tt.language, language)
+			assert.Equal(t, tt.code, code)
+		})
+	}
+}
+
+func TestValidateTool_Code(t *testing.T) {
+	tool := &types.Tool{
+		Name:        "analyst",
+		Description: "Analyses data",
+		ToolType:    types.ToolTypeCode,
+		Config: types.ToolConfig{
+			Code: &types.ToolCodeConfig{},
+		},
+	}
+
+	require.NoError(t, ValidateTool(tool, nil, true))
+	assert.Equal(t, defaultCodeTimeoutSeconds, tool.Config.Code.TimeoutSeconds)
+	assert.Equal(t, defaultCodeMemoryMB, tool.Config.Code.MemoryMB)
+	assert.Equal(t, defaultCodeCPUs, tool.Config.Code.CPUs)
+
+	tool.Config.Code.TimeoutSeconds = maxCodeTimeoutSeconds + 1
+	assert.Error(t, ValidateTool(tool, nil, true))
+
+	tool.Config.Code.TimeoutSeconds = 10
+	tool.Config.Code.MemoryMB = maxCodeMemoryMB + 1
+	assert.Error(t, ValidateTool(tool, nil, true))
+
+	tool.Config.Code.MemoryMB = 128
+	tool.Config.Code.Languages = []types.CodeLanguage{"ruby"}
+	assert.Error(t, ValidateTool(tool, nil, true))
+
+	tool.Config.Code = nil
+	assert.Error(t, ValidateTool(tool, nil, true))
+}
+
+func TestRunCodeAction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	executor := gptscript.NewMockExecutor(ctrl)
+	apiClient := oai.NewMockClient(ctrl)
+	fs := filestore.NewMockFileStore(ctrl)
+	st := store.NewMockStore(ctrl)
+
+	cfg := &config.ServerConfig{}
+	cfg.Controller.FilePrefixGlobal = "dev"
+
+	strategy, err := NewChainStrategy(cfg, st, executor, fs, apiClient)
+	require.NoError(t, err)
+
+	ctx := oai.SetContextValues(context.Background(), &oai.ContextValues{
+		OwnerID:   "user_1",
+		OwnerType: types.OwnerTypeUser,
+		SessionID: "ses_1",
+	})
+
+	sessionPath := "dev/users/user_1/sessions/ses_1"
+
+	fs.EXPECT().List(gomock.Any(), sessionPath).Return([]filestore.FileStoreItem{
+		{Path: sessionPath + "/inputs", Directory: true},
+	}, nil)
+	fs.EXPECT().List(gomock.Any(), sessionPath+"/inputs").Return([]filestore.FileStoreItem{
+		{Path: sessionPath + "/inputs/data.csv", Size: 3},
+	}, nil)
+	fs.EXPECT().OpenFile(gomock.Any(), sessionPath+"/inputs/data.csv").Return(io.NopCloser(strings.NewReader("a,b")), nil)
+
+	apiClient.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+			assert.Contains(t, req.Messages[0].Content, "inputs/data.csv (3 bytes)")
+			assert.Contains(t, req.Messages[0].Content, "no network access")
+			assert.Equal(t, "sum the columns", req.Messages[1].Content)
+
+			return openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{
+					{Message: openai.ChatCompletionMessage{Content: "```python\nprint(3)\n```"}},
+				},
+			}, nil
+		})
+
+	executor.EXPECT().ExecuteCode(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, req *types.CodeExecutionRequest) (*types.CodeExecutionResponse, error) {
+			assert.Equal(t, types.CodeLanguagePython, req.Language)
+			assert.Equal(t, "print(3)", req.Code)
+			assert.Equal(t, 10, req.TimeoutSeconds)
+			require.Len(t, req.Files, 1)
+			assert.Equal(t, "inputs/data.csv", req.Files[0].Path)
+			assert.Equal(t, "a,b", string(req.Files[0].Content))
+
+			return &types.CodeExecutionResponse{
+				Stdout:   "3\n",
+				ExitCode: 0,
+				Files:    []*types.CodeFile{{Path: "out.txt", Size: 1, Content: []byte("3")}},
+			}, nil
+		})
+
+	st.EXPECT().CreateScriptRun(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, run *types.ScriptRun) (*types.ScriptRun, error) {
+			assert.Equal(t, "user_1", run.Owner)
+			assert.Equal(t, types.GptScriptRunnerTaskTypeCode, run.Type)
+			assert.Equal(t, types.ScriptRunStateComplete, run.State)
+			assert.Nil(t, run.Request.Code.Files[0].Content)
+			assert.Nil(t, run.Response.Code.Files[0].Content)
+			return run, nil
+		})
+
+	fs.EXPECT().WriteFile(gomock.Any(), sessionPath+"/out.txt", gomock.Any()).Return(filestore.FileStoreItem{}, nil)
+
+	tool := &types.Tool{
+		Name:     "analyst",
+		ToolType: types.ToolTypeCode,
+		Config: types.ToolConfig{
+			Code: &types.ToolCodeConfig{
+				Languages:      []types.CodeLanguage{types.CodeLanguagePython},
+				TimeoutSeconds: 10,
+				SessionFiles:   true,
+			},
+		},
+	}
+
+	history := []*types.ToolHistoryMessage{
+		{Role: openai.ChatMessageRoleUser, Content: "sum the columns"},
+	}
+
+	resp, err := strategy.RunAction(ctx, "ses_1", "int_1", tool, history, "analyst")
+	require.NoError(t, err)
+
+	assert.Contains(t, resp.Message, "Exit code: 0")
+	assert.Contains(t, resp.Message, "```\n3\n```")
+	assert.Contains(t, resp.Message, "- out.txt (1 bytes)")
+	assert.NotContains(t, resp.RawMessage, "content")
+}
+
+func TestRunCodeAction_LanguageNotAllowed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	apiClient := oai.NewMockClient(ctrl)
+
+	strategy, err := NewChainStrategy(&config.ServerConfig{}, nil, gptscript.NewMockExecutor(ctrl), nil, apiClient)
+	require.NoError(t, err)
+
+	apiClient.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).Return(openai.ChatCompletionResponse{
+		Choices: []openai.ChatCompletionChoice{
+			{Message: openai.ChatCompletionMessage{Content: "```javascript\nconsole.log(1)\n```"}},
+		},
+	}, nil)
+
+	tool := &types.Tool{
+		Name:     "analyst",
+		ToolType: types.ToolTypeCode,
+		Config: types.ToolConfig{
+			Code: &types.ToolCodeConfig{
+				Languages: []types.CodeLanguage{types.CodeLanguagePython},
+			},
+		},
+	}
+
+	_, err = strategy.RunAction(context.Background(), "ses_1", "int_1", tool, nil, "analyst")
+	assert.ErrorContains(t, err, "language javascript is not allowed")
+}
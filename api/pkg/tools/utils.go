@@ -3,6 +3,8 @@ package tools
 import (
 	"encoding/json"
 	"strings"
+
+	"github.com/helixml/helix/api/pkg/types"
 )
 
 func AttemptFixJSON(data string) string {
@@ -30,3 +32,31 @@ func unmarshalJSON(data string, v interface{}) error {
 	fixedData := AttemptFixJSON(data)
 	return json.Unmarshal([]byte(fixedData), v)
 }
+
+// GetToolFromAction returns the tool that can run the action, API tools are
+// matched by the action name, other tools by the tool name
+func GetToolFromAction(tools []*types.Tool, action string) (*types.Tool, bool) {
+	for _, tool := range tools {
+		switch tool.ToolType {
+		case types.ToolTypeAPI:
+			for _, a := range tool.Config.API.Actions {
+				if a.Name == action {
+					return tool, true
+				}
+			}
+		case types.ToolTypeGPTScript:
+			if tool.Name == action {
+				return tool, true
+			}
+		case types.ToolTypeZapier:
+			if tool.Name == action {
+				return tool, true
+			}
+		case types.ToolTypeCode:
+			if tool.Name == action {
+				return tool, true
+			}
+		}
+	}
+	return nil, false
+}
@@ -126,9 +126,55 @@ func ValidateTool(tool *types.Tool, planner Planner, strict bool) error {
 		if tool.Config.Zapier.APIKey == "" {
 			return system.NewHTTPError400("API key is required for Zapier tools")
 		}
+	case types.ToolTypeCode:
+		if tool.Config.Code == nil {
+			return system.NewHTTPError400("code config is required for code tools")
+		}
+
+		if tool.Description == "" && strict {
+			return system.NewHTTPError400("description is required for code tools, describe what the code is used for")
+		}
+
+		err := validateAndDefaultCode(tool.Config.Code)
+		if err != nil {
+			return system.NewHTTPError400(err.Error())
+		}
 	default:
 		return system.NewHTTPError400("invalid tool type %s, only API tools are supported at the moment", tool.ToolType)
 	}
 
 	return nil
 }
+
+func validateAndDefaultCode(cfg *types.ToolCodeConfig) error {
+	for _, language := range cfg.Languages {
+		switch language {
+		case types.CodeLanguagePython, types.CodeLanguageJavaScript:
+		default:
+			return fmt.Errorf("unsupported language %s for code tools, use %s or %s", language, types.CodeLanguagePython, types.CodeLanguageJavaScript)
+		}
+	}
+
+	if cfg.TimeoutSeconds == 0 {
+		cfg.TimeoutSeconds = defaultCodeTimeoutSeconds
+	}
+	if cfg.TimeoutSeconds < 0 || cfg.TimeoutSeconds > maxCodeTimeoutSeconds {
+		return fmt.Errorf("timeout of code tools must be between 1 and %d seconds", maxCodeTimeoutSeconds)
+	}
+
+	if cfg.MemoryMB == 0 {
+		cfg.MemoryMB = defaultCodeMemoryMB
+	}
+	if cfg.MemoryMB < 0 || cfg.MemoryMB > maxCodeMemoryMB {
+		return fmt.Errorf("memory of code tools must be between 1 and %d MB", maxCodeMemoryMB)
+	}
+
+	if cfg.CPUs == 0 {
+		cfg.CPUs = defaultCodeCPUs
+	}
+	if cfg.CPUs < 0 || cfg.CPUs > maxCodeCPUs {
+		return fmt.Errorf("CPUs of code tools must be between 0 and %d", maxCodeCPUs)
+	}
+
+	return nil
+}
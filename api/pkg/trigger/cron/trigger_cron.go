@@ -3,6 +3,7 @@ package cron
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strings"
 	"sync"
 	"time"
@@ -10,11 +11,11 @@ import (
 	"github.com/go-co-op/gocron/v2"
 	cronv3 "github.com/robfig/cron/v3"
 	"github.com/rs/zerolog/log"
-	openai "github.com/sashabaranov/go-openai"
 
 	"github.com/helixml/helix/api/pkg/config"
 	"github.com/helixml/helix/api/pkg/controller"
 	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/trigger/execution"
 	"github.com/helixml/helix/api/pkg/types"
 )
 
@@ -22,7 +23,9 @@ type Cron struct {
 	cfg        *config.ServerConfig
 	store      store.Store
 	controller *controller.Controller
+	runner     *execution.Runner
 	cron       gocron.Scheduler
+	httpClient *http.Client
 }
 
 func New(cfg *config.ServerConfig, store store.Store, controller *controller.Controller) (*Cron, error) {
@@ -35,7 +38,11 @@ func New(cfg *config.ServerConfig, store store.Store, controller *controller.Con
 		cfg:        cfg,
 		store:      store,
 		controller: controller,
+		runner:     execution.NewRunner(store, controller),
 		cron:       s,
+		httpClient: &http.Client{
+			Timeout: webhookTimeout,
+		},
 	}, nil
 }
 
@@ -242,22 +249,7 @@ func (c *Cron) getCronAppTask(ctx context.Context, appID string) gocron.Task {
 			return
 		}
 
-		messages := []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleUser,
-				Content: trigger.Input,
-			},
-		}
-
-		resp, _, err := c.controller.ChatCompletion(ctx, &types.User{
-			ID: app.Owner,
-		}, openai.ChatCompletionRequest{
-			Stream:   false,
-			Messages: messages,
-		},
-			&controller.ChatCompletionOptions{
-				AppID: app.ID,
-			})
+		execution, err := c.runApp(ctx, app, trigger)
 		if err != nil {
 			log.Error().
 				Err(err).
@@ -266,18 +258,49 @@ func (c *Cron) getCronAppTask(ctx context.Context, appID string) gocron.Task {
 			return
 		}
 
-		var respContent string
-		if len(resp.Choices) > 0 {
-			respContent = resp.Choices[0].Message.Content
-		}
-
 		log.Info().
 			Str("app_id", app.ID).
-			Str("resp_content", respContent).
+			Str("execution_id", execution.ID).
+			Str("session_id", execution.SessionID).
+			Str("status", string(execution.Status)).
+			Int("attempts", execution.Attempts).
 			Msg("app cron job completed")
 	})
 }
 
+// runApp runs the app with the trigger input, the conversation is stored in a
+// new session and the response is delivered to the trigger outputs. Failures of
+// the run are recorded in the execution, the error is only returned if the
+// execution couldn't be recorded.
+func (c *Cron) runApp(ctx context.Context, app *types.App, trigger *types.CronTrigger) (*types.TriggerExecution, error) {
+	started := time.Now()
+
+	execution, session, err := c.runner.Start(ctx, app, types.TriggerTypeCron, trigger.Input)
+	if err != nil {
+		return nil, err
+	}
+
+	c.runner.Run(ctx, app, execution, session, retryOptions(ctx, trigger)...)
+
+	if execution.Status == types.TriggerExecutionStatusSuccess {
+		err = c.deliverOutputs(ctx, app, trigger, &outputData{
+			AppID:       app.ID,
+			AppName:     app.Config.Helix.Name,
+			ExecutionID: execution.ID,
+			SessionID:   session.ID,
+			SessionURL:  fmt.Sprintf("%s/session/%s", c.cfg.Notifications.AppURL, session.ID),
+			Input:       trigger.Input,
+			Output:      execution.Output,
+		}, session)
+		if err != nil {
+			execution.Status = types.TriggerExecutionStatusError
+			execution.Error = err.Error()
+		}
+	}
+
+	return c.runner.Finish(ctx, execution, started)
+}
+
 func (c *Cron) listApps(ctx context.Context) ([]*types.App, error) {
 	apps, err := c.store.ListApps(ctx, &store.ListAppsQuery{})
 	if err != nil {
@@ -310,6 +333,8 @@ func (c *Cron) getCronAppOptions(app *types.App) []gocron.JobOption {
 	return []gocron.JobOption{
 		gocron.WithName(app.ID),
 		gocron.WithTags(fmt.Sprintf("schedule:%s", schedule)),
+		// Retries can outlast the schedule, skip the runs that overlap
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
 	}
 }
 
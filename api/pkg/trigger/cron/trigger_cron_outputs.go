@@ -0,0 +1,242 @@
+package cron
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"text/template"
+	"time"
+
+	"github.com/avast/retry-go/v4"
+	"github.com/rs/zerolog/log"
+
+	"github.com/helixml/helix/api/pkg/notification"
+	"github.com/helixml/helix/api/pkg/trigger/discord"
+	"github.com/helixml/helix/api/pkg/trigger/execution"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+const (
+	webhookTimeout = 30 * time.Second
+	// defaultBackoff is used when the retry policy doesn't set the backoff
+	defaultBackoff = 30 * time.Second
+)
+
+// outputData is passed to the webhook body template, the webhook
+// receives it as JSON when the body isn't set
+type outputData struct {
+	AppID       string `json:"app_id"`
+	AppName     string `json:"app_name"`
+	ExecutionID string `json:"execution_id"`
+	SessionID   string `json:"session_id"`
+	SessionURL  string `json:"session_url"`
+	Input       string `json:"input"`
+	Output      string `json:"output"`
+}
+
+// deliverOutputs sends the response to every output of the trigger, each
+// output is retried separately so one failing output doesn't resend the others
+func (c *Cron) deliverOutputs(ctx context.Context, app *types.App, trigger *types.CronTrigger, run *outputData, session *types.Session) error {
+	var errs []error
+
+	for idx, output := range trigger.Outputs {
+		var err error
+		if output.Email != nil {
+			// Retried per recipient so the ones that got it aren't sent it again
+			err = c.deliverEmail(ctx, output.Email, run, session, retryOptions(ctx, trigger))
+		} else {
+			err = retry.Do(func() error {
+				return c.deliverOutput(ctx, output, run)
+			}, retryOptions(ctx, trigger)...)
+		}
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("app_id", app.ID).
+				Int("output", idx).
+				Msg("failed to deliver cron trigger output")
+
+			errs = append(errs, fmt.Errorf("output %d: %w", idx, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Cron) deliverOutput(ctx context.Context, output types.TriggerOutput, run *outputData) error {
+	switch {
+	case output.Webhook != nil:
+		return c.deliverWebhook(ctx, output.Webhook, run)
+	case output.Discord != nil:
+		// Only admins can add Discord channels to the app, see the app handlers
+		return discord.SendMessage(c.cfg, output.Discord.ChannelID, run.Output)
+	default:
+		return retry.Unrecoverable(fmt.Errorf("output type not specified"))
+	}
+}
+
+func (c *Cron) deliverWebhook(ctx context.Context, webhook *types.WebhookTriggerOutput, run *outputData) error {
+	body, err := renderWebhookBody(webhook, run)
+	if err != nil {
+		return retry.Unrecoverable(err)
+	}
+
+	method := webhook.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return retry.Unrecoverable(fmt.Errorf("failed to create request: %w", err))
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range webhook.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return nil
+}
+
+func renderWebhookBody(webhook *types.WebhookTriggerOutput, run *outputData) ([]byte, error) {
+	if webhook.Body == "" {
+		return json.Marshal(run)
+	}
+
+	tmpl, err := parseBodyTemplate(webhook.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, run)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render webhook body: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func parseBodyTemplate(body string) (*template.Template, error) {
+	tmpl, err := template.New("body").Funcs(execution.TemplateFuncs).Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse webhook body template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// deliverEmail sends the response to each recipient, the recipients are
+// restricted to the owner of the app when the app is saved
+func (c *Cron) deliverEmail(ctx context.Context, email *types.EmailTriggerOutput, run *outputData, session *types.Session, opts []retry.Option) error {
+	notifier := c.controller.Options.Notifier
+	if notifier == nil {
+		return fmt.Errorf("notifications are not configured")
+	}
+
+	var errs []error
+
+	for _, to := range email.To {
+		err := retry.Do(func() error {
+			return notifier.Notify(ctx, &notification.Notification{
+				Event:   notification.EventTriggerComplete,
+				Session: session,
+				Message: run.Output,
+				Email:   to,
+			})
+		}, opts...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("recipient %s: %w", to, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func retryOptions(ctx context.Context, trigger *types.CronTrigger) []retry.Option {
+	attempts := 1
+	backoff := defaultBackoff
+
+	if trigger.Retry != nil {
+		if trigger.Retry.MaxAttempts > 1 {
+			attempts = trigger.Retry.MaxAttempts
+		}
+
+		if trigger.Retry.Backoff != "" {
+			// Validated when the app is saved
+			d, err := time.ParseDuration(trigger.Retry.Backoff)
+			if err == nil {
+				backoff = d
+			}
+		}
+	}
+
+	return []retry.Option{
+		retry.Attempts(uint(attempts)),
+		retry.Delay(backoff),
+		retry.DelayType(retry.BackOffDelay),
+		retry.Context(ctx),
+		retry.LastErrorOnly(true),
+	}
+}
+
+// ValidateTrigger checks the outputs and the retry policy of the cron trigger
+func ValidateTrigger(trigger *types.CronTrigger) error {
+	if trigger.Retry != nil {
+		if trigger.Retry.MaxAttempts < 0 {
+			return fmt.Errorf("retry max attempts must not be negative")
+		}
+
+		if trigger.Retry.Backoff != "" {
+			_, err := time.ParseDuration(trigger.Retry.Backoff)
+			if err != nil {
+				return fmt.Errorf("invalid retry backoff: %w", err)
+			}
+		}
+	}
+
+	for idx, output := range trigger.Outputs {
+		switch {
+		case output.Webhook != nil:
+			u, err := url.Parse(output.Webhook.URL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("output %d: invalid webhook URL '%s'", idx, output.Webhook.URL)
+			}
+
+			if output.Webhook.Body != "" {
+				_, err := parseBodyTemplate(output.Webhook.Body)
+				if err != nil {
+					return fmt.Errorf("output %d: %w", idx, err)
+				}
+			}
+		case output.Email != nil:
+			if len(output.Email.To) == 0 {
+				return fmt.Errorf("output %d: email recipients not specified", idx)
+			}
+		case output.Discord != nil:
+			if output.Discord.ChannelID == "" {
+				return fmt.Errorf("output %d: discord channel ID not specified", idx)
+			}
+		default:
+			return fmt.Errorf("output %d: output type not specified", idx)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,209 @@
+package cron
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/controller"
+	"github.com/helixml/helix/api/pkg/notification"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func TestDeliverOutputs_WebhookTemplate(t *testing.T) {
+	var received map[string]string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "secret", r.Header.Get("X-Token"))
+
+		bts, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(bts, &received))
+	}))
+	defer srv.Close()
+
+	c := &Cron{cfg: &config.ServerConfig{}, httpClient: srv.Client()}
+
+	trigger := &types.CronTrigger{
+		Outputs: []types.TriggerOutput{
+			{
+				Webhook: &types.WebhookTriggerOutput{
+					URL:     srv.URL,
+					Method:  http.MethodPut,
+					Headers: map[string]string{"X-Token": "secret"},
+					Body:    `{"text": {{ json .Output }}, "app": {{ json .AppName }}}`,
+				},
+			},
+		},
+	}
+
+	err := c.deliverOutputs(context.Background(), &types.App{ID: "app_1"}, trigger, &outputData{
+		AppName: "tickets",
+		Output:  "3 tickets \"closed\"\nall good",
+	}, &types.Session{})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"text": "3 tickets \"closed\"\nall good",
+		"app":  "tickets",
+	}, received)
+}
+
+func TestDeliverOutputs_WebhookRetries(t *testing.T) {
+	var calls, failures int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		var run outputData
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&run))
+		assert.Equal(t, "ses_1", run.SessionID)
+	}))
+	defer srv.Close()
+
+	c := &Cron{cfg: &config.ServerConfig{}, httpClient: srv.Client()}
+
+	trigger := &types.CronTrigger{
+		Outputs: []types.TriggerOutput{
+			{Webhook: &types.WebhookTriggerOutput{URL: srv.URL}},
+		},
+		Retry: &types.TriggerRetry{
+			MaxAttempts: 3,
+			Backoff:     "1ms",
+		},
+	}
+
+	// Succeeds on the last attempt
+	failures = 2
+	err := c.deliverOutputs(context.Background(), &types.App{ID: "app_1"}, trigger, &outputData{
+		SessionID: "ses_1",
+	}, &types.Session{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	// Out of attempts
+	calls, failures = 0, 3
+	err = c.deliverOutputs(context.Background(), &types.App{ID: "app_1"}, trigger, &outputData{}, &types.Session{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "webhook returned status 503")
+}
+
+type failingNotifier struct {
+	failures map[string]int
+	sent     map[string]int
+}
+
+func (n *failingNotifier) Notify(_ context.Context, notification *notification.Notification) error {
+	if n.failures[notification.Email] > 0 {
+		n.failures[notification.Email]--
+		return fmt.Errorf("failed to send to %s", notification.Email)
+	}
+	n.sent[notification.Email]++
+	return nil
+}
+
+func TestDeliverOutputs_EmailRetriesPerRecipient(t *testing.T) {
+	notifier := &failingNotifier{
+		failures: map[string]int{"b@example.com": 2},
+		sent:     map[string]int{},
+	}
+
+	c := &Cron{
+		cfg:        &config.ServerConfig{},
+		controller: &controller.Controller{Options: controller.ControllerOptions{Notifier: notifier}},
+	}
+
+	trigger := &types.CronTrigger{
+		Outputs: []types.TriggerOutput{
+			{Email: &types.EmailTriggerOutput{To: []string{"a@example.com", "b@example.com"}}},
+		},
+		Retry: &types.TriggerRetry{
+			MaxAttempts: 3,
+			Backoff:     "1ms",
+		},
+	}
+
+	err := c.deliverOutputs(context.Background(), &types.App{ID: "app_1"}, trigger, &outputData{}, &types.Session{})
+	require.NoError(t, err)
+
+	// The first recipient isn't sent the email again when the second is retried
+	assert.Equal(t, map[string]int{"a@example.com": 1, "b@example.com": 1}, notifier.sent)
+}
+
+func TestValidateTrigger(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		trigger *types.CronTrigger
+		wantErr string
+	}{
+		{
+			name: "valid",
+			trigger: &types.CronTrigger{
+				Outputs: []types.TriggerOutput{
+					{Webhook: &types.WebhookTriggerOutput{URL: "https://example.com/hook", Body: `{"text": {{ json .Output }}}`}},
+					{Email: &types.EmailTriggerOutput{To: []string{"ops@example.com"}}},
+					{Discord: &types.DiscordTriggerOutput{ChannelID: "123"}},
+				},
+				Retry: &types.TriggerRetry{MaxAttempts: 3, Backoff: "1m"},
+			},
+		},
+		{
+			name: "invalid webhook URL",
+			trigger: &types.CronTrigger{
+				Outputs: []types.TriggerOutput{{Webhook: &types.WebhookTriggerOutput{URL: "example.com"}}},
+			},
+			wantErr: "invalid webhook URL",
+		},
+		{
+			name: "invalid body template",
+			trigger: &types.CronTrigger{
+				Outputs: []types.TriggerOutput{{Webhook: &types.WebhookTriggerOutput{URL: "https://example.com", Body: "{{ .Output "}}},
+			},
+			wantErr: "failed to parse webhook body template",
+		},
+		{
+			name: "no email recipients",
+			trigger: &types.CronTrigger{
+				Outputs: []types.TriggerOutput{{Email: &types.EmailTriggerOutput{}}},
+			},
+			wantErr: "email recipients not specified",
+		},
+		{
+			name: "empty output",
+			trigger: &types.CronTrigger{
+				Outputs: []types.TriggerOutput{{}},
+			},
+			wantErr: "output type not specified",
+		},
+		{
+			name: "invalid backoff",
+			trigger: &types.CronTrigger{
+				Retry: &types.TriggerRetry{Backoff: "soon"},
+			},
+			wantErr: "invalid retry backoff",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateTrigger(tc.trigger)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
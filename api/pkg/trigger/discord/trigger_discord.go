@@ -24,6 +24,8 @@ const (
 	installationDocsURL = "https://docs.helix.ml/helix/"
 	// history limit
 	historyLimit = 30
+	// Discord rejects longer messages
+	maxMessageLength = 2000
 )
 
 type Discord struct {
@@ -84,6 +86,41 @@ func (d *Discord) Start(ctx context.Context) error {
 	return nil
 }
 
+// SendMessage posts the message to the channel as the Discord bot. The bot is
+// shared by all the apps, the channel must have been approved by an admin when
+// the app was saved. Long messages are split into several messages.
+func SendMessage(cfg *config.ServerConfig, channelID, message string) error {
+	if cfg.Triggers.Discord.BotToken == "" {
+		return fmt.Errorf("discord bot token is not configured")
+	}
+
+	s, err := discordgo.New("Bot " + cfg.Triggers.Discord.BotToken)
+	if err != nil {
+		return fmt.Errorf("failed to create discord session: %w", err)
+	}
+
+	for _, part := range splitMessage(message, maxMessageLength) {
+		_, err = s.ChannelMessageSend(channelID, part)
+		if err != nil {
+			return fmt.Errorf("failed to send message to channel %s: %w", channelID, err)
+		}
+	}
+
+	return nil
+}
+
+func splitMessage(message string, limit int) []string {
+	var parts []string
+
+	runes := []rune(message)
+	for len(runes) > limit {
+		parts = append(parts, string(runes[:limit]))
+		runes = runes[limit:]
+	}
+
+	return append(parts, string(runes))
+}
+
 func (d *Discord) syncApps(ctx context.Context) error {
 	ticker := time.NewTicker(10 * time.Second)
 	defer ticker.Stop()
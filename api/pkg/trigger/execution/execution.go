@@ -0,0 +1,215 @@
+// Package execution runs the apps for the cron and webhook triggers, every run
+// is stored in a new session and recorded as a trigger execution
+package execution
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/avast/retry-go/v4"
+	"github.com/rs/zerolog/log"
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/helixml/helix/api/pkg/controller"
+	"github.com/helixml/helix/api/pkg/data"
+	oai "github.com/helixml/helix/api/pkg/openai"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+// TemplateFuncs are available in the prompt and the output templates of the triggers
+var TemplateFuncs = template.FuncMap{
+	// json quotes the value so it can be embedded in a JSON body or nested
+	// objects can be embedded in the prompt
+	"json": func(v interface{}) (string, error) {
+		bts, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(bts), nil
+	},
+}
+
+type Runner struct {
+	store      store.Store
+	controller *controller.Controller
+}
+
+func NewRunner(store store.Store, controller *controller.Controller) *Runner {
+	return &Runner{
+		store:      store,
+		controller: controller,
+	}
+}
+
+// Start stores the session of the run with the input and the placeholder for
+// the response, and records the running execution
+func (r *Runner) Start(ctx context.Context, app *types.App, trigger types.TriggerType, input string) (*types.TriggerExecution, *types.Session, error) {
+	session, err := r.createSession(ctx, app, input)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	execution, err := r.store.CreateTriggerExecution(ctx, &types.TriggerExecution{
+		AppID:     app.ID,
+		Trigger:   trigger,
+		SessionID: session.ID,
+		Input:     input,
+		Status:    types.TriggerExecutionStatusRunning,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create trigger execution: %w", err)
+	}
+
+	return execution, session, nil
+}
+
+// Run asks the app for the response to the input of the session, a single
+// attempt is made unless the retry options say otherwise. The response or the
+// error is stored in the session and set on the execution, the execution is
+// only saved by Finish.
+func (r *Runner) Run(ctx context.Context, app *types.App, execution *types.TriggerExecution, session *types.Session, retryOptions ...retry.Option) {
+	options := append([]retry.Option{
+		retry.Attempts(1),
+		retry.Context(ctx),
+		retry.LastErrorOnly(true),
+	}, retryOptions...)
+
+	var respContent string
+
+	err := retry.Do(func() error {
+		execution.Attempts++
+
+		var err error
+		respContent, err = r.chatCompletion(ctx, app, session)
+		return err
+	}, options...)
+
+	assistantInteraction := session.Interactions[len(session.Interactions)-1]
+	assistantInteraction.Completed = time.Now()
+	assistantInteraction.Finished = true
+
+	if err != nil {
+		assistantInteraction.State = types.InteractionStateError
+		assistantInteraction.Error = err.Error()
+
+		execution.Status = types.TriggerExecutionStatusError
+		execution.Error = err.Error()
+	} else {
+		assistantInteraction.State = types.InteractionStateComplete
+		assistantInteraction.Message = respContent
+
+		execution.Status = types.TriggerExecutionStatusSuccess
+		execution.Output = respContent
+	}
+
+	_, err = r.store.UpdateInteraction(ctx, assistantInteraction)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("app_id", app.ID).
+			Str("session_id", session.ID).
+			Msg("failed to update interaction")
+	}
+}
+
+// Finish saves the execution with the duration of the run
+func (r *Runner) Finish(ctx context.Context, execution *types.TriggerExecution, started time.Time) (*types.TriggerExecution, error) {
+	execution.DurationMs = int(time.Since(started).Milliseconds())
+
+	return r.store.UpdateTriggerExecution(ctx, execution)
+}
+
+func (r *Runner) createSession(ctx context.Context, app *types.App, input string) (*types.Session, error) {
+	var modelName string
+	if assistant := data.GetAssistant(app, ""); assistant != nil {
+		modelName = assistant.Model
+	}
+
+	name := app.Config.Helix.Name
+	if name == "" {
+		name = app.ID
+	}
+
+	now := time.Now()
+
+	return r.store.CreateSession(ctx, types.Session{
+		ID:        system.GenerateSessionID(),
+		Name:      name,
+		Created:   now,
+		Updated:   now,
+		Mode:      types.SessionModeInference,
+		Type:      types.SessionTypeText,
+		ModelName: modelName,
+		ParentApp: app.ID,
+		Owner:     app.Owner,
+		OwnerType: app.OwnerType,
+		Metadata: types.SessionMetadata{
+			Origin: types.SessionOrigin{
+				Type: types.SessionOriginTypeTrigger,
+			},
+			HelixVersion: data.GetHelixVersion(),
+		},
+		Interactions: []*types.Interaction{
+			{
+				ID:        system.GenerateUUID(),
+				Created:   now,
+				Updated:   now,
+				Scheduled: now,
+				Completed: now,
+				Mode:      types.SessionModeInference,
+				Creator:   types.CreatorTypeUser,
+				State:     types.InteractionStateComplete,
+				Finished:  true,
+				Message:   input,
+			},
+			{
+				ID:       system.GenerateUUID(),
+				Created:  now,
+				Updated:  now,
+				Mode:     types.SessionModeInference,
+				Creator:  types.CreatorTypeAssistant,
+				State:    types.InteractionStateWaiting,
+				Metadata: map[string]string{},
+			},
+		},
+	})
+}
+
+func (r *Runner) chatCompletion(ctx context.Context, app *types.App, session *types.Session) (string, error) {
+	// LLM calls are logged against the session of the run
+	ctx = oai.SetContextValues(ctx, &oai.ContextValues{
+		OwnerID:       app.Owner,
+		SessionID:     session.ID,
+		InteractionID: session.Interactions[len(session.Interactions)-1].ID,
+	})
+
+	resp, _, err := r.controller.ChatCompletion(ctx, &types.User{
+		ID:   app.Owner,
+		Type: app.OwnerType,
+	}, openai.ChatCompletionRequest{
+		Stream: false,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: session.Interactions[0].Message,
+			},
+		},
+	},
+		&controller.ChatCompletionOptions{
+			AppID: app.ID,
+		})
+	if err != nil {
+		return "", err
+	}
+
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("no choices in response")
+	}
+
+	return resp.Choices[0].Message.Content, nil
+}
@@ -0,0 +1,119 @@
+package execution
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/avast/retry-go/v4"
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/controller"
+	"github.com/helixml/helix/api/pkg/extract"
+	"github.com/helixml/helix/api/pkg/filestore"
+	"github.com/helixml/helix/api/pkg/janitor"
+	oai "github.com/helixml/helix/api/pkg/openai"
+	"github.com/helixml/helix/api/pkg/openai/manager"
+	"github.com/helixml/helix/api/pkg/scheduler"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func newTestRunner(t *testing.T) (*Runner, *store.MockStore, *oai.MockClient) {
+	ctrl := gomock.NewController(t)
+
+	storeMock := store.NewMockStore(ctrl)
+	openAiClient := oai.NewMockClient(ctrl)
+
+	cfg := &config.ServerConfig{}
+	cfg.Tools.Enabled = false
+	cfg.Inference.Provider = types.ProviderTogetherAI
+
+	providerManager := manager.NewMockProviderManager(ctrl)
+	providerManager.EXPECT().GetClient(gomock.Any(), gomock.Any()).Return(openAiClient, nil).AnyTimes()
+
+	c, err := controller.NewController(context.Background(), controller.ControllerOptions{
+		Config:          cfg,
+		Store:           storeMock,
+		Janitor:         janitor.NewJanitor(config.Janitor{}),
+		ProviderManager: providerManager,
+		Filestore:       filestore.NewMockFileStore(ctrl),
+		Extractor:       extract.NewMockExtractor(ctrl),
+		Scheduler:       scheduler.NewScheduler(cfg),
+	})
+	require.NoError(t, err)
+
+	return NewRunner(storeMock, c), storeMock, openAiClient
+}
+
+func TestRunner_RetriesCompletion(t *testing.T) {
+	r, storeMock, openAiClient := newTestRunner(t)
+
+	app := &types.App{
+		ID:        "app_1",
+		Owner:     "user_1",
+		OwnerType: types.OwnerTypeUser,
+		Config: types.AppConfig{
+			Helix: types.AppHelixConfig{
+				Assistants: []types.AssistantConfig{{}},
+			},
+		},
+	}
+
+	storeMock.EXPECT().GetApp(gomock.Any(), "app_1").Return(app, nil).AnyTimes()
+	storeMock.EXPECT().CreateSession(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, session types.Session) (*types.Session, error) {
+			return &session, nil
+		})
+	storeMock.EXPECT().CreateTriggerExecution(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, execution *types.TriggerExecution) (*types.TriggerExecution, error) {
+			assert.Equal(t, types.TriggerTypeCron, execution.Trigger)
+			assert.Equal(t, types.TriggerExecutionStatusRunning, execution.Status)
+			execution.ID = system.GenerateTriggerExecutionID()
+			return execution, nil
+		})
+
+	gomock.InOrder(
+		openAiClient.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).
+			Return(openai.ChatCompletionResponse{}, errors.New("provider unavailable")),
+		openAiClient.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).
+			Return(openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{
+					{Message: openai.ChatCompletionMessage{Content: "All good."}},
+				},
+			}, nil),
+	)
+
+	storeMock.EXPECT().UpdateInteraction(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, interaction *types.Interaction) (*types.Interaction, error) {
+			assert.Equal(t, types.InteractionStateComplete, interaction.State)
+			assert.Equal(t, "All good.", interaction.Message)
+			return interaction, nil
+		})
+	storeMock.EXPECT().UpdateTriggerExecution(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, execution *types.TriggerExecution) (*types.TriggerExecution, error) {
+			return execution, nil
+		})
+
+	started := time.Now()
+
+	execution, session, err := r.Start(context.Background(), app, types.TriggerTypeCron, "check the tickets")
+	require.NoError(t, err)
+	assert.Equal(t, session.ID, execution.SessionID)
+	assert.Equal(t, "check the tickets", session.Interactions[0].Message)
+
+	r.Run(context.Background(), app, execution, session, retry.Attempts(2), retry.Delay(time.Millisecond))
+
+	execution, err = r.Finish(context.Background(), execution, started)
+	require.NoError(t, err)
+
+	assert.Equal(t, types.TriggerExecutionStatusSuccess, execution.Status)
+	assert.Equal(t, "All good.", execution.Output)
+	assert.Equal(t, 2, execution.Attempts)
+}
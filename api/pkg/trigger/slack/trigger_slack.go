@@ -0,0 +1,455 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/controller"
+	oai "github.com/helixml/helix/api/pkg/openai"
+	"github.com/helixml/helix/api/pkg/pubsub"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+
+	"github.com/rs/zerolog/log"
+	openai "github.com/sashabaranov/go-openai"
+	slackgo "github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+)
+
+const (
+	// Users will be redirected to this URL to install the bot
+	installationDocsURL = "https://docs.helix.ml/helix/"
+	// history limit
+	historyLimit = 30
+	// Shown until the first progress update or the response
+	thinkingMessage = "Thinking..."
+)
+
+type Slack struct {
+	cfg        *config.ServerConfig
+	store      store.Store
+	controller *controller.Controller
+
+	client    *slackgo.Client
+	botUserID string
+	botID     string
+
+	appsMu      sync.Mutex
+	channelApps map[string]*types.App // Channel ID -> App
+	directApp   *types.App            // App answering the direct messages
+}
+
+// message is a mention of the bot in a channel or a direct message to the bot
+type message struct {
+	Channel         string
+	User            string
+	Text            string
+	TimeStamp       string
+	ThreadTimeStamp string
+	Direct          bool
+}
+
+func New(cfg *config.ServerConfig, store store.Store, controller *controller.Controller) *Slack {
+	return &Slack{
+		cfg:        cfg,
+		store:      store,
+		controller: controller,
+		client: slackgo.New(
+			cfg.Triggers.Slack.BotToken,
+			slackgo.OptionAppLevelToken(cfg.Triggers.Slack.AppToken),
+		),
+		channelApps: make(map[string]*types.App),
+	}
+}
+
+func (s *Slack) Start(ctx context.Context) error {
+	auth, err := s.client.AuthTestContext(ctx)
+	if err != nil {
+		return fmt.Errorf("error obtaining account details: %w", err)
+	}
+	s.botUserID = auth.UserID
+	s.botID = auth.BotID
+
+	logger := log.With().Str("trigger", "slack").Logger()
+
+	logger.Info().Str("team", auth.Team).Msg("starting Slack bot")
+
+	// Load the apps before connecting so the first messages can be answered,
+	// then keep them in sync
+	err = s.syncAppsOnce(ctx)
+	if err != nil {
+		logger.Err(err).Msg("failed to sync Slack apps")
+	}
+
+	// Start is called again to reconnect when the connection fails, stop the
+	// event handling and the sync of this connection when it returns. The
+	// replies that are still running use the parent context.
+	connCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go s.syncApps(connCtx)
+
+	socket := socketmode.New(s.client)
+	go s.handleEvents(ctx, connCtx, socket)
+
+	err = socket.RunContext(connCtx)
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("slack socket mode connection failed: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Slack) syncApps(ctx context.Context) {
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := s.syncAppsOnce(ctx)
+			if err != nil {
+				log.Err(err).Str("trigger", "slack").Msg("failed to sync Slack apps")
+			}
+		}
+	}
+}
+
+// syncAppsOnce routes every channel and the direct messages to the app that
+// claims them. The API rejects conflicting claims, if some still exist, for
+// example from before the check, the oldest app keeps the claim and the others
+// are skipped.
+func (s *Slack) syncAppsOnce(ctx context.Context) error {
+	// Load all apps, check for slack configuration
+	apps, err := s.store.ListApps(ctx, &store.ListAppsQuery{})
+	if err != nil {
+		return fmt.Errorf("failed to list apps: %w", err)
+	}
+
+	// The store doesn't sort the apps by age
+	apps = slices.Clone(apps)
+	slices.SortStableFunc(apps, func(a, b *types.App) int {
+		if c := a.Created.Compare(b.Created); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	channelApps := make(map[string]*types.App)
+	var directApp *types.App
+
+	for _, app := range apps {
+		for _, trigger := range app.Config.Helix.Triggers {
+			if trigger.Slack == nil {
+				continue
+			}
+
+			for _, channel := range trigger.Slack.Channels {
+				if claimed, ok := channelApps[channel]; ok && claimed.ID != app.ID {
+					log.Warn().
+						Str("trigger", "slack").
+						Str("channel", channel).
+						Str("app_id", app.ID).
+						Str("claimed_by", claimed.ID).
+						Msg("skipping Slack channel claimed by an older app")
+					continue
+				}
+				channelApps[channel] = app
+			}
+
+			if !trigger.Slack.DirectMessages {
+				continue
+			}
+			if directApp != nil && directApp.ID != app.ID {
+				log.Warn().
+					Str("trigger", "slack").
+					Str("app_id", app.ID).
+					Str("claimed_by", directApp.ID).
+					Msg("skipping Slack direct messages claimed by an older app")
+				continue
+			}
+			directApp = app
+		}
+	}
+
+	s.appsMu.Lock()
+	s.channelApps = channelApps
+	s.directApp = directApp
+	s.appsMu.Unlock()
+
+	return nil
+}
+
+func (s *Slack) getApp(m *message) (*types.App, bool) {
+	s.appsMu.Lock()
+	defer s.appsMu.Unlock()
+
+	if m.Direct {
+		return s.directApp, s.directApp != nil
+	}
+
+	app, ok := s.channelApps[m.Channel]
+	return app, ok
+}
+
+func (s *Slack) handleEvents(ctx, connCtx context.Context, socket *socketmode.Client) {
+	for {
+		select {
+		case <-connCtx.Done():
+			return
+		case evt, ok := <-socket.Events:
+			if !ok {
+				return
+			}
+
+			if evt.Type != socketmode.EventTypeEventsAPI {
+				continue
+			}
+
+			eventsAPIEvent, ok := evt.Data.(slackevents.EventsAPIEvent)
+			if !ok {
+				continue
+			}
+
+			// Slack redelivers the events that are not acknowledged within 3 seconds
+			socket.Ack(*evt.Request)
+
+			switch ev := eventsAPIEvent.InnerEvent.Data.(type) {
+			case *slackevents.AppMentionEvent:
+				if ev.BotID != "" {
+					continue
+				}
+
+				go s.handleMessage(ctx, &message{
+					Channel:         ev.Channel,
+					User:            ev.User,
+					Text:            ev.Text,
+					TimeStamp:       ev.TimeStamp,
+					ThreadTimeStamp: ev.ThreadTimeStamp,
+				})
+			case *slackevents.MessageEvent:
+				// Mentions in the channels are handled as app_mention events,
+				// subtypes are edits, deletions and bot messages
+				if ev.ChannelType != "im" || ev.SubType != "" || ev.BotID != "" || ev.User == s.botUserID {
+					continue
+				}
+
+				go s.handleMessage(ctx, &message{
+					Channel:         ev.Channel,
+					User:            ev.User,
+					Text:            ev.Text,
+					TimeStamp:       ev.TimeStamp,
+					ThreadTimeStamp: ev.ThreadTimeStamp,
+					Direct:          true,
+				})
+			}
+		}
+	}
+}
+
+func (s *Slack) handleMessage(ctx context.Context, m *message) {
+	logger := log.With().Str("trigger", "slack").Str("channel", m.Channel).Logger()
+
+	// Replies always go to the thread of the message
+	threadTimeStamp := m.ThreadTimeStamp
+	if threadTimeStamp == "" {
+		threadTimeStamp = m.TimeStamp
+	}
+
+	app, ok := s.getApp(m)
+	if !ok {
+		logger.Warn().Msg("no app configured for channel")
+
+		_, _, err := s.client.PostMessageContext(ctx, m.Channel,
+			slackgo.MsgOptionText(fmt.Sprintf("I am not yet configured to respond in this Slack channel. Please visit %s to install me.", installationDocsURL), false),
+			slackgo.MsgOptionTS(threadTimeStamp),
+		)
+		if err != nil {
+			logger.Err(err).Msg("failed to send message")
+		}
+		return
+	}
+
+	logger.Info().
+		Str("app_id", app.ID).
+		Str("user", m.User).
+		Bool("direct", m.Direct).
+		Msg("received message")
+
+	history, err := s.getThreadHistory(ctx, m)
+	if err != nil {
+		// Answer without the context rather than not at all
+		logger.Err(err).Msg("failed to get messages from thread")
+	}
+
+	// Progress and the response are shown by updating the placeholder message
+	_, replyTimeStamp, err := s.client.PostMessageContext(ctx, m.Channel,
+		slackgo.MsgOptionText(thinkingMessage, false),
+		slackgo.MsgOptionTS(threadTimeStamp),
+	)
+	if err != nil {
+		logger.Err(err).Msg("failed to send message")
+		return
+	}
+
+	var (
+		replyMu  sync.Mutex
+		finished bool
+	)
+
+	updateReply := func(text string, final bool) {
+		replyMu.Lock()
+		defer replyMu.Unlock()
+
+		// Late progress updates must not overwrite the response
+		if finished {
+			return
+		}
+		finished = final
+
+		_, _, _, err := s.client.UpdateMessageContext(ctx, m.Channel, replyTimeStamp, slackgo.MsgOptionText(text, false))
+		if err != nil {
+			logger.Err(err).Msg("failed to update message")
+		}
+	}
+
+	resp, err := s.startChat(ctx, app, history, m, func(stepInfo *types.StepInfo) {
+		updateReply(stepInfo.Message+"...", false)
+	})
+	if err != nil {
+		logger.Err(err).Msg("failed to get response from inference API")
+		updateReply(fmt.Sprintf("Failed to get response: %s", err), true)
+		return
+	}
+
+	updateReply(resp, true)
+}
+
+// getThreadHistory returns the earlier messages of the thread, without the message itself
+func (s *Slack) getThreadHistory(ctx context.Context, m *message) ([]slackgo.Message, error) {
+	if m.ThreadTimeStamp == "" {
+		return nil, nil
+	}
+
+	msgs, _, _, err := s.client.GetConversationRepliesContext(ctx, &slackgo.GetConversationRepliesParameters{
+		ChannelID: m.Channel,
+		Timestamp: m.ThreadTimeStamp,
+		Limit:     historyLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var history []slackgo.Message
+	for _, msg := range msgs {
+		if msg.Timestamp == m.TimeStamp {
+			continue
+		}
+		history = append(history, msg)
+	}
+
+	return history, nil
+}
+
+func (s *Slack) isBotMessage(msg slackgo.Message) bool {
+	return msg.User == s.botUserID || (msg.BotID != "" && msg.BotID == s.botID)
+}
+
+func (s *Slack) startChat(ctx context.Context, app *types.App, history []slackgo.Message, m *message, onStep func(*types.StepInfo)) (string, error) {
+	systemMessage := openai.ChatCompletionMessage{
+		Role:    openai.ChatMessageRoleSystem,
+		Content: `You are an AI assistant Slack bot. Be concise with the replies, keep them short but informative.`,
+	}
+
+	messages := []openai.ChatCompletionMessage{
+		systemMessage,
+	}
+
+	for _, msg := range history {
+		switch {
+		case s.isBotMessage(msg):
+			messages = append(messages, openai.ChatCompletionMessage{
+				Role:    openai.ChatMessageRoleAssistant,
+				Content: msg.Text,
+			})
+		default:
+			messages = append(messages, openai.ChatCompletionMessage{
+				Role:    openai.ChatMessageRoleUser,
+				Content: s.stripMention(msg.Text),
+			})
+		}
+	}
+
+	messages = append(messages, openai.ChatCompletionMessage{
+		Role:    openai.ChatMessageRoleUser,
+		Content: s.stripMention(m.Text),
+	})
+
+	// The controller publishes the progress (knowledge search, tool calls) to the
+	// session queue, the session only exists for the duration of the chat
+	sessionID := system.GenerateSessionID()
+
+	if s.controller.Options.PubSub != nil {
+		sub, err := s.controller.Options.PubSub.Subscribe(ctx, pubsub.GetSessionQueue(app.Owner, sessionID), func(payload []byte) error {
+			var event types.WebsocketEvent
+			err := json.Unmarshal(payload, &event)
+			if err != nil {
+				return err
+			}
+
+			if event.Type == types.WebsocketEventProcessingStepInfo && event.StepInfo != nil {
+				onStep(event.StepInfo)
+			}
+			return nil
+		})
+		if err != nil {
+			log.Warn().Err(err).Str("trigger", "slack").Msg("failed to subscribe to progress updates")
+		} else {
+			defer sub.Unsubscribe()
+		}
+	}
+
+	ctx = oai.SetContextValues(ctx, &oai.ContextValues{
+		OwnerID:       app.Owner,
+		SessionID:     sessionID,
+		InteractionID: system.GenerateUUID(),
+	})
+
+	resp, _, err := s.controller.ChatCompletion(
+		ctx,
+		&types.User{
+			ID:   app.Owner,
+			Type: app.OwnerType,
+		},
+		openai.ChatCompletionRequest{
+			Stream:   false,
+			Messages: messages,
+		},
+		&controller.ChatCompletionOptions{
+			AppID: app.ID,
+		},
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to get response from inference API: %w", err)
+	}
+
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("no choices in response")
+	}
+
+	return resp.Choices[0].Message.Content, nil
+}
+
+func (s *Slack) stripMention(text string) string {
+	return strings.TrimSpace(strings.ReplaceAll(text, "<@"+s.botUserID+">", ""))
+}
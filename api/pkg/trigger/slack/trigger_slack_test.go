@@ -0,0 +1,258 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+	slackgo "github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/controller"
+	"github.com/helixml/helix/api/pkg/extract"
+	"github.com/helixml/helix/api/pkg/filestore"
+	"github.com/helixml/helix/api/pkg/janitor"
+	oai "github.com/helixml/helix/api/pkg/openai"
+	"github.com/helixml/helix/api/pkg/openai/manager"
+	"github.com/helixml/helix/api/pkg/pubsub"
+	"github.com/helixml/helix/api/pkg/scheduler"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+// fakeSlack records the messages posted and updated through the Slack Web API
+type fakeSlack struct {
+	mu      sync.Mutex
+	posted  []string
+	updates []string
+	replies []slackgo.Message
+}
+
+func (f *fakeSlack) handler(t *testing.T) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "100.000", r.FormValue("thread_ts"))
+
+		f.mu.Lock()
+		f.posted = append(f.posted, r.FormValue("text"))
+		f.mu.Unlock()
+
+		writeJSON(t, w, map[string]interface{}{"ok": true, "channel": r.FormValue("channel"), "ts": "200.000"})
+	})
+
+	mux.HandleFunc("/chat.update", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "200.000", r.FormValue("ts"))
+
+		f.mu.Lock()
+		f.updates = append(f.updates, r.FormValue("text"))
+		f.mu.Unlock()
+
+		writeJSON(t, w, map[string]interface{}{"ok": true, "channel": r.FormValue("channel"), "ts": "200.000"})
+	})
+
+	mux.HandleFunc("/conversations.replies", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "100.000", r.FormValue("ts"))
+
+		writeJSON(t, w, map[string]interface{}{"ok": true, "messages": f.replies, "has_more": false})
+	})
+
+	return mux
+}
+
+func (f *fakeSlack) getUpdates() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.updates...)
+}
+
+func writeJSON(t *testing.T, w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	require.NoError(t, json.NewEncoder(w).Encode(v))
+}
+
+func newTestSlack(t *testing.T, fake *fakeSlack) (*Slack, *store.MockStore, *oai.MockClient, pubsub.PubSub) {
+	ctrl := gomock.NewController(t)
+
+	srv := httptest.NewServer(fake.handler(t))
+	t.Cleanup(srv.Close)
+
+	ps, err := pubsub.New(t.TempDir())
+	require.NoError(t, err)
+
+	storeMock := store.NewMockStore(ctrl)
+	openAiClient := oai.NewMockClient(ctrl)
+
+	cfg := &config.ServerConfig{}
+	cfg.Tools.Enabled = false
+	cfg.Inference.Provider = types.ProviderTogetherAI
+
+	providerManager := manager.NewMockProviderManager(ctrl)
+	providerManager.EXPECT().GetClient(gomock.Any(), gomock.Any()).Return(openAiClient, nil).AnyTimes()
+
+	c, err := controller.NewController(context.Background(), controller.ControllerOptions{
+		Config:          cfg,
+		Store:           storeMock,
+		Janitor:         janitor.NewJanitor(config.Janitor{}),
+		ProviderManager: providerManager,
+		Filestore:       filestore.NewMockFileStore(ctrl),
+		Extractor:       extract.NewMockExtractor(ctrl),
+		Scheduler:       scheduler.NewScheduler(cfg),
+		PubSub:          ps,
+	})
+	require.NoError(t, err)
+
+	return &Slack{
+		cfg:         cfg,
+		store:       storeMock,
+		controller:  c,
+		client:      slackgo.New("xoxb-test", slackgo.OptionAPIURL(srv.URL+"/")),
+		botUserID:   "UBOT",
+		botID:       "BBOT",
+		channelApps: make(map[string]*types.App),
+	}, storeMock, openAiClient, ps
+}
+
+func TestHandleMessage_ThreadReply(t *testing.T) {
+	fake := &fakeSlack{
+		replies: []slackgo.Message{
+			{Msg: slackgo.Msg{User: "U1", Text: "<@UBOT> what is helix?", Timestamp: "100.000"}},
+			{Msg: slackgo.Msg{User: "UBOT", BotID: "BBOT", Text: "A GenAI platform.", Timestamp: "101.000"}},
+			{Msg: slackgo.Msg{User: "U1", Text: "<@UBOT> can it run locally?", Timestamp: "102.000"}},
+		},
+	}
+
+	s, storeMock, openAiClient, ps := newTestSlack(t, fake)
+
+	app := &types.App{
+		ID:        "app_1",
+		Owner:     "user_1",
+		OwnerType: types.OwnerTypeUser,
+		Config: types.AppConfig{
+			Helix: types.AppHelixConfig{
+				Assistants: []types.AssistantConfig{
+					{SystemPrompt: "You answer questions about Helix."},
+				},
+			},
+		},
+	}
+	s.channelApps["C1"] = app
+
+	storeMock.EXPECT().GetApp(gomock.Any(), "app_1").Return(app, nil)
+
+	openAiClient.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+			require.Len(t, req.Messages, 4)
+			assert.Equal(t, openai.ChatMessageRoleSystem, req.Messages[0].Role)
+			assert.Equal(t, "You answer questions about Helix.", req.Messages[0].Content)
+			assert.Equal(t, openai.ChatMessageRoleUser, req.Messages[1].Role)
+			assert.Equal(t, "what is helix?", req.Messages[1].Content)
+			assert.Equal(t, openai.ChatMessageRoleAssistant, req.Messages[2].Role)
+			assert.Equal(t, "A GenAI platform.", req.Messages[2].Content)
+			assert.Equal(t, openai.ChatMessageRoleUser, req.Messages[3].Role)
+			assert.Equal(t, "can it run locally?", req.Messages[3].Content)
+
+			// Progress is published by the controller while the tools and knowledge run
+			vals, ok := oai.GetContextValues(ctx)
+			require.True(t, ok)
+
+			bts, err := json.Marshal(&types.WebsocketEvent{
+				Type:     types.WebsocketEventProcessingStepInfo,
+				StepInfo: &types.StepInfo{Message: "Searching knowledge"},
+			})
+			require.NoError(t, err)
+
+			err = ps.Publish(ctx, pubsub.GetSessionQueue(vals.OwnerID, vals.SessionID), bts)
+			require.NoError(t, err)
+
+			require.Eventually(t, func() bool {
+				return len(fake.getUpdates()) == 1
+			}, 5*time.Second, 10*time.Millisecond)
+
+			return openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{
+					{Message: openai.ChatCompletionMessage{Content: "Yes, with your own GPUs."}},
+				},
+			}, nil
+		})
+
+	s.handleMessage(context.Background(), &message{
+		Channel:         "C1",
+		User:            "U1",
+		Text:            "<@UBOT> can it run locally?",
+		TimeStamp:       "102.000",
+		ThreadTimeStamp: "100.000",
+	})
+
+	assert.Equal(t, []string{thinkingMessage}, fake.posted)
+	assert.Equal(t, []string{"Searching knowledge...", "Yes, with your own GPUs."}, fake.getUpdates())
+}
+
+func TestHandleMessage_NotConfigured(t *testing.T) {
+	fake := &fakeSlack{}
+
+	s, _, _, _ := newTestSlack(t, fake)
+
+	s.handleMessage(context.Background(), &message{
+		Channel:   "C2",
+		User:      "U1",
+		Text:      "<@UBOT> hello",
+		TimeStamp: "100.000",
+	})
+
+	require.Len(t, fake.posted, 1)
+	assert.Contains(t, fake.posted[0], "not yet configured")
+	assert.Empty(t, fake.getUpdates())
+}
+
+func TestSyncAppsOnce_ConflictingClaims(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+
+	now := time.Now()
+	slackApp := func(id string, created time.Time, channels ...string) *types.App {
+		return &types.App{
+			ID:      id,
+			Created: created,
+			Config: types.AppConfig{
+				Helix: types.AppHelixConfig{
+					Triggers: []types.Trigger{
+						{Slack: &types.SlackTrigger{Channels: channels, DirectMessages: true}},
+					},
+				},
+			},
+		}
+	}
+
+	// The newer app is listed first, the order of the store is not stable
+	storeMock.EXPECT().ListApps(gomock.Any(), &store.ListAppsQuery{}).Return([]*types.App{
+		slackApp("app_new", now, "C1", "C2"),
+		slackApp("app_old", now.Add(-time.Hour), "C1"),
+	}, nil)
+
+	s := &Slack{store: storeMock}
+	require.NoError(t, s.syncAppsOnce(context.Background()))
+
+	app, ok := s.getApp(&message{Channel: "C1"})
+	require.True(t, ok)
+	assert.Equal(t, "app_old", app.ID)
+
+	app, ok = s.getApp(&message{Channel: "C2"})
+	require.True(t, ok)
+	assert.Equal(t, "app_new", app.ID)
+
+	app, ok = s.getApp(&message{Direct: true})
+	require.True(t, ok)
+	assert.Equal(t, "app_old", app.ID)
+}
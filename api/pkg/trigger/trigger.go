@@ -10,6 +10,7 @@ import (
 	"github.com/helixml/helix/api/pkg/store"
 	"github.com/helixml/helix/api/pkg/trigger/cron"
 	"github.com/helixml/helix/api/pkg/trigger/discord"
+	"github.com/helixml/helix/api/pkg/trigger/slack"
 
 	"github.com/rs/zerolog/log"
 )
@@ -41,6 +42,14 @@ func (t *TriggerManager) Start(ctx context.Context) {
 		}()
 	}
 
+	if t.cfg.Triggers.Slack.Enabled && t.cfg.Triggers.Slack.AppToken != "" && t.cfg.Triggers.Slack.BotToken != "" {
+		t.wg.Add(1)
+		go func() {
+			defer t.wg.Done()
+			t.runSlack(ctx)
+		}()
+	}
+
 	t.wg.Add(1)
 	go func() {
 		defer t.wg.Done()
@@ -67,6 +76,23 @@ func (t *TriggerManager) runDiscord(ctx context.Context) {
 	}
 }
 
+func (t *TriggerManager) runSlack(ctx context.Context) {
+	slackTrigger := slack.New(t.cfg, t.store, t.controller)
+
+	for {
+		err := slackTrigger.Start(ctx)
+		if err != nil {
+			log.Err(err).Msg("failed to start Slack trigger, retrying in 10 seconds")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(10 * time.Second):
+		}
+	}
+}
+
 func (t *TriggerManager) runCron(ctx context.Context) {
 	cronTrigger, err := cron.New(t.cfg, t.store, t.controller)
 	if err != nil {
@@ -0,0 +1,331 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/controller"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/trigger/execution"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+const (
+	// SignatureHeader carries the HMAC-SHA256 of the timestamp and the request
+	// body, it is also set on the callback requests so the receiver can verify them
+	SignatureHeader = "X-Helix-Signature"
+	// TimestampHeader is the unix time in seconds when the request was signed,
+	// it's part of the signature so captured requests can't be replayed later
+	TimestampHeader = "X-Helix-Timestamp"
+	signaturePrefix = "sha256="
+
+	// SignatureTolerance is how far the timestamp can be from the current time
+	SignatureTolerance = 5 * time.Minute
+
+	callbackTimeout = 30 * time.Second
+)
+
+type Webhook struct {
+	cfg        *config.ServerConfig
+	store      store.Store
+	runner     *execution.Runner
+	httpClient *http.Client
+}
+
+func New(cfg *config.ServerConfig, store store.Store, controller *controller.Controller) *Webhook {
+	return &Webhook{
+		cfg:    cfg,
+		store:  store,
+		runner: execution.NewRunner(store, controller),
+		httpClient: &http.Client{
+			Timeout: callbackTimeout,
+		},
+	}
+}
+
+// Result is returned to the caller of the webhook or POSTed to the callback URL
+type Result struct {
+	ExecutionID string                       `json:"execution_id"`
+	SessionID   string                       `json:"session_id,omitempty"`
+	Status      types.TriggerExecutionStatus `json:"status"`
+	Output      string                       `json:"output,omitempty"`
+	Error       string                       `json:"error,omitempty"`
+}
+
+func newResult(execution *types.TriggerExecution) *Result {
+	return &Result{
+		ExecutionID: execution.ID,
+		SessionID:   execution.SessionID,
+		Status:      execution.Status,
+		Output:      execution.Output,
+		Error:       execution.Error,
+	}
+}
+
+// GetTrigger returns the enabled webhook trigger of the app
+func GetTrigger(app *types.App) (*types.WebhookTrigger, bool) {
+	for _, trigger := range app.Config.Helix.Triggers {
+		if trigger.Webhook != nil && trigger.Webhook.Enabled {
+			return trigger.Webhook, true
+		}
+	}
+
+	return nil, false
+}
+
+// Sign returns the signature header value for the timestamp and the body, the
+// signed content is the timestamp header value, a dot and the body
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10) + "."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SetSignatureHeaders signs the body with the current time
+func SetSignatureHeaders(header http.Header, secret string, body []byte) {
+	now := time.Now()
+	header.Set(TimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	header.Set(SignatureHeader, Sign(secret, now, body))
+}
+
+// VerifySignature checks the signature and the timestamp headers against the
+// body, requests signed more than SignatureTolerance away from now are rejected
+func VerifySignature(secret string, body []byte, header http.Header) error {
+	signature := header.Get(SignatureHeader)
+	if signature == "" {
+		return fmt.Errorf("missing %s header", SignatureHeader)
+	}
+
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return fmt.Errorf("unsupported signature, expected %s<hex>", signaturePrefix)
+	}
+
+	value := header.Get(TimestampHeader)
+	if value == "" {
+		return fmt.Errorf("missing %s header", TimestampHeader)
+	}
+
+	unix, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %s header, expected unix time in seconds", TimestampHeader)
+	}
+
+	timestamp := time.Unix(unix, 0)
+	if age := time.Since(timestamp); age > SignatureTolerance || age < -SignatureTolerance {
+		return fmt.Errorf("%s is outside of the %s tolerance", TimestampHeader, SignatureTolerance)
+	}
+
+	if !hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature)) {
+		return fmt.Errorf("signature mismatch")
+	}
+
+	return nil
+}
+
+// RenderPrompt maps the JSON payload into the prompt with the trigger's template
+func RenderPrompt(trigger *types.WebhookTrigger, body []byte) (string, error) {
+	if trigger.PromptTemplate == "" {
+		return string(body), nil
+	}
+
+	tmpl, err := parsePromptTemplate(trigger.PromptTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	var payload interface{}
+	err = json.Unmarshal(body, &payload)
+	if err != nil {
+		return "", fmt.Errorf("invalid JSON payload: %w", err)
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to render prompt: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+func parsePromptTemplate(promptTemplate string) (*template.Template, error) {
+	// Missing fields are errors rather than "<no value>" in the prompt
+	tmpl, err := template.New("prompt").Funcs(execution.TemplateFuncs).Option("missingkey=error").Parse(promptTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prompt template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// ValidateTrigger checks the secret, the prompt template and the callback URL
+func ValidateTrigger(trigger *types.WebhookTrigger) error {
+	if !trigger.Enabled {
+		return nil
+	}
+
+	if trigger.Secret == "" {
+		return fmt.Errorf("webhook secret not specified")
+	}
+
+	if trigger.PromptTemplate != "" {
+		_, err := parsePromptTemplate(trigger.PromptTemplate)
+		if err != nil {
+			return err
+		}
+	}
+
+	if trigger.CallbackURL != "" {
+		u, err := url.Parse(trigger.CallbackURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid callback URL '%s'", trigger.CallbackURL)
+		}
+	}
+
+	return nil
+}
+
+// Invoke runs the app with the payload and returns the result. Failures of the
+// run are recorded in the execution, the error is only returned if the
+// execution couldn't be recorded.
+func (w *Webhook) Invoke(ctx context.Context, app *types.App, trigger *types.WebhookTrigger, body []byte) (*Result, error) {
+	execution, session, err := w.start(ctx, app, trigger, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if session != nil {
+		execution, err = w.run(ctx, app, execution, session)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return newResult(execution), nil
+}
+
+// InvokeAsync records the invocation and runs the app in the background, the
+// result is POSTed to the callback URL of the trigger. The context must outlive
+// the request.
+func (w *Webhook) InvokeAsync(ctx context.Context, app *types.App, trigger *types.WebhookTrigger, body []byte) (*Result, error) {
+	execution, session, err := w.start(ctx, app, trigger, body)
+	if err != nil {
+		return nil, err
+	}
+
+	// Nothing to run, the caller gets the error right away
+	if session == nil {
+		return newResult(execution), nil
+	}
+
+	result := newResult(execution)
+
+	go func() {
+		execution, err := w.run(ctx, app, execution, session)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("app_id", app.ID).
+				Msg("failed to record webhook trigger execution")
+			return
+		}
+
+		err = w.callback(ctx, trigger, newResult(execution))
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("app_id", app.ID).
+				Str("execution_id", execution.ID).
+				Msg("failed to deliver webhook trigger callback")
+
+			execution.Status = types.TriggerExecutionStatusError
+			execution.Error = err.Error()
+
+			_, err = w.store.UpdateTriggerExecution(ctx, execution)
+			if err != nil {
+				log.Error().
+					Err(err).
+					Str("execution_id", execution.ID).
+					Msg("failed to update trigger execution")
+			}
+		}
+	}()
+
+	return result, nil
+}
+
+// start records the invocation, the session is nil when the payload couldn't
+// be mapped to the prompt
+func (w *Webhook) start(ctx context.Context, app *types.App, trigger *types.WebhookTrigger, body []byte) (*types.TriggerExecution, *types.Session, error) {
+	prompt, err := RenderPrompt(trigger, body)
+	if err != nil {
+		execution, createErr := w.store.CreateTriggerExecution(ctx, &types.TriggerExecution{
+			AppID:   app.ID,
+			Trigger: types.TriggerTypeWebhook,
+			Input:   string(body),
+			Status:  types.TriggerExecutionStatusError,
+			Error:   err.Error(),
+		})
+		if createErr != nil {
+			return nil, nil, fmt.Errorf("failed to create trigger execution: %w", createErr)
+		}
+		return execution, nil, nil
+	}
+
+	return w.runner.Start(ctx, app, types.TriggerTypeWebhook, prompt)
+}
+
+func (w *Webhook) run(ctx context.Context, app *types.App, execution *types.TriggerExecution, session *types.Session) (*types.TriggerExecution, error) {
+	started := time.Now()
+
+	w.runner.Run(ctx, app, execution, session)
+
+	return w.runner.Finish(ctx, execution, started)
+}
+
+// callback POSTs the result to the callback URL, signed with the trigger's secret
+func (w *Webhook) callback(ctx context.Context, trigger *types.WebhookTrigger, result *Result) error {
+	body, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, trigger.CallbackURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create callback request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range trigger.CallbackHeaders {
+		req.Header.Set(k, v)
+	}
+	SetSignatureHeaders(req.Header, trigger.Secret, body)
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call callback URL: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("callback returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return nil
+}
@@ -0,0 +1,301 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/controller"
+	"github.com/helixml/helix/api/pkg/extract"
+	"github.com/helixml/helix/api/pkg/filestore"
+	"github.com/helixml/helix/api/pkg/janitor"
+	oai "github.com/helixml/helix/api/pkg/openai"
+	"github.com/helixml/helix/api/pkg/openai/manager"
+	"github.com/helixml/helix/api/pkg/scheduler"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"title": "build failed"}`)
+
+	signed := func(secret string, timestamp time.Time, body []byte) http.Header {
+		header := http.Header{}
+		header.Set(TimestampHeader, strconv.FormatInt(timestamp.Unix(), 10))
+		header.Set(SignatureHeader, Sign(secret, timestamp, body))
+		return header
+	}
+
+	header := http.Header{}
+	SetSignatureHeaders(header, "secret", body)
+	assert.NoError(t, VerifySignature("secret", body, header))
+
+	assert.NoError(t, VerifySignature("secret", body, signed("secret", time.Now().Add(-time.Minute), body)))
+	assert.ErrorContains(t, VerifySignature("secret", body, http.Header{}), "missing X-Helix-Signature header")
+	assert.ErrorContains(t, VerifySignature("secret", body, http.Header{SignatureHeader: {"sha1=abc"}}), "unsupported signature")
+	assert.ErrorContains(t, VerifySignature("other", body, signed("secret", time.Now(), body)), "signature mismatch")
+	assert.ErrorContains(t, VerifySignature("secret", []byte(`{}`), signed("secret", time.Now(), body)), "signature mismatch")
+
+	// Replayed and future requests are outside of the tolerance
+	assert.ErrorContains(t, VerifySignature("secret", body, signed("secret", time.Now().Add(-10*time.Minute), body)), "outside of the 5m0s tolerance")
+	assert.ErrorContains(t, VerifySignature("secret", body, signed("secret", time.Now().Add(10*time.Minute), body)), "outside of the 5m0s tolerance")
+
+	// The timestamp is part of the signature
+	header = signed("secret", time.Now().Add(-10*time.Minute), body)
+	header.Set(TimestampHeader, strconv.FormatInt(time.Now().Unix(), 10))
+	assert.ErrorContains(t, VerifySignature("secret", body, header), "signature mismatch")
+
+	header = http.Header{SignatureHeader: {Sign("secret", time.Now(), body)}}
+	assert.ErrorContains(t, VerifySignature("secret", body, header), "missing X-Helix-Timestamp header")
+}
+
+func TestRenderPrompt(t *testing.T) {
+	trigger := &types.WebhookTrigger{
+		PromptTemplate: `Triage {{ .ticket.title }} with labels {{ json .ticket.labels }}`,
+	}
+
+	prompt, err := RenderPrompt(trigger, []byte(`{"ticket": {"title": "Login broken", "labels": ["auth", "p1"]}}`))
+	require.NoError(t, err)
+	assert.Equal(t, `Triage Login broken with labels ["auth","p1"]`, prompt)
+
+	_, err = RenderPrompt(trigger, []byte(`not json`))
+	assert.ErrorContains(t, err, "invalid JSON payload")
+
+	_, err = RenderPrompt(trigger, []byte(`{"issue": {}}`))
+	assert.ErrorContains(t, err, "failed to render prompt")
+
+	// The raw body is used without the template
+	prompt, err = RenderPrompt(&types.WebhookTrigger{}, []byte(`summarize this`))
+	require.NoError(t, err)
+	assert.Equal(t, "summarize this", prompt)
+}
+
+func TestValidateTrigger(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		trigger *types.WebhookTrigger
+		wantErr string
+	}{
+		{
+			name: "valid",
+			trigger: &types.WebhookTrigger{
+				Enabled:        true,
+				Secret:         "secret",
+				PromptTemplate: "{{ .title }}",
+				CallbackURL:    "https://ci.example.com/hook",
+			},
+		},
+		{
+			name:    "disabled",
+			trigger: &types.WebhookTrigger{},
+		},
+		{
+			name:    "no secret",
+			trigger: &types.WebhookTrigger{Enabled: true},
+			wantErr: "webhook secret not specified",
+		},
+		{
+			name:    "invalid template",
+			trigger: &types.WebhookTrigger{Enabled: true, Secret: "secret", PromptTemplate: "{{ .title "},
+			wantErr: "failed to parse prompt template",
+		},
+		{
+			name:    "invalid callback URL",
+			trigger: &types.WebhookTrigger{Enabled: true, Secret: "secret", CallbackURL: "ci.example.com"},
+			wantErr: "invalid callback URL",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateTrigger(tc.trigger)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
+func newTestWebhook(t *testing.T) (*Webhook, *store.MockStore, *oai.MockClient) {
+	ctrl := gomock.NewController(t)
+
+	storeMock := store.NewMockStore(ctrl)
+	openAiClient := oai.NewMockClient(ctrl)
+
+	cfg := &config.ServerConfig{}
+	cfg.Tools.Enabled = false
+	cfg.Inference.Provider = types.ProviderTogetherAI
+
+	providerManager := manager.NewMockProviderManager(ctrl)
+	providerManager.EXPECT().GetClient(gomock.Any(), gomock.Any()).Return(openAiClient, nil).AnyTimes()
+
+	c, err := controller.NewController(context.Background(), controller.ControllerOptions{
+		Config:          cfg,
+		Store:           storeMock,
+		Janitor:         janitor.NewJanitor(config.Janitor{}),
+		ProviderManager: providerManager,
+		Filestore:       filestore.NewMockFileStore(ctrl),
+		Extractor:       extract.NewMockExtractor(ctrl),
+		Scheduler:       scheduler.NewScheduler(cfg),
+	})
+	require.NoError(t, err)
+
+	return New(cfg, storeMock, c), storeMock, openAiClient
+}
+
+func expectExecution(storeMock *store.MockStore) {
+	storeMock.EXPECT().CreateSession(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, session types.Session) (*types.Session, error) {
+			return &session, nil
+		})
+	storeMock.EXPECT().CreateTriggerExecution(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, execution *types.TriggerExecution) (*types.TriggerExecution, error) {
+			execution.ID = system.GenerateTriggerExecutionID()
+			return execution, nil
+		})
+	storeMock.EXPECT().UpdateInteraction(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, interaction *types.Interaction) (*types.Interaction, error) {
+			return interaction, nil
+		})
+	storeMock.EXPECT().UpdateTriggerExecution(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, execution *types.TriggerExecution) (*types.TriggerExecution, error) {
+			return execution, nil
+		})
+}
+
+func testApp() *types.App {
+	return &types.App{
+		ID:        "app_1",
+		Owner:     "user_1",
+		OwnerType: types.OwnerTypeUser,
+		Config: types.AppConfig{
+			Helix: types.AppHelixConfig{
+				Assistants: []types.AssistantConfig{{}},
+			},
+		},
+	}
+}
+
+func TestInvoke(t *testing.T) {
+	w, storeMock, openAiClient := newTestWebhook(t)
+
+	app := testApp()
+	trigger := &types.WebhookTrigger{
+		Enabled:        true,
+		Secret:         "secret",
+		PromptTemplate: "Triage: {{ .title }}",
+	}
+
+	storeMock.EXPECT().GetApp(gomock.Any(), "app_1").Return(app, nil)
+	expectExecution(storeMock)
+
+	openAiClient.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+			require.Len(t, req.Messages, 1)
+			assert.Equal(t, "Triage: Login broken", req.Messages[0].Content)
+
+			return openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{
+					{Message: openai.ChatCompletionMessage{Content: "Assign to the auth team."}},
+				},
+			}, nil
+		})
+
+	result, err := w.Invoke(context.Background(), app, trigger, []byte(`{"title": "Login broken"}`))
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, result.ExecutionID)
+	assert.NotEmpty(t, result.SessionID)
+	assert.Equal(t, types.TriggerExecutionStatusSuccess, result.Status)
+	assert.Equal(t, "Assign to the auth team.", result.Output)
+}
+
+func TestInvoke_InvalidPayload(t *testing.T) {
+	w, storeMock, _ := newTestWebhook(t)
+
+	trigger := &types.WebhookTrigger{
+		Enabled:        true,
+		Secret:         "secret",
+		PromptTemplate: "Triage: {{ .title }}",
+	}
+
+	// The invocation is recorded without running the app
+	storeMock.EXPECT().CreateTriggerExecution(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, execution *types.TriggerExecution) (*types.TriggerExecution, error) {
+			assert.Equal(t, types.TriggerTypeWebhook, execution.Trigger)
+			assert.Equal(t, "not json", execution.Input)
+			execution.ID = system.GenerateTriggerExecutionID()
+			return execution, nil
+		})
+
+	result, err := w.Invoke(context.Background(), testApp(), trigger, []byte(`not json`))
+	require.NoError(t, err)
+
+	assert.Equal(t, types.TriggerExecutionStatusError, result.Status)
+	assert.Contains(t, result.Error, "invalid JSON payload")
+	assert.Empty(t, result.SessionID)
+}
+
+func TestInvokeAsync_Callback(t *testing.T) {
+	callbacks := make(chan *Result, 1)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "ci", r.Header.Get("X-Source"))
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.NoError(t, VerifySignature("secret", body, r.Header))
+
+		var result Result
+		require.NoError(t, json.Unmarshal(body, &result))
+
+		callbacks <- &result
+	}))
+	defer srv.Close()
+
+	w, storeMock, openAiClient := newTestWebhook(t)
+	w.httpClient = srv.Client()
+
+	app := testApp()
+	trigger := &types.WebhookTrigger{
+		Enabled:         true,
+		Secret:          "secret",
+		CallbackURL:     srv.URL,
+		CallbackHeaders: map[string]string{"X-Source": "ci"},
+	}
+
+	storeMock.EXPECT().GetApp(gomock.Any(), "app_1").Return(app, nil)
+	expectExecution(storeMock)
+
+	openAiClient.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).
+		Return(openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{
+				{Message: openai.ChatCompletionMessage{Content: "Looks good."}},
+			},
+		}, nil)
+
+	result, err := w.InvokeAsync(context.Background(), app, trigger, []byte(`review the build`))
+	require.NoError(t, err)
+	assert.Equal(t, types.TriggerExecutionStatusRunning, result.Status)
+
+	select {
+	case callback := <-callbacks:
+		assert.Equal(t, result.ExecutionID, callback.ExecutionID)
+		assert.Equal(t, types.TriggerExecutionStatusSuccess, callback.Status)
+		assert.Equal(t, "Looks good.", callback.Output)
+	case <-time.After(5 * time.Second):
+		t.Fatal("callback not received")
+	}
+}
@@ -10,6 +10,8 @@ const (
 	SessionOriginTypeNone        SessionOriginType = ""
 	SessionOriginTypeUserCreated SessionOriginType = "user_created"
 	SessionOriginTypeCloned      SessionOriginType = "cloned"
+	SessionOriginTypeTrigger     SessionOriginType = "trigger"
+	SessionOriginTypeImported    SessionOriginType = "imported"
 )
 
 // this will change from finetune to inference (so the user can chat to their fine tuned model)
@@ -106,6 +108,7 @@ type OwnerType string
 const (
 	OwnerTypeUser   OwnerType = "user"
 	OwnerTypeSystem OwnerType = "system"
+	OwnerTypeOrg    OwnerType = "org"
 )
 
 type PaymentType string
@@ -259,6 +262,7 @@ type FileStoreType string
 const (
 	FileStoreTypeLocalFS  FileStoreType = "fs"
 	FileStoreTypeLocalGCS FileStoreType = "gcs"
+	FileStoreTypeS3       FileStoreType = "s3"
 )
 
 type APIKeyType string
@@ -273,6 +277,28 @@ const (
 	APIKeyType_App APIKeyType = "app"
 )
 
+type APIKeyScope string
+
+const (
+	// chat completions and the sessions chat API
+	APIKeyScopeChat APIKeyScope = "chat"
+	// reading the sessions of the owner
+	APIKeyScopeSessionsRead   APIKeyScope = "sessions:read"
+	APIKeyScopeKnowledgeRead  APIKeyScope = "knowledge:read"
+	APIKeyScopeKnowledgeAdmin APIKeyScope = "knowledge:admin"
+	APIKeyScopeAppsRead       APIKeyScope = "apps:read"
+	APIKeyScopeAppsAdmin      APIKeyScope = "apps:admin"
+)
+
+var APIKeyScopes = []APIKeyScope{
+	APIKeyScopeChat,
+	APIKeyScopeSessionsRead,
+	APIKeyScopeKnowledgeRead,
+	APIKeyScopeKnowledgeAdmin,
+	APIKeyScopeAppsRead,
+	APIKeyScopeAppsAdmin,
+}
+
 type DataEntityType string
 
 const (
@@ -316,9 +342,33 @@ const (
 	TokenTypeNone     TokenType = ""
 	TokenTypeRunner   TokenType = "runner"
 	TokenTypeKeycloak TokenType = "keycloak"
+	TokenTypeOIDC     TokenType = "oidc"
 	TokenTypeAPIKey   TokenType = "api_key"
 )
 
+type AuthProvider string
+
+const (
+	AuthProviderKeycloak AuthProvider = "keycloak"
+	AuthProviderOIDC     AuthProvider = "oidc"
+)
+
+type TriggerType string
+
+const (
+	TriggerTypeDiscord TriggerType = "discord"
+	TriggerTypeCron    TriggerType = "cron"
+	TriggerTypeWebhook TriggerType = "webhook"
+)
+
+type TriggerExecutionStatus string
+
+const (
+	TriggerExecutionStatusRunning TriggerExecutionStatus = "running"
+	TriggerExecutionStatusSuccess TriggerExecutionStatus = "success"
+	TriggerExecutionStatusError   TriggerExecutionStatus = "error"
+)
+
 type ScriptRunState string
 
 const (
@@ -73,6 +73,14 @@ type Knowledge struct {
 	// or 'every 5m' or '0 0 * * *' for daily at midnight.
 	RefreshSchedule string `json:"refresh_schedule" yaml:"refresh_schedule"`
 
+	// SourceRevision is the revision of the source that was last indexed, for
+	// example the commit of a GitHub repository. Refreshes are skipped when
+	// nothing changed since.
+	SourceRevision string `json:"source_revision"`
+	// GithubWebhookSecret verifies the push events of the GitHub repository
+	// of the source, the webhook is added when the repository is first cloned
+	GithubWebhookSecret string `json:"-"`
+
 	// Size of the knowledge in bytes
 	Size int64 `json:"size"`
 
@@ -121,6 +129,7 @@ type KnowledgeSource struct {
 	S3        *KnowledgeSourceS3             `json:"s3"`
 	GCS       *KnowledgeSourceGCS            `json:"gcs"`
 	Web       *KnowledgeSourceWeb            `json:"web"`
+	Github    *KnowledgeSourceGithub         `json:"github" yaml:"github"`
 	Content   *string                        `json:"text"`
 }
 
@@ -171,8 +180,8 @@ type Firecrawl struct {
 }
 
 type KnowledgeSourceWebAuth struct {
-	Username string
-	Password string
+	Username string `json:"username" yaml:"username"`
+	Password string `json:"password" yaml:"password"`
 }
 
 type KnowledgeSourceHelixFilestore struct {
@@ -181,22 +190,48 @@ type KnowledgeSourceHelixFilestore struct {
 
 // KnowledgeSourceS3 authentication through AWS IAM role
 type KnowledgeSourceS3 struct {
-	Bucket string `json:"bucket"`
-	Path   string `json:"path"`
+	Bucket string `json:"bucket" yaml:"bucket"`
+	Path   string `json:"path" yaml:"path"`
+	Region string `json:"region" yaml:"region"`
+	// Endpoint is optional, set it to use an S3 compatible service such as MinIO
+	Endpoint string `json:"endpoint" yaml:"endpoint"`
+	// UsePathStyle addresses the bucket as part of the path instead of the host,
+	// most S3 compatible services require this
+	UsePathStyle bool `json:"use_path_style" yaml:"use_path_style"`
 }
 
 // KnowledgeSourceGCS authentication through GCP service account
 type KnowledgeSourceGCS struct {
-	Bucket string `json:"bucket"`
-	Path   string `json:"path"`
+	Bucket string `json:"bucket" yaml:"bucket"`
+	Path   string `json:"path" yaml:"path"`
 }
 
+// KnowledgeSourceGithub clones the repository using the owner's GitHub
+// integration token, public repositories can be used without it
 type KnowledgeSourceGithub struct {
-	Owner            string   `json:"owner"`
-	Repository       string   `json:"repository"`
-	Branch           string   `json:"branch"`
-	FilterPaths      []string `json:"filter_paths"`
-	FilterExtensions []string `json:"filter_extensions"`
+	Owner      string `json:"owner" yaml:"owner"`
+	Repository string `json:"repository" yaml:"repository"`
+	Branch     string `json:"branch" yaml:"branch"` // Default branch of the repository if empty
+	// FilterPaths limits the indexed files to these directories or files, for example 'docs/'
+	FilterPaths []string `json:"filter_paths" yaml:"filter_paths"`
+	// FilterExtensions limits the indexed files to these extensions, for example '.md'
+	FilterExtensions []string `json:"filter_extensions" yaml:"filter_extensions"`
+}
+
+// KnowledgeSourceObject is a single object (for example a file in a bucket) that
+// was extracted for the knowledge. We keep the extracted contents together with
+// the object revision so that refreshes only need to extract the objects that
+// have changed since the last run.
+type KnowledgeSourceObject struct {
+	ID          string    `json:"id" gorm:"primaryKey"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+	KnowledgeID string    `json:"knowledge_id" gorm:"index"`
+	Key         string    `json:"key"`       // Object key, file path
+	Revision    string    `json:"revision"`  // ETag, generation
+	Size        int64     `json:"size"`      // Size of the original object
+	Extracted   bool      `json:"extracted"` // Whether Data holds extracted text or the raw object
+	Data        []byte    `json:"-"`
 }
 
 // CrawledDocument used internally to work with the crawled data
@@ -0,0 +1,82 @@
+package types
+
+import "time"
+
+type OrganizationRole string
+
+const (
+	OrganizationRoleOwner  OrganizationRole = "owner"
+	OrganizationRoleAdmin  OrganizationRole = "admin"
+	OrganizationRoleMember OrganizationRole = "member"
+)
+
+var organizationRoleRanks = map[OrganizationRole]int{
+	OrganizationRoleMember: 1,
+	OrganizationRoleAdmin:  2,
+	OrganizationRoleOwner:  3,
+}
+
+func (r OrganizationRole) Valid() bool {
+	_, ok := organizationRoleRanks[r]
+	return ok
+}
+
+// Includes reports whether the role has all the permissions of the other role,
+// owners can do everything admins can and admins everything members can
+func (r OrganizationRole) Includes(other OrganizationRole) bool {
+	rank, ok := organizationRoleRanks[r]
+	if !ok {
+		return false
+	}
+	return rank >= organizationRoleRanks[other]
+}
+
+// Organization owns apps, knowledge, tools, API keys and sessions that all of
+// its members share
+type Organization struct {
+	ID      string    `json:"id" gorm:"primaryKey"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+	Name    string    `json:"name" gorm:"uniqueIndex"`
+	Owner   string    `json:"owner" gorm:"index"` // User ID of the creator
+}
+
+type OrganizationMembership struct {
+	OrganizationID string           `json:"organization_id" gorm:"primaryKey"`
+	UserID         string           `json:"user_id" gorm:"primaryKey;index"`
+	Created        time.Time        `json:"created"`
+	Updated        time.Time        `json:"updated"`
+	Role           OrganizationRole `json:"role"`
+}
+
+// HasOwnerRole reports whether the user can act on a resource of the owner. The
+// resources of an organization need at least the role in it, the resources of
+// a user can only be used by that user.
+func (u *User) HasOwnerRole(owner string, ownerType OwnerType, role OrganizationRole) bool {
+	if owner == "" {
+		return false
+	}
+
+	if ownerType != OwnerTypeOrg {
+		return owner == u.ID
+	}
+
+	// Organization API keys act as the organization itself
+	if u.Type == OwnerTypeOrg {
+		return owner == u.ID
+	}
+
+	member, ok := u.Organizations[owner]
+	return ok && member.Includes(role)
+}
+
+// AsOrganization returns the user acting on behalf of the organization, the
+// resources it creates and lists are the organization's
+func (u *User) AsOrganization(organizationID string) *User {
+	orgUser := *u
+	orgUser.ID = organizationID
+	orgUser.Type = OwnerTypeOrg
+	orgUser.Admin = false
+	orgUser.Organizations = nil
+	return &orgUser
+}
@@ -1,5 +1,7 @@
 package types
 
+import "time"
+
 type Provider string
 
 const (
@@ -7,3 +9,53 @@ const (
 	ProviderTogetherAI Provider = "togetherai"
 	ProviderHelix      Provider = "helix"
 )
+
+// IsBuiltin returns true for the providers configured with the environment variables
+func (p Provider) IsBuiltin() bool {
+	switch p {
+	case ProviderOpenAI, ProviderTogetherAI, ProviderHelix:
+		return true
+	}
+	return false
+}
+
+type ProviderEndpointType string
+
+const (
+	ProviderEndpointTypeOpenAI ProviderEndpointType = "openai"
+	ProviderEndpointTypeAzure  ProviderEndpointType = "azure"
+)
+
+// ProviderEndpoint is an OpenAI compatible endpoint registered at runtime
+// (e.g. vLLM, Ollama or Azure OpenAI), apps use it by setting its name as
+// the provider of the assistant
+type ProviderEndpoint struct {
+	ID          string               `json:"id" gorm:"primaryKey"`
+	Created     time.Time            `json:"created"`
+	Updated     time.Time            `json:"updated"`
+	Name        string               `json:"name" gorm:"uniqueIndex"`
+	Description string               `json:"description"`
+	Type        ProviderEndpointType `json:"type"`
+	BaseURL     string               `json:"base_url"`
+	// APIKey is encrypted in the database and never returned by the API
+	APIKey string `json:"api_key,omitempty"`
+	// APIVersion of the Azure OpenAI API
+	APIVersion string `json:"api_version,omitempty"`
+	// Models the endpoint can be used with, all models if empty
+	Models StringList `json:"models"`
+	// Headers sent with every request to the endpoint
+	Headers StringMap `json:"headers"`
+}
+
+// AllowsModel returns true if the model is in the allowlist of the endpoint
+func (e *ProviderEndpoint) AllowsModel(model string) bool {
+	if len(e.Models) == 0 {
+		return true
+	}
+	for _, m := range e.Models {
+		if m == model {
+			return true
+		}
+	}
+	return false
+}
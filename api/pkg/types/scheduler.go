@@ -0,0 +1,89 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+type SchedulerSlotState string
+
+const (
+	SchedulerSlotStateScheduled SchedulerSlotState = "scheduled" // work is waiting for the runner to pick it up
+	SchedulerSlotStateRunning   SchedulerSlotState = "running"   // the runner is working on it
+	SchedulerSlotStateIdle      SchedulerSlotState = "idle"      // the model is warm, waiting for more work
+)
+
+// SchedulerSlot is the persisted state of a scheduler slot, the control plane
+// restores the slots on startup and reconciles them with the runners
+type SchedulerSlot struct {
+	ID       string             `json:"id" gorm:"primaryKey"`
+	Created  time.Time          `json:"created"`
+	Updated  time.Time          `json:"updated"`
+	RunnerID string             `json:"runner_id" gorm:"index"`
+	State    SchedulerSlotState `json:"state"`
+	// IsNew is set until the runner starts the first work, the model might
+	// not be loaded yet
+	IsNew bool              `json:"is_new"`
+	Work  SchedulerSlotWork `json:"work" gorm:"type:jsonb"`
+}
+
+// SchedulerSlotWork is the last work assigned to the slot, either an LLM
+// inference request or a session
+type SchedulerSlotWork struct {
+	LLMInferenceRequest *RunnerLLMInferenceRequest `json:"llm_inference_request,omitempty"`
+	Session             *Session                   `json:"session,omitempty"`
+}
+
+func (m SchedulerSlotWork) Value() (driver.Value, error) {
+	j, err := json.Marshal(m)
+	return j, err
+}
+
+func (t *SchedulerSlotWork) Scan(src interface{}) error {
+	source, ok := src.([]byte)
+	if !ok {
+		return errors.New("type assertion .([]byte) failed.")
+	}
+	var result SchedulerSlotWork
+	if err := json.Unmarshal(source, &result); err != nil {
+		return err
+	}
+	*t = result
+	return nil
+}
+
+func (SchedulerSlotWork) GormDataType() string {
+	return "json"
+}
+
+// LLMInferenceQueueItem is an LLM inference request waiting in the queue of the
+// control plane, the queue is restored on startup
+type LLMInferenceQueueItem struct {
+	ID      string                    `json:"id" gorm:"primaryKey"` // The request ID
+	Created time.Time                 `json:"created"`
+	Request RunnerLLMInferenceRequest `json:"request" gorm:"type:jsonb"`
+}
+
+func (m RunnerLLMInferenceRequest) Value() (driver.Value, error) {
+	j, err := json.Marshal(m)
+	return j, err
+}
+
+func (t *RunnerLLMInferenceRequest) Scan(src interface{}) error {
+	source, ok := src.([]byte)
+	if !ok {
+		return errors.New("type assertion .([]byte) failed.")
+	}
+	var result RunnerLLMInferenceRequest
+	if err := json.Unmarshal(source, &result); err != nil {
+		return err
+	}
+	*t = result
+	return nil
+}
+
+func (RunnerLLMInferenceRequest) GormDataType() string {
+	return "json"
+}
@@ -1,18 +1,27 @@
 package types
 
 import (
+	"crypto/sha256"
 	"database/sql"
 	"database/sql/driver"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"strings"
 	"time"
 
 	openai "github.com/sashabaranov/go-openai"
 	"gorm.io/datatypes"
 )
 
+// Interaction is stored in its own table, IDs are only unique within a
+// session as cloned sessions keep the IDs of the interactions they copied
 type Interaction struct {
-	ID        string      `json:"id"`
+	ID        string `json:"id" gorm:"primaryKey"`
+	SessionID string `json:"session_id" gorm:"primaryKey"`
+	// Seq orders the interactions of a session, it's set by the store when
+	// the interaction is appended
+	Seq       int64       `json:"-" gorm:"autoIncrement;index"`
 	Created   time.Time   `json:"created"`
 	Updated   time.Time   `json:"updated"`
 	Scheduled time.Time   `json:"scheduled"`
@@ -25,41 +34,47 @@ type Interaction struct {
 	// to get down to what actually matters
 	Mode SessionMode `json:"mode"`
 	// the ID of the runner that processed this interaction
-	Runner         string         `json:"runner"`          // e.g. 0
-	Message        string         `json:"message"`         // e.g. Prove pythagoras
-	ResponseFormat ResponseFormat `json:"response_format"` // e.g. json
-
-	DisplayMessage string            `json:"display_message"` // if this is defined, the UI will always display it instead of the message (so we can augment the internal prompt with RAG context)
-	Progress       int               `json:"progress"`        // e.g. 0-100
-	Files          []string          `json:"files"`           // list of filepath paths
-	Finished       bool              `json:"finished"`        // if true, the message has finished being written to, and is ready for a response (e.g. from the other participant)
-	Metadata       map[string]string `json:"metadata"`        // different modes and models can put values here - for example, the image fine tuning will keep labels here to display in the frontend
+	Runner         string         `json:"runner"`                                            // e.g. 0
+	Message        string         `json:"message"`                                           // e.g. Prove pythagoras
+	ResponseFormat ResponseFormat `json:"response_format" gorm:"type:jsonb;serializer:json"` // e.g. json
+
+	DisplayMessage string            `json:"display_message"`                            // if this is defined, the UI will always display it instead of the message (so we can augment the internal prompt with RAG context)
+	Progress       int               `json:"progress"`                                   // e.g. 0-100
+	Files          []string          `json:"files" gorm:"type:jsonb;serializer:json"`    // list of filepath paths
+	Finished       bool              `json:"finished"`                                   // if true, the message has finished being written to, and is ready for a response (e.g. from the other participant)
+	Metadata       map[string]string `json:"metadata" gorm:"type:jsonb;serializer:json"` // different modes and models can put values here - for example, the image fine tuning will keep labels here to display in the frontend
 	State          InteractionState  `json:"state"`
 	Status         string            `json:"status"`
 	Error          string            `json:"error"`
 	// we hoist this from files so a single interaction knows that it "Created a finetune file"
 	LoraDir             string                     `json:"lora_dir"`
-	DataPrepChunks      map[string][]DataPrepChunk `json:"data_prep_chunks"`
+	DataPrepChunks      map[string][]DataPrepChunk `json:"data_prep_chunks" gorm:"type:jsonb;serializer:json"`
 	DataPrepStage       TextDataPrepStage          `json:"data_prep_stage"`
 	DataPrepLimited     bool                       `json:"data_prep_limited"` // If true, the data prep is limited to a certain number of chunks due to quotas
 	DataPrepLimit       int                        `json:"data_prep_limit"`   // If true, the data prep is limited to a certain number of chunks due to quotas
 	DataPrepTotalChunks int                        `json:"data_prep_total_chunks"`
 
-	RagResults []*SessionRAGResult `json:"rag_results"`
+	RagResults []*SessionRAGResult `json:"rag_results" gorm:"type:jsonb;serializer:json"`
 
 	// Model function calling, not to be mistaken with Helix tools
-	Tools []openai.Tool `json:"tools"`
+	Tools []openai.Tool `json:"tools" gorm:"type:jsonb;serializer:json"`
 
 	// This can be either a string or an ToolChoice object.
-	ToolChoice any `json:"tool_choice,omitempty"`
+	ToolChoice any `json:"tool_choice,omitempty" gorm:"type:jsonb;serializer:json"`
 
 	// For Role=assistant prompts this may be set to the tool calls generated by the model, such as function calls.
-	ToolCalls []openai.ToolCall `json:"tool_calls,omitempty"`
+	ToolCalls []openai.ToolCall `json:"tool_calls,omitempty" gorm:"type:jsonb;serializer:json"`
 
 	// For Role=tool prompts this should be set to the ID given in the assistant's prior request to call a tool.
 	ToolCallID string `json:"tool_call_id,omitempty"`
 
-	Usage Usage `json:"usage"`
+	Usage Usage `json:"usage" gorm:"type:jsonb;serializer:json"`
+}
+
+type InteractionsList struct {
+	// the total number of interactions of the session
+	Counter      *Counter       `json:"counter"`
+	Interactions []*Interaction `json:"interactions"`
 }
 
 type ResponseFormatType string
@@ -83,6 +98,8 @@ type SessionOrigin struct {
 	Type                SessionOriginType `json:"type"`
 	ClonedSessionID     string            `json:"cloned_session_id"`
 	ClonedInteractionID string            `json:"cloned_interaction_id"`
+	// the id of the session in the instance it was exported from
+	ImportedSessionID string `json:"imported_session_id,omitempty"`
 }
 
 type TextSplitterType string
@@ -104,6 +121,11 @@ type RAGSettings struct {
 	DisableDownloading bool             `json:"disable_downloading" yaml:"disable_downloading"` // if true, we will not download the file and send the URL to the RAG indexing endpoint
 	PromptTemplate     string           `json:"prompt_template" yaml:"prompt_template"`         // the prompt template to use for the RAG query
 
+	Hybrid      bool     `json:"hybrid" yaml:"hybrid"`                                 // if true, keyword (BM25) and vector search scores are blended
+	HybridAlpha *float64 `json:"hybrid_alpha,omitempty" yaml:"hybrid_alpha,omitempty"` // the weight of the vector score in hybrid mode, between 0 (keyword only) and 1 - will default to 0.5 if unset
+
+	Rerank RAGRerankSettings `json:"rerank" yaml:"rerank"` // reorders the results with a reranking model before they are truncated to results_count
+
 	// RAG endpoint configuration if used with a custom RAG service
 	IndexURL  string `json:"index_url" yaml:"index_url"`   // the URL of the index endpoint (defaults to Helix RAG_INDEX_URL env var)
 	QueryURL  string `json:"query_url" yaml:"query_url"`   // the URL of the query endpoint (defaults to Helix RAG_QUERY_URL env var)
@@ -116,6 +138,13 @@ type RAGSettings struct {
 	} `json:"typesense" yaml:"typesense"`
 }
 
+type RAGRerankSettings struct {
+	Enabled  bool   `json:"enabled" yaml:"enabled"`
+	Provider string `json:"provider" yaml:"provider"` // the provider of the reranking model - will default to the inference provider
+	Model    string `json:"model" yaml:"model"`       // the reranking model - will default to the tools model
+	TopN     int    `json:"top_n" yaml:"top_n"`       // the number of results to rerank - will default to 20
+}
+
 func (m RAGSettings) Value() (driver.Value, error) {
 	j, err := json.Marshal(m)
 	return j, err
@@ -152,11 +181,13 @@ type SessionRAGIndexChunk struct {
 // the query we post to llamaindex to get results back from a user
 // prompt against a rag enabled session
 type SessionRAGQuery struct {
-	Prompt            string  `json:"prompt"`
-	DataEntityID      string  `json:"data_entity_id"`
-	DistanceThreshold float64 `json:"distance_threshold"`
-	DistanceFunction  string  `json:"distance_function"`
-	MaxResults        int     `json:"max_results"`
+	Prompt            string   `json:"prompt"`
+	DataEntityID      string   `json:"data_entity_id"`
+	DistanceThreshold float64  `json:"distance_threshold"`
+	DistanceFunction  string   `json:"distance_function"`
+	MaxResults        int      `json:"max_results"`
+	Hybrid            bool     `json:"hybrid"`
+	HybridAlpha       *float64 `json:"hybrid_alpha,omitempty"` // DefaultHybridAlpha if nil
 }
 
 type DeleteIndexRequest struct {
@@ -176,6 +207,13 @@ type SessionRAGResult struct {
 	ContentOffset   int     `json:"content_offset"`
 	Content         string  `json:"content"`
 	Distance        float64 `json:"distance"`
+
+	// Scores of the retrieval steps, which ones are set depends on the
+	// RAG backend and the settings
+	VectorScore  float64 `json:"vector_score,omitempty"`
+	KeywordScore float64 `json:"keyword_score,omitempty"`
+	HybridScore  float64 `json:"hybrid_score,omitempty"`
+	RerankScore  float64 `json:"rerank_score,omitempty"`
 }
 
 // gives us a quick way to add settings
@@ -185,6 +223,7 @@ type SessionMetadata struct {
 	Shared                  bool              `json:"shared"`
 	Avatar                  string            `json:"avatar"`
 	Priority                bool              `json:"priority"`
+	PriorityClass           string            `json:"priority_class,omitempty"` // scheduling priority class, see HELIX_PRIORITY_CLASSES
 	DocumentIDs             map[string]string `json:"document_ids"`
 	DocumentGroupID         string            `json:"document_group_id"`
 	ManuallyReviewQuestions bool              `json:"manually_review_questions"`
@@ -329,6 +368,7 @@ type InternalSessionRequest struct {
 	OwnerType               OwnerType
 	UserInteractions        []*Interaction
 	Priority                bool
+	PriorityClass           string
 	ManuallyReviewQuestions bool
 	RAGEnabled              bool
 	TextFinetuneEnabled     bool
@@ -374,9 +414,9 @@ type Session struct {
 	// currently the only place you can do inference on a finetune is within the
 	// session where the finetune was generated
 	LoraDir string `json:"lora_dir"`
-	// for now we just whack the entire history of the interaction in here, json
-	// style
-	Interactions Interactions `json:"interactions" gorm:"type:jsonb"`
+	// the interactions are stored in their own table, the store loads them
+	// with the session
+	Interactions Interactions `json:"interactions" gorm:"-"`
 	// uuid of owner entity
 	Owner string `json:"owner"`
 	// e.g. user, system, org
@@ -473,6 +513,23 @@ func (d *Duration) UnmarshalJSON(b []byte) error {
 	}
 }
 
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return time.Duration(d).String(), nil
+}
+
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value string
+	if err := unmarshal(&value); err != nil {
+		return err
+	}
+	tmp, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(tmp)
+	return nil
+}
+
 type SessionFilter struct {
 	// e.g. inference, finetune
 	Mode SessionMode `json:"mode"`
@@ -511,12 +568,133 @@ type APIKey struct {
 	Name      string          `json:"name"`
 	Type      APIKeyType      `json:"type" gorm:"default:api"`
 	AppID     *sql.NullString `json:"app_id"`
+	// PriorityClass of the work started with the key, only admins can set it
+	PriorityClass string `json:"priority_class,omitempty"`
+	// Scopes limit what the key can be used for, keys without scopes have
+	// the full rights of the owner
+	Scopes APIKeyScopeList `json:"scopes,omitempty"`
+	// ExpiresAt is when the key stops working, keys without it don't expire
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// AllowedIPs are the IP addresses and CIDR ranges the key can be used from
+	AllowedIPs StringList `json:"allowed_ips,omitempty"`
+	// Rate limits of the key, zero means unlimited
+	RequestsPerMinute int        `json:"requests_per_minute,omitempty"`
+	TokensPerMinute   int        `json:"tokens_per_minute,omitempty"`
+	LastUsed          *time.Time `json:"last_used,omitempty"`
 }
 
 func (APIKey) TableName() string {
 	return "api_key"
 }
 
+// APIKeyID identifies the key in the usage ledger and the LLM calls without
+// storing the key itself, it's the start of the SHA-256 hash of the key
+func APIKeyID(key string) string {
+	if key == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// HasScope returns true if the key has the scope, keys without scopes have all of them
+func (k *APIKey) HasScope(scope APIKeyScope) bool {
+	if len(k.Scopes) == 0 {
+		return true
+	}
+
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type APIKeyScopeList []APIKeyScope
+
+func (m APIKeyScopeList) Value() (driver.Value, error) {
+	j, err := json.Marshal(m)
+	return j, err
+}
+
+func (t *APIKeyScopeList) Scan(src interface{}) error {
+	if src == nil {
+		*t = nil
+		return nil
+	}
+	source, ok := src.([]byte)
+	if !ok {
+		return errors.New("type assertion .([]byte) failed.")
+	}
+	var result []APIKeyScope
+	if err := json.Unmarshal(source, &result); err != nil {
+		return err
+	}
+	*t = result
+	return nil
+}
+
+func (APIKeyScopeList) GormDataType() string {
+	return "json"
+}
+
+type StringList []string
+
+func (m StringList) Value() (driver.Value, error) {
+	j, err := json.Marshal(m)
+	return j, err
+}
+
+func (t *StringList) Scan(src interface{}) error {
+	if src == nil {
+		*t = nil
+		return nil
+	}
+	source, ok := src.([]byte)
+	if !ok {
+		return errors.New("type assertion .([]byte) failed.")
+	}
+	var result []string
+	if err := json.Unmarshal(source, &result); err != nil {
+		return err
+	}
+	*t = result
+	return nil
+}
+
+func (StringList) GormDataType() string {
+	return "json"
+}
+
+type StringMap map[string]string
+
+func (m StringMap) Value() (driver.Value, error) {
+	j, err := json.Marshal(m)
+	return j, err
+}
+
+func (t *StringMap) Scan(src interface{}) error {
+	if src == nil {
+		*t = nil
+		return nil
+	}
+	source, ok := src.([]byte)
+	if !ok {
+		return errors.New("type assertion .([]byte) failed.")
+	}
+	var result map[string]string
+	if err := json.Unmarshal(source, &result); err != nil {
+		return err
+	}
+	*t = result
+	return nil
+}
+
+func (StringMap) GormDataType() string {
+	return "json"
+}
+
 type OwnerContext struct {
 	Owner     string
 	OwnerType OwnerType
@@ -537,11 +715,15 @@ type UserConfig struct {
 }
 
 // this lives in the database
-// the ID is the keycloak user ID
+// the ID is the keycloak or OIDC user ID
 // there might not be a record for every user
 type UserMeta struct {
 	ID     string     `json:"id"`
 	Config UserConfig `json:"config"`
+	// the profile is only kept for the OIDC users, keycloak is queried for the others
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	FullName string `json:"full_name"`
 }
 
 // this is given to the frontend as user context
@@ -560,6 +742,10 @@ type User struct {
 	Admin bool
 	// if the token is associated with an app
 	AppID string
+	// the scheduling priority class of the API key
+	PriorityClass string
+	// the API key the request was made with, its scopes and limits apply
+	APIKey *APIKey
 	// these are set by the keycloak user based on the token
 	// if it's an app token - the keycloak user is loaded from the owner of the app
 	// if it's a runner token - these values will be empty
@@ -568,6 +754,9 @@ type User struct {
 	Email    string
 	Username string
 	FullName string
+	// the roles of the user in the organizations they are a member of,
+	// keyed by the organization ID
+	Organizations map[string]OrganizationRole
 }
 
 // a single envelope that is broadcast to users
@@ -682,6 +871,57 @@ type ServerConfigForFrontend struct {
 	AppsEnabled             bool   `json:"apps_enabled"`
 	RudderStackWriteKey     string `json:"rudderstack_write_key"`
 	RudderStackDataPlaneURL string `json:"rudderstack_data_plane_url"`
+	// AuthProvider tells the web UI how to log in, the OIDC settings are only
+	// set for the oidc provider
+	AuthProvider AuthProvider `json:"auth_provider"`
+	OIDCIssuer   string       `json:"oidc_issuer,omitempty"`
+	OIDCClientID string       `json:"oidc_client_id,omitempty"`
+	OIDCScopes   string       `json:"oidc_scopes,omitempty"`
+}
+
+// SessionSearchResult is an interaction that matches a session search
+type SessionSearchResult struct {
+	SessionID     string      `json:"session_id"`
+	SessionName   string      `json:"session_name"`
+	AppID         string      `json:"app_id"`
+	Mode          SessionMode `json:"mode"`
+	ModelName     string      `json:"model_name"`
+	InteractionID string      `json:"interaction_id"`
+	Creator       CreatorType `json:"creator"`
+	Created       time.Time   `json:"created"`
+	// Snippet of the interaction message with the matching terms
+	// highlighted as **term**
+	Snippet string  `json:"snippet"`
+	Rank    float64 `json:"rank"`
+}
+
+type SessionSearchResults struct {
+	// the total number of interactions that match the search
+	Counter *Counter               `json:"counter"`
+	Results []*SessionSearchResult `json:"results"`
+}
+
+type SessionExportFormat string
+
+const (
+	// SessionExportFormatTar is a self-contained bundle with the session, a
+	// markdown transcript and the session files
+	SessionExportFormatTar      SessionExportFormat = "tar"
+	SessionExportFormatJSON     SessionExportFormat = "json"
+	SessionExportFormatMarkdown SessionExportFormat = "markdown"
+)
+
+// SessionExportVersion is bumped when the export can't be imported by older versions
+const SessionExportVersion = 1
+
+// SessionExport is the portable form of a session, the file paths are
+// relative to the session folder so it can be imported under another owner
+// or into another instance
+type SessionExport struct {
+	Version      int       `json:"version"`
+	HelixVersion string    `json:"helix_version"`
+	Exported     time.Time `json:"exported"`
+	Session      *Session  `json:"session"`
 }
 
 // a short version of a session that we keep for the dashboard
@@ -700,9 +940,10 @@ type SessionSummary struct {
 	Owner         string      `json:"owner"`
 	LoraDir       string      `json:"lora_dir,omitempty"`
 	// this is either the prompt or the summary of the training data
-	Summary  string `json:"summary"`
-	Priority bool   `json:"priority"`
-	AppID    string `json:"app_id,omitempty"`
+	Summary       string `json:"summary"`
+	Priority      bool   `json:"priority"`
+	PriorityClass string `json:"priority_class,omitempty"`
+	AppID         string `json:"app_id,omitempty"`
 }
 
 type ModelInstanceState struct {
@@ -737,12 +978,26 @@ type RunnerState struct {
 	Labels              map[string]string     `json:"labels"`
 	ModelInstances      []*ModelInstanceState `json:"model_instances"`
 	SchedulingDecisions []string              `json:"scheduling_decisions"`
+	// PreemptedSessions is set in the response to the state report, the runner
+	// stops the model instances working on these sessions
+	PreemptedSessions []string `json:"preempted_sessions,omitempty"`
 }
 
 type DashboardData struct {
 	SessionQueue              []*SessionSummary           `json:"session_queue"`
 	Runners                   []*RunnerState              `json:"runners"`
 	GlobalSchedulingDecisions []*GlobalSchedulingDecision `json:"global_scheduling_decisions"`
+	// Cache stats are only set when the response cache is enabled
+	Cache *CacheStats `json:"cache,omitempty"`
+}
+
+// CacheStats are the hits and misses of the response cache since the start
+type CacheStats struct {
+	Entries      int     `json:"entries"`
+	Hits         int64   `json:"hits"`
+	SemanticHits int64   `json:"semantic_hits"`
+	Misses       int64   `json:"misses"`
+	HitRate      float64 `json:"hit_rate"`
 }
 
 type GlobalSchedulingDecision struct {
@@ -753,6 +1008,11 @@ type GlobalSchedulingDecision struct {
 	ModelName     string        `json:"model_name"`
 	Mode          SessionMode   `json:"mode"`
 	Filter        SessionFilter `json:"filter"`
+	Owner         string        `json:"owner,omitempty"`
+	PriorityClass string        `json:"priority_class,omitempty"`
+	// Reason explains the placement, e.g. a warm slot or the preempted work
+	Reason    string   `json:"reason,omitempty"`
+	Preempted []string `json:"preempted,omitempty"`
 }
 
 // keep track of the state of the data prep
@@ -795,7 +1055,8 @@ func HistoryFromChatCompletionRequest(req openai.ChatCompletionRequest) []*ToolH
 
 	// Copy the messages from the request into history messages
 	for _, message := range req.Messages {
-		if message.Content == "" {
+		content := ChatMessageText(message)
+		if content == "" {
 			continue
 		}
 		if message.Role == openai.ChatMessageRoleSystem {
@@ -805,13 +1066,30 @@ func HistoryFromChatCompletionRequest(req openai.ChatCompletionRequest) []*ToolH
 		}
 		history = append(history, &ToolHistoryMessage{
 			Role:    string(message.Role),
-			Content: message.Content,
+			Content: content,
 		})
 	}
 
 	return history
 }
 
+// ChatMessageText returns the text of the message, the text parts of multi
+// content messages (e.g. text with images) are joined with new lines
+func ChatMessageText(message openai.ChatCompletionMessage) string {
+	if len(message.MultiContent) == 0 {
+		return message.Content
+	}
+
+	var texts []string
+	for _, part := range message.MultiContent {
+		if part.Type == openai.ChatMessagePartTypeText && part.Text != "" {
+			texts = append(texts, part.Text)
+		}
+	}
+
+	return strings.Join(texts, "\n")
+}
+
 func HistoryFromInteractions(interactions []*Interaction) []*ToolHistoryMessage {
 	var history []*ToolHistoryMessage
 
@@ -859,6 +1137,7 @@ const (
 	ToolTypeAPI       ToolType = "api"
 	ToolTypeGPTScript ToolType = "gptscript"
 	ToolTypeZapier    ToolType = "zapier"
+	ToolTypeCode      ToolType = "code"
 )
 
 type Tool struct {
@@ -880,6 +1159,7 @@ type ToolConfig struct {
 	API       *ToolApiConfig       `json:"api"`
 	GPTScript *ToolGPTScriptConfig `json:"gptscript"`
 	Zapier    *ToolZapierConfig    `json:"zapier"`
+	Code      *ToolCodeConfig      `json:"code"`
 }
 
 func (m ToolConfig) Value() (driver.Value, error) {
@@ -936,6 +1216,27 @@ type ToolZapierConfig struct {
 	MaxIterations int    `json:"max_iterations"`
 }
 
+type CodeLanguage string
+
+const (
+	CodeLanguagePython     CodeLanguage = "python"
+	CodeLanguageJavaScript CodeLanguage = "javascript"
+)
+
+// ToolCodeConfig lets the assistant write and run code snippets in a sandbox,
+// zero limits are set to the defaults when the tool is validated
+type ToolCodeConfig struct {
+	Languages      []CodeLanguage `json:"languages" yaml:"languages"` // Allowed languages, all of them if empty
+	TimeoutSeconds int            `json:"timeout_seconds" yaml:"timeout_seconds"`
+	MemoryMB       int            `json:"memory_mb" yaml:"memory_mb"`
+	CPUs           float64        `json:"cpus" yaml:"cpus"`
+	// Network access of the sandbox, disabled by default
+	Network bool `json:"network" yaml:"network"`
+	// SessionFiles are copied into the scratch directory of the sandbox and
+	// the files the code writes there are saved back to the session
+	SessionFiles bool `json:"session_files" yaml:"session_files"`
+}
+
 // SessionToolBinding used to add tools to sessions
 type SessionToolBinding struct {
 	SessionID string `gorm:"primaryKey;index"`
@@ -968,6 +1269,13 @@ type AssistantZapier struct {
 	MaxIterations int    `json:"max_iterations" yaml:"max_iterations"`
 }
 
+// AssistantCode lets the assistant run the code it writes in a sandbox
+type AssistantCode struct {
+	Name           string `json:"name" yaml:"name"`
+	Description    string `json:"description" yaml:"description"`
+	ToolCodeConfig `yaml:",inline"`
+}
+
 type AssistantAPI struct {
 	Name        string            `json:"name" yaml:"name"`
 	Description string            `json:"description" yaml:"description"`
@@ -1017,6 +1325,16 @@ type AssistantConfig struct {
 
 	Zapier []AssistantZapier `json:"zapier" yaml:"zapier"`
 
+	// the list of code tools this assistant will use
+	Code []AssistantCode `json:"code" yaml:"code"`
+
+	// AgentMode lets the assistant call several tools in a row, feeding the
+	// results of each call back into the planner, before answering the user
+	AgentMode bool `json:"agent_mode" yaml:"agent_mode"`
+
+	// MaxAgentSteps is the maximum number of tool calls in the agent mode, defaults to 10
+	MaxAgentSteps int `json:"max_agent_steps" yaml:"max_agent_steps"`
+
 	// these are populated from the APIs and GPTScripts on create and update
 	// we include tools in the JSON that we send to the browser
 	// but we don't include it in the yaml which feeds this struct because
@@ -1033,6 +1351,14 @@ type AppHelixConfig struct {
 	ExternalURL string            `json:"external_url" yaml:"external_url"`
 	Assistants  []AssistantConfig `json:"assistants" yaml:"assistants"`
 	Triggers    []Trigger         `json:"triggers" yaml:"triggers"`
+	// PriorityClass of the work started by the app, only admins can set it
+	PriorityClass string `json:"priority_class,omitempty" yaml:"priority_class,omitempty"`
+	// Monthly budgets of the app across all its users, zero is unlimited
+	MonthlyTokenBudget int64   `json:"monthly_token_budget,omitempty" yaml:"monthly_token_budget,omitempty"`
+	MonthlyCostBudget  float64 `json:"monthly_cost_budget,omitempty" yaml:"monthly_cost_budget,omitempty"`
+	// CacheTTL of the cached responses of the app when the response cache is
+	// enabled, zero uses the server default and a negative TTL disables it
+	CacheTTL Duration `json:"cache_ttl,omitempty" yaml:"cache_ttl,omitempty"`
 }
 
 type AppGithubConfigUpdate struct {
@@ -1082,14 +1408,77 @@ type DiscordTrigger struct {
 	ServerName string `json:"server_name" yaml:"server_name"`
 }
 
+type SlackTrigger struct {
+	// Channels are the IDs of the Slack channels where the app answers mentions
+	Channels []string `json:"channels,omitempty" yaml:"channels,omitempty"`
+	// DirectMessages makes the app answer the direct messages to the bot
+	DirectMessages bool `json:"direct_messages,omitempty" yaml:"direct_messages,omitempty"`
+}
+
 type CronTrigger struct {
 	Schedule string `json:"schedule,omitempty"`
 	Input    string `json:"input,omitempty"`
+	// Outputs receive the response of every successful run
+	Outputs []TriggerOutput `json:"outputs,omitempty" yaml:"outputs,omitempty"`
+	// Retry is used when the run or the delivery to an output fails
+	Retry *TriggerRetry `json:"retry,omitempty" yaml:"retry,omitempty"`
+}
+
+// WebhookTrigger lets external systems run the app by POSTing a JSON payload
+// to /api/v1/apps/{id}/webhook
+type WebhookTrigger struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// Secret signs the requests, the X-Helix-Signature header must be
+	// "sha256=" followed by the hex HMAC-SHA256 of the X-Helix-Timestamp
+	// header, a dot and the body. Only the owners of the app can see it.
+	Secret string `json:"secret" yaml:"secret"`
+	// PromptTemplate is a Go template that is rendered with the payload, for example
+	// "Triage the ticket {{ .title }}: {{ .description }}". The raw body is used when empty.
+	PromptTemplate string `json:"prompt_template,omitempty" yaml:"prompt_template,omitempty"`
+	// CallbackURL receives the result, the request is then answered right away.
+	// The result is returned in the response when the callback URL is empty.
+	CallbackURL     string            `json:"callback_url,omitempty" yaml:"callback_url,omitempty"`
+	CallbackHeaders map[string]string `json:"callback_headers,omitempty" yaml:"callback_headers,omitempty"`
+}
+
+// TriggerOutput is where the response of a trigger run is delivered, only one
+// of the outputs should be set
+type TriggerOutput struct {
+	Webhook *WebhookTriggerOutput `json:"webhook,omitempty" yaml:"webhook,omitempty"`
+	Email   *EmailTriggerOutput   `json:"email,omitempty" yaml:"email,omitempty"`
+	Discord *DiscordTriggerOutput `json:"discord,omitempty" yaml:"discord,omitempty"`
+}
+
+type WebhookTriggerOutput struct {
+	URL string `json:"url" yaml:"url"`
+	// Method defaults to POST
+	Method  string            `json:"method,omitempty" yaml:"method,omitempty"`
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	// Body is a Go template that is rendered with the run, for example
+	// {"text": {{ json .Output }}}. The run is sent as JSON when the body is empty.
+	Body string `json:"body,omitempty" yaml:"body,omitempty"`
+}
+
+type EmailTriggerOutput struct {
+	To []string `json:"to" yaml:"to"`
+}
+
+type DiscordTriggerOutput struct {
+	ChannelID string `json:"channel_id" yaml:"channel_id"`
+}
+
+type TriggerRetry struct {
+	// MaxAttempts includes the first attempt, runs are attempted once by default
+	MaxAttempts int `json:"max_attempts,omitempty" yaml:"max_attempts,omitempty"`
+	// Backoff is the delay before the first retry (e.g. 30s), doubled after every attempt
+	Backoff string `json:"backoff,omitempty" yaml:"backoff,omitempty"`
 }
 
 type Trigger struct {
 	Discord *DiscordTrigger `json:"discord,omitempty"`
+	Slack   *SlackTrigger   `json:"slack,omitempty" yaml:"slack,omitempty"`
 	Cron    *CronTrigger    `json:"cron,omitempty"`
+	Webhook *WebhookTrigger `json:"webhook,omitempty" yaml:"webhook,omitempty"`
 }
 
 func (m Trigger) Value() (driver.Value, error) {
@@ -1138,6 +1527,23 @@ func (Triggers) GormDataType() string {
 	return "json"
 }
 
+// TriggerExecution is a single run of an app trigger, the conversation is
+// stored in the session of the run
+type TriggerExecution struct {
+	ID         string                 `json:"id" gorm:"primaryKey"`
+	Created    time.Time              `json:"created"`
+	Updated    time.Time              `json:"updated"`
+	AppID      string                 `json:"app_id" gorm:"index"`
+	Trigger    TriggerType            `json:"trigger"`
+	SessionID  string                 `json:"session_id"`
+	Status     TriggerExecutionStatus `json:"status"`
+	Input      string                 `json:"input"`
+	Attempts   int                    `json:"attempts"`
+	DurationMs int                    `json:"duration_ms"`
+	Output     string                 `json:"output"`
+	Error      string                 `json:"error"`
+}
+
 type App struct {
 	ID      string    `json:"id" gorm:"primaryKey"`
 	Created time.Time `json:"created"`
@@ -1194,6 +1600,41 @@ type GptScriptResponse struct {
 	Output  string `json:"output"`
 	Error   string `json:"error"`
 	Retries int    `json:"retries"`
+	// Code is the result of the code tools, the output is its stdout
+	Code *CodeExecutionResponse `json:"code,omitempty"`
+}
+
+// CodeExecutionRequest runs a code snippet in the sandbox of the GPTScript runner
+type CodeExecutionRequest struct {
+	Language       CodeLanguage `json:"language"`
+	Code           string       `json:"code"`
+	TimeoutSeconds int          `json:"timeout_seconds"`
+	MemoryMB       int          `json:"memory_mb"`
+	CPUs           float64      `json:"cpus"`
+	Network        bool         `json:"network"`
+	// Files are written to the scratch directory, the working directory of the code
+	Files []*CodeFile `json:"files,omitempty"`
+}
+
+type CodeExecutionResponse struct {
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+	ExitCode int    `json:"exit_code"`
+	TimedOut bool   `json:"timed_out"`
+	// Truncated is set when the output or the files were over the limits
+	Truncated bool `json:"truncated"`
+	// Files created or changed by the code in the scratch directory
+	Files      []*CodeFile `json:"files,omitempty"`
+	DurationMs int         `json:"duration_ms"`
+	// Error is set when the code couldn't be run at all
+	Error string `json:"error,omitempty"`
+}
+
+// CodeFile is a file of the scratch directory, the path is relative to it
+type CodeFile struct {
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	Content []byte `json:"content,omitempty"`
 }
 
 func (m GptScriptResponse) Value() (driver.Value, error) {
@@ -1268,7 +1709,7 @@ type ScriptRunType string
 const (
 	GptScriptRunnerTaskTypeGithubApp ScriptRunType = "github_app"
 	GptScriptRunnerTaskTypeTool      ScriptRunType = "tool"
-	// TODO: add more types, like python script, etc.
+	GptScriptRunnerTaskTypeCode      ScriptRunType = "code"
 )
 
 // ScriptRun is an internal type that is used when GPTScript
@@ -1298,7 +1739,8 @@ type GptScriptRunsQuery struct {
 }
 
 type GptScriptRunnerRequest struct {
-	GithubApp *GptScriptGithubApp `json:"github_app"`
+	GithubApp *GptScriptGithubApp   `json:"github_app"`
+	Code      *CodeExecutionRequest `json:"code,omitempty"`
 }
 
 func (m GptScriptRunnerRequest) Value() (driver.Value, error) {
@@ -1331,6 +1773,8 @@ func (r RunnerEventRequestType) String() string {
 		return "tool"
 	case RunnerEventRequestApp:
 		return "app"
+	case RunnerEventRequestCode:
+		return "code"
 	default:
 		return "unknown"
 	}
@@ -1339,6 +1783,7 @@ func (r RunnerEventRequestType) String() string {
 const (
 	RunnerEventRequestTool RunnerEventRequestType = iota
 	RunnerEventRequestApp
+	RunnerEventRequestCode
 )
 
 type RunnerEventRequestEnvelope struct {
@@ -1363,11 +1808,22 @@ type RunnerLLMInferenceRequest struct {
 	CreatedAt time.Time
 
 	Priority      bool
+	PriorityClass string
 	OwnerID       string
 	SessionID     string
 	InteractionID string
 
 	Request *openai.ChatCompletionRequest
+	// EmbeddingRequest is set instead of Request for the embeddings
+	EmbeddingRequest *openai.EmbeddingRequest
+}
+
+// ModelName returns the model of either the chat completion or the embedding request
+func (r *RunnerLLMInferenceRequest) ModelName() string {
+	if r.EmbeddingRequest != nil {
+		return string(r.EmbeddingRequest.Model)
+	}
+	return r.Request.Model
 }
 
 type RunnerLLMInferenceResponse struct {
@@ -1379,8 +1835,9 @@ type RunnerLLMInferenceResponse struct {
 	SessionID     string
 	InteractionID string
 
-	Response       *openai.ChatCompletionResponse
-	StreamResponse *openai.ChatCompletionStreamResponse
+	Response          *openai.ChatCompletionResponse
+	StreamResponse    *openai.ChatCompletionStreamResponse
+	EmbeddingResponse *openai.EmbeddingResponse
 
 	// Error is set if there was an error
 	Error string
@@ -1398,7 +1855,11 @@ const (
 	LLMCallStepDefault           LLMCallStep = "default"
 	LLMCallStepIsActionable      LLMCallStep = "is_actionable"
 	LLMCallStepPrepareAPIRequest LLMCallStep = "prepare_api_request"
+	LLMCallStepPrepareCode       LLMCallStep = "prepare_code"
 	LLMCallStepInterpretResponse LLMCallStep = "interpret_response"
+	LLMCallStepAgentPlan         LLMCallStep = "agent_plan"
+	LLMCallStepAgentAnswer       LLMCallStep = "agent_answer"
+	LLMCallStepRerank            LLMCallStep = "rerank"
 )
 
 // LLMCall used to store the request and response of LLM calls
@@ -1420,4 +1881,107 @@ type LLMCall struct {
 	PromptTokens     int64
 	CompletionTokens int64
 	TotalTokens      int64
+	// the call is attributed to the owner, the app and the API key in the usage
+	// ledger, the key is only stored as its ID, see APIKeyID
+	OwnerType OwnerType `json:"owner_type"`
+	AppID     string    `json:"app_id"`
+	APIKeyID  string    `json:"api_key_id"`
+	// the routing policy that picked the provider, the attempt and why the
+	// previous providers of the route were skipped
+	Route        string `json:"route,omitempty" gorm:"index"`
+	RouteAttempt int    `json:"route_attempt,omitempty"`
+	RouteReason  string `json:"route_reason,omitempty"`
+}
+
+// DailyUsage is a row of the usage ledger, the LLM calls of a day are
+// aggregated by the owner, app, API key, provider and model
+type DailyUsage struct {
+	Date      time.Time `json:"date" gorm:"primaryKey;type:date"`
+	Owner     string    `json:"owner" gorm:"primaryKey"`
+	OwnerType OwnerType `json:"owner_type" gorm:"primaryKey"`
+	AppID     string    `json:"app_id" gorm:"primaryKey;index"`
+	APIKeyID  string    `json:"api_key_id" gorm:"primaryKey"`
+	Provider  string    `json:"provider" gorm:"primaryKey"`
+	Model     string    `json:"model" gorm:"primaryKey"`
+	Updated   time.Time `json:"updated"`
+	UsageTotals
+}
+
+func (DailyUsage) TableName() string {
+	return "daily_usage"
+}
+
+type UsageTotals struct {
+	Requests         int64 `json:"requests"`
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+	TotalTokens      int64 `json:"total_tokens"`
+	// Cost in USD, calls of models without a price don't cost anything
+	Cost float64 `json:"cost"`
+}
+
+// Add adds the usage to the totals
+func (t *UsageTotals) Add(u *UsageTotals) {
+	t.Requests += u.Requests
+	t.PromptTokens += u.PromptTokens
+	t.CompletionTokens += u.CompletionTokens
+	t.TotalTokens += u.TotalTokens
+	t.Cost += u.Cost
+}
+
+// UsageReport is the usage of a period, from and to are inclusive dates
+type UsageReport struct {
+	From   time.Time     `json:"from"`
+	To     time.Time     `json:"to"`
+	Totals UsageTotals   `json:"totals"`
+	Usage  []*DailyUsage `json:"usage"`
+}
+
+type AuditAction string
+
+const (
+	AuditActionCreate  AuditAction = "create"
+	AuditActionUpdate  AuditAction = "update"
+	AuditActionDelete  AuditAction = "delete"
+	AuditActionRefresh AuditAction = "refresh"
+)
+
+type AuditTargetType string
+
+const (
+	AuditTargetApp              AuditTargetType = "app"
+	AuditTargetAppGithubConfig  AuditTargetType = "app_github_config"
+	AuditTargetTool             AuditTargetType = "tool"
+	AuditTargetKnowledge        AuditTargetType = "knowledge"
+	AuditTargetAPIKey           AuditTargetType = "api_key"
+	AuditTargetSession          AuditTargetType = "session"
+	AuditTargetProviderEndpoint AuditTargetType = "provider_endpoint"
+)
+
+// AuditLog is an entry of the append-only log of the administrative actions,
+// secrets are redacted from the before and after snapshots
+type AuditLog struct {
+	ID         string          `json:"id" gorm:"primaryKey"`
+	Created    time.Time       `json:"created" gorm:"index"`
+	UserID     string          `json:"user_id" gorm:"index"`
+	UserEmail  string          `json:"user_email"`
+	TokenType  TokenType       `json:"token_type"`
+	RemoteAddr string          `json:"remote_addr"`
+	Action     AuditAction     `json:"action" gorm:"index"`
+	TargetType AuditTargetType `json:"target_type" gorm:"index"`
+	TargetID   string          `json:"target_id" gorm:"index"`
+	// AppID of the app the target belongs to, if any
+	AppID  string         `json:"app_id,omitempty" gorm:"index"`
+	Before datatypes.JSON `json:"before,omitempty" gorm:"type:jsonb"`
+	After  datatypes.JSON `json:"after,omitempty" gorm:"type:jsonb"`
+	// Diff lists the changed fields between before and after
+	Diff datatypes.JSON `json:"diff,omitempty" gorm:"type:jsonb"`
+}
+
+// AuditChange is a changed field of the audited target, the path is the
+// dot separated JSON path of the field
+type AuditChange struct {
+	Path   string      `json:"path"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
 }
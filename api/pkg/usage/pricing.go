@@ -0,0 +1,81 @@
+package usage
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+// ModelPrice is the price of a model in USD per million tokens
+type ModelPrice struct {
+	// Provider of the model, the price applies to all providers when empty
+	Provider   types.Provider `yaml:"provider"`
+	Model      string         `yaml:"model"`
+	Prompt     float64        `yaml:"prompt"`
+	Completion float64        `yaml:"completion"`
+}
+
+// Pricing is the list of the model prices, e.g.:
+//
+//   - provider: openai
+//     model: gpt-4o
+//     prompt: 2.5
+//     completion: 10
+type Pricing []ModelPrice
+
+func LoadPricing(path string) (Pricing, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	bts, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pricing file: %w", err)
+	}
+
+	var pricing Pricing
+	err = yaml.Unmarshal(bts, &pricing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pricing file: %w", err)
+	}
+
+	for _, price := range pricing {
+		if price.Model == "" {
+			return nil, fmt.Errorf("pricing file has a price without a model")
+		}
+		if price.Prompt < 0 || price.Completion < 0 {
+			return nil, fmt.Errorf("price of model '%s' must not be negative", price.Model)
+		}
+	}
+
+	return pricing, nil
+}
+
+// Cost returns the cost of the tokens in USD, the price of the provider takes
+// precedence over the price for all providers. Models without a price are free.
+func (p Pricing) Cost(provider types.Provider, model string, promptTokens, completionTokens int64) float64 {
+	var match *ModelPrice
+
+	for i := range p {
+		price := &p[i]
+		if price.Model != model {
+			continue
+		}
+		if price.Provider == provider {
+			match = price
+			break
+		}
+		if price.Provider == "" && match == nil {
+			match = price
+		}
+	}
+
+	if match == nil {
+		return 0
+	}
+
+	return (float64(promptTokens)*match.Prompt + float64(completionTokens)*match.Completion) / 1_000_000
+}
@@ -0,0 +1,52 @@
+package usage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func TestLoadPricing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pricing.yaml")
+	err := os.WriteFile(path, []byte(`
+- model: gpt-4o
+  prompt: 5
+  completion: 15
+- provider: openai
+  model: gpt-4o
+  prompt: 2.5
+  completion: 10
+`), 0o644)
+	require.NoError(t, err)
+
+	pricing, err := LoadPricing(path)
+	require.NoError(t, err)
+	require.Len(t, pricing, 2)
+
+	// The price of the provider takes precedence
+	assert.InDelta(t, 0.0125, pricing.Cost(types.ProviderOpenAI, "gpt-4o", 1000, 1000), 1e-9)
+	assert.InDelta(t, 0.02, pricing.Cost(types.ProviderTogetherAI, "gpt-4o", 1000, 1000), 1e-9)
+	assert.Zero(t, pricing.Cost(types.ProviderOpenAI, "gpt-4o-mini", 1000, 1000))
+
+	pricing, err = LoadPricing("")
+	require.NoError(t, err)
+	assert.Zero(t, pricing.Cost(types.ProviderOpenAI, "gpt-4o", 1000, 1000))
+}
+
+func TestLoadPricing_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pricing.yaml")
+
+	require.NoError(t, os.WriteFile(path, []byte(`- prompt: 1`), 0o644))
+	_, err := LoadPricing(path)
+	assert.ErrorContains(t, err, "price without a model")
+
+	require.NoError(t, os.WriteFile(path, []byte(`- model: gpt-4o
+  prompt: -1`), 0o644))
+	_, err = LoadPricing(path)
+	assert.ErrorContains(t, err, "must not be negative")
+}
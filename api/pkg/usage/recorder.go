@@ -0,0 +1,66 @@
+package usage
+
+import (
+	"context"
+	"time"
+
+	"github.com/helixml/helix/api/pkg/openai/logger"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+// Recorder adds the LLM calls to the usage ledger, it's used as a log store
+// of the LLM calls
+type Recorder struct {
+	store   store.Store
+	pricing Pricing
+}
+
+var _ logger.LogStore = &Recorder{}
+
+func NewRecorder(store store.Store, pricing Pricing) *Recorder {
+	return &Recorder{
+		store:   store,
+		pricing: pricing,
+	}
+}
+
+func (r *Recorder) CreateLLMCall(ctx context.Context, call *types.LLMCall) (*types.LLMCall, error) {
+	// Calls made outside of the sessions and the API (e.g. Discord) don't
+	// have an owner to attribute them to
+	if call.UserID == "" {
+		return call, nil
+	}
+
+	ownerType := call.OwnerType
+	if ownerType == "" {
+		ownerType = types.OwnerTypeUser
+	}
+
+	date := call.Created
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	err := r.store.RecordUsage(ctx, &types.DailyUsage{
+		Date:      date,
+		Owner:     call.UserID,
+		OwnerType: ownerType,
+		AppID:     call.AppID,
+		APIKeyID:  call.APIKeyID,
+		Provider:  call.Provider,
+		Model:     call.Model,
+		UsageTotals: types.UsageTotals{
+			Requests:         1,
+			PromptTokens:     call.PromptTokens,
+			CompletionTokens: call.CompletionTokens,
+			TotalTokens:      call.TotalTokens,
+			Cost:             r.pricing.Cost(types.Provider(call.Provider), call.Model, call.PromptTokens, call.CompletionTokens),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return call, nil
+}
@@ -0,0 +1,58 @@
+package usage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func TestRecorder_CreateLLMCall(t *testing.T) {
+	storeMock := store.NewMockStore(gomock.NewController(t))
+
+	recorder := NewRecorder(storeMock, Pricing{
+		{Model: "gpt-4o", Prompt: 2.5, Completion: 10},
+	})
+
+	created := time.Date(2024, 9, 3, 15, 4, 5, 0, time.UTC)
+
+	storeMock.EXPECT().RecordUsage(gomock.Any(), &types.DailyUsage{
+		Date:      created,
+		Owner:     "user_1",
+		OwnerType: types.OwnerTypeUser,
+		AppID:     "app_1",
+		APIKeyID:  "0123456789abcdef",
+		Provider:  "openai",
+		Model:     "gpt-4o",
+		UsageTotals: types.UsageTotals{
+			Requests:         1,
+			PromptTokens:     1000,
+			CompletionTokens: 500,
+			TotalTokens:      1500,
+			Cost:             0.0075,
+		},
+	}).Return(nil)
+
+	_, err := recorder.CreateLLMCall(context.Background(), &types.LLMCall{
+		Created:          created,
+		UserID:           "user_1",
+		AppID:            "app_1",
+		APIKeyID:         "0123456789abcdef",
+		Provider:         "openai",
+		Model:            "gpt-4o",
+		PromptTokens:     1000,
+		CompletionTokens: 500,
+		TotalTokens:      1500,
+	})
+	require.NoError(t, err)
+
+	// Calls without an owner are not recorded
+	_, err = recorder.CreateLLMCall(context.Background(), &types.LLMCall{Model: "gpt-4o"})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,41 @@
+import React, { FC } from 'react'
+import Typography from '@mui/material/Typography'
+import Row from '../widgets/Row'
+import Cell from '../widgets/Cell'
+
+import {
+  ICacheStats,
+} from '../../types'
+
+export const CacheSummary: FC<{
+  cache: ICacheStats,
+}> = ({
+  cache,
+}) => {
+  return (
+    <Row>
+      <Cell flexGrow={ 1 }>
+        <Typography component="div" variant="caption">
+          { cache.entries } entries
+        </Typography>
+      </Cell>
+      <Cell flexGrow={ 1 }>
+        <Typography component="div" variant="caption">
+          { cache.hits } hits{ cache.semantic_hits > 0 ? ` (${cache.semantic_hits} semantic)` : '' }
+        </Typography>
+      </Cell>
+      <Cell flexGrow={ 1 }>
+        <Typography component="div" variant="caption">
+          { cache.misses } misses
+        </Typography>
+      </Cell>
+      <Cell flexGrow={ 1 }>
+        <Typography component="div" variant="caption">
+          { (cache.hit_rate * 100).toFixed(1) }% hit rate
+        </Typography>
+      </Cell>
+    </Row>
+  )
+}
+
+export default CacheSummary
@@ -61,7 +61,7 @@ export const SchedulingDecisionSummary: FC<{
       </Cell>
       <Cell flexGrow={ 1 }>
         <Typography component="div" variant="caption" style={{ whiteSpace: 'nowrap', overflow: 'hidden', textOverflow: 'ellipsis' }}>
-          -&gt; { decision.runner_id }
+          -&gt; { decision.runner_id }{ decision.priority_class ? ` [${decision.priority_class}]` : '' }{ decision.reason ? ` (${decision.reason})` : '' }
         </Typography>
       </Cell>
       <Cell>
@@ -1,4 +1,4 @@
-import React, { FC, useEffect, createContext, useMemo, useState, useCallback } from 'react'
+import React, { FC, useEffect, createContext, useMemo, useState, useCallback, useRef } from 'react'
 import bluebird from 'bluebird'
 import Keycloak from 'keycloak-js'
 import useApi from '../hooks/useApi'
@@ -6,6 +6,7 @@ import useSnackbar from '../hooks/useSnackbar'
 import useLoading from '../hooks/useLoading'
 import useRouter from '../hooks/useRouter'
 import { extractErrorMessage } from '../hooks/useErrorCallback'
+import { OIDCClient } from '../utils/oidc'
 
 import {
   IKeycloakUser,
@@ -102,6 +103,9 @@ export const useAccountContext = (): IAccountContext => {
     })
   }, [])
 
+  // set when the server uses a generic OpenID Connect provider instead of Keycloak
+  const oidc = useRef<OIDCClient>()
+
   const token = useMemo(() => {
     if(user && user.token) {
       return user.token
@@ -150,6 +154,10 @@ export const useAccountContext = (): IAccountContext => {
   ])
 
   const onLogin = useCallback(() => {
+    if(oidc.current) {
+      oidc.current.login()
+      return
+    }
     keycloak.login()
   }, [
     keycloak,
@@ -158,14 +166,77 @@ export const useAccountContext = (): IAccountContext => {
   const onLogout = useCallback(() => {
     setLoggingOut(true)
     router.navigate('home')
+    if(oidc.current) {
+      oidc.current.logout()
+      return
+    }
     keycloak.logout()
   }, [
     keycloak,
   ])
 
+  const setLoggedInUser = useCallback((user: IKeycloakUser) => {
+    const win = (window as any)
+    if(win.setUser) {
+      win.setUser(user)
+    }
+
+    if(win.$crisp) {
+      win.$crisp.push(['set', 'user:email', user?.email])
+      win.$crisp.push(['set', 'user:nickname', user?.name])
+    }
+
+    api.setToken(user.token)
+    setUser(user)
+  }, [])
+
+  const initializeOIDC = useCallback(async (client: OIDCClient) => {
+    const authenticated = await client.init()
+    if(!authenticated) return
+
+    const claims = client.claims
+    if(!claims?.sub) throw new Error(`no user id found from the OIDC provider`)
+    if(!client.token) throw new Error(`no user token found from the OIDC provider`)
+    const user: IKeycloakUser = {
+      id: claims.sub,
+      email: claims.email || claims.preferred_username || '',
+      token: client.token,
+      name: claims.name || claims.preferred_username || '',
+    }
+    setLoggedInUser(user)
+    setInterval(async () => {
+      try {
+        const updated = await client.updateToken()
+        if(updated && client.token) {
+          api.setToken(client.token)
+          setUser(Object.assign({}, user, {
+            token: client.token,
+          }))
+        }
+      } catch(e) {
+        client.login()
+      }
+    }, 10 * 1000)
+  }, [])
+
   const initialize = useCallback(async () => {
     loading.setLoading(true)
     try {
+      // The login depends on the auth provider of the server
+      const configResult = await api.get<IServerConfig>('/api/v1/config')
+      if(configResult?.auth_provider == 'oidc') {
+        if(!configResult.oidc_issuer || !configResult.oidc_client_id) throw new Error(`the OIDC issuer and client ID are not configured`)
+        oidc.current = new OIDCClient({
+          issuer: configResult.oidc_issuer,
+          clientId: configResult.oidc_client_id,
+          scopes: configResult.oidc_scopes || '',
+        })
+        await initializeOIDC(oidc.current)
+        loading.setLoading(false)
+        setInitialized(true)
+        return
+      }
+
       const authenticated = await keycloak.init({
         onLoad: 'check-sso',
         pkceMethod: 'S256',
@@ -180,18 +251,7 @@ export const useAccountContext = (): IAccountContext => {
           token: keycloak.token,
           name: keycloak.tokenParsed?.name,
         }
-        const win = (window as any)
-        if(win.setUser) {
-          win.setUser(user)
-        }
-
-        if(win.$crisp) {
-          win.$crisp.push(['set', 'user:email', user?.email])
-          win.$crisp.push(['set', 'user:nickname', user?.name])
-        }
-
-        api.setToken(keycloak.token)
-        setUser(user)
+        setLoggedInUser(user)
         setInterval(async () => {
           try {
             const updated = await keycloak.updateToken(10)
@@ -23,6 +23,7 @@ import RunnerSummary from '../components/session/RunnerSummary'
 import SchedulingDecisionSummary from '../components/session/SchedulingDecisionSummary'
 import SessionBadgeKey from '../components/session/SessionBadgeKey'
 import LLMCallsTable from '../components/dashboard/LLMCallsTable'
+import CacheSummary from '../components/dashboard/CacheSummary'
 
 import useRouter from '../hooks/useRouter'
 import useAccount from '../hooks/useAccount'
@@ -241,6 +242,12 @@ const Dashboard: FC = () => {
                     mt: 1,
                     mb: 1,
                   }} />
+                {data.cache && (
+                  <>
+                    <Typography variant="h6">Response Cache</Typography>
+                    <CacheSummary cache={data.cache} />
+                  </>
+                )}
                 {data?.runners.map((runner) => {
                   const allSessions = runner.model_instances.reduce<ISessionSummary[]>((allSessions, modelInstance) => {
                     return modelInstance.current_session ? [...allSessions, modelInstance.current_session] : allSessions
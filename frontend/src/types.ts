@@ -15,9 +15,10 @@ export type ISessionType = 'text' | 'image'
 export const SESSION_TYPE_TEXT: ISessionType = 'text'
 export const SESSION_TYPE_IMAGE: ISessionType = 'image'
 
-export type ISessionOriginType = 'user_created' | 'cloned'
+export type ISessionOriginType = 'user_created' | 'cloned' | 'trigger'
 export const SESSION_ORIGIN_TYPE_USER_CREATED: ISessionOriginType = 'user_created'
 export const SESSION_ORIGIN_TYPE_CLONED: ISessionOriginType = 'cloned'
+export const SESSION_ORIGIN_TYPE_TRIGGER: ISessionOriginType = 'trigger'
 
 export type IInteractionState = 'waiting' | 'editing' | 'complete' | 'error'
 export const INTERACTION_STATE_WAITING: IInteractionState = 'waiting'
@@ -98,6 +99,24 @@ export interface IHelixModel {
 
 export type IOwnerType = 'user' | 'system' | 'org'
 
+export type IOrganizationRole = 'owner' | 'admin' | 'member'
+
+export interface IOrganization {
+  id: string,
+  created: string,
+  updated: string,
+  name: string,
+  owner: string,
+}
+
+export interface IOrganizationMembership {
+  organization_id: string,
+  user_id: string,
+  created: string,
+  updated: string,
+  role: IOrganizationRole,
+}
+
 export type IApiKeyType = 'api' | 'github' | 'app'
 
 export interface IApiKey {
@@ -271,6 +290,10 @@ export interface IServerConfig {
   eval_user_id: string,
   tools_enabled: boolean,
   apps_enabled: boolean,
+  auth_provider?: 'keycloak' | 'oidc',
+  oidc_issuer?: string,
+  oidc_client_id?: string,
+  oidc_scopes?: string,
 }
 
 export interface IConversation {
@@ -335,12 +358,26 @@ export interface  IGlobalSchedulingDecision {
   filter: ISessionFilter,
   mode: ISessionMode,
   model_name: string,
+  owner?: string,
+  priority_class?: string,
+  reason?: string,
+  preempted?: string[],
 }
 
 export interface IDashboardData {
   session_queue: ISessionSummary[],
   runners: IRunnerState[],
   global_scheduling_decisions: IGlobalSchedulingDecision[],
+  // only set when the response cache is enabled
+  cache?: ICacheStats,
+}
+
+export interface ICacheStats {
+  entries: number,
+  hits: number,
+  semantic_hits: number,
+  misses: number,
+  hit_rate: number,
 }
 
 export interface ISessionSummary {
@@ -533,6 +570,8 @@ export interface IAssistantConfig {
   gptscripts: IAssistantGPTScript[];
   tools: ITool[];
   knowledge?: IKnowledgeSource[];
+  agent_mode?: boolean;
+  max_agent_steps?: number;
 }
 
 export interface IKnowledgeSource {
@@ -544,10 +583,19 @@ export interface IKnowledgeSource {
     results_count: number;
     chunk_size: number;
     chunk_overflow: number;
+    hybrid?: boolean;
+    hybrid_alpha?: number;
+    rerank?: {
+      enabled: boolean;
+      provider?: string;
+      model?: string;
+      top_n?: number;
+    };
   };
   state: string;
   message?: string;
   progress_percent?: number;
+  source_revision?: string;
   source: {
     helix_drive?: {
       path: string;
@@ -563,6 +611,13 @@ export interface IKnowledgeSource {
     filestore?: {
       path: string;
     };
+    github?: {
+      owner: string;
+      repository: string;
+      branch?: string;
+      filter_paths?: string[];
+      filter_extensions?: string[];
+    };
     web?: {
       urls?: string[];
       excludes?: string[];
@@ -599,6 +654,11 @@ export interface ISessionRAGResult {
   source: string;
   document_id: string;
   document_group_id: string;
+  distance?: number;
+  vector_score?: number;
+  keyword_score?: number;
+  hybrid_score?: number;
+  rerank_score?: number;
   // Add any other properties that your API returns
 }
 
@@ -0,0 +1,224 @@
+// A minimal OpenID Connect client for the authorization code flow with PKCE,
+// used when Helix is configured with AUTH_PROVIDER=oidc instead of Keycloak.
+// The provider must allow the Helix URL as a redirect URI of a public client.
+
+const TOKENS_KEY = 'helix_oidc_tokens'
+const LOGIN_KEY = 'helix_oidc_login'
+
+// refresh the tokens this long before they expire
+const REFRESH_MARGIN_MS = 30 * 1000
+
+export interface IOIDCConfig {
+  issuer: string,
+  clientId: string,
+  scopes: string,
+}
+
+export interface IOIDCTokens {
+  access_token: string,
+  id_token?: string,
+  refresh_token?: string,
+  // unix time in milliseconds
+  expires_at: number,
+}
+
+export interface IOIDCClaims {
+  sub: string,
+  email?: string,
+  name?: string,
+  preferred_username?: string,
+  [key: string]: any,
+}
+
+interface IOIDCDiscovery {
+  authorization_endpoint: string,
+  token_endpoint: string,
+  end_session_endpoint?: string,
+}
+
+interface IOIDCLogin {
+  state: string,
+  verifier: string,
+  returnTo: string,
+}
+
+const base64UrlEncode = (bytes: Uint8Array) => {
+  let str = ''
+  bytes.forEach(b => str += String.fromCharCode(b))
+  return btoa(str).replace(/\+/g, '-').replace(/\//g, '_').replace(/=+$/, '')
+}
+
+const randomString = () => {
+  const bytes = new Uint8Array(32)
+  window.crypto.getRandomValues(bytes)
+  return base64UrlEncode(bytes)
+}
+
+const codeChallenge = async (verifier: string) => {
+  const digest = await window.crypto.subtle.digest('SHA-256', new TextEncoder().encode(verifier))
+  return base64UrlEncode(new Uint8Array(digest))
+}
+
+export const parseJWT = (token: string): IOIDCClaims | undefined => {
+  const parts = token.split('.')
+  if(parts.length != 3) return undefined
+  try {
+    const payload = parts[1].replace(/-/g, '+').replace(/_/g, '/')
+    const json = decodeURIComponent(atob(payload).split('').map(c => '%' + ('00' + c.charCodeAt(0).toString(16)).slice(-2)).join(''))
+    return JSON.parse(json)
+  } catch(e) {
+    return undefined
+  }
+}
+
+export class OIDCClient {
+  private config: IOIDCConfig
+  private discovery?: IOIDCDiscovery
+  private tokens?: IOIDCTokens
+
+  constructor(config: IOIDCConfig) {
+    this.config = config
+  }
+
+  private redirectUri() {
+    return window.location.origin + '/'
+  }
+
+  private async discover(): Promise<IOIDCDiscovery> {
+    if(this.discovery) return this.discovery
+    const res = await fetch(this.config.issuer.replace(/\/$/, '') + '/.well-known/openid-configuration')
+    if(!res.ok) throw new Error(`failed to discover the OIDC provider configuration: ${res.status}`)
+    this.discovery = await res.json() as IOIDCDiscovery
+    return this.discovery
+  }
+
+  private async requestTokens(params: Record<string, string>): Promise<IOIDCTokens> {
+    const discovery = await this.discover()
+    const res = await fetch(discovery.token_endpoint, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/x-www-form-urlencoded',
+      },
+      body: new URLSearchParams(Object.assign({
+        client_id: this.config.clientId,
+      }, params)).toString(),
+    })
+    if(!res.ok) throw new Error(`OIDC token request failed: ${res.status} ${await res.text()}`)
+    const data = await res.json()
+    const tokens: IOIDCTokens = {
+      access_token: data.access_token,
+      id_token: data.id_token || this.tokens?.id_token,
+      // providers don't always rotate the refresh token
+      refresh_token: data.refresh_token || this.tokens?.refresh_token,
+      expires_at: Date.now() + (data.expires_in || 300) * 1000,
+    }
+    this.tokens = tokens
+    localStorage.setItem(TOKENS_KEY, JSON.stringify(tokens))
+    return tokens
+  }
+
+  // init completes the login when the provider redirected back with the code,
+  // otherwise it restores the tokens of the previous login
+  async init(): Promise<boolean> {
+    const params = new URLSearchParams(window.location.search)
+    const code = params.get('code')
+    const state = params.get('state')
+    const pendingLogin = sessionStorage.getItem(LOGIN_KEY)
+
+    if(code && state && pendingLogin) {
+      sessionStorage.removeItem(LOGIN_KEY)
+      const login = JSON.parse(pendingLogin) as IOIDCLogin
+      if(login.state != state) throw new Error('OIDC login state mismatch')
+      await this.requestTokens({
+        grant_type: 'authorization_code',
+        code,
+        redirect_uri: this.redirectUri(),
+        code_verifier: login.verifier,
+      })
+      window.history.replaceState(null, '', login.returnTo)
+      return true
+    }
+
+    const stored = localStorage.getItem(TOKENS_KEY)
+    if(!stored) return false
+    this.tokens = JSON.parse(stored) as IOIDCTokens
+
+    try {
+      await this.updateToken()
+    } catch(e) {
+      this.clear()
+      return false
+    }
+    return true
+  }
+
+  async login() {
+    const discovery = await this.discover()
+    const login: IOIDCLogin = {
+      state: randomString(),
+      verifier: randomString(),
+      returnTo: window.location.pathname + window.location.search,
+    }
+    sessionStorage.setItem(LOGIN_KEY, JSON.stringify(login))
+
+    const params = new URLSearchParams({
+      response_type: 'code',
+      client_id: this.config.clientId,
+      redirect_uri: this.redirectUri(),
+      scope: this.config.scopes || 'openid profile email',
+      state: login.state,
+      code_challenge: await codeChallenge(login.verifier),
+      code_challenge_method: 'S256',
+    })
+    window.location.href = `${discovery.authorization_endpoint}?${params.toString()}`
+  }
+
+  async logout() {
+    const idToken = this.tokens?.id_token
+    this.clear()
+
+    const discovery = await this.discover()
+    if(!discovery.end_session_endpoint) {
+      window.location.href = this.redirectUri()
+      return
+    }
+
+    const params = new URLSearchParams({
+      client_id: this.config.clientId,
+      post_logout_redirect_uri: this.redirectUri(),
+    })
+    if(idToken) params.set('id_token_hint', idToken)
+    window.location.href = `${discovery.end_session_endpoint}?${params.toString()}`
+  }
+
+  // updateToken refreshes the tokens when they are about to expire, it
+  // returns true if they were refreshed
+  async updateToken(): Promise<boolean> {
+    if(!this.tokens) throw new Error('not logged in')
+    if(this.tokens.expires_at - Date.now() > REFRESH_MARGIN_MS) return false
+    if(!this.tokens.refresh_token) throw new Error('the OIDC session expired')
+    await this.requestTokens({
+      grant_type: 'refresh_token',
+      refresh_token: this.tokens.refresh_token,
+    })
+    return true
+  }
+
+  // token is sent to the API, access tokens aren't always JWTs so the ID token
+  // is used when the access token is opaque
+  get token(): string | undefined {
+    if(!this.tokens) return undefined
+    if(parseJWT(this.tokens.access_token)) return this.tokens.access_token
+    return this.tokens.id_token
+  }
+
+  get claims(): IOIDCClaims | undefined {
+    if(!this.tokens) return undefined
+    return parseJWT(this.tokens.id_token || this.tokens.access_token)
+  }
+
+  private clear() {
+    this.tokens = undefined
+    localStorage.removeItem(TOKENS_KEY)
+  }
+}